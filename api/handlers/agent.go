@@ -129,6 +129,33 @@ func (h *AgentHandler) GetAgent(c *gin.Context) {
 	})
 }
 
+// GetAgentInstances 获取Agent的sing-box实例状态
+// @Summary 获取Agent的sing-box实例状态
+// @Description 返回Agent最近一次心跳上报的各sing-box实例状态（监管状态、PID、配置版本、连接统计）
+// @Tags agents
+// @Produce json
+// @Param id path string true "Agent ID"
+// @Success 200 {object} Response
+// @Router /api/v1/agents/{id}/instances [get]
+func (h *AgentHandler) GetAgentInstances(c *gin.Context) {
+	agentID := c.Param("id")
+
+	instances, ok := h.agentService.GetInstances(agentID)
+	if !ok {
+		c.JSON(http.StatusNotFound, Response{
+			Code:    404,
+			Message: "Agent尚未上报实例状态",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "success",
+		Data:    instances,
+	})
+}
+
 // DeleteAgent 删除Agent
 // @Summary 删除Agent
 // @Description 删除指定的Agent节点
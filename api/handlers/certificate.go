@@ -0,0 +1,377 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xbox/sing-box-manager/internal/controller/service"
+)
+
+// CertificateHandler TLS证书托管API处理器
+type CertificateHandler struct {
+	certificateService service.CertificateService
+}
+
+// NewCertificateHandler 创建TLS证书处理器实例
+func NewCertificateHandler(certificateService service.CertificateService) *CertificateHandler {
+	return &CertificateHandler{
+		certificateService: certificateService,
+	}
+}
+
+// parseCertificateID 解析路径中的证书ID
+func parseCertificateID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    400,
+			Message: "证书ID无效",
+			Error:   err.Error(),
+		})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// CreateCertificate 上传证书
+// @Summary 上传证书
+// @Description 上传PEM格式的证书链和私钥，解析SAN、签发者和有效期。证书与私钥需匹配且未过期，私钥不会在接口中返回
+// @Tags certificates
+// @Accept json
+// @Produce json
+// @Param request body service.CertificateRequest true "证书"
+// @Success 200 {object} Response
+// @Router /api/v1/certificates [post]
+func (h *CertificateHandler) CreateCertificate(c *gin.Context) {
+	var req service.CertificateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    400,
+			Message: "请求参数错误",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	cert, err := h.certificateService.CreateCertificate(&req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    400,
+			Message: "上传证书失败",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "证书已上传",
+		Data:    cert,
+	})
+}
+
+// ListCertificates 获取证书列表
+// @Summary 获取证书列表
+// @Description 按到期时间排序，附带剩余天数、是否即将到期和分配情况
+// @Tags certificates
+// @Produce json
+// @Success 200 {object} Response
+// @Router /api/v1/certificates [get]
+func (h *CertificateHandler) ListCertificates(c *gin.Context) {
+	certs, err := h.certificateService.ListCertificates()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, Response{
+			Code:    500,
+			Message: "获取证书列表失败",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "success",
+		Data:    certs,
+	})
+}
+
+// ListExpiring 获取即将到期的证书
+// @Summary 获取即将到期的证书
+// @Description 返回指定天数内到期或已过期的证书及其分配
+// @Tags certificates
+// @Produce json
+// @Param days query int false "天数，默认使用certificate.warn_days配置"
+// @Success 200 {object} Response
+// @Router /api/v1/certificates/expiring [get]
+func (h *CertificateHandler) ListExpiring(c *gin.Context) {
+	days, err := strconv.Atoi(c.DefaultQuery("days", "0"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    400,
+			Message: "days参数无效",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	certs, err := h.certificateService.ListExpiring(days)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, Response{
+			Code:    500,
+			Message: "获取即将到期的证书失败",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "success",
+		Data:    certs,
+	})
+}
+
+// GetCertificate 获取证书详情
+// @Summary 获取证书详情
+// @Tags certificates
+// @Produce json
+// @Param id path int true "证书ID"
+// @Success 200 {object} Response
+// @Failure 404 {object} Response
+// @Router /api/v1/certificates/{id} [get]
+func (h *CertificateHandler) GetCertificate(c *gin.Context) {
+	id, ok := parseCertificateID(c)
+	if !ok {
+		return
+	}
+
+	cert, err := h.certificateService.GetCertificate(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, Response{
+			Code:    404,
+			Message: "证书不存在",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "success",
+		Data:    cert,
+	})
+}
+
+// RenewCertificate 续期证书
+// @Summary 续期证书
+// @Description 以新的证书和私钥替换证书内容，名称不变。push为true时立即下发到全部已分配的入站（一键续期），否则分配状态变为pending，可稍后调用push接口下发
+// @Tags certificates
+// @Accept json
+// @Produce json
+// @Param id path int true "证书ID"
+// @Param request body service.CertificateRequest true "新证书，name被忽略"
+// @Success 200 {object} Response
+// @Router /api/v1/certificates/{id} [put]
+func (h *CertificateHandler) RenewCertificate(c *gin.Context) {
+	id, ok := parseCertificateID(c)
+	if !ok {
+		return
+	}
+
+	var req service.CertificateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    400,
+			Message: "请求参数错误",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	cert, results, err := h.certificateService.RenewCertificate(id, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    400,
+			Message: "续期证书失败",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "证书已续期",
+		Data: gin.H{
+			"certificate": cert,
+			"results":     results,
+		},
+	})
+}
+
+// DeleteCertificate 删除证书
+// @Summary 删除证书
+// @Description 证书仍分配给入站时删除失败
+// @Tags certificates
+// @Produce json
+// @Param id path int true "证书ID"
+// @Success 200 {object} Response
+// @Router /api/v1/certificates/{id} [delete]
+func (h *CertificateHandler) DeleteCertificate(c *gin.Context) {
+	id, ok := parseCertificateID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.certificateService.DeleteCertificate(id); err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    400,
+			Message: "删除证书失败",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "证书已删除",
+	})
+}
+
+// AssignCertificate 分配证书
+// @Summary 分配证书
+// @Description 将证书分配给Agent实例的入站并立即下发。Agent以0600权限写入证书文件，将入站TLS切换为该证书后热重载sing-box。入站已分配其他证书时改为使用该证书
+// @Tags certificates
+// @Accept json
+// @Produce json
+// @Param id path int true "证书ID"
+// @Param request body service.CertificateAssignRequest true "分配目标"
+// @Success 200 {object} Response
+// @Router /api/v1/certificates/{id}/assignments [post]
+func (h *CertificateHandler) AssignCertificate(c *gin.Context) {
+	id, ok := parseCertificateID(c)
+	if !ok {
+		return
+	}
+
+	var req service.CertificateAssignRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    400,
+			Message: "请求参数错误",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	result, err := h.certificateService.AssignCertificate(id, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    400,
+			Message: "分配证书失败",
+			Error:   err.Error(),
+		})
+		return
+	}
+	if result.Status != service.CertAssignmentApplied {
+		c.JSON(http.StatusInternalServerError, Response{
+			Code:    500,
+			Message: "证书已分配，下发失败",
+			Data:    result,
+			Error:   result.ErrorMessage,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "证书已分配并下发",
+		Data:    result,
+	})
+}
+
+// UnassignCertificate 取消证书分配
+// @Summary 取消证书分配
+// @Description 仅删除分配记录，Agent上的证书文件和入站TLS配置保持不变
+// @Tags certificates
+// @Produce json
+// @Param id path int true "证书ID"
+// @Param assignment_id path int true "分配ID"
+// @Success 200 {object} Response
+// @Router /api/v1/certificates/{id}/assignments/{assignment_id} [delete]
+func (h *CertificateHandler) UnassignCertificate(c *gin.Context) {
+	id, ok := parseCertificateID(c)
+	if !ok {
+		return
+	}
+	assignmentID, err := strconv.ParseUint(c.Param("assignment_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    400,
+			Message: "分配ID无效",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	if err := h.certificateService.UnassignCertificate(id, uint(assignmentID)); err != nil {
+		c.JSON(http.StatusNotFound, Response{
+			Code:    404,
+			Message: "取消证书分配失败",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "证书分配已取消",
+	})
+}
+
+// PushCertificate 下发证书
+// @Summary 下发证书
+// @Description 将证书重新下发到全部已分配的入站，返回各分配的下发结果
+// @Tags certificates
+// @Produce json
+// @Param id path int true "证书ID"
+// @Success 200 {object} Response
+// @Router /api/v1/certificates/{id}/push [post]
+func (h *CertificateHandler) PushCertificate(c *gin.Context) {
+	id, ok := parseCertificateID(c)
+	if !ok {
+		return
+	}
+
+	results, err := h.certificateService.PushCertificate(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, Response{
+			Code:    404,
+			Message: "下发证书失败",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	failed := 0
+	for _, result := range results {
+		if result.Status != service.CertAssignmentApplied {
+			failed++
+		}
+	}
+	if failed > 0 {
+		c.JSON(http.StatusInternalServerError, Response{
+			Code:    500,
+			Message: "部分入站下发失败",
+			Data:    results,
+			Error:   strconv.Itoa(failed) + " 个入站下发失败",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "证书已下发",
+		Data:    results,
+	})
+}
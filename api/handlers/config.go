@@ -0,0 +1,341 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xbox/sing-box-manager/internal/agent/singbox"
+	"github.com/xbox/sing-box-manager/internal/controller/service"
+)
+
+// ConfigHandler Agent配置管理API处理器
+type ConfigHandler struct {
+	configService service.ConfigService
+}
+
+// NewConfigHandler 创建配置管理处理器实例
+func NewConfigHandler(configService service.ConfigService) *ConfigHandler {
+	return &ConfigHandler{
+		configService: configService,
+	}
+}
+
+// ConfigRollbackRequest 配置回滚请求
+type ConfigRollbackRequest struct {
+	Scope         string `json:"scope" binding:"omitempty,oneof=filter singbox dns"` // 回滚范围，默认filter
+	TargetVersion string `json:"target_version"`                                     // 目标版本，为空时回滚到上一代
+	Reason        string `json:"reason"`
+}
+
+// ConfigContentRequest sing-box完整配置请求
+type ConfigContentRequest struct {
+	Config json.RawMessage `json:"config" binding:"required"` // sing-box配置JSON，原样下发
+}
+
+// ConfigImportRequest 配置导入请求
+type ConfigImportRequest struct {
+	Format   string   `json:"format" binding:"omitempty,oneof=clash v2ray xray"` // 源配置格式，为空时自动识别
+	Content  string   `json:"content" binding:"required"`                        // 源配置内容
+	AgentIDs []string `json:"agent_ids"`                                         // 下发目标，为空时只转换
+	Instance string   `json:"instance"`                                          // sing-box实例名称，默认default
+	Force    bool     `json:"force"`                                             // 入站端口冲突时仍然应用
+}
+
+// ConfigDiffResponse 配置diff响应
+type ConfigDiffResponse struct {
+	FromVersion int64  `json:"from_version"`
+	ToVersion   int64  `json:"to_version"`
+	Diff        string `json:"diff"`
+}
+
+// ListGenerations 获取sing-box配置历史
+// @Summary 获取sing-box配置历史
+// @Description 列出Agent上保存的sing-box配置代（版本、生效时间、来源、sha256）
+// @Tags configs
+// @Produce json
+// @Param id path string true "Agent ID"
+// @Param instance query string false "sing-box实例名称，默认default"
+// @Success 200 {object} Response
+// @Router /api/v1/agents/{id}/config/generations [get]
+func (h *ConfigHandler) ListGenerations(c *gin.Context) {
+	agentID := c.Param("id")
+
+	generations, err := h.configService.ListGenerations(agentID, c.Query("instance"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, Response{
+			Code:    500,
+			Message: "获取配置历史失败",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "success",
+		Data:    generations,
+	})
+}
+
+// DiffGenerations 比较两代sing-box配置
+// @Summary 比较两代sing-box配置
+// @Description 返回两代配置之间的unified diff，to为空或0时与当前代比较
+// @Tags configs
+// @Produce json
+// @Param id path string true "Agent ID"
+// @Param instance query string false "sing-box实例名称，默认default"
+// @Param from query int true "起始版本"
+// @Param to query int false "目标版本，默认当前代"
+// @Success 200 {object} Response{data=ConfigDiffResponse}
+// @Router /api/v1/agents/{id}/config/diff [get]
+func (h *ConfigHandler) DiffGenerations(c *gin.Context) {
+	agentID := c.Param("id")
+
+	fromVersion, err := strconv.ParseInt(c.Query("from"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    400,
+			Message: "from参数无效",
+			Error:   err.Error(),
+		})
+		return
+	}
+	toVersion, err := strconv.ParseInt(c.DefaultQuery("to", "0"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    400,
+			Message: "to参数无效",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	diff, err := h.configService.DiffGenerations(agentID, c.Query("instance"), fromVersion, toVersion)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, Response{
+			Code:    500,
+			Message: "比较配置失败",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "success",
+		Data: ConfigDiffResponse{
+			FromVersion: fromVersion,
+			ToVersion:   toVersion,
+			Diff:        diff,
+		},
+	})
+}
+
+// RollbackConfig 按版本回滚配置
+// @Summary 按版本回滚配置
+// @Description 将Agent的过滤器、sing-box或DNS配置回滚到指定版本
+// @Tags configs
+// @Accept json
+// @Produce json
+// @Param id path string true "Agent ID"
+// @Param instance query string false "sing-box实例名称，默认default"
+// @Param request body ConfigRollbackRequest true "回滚参数"
+// @Success 200 {object} Response
+// @Router /api/v1/agents/{id}/config/rollback [post]
+func (h *ConfigHandler) RollbackConfig(c *gin.Context) {
+	agentID := c.Param("id")
+
+	var req ConfigRollbackRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    400,
+			Message: "请求参数错误",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	if err := h.configService.Rollback(agentID, c.Query("instance"), req.Scope, req.TargetVersion, req.Reason); err != nil {
+		c.JSON(http.StatusInternalServerError, Response{
+			Code:    500,
+			Message: "配置回滚失败",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "配置回滚成功",
+	})
+}
+
+// ValidateConfig 校验sing-box配置
+// @Summary 校验sing-box配置
+// @Description 对sing-box配置做语义校验（tag重复、引用缺失、detour成环、端口冲突、CIDR/端口格式、TLS证书），返回带路径的错误列表
+// @Tags configs
+// @Accept json
+// @Produce json
+// @Param request body ConfigContentRequest true "sing-box配置"
+// @Success 200 {object} Response
+// @Failure 400 {object} Response{data=singbox.ValidationErrors}
+// @Router /api/v1/configs/validate [post]
+func (h *ConfigHandler) ValidateConfig(c *gin.Context) {
+	var req ConfigContentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    400,
+			Message: "请求参数错误",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	if errs := h.configService.ValidateConfig(string(req.Config)); errs != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    400,
+			Message: "配置校验失败",
+			Data:    errs,
+			Error:   errs.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "配置校验通过",
+	})
+}
+
+// PushConfig 下发sing-box配置
+// @Summary 下发sing-box配置
+// @Description 校验通过后记录配置并下发到Agent，校验失败时返回400及带路径的错误列表。
+// @Description Agent在替换配置前检查入站端口占用，存在冲突时拒绝应用并返回409及冲突列表，force=true时忽略冲突
+// @Tags configs
+// @Accept json
+// @Produce json
+// @Param id path string true "Agent ID"
+// @Param instance query string false "sing-box实例名称，默认default"
+// @Param force query bool false "入站端口冲突时仍然应用"
+// @Param request body ConfigContentRequest true "sing-box配置"
+// @Success 200 {object} Response
+// @Failure 400 {object} Response{data=singbox.ValidationErrors}
+// @Failure 409 {object} Response{data=[]agent.PortConflict}
+// @Router /api/v1/agents/{id}/config [put]
+func (h *ConfigHandler) PushConfig(c *gin.Context) {
+	agentID := c.Param("id")
+
+	var req ConfigContentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    400,
+			Message: "请求参数错误",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	force := c.Query("force") == "true"
+	record, err := h.configService.PushConfig(agentID, c.Query("instance"), string(req.Config), force)
+	if err != nil {
+		var validationErrs singbox.ValidationErrors
+		if errors.As(err, &validationErrs) {
+			c.JSON(http.StatusBadRequest, Response{
+				Code:    400,
+				Message: "配置校验失败",
+				Data:    validationErrs,
+				Error:   err.Error(),
+			})
+			return
+		}
+		var conflictErr *service.PortConflictError
+		if errors.As(err, &conflictErr) {
+			c.JSON(http.StatusConflict, Response{
+				Code:    409,
+				Message: "入站端口已被占用，配置未应用",
+				Data:    conflictErr.Conflicts,
+				Error:   err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, Response{
+			Code:    500,
+			Message: "配置下发失败",
+			Data:    record,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "配置下发成功",
+		Data:    record,
+	})
+}
+
+// ImportConfig 导入Clash/V2Ray/Xray配置
+// @Summary 导入Clash/V2Ray/Xray配置
+// @Description 将Clash（含Clash-Meta）YAML或V2Ray/Xray JSON转换为sing-box配置，转换入站、出站、路由规则和DNS，返回转换后的配置及未能转换项的报告。
+// @Description 指定agent_ids时，转换结果校验通过后逐个下发，单个Agent失败不影响其他Agent；校验失败时返回400且不下发
+// @Tags configs
+// @Accept json
+// @Produce json
+// @Param request body ConfigImportRequest true "导入参数"
+// @Success 200 {object} Response{data=service.ImportResult}
+// @Failure 400 {object} Response{data=service.ImportResult}
+// @Router /api/v1/configs/import [post]
+func (h *ConfigHandler) ImportConfig(c *gin.Context) {
+	var req ConfigImportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    400,
+			Message: "请求参数错误",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	result, err := h.configService.ImportConfig(req.Format, []byte(req.Content), req.AgentIDs, req.Instance, req.Force)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    400,
+			Message: "配置转换失败",
+			Error:   err.Error(),
+		})
+		return
+	}
+	if result.ValidationErrors != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    400,
+			Message: "转换后的配置校验失败",
+			Data:    result,
+			Error:   result.ValidationErrors.Error(),
+		})
+		return
+	}
+
+	failed := 0
+	for _, item := range result.Results {
+		if !item.Success {
+			failed++
+		}
+	}
+	message := "配置转换成功"
+	if len(result.Results) > 0 {
+		message = "配置已导入并下发"
+	}
+	if failed > 0 {
+		message = fmt.Sprintf("%d 个Agent下发失败", failed)
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: message,
+		Data:    result,
+	})
+}
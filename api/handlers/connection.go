@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xbox/sing-box-manager/internal/controller/service"
+	pb "github.com/xbox/sing-box-manager/proto/agent"
+)
+
+// ConnectionHandler sing-box连接API处理器
+type ConnectionHandler struct {
+	connectionService service.ConnectionService
+}
+
+// NewConnectionHandler 创建连接处理器实例
+func NewConnectionHandler(connectionService service.ConnectionService) *ConnectionHandler {
+	return &ConnectionHandler{
+		connectionService: connectionService,
+	}
+}
+
+// CloseConnectionsRequest 关闭连接请求，各条件同时满足的连接会被关闭
+type CloseConnectionsRequest struct {
+	Host     string `json:"host"`      // 目标域名（含子域名）或目标IP
+	SourceIP string `json:"source_ip"` // 来源IP或CIDR
+	Rule     string `json:"rule"`      // 命中的规则（子串匹配）
+	Inbound  string `json:"inbound"`   // 入站tag
+	Outbound string `json:"outbound"`  // 出站tag
+	All      bool   `json:"all"`       // 未指定条件时需设置为true才会关闭全部连接
+}
+
+// GetConnectionStats 获取连接与流量统计
+// @Summary 获取连接与流量统计
+// @Description 返回Agent最近一次心跳上报的连接数与速率，按入站和出站tag聚合
+// @Tags connections
+// @Produce json
+// @Param id path string true "Agent ID"
+// @Param instance query string false "sing-box实例名称，默认default"
+// @Success 200 {object} Response
+// @Router /api/v1/agents/{id}/connections [get]
+func (h *ConnectionHandler) GetConnectionStats(c *gin.Context) {
+	stats, err := h.connectionService.GetStats(c.Param("id"), c.Query("instance"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, Response{
+			Code:    404,
+			Message: "获取连接统计失败",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "success",
+		Data:    stats,
+	})
+}
+
+// CloseConnections 关闭连接
+// @Summary 关闭连接
+// @Description 按目标域名/IP、来源IP、规则、入站或出站关闭Agent上的连接
+// @Tags connections
+// @Accept json
+// @Produce json
+// @Param id path string true "Agent ID"
+// @Param instance query string false "sing-box实例名称，默认default"
+// @Param request body CloseConnectionsRequest true "过滤条件"
+// @Success 200 {object} Response
+// @Router /api/v1/agents/{id}/connections/close [post]
+func (h *ConnectionHandler) CloseConnections(c *gin.Context) {
+	var req CloseConnectionsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    400,
+			Message: "请求参数错误",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	resp, err := h.connectionService.CloseConnections(&pb.CloseConnectionsRequest{
+		AgentId:  c.Param("id"),
+		Host:     req.Host,
+		SourceIp: req.SourceIP,
+		Rule:     req.Rule,
+		Inbound:  req.Inbound,
+		Outbound: req.Outbound,
+		All:      req.All,
+		Instance: c.Query("instance"),
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, Response{
+			Code:    500,
+			Message: "关闭连接失败",
+			Data:    resp,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: resp.Message,
+		Data:    resp,
+	})
+}
@@ -0,0 +1,271 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xbox/sing-box-manager/internal/controller/service"
+)
+
+// CredentialRotationHandler 凭据轮换API处理器
+type CredentialRotationHandler struct {
+	rotationService service.CredentialRotationService
+}
+
+// NewCredentialRotationHandler 创建凭据轮换处理器实例
+func NewCredentialRotationHandler(rotationService service.CredentialRotationService) *CredentialRotationHandler {
+	return &CredentialRotationHandler{
+		rotationService: rotationService,
+	}
+}
+
+// parseRotationID 解析路径中的轮换计划ID
+func parseRotationID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    400,
+			Message: "轮换计划ID无效",
+			Error:   err.Error(),
+		})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// CreateRotation 创建凭据轮换计划
+// @Summary 创建凭据轮换计划
+// @Description 按周期为匹配的入站用户生成新的uuid或密码并下发到各Agent，旧凭据以 <name>~old 保留至重叠期结束后吊销。首次轮换在一个周期后执行，可调用run接口立即执行
+// @Tags credential-rotations
+// @Accept json
+// @Produce json
+// @Param request body service.CredentialRotationRequest true "轮换计划"
+// @Success 200 {object} Response
+// @Router /api/v1/credential-rotations [post]
+func (h *CredentialRotationHandler) CreateRotation(c *gin.Context) {
+	var req service.CredentialRotationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    400,
+			Message: "请求参数错误",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	rotation, err := h.rotationService.CreateRotation(&req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    400,
+			Message: "创建凭据轮换计划失败",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "凭据轮换计划已创建",
+		Data:    rotation,
+	})
+}
+
+// ListRotations 获取凭据轮换计划列表
+// @Summary 获取凭据轮换计划列表
+// @Tags credential-rotations
+// @Produce json
+// @Success 200 {object} Response
+// @Router /api/v1/credential-rotations [get]
+func (h *CredentialRotationHandler) ListRotations(c *gin.Context) {
+	rotations, err := h.rotationService.ListRotations()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, Response{
+			Code:    500,
+			Message: "获取凭据轮换计划失败",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "success",
+		Data:    rotations,
+	})
+}
+
+// GetRotation 获取凭据轮换计划详情
+// @Summary 获取凭据轮换计划详情
+// @Tags credential-rotations
+// @Produce json
+// @Param id path int true "轮换计划ID"
+// @Success 200 {object} Response
+// @Failure 404 {object} Response
+// @Router /api/v1/credential-rotations/{id} [get]
+func (h *CredentialRotationHandler) GetRotation(c *gin.Context) {
+	id, ok := parseRotationID(c)
+	if !ok {
+		return
+	}
+
+	rotation, err := h.rotationService.GetRotation(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, Response{
+			Code:    404,
+			Message: "凭据轮换计划不存在",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "success",
+		Data:    rotation,
+	})
+}
+
+// UpdateRotation 修改凭据轮换计划
+// @Summary 修改凭据轮换计划
+// @Description 下次轮换时间从上次轮换起按新周期重新计算
+// @Tags credential-rotations
+// @Accept json
+// @Produce json
+// @Param id path int true "轮换计划ID"
+// @Param request body service.CredentialRotationRequest true "轮换计划"
+// @Success 200 {object} Response
+// @Router /api/v1/credential-rotations/{id} [put]
+func (h *CredentialRotationHandler) UpdateRotation(c *gin.Context) {
+	id, ok := parseRotationID(c)
+	if !ok {
+		return
+	}
+
+	var req service.CredentialRotationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    400,
+			Message: "请求参数错误",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	rotation, err := h.rotationService.UpdateRotation(id, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    400,
+			Message: "修改凭据轮换计划失败",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "凭据轮换计划已更新",
+		Data:    rotation,
+	})
+}
+
+// DeleteRotation 删除凭据轮换计划
+// @Summary 删除凭据轮换计划
+// @Description 已轮换的旧凭据仍按计划吊销
+// @Tags credential-rotations
+// @Produce json
+// @Param id path int true "轮换计划ID"
+// @Success 200 {object} Response
+// @Router /api/v1/credential-rotations/{id} [delete]
+func (h *CredentialRotationHandler) DeleteRotation(c *gin.Context) {
+	id, ok := parseRotationID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.rotationService.DeleteRotation(id); err != nil {
+		c.JSON(http.StatusNotFound, Response{
+			Code:    404,
+			Message: "删除凭据轮换计划失败",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "凭据轮换计划已删除",
+	})
+}
+
+// RunRotation 立即执行凭据轮换
+// @Summary 立即执行凭据轮换
+// @Description 对计划覆盖的每个Agent入站生成新凭据并下发，返回各入站的执行记录以及没有匹配入站或无法获取入站的Agent，每次轮换和吊销都记录在操作日志中
+// @Tags credential-rotations
+// @Produce json
+// @Param id path int true "轮换计划ID"
+// @Success 200 {object} Response
+// @Router /api/v1/credential-rotations/{id}/run [post]
+func (h *CredentialRotationHandler) RunRotation(c *gin.Context) {
+	id, ok := parseRotationID(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.rotationService.RunRotation(id, "api")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, Response{
+			Code:    500,
+			Message: "执行凭据轮换失败",
+			Data:    result,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "凭据轮换已执行",
+		Data:    result,
+	})
+}
+
+// ListRuns 获取凭据轮换执行记录
+// @Summary 获取凭据轮换执行记录
+// @Tags credential-rotations
+// @Produce json
+// @Param id path int true "轮换计划ID"
+// @Param limit query int false "返回条数，默认100"
+// @Success 200 {object} Response
+// @Router /api/v1/credential-rotations/{id}/runs [get]
+func (h *CredentialRotationHandler) ListRuns(c *gin.Context) {
+	id, ok := parseRotationID(c)
+	if !ok {
+		return
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    400,
+			Message: "limit参数无效",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	runs, err := h.rotationService.ListRuns(id, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, Response{
+			Code:    500,
+			Message: "获取凭据轮换记录失败",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "success",
+		Data:    runs,
+	})
+}
@@ -0,0 +1,194 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xbox/sing-box-manager/internal/controller/service"
+	pb "github.com/xbox/sing-box-manager/proto/agent"
+)
+
+// DNSHandler DNS配置API处理器
+type DNSHandler struct {
+	dnsService service.DNSService
+}
+
+// NewDNSHandler 创建DNS配置处理器实例
+func NewDNSHandler(dnsService service.DNSService) *DNSHandler {
+	return &DNSHandler{
+		dnsService: dnsService,
+	}
+}
+
+// DNSServersRequest DNS服务器更新请求
+type DNSServersRequest struct {
+	Operation string          `json:"operation" binding:"omitempty,oneof=add update remove move replace"`
+	Servers   json.RawMessage `json:"servers"`  // sing-box DNS服务器数组，add、update、replace时使用
+	Tags      []string        `json:"tags"`     // remove时为待删除的tag，move时为新的顺序
+	Position  int             `json:"position"` // add时插入的位置（从1开始），0表示追加到末尾
+	Final     string          `json:"final"`    // 默认DNS服务器tag
+	Strategy  string          `json:"strategy" binding:"omitempty,oneof=prefer_ipv4 prefer_ipv6 ipv4_only ipv6_only"`
+}
+
+// DNSRulesRequest DNS规则更新请求
+type DNSRulesRequest struct {
+	Operation string          `json:"operation" binding:"required,oneof=add remove move replace"`
+	Rules     json.RawMessage `json:"rules"`     // sing-box DNS规则数组，add、replace时使用
+	Positions []int           `json:"positions"` // remove时为待删除规则的位置，move时为被移动规则的位置（从1开始）
+	Position  int             `json:"position"`  // add时插入的位置，move时的目标位置（从1开始）
+}
+
+// FakeIPRequest FakeIP更新请求
+type FakeIPRequest struct {
+	Enabled    bool   `json:"enabled"`
+	Inet4Range string `json:"inet4_range"` // 为空时使用198.18.0.0/15
+	Inet6Range string `json:"inet6_range"` // 为空时不分配IPv6地址
+}
+
+// DNSConfigData DNS配置接口返回的数据
+type DNSConfigData struct {
+	DNS      json.RawMessage  `json:"dns,omitempty"`
+	Version  string           `json:"version"`
+	Versions []string         `json:"versions"`
+	Phases   []*pb.ApplyPhase `json:"phases,omitempty"`
+}
+
+// GetConfig 获取DNS配置
+// @Summary 获取DNS配置
+// @Description 获取Agent当前生效的DNS配置、版本和可回滚的历史版本
+// @Tags dns
+// @Produce json
+// @Param id path string true "Agent ID"
+// @Param instance query string false "sing-box实例名称，默认default"
+// @Success 200 {object} Response
+// @Router /api/v1/agents/{id}/dns [get]
+func (h *DNSHandler) GetConfig(c *gin.Context) {
+	resp, err := h.dnsService.GetConfig(c.Param("id"), c.Query("instance"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, Response{
+			Code:    500,
+			Message: "获取DNS配置失败",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "success",
+		Data:    newDNSConfigData(resp),
+	})
+}
+
+// UpdateServers 更新DNS服务器
+// @Summary 更新DNS服务器
+// @Description 增删、修改、移动或替换DNS服务器（local、UDP/TCP、DoT、DoH、DoQ、DHCP等），operation为空时只设置final和strategy
+// @Tags dns
+// @Accept json
+// @Produce json
+// @Param id path string true "Agent ID"
+// @Param instance query string false "sing-box实例名称，默认default"
+// @Param request body DNSServersRequest true "DNS服务器更新请求"
+// @Success 200 {object} Response
+// @Router /api/v1/agents/{id}/dns/servers [post]
+func (h *DNSHandler) UpdateServers(c *gin.Context) {
+	var req DNSServersRequest
+	if !bindDNSRequest(c, &req) {
+		return
+	}
+
+	resp, err := h.dnsService.UpdateServers(c.Param("id"), c.Query("instance"), req.Operation, req.Servers, req.Tags, req.Position, req.Final, req.Strategy)
+	respondDNSUpdate(c, "DNS服务器更新成功", "更新DNS服务器失败", resp, err)
+}
+
+// UpdateRules 更新DNS规则
+// @Summary 更新DNS规则
+// @Description 增删、移动或替换DNS规则，规则引用的服务器必须存在
+// @Tags dns
+// @Accept json
+// @Produce json
+// @Param id path string true "Agent ID"
+// @Param instance query string false "sing-box实例名称，默认default"
+// @Param request body DNSRulesRequest true "DNS规则更新请求"
+// @Success 200 {object} Response
+// @Router /api/v1/agents/{id}/dns/rules [post]
+func (h *DNSHandler) UpdateRules(c *gin.Context) {
+	var req DNSRulesRequest
+	if !bindDNSRequest(c, &req) {
+		return
+	}
+
+	resp, err := h.dnsService.UpdateRules(c.Param("id"), c.Query("instance"), req.Operation, req.Rules, req.Positions, req.Position)
+	respondDNSUpdate(c, "DNS规则更新成功", "更新DNS规则失败", resp, err)
+}
+
+// UpdateFakeIP 启用或关闭FakeIP
+// @Summary 启用或关闭FakeIP
+// @Description 启用时在缺少fakeip服务器时自动添加，关闭时移除fakeip服务器及引用它的DNS规则
+// @Tags dns
+// @Accept json
+// @Produce json
+// @Param id path string true "Agent ID"
+// @Param instance query string false "sing-box实例名称，默认default"
+// @Param request body FakeIPRequest true "FakeIP配置"
+// @Success 200 {object} Response
+// @Router /api/v1/agents/{id}/dns/fakeip [put]
+func (h *DNSHandler) UpdateFakeIP(c *gin.Context) {
+	var req FakeIPRequest
+	if !bindDNSRequest(c, &req) {
+		return
+	}
+
+	resp, err := h.dnsService.UpdateFakeIP(c.Param("id"), c.Query("instance"), req.Enabled, req.Inet4Range, req.Inet6Range)
+	respondDNSUpdate(c, "FakeIP更新成功", "更新FakeIP失败", resp, err)
+}
+
+// bindDNSRequest 解析请求体，失败时返回400
+func bindDNSRequest(c *gin.Context, req interface{}) bool {
+	if err := c.ShouldBindJSON(req); err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    400,
+			Message: "请求参数错误",
+			Error:   err.Error(),
+		})
+		return false
+	}
+	return true
+}
+
+// respondDNSUpdate 返回DNS更新结果，失败时附带Agent返回的应用阶段和当前配置
+func respondDNSUpdate(c *gin.Context, success, failure string, resp *pb.DNSConfigResponse, err error) {
+	if err != nil {
+		var data interface{}
+		if resp != nil {
+			data = newDNSConfigData(resp)
+		}
+		c.JSON(http.StatusInternalServerError, Response{
+			Code:    500,
+			Message: failure,
+			Data:    data,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: success,
+		Data:    newDNSConfigData(resp),
+	})
+}
+
+// newDNSConfigData 将Agent响应转换为接口数据，DNS配置以JSON对象返回
+func newDNSConfigData(resp *pb.DNSConfigResponse) *DNSConfigData {
+	data := &DNSConfigData{
+		Version:  resp.Version,
+		Versions: resp.Versions,
+		Phases:   resp.Phases,
+	}
+	if resp.DnsConfig != "" {
+		data.DNS = json.RawMessage(resp.DnsConfig)
+	}
+	return data
+}
@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xbox/sing-box-manager/internal/controller/service"
+	pb "github.com/xbox/sing-box-manager/proto/agent"
+)
+
+// InboundHandler 入站用户API处理器
+type InboundHandler struct {
+	inboundService service.InboundService
+}
+
+// NewInboundHandler 创建入站用户处理器实例
+func NewInboundHandler(inboundService service.InboundService) *InboundHandler {
+	return &InboundHandler{
+		inboundService: inboundService,
+	}
+}
+
+// InboundUserRequest 入站用户
+type InboundUserRequest struct {
+	Name     string `json:"name"`     // vmess, vless, trojan, shadowsocks, hysteria2, tuic
+	Username string `json:"username"` // mixed, http, socks
+	Password string `json:"password"`
+	UUID     string `json:"uuid"`
+	Flow     string `json:"flow"`
+	AlterID  int32  `json:"alter_id"`
+	Extra    string `json:"extra"` // 未建模的用户字段（JSON对象字符串），原样写入sing-box配置
+}
+
+// InboundUsersRequest 入站用户批量请求
+type InboundUsersRequest struct {
+	Users    []InboundUserRequest `json:"users"`
+	Revision string               `json:"revision"` // 查询时返回的修订，非空时入站用户已被修改则拒绝更新
+}
+
+// GetUsers 获取入站用户列表
+// @Summary 获取入站用户列表
+// @Description 获取Agent上指定入站的类型和用户列表
+// @Tags inbounds
+// @Produce json
+// @Param id path string true "Agent ID"
+// @Param instance query string false "sing-box实例名称，默认default"
+// @Param tag path string true "入站tag"
+// @Success 200 {object} Response
+// @Router /api/v1/agents/{id}/inbounds/{tag}/users [get]
+func (h *InboundHandler) GetUsers(c *gin.Context) {
+	resp, err := h.inboundService.GetUsers(c.Param("id"), c.Query("instance"), c.Param("tag"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, Response{
+			Code:    500,
+			Message: "获取入站用户失败",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "success",
+		Data:    resp,
+	})
+}
+
+// AddUsers 添加入站用户
+// @Summary 添加入站用户
+// @Tags inbounds
+// @Accept json
+// @Produce json
+// @Param id path string true "Agent ID"
+// @Param instance query string false "sing-box实例名称，默认default"
+// @Param tag path string true "入站tag"
+// @Param request body InboundUsersRequest true "用户列表"
+// @Success 200 {object} Response
+// @Failure 409 {object} Response
+// @Router /api/v1/agents/{id}/inbounds/{tag}/users [post]
+func (h *InboundHandler) AddUsers(c *gin.Context) {
+	h.updateUsers(c, "add")
+}
+
+// ReplaceUsers 替换入站全部用户
+// @Summary 替换入站全部用户
+// @Tags inbounds
+// @Accept json
+// @Produce json
+// @Param id path string true "Agent ID"
+// @Param instance query string false "sing-box实例名称，默认default"
+// @Param tag path string true "入站tag"
+// @Param request body InboundUsersRequest true "用户列表"
+// @Success 200 {object} Response
+// @Failure 409 {object} Response
+// @Router /api/v1/agents/{id}/inbounds/{tag}/users [put]
+func (h *InboundHandler) ReplaceUsers(c *gin.Context) {
+	h.updateUsers(c, "replace")
+}
+
+// RemoveUsers 删除入站用户
+// @Summary 删除入站用户
+// @Description 按name（mixed/http/socks为username）删除用户，携带uuid或password时需同时匹配
+// @Tags inbounds
+// @Accept json
+// @Produce json
+// @Param id path string true "Agent ID"
+// @Param instance query string false "sing-box实例名称，默认default"
+// @Param tag path string true "入站tag"
+// @Param request body InboundUsersRequest true "用户列表"
+// @Success 200 {object} Response
+// @Failure 409 {object} Response
+// @Router /api/v1/agents/{id}/inbounds/{tag}/users/remove [post]
+func (h *InboundHandler) RemoveUsers(c *gin.Context) {
+	h.updateUsers(c, "remove")
+}
+
+// updateUsers 执行入站用户更新
+func (h *InboundHandler) updateUsers(c *gin.Context, operation string) {
+	var req InboundUsersRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    400,
+			Message: "请求参数错误",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	users := make([]*pb.InboundUser, 0, len(req.Users))
+	for _, user := range req.Users {
+		users = append(users, &pb.InboundUser{
+			Name:     user.Name,
+			Username: user.Username,
+			Password: user.Password,
+			Uuid:     user.UUID,
+			Flow:     user.Flow,
+			AlterId:  user.AlterID,
+			Extra:    user.Extra,
+		})
+	}
+
+	resp, err := h.inboundService.UpdateUsers(c.Param("id"), c.Query("instance"), c.Param("tag"), operation, req.Revision, users)
+	if err != nil {
+		if errors.Is(err, service.ErrInboundUsersChanged) {
+			c.JSON(http.StatusConflict, Response{
+				Code:    409,
+				Message: "入站用户已被修改，请重新获取后重试",
+				Data:    resp,
+				Error:   err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, Response{
+			Code:    500,
+			Message: "更新入站用户失败",
+			Data:    resp,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "入站用户更新成功",
+		Data:    resp,
+	})
+}
@@ -0,0 +1,151 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xbox/sing-box-manager/internal/controller/service"
+	pb "github.com/xbox/sing-box-manager/proto/agent"
+)
+
+const (
+	defaultLogTail    = 100              // 默认返回的日志行数
+	maxLogTail        = 5000             // 单次最多返回的日志行数
+	sseKeepaliveEvery = 15 * time.Second // SSE保活间隔
+)
+
+// LogHandler sing-box日志API处理器
+type LogHandler struct {
+	logService service.LogService
+}
+
+// NewLogHandler 创建日志处理器实例
+func NewLogHandler(logService service.LogService) *LogHandler {
+	return &LogHandler{
+		logService: logService,
+	}
+}
+
+// GetSingboxLogs 查看sing-box日志
+// @Summary 查看sing-box日志
+// @Description 返回Agent上缓冲的sing-box日志；follow=true时以SSE持续推送新日志
+// @Tags agents
+// @Produce json
+// @Produce text/event-stream
+// @Param id path string true "Agent ID"
+// @Param instance query string false "sing-box实例名称，默认default"
+// @Param level query string false "最低日志级别: trace, debug, info, warn, error, fatal, panic"
+// @Param keyword query string false "关键字过滤"
+// @Param tail query int false "最近的行数，默认100"
+// @Param follow query bool false "是否持续推送"
+// @Success 200 {object} Response
+// @Router /api/v1/agents/{id}/logs [get]
+func (h *LogHandler) GetSingboxLogs(c *gin.Context) {
+	agentID := c.Param("id")
+
+	tail, err := strconv.Atoi(c.DefaultQuery("tail", strconv.Itoa(defaultLogTail)))
+	if err != nil || tail < 0 || tail > maxLogTail {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    400,
+			Message: "tail参数无效",
+		})
+		return
+	}
+
+	opts := service.LogStreamOptions{
+		Instance: c.Query("instance"),
+		Level:    c.Query("level"),
+		Keyword:  c.Query("keyword"),
+		Tail:     tail,
+		Follow:   c.Query("follow") == "true",
+	}
+
+	if opts.Follow {
+		h.streamLogs(c, agentID, opts)
+		return
+	}
+
+	entries := make([]*pb.SingboxLogEntry, 0, tail)
+	err = h.logService.StreamSingboxLogs(c.Request.Context(), agentID, opts, func(entry *pb.SingboxLogEntry) error {
+		entries = append(entries, entry)
+		return nil
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, Response{
+			Code:    500,
+			Message: "获取sing-box日志失败",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "success",
+		Data:    entries,
+	})
+}
+
+// streamLogs 以SSE持续推送日志，直到客户端断开或Agent结束推送
+func (h *LogHandler) streamLogs(c *gin.Context, agentID string, opts service.LogStreamOptions) {
+	// 日志流是长连接，解除HTTP服务器的写超时
+	if err := http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{}); err != nil {
+		c.JSON(http.StatusInternalServerError, Response{
+			Code:    500,
+			Message: "当前连接不支持日志流",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx := c.Request.Context()
+	entries := make(chan *pb.SingboxLogEntry, 64)
+	done := make(chan error, 1)
+	go func() {
+		done <- h.logService.StreamSingboxLogs(ctx, agentID, opts, func(entry *pb.SingboxLogEntry) error {
+			select {
+			case entries <- entry:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		})
+	}()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+
+	keepalive := time.NewTicker(sseKeepaliveEvery)
+	defer keepalive.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case entry := <-entries:
+			c.SSEvent("log", entry)
+			c.Writer.Flush()
+		case <-keepalive.C:
+			c.Writer.WriteString(": keepalive\n\n")
+			c.Writer.Flush()
+		case err := <-done:
+			// 推送结束前先发送已缓冲的日志
+			for len(entries) > 0 {
+				c.SSEvent("log", <-entries)
+			}
+			if err != nil {
+				c.SSEvent("error", gin.H{"message": err.Error()})
+			} else {
+				c.SSEvent("end", gin.H{"message": "日志流已结束"})
+			}
+			c.Writer.Flush()
+			return
+		}
+	}
+}
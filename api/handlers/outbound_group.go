@@ -0,0 +1,245 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xbox/sing-box-manager/internal/controller/service"
+	pb "github.com/xbox/sing-box-manager/proto/agent"
+)
+
+// OutboundGroupHandler 出站组API处理器
+type OutboundGroupHandler struct {
+	groupService service.OutboundGroupService
+}
+
+// NewOutboundGroupHandler 创建出站组处理器实例
+func NewOutboundGroupHandler(groupService service.OutboundGroupService) *OutboundGroupHandler {
+	return &OutboundGroupHandler{
+		groupService: groupService,
+	}
+}
+
+// OutboundGroupRequest 出站组
+type OutboundGroupRequest struct {
+	Tag                       string   `json:"tag"`
+	Type                      string   `json:"type" binding:"required,oneof=selector urltest"`
+	Outbounds                 []string `json:"outbounds" binding:"required,min=1"`
+	Default                   string   `json:"default"`      // selector默认成员
+	URL                       string   `json:"url"`          // urltest测试地址
+	Interval                  string   `json:"interval"`     // urltest测试间隔，如3m
+	Tolerance                 uint32   `json:"tolerance"`    // urltest切换容差（毫秒）
+	IdleTimeout               string   `json:"idle_timeout"` // urltest空闲超时
+	InterruptExistConnections bool     `json:"interrupt_exist_connections"`
+}
+
+// GroupMembersRequest 出站组成员请求
+type GroupMembersRequest struct {
+	Members []string `json:"members" binding:"required,min=1"`
+}
+
+// GetGroups 获取出站组
+// @Summary 获取出站组
+// @Description 获取Agent上的selector/urltest出站组，Clash API可用时附带当前选择的成员和成员延迟
+// @Tags outbound-groups
+// @Produce json
+// @Param id path string true "Agent ID"
+// @Param instance query string false "sing-box实例名称，默认default"
+// @Param tag query string false "出站组tag，为空时返回全部"
+// @Param test query bool false "查询前对成员测试一次延迟"
+// @Param url query string false "延迟测试地址，默认使用组的url"
+// @Param timeout query int false "单个成员的测试超时（毫秒），默认5000"
+// @Success 200 {object} Response
+// @Router /api/v1/agents/{id}/outbound-groups [get]
+func (h *OutboundGroupHandler) GetGroups(c *gin.Context) {
+	timeout, err := strconv.Atoi(c.DefaultQuery("timeout", "0"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    400,
+			Message: "timeout参数无效",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	resp, err := h.groupService.GetGroups(c.Param("id"), c.Query("instance"), c.Query("tag"), c.Query("test") == "true", c.Query("url"), timeout)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, Response{
+			Code:    500,
+			Message: "获取出站组失败",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "success",
+		Data:    resp,
+	})
+}
+
+// CreateGroup 创建出站组
+// @Summary 创建出站组
+// @Tags outbound-groups
+// @Accept json
+// @Produce json
+// @Param id path string true "Agent ID"
+// @Param instance query string false "sing-box实例名称，默认default"
+// @Param request body OutboundGroupRequest true "出站组"
+// @Success 200 {object} Response
+// @Router /api/v1/agents/{id}/outbound-groups [post]
+func (h *OutboundGroupHandler) CreateGroup(c *gin.Context) {
+	var req OutboundGroupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    400,
+			Message: "请求参数错误",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	resp, err := h.groupService.UpdateGroup(c.Param("id"), c.Query("instance"), "create", req.toPB())
+	respondGroupUpdate(c, "出站组创建成功", "创建出站组失败", resp, err)
+}
+
+// UpdateGroup 修改出站组
+// @Summary 修改出站组
+// @Description 以请求内容整体替换出站组，tag取自路径
+// @Tags outbound-groups
+// @Accept json
+// @Produce json
+// @Param id path string true "Agent ID"
+// @Param instance query string false "sing-box实例名称，默认default"
+// @Param tag path string true "出站组tag"
+// @Param request body OutboundGroupRequest true "出站组"
+// @Success 200 {object} Response
+// @Router /api/v1/agents/{id}/outbound-groups/{tag} [put]
+func (h *OutboundGroupHandler) UpdateGroup(c *gin.Context) {
+	var req OutboundGroupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    400,
+			Message: "请求参数错误",
+			Error:   err.Error(),
+		})
+		return
+	}
+	req.Tag = c.Param("tag")
+
+	resp, err := h.groupService.UpdateGroup(c.Param("id"), c.Query("instance"), "update", req.toPB())
+	respondGroupUpdate(c, "出站组修改成功", "修改出站组失败", resp, err)
+}
+
+// DeleteGroup 删除出站组
+// @Summary 删除出站组
+// @Description 出站组仍被路由规则或其他出站引用时删除失败
+// @Tags outbound-groups
+// @Produce json
+// @Param id path string true "Agent ID"
+// @Param instance query string false "sing-box实例名称，默认default"
+// @Param tag path string true "出站组tag"
+// @Success 200 {object} Response
+// @Router /api/v1/agents/{id}/outbound-groups/{tag} [delete]
+func (h *OutboundGroupHandler) DeleteGroup(c *gin.Context) {
+	resp, err := h.groupService.UpdateGroup(c.Param("id"), c.Query("instance"), "delete", &pb.OutboundGroup{Tag: c.Param("tag")})
+	respondGroupUpdate(c, "出站组删除成功", "删除出站组失败", resp, err)
+}
+
+// AddMembers 添加出站组成员
+// @Summary 添加出站组成员
+// @Tags outbound-groups
+// @Accept json
+// @Produce json
+// @Param id path string true "Agent ID"
+// @Param instance query string false "sing-box实例名称，默认default"
+// @Param tag path string true "出站组tag"
+// @Param request body GroupMembersRequest true "成员列表"
+// @Success 200 {object} Response
+// @Router /api/v1/agents/{id}/outbound-groups/{tag}/members [post]
+func (h *OutboundGroupHandler) AddMembers(c *gin.Context) {
+	h.updateMembers(c, "add")
+}
+
+// ReplaceMembers 替换出站组全部成员
+// @Summary 替换出站组全部成员
+// @Tags outbound-groups
+// @Accept json
+// @Produce json
+// @Param id path string true "Agent ID"
+// @Param instance query string false "sing-box实例名称，默认default"
+// @Param tag path string true "出站组tag"
+// @Param request body GroupMembersRequest true "成员列表"
+// @Success 200 {object} Response
+// @Router /api/v1/agents/{id}/outbound-groups/{tag}/members [put]
+func (h *OutboundGroupHandler) ReplaceMembers(c *gin.Context) {
+	h.updateMembers(c, "replace")
+}
+
+// RemoveMembers 删除出站组成员
+// @Summary 删除出站组成员
+// @Description 删除selector的默认成员时同时清除default
+// @Tags outbound-groups
+// @Accept json
+// @Produce json
+// @Param id path string true "Agent ID"
+// @Param instance query string false "sing-box实例名称，默认default"
+// @Param tag path string true "出站组tag"
+// @Param request body GroupMembersRequest true "成员列表"
+// @Success 200 {object} Response
+// @Router /api/v1/agents/{id}/outbound-groups/{tag}/members/remove [post]
+func (h *OutboundGroupHandler) RemoveMembers(c *gin.Context) {
+	h.updateMembers(c, "remove")
+}
+
+// updateMembers 执行出站组成员更新
+func (h *OutboundGroupHandler) updateMembers(c *gin.Context, operation string) {
+	var req GroupMembersRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    400,
+			Message: "请求参数错误",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	resp, err := h.groupService.UpdateMembers(c.Param("id"), c.Query("instance"), c.Param("tag"), operation, req.Members)
+	respondGroupUpdate(c, "出站组成员更新成功", "更新出站组成员失败", resp, err)
+}
+
+// respondGroupUpdate 返回出站组更新结果，失败时附带Agent返回的应用阶段
+func respondGroupUpdate(c *gin.Context, success, failure string, resp *pb.OutboundGroupResponse, err error) {
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, Response{
+			Code:    500,
+			Message: failure,
+			Data:    resp,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: success,
+		Data:    resp,
+	})
+}
+
+// toPB 转换为protobuf出站组
+func (r *OutboundGroupRequest) toPB() *pb.OutboundGroup {
+	return &pb.OutboundGroup{
+		Tag:                       r.Tag,
+		Type:                      r.Type,
+		Outbounds:                 r.Outbounds,
+		Default:                   r.Default,
+		Url:                       r.URL,
+		Interval:                  r.Interval,
+		Tolerance:                 r.Tolerance,
+		IdleTimeout:               r.IdleTimeout,
+		InterruptExistConnections: r.InterruptExistConnections,
+	}
+}
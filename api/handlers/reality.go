@@ -0,0 +1,268 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xbox/sing-box-manager/internal/controller/service"
+)
+
+// RealityHandler Reality密钥托管API处理器
+type RealityHandler struct {
+	realityService service.RealityService
+}
+
+// NewRealityHandler 创建Reality密钥处理器实例
+func NewRealityHandler(realityService service.RealityService) *RealityHandler {
+	return &RealityHandler{
+		realityService: realityService,
+	}
+}
+
+// RealityScheduleRequest 修改轮换周期请求
+type RealityScheduleRequest struct {
+	RotationDays int `json:"rotation_days" binding:"min=0"` // 自动轮换周期（天），0为不自动轮换
+}
+
+// parseRealityKeyID 解析路径中的密钥ID
+func parseRealityKeyID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    400,
+			Message: "密钥ID无效",
+			Error:   err.Error(),
+		})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// CreateKey 托管Reality密钥
+// @Summary 托管Reality密钥
+// @Description 为Agent入站生成X25519密钥对和short_id，渲染进该实例最近一次成功下发的配置并下发。私钥加密保存，客户端TLS配置随响应返回，订阅输出随下发的配置同步更新
+// @Tags reality-keys
+// @Accept json
+// @Produce json
+// @Param request body service.RealityKeyRequest true "托管参数"
+// @Success 200 {object} Response
+// @Router /api/v1/reality-keys [post]
+func (h *RealityHandler) CreateKey(c *gin.Context) {
+	var req service.RealityKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    400,
+			Message: "请求参数错误",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	key, err := h.realityService.CreateKey(&req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    400,
+			Message: "托管Reality密钥失败",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "Reality密钥已生成并下发",
+		Data:    key,
+	})
+}
+
+// ListKeys 获取Reality密钥列表
+// @Summary 获取Reality密钥列表
+// @Tags reality-keys
+// @Produce json
+// @Param agent_id query string false "Agent ID，为空时返回全部"
+// @Success 200 {object} Response
+// @Router /api/v1/reality-keys [get]
+func (h *RealityHandler) ListKeys(c *gin.Context) {
+	keys, err := h.realityService.ListKeys(c.Query("agent_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, Response{
+			Code:    500,
+			Message: "获取Reality密钥列表失败",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "success",
+		Data:    keys,
+	})
+}
+
+// GetKey 获取Reality密钥详情
+// @Summary 获取Reality密钥详情
+// @Description 返回公钥、short_id、轮换计划和客户端TLS配置，私钥不会返回
+// @Tags reality-keys
+// @Produce json
+// @Param id path int true "密钥ID"
+// @Success 200 {object} Response
+// @Failure 404 {object} Response
+// @Router /api/v1/reality-keys/{id} [get]
+func (h *RealityHandler) GetKey(c *gin.Context) {
+	id, ok := parseRealityKeyID(c)
+	if !ok {
+		return
+	}
+
+	key, err := h.realityService.GetKey(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, Response{
+			Code:    404,
+			Message: "Reality密钥不存在",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "success",
+		Data:    key,
+	})
+}
+
+// UpdateSchedule 修改轮换周期
+// @Summary 修改Reality密钥轮换周期
+// @Description 下次轮换时间从上次轮换起计算，0为不自动轮换
+// @Tags reality-keys
+// @Accept json
+// @Produce json
+// @Param id path int true "密钥ID"
+// @Param request body RealityScheduleRequest true "轮换周期"
+// @Success 200 {object} Response
+// @Router /api/v1/reality-keys/{id} [put]
+func (h *RealityHandler) UpdateSchedule(c *gin.Context) {
+	id, ok := parseRealityKeyID(c)
+	if !ok {
+		return
+	}
+
+	var req RealityScheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    400,
+			Message: "请求参数错误",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	key, err := h.realityService.UpdateSchedule(id, req.RotationDays)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    400,
+			Message: "修改轮换周期失败",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "轮换周期已更新",
+		Data:    key,
+	})
+}
+
+// RotateKey 立即轮换Reality密钥
+// @Summary 立即轮换Reality密钥
+// @Description 生成新的密钥对和short_id并随配置下发，下发失败时Agent和订阅继续使用当前密钥
+// @Tags reality-keys
+// @Produce json
+// @Param id path int true "密钥ID"
+// @Success 200 {object} Response
+// @Router /api/v1/reality-keys/{id}/rotate [post]
+func (h *RealityHandler) RotateKey(c *gin.Context) {
+	id, ok := parseRealityKeyID(c)
+	if !ok {
+		return
+	}
+
+	key, err := h.realityService.RotateKey(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, Response{
+			Code:    500,
+			Message: "轮换Reality密钥失败",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "Reality密钥已轮换",
+		Data:    key,
+	})
+}
+
+// ReapplyKey 重新下发Reality密钥
+// @Summary 重新下发Reality密钥
+// @Description 将当前托管的密钥重新渲染进最近一次成功下发的配置并下发，用于配置被模板或手工下发覆盖后恢复
+// @Tags reality-keys
+// @Produce json
+// @Param id path int true "密钥ID"
+// @Success 200 {object} Response
+// @Router /api/v1/reality-keys/{id}/apply [post]
+func (h *RealityHandler) ReapplyKey(c *gin.Context) {
+	id, ok := parseRealityKeyID(c)
+	if !ok {
+		return
+	}
+
+	key, err := h.realityService.ReapplyKey(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, Response{
+			Code:    500,
+			Message: "重新下发Reality密钥失败",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "Reality密钥已重新下发",
+		Data:    key,
+	})
+}
+
+// DeleteKey 停止托管Reality密钥
+// @Summary 停止托管Reality密钥
+// @Description 删除托管记录和加密的私钥，Agent配置中的密钥保持不变
+// @Tags reality-keys
+// @Produce json
+// @Param id path int true "密钥ID"
+// @Success 200 {object} Response
+// @Router /api/v1/reality-keys/{id} [delete]
+func (h *RealityHandler) DeleteKey(c *gin.Context) {
+	id, ok := parseRealityKeyID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.realityService.DeleteKey(id); err != nil {
+		c.JSON(http.StatusNotFound, Response{
+			Code:    404,
+			Message: "停止托管Reality密钥失败",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "Reality密钥已停止托管",
+	})
+}
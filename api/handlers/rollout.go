@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xbox/sing-box-manager/internal/controller/service"
+)
+
+// RolloutHandler sing-box版本切换API处理器
+type RolloutHandler struct {
+	rolloutService service.RolloutService
+}
+
+// NewRolloutHandler 创建版本切换处理器实例
+func NewRolloutHandler(rolloutService service.RolloutService) *RolloutHandler {
+	return &RolloutHandler{
+		rolloutService: rolloutService,
+	}
+}
+
+// StartRollout 创建sing-box版本切换任务
+// @Summary 创建sing-box版本切换任务
+// @Description 将一批Agent的sing-box切换到指定版本（升级或降级）。Agent并行安装目标版本，用其校验当前配置后替换二进制并重启，健康探测失败时自动恢复原版本。任务在后台按max_parallel并发执行，失败数超过max_failures后剩余Agent标记为skipped
+// @Tags singbox
+// @Accept json
+// @Produce json
+// @Param request body service.RolloutRequest true "切换请求"
+// @Success 202 {object} Response
+// @Router /api/v1/singbox/upgrades [post]
+func (h *RolloutHandler) StartRollout(c *gin.Context) {
+	var req service.RolloutRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    400,
+			Message: "请求参数错误",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	rollout, err := h.rolloutService.StartRollout(&req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    400,
+			Message: "创建版本切换任务失败",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, Response{
+		Code:    202,
+		Message: "版本切换任务已创建",
+		Data:    rollout,
+	})
+}
+
+// ListRollouts 获取sing-box版本切换任务列表
+// @Summary 获取sing-box版本切换任务列表
+// @Description 按创建时间倒序返回最近的版本切换任务
+// @Tags singbox
+// @Produce json
+// @Param limit query int false "返回数量，默认20"
+// @Success 200 {object} Response
+// @Router /api/v1/singbox/upgrades [get]
+func (h *RolloutHandler) ListRollouts(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+
+	rollouts, err := h.rolloutService.ListRollouts(limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, Response{
+			Code:    500,
+			Message: "获取版本切换任务失败",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "success",
+		Data:    rollouts,
+	})
+}
+
+// GetRollout 获取sing-box版本切换任务详情
+// @Summary 获取sing-box版本切换任务详情
+// @Description 返回任务汇总和每个Agent的切换状态（pending/running/succeeded/rolled_back/failed/skipped）及切换前后版本
+// @Tags singbox
+// @Produce json
+// @Param id path int true "任务ID"
+// @Success 200 {object} Response
+// @Failure 404 {object} Response
+// @Router /api/v1/singbox/upgrades/{id} [get]
+func (h *RolloutHandler) GetRollout(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    400,
+			Message: "任务ID无效",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	rollout, err := h.rolloutService.GetRollout(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, Response{
+			Code:    404,
+			Message: "版本切换任务不存在",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "success",
+		Data:    rollout,
+	})
+}
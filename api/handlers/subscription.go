@@ -0,0 +1,276 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xbox/sing-box-manager/internal/controller/service"
+)
+
+// SubscriptionHandler 客户端订阅API处理器
+type SubscriptionHandler struct {
+	subscriptionService service.SubscriptionService
+}
+
+// NewSubscriptionHandler 创建客户端订阅处理器实例
+func NewSubscriptionHandler(subscriptionService service.SubscriptionService) *SubscriptionHandler {
+	return &SubscriptionHandler{
+		subscriptionService: subscriptionService,
+	}
+}
+
+// parseSubscriptionID 解析路径中的订阅ID
+func parseSubscriptionID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    400,
+			Message: "订阅ID无效",
+			Error:   err.Error(),
+		})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// CreateSubscription 创建订阅
+// @Summary 创建订阅
+// @Description 为入站用户创建订阅并生成令牌。用户在各Agent入站users中的name（mixed/http/socks为username）需与user_name一致，可按国家或地区、Agent和入站tag限定授权范围
+// @Tags subscriptions
+// @Accept json
+// @Produce json
+// @Param request body service.SubscriptionRequest true "订阅参数"
+// @Success 200 {object} Response
+// @Router /api/v1/subscriptions [post]
+func (h *SubscriptionHandler) CreateSubscription(c *gin.Context) {
+	var req service.SubscriptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    400,
+			Message: "请求参数错误",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	sub, err := h.subscriptionService.CreateSubscription(&req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, Response{
+			Code:    500,
+			Message: "创建订阅失败",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "订阅已创建",
+		Data:    sub,
+	})
+}
+
+// ListSubscriptions 获取订阅列表
+// @Summary 获取订阅列表
+// @Tags subscriptions
+// @Produce json
+// @Success 200 {object} Response
+// @Router /api/v1/subscriptions [get]
+func (h *SubscriptionHandler) ListSubscriptions(c *gin.Context) {
+	subs, err := h.subscriptionService.ListSubscriptions()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, Response{
+			Code:    500,
+			Message: "获取订阅列表失败",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "success",
+		Data:    subs,
+	})
+}
+
+// GetSubscription 获取订阅详情
+// @Summary 获取订阅详情
+// @Tags subscriptions
+// @Produce json
+// @Param id path int true "订阅ID"
+// @Success 200 {object} Response
+// @Failure 404 {object} Response
+// @Router /api/v1/subscriptions/{id} [get]
+func (h *SubscriptionHandler) GetSubscription(c *gin.Context) {
+	id, ok := parseSubscriptionID(c)
+	if !ok {
+		return
+	}
+
+	sub, err := h.subscriptionService.GetSubscription(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, Response{
+			Code:    404,
+			Message: "订阅不存在",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "success",
+		Data:    sub,
+	})
+}
+
+// UpdateSubscription 修改订阅
+// @Summary 修改订阅
+// @Description 修改订阅的用户标识、启用状态、授权范围和过期时间，令牌不变
+// @Tags subscriptions
+// @Accept json
+// @Produce json
+// @Param id path int true "订阅ID"
+// @Param request body service.SubscriptionRequest true "订阅参数"
+// @Success 200 {object} Response
+// @Router /api/v1/subscriptions/{id} [put]
+func (h *SubscriptionHandler) UpdateSubscription(c *gin.Context) {
+	id, ok := parseSubscriptionID(c)
+	if !ok {
+		return
+	}
+
+	var req service.SubscriptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    400,
+			Message: "请求参数错误",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	sub, err := h.subscriptionService.UpdateSubscription(id, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    400,
+			Message: "修改订阅失败",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "订阅已更新",
+		Data:    sub,
+	})
+}
+
+// DeleteSubscription 删除订阅
+// @Summary 删除订阅
+// @Tags subscriptions
+// @Produce json
+// @Param id path int true "订阅ID"
+// @Success 200 {object} Response
+// @Router /api/v1/subscriptions/{id} [delete]
+func (h *SubscriptionHandler) DeleteSubscription(c *gin.Context) {
+	id, ok := parseSubscriptionID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.subscriptionService.DeleteSubscription(id); err != nil {
+		c.JSON(http.StatusNotFound, Response{
+			Code:    404,
+			Message: "删除订阅失败",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "订阅已删除",
+	})
+}
+
+// ResetToken 重置订阅令牌
+// @Summary 重置订阅令牌
+// @Description 重新生成订阅令牌，旧的订阅地址立即失效
+// @Tags subscriptions
+// @Produce json
+// @Param id path int true "订阅ID"
+// @Success 200 {object} Response
+// @Router /api/v1/subscriptions/{id}/reset-token [post]
+func (h *SubscriptionHandler) ResetToken(c *gin.Context) {
+	id, ok := parseSubscriptionID(c)
+	if !ok {
+		return
+	}
+
+	sub, err := h.subscriptionService.ResetToken(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, Response{
+			Code:    500,
+			Message: "重置订阅令牌失败",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "订阅令牌已重置",
+		Data:    sub,
+	})
+}
+
+// GetSubscriptionContent 获取订阅内容
+// @Summary 获取订阅内容
+// @Description 按令牌返回用户可用节点，节点来自各Agent最近一次成功下发的配置。format为links（vmess/vless/trojan/ss/hysteria2分享链接）、singbox或clash（Clash-Meta），mode为base64或raw，分享链接默认base64，客户端配置默认raw
+// @Tags subscriptions
+// @Produce plain
+// @Param token path string true "订阅令牌"
+// @Param format query string false "输出格式：links、singbox、clash，默认links"
+// @Param mode query string false "输出模式：base64、raw"
+// @Param region query string false "按国家或地区过滤，多个用逗号分隔"
+// @Success 200 {string} string "订阅内容"
+// @Failure 404 {object} Response
+// @Router /sub/{token} [get]
+func (h *SubscriptionHandler) GetSubscriptionContent(c *gin.Context) {
+	opts := service.SubscriptionOptions{
+		Format: c.Query("format"),
+		Mode:   c.Query("mode"),
+	}
+	if region := c.Query("region"); region != "" {
+		opts.Regions = strings.Split(region, ",")
+	}
+
+	content, err := h.subscriptionService.Render(c.Param("token"), opts)
+	if err != nil {
+		if errors.Is(err, service.ErrSubscriptionNotFound) {
+			c.JSON(http.StatusNotFound, Response{
+				Code:    404,
+				Message: "订阅不存在",
+				Error:   err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    400,
+			Message: "生成订阅失败",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	if content.Filename != "" {
+		c.Header("Content-Disposition", "attachment; filename="+content.Filename)
+	}
+	c.Data(http.StatusOK, content.ContentType, content.Data)
+}
@@ -0,0 +1,411 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xbox/sing-box-manager/internal/controller/service"
+)
+
+// TemplateHandler sing-box配置模板API处理器
+type TemplateHandler struct {
+	templateService service.TemplateService
+}
+
+// NewTemplateHandler 创建配置模板处理器实例
+func NewTemplateHandler(templateService service.TemplateService) *TemplateHandler {
+	return &TemplateHandler{
+		templateService: templateService,
+	}
+}
+
+// TemplateRequest 创建或修改配置模板请求
+type TemplateRequest struct {
+	Name        string `json:"name"` // 模板名称，创建时必填，修改时忽略
+	Description string `json:"description"`
+	Content     string `json:"content" binding:"required"` // text/template格式的sing-box配置
+}
+
+// TemplatePreviewRequest 模板预览请求
+type TemplatePreviewRequest struct {
+	AgentIDs []string `json:"agent_ids" binding:"required,min=1"`
+	Revision int      `json:"revision"` // 修订版本，默认当前版本
+}
+
+// TemplateApplyRequest 模板下发请求
+type TemplateApplyRequest struct {
+	AgentIDs []string `json:"agent_ids" binding:"required,min=1"`
+	Revision int      `json:"revision"` // 修订版本，默认当前版本
+	Instance string   `json:"instance"` // sing-box实例名称，默认default
+	Force    bool     `json:"force"`    // 忽略端口冲突
+}
+
+// parseTemplateID 解析路径中的模板ID
+func parseTemplateID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    400,
+			Message: "模板ID无效",
+			Error:   err.Error(),
+		})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// CreateTemplate 创建配置模板
+// @Summary 创建配置模板
+// @Description 创建sing-box配置模板（Go text/template），可引用.Agent（ID、Hostname、IP、IPRange、Country、Region、City、ISP、Version）、.Labels（Agent元数据）和.Vars（Agent模板变量），并可使用json、default、required函数
+// @Tags templates
+// @Accept json
+// @Produce json
+// @Param request body TemplateRequest true "模板内容"
+// @Success 200 {object} Response
+// @Router /api/v1/templates [post]
+func (h *TemplateHandler) CreateTemplate(c *gin.Context) {
+	var req TemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    400,
+			Message: "请求参数错误",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	tmpl, err := h.templateService.CreateTemplate(req.Name, req.Description, req.Content)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    400,
+			Message: "创建配置模板失败",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "配置模板已创建",
+		Data:    tmpl,
+	})
+}
+
+// ListTemplates 获取配置模板列表
+// @Summary 获取配置模板列表
+// @Tags templates
+// @Produce json
+// @Success 200 {object} Response
+// @Router /api/v1/templates [get]
+func (h *TemplateHandler) ListTemplates(c *gin.Context) {
+	templates, err := h.templateService.ListTemplates()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, Response{
+			Code:    500,
+			Message: "获取配置模板失败",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "success",
+		Data:    templates,
+	})
+}
+
+// GetTemplate 获取配置模板详情
+// @Summary 获取配置模板详情
+// @Tags templates
+// @Produce json
+// @Param id path int true "模板ID"
+// @Success 200 {object} Response
+// @Failure 404 {object} Response
+// @Router /api/v1/templates/{id} [get]
+func (h *TemplateHandler) GetTemplate(c *gin.Context) {
+	id, ok := parseTemplateID(c)
+	if !ok {
+		return
+	}
+
+	tmpl, err := h.templateService.GetTemplate(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, Response{
+			Code:    404,
+			Message: "配置模板不存在",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "success",
+		Data:    tmpl,
+	})
+}
+
+// UpdateTemplate 修改配置模板
+// @Summary 修改配置模板
+// @Description 修改模板描述和内容，内容变化时修订版本加1，旧版本保留可供预览和下发
+// @Tags templates
+// @Accept json
+// @Produce json
+// @Param id path int true "模板ID"
+// @Param request body TemplateRequest true "模板内容"
+// @Success 200 {object} Response
+// @Router /api/v1/templates/{id} [put]
+func (h *TemplateHandler) UpdateTemplate(c *gin.Context) {
+	id, ok := parseTemplateID(c)
+	if !ok {
+		return
+	}
+
+	var req TemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    400,
+			Message: "请求参数错误",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	tmpl, err := h.templateService.UpdateTemplate(id, req.Description, req.Content)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    400,
+			Message: "修改配置模板失败",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "配置模板已更新",
+		Data:    tmpl,
+	})
+}
+
+// DeleteTemplate 删除配置模板
+// @Summary 删除配置模板
+// @Description 删除模板及其修订版本，已下发配置记录中的模板ID和修订版本保留
+// @Tags templates
+// @Produce json
+// @Param id path int true "模板ID"
+// @Success 200 {object} Response
+// @Router /api/v1/templates/{id} [delete]
+func (h *TemplateHandler) DeleteTemplate(c *gin.Context) {
+	id, ok := parseTemplateID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.templateService.DeleteTemplate(id); err != nil {
+		c.JSON(http.StatusInternalServerError, Response{
+			Code:    500,
+			Message: "删除配置模板失败",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "配置模板已删除",
+	})
+}
+
+// ListRevisions 获取配置模板修订版本
+// @Summary 获取配置模板修订版本
+// @Description 按修订版本倒序返回模板的历史内容
+// @Tags templates
+// @Produce json
+// @Param id path int true "模板ID"
+// @Success 200 {object} Response
+// @Router /api/v1/templates/{id}/revisions [get]
+func (h *TemplateHandler) ListRevisions(c *gin.Context) {
+	id, ok := parseTemplateID(c)
+	if !ok {
+		return
+	}
+
+	revisions, err := h.templateService.ListRevisions(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, Response{
+			Code:    500,
+			Message: "获取模板修订版本失败",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "success",
+		Data:    revisions,
+	})
+}
+
+// PreviewTemplate 按Agent预览模板渲染结果
+// @Summary 按Agent预览模板渲染结果
+// @Description 为每个Agent渲染模板并做sing-box语义校验，返回规范化后的配置、校验问题或渲染错误，不下发
+// @Tags templates
+// @Accept json
+// @Produce json
+// @Param id path int true "模板ID"
+// @Param request body TemplatePreviewRequest true "预览参数"
+// @Success 200 {object} Response{data=[]service.RenderedConfig}
+// @Router /api/v1/templates/{id}/preview [post]
+func (h *TemplateHandler) PreviewTemplate(c *gin.Context) {
+	id, ok := parseTemplateID(c)
+	if !ok {
+		return
+	}
+
+	var req TemplatePreviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    400,
+			Message: "请求参数错误",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	results, err := h.templateService.Preview(id, req.Revision, req.AgentIDs)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    400,
+			Message: "预览配置模板失败",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "success",
+		Data:    results,
+	})
+}
+
+// ApplyTemplate 渲染模板并下发到Agent
+// @Summary 渲染模板并下发到Agent
+// @Description 为每个Agent渲染模板，校验通过后经UpdateConfig下发，配置记录中保存模板ID和修订版本。单个Agent失败不影响其他Agent
+// @Tags templates
+// @Accept json
+// @Produce json
+// @Param id path int true "模板ID"
+// @Param request body TemplateApplyRequest true "下发参数"
+// @Success 200 {object} Response{data=[]service.TemplateApplyResult}
+// @Router /api/v1/templates/{id}/apply [post]
+func (h *TemplateHandler) ApplyTemplate(c *gin.Context) {
+	id, ok := parseTemplateID(c)
+	if !ok {
+		return
+	}
+
+	var req TemplateApplyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    400,
+			Message: "请求参数错误",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	results, err := h.templateService.Apply(id, req.Revision, req.AgentIDs, req.Instance, req.Force)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    400,
+			Message: "下发配置模板失败",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	failed := 0
+	for _, result := range results {
+		if !result.Success {
+			failed++
+		}
+	}
+	message := "配置模板已下发"
+	if failed > 0 {
+		message = fmt.Sprintf("%d 个Agent下发失败", failed)
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: message,
+		Data:    results,
+	})
+}
+
+// GetAgentVariables 获取Agent模板变量
+// @Summary 获取Agent模板变量
+// @Tags templates
+// @Produce json
+// @Param id path string true "Agent ID"
+// @Success 200 {object} Response
+// @Router /api/v1/agents/{id}/template-vars [get]
+func (h *TemplateHandler) GetAgentVariables(c *gin.Context) {
+	vars, err := h.templateService.GetAgentVariables(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, Response{
+			Code:    404,
+			Message: "获取模板变量失败",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "success",
+		Data:    vars,
+	})
+}
+
+// SetAgentVariables 设置Agent模板变量
+// @Summary 设置Agent模板变量
+// @Description 替换Agent的全部模板变量，模板中以{{.Vars.name}}引用
+// @Tags templates
+// @Accept json
+// @Produce json
+// @Param id path string true "Agent ID"
+// @Param request body map[string]string true "模板变量"
+// @Success 200 {object} Response
+// @Router /api/v1/agents/{id}/template-vars [put]
+func (h *TemplateHandler) SetAgentVariables(c *gin.Context) {
+	var vars map[string]string
+	if err := c.ShouldBindJSON(&vars); err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    400,
+			Message: "请求参数错误",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	if err := h.templateService.SetAgentVariables(c.Param("id"), vars); err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    400,
+			Message: "设置模板变量失败",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "模板变量已更新",
+		Data:    vars,
+	})
+}
@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xbox/sing-box-manager/internal/controller/service"
+)
+
+// 未指定起始时间时默认查询最近24小时
+const defaultUsageRange = 24 * time.Hour
+
+// UsageHandler 流量用量API处理器
+type UsageHandler struct {
+	usageService service.UsageService
+}
+
+// NewUsageHandler 创建流量用量处理器实例
+func NewUsageHandler(usageService service.UsageService) *UsageHandler {
+	return &UsageHandler{
+		usageService: usageService,
+	}
+}
+
+// GetHourly 查询流量小时汇总
+// @Summary 查询流量小时汇总
+// @Description 按Agent、统计类型（user/inbound/outbound）和名称查询每小时的上下行流量
+// @Tags usage
+// @Produce json
+// @Param agent_id query string false "Agent ID"
+// @Param instance query string false "sing-box实例名称"
+// @Param scope query string false "统计类型: user, inbound, outbound"
+// @Param name query string false "用户名或tag"
+// @Param from query string false "起始时间(RFC3339)，默认24小时前"
+// @Param to query string false "结束时间(RFC3339)，默认当前时间"
+// @Success 200 {object} Response
+// @Router /api/v1/usage/hourly [get]
+func (h *UsageHandler) GetHourly(c *gin.Context) {
+	query, err := parseUsageQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    400,
+			Message: "请求参数错误",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	rows, err := h.usageService.GetHourly(query)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, Response{
+			Code:    500,
+			Message: "查询流量小时汇总失败",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "success",
+		Data:    rows,
+	})
+}
+
+// GetTotals 查询流量合计
+// @Summary 查询流量合计
+// @Description 查询区间内每个用户、入站或出站的上下行流量合计，按总流量倒序
+// @Tags usage
+// @Produce json
+// @Param agent_id query string false "Agent ID"
+// @Param instance query string false "sing-box实例名称"
+// @Param scope query string false "统计类型: user, inbound, outbound"
+// @Param name query string false "用户名或tag"
+// @Param from query string false "起始时间(RFC3339)，默认24小时前"
+// @Param to query string false "结束时间(RFC3339)，默认当前时间"
+// @Success 200 {object} Response
+// @Router /api/v1/usage/totals [get]
+func (h *UsageHandler) GetTotals(c *gin.Context) {
+	query, err := parseUsageQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, Response{
+			Code:    400,
+			Message: "请求参数错误",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	totals, err := h.usageService.GetTotals(query)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, Response{
+			Code:    500,
+			Message: "查询流量合计失败",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, Response{
+		Code:    200,
+		Message: "success",
+		Data:    totals,
+	})
+}
+
+// parseUsageQuery 解析流量查询参数
+func parseUsageQuery(c *gin.Context) (service.UsageQuery, error) {
+	query := service.UsageQuery{
+		AgentID:  c.Query("agent_id"),
+		Instance: c.Query("instance"),
+		Scope:    c.Query("scope"),
+		Name:     c.Query("name"),
+		To:       time.Now(),
+	}
+
+	switch query.Scope {
+	case "", "user", "inbound", "outbound":
+	default:
+		return query, fmt.Errorf("scope必须为user、inbound或outbound")
+	}
+
+	if to := c.Query("to"); to != "" {
+		t, err := time.Parse(time.RFC3339, to)
+		if err != nil {
+			return query, fmt.Errorf("to时间格式无效: %v", err)
+		}
+		query.To = t
+	}
+	query.From = query.To.Add(-defaultUsageRange)
+	if from := c.Query("from"); from != "" {
+		t, err := time.Parse(time.RFC3339, from)
+		if err != nil {
+			return query, fmt.Errorf("from时间格式无效: %v", err)
+		}
+		query.From = t
+	}
+	if !query.From.Before(query.To) {
+		return query, fmt.Errorf("from必须早于to")
+	}
+	return query, nil
+}
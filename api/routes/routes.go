@@ -6,15 +6,48 @@ import (
 	"github.com/xbox/sing-box-manager/internal/controller/service"
 )
 
+// Services API路由使用的服务
+type Services struct {
+	Agent              service.AgentService
+	Multiplex          service.MultiplexService
+	Report             *service.NodeReportService // 未启用节点上报时为nil
+	Config             service.ConfigService
+	Log                service.LogService
+	Inbound            service.InboundService
+	Connection         service.ConnectionService
+	Usage              service.UsageService
+	Rollout            service.RolloutService
+	Template           service.TemplateService
+	Subscription       service.SubscriptionService
+	DNS                service.DNSService
+	OutboundGroup      service.OutboundGroupService
+	Certificate        service.CertificateService
+	Reality            service.RealityService
+	CredentialRotation service.CredentialRotationService
+}
+
 // SetupRoutes 设置API路由
-func SetupRoutes(r *gin.Engine, agentService service.AgentService, multiplexService service.MultiplexService, reportService *service.NodeReportService) {
+func SetupRoutes(r *gin.Engine, services Services) {
 	// 创建处理器
-	agentHandler := handlers.NewAgentHandler(agentService, nil)
-	multiplexHandler := handlers.NewMultiplexHandler(multiplexService)
+	agentHandler := handlers.NewAgentHandler(services.Agent, nil)
+	multiplexHandler := handlers.NewMultiplexHandler(services.Multiplex)
+	configHandler := handlers.NewConfigHandler(services.Config)
+	logHandler := handlers.NewLogHandler(services.Log)
+	inboundHandler := handlers.NewInboundHandler(services.Inbound)
+	connectionHandler := handlers.NewConnectionHandler(services.Connection)
+	usageHandler := handlers.NewUsageHandler(services.Usage)
+	rolloutHandler := handlers.NewRolloutHandler(services.Rollout)
+	templateHandler := handlers.NewTemplateHandler(services.Template)
+	subscriptionHandler := handlers.NewSubscriptionHandler(services.Subscription)
+	dnsHandler := handlers.NewDNSHandler(services.DNS)
+	outboundGroupHandler := handlers.NewOutboundGroupHandler(services.OutboundGroup)
+	certificateHandler := handlers.NewCertificateHandler(services.Certificate)
+	realityHandler := handlers.NewRealityHandler(services.Reality)
+	rotationHandler := handlers.NewCredentialRotationHandler(services.CredentialRotation)
 	
 	var reportHandler *handlers.ReportHandler
-	if reportService != nil {
-		reportHandler = handlers.NewReportHandler(reportService)
+	if services.Report != nil {
+		reportHandler = handlers.NewReportHandler(services.Report)
 	}
 	
 	// API v1 路由组
@@ -27,10 +60,45 @@ func SetupRoutes(r *gin.Engine, agentService service.AgentService, multiplexServ
 			agents.GET("/stats", agentHandler.GetAgentStats)    // 获取Agent统计
 			agents.GET("/ip-ranges", agentHandler.GetIPRanges)  // 获取IP段信息
 			agents.GET("/:id", agentHandler.GetAgent)           // 获取单个Agent
+			agents.GET("/:id/instances", agentHandler.GetAgentInstances) // 获取sing-box实例状态
 			agents.PUT("/:id", agentHandler.UpdateAgent)        // 更新Agent
 			agents.DELETE("/:id", agentHandler.DeleteAgent)     // 删除Agent
 			agents.POST("/deploy", agentHandler.DeployAgent)    // 部署Agent
 			agents.POST("/uninstall", agentHandler.UninstallAgent) // 卸载Agent
+			
+			// sing-box配置
+			agents.PUT("/:id/config", configHandler.PushConfig)                   // 校验并下发配置
+			agents.GET("/:id/config/generations", configHandler.ListGenerations) // 列出配置历史代
+			agents.GET("/:id/config/diff", configHandler.DiffGenerations)        // 比较两代配置
+			agents.POST("/:id/config/rollback", configHandler.RollbackConfig)    // 按版本回滚
+			agents.GET("/:id/logs", logHandler.GetSingboxLogs)                   // 查看sing-box日志（follow=true时为SSE）
+			agents.GET("/:id/template-vars", templateHandler.GetAgentVariables)  // 获取模板变量
+			agents.PUT("/:id/template-vars", templateHandler.SetAgentVariables)  // 设置模板变量
+			
+			// 入站用户管理
+			agents.GET("/:id/inbounds/:tag/users", inboundHandler.GetUsers)            // 获取入站用户
+			agents.POST("/:id/inbounds/:tag/users", inboundHandler.AddUsers)           // 添加入站用户
+			agents.PUT("/:id/inbounds/:tag/users", inboundHandler.ReplaceUsers)        // 替换入站全部用户
+			agents.POST("/:id/inbounds/:tag/users/remove", inboundHandler.RemoveUsers) // 删除入站用户
+			
+			// DNS配置（回滚使用config/rollback，scope=dns）
+			agents.GET("/:id/dns", dnsHandler.GetConfig)              // 获取DNS配置与版本
+			agents.POST("/:id/dns/servers", dnsHandler.UpdateServers) // 更新DNS服务器
+			agents.POST("/:id/dns/rules", dnsHandler.UpdateRules)     // 更新DNS规则
+			agents.PUT("/:id/dns/fakeip", dnsHandler.UpdateFakeIP)    // 启用或关闭FakeIP
+			
+			// 出站组（selector/urltest）
+			agents.GET("/:id/outbound-groups", outboundGroupHandler.GetGroups)                           // 出站组、当前选择与成员延迟
+			agents.POST("/:id/outbound-groups", outboundGroupHandler.CreateGroup)                        // 创建出站组
+			agents.PUT("/:id/outbound-groups/:tag", outboundGroupHandler.UpdateGroup)                    // 修改出站组
+			agents.DELETE("/:id/outbound-groups/:tag", outboundGroupHandler.DeleteGroup)                 // 删除出站组
+			agents.POST("/:id/outbound-groups/:tag/members", outboundGroupHandler.AddMembers)            // 添加成员
+			agents.PUT("/:id/outbound-groups/:tag/members", outboundGroupHandler.ReplaceMembers)         // 替换全部成员
+			agents.POST("/:id/outbound-groups/:tag/members/remove", outboundGroupHandler.RemoveMembers) // 删除成员
+			
+			// 连接与流量
+			agents.GET("/:id/connections", connectionHandler.GetConnectionStats)    // 连接与流量统计
+			agents.POST("/:id/connections/close", connectionHandler.CloseConnections) // 按条件关闭连接
 		}
 		
 		// 过滤器管理路由（黑名单/白名单）
@@ -55,6 +123,89 @@ func SetupRoutes(r *gin.Engine, agentService service.AgentService, multiplexServ
 			}
 		}
 		
+		// 流量用量路由
+		usage := v1.Group("/usage")
+		{
+			usage.GET("/hourly", usageHandler.GetHourly) // 小时汇总
+			usage.GET("/totals", usageHandler.GetTotals) // 区间合计（按用户/入站/出站）
+		}
+		
+		// sing-box版本切换
+		upgrades := v1.Group("/singbox/upgrades")
+		{
+			upgrades.POST("", rolloutHandler.StartRollout)   // 创建批量切换任务
+			upgrades.GET("", rolloutHandler.ListRollouts)    // 任务列表
+			upgrades.GET("/:id", rolloutHandler.GetRollout)  // 任务详情及各Agent进度
+		}
+		
+		// sing-box配置模板
+		templates := v1.Group("/templates")
+		{
+			templates.POST("", templateHandler.CreateTemplate)               // 创建模板
+			templates.GET("", templateHandler.ListTemplates)                 // 模板列表
+			templates.GET("/:id", templateHandler.GetTemplate)               // 模板详情
+			templates.PUT("/:id", templateHandler.UpdateTemplate)            // 修改模板（生成新修订版本）
+			templates.DELETE("/:id", templateHandler.DeleteTemplate)         // 删除模板
+			templates.GET("/:id/revisions", templateHandler.ListRevisions)   // 修订版本列表
+			templates.POST("/:id/preview", templateHandler.PreviewTemplate)  // 按Agent预览渲染结果
+			templates.POST("/:id/apply", templateHandler.ApplyTemplate)      // 渲染并下发到Agent
+		}
+		
+		// 客户端订阅管理
+		subscriptions := v1.Group("/subscriptions")
+		{
+			subscriptions.POST("", subscriptionHandler.CreateSubscription)                // 创建订阅
+			subscriptions.GET("", subscriptionHandler.ListSubscriptions)                  // 订阅列表
+			subscriptions.GET("/:id", subscriptionHandler.GetSubscription)                // 订阅详情
+			subscriptions.PUT("/:id", subscriptionHandler.UpdateSubscription)             // 修改订阅
+			subscriptions.DELETE("/:id", subscriptionHandler.DeleteSubscription)          // 删除订阅
+			subscriptions.POST("/:id/reset-token", subscriptionHandler.ResetToken)        // 重置订阅令牌
+		}
+		
+		// TLS证书托管与下发
+		certificates := v1.Group("/certificates")
+		{
+			certificates.POST("", certificateHandler.CreateCertificate)                                    // 上传证书
+			certificates.GET("", certificateHandler.ListCertificates)                                      // 证书列表与到期状态
+			certificates.GET("/expiring", certificateHandler.ListExpiring)                                 // 即将到期的证书
+			certificates.GET("/:id", certificateHandler.GetCertificate)                                    // 证书详情
+			certificates.PUT("/:id", certificateHandler.RenewCertificate)                                  // 续期证书
+			certificates.DELETE("/:id", certificateHandler.DeleteCertificate)                              // 删除证书
+			certificates.POST("/:id/assignments", certificateHandler.AssignCertificate)                    // 分配到入站并下发
+			certificates.DELETE("/:id/assignments/:assignment_id", certificateHandler.UnassignCertificate) // 取消分配
+			certificates.POST("/:id/push", certificateHandler.PushCertificate)                             // 下发到全部已分配的入站
+		}
+		
+		// Reality密钥托管与轮换
+		realityKeys := v1.Group("/reality-keys")
+		{
+			realityKeys.POST("", realityHandler.CreateKey)            // 生成密钥并下发
+			realityKeys.GET("", realityHandler.ListKeys)              // 密钥列表
+			realityKeys.GET("/:id", realityHandler.GetKey)            // 密钥详情与客户端配置
+			realityKeys.PUT("/:id", realityHandler.UpdateSchedule)    // 修改轮换周期
+			realityKeys.DELETE("/:id", realityHandler.DeleteKey)      // 停止托管
+			realityKeys.POST("/:id/rotate", realityHandler.RotateKey) // 立即轮换
+			realityKeys.POST("/:id/apply", realityHandler.ReapplyKey) // 重新下发当前密钥
+		}
+		
+		// 入站用户凭据定期轮换
+		rotations := v1.Group("/credential-rotations")
+		{
+			rotations.POST("", rotationHandler.CreateRotation)       // 创建轮换计划
+			rotations.GET("", rotationHandler.ListRotations)         // 轮换计划列表
+			rotations.GET("/:id", rotationHandler.GetRotation)       // 轮换计划详情
+			rotations.PUT("/:id", rotationHandler.UpdateRotation)    // 修改轮换计划
+			rotations.DELETE("/:id", rotationHandler.DeleteRotation) // 删除轮换计划
+			rotations.POST("/:id/run", rotationHandler.RunRotation)  // 立即轮换
+			rotations.GET("/:id/runs", rotationHandler.ListRuns)     // 执行记录
+		}
+		
+		// sing-box配置校验
+		v1.POST("/configs/validate", configHandler.ValidateConfig)
+		
+		// 导入Clash/V2Ray/Xray配置
+		v1.POST("/configs/import", configHandler.ImportConfig)
+		
 		// TODO: 配置管理路由
 		// configs := v1.Group("/configs")
 		// {
@@ -81,6 +232,9 @@ func SetupRoutes(r *gin.Engine, agentService service.AgentService, multiplexServ
 		// }
 	}
 	
+	// 客户端订阅内容，凭令牌访问
+	r.GET("/sub/:token", subscriptionHandler.GetSubscriptionContent)
+	
 	// 健康检查路由
 	r.GET("/health", func(c *gin.Context) {
 		c.JSON(200, gin.H{
@@ -10,25 +10,20 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/xbox/sing-box-manager/api/routes"
 	"github.com/xbox/sing-box-manager/internal/config"
-	"github.com/xbox/sing-box-manager/internal/controller/service"
 )
 
 // Server HTTP API服务器
 type Server struct {
-	config           *config.Config
-	httpServer       *http.Server
-	agentService     service.AgentService
-	multiplexService service.MultiplexService
-	reportService    *service.NodeReportService
+	config     *config.Config
+	httpServer *http.Server
+	services   routes.Services
 }
 
 // NewServer 创建HTTP服务器实例
-func NewServer(cfg *config.Config, agentService service.AgentService, multiplexService service.MultiplexService, reportService *service.NodeReportService) *Server {
+func NewServer(cfg *config.Config, services routes.Services) *Server {
 	return &Server{
-		config:           cfg,
-		agentService:     agentService,
-		multiplexService: multiplexService,
-		reportService:    reportService,
+		config:   cfg,
+		services: services,
 	}
 }
 
@@ -45,8 +40,14 @@ func (s *Server) Start() error {
 	r.Use(gin.Recovery())
 	r.Use(corsMiddleware())
 	
+	// 托管sing-box制品，供Agent以mirror来源安装
+	if dir := s.config.Server.ArtifactsDir; dir != "" {
+		r.StaticFS("/api/v1/artifacts/sing-box", gin.Dir(dir, false))
+		log.Printf("sing-box制品目录: %s", dir)
+	}
+	
 	// 设置路由
-	routes.SetupRoutes(r, s.agentService, s.multiplexService, s.reportService)
+	routes.SetupRoutes(r, s.services)
 	
 	// 创建HTTP服务器
 	s.httpServer = &http.Server{
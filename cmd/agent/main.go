@@ -49,12 +49,14 @@ func main() {
 	log.Printf("Xbox Agent %s 启动中...", Version)
 	
 	// 检查和安装sing-box
-	if err := checkAndInstallSingbox(cfg); err != nil {
+	installer, err := checkAndInstallSingbox(cfg)
+	if err != nil {
 		log.Fatalf("sing-box检查安装失败: %v", err)
 	}
 	
 	// 创建gRPC客户端
 	client := grpc.NewClient(cfg)
+	client.SetSingboxInstallation(installer.GetVersion(), installer.GetChecksum())
 	
 	// 启动gRPC服务器（用于接收Controller的配置推送）
 	server := grpc.NewServer(client, "9091")
@@ -81,18 +83,30 @@ func main() {
 	// 启动心跳循环
 	go client.StartHeartbeat()
 	
+	// 启动连接与流量采集
+	client.StartTrafficCollector()
+	
+	// 启动用户流量上报
+	go client.StartUsageReport()
+	
+	// 启动出站可达性探测
+	client.StartOutboundProbes()
+	
 	// 输出sing-box配置信息
 	if err := outputSingboxConfig(cfg); err != nil {
 		log.Printf("输出sing-box配置信息失败: %v", err)
 	}
 	
-	// 如果配置中指定了启动sing-box，则自动启动
-	if shouldStartSingbox(cfg) {
-		log.Println("正在启动sing-box服务...")
-		if err := client.StartSingbox(); err != nil {
-			log.Printf("启动sing-box失败: %v", err)
+	// 启动配置文件已就绪的sing-box实例
+	for _, inst := range client.Instances() {
+		if !shouldStartSingbox(inst.BinaryPath(), inst.ConfigPath()) {
+			continue
+		}
+		log.Printf("正在启动sing-box实例: %s", inst.Name())
+		if err := inst.StartSingbox(); err != nil {
+			log.Printf("启动sing-box实例 %s 失败: %v", inst.Name(), err)
 		} else {
-			log.Println("sing-box服务已启动")
+			log.Printf("sing-box实例 %s 已启动", inst.Name())
 		}
 	}
 	
@@ -103,30 +117,34 @@ func main() {
 	<-sigChan
 	log.Println("正在关闭服务...")
 	
-	// 停止sing-box服务
-	if err := client.StopSingbox(); err != nil {
-		log.Printf("停止sing-box失败: %v", err)
+	// 停止sing-box实例，由systemd托管的实例保持运行，避免Agent重启或升级中断用户连接
+	for _, inst := range client.Instances() {
+		if inst.SingboxManagedBySystemd() {
+			log.Printf("sing-box实例 %s 由systemd托管，保持运行", inst.Name())
+		} else if err := inst.StopSingbox(); err != nil {
+			log.Printf("停止sing-box实例 %s 失败: %v", inst.Name(), err)
+		}
 	}
 }
 
 // shouldStartSingbox 检查是否应该启动sing-box
-func shouldStartSingbox(cfg *config.Config) bool {
+func shouldStartSingbox(binaryPath, configPath string) bool {
 	// 检查配置文件是否存在
-	if cfg.Agent.SingBoxConfig == "" || cfg.Agent.SingBoxBinary == "" {
+	if configPath == "" || binaryPath == "" {
 		return false
 	}
 	
 	// 检查配置文件是否存在
-	if _, err := os.Stat(cfg.Agent.SingBoxConfig); os.IsNotExist(err) {
-		log.Printf("sing-box配置文件不存在: %s", cfg.Agent.SingBoxConfig)
+	if _, err := os.Stat(configPath); os.IsNotExist(err) {
+		log.Printf("sing-box配置文件不存在: %s", configPath)
 		return false
 	}
 	
 	return true
 }
 
-// checkAndInstallSingbox 检查和安装sing-box
-func checkAndInstallSingbox(cfg *config.Config) error {
+// checkAndInstallSingbox 检查和安装sing-box，配置了固定版本且已安装版本不一致时安装固定版本
+func checkAndInstallSingbox(cfg *config.Config) (*singbox.Installer, error) {
 	log.Println("检查sing-box安装状态...")
 	
 	// 确定安装目录
@@ -137,16 +155,26 @@ func checkAndInstallSingbox(cfg *config.Config) error {
 	
 	// 创建安装器
 	installer := singbox.NewInstaller(installDir)
+	installer.SetOptions(singbox.InstallOptions{
+		Version:   cfg.Agent.Install.Version,
+		Source:    cfg.Agent.Install.Source,
+		MirrorURL: cfg.Agent.Install.MirrorURL,
+		LocalDir:  cfg.Agent.Install.LocalDir,
+		SHA256:    cfg.Agent.Install.SHA256,
+		PublicKey: cfg.Agent.Install.PublicKey,
+	})
+	pinned := installer.PinnedVersion()
 	
 	// 检查是否已安装
 	installed, version, err := installer.Check()
 	if err != nil {
-		return fmt.Errorf("检查sing-box状态失败: %v", err)
+		return nil, fmt.Errorf("检查sing-box状态失败: %v", err)
 	}
 	
-	if installed {
+	if installed && (pinned == "" || version == pinned) {
 		log.Printf("sing-box已安装，版本: %s", version)
 		log.Printf("二进制路径: %s", installer.GetBinaryPath())
+		log.Printf("sha256: %s", installer.GetChecksum())
 		
 		// 更新配置中的二进制路径
 		if cfg.Agent.SingBoxBinary == "" || cfg.Agent.SingBoxBinary != installer.GetBinaryPath() {
@@ -154,33 +182,41 @@ func checkAndInstallSingbox(cfg *config.Config) error {
 			log.Printf("已更新sing-box二进制路径: %s", cfg.Agent.SingBoxBinary)
 		}
 		
-		return nil
+		return installer, nil
 	}
 	
-	log.Println("sing-box未安装，开始自动安装...")
+	if pinned == "" {
+		return nil, fmt.Errorf("sing-box未安装，请通过agent.install.version指定要安装的版本")
+	}
+	if installed {
+		log.Printf("sing-box已安装版本 %s 与固定版本 %s 不一致，开始安装固定版本...", version, pinned)
+	} else {
+		log.Printf("sing-box未安装，开始安装固定版本 %s...", pinned)
+	}
 	
 	// 执行安装
 	if err := installer.Install(); err != nil {
-		return fmt.Errorf("安装sing-box失败: %v", err)
+		return nil, fmt.Errorf("安装sing-box失败: %v", err)
 	}
 	
 	// 重新检查安装结果
 	installed, version, err = installer.Check()
 	if err != nil {
-		return fmt.Errorf("安装后检查失败: %v", err)
+		return nil, fmt.Errorf("安装后检查失败: %v", err)
 	}
 	
-	if !installed {
-		return fmt.Errorf("sing-box安装失败")
+	if !installed || version != pinned {
+		return nil, fmt.Errorf("sing-box安装失败")
 	}
 	
 	log.Printf("sing-box安装成功，版本: %s", version)
 	log.Printf("二进制路径: %s", installer.GetBinaryPath())
+	log.Printf("sha256: %s", installer.GetChecksum())
 	
 	// 更新配置中的二进制路径
 	cfg.Agent.SingBoxBinary = installer.GetBinaryPath()
 	
-	return nil
+	return installer, nil
 }
 
 // outputSingboxConfig 输出sing-box配置信息
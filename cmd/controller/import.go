@@ -0,0 +1,137 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/xbox/sing-box-manager/internal/agent/singbox"
+	"github.com/xbox/sing-box-manager/internal/converter"
+)
+
+// runImport 执行import子命令：将Clash/V2Ray/Xray配置转换为sing-box配置，
+// 转换报告输出到标准错误，指定-push时经控制器REST API下发到Agent
+func runImport(args []string) error {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	format := fs.String("format", "", "源配置格式：clash、v2ray、xray，为空时自动识别")
+	in := fs.String("in", "-", "源配置文件，-表示标准输入")
+	out := fs.String("out", "-", "sing-box配置输出文件，-表示标准输出")
+	push := fs.String("push", "", "下发目标Agent ID，多个用逗号分隔")
+	instance := fs.String("instance", "", "sing-box实例名称，默认default")
+	force := fs.Bool("force", false, "入站端口冲突时仍然应用")
+	api := fs.String("api", "http://127.0.0.1:8080", "控制器API地址，-push时使用")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "用法: %s import [选项]\n\n选项:\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	var data []byte
+	var err error
+	if *in == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(*in)
+	}
+	if err != nil {
+		return fmt.Errorf("读取源配置失败: %v", err)
+	}
+
+	config, report, err := converter.Convert(*format, data)
+	if err != nil {
+		return err
+	}
+	fmt.Fprint(os.Stderr, report.String())
+
+	content, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return fmt.Errorf("序列化sing-box配置失败: %v", err)
+	}
+	if *out == "-" {
+		fmt.Println(string(content))
+	} else if err := os.WriteFile(*out, append(content, '\n'), 0644); err != nil {
+		return fmt.Errorf("写入sing-box配置失败: %v", err)
+	}
+
+	if errs := singbox.Validate(config); errs != nil {
+		return fmt.Errorf("转换后的配置校验失败，未下发: %v", errs)
+	}
+	if *push == "" {
+		return nil
+	}
+
+	failed := 0
+	for _, agentID := range strings.Split(*push, ",") {
+		agentID = strings.TrimSpace(agentID)
+		if agentID == "" {
+			continue
+		}
+		if err := pushImportedConfig(*api, agentID, *instance, *force, content); err != nil {
+			fmt.Fprintf(os.Stderr, "下发到Agent %s 失败: %v\n", agentID, err)
+			failed++
+			continue
+		}
+		fmt.Fprintf(os.Stderr, "已下发到Agent %s\n", agentID)
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d 个Agent下发失败", failed)
+	}
+	return nil
+}
+
+// pushImportedConfig 调用控制器的配置下发接口
+func pushImportedConfig(api, agentID, instance string, force bool, content []byte) error {
+	body, err := json.Marshal(map[string]json.RawMessage{"config": content})
+	if err != nil {
+		return err
+	}
+
+	query := url.Values{}
+	if instance != "" {
+		query.Set("instance", instance)
+	}
+	if force {
+		query.Set("force", "true")
+	}
+	endpoint := fmt.Sprintf("%s/api/v1/agents/%s/config", strings.TrimRight(api, "/"), url.PathEscape(agentID))
+	if len(query) > 0 {
+		endpoint += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequest(http.MethodPut, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	client := &http.Client{Timeout: 60 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		Message string `json:"message"`
+		Error   string `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+	if resp.StatusCode != http.StatusOK {
+		if result.Error != "" {
+			return fmt.Errorf("%s: %s", result.Message, result.Error)
+		}
+		return fmt.Errorf("HTTP %d: %s", resp.StatusCode, result.Message)
+	}
+	return nil
+}
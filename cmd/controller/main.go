@@ -12,6 +12,7 @@ import (
 	"time"
 
 	"github.com/xbox/sing-box-manager/api"
+	"github.com/xbox/sing-box-manager/api/routes"
 	"github.com/xbox/sing-box-manager/internal/config"
 	"github.com/xbox/sing-box-manager/internal/controller/grpc"
 	"github.com/xbox/sing-box-manager/internal/controller/repository"
@@ -32,6 +33,14 @@ const (
 )
 
 func main() {
+	// import子命令只做配置转换和下发，不需要加载控制器配置
+	if len(os.Args) > 1 && os.Args[1] == "import" {
+		if err := runImport(os.Args[2:]); err != nil {
+			log.Fatalf("导入配置失败: %v", err)
+		}
+		return
+	}
+
 	flag.Parse()
 	
 	if *showVersion {
@@ -43,6 +52,8 @@ func main() {
 		fmt.Printf("%s - Xbox Sing-box管理系统控制器\n\n", Name)
 		fmt.Println("选项:")
 		flag.PrintDefaults()
+		fmt.Println("\n子命令:")
+		fmt.Println("  import    将Clash/V2Ray/Xray配置转换为sing-box配置并可下发到Agent")
 		return
 	}
 	
@@ -83,6 +94,20 @@ func main() {
 	// 创建Agent客户端和多路复用服务
 	agentClient := service.NewAgentClient()
 	multiplexService := service.NewMultiplexService(db, agentClient)
+	realityService := service.NewRealityService(db, agentRepo, agentClient, cfg.Reality.SecretKey)
+	configService := service.NewConfigService(db, agentRepo, agentClient, realityService)
+	logService := service.NewLogService(agentRepo, agentClient)
+	inboundService := service.NewInboundService(agentRepo, agentClient)
+	connectionService := service.NewConnectionService(agentRepo, agentService, agentClient)
+	usageService := service.NewUsageService(db)
+	probeService := service.NewProbeService(db)
+	rolloutService := service.NewRolloutService(db, agentRepo, agentClient)
+	templateService := service.NewTemplateService(db, agentRepo, configService)
+	subscriptionService := service.NewSubscriptionService(db, agentClient)
+	dnsService := service.NewDNSService(agentRepo, agentClient)
+	outboundGroupService := service.NewOutboundGroupService(agentRepo, agentClient)
+	certificateService := service.NewCertificateService(db, agentRepo, agentClient, cfg.Certificate.WarnDays, cfg.Certificate.SecretKey)
+	rotationService := service.NewCredentialRotationService(db, agentClient)
 	
 	// 创建节点上报服务
 	var reportService *service.NodeReportService
@@ -91,8 +116,25 @@ func main() {
 	}
 	
 	// 创建服务器
-	grpcServer := grpc.NewServer(cfg, agentService, multiplexService, reportService)
-	httpServer := api.NewServer(cfg, agentService, multiplexService, reportService)
+	grpcServer := grpc.NewServer(cfg, agentService, multiplexService, reportService, usageService, probeService)
+	httpServer := api.NewServer(cfg, routes.Services{
+		Agent:              agentService,
+		Multiplex:          multiplexService,
+		Report:             reportService,
+		Config:             configService,
+		Log:                logService,
+		Inbound:            inboundService,
+		Connection:         connectionService,
+		Usage:              usageService,
+		Rollout:            rolloutService,
+		Template:           templateService,
+		Subscription:       subscriptionService,
+		DNS:                dnsService,
+		OutboundGroup:      outboundGroupService,
+		Certificate:        certificateService,
+		Reality:            realityService,
+		CredentialRotation: rotationService,
+	})
 	
 	// 使用WaitGroup等待所有服务启动
 	var wg sync.WaitGroup
@@ -134,6 +176,28 @@ func main() {
 		log.Printf("节点上报服务已启动，间隔: %d秒", cfg.Report.Interval)
 	}
 	
+	// 启动证书到期检查
+	certCtx, cancelCertCheck := context.WithCancel(context.Background())
+	defer cancelCertCheck()
+	go certificateService.StartExpiryCheck(certCtx, time.Duration(cfg.Certificate.CheckInterval)*time.Second)
+	log.Printf("证书到期检查已启动，告警天数: %d", cfg.Certificate.WarnDays)
+	if cfg.Certificate.SecretKey == "" {
+		log.Printf("未配置certificate.secret_key，无法上传或续期TLS证书")
+	}
+	
+	// 启动Reality密钥定期轮换
+	realityCtx, cancelRealityRotation := context.WithCancel(context.Background())
+	defer cancelRealityRotation()
+	go realityService.StartRotation(realityCtx, time.Duration(cfg.Reality.CheckInterval)*time.Second)
+	if cfg.Reality.SecretKey == "" {
+		log.Printf("未配置reality.secret_key，Reality密钥托管不可用")
+	}
+	
+	// 启动凭据定期轮换
+	rotationCtx, cancelRotation := context.WithCancel(context.Background())
+	defer cancelRotation()
+	go rotationService.StartScheduler(rotationCtx, time.Duration(cfg.Credential.CheckInterval)*time.Second)
+	
 	log.Printf("Controller服务已启动")
 	log.Printf("gRPC地址: %s", cfg.GetGRPCAddr())
 	log.Printf("HTTP地址: %s", cfg.GetServerAddr())
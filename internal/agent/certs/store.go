@@ -0,0 +1,131 @@
+package certs
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// fingerprintLen 文件名中的指纹长度
+const fingerprintLen = 16
+
+// namePattern 证书名称格式，名称会用于文件名
+var namePattern = regexp.MustCompile(`^[A-Za-z0-9._-]{1,128}$`)
+
+// Store Controller下发证书的本地存储，文件名包含指纹，新旧证书可以同时存在以便应用失败时回退
+type Store struct {
+	dir string
+}
+
+// NewStore 创建证书存储
+func NewStore(dir string) *Store {
+	return &Store{dir: dir}
+}
+
+// Dir 返回证书目录
+func (s *Store) Dir() string {
+	return s.dir
+}
+
+// Fingerprint 计算PEM中叶子证书的sha256指纹
+func Fingerprint(certPEM, keyPEM []byte) (string, error) {
+	pair, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return "", fmt.Errorf("证书与私钥无效或不匹配: %v", err)
+	}
+	sum := sha256.Sum256(pair.Certificate[0])
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// Write 校验证书与私钥后以0600权限写入，fingerprint不为空时需与证书一致，返回证书和私钥的绝对路径
+func (s *Store) Write(name, fingerprint string, certPEM, keyPEM []byte) (string, string, error) {
+	if !namePattern.MatchString(name) {
+		return "", "", fmt.Errorf("证书名称无效: %q", name)
+	}
+	actual, err := Fingerprint(certPEM, keyPEM)
+	if err != nil {
+		return "", "", err
+	}
+	if fingerprint != "" && !strings.EqualFold(fingerprint, actual) {
+		return "", "", fmt.Errorf("证书指纹不匹配: 期望 %s，实际 %s", fingerprint, actual)
+	}
+
+	dir, err := filepath.Abs(s.dir)
+	if err != nil {
+		return "", "", fmt.Errorf("解析证书目录失败: %v", err)
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", "", fmt.Errorf("创建证书目录失败: %v", err)
+	}
+
+	base := filepath.Join(dir, fmt.Sprintf("%s-%s", name, actual[:fingerprintLen]))
+	certPath := base + ".crt"
+	keyPath := base + ".key"
+	if err := writeFile(keyPath, keyPEM); err != nil {
+		return "", "", err
+	}
+	if err := writeFile(certPath, certPEM); err != nil {
+		return "", "", err
+	}
+	return certPath, keyPath, nil
+}
+
+// Cleanup 删除名称为name且不在keep中的证书文件，返回删除的文件
+func (s *Store) Cleanup(name string, keep map[string]bool) []string {
+	dir, err := filepath.Abs(s.dir)
+	if err != nil {
+		return nil
+	}
+
+	var removed []string
+	for _, ext := range []string{".crt", ".key"} {
+		// 文件名为 <name>-<指纹前16位>，按固定长度匹配以免误删名称以name-开头的其他证书
+		matches, err := filepath.Glob(filepath.Join(dir, name+"-"+strings.Repeat("?", fingerprintLen)+ext))
+		if err != nil {
+			continue
+		}
+		for _, path := range matches {
+			if keep[path] {
+				continue
+			}
+			if err := os.Remove(path); err == nil {
+				removed = append(removed, path)
+			}
+		}
+	}
+	return removed
+}
+
+// writeFile 通过临时文件原子写入，权限为0600
+func writeFile(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".cert-*")
+	if err != nil {
+		return fmt.Errorf("创建临时文件失败: %v", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := tmp.Chmod(0600); err != nil {
+		tmp.Close()
+		return fmt.Errorf("设置文件权限失败: %v", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("写入证书文件失败: %v", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("写入证书文件失败: %v", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("写入证书文件失败: %v", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("替换证书文件失败: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,234 @@
+package clashapi
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Endpoint Clash API访问地址
+type Endpoint struct {
+	Addr   string // host:port
+	Secret string
+}
+
+// Metadata 连接元数据，字段名与sing-box Clash API一致
+type Metadata struct {
+	Network         string `json:"network"`
+	Type            string `json:"type"` // 入站类型/入站tag
+	SourceIP        string `json:"sourceIP"`
+	DestinationIP   string `json:"destinationIP"`
+	SourcePort      string `json:"sourcePort"`
+	DestinationPort string `json:"destinationPort"`
+	Host            string `json:"host"`
+	ProcessPath     string `json:"processPath"`
+}
+
+// Connection 一条活动连接
+type Connection struct {
+	ID          string    `json:"id"`
+	Metadata    Metadata  `json:"metadata"`
+	Upload      int64     `json:"upload"`
+	Download    int64     `json:"download"`
+	Start       time.Time `json:"start"`
+	Chains      []string  `json:"chains"` // 出站链，第一个为实际承载流量的出站
+	Rule        string    `json:"rule"`
+	RulePayload string    `json:"rulePayload"`
+}
+
+// Inbound 返回连接所属的入站tag，入站未设置tag时返回入站类型
+func (c Connection) Inbound() string {
+	if idx := strings.IndexByte(c.Metadata.Type, '/'); idx >= 0 {
+		return c.Metadata.Type[idx+1:]
+	}
+	return c.Metadata.Type
+}
+
+// Outbound 返回实际承载连接的出站tag
+func (c Connection) Outbound() string {
+	if len(c.Chains) == 0 {
+		return ""
+	}
+	return c.Chains[0]
+}
+
+// Destination 返回连接目标，优先使用域名
+func (c Connection) Destination() string {
+	host := c.Metadata.Host
+	if host == "" {
+		host = c.Metadata.DestinationIP
+	}
+	if strings.Contains(host, ":") {
+		host = "[" + host + "]"
+	}
+	return host + ":" + c.Metadata.DestinationPort
+}
+
+// String 返回连接的简要描述
+func (c Connection) String() string {
+	return fmt.Sprintf("%s %s:%s -> %s [%s -> %s]",
+		c.Metadata.Network, c.Metadata.SourceIP, c.Metadata.SourcePort, c.Destination(), c.Inbound(), c.Outbound())
+}
+
+// ConnectionsSnapshot /connections返回的连接快照
+type ConnectionsSnapshot struct {
+	DownloadTotal int64        `json:"downloadTotal"`
+	UploadTotal   int64        `json:"uploadTotal"`
+	Connections   []Connection `json:"connections"`
+}
+
+// Traffic /traffic推送的一次实时速率采样（字节/秒）
+type Traffic struct {
+	Up   int64 `json:"up"`
+	Down int64 `json:"down"`
+}
+
+// DelayHistory 一次延迟测试记录，Delay为0表示测试失败
+type DelayHistory struct {
+	Time  time.Time `json:"time"`
+	Delay int       `json:"delay"`
+}
+
+// Proxy /proxies返回的出站状态
+type Proxy struct {
+	Name    string         `json:"name"`
+	Type    string         `json:"type"`
+	Now     string         `json:"now"` // 组当前选择的成员
+	All     []string       `json:"all"` // 组成员
+	History []DelayHistory `json:"history"`
+}
+
+// LastDelay 返回最近一次延迟测试记录，尚未测试时ok为false
+func (p Proxy) LastDelay() (DelayHistory, bool) {
+	if len(p.History) == 0 {
+		return DelayHistory{}, false
+	}
+	return p.History[len(p.History)-1], true
+}
+
+// Client sing-box Clash API客户端
+type Client struct {
+	httpClient *http.Client
+}
+
+// NewClient 创建Clash API客户端
+func NewClient() *Client {
+	return &Client{
+		httpClient: &http.Client{},
+	}
+}
+
+// Connections 获取当前活动连接
+func (c *Client) Connections(ctx context.Context, ep Endpoint) (*ConnectionsSnapshot, error) {
+	resp, err := c.do(ctx, ep, http.MethodGet, "/connections")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var snapshot ConnectionsSnapshot
+	if err := json.NewDecoder(resp.Body).Decode(&snapshot); err != nil {
+		return nil, fmt.Errorf("解析连接列表失败: %v", err)
+	}
+	return &snapshot, nil
+}
+
+// Traffic 读取/traffic流中的一次速率采样
+func (c *Client) Traffic(ctx context.Context, ep Endpoint) (*Traffic, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	resp, err := c.do(ctx, ep, http.MethodGet, "/traffic")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	line, err := bufio.NewReader(resp.Body).ReadBytes('\n')
+	if err != nil && len(line) == 0 {
+		return nil, fmt.Errorf("读取流量采样失败: %v", err)
+	}
+
+	var traffic Traffic
+	if err := json.Unmarshal(line, &traffic); err != nil {
+		return nil, fmt.Errorf("解析流量采样失败: %v", err)
+	}
+	return &traffic, nil
+}
+
+// CloseConnection 关闭指定连接
+func (c *Client) CloseConnection(ctx context.Context, ep Endpoint, id string) error {
+	resp, err := c.do(ctx, ep, http.MethodDelete, "/connections/"+url.PathEscape(id))
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// Proxies 获取全部出站的状态，按出站tag索引
+func (c *Client) Proxies(ctx context.Context, ep Endpoint) (map[string]Proxy, error) {
+	resp, err := c.do(ctx, ep, http.MethodGet, "/proxies")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		Proxies map[string]Proxy `json:"proxies"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("解析出站状态失败: %v", err)
+	}
+	return result.Proxies, nil
+}
+
+// Delay 对指定出站进行一次延迟测试，返回毫秒数
+func (c *Client) Delay(ctx context.Context, ep Endpoint, name, testURL string, timeout time.Duration) (int, error) {
+	query := url.Values{}
+	query.Set("url", testURL)
+	query.Set("timeout", strconv.FormatInt(timeout.Milliseconds(), 10))
+
+	resp, err := c.do(ctx, ep, http.MethodGet, "/proxies/"+url.PathEscape(name)+"/delay?"+query.Encode())
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		Delay int `json:"delay"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return 0, fmt.Errorf("解析延迟测试结果失败: %v", err)
+	}
+	return result.Delay, nil
+}
+
+// do 发送请求并检查状态码，调用方负责关闭响应体
+func (c *Client) do(ctx context.Context, ep Endpoint, method, path string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, "http://"+ep.Addr+path, nil)
+	if err != nil {
+		return nil, fmt.Errorf("创建Clash API请求失败: %v", err)
+	}
+	if ep.Secret != "" {
+		req.Header.Set("Authorization", "Bearer "+ep.Secret)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("请求Clash API失败: %v", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		resp.Body.Close()
+		return nil, fmt.Errorf("Clash API返回 %d %s: %s", resp.StatusCode, method+" "+path, strings.TrimSpace(string(body)))
+	}
+	return resp, nil
+}
@@ -0,0 +1,336 @@
+package clashapi
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/netip"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultPollInterval = 5 * time.Second
+	requestTimeout      = 3 * time.Second
+)
+
+// TagStats 按入站或出站tag聚合的连接与速率
+type TagStats struct {
+	Tag          string `json:"tag"`
+	Connections  int    `json:"connections"`
+	UploadRate   int64  `json:"upload_rate"`   // 字节/秒
+	DownloadRate int64  `json:"download_rate"` // 字节/秒
+}
+
+// Stats 一次采集的连接与流量统计
+type Stats struct {
+	Time          time.Time    `json:"time"`
+	Available     bool         `json:"available"`
+	Error         string       `json:"error,omitempty"`
+	Connections   int          `json:"connections"`
+	UploadRate    int64        `json:"upload_rate"`
+	DownloadRate  int64        `json:"download_rate"`
+	UploadTotal   int64        `json:"upload_total"`
+	DownloadTotal int64        `json:"download_total"`
+	Inbounds      []TagStats   `json:"inbounds"`
+	Outbounds     []TagStats   `json:"outbounds"`
+	Active        []Connection `json:"-"`
+}
+
+// CloseFilter 关闭连接的过滤条件，各条件同时满足才匹配
+type CloseFilter struct {
+	Host     string // 目标域名（含子域名）或目标IP
+	SourceIP string // 来源IP或CIDR
+	Rule     string // 命中的规则（子串，不区分大小写）
+	Inbound  string // 入站tag
+	Outbound string // 出站tag
+	All      bool   // 未指定条件时需显式设置才匹配全部连接
+}
+
+// Empty 判断是否未指定任何条件
+func (f CloseFilter) Empty() bool {
+	return f.Host == "" && f.SourceIP == "" && f.Rule == "" && f.Inbound == "" && f.Outbound == ""
+}
+
+// Validate 校验过滤条件
+func (f CloseFilter) Validate() error {
+	if f.Empty() && !f.All {
+		return fmt.Errorf("未指定过滤条件，关闭全部连接需设置all")
+	}
+	if f.SourceIP != "" {
+		if _, err := parseIPOrPrefix(f.SourceIP); err != nil {
+			return fmt.Errorf("来源IP无效: %s", f.SourceIP)
+		}
+	}
+	return nil
+}
+
+// Match 判断连接是否满足过滤条件
+func (f CloseFilter) Match(conn Connection) bool {
+	if f.Host != "" {
+		host := strings.ToLower(strings.TrimSuffix(f.Host, "."))
+		connHost := strings.ToLower(conn.Metadata.Host)
+		if connHost != host && !strings.HasSuffix(connHost, "."+host) && conn.Metadata.DestinationIP != f.Host {
+			return false
+		}
+	}
+	if f.SourceIP != "" {
+		prefix, err := parseIPOrPrefix(f.SourceIP)
+		addr, addrErr := netip.ParseAddr(conn.Metadata.SourceIP)
+		if err != nil || addrErr != nil || !prefix.Contains(addr.Unmap()) {
+			return false
+		}
+	}
+	if f.Rule != "" && !strings.Contains(strings.ToLower(conn.Rule), strings.ToLower(f.Rule)) {
+		return false
+	}
+	if f.Inbound != "" && conn.Inbound() != f.Inbound {
+		return false
+	}
+	if f.Outbound != "" && conn.Outbound() != f.Outbound {
+		return false
+	}
+	return true
+}
+
+// parseIPOrPrefix 解析单个IP或CIDR
+func parseIPOrPrefix(s string) (netip.Prefix, error) {
+	if prefix, err := netip.ParsePrefix(s); err == nil {
+		return prefix.Masked(), nil
+	}
+	addr, err := netip.ParseAddr(s)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+	addr = addr.Unmap()
+	return netip.PrefixFrom(addr, addr.BitLen()), nil
+}
+
+// Collector 定期轮询Clash API，计算按入站/出站聚合的连接数与速率
+type Collector struct {
+	endpoint func() (Endpoint, bool)
+	client   *Client
+	interval time.Duration
+
+	mu       sync.RWMutex
+	stats    Stats
+	prev     map[string][2]int64 // 连接ID -> 上次采集时的上传/下载字节
+	prevTime time.Time
+
+	stopOnce sync.Once
+	stop     chan struct{}
+}
+
+// NewCollector 创建采集器，endpoint返回当前可用的Clash API地址
+func NewCollector(endpoint func() (Endpoint, bool), interval time.Duration) *Collector {
+	if interval <= 0 {
+		interval = defaultPollInterval
+	}
+	return &Collector{
+		endpoint: endpoint,
+		client:   NewClient(),
+		interval: interval,
+		prev:     make(map[string][2]int64),
+		stop:     make(chan struct{}),
+	}
+}
+
+// Start 启动后台采集循环
+func (c *Collector) Start() {
+	go func() {
+		ticker := time.NewTicker(c.interval)
+		defer ticker.Stop()
+
+		for {
+			c.poll()
+			select {
+			case <-ticker.C:
+			case <-c.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop 停止采集
+func (c *Collector) Stop() {
+	c.stopOnce.Do(func() {
+		close(c.stop)
+	})
+}
+
+// Stats 返回最近一次采集结果
+func (c *Collector) Stats() Stats {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.stats
+}
+
+// poll 执行一次采集并更新统计结果
+func (c *Collector) poll() {
+	now := time.Now()
+	ep, ok := c.endpoint()
+	if !ok {
+		c.setUnavailable(now, "Clash API未启用")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+
+	snapshot, err := c.client.Connections(ctx, ep)
+	if err != nil {
+		c.setUnavailable(now, err.Error())
+		return
+	}
+	traffic, err := c.client.Traffic(ctx, ep)
+	if err != nil {
+		// 速率采样失败不影响连接统计
+		log.Printf("读取sing-box实时流量失败: %v", err)
+		traffic = &Traffic{}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elapsed := now.Sub(c.prevTime).Seconds()
+	inbounds := make(map[string]*TagStats)
+	outbounds := make(map[string]*TagStats)
+	current := make(map[string][2]int64, len(snapshot.Connections))
+
+	for _, conn := range snapshot.Connections {
+		current[conn.ID] = [2]int64{conn.Upload, conn.Download}
+
+		var up, down int64
+		if prev, ok := c.prev[conn.ID]; ok && elapsed > 0 {
+			up = rate(conn.Upload-prev[0], elapsed)
+			down = rate(conn.Download-prev[1], elapsed)
+		} else if age := now.Sub(conn.Start).Seconds(); age > 0 {
+			up = rate(conn.Upload, age)
+			down = rate(conn.Download, age)
+		}
+
+		for _, entry := range []struct {
+			stats map[string]*TagStats
+			tag   string
+		}{{inbounds, conn.Inbound()}, {outbounds, conn.Outbound()}} {
+			stat, ok := entry.stats[entry.tag]
+			if !ok {
+				stat = &TagStats{Tag: entry.tag}
+				entry.stats[entry.tag] = stat
+			}
+			stat.Connections++
+			stat.UploadRate += up
+			stat.DownloadRate += down
+		}
+	}
+
+	c.prev = current
+	c.prevTime = now
+	c.stats = Stats{
+		Time:          now,
+		Available:     true,
+		Connections:   len(snapshot.Connections),
+		UploadRate:    traffic.Up,
+		DownloadRate:  traffic.Down,
+		UploadTotal:   snapshot.UploadTotal,
+		DownloadTotal: snapshot.DownloadTotal,
+		Inbounds:      sortedTagStats(inbounds),
+		Outbounds:     sortedTagStats(outbounds),
+		Active:        snapshot.Connections,
+	}
+}
+
+// setUnavailable 记录Clash API不可用
+func (c *Collector) setUnavailable(now time.Time, reason string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.prev = make(map[string][2]int64)
+	c.prevTime = time.Time{}
+	c.stats = Stats{Time: now, Error: reason}
+}
+
+// CloseConnections 关闭满足条件的连接，返回已关闭的连接ID
+func (c *Collector) CloseConnections(ctx context.Context, filter CloseFilter) ([]string, error) {
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+	ep, ok := c.endpoint()
+	if !ok {
+		return nil, fmt.Errorf("Clash API未启用")
+	}
+
+	snapshot, err := c.client.Connections(ctx, ep)
+	if err != nil {
+		return nil, err
+	}
+
+	var closed []string
+	for _, conn := range snapshot.Connections {
+		if !filter.Match(conn) {
+			continue
+		}
+		if err := c.client.CloseConnection(ctx, ep, conn.ID); err != nil {
+			return closed, fmt.Errorf("关闭连接 %s 失败: %v", conn.ID, err)
+		}
+		closed = append(closed, conn.ID)
+	}
+	return closed, nil
+}
+
+// Proxies 获取出站状态，包括组当前选择的成员和各出站的延迟测试记录
+func (c *Collector) Proxies(ctx context.Context) (map[string]Proxy, error) {
+	ep, ok := c.endpoint()
+	if !ok {
+		return nil, fmt.Errorf("Clash API未启用")
+	}
+	return c.client.Proxies(ctx, ep)
+}
+
+// TestDelays 并发测试多个出站的延迟，返回每个出站的测试错误，成功的出站不在结果中。
+// 测试结果由sing-box记录在出站的延迟历史中，可随后通过Proxies读取
+func (c *Collector) TestDelays(ctx context.Context, names []string, testURL string, timeout time.Duration) (map[string]error, error) {
+	ep, ok := c.endpoint()
+	if !ok {
+		return nil, fmt.Errorf("Clash API未启用")
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	failed := make(map[string]error)
+	for _, name := range names {
+		wg.Add(1)
+		go func(name string) {
+			defer wg.Done()
+			if _, err := c.client.Delay(ctx, ep, name, testURL, timeout); err != nil {
+				mu.Lock()
+				failed[name] = err
+				mu.Unlock()
+			}
+		}(name)
+	}
+	wg.Wait()
+	return failed, nil
+}
+
+// rate 计算每秒速率，计数器回绕时返回0
+func rate(delta int64, seconds float64) int64 {
+	if delta <= 0 || seconds <= 0 {
+		return 0
+	}
+	return int64(float64(delta) / seconds)
+}
+
+// sortedTagStats 按tag排序输出
+func sortedTagStats(stats map[string]*TagStats) []TagStats {
+	result := make([]TagStats, 0, len(stats))
+	for _, stat := range stats {
+		result = append(result, *stat)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Tag < result[j].Tag
+	})
+	return result
+}
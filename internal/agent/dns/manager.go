@@ -0,0 +1,241 @@
+// Package dns 管理sing-box的DNS配置：对服务器、规则和FakeIP的增量修改，以及按版本保存和回滚
+package dns
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/xbox/sing-box-manager/internal/agent/singbox"
+)
+
+// maxVersions 保留的历史版本数
+const maxVersions = 10
+
+// DNSManager DNS配置版本管理器，记录每次生效的DNS配置，可回滚到历史版本
+type DNSManager struct {
+	mu             sync.RWMutex
+	configPath     string
+	versions       []string // 历史版本，从旧到新，不含当前版本
+	currentVersion string
+	config         *singbox.DNSConfig
+}
+
+// DNSSnapshot DNS配置文件结构
+type DNSSnapshot struct {
+	Version   string             `json:"version"`
+	Timestamp time.Time          `json:"timestamp"`
+	Versions  []string           `json:"versions,omitempty"`
+	DNS       *singbox.DNSConfig `json:"dns"`
+}
+
+// NewDNSManager 创建DNS配置版本管理器
+func NewDNSManager(configPath string) *DNSManager {
+	dm := &DNSManager{configPath: configPath}
+	if err := dm.loadConfig(); err != nil && !os.IsNotExist(err) {
+		log.Printf("加载DNS配置版本失败: %v", err)
+	}
+	return dm
+}
+
+// GetCurrentVersion 获取当前DNS配置版本，尚未通过DNS接口修改过时为空
+func (dm *DNSManager) GetCurrentVersion() string {
+	dm.mu.RLock()
+	defer dm.mu.RUnlock()
+	return dm.currentVersion
+}
+
+// GetVersions 获取可回滚的历史版本，从旧到新
+func (dm *DNSManager) GetVersions() []string {
+	dm.mu.RLock()
+	defer dm.mu.RUnlock()
+	return append([]string(nil), dm.versions...)
+}
+
+// Commit 记录已生效的DNS配置为新版本。before为修改前生效的DNS配置，
+// 与当前版本不一致（如期间下发过完整配置）或尚无版本时先将其记录为一个版本，保证可以回滚到修改前的状态
+func (dm *DNSManager) Commit(before, after *singbox.DNSConfig) (string, error) {
+	dm.mu.Lock()
+	defer dm.mu.Unlock()
+
+	if dm.currentVersion == "" || !sameDNS(dm.config, before) {
+		if err := dm.pushVersion(before); err != nil {
+			return "", err
+		}
+	}
+	if err := dm.pushVersion(after); err != nil {
+		return "", err
+	}
+	return dm.currentVersion, nil
+}
+
+// Snapshot 读取指定版本的DNS配置，version为空时为上一个版本
+func (dm *DNSManager) Snapshot(version string) (string, *singbox.DNSConfig, error) {
+	dm.mu.RLock()
+	defer dm.mu.RUnlock()
+
+	if version == "" {
+		if len(dm.versions) == 0 {
+			return "", nil, fmt.Errorf("没有可回滚的DNS配置版本")
+		}
+		version = dm.versions[len(dm.versions)-1]
+	}
+	if version == dm.currentVersion {
+		return version, cloneDNS(dm.config), nil
+	}
+	if dm.versionIndex(version) < 0 {
+		return "", nil, fmt.Errorf("DNS配置版本 %s 不存在", version)
+	}
+
+	data, err := os.ReadFile(dm.backupPath(version))
+	if err != nil {
+		return "", nil, fmt.Errorf("读取DNS配置版本 %s 失败: %v", version, err)
+	}
+	var snapshot DNSSnapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return "", nil, fmt.Errorf("解析DNS配置版本 %s 失败: %v", version, err)
+	}
+	return version, snapshot.DNS, nil
+}
+
+// MarkRolledBack 回滚生效后将当前版本指向目标版本，目标之后的版本被丢弃，再次回滚时继续向前
+func (dm *DNSManager) MarkRolledBack(version string, config *singbox.DNSConfig) error {
+	dm.mu.Lock()
+	defer dm.mu.Unlock()
+
+	if version == dm.currentVersion {
+		return nil
+	}
+	index := dm.versionIndex(version)
+	if index < 0 {
+		return fmt.Errorf("DNS配置版本 %s 不存在", version)
+	}
+
+	discarded := append(append([]string(nil), dm.versions[index+1:]...), dm.currentVersion)
+	dm.versions = dm.versions[:index]
+	dm.currentVersion = version
+	dm.config = cloneDNS(config)
+	for _, v := range discarded {
+		os.Remove(dm.backupPath(v))
+	}
+	os.Remove(dm.backupPath(version))
+	return dm.saveConfigFile()
+}
+
+// pushVersion 备份当前版本并将config记录为新的当前版本
+func (dm *DNSManager) pushVersion(config *singbox.DNSConfig) error {
+	if dm.currentVersion != "" {
+		if err := dm.writeSnapshot(dm.backupPath(dm.currentVersion), dm.currentVersion, nil, dm.config); err != nil {
+			return fmt.Errorf("备份DNS配置失败: %v", err)
+		}
+		dm.versions = append(dm.versions, dm.currentVersion)
+	}
+
+	// 只保留最近的版本
+	if len(dm.versions) > maxVersions {
+		for _, v := range dm.versions[:len(dm.versions)-maxVersions] {
+			os.Remove(dm.backupPath(v))
+		}
+		dm.versions = append([]string(nil), dm.versions[len(dm.versions)-maxVersions:]...)
+	}
+
+	dm.currentVersion = dm.nextVersion()
+	dm.config = cloneDNS(config)
+	return dm.saveConfigFile()
+}
+
+// nextVersion 生成新版本号，同一秒内多次修改时递增以保证唯一
+func (dm *DNSManager) nextVersion() string {
+	next := time.Now().Unix()
+	for _, v := range append(dm.versions, dm.currentVersion) {
+		if n, err := strconv.ParseInt(strings.TrimPrefix(v, "v"), 10, 64); err == nil && n >= next {
+			next = n + 1
+		}
+	}
+	return fmt.Sprintf("v%d", next)
+}
+
+// versionIndex 返回历史版本的位置，不存在时返回-1
+func (dm *DNSManager) versionIndex(version string) int {
+	for i, v := range dm.versions {
+		if v == version {
+			return i
+		}
+	}
+	return -1
+}
+
+// saveConfigFile 保存当前版本
+func (dm *DNSManager) saveConfigFile() error {
+	return dm.writeSnapshot(dm.configPath, dm.currentVersion, dm.versions, dm.config)
+}
+
+// writeSnapshot 写入DNS配置快照
+func (dm *DNSManager) writeSnapshot(path, version string, versions []string, config *singbox.DNSConfig) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("创建配置目录失败: %v", err)
+	}
+
+	data, err := json.MarshalIndent(DNSSnapshot{
+		Version:   version,
+		Timestamp: time.Now(),
+		Versions:  versions,
+		DNS:       config,
+	}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("序列化DNS配置失败: %v", err)
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// loadConfig 加载当前版本
+func (dm *DNSManager) loadConfig() error {
+	data, err := os.ReadFile(dm.configPath)
+	if err != nil {
+		return err
+	}
+
+	var snapshot DNSSnapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return err
+	}
+	dm.currentVersion = snapshot.Version
+	dm.versions = snapshot.Versions
+	dm.config = snapshot.DNS
+	return nil
+}
+
+// backupPath 历史版本的备份文件路径
+func (dm *DNSManager) backupPath(version string) string {
+	return fmt.Sprintf("%s.%s.backup", dm.configPath, version)
+}
+
+// sameDNS 比较两份DNS配置的内容
+func sameDNS(a, b *singbox.DNSConfig) bool {
+	dataA, errA := json.Marshal(a)
+	dataB, errB := json.Marshal(b)
+	return errA == nil && errB == nil && bytes.Equal(dataA, dataB)
+}
+
+// cloneDNS 深拷贝DNS配置（经由JSON往返，包含未建模字段）
+func cloneDNS(config *singbox.DNSConfig) *singbox.DNSConfig {
+	if config == nil {
+		return nil
+	}
+	data, err := json.Marshal(config)
+	if err != nil {
+		return nil
+	}
+	var clone singbox.DNSConfig
+	if err := json.Unmarshal(data, &clone); err != nil {
+		return nil
+	}
+	return &clone
+}
@@ -0,0 +1,424 @@
+package dns
+
+import (
+	"fmt"
+	"net"
+	"net/netip"
+	"net/url"
+	"strings"
+
+	"github.com/xbox/sing-box-manager/internal/agent/singbox"
+)
+
+// DNS服务器和规则操作类型
+const (
+	OpAdd     = "add"     // 添加
+	OpUpdate  = "update"  // 按tag修改服务器
+	OpRemove  = "remove"  // 删除
+	OpMove    = "move"    // 调整顺序
+	OpReplace = "replace" // 替换全部
+)
+
+// DefaultFakeIPInet4Range 未指定时的FakeIP IPv4地址池
+const DefaultFakeIPInet4Range = "198.18.0.0/15"
+
+// fakeIPServerTag 启用FakeIP时自动添加的服务器tag
+const fakeIPServerTag = "fakeip"
+
+// validStrategies sing-box支持的解析策略
+var validStrategies = map[string]bool{
+	"prefer_ipv4": true,
+	"prefer_ipv6": true,
+	"ipv4_only":   true,
+	"ipv6_only":   true,
+}
+
+// serverSchemes DNS服务器地址支持的协议：udp/tcp、DoT(tls)、DoH(https/h3)、DoQ(quic)、DHCP和rcode
+var serverSchemes = map[string]bool{
+	"udp":   true,
+	"tcp":   true,
+	"tls":   true,
+	"https": true,
+	"h3":    true,
+	"quic":  true,
+	"dhcp":  true,
+	"rcode": true,
+}
+
+// ValidateServer 校验DNS服务器的tag和地址
+func ValidateServer(server singbox.DNSServer) error {
+	if server.Tag == "" {
+		return fmt.Errorf("DNS服务器缺少tag")
+	}
+	if server.Strategy != "" && !validStrategies[server.Strategy] {
+		return fmt.Errorf("DNS服务器 %s 的strategy无效: %s", server.Tag, server.Strategy)
+	}
+
+	// sing-box 1.12起的新格式使用type和server字段
+	if server.Address == "" {
+		if _, ok := server.Extra.Get("type"); ok {
+			return nil
+		}
+		return fmt.Errorf("DNS服务器 %s 缺少address", server.Tag)
+	}
+
+	address := server.Address
+	if address == "local" || address == "fakeip" {
+		return nil
+	}
+	if !strings.Contains(address, "://") {
+		if _, err := netip.ParseAddr(address); err == nil {
+			return nil
+		}
+		if host, _, err := net.SplitHostPort(address); err == nil {
+			if _, err := netip.ParseAddr(host); err == nil {
+				return nil
+			}
+		}
+		return fmt.Errorf("DNS服务器 %s 的地址无效: %s，域名地址需指定协议，如 https://dns.google/dns-query", server.Tag, address)
+	}
+
+	u, err := url.Parse(address)
+	if err != nil {
+		return fmt.Errorf("DNS服务器 %s 的地址无效: %v", server.Tag, err)
+	}
+	if !serverSchemes[u.Scheme] {
+		return fmt.Errorf("DNS服务器 %s 的协议不支持: %s", server.Tag, u.Scheme)
+	}
+	if u.Host == "" {
+		return fmt.Errorf("DNS服务器 %s 的地址缺少主机: %s", server.Tag, address)
+	}
+	return nil
+}
+
+// UpdateServers 增删改、移动或替换DNS服务器。
+// add时position为插入位置（从1开始），0表示追加；remove时tags为待删除的tag；move时tags为新的顺序，未列出的服务器保持原顺序排在后面
+func UpdateServers(config *singbox.DNSConfig, operation string, servers []singbox.DNSServer, tags []string, position int) error {
+	switch operation {
+	case OpAdd:
+		if len(servers) == 0 {
+			return fmt.Errorf("DNS服务器列表不能为空")
+		}
+		existing := serverIndex(config.Servers)
+		for _, server := range servers {
+			if err := ValidateServer(server); err != nil {
+				return err
+			}
+			if _, ok := existing[server.Tag]; ok {
+				return fmt.Errorf("DNS服务器 %s 已存在", server.Tag)
+			}
+			existing[server.Tag] = -1
+		}
+		if position < 0 || position > len(config.Servers)+1 {
+			return fmt.Errorf("插入位置超出范围: %d", position)
+		}
+		if position == 0 {
+			position = len(config.Servers) + 1
+		}
+		index := position - 1
+		updated := make([]singbox.DNSServer, 0, len(config.Servers)+len(servers))
+		updated = append(updated, config.Servers[:index]...)
+		updated = append(updated, servers...)
+		config.Servers = append(updated, config.Servers[index:]...)
+
+	case OpUpdate:
+		if len(servers) == 0 {
+			return fmt.Errorf("DNS服务器列表不能为空")
+		}
+		existing := serverIndex(config.Servers)
+		for _, server := range servers {
+			if err := ValidateServer(server); err != nil {
+				return err
+			}
+			if _, ok := existing[server.Tag]; !ok {
+				return fmt.Errorf("DNS服务器 %s 不存在", server.Tag)
+			}
+		}
+		updated := append([]singbox.DNSServer(nil), config.Servers...)
+		for _, server := range servers {
+			updated[existing[server.Tag]] = server
+		}
+		config.Servers = updated
+
+	case OpRemove:
+		if len(tags) == 0 {
+			return fmt.Errorf("未指定待删除的DNS服务器")
+		}
+		remove := make(map[string]bool, len(tags))
+		existing := serverIndex(config.Servers)
+		for _, tag := range tags {
+			if _, ok := existing[tag]; !ok {
+				return fmt.Errorf("DNS服务器 %s 不存在", tag)
+			}
+			remove[tag] = true
+		}
+		kept := make([]singbox.DNSServer, 0, len(config.Servers))
+		for _, server := range config.Servers {
+			if !remove[server.Tag] {
+				kept = append(kept, server)
+			}
+		}
+		if err := checkServerReferences(config, kept, remove); err != nil {
+			return err
+		}
+		config.Servers = kept
+
+	case OpMove:
+		if len(tags) == 0 {
+			return fmt.Errorf("未指定DNS服务器顺序")
+		}
+		existing := serverIndex(config.Servers)
+		seen := make(map[string]bool, len(tags))
+		ordered := make([]singbox.DNSServer, 0, len(config.Servers))
+		for _, tag := range tags {
+			index, ok := existing[tag]
+			if !ok {
+				return fmt.Errorf("DNS服务器 %s 不存在", tag)
+			}
+			if seen[tag] {
+				return fmt.Errorf("DNS服务器 %s 重复", tag)
+			}
+			seen[tag] = true
+			ordered = append(ordered, config.Servers[index])
+		}
+		for _, server := range config.Servers {
+			if !seen[server.Tag] {
+				ordered = append(ordered, server)
+			}
+		}
+		config.Servers = ordered
+
+	case OpReplace:
+		seen := make(map[string]bool, len(servers))
+		for _, server := range servers {
+			if err := ValidateServer(server); err != nil {
+				return err
+			}
+			if seen[server.Tag] {
+				return fmt.Errorf("DNS服务器 %s 重复", server.Tag)
+			}
+			seen[server.Tag] = true
+		}
+		removed := make(map[string]bool)
+		for _, server := range config.Servers {
+			if !seen[server.Tag] {
+				removed[server.Tag] = true
+			}
+		}
+		if err := checkServerReferences(config, servers, removed); err != nil {
+			return err
+		}
+		config.Servers = servers
+
+	default:
+		return fmt.Errorf("不支持的DNS服务器操作: %s", operation)
+	}
+	return nil
+}
+
+// SetDefaults 设置默认DNS服务器和解析策略，参数为空时不修改
+func SetDefaults(config *singbox.DNSConfig, final, strategy string) error {
+	if final != "" {
+		if _, ok := serverIndex(config.Servers)[final]; !ok {
+			return fmt.Errorf("DNS服务器 %s 不存在", final)
+		}
+		config.Final = final
+	}
+	if strategy != "" {
+		if !validStrategies[strategy] {
+			return fmt.Errorf("不支持的解析策略: %s", strategy)
+		}
+		config.Strategy = strategy
+	}
+	return nil
+}
+
+// UpdateRules 添加、删除、移动或替换DNS规则，位置从1开始。
+// add时position为插入位置，0表示追加；remove时positions为待删除规则的位置；move时将positions[0]处的规则移动到position
+func UpdateRules(config *singbox.DNSConfig, operation string, rules []singbox.DNSRule, positions []int, position int) error {
+	switch operation {
+	case OpAdd:
+		if len(rules) == 0 {
+			return fmt.Errorf("DNS规则列表不能为空")
+		}
+		if err := checkRuleServers(config, rules); err != nil {
+			return err
+		}
+		if position < 0 || position > len(config.Rules)+1 {
+			return fmt.Errorf("插入位置超出范围: %d", position)
+		}
+		if position == 0 {
+			position = len(config.Rules) + 1
+		}
+		index := position - 1
+		updated := make([]singbox.DNSRule, 0, len(config.Rules)+len(rules))
+		updated = append(updated, config.Rules[:index]...)
+		updated = append(updated, rules...)
+		config.Rules = append(updated, config.Rules[index:]...)
+
+	case OpRemove:
+		if len(positions) == 0 {
+			return fmt.Errorf("未指定待删除的DNS规则")
+		}
+		remove := make(map[int]bool, len(positions))
+		for _, p := range positions {
+			if p < 1 || p > len(config.Rules) {
+				return fmt.Errorf("DNS规则位置超出范围: %d", p)
+			}
+			remove[p-1] = true
+		}
+		kept := make([]singbox.DNSRule, 0, len(config.Rules))
+		for i, rule := range config.Rules {
+			if !remove[i] {
+				kept = append(kept, rule)
+			}
+		}
+		config.Rules = kept
+
+	case OpMove:
+		if len(positions) != 1 {
+			return fmt.Errorf("移动DNS规则时需指定一个规则位置")
+		}
+		from := positions[0]
+		if from < 1 || from > len(config.Rules) {
+			return fmt.Errorf("DNS规则位置超出范围: %d", from)
+		}
+		if position < 1 || position > len(config.Rules) {
+			return fmt.Errorf("目标位置超出范围: %d", position)
+		}
+		rule := config.Rules[from-1]
+		rest := append(append([]singbox.DNSRule(nil), config.Rules[:from-1]...), config.Rules[from:]...)
+		index := position - 1
+		config.Rules = append(rest[:index], append([]singbox.DNSRule{rule}, rest[index:]...)...)
+
+	case OpReplace:
+		if err := checkRuleServers(config, rules); err != nil {
+			return err
+		}
+		config.Rules = rules
+
+	default:
+		return fmt.Errorf("不支持的DNS规则操作: %s", operation)
+	}
+	return nil
+}
+
+// SetFakeIP 启用或停用FakeIP。启用时设置地址池并在没有fakeip服务器时添加一个；
+// 停用时删除fakeip服务器及引用它们的规则
+func SetFakeIP(config *singbox.DNSConfig, enabled bool, inet4Range, inet6Range string) error {
+	if !enabled {
+		removed := make(map[string]bool)
+		kept := make([]singbox.DNSServer, 0, len(config.Servers))
+		for _, server := range config.Servers {
+			if server.Address == "fakeip" {
+				removed[server.Tag] = true
+				continue
+			}
+			kept = append(kept, server)
+		}
+		if removed[config.Final] {
+			return fmt.Errorf("默认DNS服务器 %s 为fakeip，请先修改final", config.Final)
+		}
+		rules := make([]singbox.DNSRule, 0, len(config.Rules))
+		for _, rule := range config.Rules {
+			if !removed[rule.Server] {
+				rules = append(rules, rule)
+			}
+		}
+		candidate := *config
+		candidate.Rules = rules
+		if err := checkServerReferences(&candidate, kept, removed); err != nil {
+			return err
+		}
+		config.Servers = kept
+		config.Rules = rules
+		config.FakeIP = nil
+		return nil
+	}
+
+	if inet4Range == "" {
+		inet4Range = DefaultFakeIPInet4Range
+	}
+	prefix, err := netip.ParsePrefix(inet4Range)
+	if err != nil || !prefix.Addr().Is4() {
+		return fmt.Errorf("FakeIP IPv4地址池无效: %s", inet4Range)
+	}
+	if inet6Range != "" {
+		prefix, err := netip.ParsePrefix(inet6Range)
+		if err != nil || !prefix.Addr().Is6() {
+			return fmt.Errorf("FakeIP IPv6地址池无效: %s", inet6Range)
+		}
+	}
+
+	if config.FakeIP == nil {
+		config.FakeIP = &singbox.FakeIPConfig{}
+	}
+	config.FakeIP.Enabled = true
+	config.FakeIP.Inet4Range = inet4Range
+	config.FakeIP.Inet6Range = inet6Range
+
+	for _, server := range config.Servers {
+		if server.Address == "fakeip" {
+			return nil
+		}
+	}
+	tag := fakeIPServerTag
+	existing := serverIndex(config.Servers)
+	for i := 2; ; i++ {
+		if _, ok := existing[tag]; !ok {
+			break
+		}
+		tag = fmt.Sprintf("%s-%d", fakeIPServerTag, i)
+	}
+	config.Servers = append(config.Servers, singbox.DNSServer{Tag: tag, Address: "fakeip"})
+	return nil
+}
+
+// serverIndex 返回DNS服务器tag到位置的映射
+func serverIndex(servers []singbox.DNSServer) map[string]int {
+	index := make(map[string]int, len(servers))
+	for i, server := range servers {
+		index[server.Tag] = i
+	}
+	return index
+}
+
+// checkRuleServers 检查规则引用的DNS服务器是否存在
+func checkRuleServers(config *singbox.DNSConfig, rules []singbox.DNSRule) error {
+	existing := serverIndex(config.Servers)
+	for i, rule := range rules {
+		if rule.Server == "" {
+			continue
+		}
+		if _, ok := existing[rule.Server]; !ok {
+			return fmt.Errorf("第 %d 条规则引用的DNS服务器不存在: %s", i+1, rule.Server)
+		}
+	}
+	return nil
+}
+
+// checkServerReferences 检查被删除的DNS服务器是否仍被默认服务器、剩余服务器的地址解析或规则引用
+func checkServerReferences(config *singbox.DNSConfig, servers []singbox.DNSServer, removed map[string]bool) error {
+	if len(removed) == 0 {
+		return nil
+	}
+
+	var refs []string
+	if removed[config.Final] {
+		refs = append(refs, fmt.Sprintf("final引用了 %s", config.Final))
+	}
+	for _, server := range servers {
+		if removed[server.AddressResolver] {
+			refs = append(refs, fmt.Sprintf("服务器 %s 的address_resolver引用了 %s", server.Tag, server.AddressResolver))
+		}
+	}
+	for i, rule := range config.Rules {
+		if removed[rule.Server] {
+			refs = append(refs, fmt.Sprintf("第 %d 条规则引用了 %s", i+1, rule.Server))
+		}
+	}
+	if len(refs) == 0 {
+		return nil
+	}
+	return fmt.Errorf("DNS服务器仍被引用: %s", strings.Join(refs, "; "))
+}
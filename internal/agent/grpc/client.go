@@ -8,12 +8,18 @@ import (
 	"fmt"
 	"io/ioutil"
 	"log"
+	"net"
 	"os"
+	"strconv"
+	"sync"
 	"time"
 
+	"github.com/xbox/sing-box-manager/internal/agent/clashapi"
+	"github.com/xbox/sing-box-manager/internal/agent/dns"
 	"github.com/xbox/sing-box-manager/internal/agent/filter"
 	"github.com/xbox/sing-box-manager/internal/agent/monitor"
 	"github.com/xbox/sing-box-manager/internal/agent/network"
+	"github.com/xbox/sing-box-manager/internal/agent/probe"
 	"github.com/xbox/sing-box-manager/internal/agent/singbox"
 	"github.com/xbox/sing-box-manager/internal/agent/uninstall"
 	"github.com/xbox/sing-box-manager/internal/config"
@@ -32,10 +38,12 @@ type Client struct {
 	token            string
 	registered       bool
 	monitor          *monitor.SystemMonitor
-	singboxMgr       *singbox.Manager
-	filterMgr        *filter.FilterManager
+	instances        map[string]*Instance // sing-box实例，按名称索引
+	instanceNames    []string             // 实例名称，按配置顺序排列
 	ipRangeDetector  *network.IPRangeDetector
 	uninstallManager *uninstall.UninstallManager
+	singboxVersion   string // 已安装的sing-box版本，注册时上报
+	singboxSHA256    string // 已安装的sing-box二进制sha256，注册时上报
 }
 
 // NewClient 创建gRPC客户端实例
@@ -47,14 +55,8 @@ func NewClient(cfg *config.Config) *Client {
 		agentID = fmt.Sprintf("%s-%d", hostname, time.Now().Unix())
 	}
 
-	// 创建sing-box管理器
-	singboxMgr := singbox.NewManager(
-		cfg.Agent.SingBoxBinary,
-		cfg.Agent.SingBoxConfig,
-	)
-	
-	// 创建过滤器管理器
-	filterMgr := filter.NewFilterManager("./configs/filter.json")
+	// 创建sing-box实例
+	instances, instanceNames := newInstances(cfg)
 	
 	// 创建IP段检测器
 	ipRangeDetector := network.NewIPRangeDetector()
@@ -69,13 +71,27 @@ func NewClient(cfg *config.Config) *Client {
 		config:           cfg,
 		agentID:          agentID,
 		monitor:          monitor.NewSystemMonitor(),
-		singboxMgr:       singboxMgr,
-		filterMgr:        filterMgr,
+		instances:        instances,
+		instanceNames:    instanceNames,
 		ipRangeDetector:  ipRangeDetector,
 		uninstallManager: uninstallManager,
 	}
 }
 
+// loopbackListen 校验本地API监听地址，非回环地址时回退到fallback
+func loopbackListen(listen, fallback string) string {
+	host, _, err := net.SplitHostPort(listen)
+	if err != nil {
+		log.Printf("本地API监听地址无效(%s)，使用 %s", listen, fallback)
+		return fallback
+	}
+	if ip := net.ParseIP(host); host != "localhost" && (ip == nil || !ip.IsLoopback()) {
+		log.Printf("本地API仅允许监听回环地址(%s)，使用 %s", listen, fallback)
+		return fallback
+	}
+	return listen
+}
+
 // Connect 连接到Controller
 func (c *Client) Connect() error {
 	var err error
@@ -165,6 +181,10 @@ func (c *Client) Register() error {
 		},
 	}
 	req.Metadata["started"] = time.Now().Format(time.RFC3339)
+	if c.singboxVersion != "" {
+		req.Metadata["singbox_version"] = c.singboxVersion
+		req.Metadata["singbox_sha256"] = c.singboxSHA256
+	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
@@ -185,6 +205,12 @@ func (c *Client) Register() error {
 	return nil
 }
 
+// SetSingboxInstallation 设置已安装的sing-box版本和二进制sha256，在注册前调用
+func (c *Client) SetSingboxInstallation(version, checksum string) {
+	c.singboxVersion = version
+	c.singboxSHA256 = checksum
+}
+
 // SendHeartbeat 发送心跳
 func (c *Client) SendHeartbeat() error {
 	if !c.registered {
@@ -197,6 +223,37 @@ func (c *Client) SendHeartbeat() error {
 		Metrics: c.monitor.CollectMetrics(),
 	}
 
+	// 上报默认实例的sing-box监管状态和连接统计
+	singboxStatus := c.defaultInstance().singboxMgr.GetStatus()
+	for k, v := range singboxStatus {
+		req.Metrics["singbox_"+k] = v
+	}
+
+	// 上报各实例状态，任一实例崩溃循环时标记节点异常
+	for _, inst := range c.Instances() {
+		status := inst.Status()
+		req.Instances = append(req.Instances, status)
+		if inst.name == DefaultInstance {
+			req.ConnectionStats = status.ConnectionStats
+		}
+		if status.State == string(singbox.StateCrashLooping) {
+			req.Status = "error"
+		}
+	}
+
+	// 上报上次心跳以来的出站探测结果，心跳成功后才丢弃
+	var probed []*Instance
+	for _, inst := range c.Instances() {
+		if inst.prober == nil {
+			continue
+		}
+		batch := inst.prober.Pending()
+		for _, result := range batch.Results {
+			req.OutboundProbes = append(req.OutboundProbes, convertProbeResult(inst.name, batch, result))
+		}
+		probed = append(probed, inst)
+	}
+
 	// 检查IP段信息是否有变化（可选发送）
 	currentIPInfo, err := c.ipRangeDetector.DetectIPRange()
 	if err == nil {
@@ -231,10 +288,32 @@ func (c *Client) SendHeartbeat() error {
 		return fmt.Errorf("心跳失败: %s", resp.Message)
 	}
 
+	for _, inst := range probed {
+		inst.prober.Commit()
+	}
+
 	log.Printf("心跳成功，下次间隔: %d秒", resp.NextHeartbeatInterval)
 	return nil
 }
 
+// convertProbeResult 转换出站探测结果
+func convertProbeResult(instance string, batch probe.Batch, result probe.Result) *pb.OutboundProbeResult {
+	return &pb.OutboundProbeResult{
+		Instance:      instance,
+		Outbound:      result.Outbound,
+		TargetType:    result.TargetType,
+		Target:        result.Target,
+		Probes:        int32(result.Probes),
+		Successes:     int32(result.Successes),
+		AvgLatencyMs:  result.AvgLatencyMs(),
+		MaxLatencyMs:  result.MaxLatencyMs,
+		LastLatencyMs: result.LastLatencyMs,
+		LastError:     result.LastError,
+		PeriodStart:   batch.Start.Unix(),
+		PeriodEnd:     batch.End.Unix(),
+	}
+}
+
 // StartHeartbeat 启动心跳循环
 func (c *Client) StartHeartbeat() {
 	interval := time.Duration(c.config.Agent.HeartbeatInterval) * time.Second
@@ -258,8 +337,263 @@ func (c *Client) StartHeartbeat() {
 	}
 }
 
+// StartTrafficCollector 启动Clash API连接与流量采集
+func (c *Client) StartTrafficCollector() {
+	for _, inst := range c.Instances() {
+		if inst.clashCollector != nil {
+			inst.clashCollector.Start()
+		}
+	}
+}
+
+// StartOutboundProbes 启动出站可达性探测
+func (c *Client) StartOutboundProbes() {
+	for _, inst := range c.Instances() {
+		if inst.prober != nil {
+			inst.prober.Start()
+		}
+	}
+}
+
+// StartUsageReport 启动用户流量采集与上报循环
+func (c *Client) StartUsageReport() {
+	var collecting []*Instance
+	for _, inst := range c.Instances() {
+		if inst.usageCollector != nil {
+			collecting = append(collecting, inst)
+		}
+	}
+	if len(collecting) == 0 {
+		return
+	}
+
+	interval := time.Duration(c.config.Agent.V2RayAPI.ReportInterval) * time.Second
+	if interval <= 0 {
+		interval = time.Minute
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		for _, inst := range collecting {
+			if err := c.reportUsage(inst); err != nil {
+				log.Printf("实例 %s 用户流量上报失败: %v", inst.name, err)
+			}
+		}
+	}
+}
+
+// reportUsage 采集实例的一次流量增量并上报，上报失败的增量保留到下一次
+func (c *Client) reportUsage(inst *Instance) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := inst.usageCollector.Poll(ctx); err != nil {
+		return err
+	}
+	if !c.registered {
+		return fmt.Errorf("Agent未注册，流量增量将在下次上报")
+	}
+
+	batch := inst.usageCollector.Pending()
+	if len(batch.Records) == 0 {
+		return nil
+	}
+
+	req := &pb.TrafficUsageReport{
+		AgentId:     c.agentID,
+		PeriodStart: batch.Start.Unix(),
+		PeriodEnd:   batch.End.Unix(),
+		Instance:    inst.name,
+	}
+	for _, record := range batch.Records {
+		req.Usages = append(req.Usages, &pb.TrafficUsage{
+			Scope:    record.Scope,
+			Name:     record.Name,
+			Uplink:   record.Uplink,
+			Downlink: record.Downlink,
+		})
+	}
+
+	resp, err := c.client.ReportTrafficUsage(ctx, req)
+	if err != nil {
+		return fmt.Errorf("发送流量上报失败: %v", err)
+	}
+	if !resp.Success {
+		return fmt.Errorf("Controller拒绝流量上报: %s", resp.Message)
+	}
+
+	inst.usageCollector.Commit(batch)
+	return nil
+}
+
+// ActiveConnections 返回最近一次采集到的活动连接描述
+func (i *Instance) ActiveConnections() []string {
+	if i.clashCollector == nil {
+		return nil
+	}
+	active := i.clashCollector.Stats().Active
+	result := make([]string, 0, len(active))
+	for _, conn := range active {
+		result = append(result, conn.String())
+	}
+	return result
+}
+
+// CloseConnections 关闭满足条件的sing-box连接
+func (i *Instance) CloseConnections(filter clashapi.CloseFilter) ([]string, error) {
+	if i.clashCollector == nil {
+		return nil, fmt.Errorf("未启用Clash API采集")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	return i.clashCollector.CloseConnections(ctx, filter)
+}
+
+// defaultURLTestURL 未指定测试地址时使用的地址，与sing-box urltest的默认值一致
+const defaultURLTestURL = "https://www.gstatic.com/generate_204"
+
+// GetOutboundGroups 获取selector/urltest出站组
+func (i *Instance) GetOutboundGroups() ([]singbox.Outbound, error) {
+	return i.singboxMgr.GetOutboundGroups()
+}
+
+// UpdateOutboundGroup 创建、修改或删除出站组
+func (i *Instance) UpdateOutboundGroup(operation string, group singbox.Outbound) (*singbox.ApplyResult, error) {
+	log.Printf("开始更新出站组: tag=%s, type=%s, operation=%s, members=%v", group.Tag, group.Type, operation, group.Outbounds)
+
+	result, err := i.singboxMgr.UpdateOutboundGroup(operation, group)
+	if err != nil {
+		return result, fmt.Errorf("更新出站组失败: %v", err)
+	}
+
+	log.Printf("出站组更新成功: tag=%s", group.Tag)
+	return result, nil
+}
+
+// UpdateGroupMembers 增删或替换出站组成员
+func (i *Instance) UpdateGroupMembers(tag, operation string, members []string) (*singbox.ApplyResult, error) {
+	log.Printf("开始更新出站组成员: tag=%s, operation=%s, members=%v", tag, operation, members)
+
+	result, err := i.singboxMgr.UpdateGroupMembers(tag, operation, members)
+	if err != nil {
+		return result, fmt.Errorf("更新出站组成员失败: %v", err)
+	}
+
+	log.Printf("出站组成员更新成功: tag=%s", tag)
+	return result, nil
+}
+
+// OutboundProxies 通过Clash API获取出站的当前选择与延迟记录
+func (i *Instance) OutboundProxies() (map[string]clashapi.Proxy, error) {
+	if i.clashCollector == nil {
+		return nil, fmt.Errorf("未启用Clash API采集")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	return i.clashCollector.Proxies(ctx)
+}
+
+// TestGroupDelays 通过Clash API对出站组成员测试一次延迟，testURL为空时使用组的url或默认地址，
+// 返回测试失败的成员及原因
+func (i *Instance) TestGroupDelays(groups []singbox.Outbound, testURL string, timeout time.Duration) (map[string]error, error) {
+	if i.clashCollector == nil {
+		return nil, fmt.Errorf("未启用Clash API采集")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout+10*time.Second)
+	defer cancel()
+
+	// 按测试地址合并成员，所有成员并发测试
+	members := make(map[string][]string)
+	seen := make(map[[2]string]bool)
+	for _, group := range groups {
+		url := testURL
+		if url == "" {
+			url = group.URL
+		}
+		if url == "" {
+			url = defaultURLTestURL
+		}
+		for _, tag := range group.Outbounds {
+			if key := [2]string{url, tag}; !seen[key] {
+				seen[key] = true
+				members[url] = append(members[url], tag)
+			}
+		}
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	var testErr error
+	failed := make(map[string]error)
+	for url, tags := range members {
+		wg.Add(1)
+		go func(url string, tags []string) {
+			defer wg.Done()
+			result, err := i.clashCollector.TestDelays(ctx, tags, url, timeout)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				testErr = err
+				return
+			}
+			for tag, err := range result {
+				failed[tag] = err
+			}
+		}(url, tags)
+	}
+	wg.Wait()
+	if testErr != nil {
+		return nil, testErr
+	}
+	return failed, nil
+}
+
+// convertConnectionStats 将连接统计转换为protobuf格式
+func convertConnectionStats(stats clashapi.Stats) *pb.ConnectionStats {
+	result := &pb.ConnectionStats{
+		Available:        stats.Available,
+		Error:            stats.Error,
+		TotalConnections: int32(stats.Connections),
+		UploadRate:       stats.UploadRate,
+		DownloadRate:     stats.DownloadRate,
+		UploadTotal:      stats.UploadTotal,
+		DownloadTotal:    stats.DownloadTotal,
+		CollectedAt:      stats.Time.Unix(),
+	}
+	for _, tag := range stats.Inbounds {
+		result.Inbounds = append(result.Inbounds, convertTagTraffic(tag))
+	}
+	for _, tag := range stats.Outbounds {
+		result.Outbounds = append(result.Outbounds, convertTagTraffic(tag))
+	}
+	return result
+}
+
+// convertTagTraffic 将单个tag的统计转换为protobuf格式
+func convertTagTraffic(stats clashapi.TagStats) *pb.TagTraffic {
+	return &pb.TagTraffic{
+		Tag:          stats.Tag,
+		Connections:  int32(stats.Connections),
+		UploadRate:   stats.UploadRate,
+		DownloadRate: stats.DownloadRate,
+	}
+}
+
 // Close 关闭连接
 func (c *Client) Close() error {
+	for _, inst := range c.Instances() {
+		if inst.clashCollector != nil {
+			inst.clashCollector.Stop()
+		}
+		if inst.usageCollector != nil {
+			inst.usageCollector.Close()
+		}
+		if inst.prober != nil {
+			inst.prober.Stop()
+		}
+	}
 	if c.conn != nil {
 		return c.conn.Close()
 	}
@@ -277,23 +611,27 @@ func (c *Client) IsRegistered() bool {
 }
 
 // UpdateConfig 处理配置更新
-func (c *Client) UpdateConfig(configData string) error {
+func (i *Instance) UpdateConfig(configData string, force bool) (*singbox.ApplyResult, error) {
 	var config singbox.Config
 	if err := json.Unmarshal([]byte(configData), &config); err != nil {
-		return fmt.Errorf("解析配置失败: %v", err)
+		return nil, fmt.Errorf("解析配置失败: %v", err)
 	}
 
-	if err := c.singboxMgr.UpdateConfig(&config); err != nil {
-		return fmt.Errorf("更新配置失败: %v", err)
+	opts := singbox.DefaultApplyOptions()
+	opts.Source = "grpc"
+	opts.Force = force
+	result := i.singboxMgr.ApplyConfig(&config, opts)
+	if err := result.Err(); err != nil {
+		return result, fmt.Errorf("更新配置失败: %v", err)
 	}
 
 	log.Printf("配置更新成功")
-	return nil
+	return result, nil
 }
 
-// UpdateRules 处理规则更新
-func (c *Client) UpdateRules(rules []*pb.Rule) error {
-	log.Printf("收到规则更新，共 %d 条规则", len(rules))
+// UpdateRules 处理实例的规则更新
+func (i *Instance) UpdateRules(rules []*pb.Rule) error {
+	log.Printf("实例 %s 收到规则更新，共 %d 条规则", i.name, len(rules))
 	
 	// 处理规则内容，这里简化处理
 	// 实际应用中需要根据rule.Type和rule.Content来解析和应用具体规则
@@ -326,12 +664,17 @@ func (c *Client) UpdateRules(rules []*pb.Rule) error {
 	return nil
 }
 
+// SingboxLogs 获取sing-box日志缓冲区
+func (i *Instance) SingboxLogs() *singbox.LogBuffer {
+	return i.singboxMgr.Logs()
+}
+
 // GetStatus 获取Agent状态
 func (c *Client) GetStatus() map[string]string {
 	status := c.monitor.CollectMetrics()
 	
-	// 添加sing-box状态
-	singboxStatus := c.singboxMgr.GetStatus()
+	// 添加默认实例的sing-box状态
+	singboxStatus := c.defaultInstance().singboxMgr.GetStatus()
 	for k, v := range singboxStatus {
 		status["singbox_"+k] = v
 	}
@@ -344,29 +687,69 @@ func (c *Client) GetStatus() map[string]string {
 	return status
 }
 
+// restartPolicyFromConfig 根据Agent配置构建sing-box重启策略
+func restartPolicyFromConfig(cfg config.SupervisorConfig) singbox.RestartPolicy {
+	policy := singbox.DefaultRestartPolicy()
+	policy.Enabled = cfg.Enabled
+	if cfg.InitialBackoff > 0 {
+		policy.InitialBackoff = time.Duration(cfg.InitialBackoff) * time.Second
+	}
+	if cfg.MaxBackoff > 0 {
+		policy.MaxBackoff = time.Duration(cfg.MaxBackoff) * time.Second
+	}
+	if cfg.Multiplier >= 1 {
+		policy.Multiplier = cfg.Multiplier
+	}
+	if cfg.MaxRestarts > 0 {
+		policy.MaxRestarts = cfg.MaxRestarts
+	}
+	if cfg.RestartWindow > 0 {
+		policy.Window = time.Duration(cfg.RestartWindow) * time.Second
+	}
+	if cfg.StableAfter > 0 {
+		policy.StableAfter = time.Duration(cfg.StableAfter) * time.Second
+	}
+	return policy
+}
+
 // StartSingbox 启动sing-box服务
-func (c *Client) StartSingbox() error {
-	return c.singboxMgr.Start()
+func (i *Instance) StartSingbox() error {
+	return i.singboxMgr.Start()
 }
 
 // StopSingbox 停止sing-box服务
-func (c *Client) StopSingbox() error {
-	return c.singboxMgr.Stop()
+func (i *Instance) StopSingbox() error {
+	return i.singboxMgr.Stop()
+}
+
+// SingboxManagedBySystemd 判断sing-box是否由systemd托管
+func (i *Instance) SingboxManagedBySystemd() bool {
+	return i.singboxMgr.ProcessMode() == singbox.ProcessModeSystemd
 }
 
 // RestartSingbox 重启sing-box服务
-func (c *Client) RestartSingbox() error {
-	return c.singboxMgr.Restart()
+func (i *Instance) RestartSingbox() error {
+	return i.singboxMgr.Restart()
+}
+
+// UpgradeSingbox 切换sing-box版本，失败时恢复原二进制
+func (i *Instance) UpgradeSingbox(version, checksum string) (*singbox.UpgradeResult, error) {
+	result := i.singboxMgr.Upgrade(version, checksum, i.installSource)
+	if err := result.Err(); err != nil {
+		return result, err
+	}
+	log.Printf("sing-box实例 %s 已切换版本: %s -> %s", i.name, result.FromVersion, result.CurrentVersion)
+	return result, nil
 }
 
 // UpdateBlacklist 更新黑名单
-func (c *Client) UpdateBlacklist(protocol string, domains, ips, ports []string, operation string) error {
-	if err := c.filterMgr.UpdateBlacklist(protocol, domains, ips, ports, operation); err != nil {
+func (i *Instance) UpdateBlacklist(protocol string, domains, ips, ports []string, operation string) error {
+	if err := i.filterMgr.UpdateBlacklist(protocol, domains, ips, ports, operation); err != nil {
 		return fmt.Errorf("更新黑名单失败: %v", err)
 	}
 	
 	// 重新生成sing-box配置并重启
-	if err := c.regenerateSingboxConfig(); err != nil {
+	if err := i.regenerateSingboxConfig(); err != nil {
 		return fmt.Errorf("重新生成配置失败: %v", err)
 	}
 	
@@ -375,13 +758,13 @@ func (c *Client) UpdateBlacklist(protocol string, domains, ips, ports []string,
 }
 
 // UpdateWhitelist 更新白名单
-func (c *Client) UpdateWhitelist(protocol string, domains, ips, ports []string, operation string) error {
-	if err := c.filterMgr.UpdateWhitelist(protocol, domains, ips, ports, operation); err != nil {
+func (i *Instance) UpdateWhitelist(protocol string, domains, ips, ports []string, operation string) error {
+	if err := i.filterMgr.UpdateWhitelist(protocol, domains, ips, ports, operation); err != nil {
 		return fmt.Errorf("更新白名单失败: %v", err)
 	}
 	
 	// 重新生成sing-box配置并重启
-	if err := c.regenerateSingboxConfig(); err != nil {
+	if err := i.regenerateSingboxConfig(); err != nil {
 		return fmt.Errorf("重新生成配置失败: %v", err)
 	}
 	
@@ -390,13 +773,13 @@ func (c *Client) UpdateWhitelist(protocol string, domains, ips, ports []string,
 }
 
 // GetFilterConfig 获取过滤器配置
-func (c *Client) GetFilterConfig(protocol string) map[string]*filter.ProtocolFilter {
+func (i *Instance) GetFilterConfig(protocol string) map[string]*filter.ProtocolFilter {
 	if protocol == "" {
-		return c.filterMgr.GetAllFilters()
+		return i.filterMgr.GetAllFilters()
 	}
 	
 	result := make(map[string]*filter.ProtocolFilter)
-	if filter, exists := c.filterMgr.GetFilter(protocol); exists {
+	if filter, exists := i.filterMgr.GetFilter(protocol); exists {
 		result[protocol] = filter
 	}
 	
@@ -404,15 +787,15 @@ func (c *Client) GetFilterConfig(protocol string) map[string]*filter.ProtocolFil
 }
 
 // RollbackConfig 回滚配置
-func (c *Client) RollbackConfig(targetVersion, reason string) error {
+func (i *Instance) RollbackConfig(targetVersion, reason string) error {
 	log.Printf("开始配置回滚: target_version=%s, reason=%s", targetVersion, reason)
 	
-	if err := c.filterMgr.Rollback(targetVersion); err != nil {
+	if err := i.filterMgr.Rollback(targetVersion); err != nil {
 		return fmt.Errorf("回滚过滤器配置失败: %v", err)
 	}
 	
 	// 重新生成sing-box配置并重启
-	if err := c.regenerateSingboxConfig(); err != nil {
+	if err := i.regenerateSingboxConfig(); err != nil {
 		return fmt.Errorf("重新生成配置失败: %v", err)
 	}
 	
@@ -420,13 +803,243 @@ func (c *Client) RollbackConfig(targetVersion, reason string) error {
 	return nil
 }
 
+// RollbackSingboxConfig 将sing-box配置回滚到指定代，targetVersion为空时回滚到上一代
+func (i *Instance) RollbackSingboxConfig(targetVersion, reason string) (*singbox.ApplyResult, error) {
+	log.Printf("开始sing-box配置回滚: target_version=%s, reason=%s", targetVersion, reason)
+
+	var version int64
+	if targetVersion != "" {
+		v, err := strconv.ParseInt(targetVersion, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("无效的配置代版本: %s", targetVersion)
+		}
+		version = v
+	}
+
+	result, err := i.singboxMgr.RollbackToGeneration(version, singbox.DefaultApplyOptions())
+	if err != nil {
+		return result, fmt.Errorf("回滚sing-box配置失败: %v", err)
+	}
+
+	log.Printf("sing-box配置回滚成功: 新配置代=%d", result.Version)
+	return result, nil
+}
+
+// ListConfigGenerations 列出sing-box配置历史代
+func (i *Instance) ListConfigGenerations() ([]singbox.Generation, error) {
+	return i.singboxMgr.ListGenerations()
+}
+
+// DiffConfigGenerations 比较两代sing-box配置
+func (i *Instance) DiffConfigGenerations(fromVersion, toVersion int64) (string, error) {
+	return i.singboxMgr.DiffGenerations(fromVersion, toVersion)
+}
+
+// UpdateInboundUsers 增删或替换入站用户，revision非空时需与入站用户的当前修订一致
+func (i *Instance) UpdateInboundUsers(tag, operation string, users []singbox.InboundUser, revision string) (*singbox.ApplyResult, error) {
+	log.Printf("开始更新入站用户: tag=%s, operation=%s, users=%d", tag, operation, len(users))
+
+	result, err := i.singboxMgr.UpdateInboundUsers(tag, operation, users, revision)
+	if err != nil {
+		// 保留ErrUsersRevisionMismatch供调用方判断
+		return result, fmt.Errorf("更新入站用户失败: %w", err)
+	}
+
+	log.Printf("入站用户更新成功: tag=%s", tag)
+	return result, nil
+}
+
+// GetInboundUsers 获取入站类型和用户列表
+func (i *Instance) GetInboundUsers(tag string) (string, []singbox.InboundUser, error) {
+	return i.singboxMgr.GetInboundUsers(tag)
+}
+
+// GetInbounds 获取当前生效的入站定义
+func (i *Instance) GetInbounds() ([]singbox.Inbound, error) {
+	config := i.singboxMgr.GetConfig()
+	if config == nil {
+		return nil, fmt.Errorf("sing-box配置未加载")
+	}
+	return config.Inbounds, nil
+}
+
+// UpdateCertificate 写入Controller下发的证书并切换入站TLS证书，配置应用后sing-box热重载，
+// 当前配置不再引用的同名证书文件（包括应用失败时新写入的文件）会被删除
+func (i *Instance) UpdateCertificate(tag, name, fingerprint string, certPEM, keyPEM []byte) (string, string, *singbox.ApplyResult, error) {
+	log.Printf("开始更新入站证书: tag=%s, name=%s", tag, name)
+
+	certPath, keyPath, err := i.certStore.Write(name, fingerprint, certPEM, keyPEM)
+	if err != nil {
+		return "", "", nil, fmt.Errorf("写入证书失败: %v", err)
+	}
+
+	result, err := i.singboxMgr.SetInboundCertificate(tag, certPath, keyPath)
+	for _, path := range i.certStore.Cleanup(name, i.singboxMgr.CertificatePaths()) {
+		log.Printf("已删除不再使用的证书文件: %s", path)
+	}
+	if err != nil {
+		return certPath, keyPath, result, fmt.Errorf("更新入站证书失败: %v", err)
+	}
+
+	log.Printf("入站证书更新成功: tag=%s, certificate=%s", tag, certPath)
+	return certPath, keyPath, result, nil
+}
+
+// UpdateReality 设置入站的Reality私钥和short_id，配置应用后sing-box热重载
+func (i *Instance) UpdateReality(tag, privateKey string, shortIDs []string, handshake *singbox.RealityHandshake) (*singbox.InboundTLS, *singbox.ApplyResult, error) {
+	log.Printf("开始更新入站Reality密钥: tag=%s, short_ids=%d", tag, len(shortIDs))
+
+	result, err := i.singboxMgr.SetInboundReality(tag, privateKey, shortIDs, handshake)
+	if err != nil {
+		return nil, result, fmt.Errorf("更新入站Reality密钥失败: %v", err)
+	}
+	tls, err := i.singboxMgr.GetInboundTLS(tag)
+	if err != nil {
+		return nil, result, err
+	}
+
+	log.Printf("入站Reality密钥更新成功: tag=%s", tag)
+	return tls, result, nil
+}
+
+// GetInboundTLS 获取入站当前的TLS配置
+func (i *Instance) GetInboundTLS(tag string) (*singbox.InboundTLS, error) {
+	return i.singboxMgr.GetInboundTLS(tag)
+}
+
+// GetDNSConfig 获取当前生效的DNS配置
+func (i *Instance) GetDNSConfig() (*singbox.DNSConfig, error) {
+	config := i.singboxMgr.GetConfig()
+	if config == nil {
+		return nil, fmt.Errorf("sing-box配置未加载")
+	}
+	if config.DNS == nil {
+		return &singbox.DNSConfig{}, nil
+	}
+	return config.DNS, nil
+}
+
+// GetDNSVersion 获取当前DNS配置版本
+func (i *Instance) GetDNSVersion() string {
+	return i.dnsMgr.GetCurrentVersion()
+}
+
+// GetDNSVersions 获取可回滚的DNS配置版本
+func (i *Instance) GetDNSVersions() []string {
+	return i.dnsMgr.GetVersions()
+}
+
+// UpdateDNSServers 增删、修改、移动或替换DNS服务器，并设置默认服务器与解析策略
+func (i *Instance) UpdateDNSServers(operation string, servers []singbox.DNSServer, tags []string, position int, final, strategy string) (*singbox.ApplyResult, error) {
+	log.Printf("开始更新DNS服务器: operation=%s, servers=%d, tags=%v", operation, len(servers), tags)
+
+	result, err := i.updateDNS("dns:servers", func(config *singbox.DNSConfig) error {
+		if operation != "" {
+			if err := dns.UpdateServers(config, operation, servers, tags, position); err != nil {
+				return err
+			}
+		}
+		return dns.SetDefaults(config, final, strategy)
+	})
+	if err != nil {
+		return result, fmt.Errorf("更新DNS服务器失败: %v", err)
+	}
+
+	log.Printf("DNS服务器更新成功: version=%s", i.dnsMgr.GetCurrentVersion())
+	return result, nil
+}
+
+// UpdateDNSRules 增删、移动或替换DNS规则
+func (i *Instance) UpdateDNSRules(operation string, rules []singbox.DNSRule, positions []int, position int) (*singbox.ApplyResult, error) {
+	log.Printf("开始更新DNS规则: operation=%s, rules=%d, positions=%v", operation, len(rules), positions)
+
+	result, err := i.updateDNS("dns:rules", func(config *singbox.DNSConfig) error {
+		return dns.UpdateRules(config, operation, rules, positions, position)
+	})
+	if err != nil {
+		return result, fmt.Errorf("更新DNS规则失败: %v", err)
+	}
+
+	log.Printf("DNS规则更新成功: version=%s", i.dnsMgr.GetCurrentVersion())
+	return result, nil
+}
+
+// UpdateFakeIP 启用或关闭FakeIP并设置地址段
+func (i *Instance) UpdateFakeIP(enabled bool, inet4Range, inet6Range string) (*singbox.ApplyResult, error) {
+	log.Printf("开始更新FakeIP: enabled=%t, inet4_range=%s, inet6_range=%s", enabled, inet4Range, inet6Range)
+
+	result, err := i.updateDNS("dns:fakeip", func(config *singbox.DNSConfig) error {
+		return dns.SetFakeIP(config, enabled, inet4Range, inet6Range)
+	})
+	if err != nil {
+		return result, fmt.Errorf("更新FakeIP失败: %v", err)
+	}
+
+	log.Printf("FakeIP更新成功: version=%s", i.dnsMgr.GetCurrentVersion())
+	return result, nil
+}
+
+// RollbackDNSConfig 将DNS配置回滚到指定版本，targetVersion为空时回滚到上一个版本，配置的其余部分保持不变
+func (i *Instance) RollbackDNSConfig(targetVersion, reason string) (*singbox.ApplyResult, error) {
+	log.Printf("开始DNS配置回滚: target_version=%s, reason=%s", targetVersion, reason)
+
+	version, snapshot, err := i.dnsMgr.Snapshot(targetVersion)
+	if err != nil {
+		return nil, fmt.Errorf("回滚DNS配置失败: %v", err)
+	}
+	config := i.singboxMgr.GetConfig()
+	if config == nil {
+		return nil, fmt.Errorf("回滚DNS配置失败: sing-box配置未加载")
+	}
+	config.DNS = snapshot
+
+	opts := singbox.DefaultApplyOptions()
+	opts.Source = "dns-rollback:" + version
+	result := i.singboxMgr.ApplyConfig(config, opts)
+	if err := result.Err(); err != nil {
+		return result, fmt.Errorf("回滚DNS配置失败: %v", err)
+	}
+	if err := i.dnsMgr.MarkRolledBack(version, snapshot); err != nil {
+		log.Printf("记录DNS配置版本失败: %v", err)
+	}
+
+	log.Printf("DNS配置回滚成功: version=%s", version)
+	return result, nil
+}
+
+// updateDNS 在当前生效配置上修改DNS部分并应用，其余配置保持不变，生效后记录为新的DNS配置版本
+func (i *Instance) updateDNS(source string, mutate func(*singbox.DNSConfig) error) (*singbox.ApplyResult, error) {
+	config := i.singboxMgr.GetConfig()
+	if config == nil {
+		return nil, fmt.Errorf("sing-box配置未加载")
+	}
+	before := config.Clone().DNS
+	if config.DNS == nil {
+		config.DNS = &singbox.DNSConfig{}
+	}
+	if err := mutate(config.DNS); err != nil {
+		return nil, err
+	}
+
+	opts := singbox.DefaultApplyOptions()
+	opts.Source = source
+	result := i.singboxMgr.ApplyConfig(config, opts)
+	if err := result.Err(); err != nil {
+		return result, err
+	}
+	if _, err := i.dnsMgr.Commit(before, config.DNS); err != nil {
+		log.Printf("记录DNS配置版本失败: %v", err)
+	}
+	return result, nil
+}
+
 // regenerateSingboxConfig 重新生成sing-box配置
-func (c *Client) regenerateSingboxConfig() error {
+func (i *Instance) regenerateSingboxConfig() error {
 	// 获取过滤器规则
-	filterRules := c.filterMgr.GenerateRouteRules()
+	filterRules := i.filterMgr.GenerateRouteRules()
 	
 	// 读取基础配置模板
-	baseConfig, err := c.loadBaseSingboxConfig()
+	baseConfig, err := i.loadBaseSingboxConfig()
 	if err != nil {
 		return fmt.Errorf("加载基础配置失败: %v", err)
 	}
@@ -466,14 +1079,14 @@ func (c *Client) regenerateSingboxConfig() error {
 	baseConfig.Route.Rules = newRules
 	
 	// 更新sing-box配置
-	return c.singboxMgr.UpdateConfig(baseConfig)
+	return i.singboxMgr.UpdateConfig(baseConfig, "filter")
 }
 
 // loadBaseSingboxConfig 加载基础sing-box配置
-func (c *Client) loadBaseSingboxConfig() (*singbox.Config, error) {
+func (i *Instance) loadBaseSingboxConfig() (*singbox.Config, error) {
 	// 这里可以从模板文件加载基础配置
 	// 或者从当前配置中提取基础部分
-	current := c.singboxMgr.GetConfig()
+	current := i.singboxMgr.GetConfig()
 	if current != nil {
 		return current, nil
 	}
@@ -515,12 +1128,12 @@ func (c *Client) loadBaseSingboxConfig() (*singbox.Config, error) {
 }
 
 // GetFilterVersion 获取当前过滤器配置版本
-func (c *Client) GetFilterVersion() string {
-	return c.filterMgr.GetCurrentVersion()
+func (i *Instance) GetFilterVersion() string {
+	return i.filterMgr.GetCurrentVersion()
 }
 
 // UpdateMultiplexConfig 更新多路复用配置
-func (c *Client) UpdateMultiplexConfig(protocol string, config map[string]interface{}) error {
+func (i *Instance) UpdateMultiplexConfig(protocol string, config map[string]interface{}) error {
 	log.Printf("开始更新多路复用配置: protocol=%s", protocol)
 	
 	// 验证协议类型
@@ -554,7 +1167,7 @@ func (c *Client) UpdateMultiplexConfig(protocol string, config map[string]interf
 		enabled, maxConnections, minStreams, padding)
 	
 	// 更新sing-box配置中的多路复用设置
-	if err := c.updateSingboxMultiplex(protocol, enabled, maxConnections, minStreams, padding, brutalConfig); err != nil {
+	if err := i.updateSingboxMultiplex(protocol, enabled, maxConnections, minStreams, padding, brutalConfig); err != nil {
 		return fmt.Errorf("更新sing-box多路复用配置失败: %v", err)
 	}
 	
@@ -563,8 +1176,8 @@ func (c *Client) UpdateMultiplexConfig(protocol string, config map[string]interf
 }
 
 // GetMultiplexConfig 获取多路复用配置
-func (c *Client) GetMultiplexConfig(protocol string) (map[string]interface{}, error) {
-	config := c.singboxMgr.GetConfig()
+func (i *Instance) GetMultiplexConfig(protocol string) (map[string]interface{}, error) {
+	config := i.singboxMgr.GetConfig()
 	if config == nil {
 		return nil, fmt.Errorf("未找到sing-box配置")
 	}
@@ -573,7 +1186,7 @@ func (c *Client) GetMultiplexConfig(protocol string) (map[string]interface{}, er
 	
 	// 如果指定了协议，返回该协议的多路复用配置
 	if protocol != "" {
-		multiplexConfig := c.extractMultiplexConfigFromOutbound(config, protocol)
+		multiplexConfig := i.extractMultiplexConfigFromOutbound(config, protocol)
 		if multiplexConfig != nil {
 			result[protocol] = multiplexConfig
 		}
@@ -583,7 +1196,7 @@ func (c *Client) GetMultiplexConfig(protocol string) (map[string]interface{}, er
 	// 如果未指定协议，返回所有协议的多路复用配置
 	supportedProtocols := []string{"vmess", "vless", "trojan", "shadowsocks"}
 	for _, proto := range supportedProtocols {
-		multiplexConfig := c.extractMultiplexConfigFromOutbound(config, proto)
+		multiplexConfig := i.extractMultiplexConfigFromOutbound(config, proto)
 		if multiplexConfig != nil {
 			result[proto] = multiplexConfig
 		}
@@ -593,8 +1206,8 @@ func (c *Client) GetMultiplexConfig(protocol string) (map[string]interface{}, er
 }
 
 // updateSingboxMultiplex 更新sing-box配置中的多路复用设置
-func (c *Client) updateSingboxMultiplex(protocol string, enabled bool, maxConnections, minStreams int, padding bool, brutalConfig map[string]interface{}) error {
-	config := c.singboxMgr.GetConfig()
+func (i *Instance) updateSingboxMultiplex(protocol string, enabled bool, maxConnections, minStreams int, padding bool, brutalConfig map[string]interface{}) error {
+	config := i.singboxMgr.GetConfig()
 	if config == nil {
 		return fmt.Errorf("未找到当前配置")
 	}
@@ -615,8 +1228,8 @@ func (c *Client) updateSingboxMultiplex(protocol string, enabled bool, maxConnec
 	updated := false
 	updatedOutbounds := []string{}
 	
-	for i := range config.Outbounds {
-		outbound := &config.Outbounds[i]
+	for idx := range config.Outbounds {
+		outbound := &config.Outbounds[idx]
 		if outbound.Type == protocol {
 			log.Printf("找到匹配的出站配置: Tag=%s, Type=%s", outbound.Tag, outbound.Type)
 			
@@ -680,7 +1293,7 @@ func (c *Client) updateSingboxMultiplex(protocol string, enabled bool, maxConnec
 	
 	// 应用更新后的配置
 	log.Printf("正在应用多路复用配置到sing-box...")
-	if err := c.singboxMgr.UpdateConfig(config); err != nil {
+	if err := i.singboxMgr.UpdateConfig(config, "multiplex"); err != nil {
 		log.Printf("应用sing-box配置失败: %v", err)
 		return fmt.Errorf("应用sing-box配置失败: %v", err)
 	}
@@ -690,7 +1303,7 @@ func (c *Client) updateSingboxMultiplex(protocol string, enabled bool, maxConnec
 }
 
 // extractMultiplexConfigFromOutbound 从出站配置中提取多路复用配置
-func (c *Client) extractMultiplexConfigFromOutbound(config *singbox.Config, protocol string) map[string]interface{} {
+func (i *Instance) extractMultiplexConfigFromOutbound(config *singbox.Config, protocol string) map[string]interface{} {
 	for _, outbound := range config.Outbounds {
 		if outbound.Type == protocol && outbound.Multiplex != nil {
 			result := make(map[string]interface{})
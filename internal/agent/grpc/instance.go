@@ -0,0 +1,318 @@
+package grpc
+
+import (
+	"fmt"
+	"log"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/xbox/sing-box-manager/internal/agent/certs"
+	"github.com/xbox/sing-box-manager/internal/agent/clashapi"
+	"github.com/xbox/sing-box-manager/internal/agent/dns"
+	"github.com/xbox/sing-box-manager/internal/agent/filter"
+	"github.com/xbox/sing-box-manager/internal/agent/probe"
+	"github.com/xbox/sing-box-manager/internal/agent/singbox"
+	"github.com/xbox/sing-box-manager/internal/agent/usage"
+	"github.com/xbox/sing-box-manager/internal/config"
+	pb "github.com/xbox/sing-box-manager/proto/agent"
+)
+
+// DefaultInstance 默认sing-box实例名称，对应agent.singbox_config等顶层配置
+const DefaultInstance = "default"
+
+// instanceNamePattern 实例名称格式，名称会用于systemd unit和过滤器配置文件名
+var instanceNamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,32}$`)
+
+// Instance 一个隔离的sing-box实例，拥有独立的二进制、配置文件、配置历史、进程监管、过滤器与DNS配置版本
+type Instance struct {
+	name           string
+	binaryPath     string
+	configPath     string
+	singboxMgr     *singbox.Manager
+	filterMgr      *filter.FilterManager
+	dnsMgr         *dns.DNSManager
+	clashCollector *clashapi.Collector    // 未启用Clash API采集时为nil
+	usageCollector *usage.Collector       // 未启用V2Ray API统计时为nil
+	prober         *probe.Prober          // 未启用出站探测时为nil
+	certStore      *certs.Store           // Controller下发的TLS证书
+	installSource  singbox.InstallOptions // 版本切换时使用的制品来源
+}
+
+// instanceOptions 创建实例所需的参数
+type instanceOptions struct {
+	name           string
+	binaryPath     string
+	configPath     string
+	filterPath     string
+	dnsPath        string
+	processMode    string
+	systemdUnit    string
+	clashAPIListen string // 为空时不注入Clash API
+	v2rayAPIListen string // 为空时不启用V2Ray API统计
+	probeListen    string // 为空时不探测出站
+}
+
+// newInstances 根据Agent配置创建默认实例和额外实例，返回按配置顺序排列的实例名称
+func newInstances(cfg *config.Config) (map[string]*Instance, []string) {
+	defaults := instanceOptions{
+		name:        DefaultInstance,
+		binaryPath:  cfg.Agent.SingBoxBinary,
+		configPath:  cfg.Agent.SingBoxConfig,
+		filterPath:  "./configs/filter.json",
+		dnsPath:     "./configs/dns.json",
+		processMode: cfg.Agent.ProcessMode,
+		systemdUnit: cfg.Agent.Systemd.Unit,
+	}
+	if cfg.Agent.ClashAPI.Enabled {
+		defaults.clashAPIListen = loopbackListen(cfg.Agent.ClashAPI.Listen, "127.0.0.1:19090")
+	}
+	if cfg.Agent.V2RayAPI.Enabled {
+		defaults.v2rayAPIListen = loopbackListen(cfg.Agent.V2RayAPI.Listen, "127.0.0.1:10085")
+	}
+	if cfg.Agent.Probe.Enabled {
+		defaults.probeListen = loopbackListen(cfg.Agent.Probe.Listen, "127.0.0.1:19080")
+	}
+
+	instances := map[string]*Instance{DefaultInstance: newInstance(cfg, defaults)}
+	names := []string{DefaultInstance}
+	configPaths := map[string]string{absPath(defaults.configPath): DefaultInstance}
+
+	for _, ic := range cfg.Agent.Instances {
+		if !instanceNamePattern.MatchString(ic.Name) {
+			log.Printf("忽略sing-box实例配置: 名称无效(%q)", ic.Name)
+			continue
+		}
+		if _, exists := instances[ic.Name]; exists {
+			log.Printf("忽略sing-box实例配置: 名称重复(%s)", ic.Name)
+			continue
+		}
+		if ic.SingBoxConfig == "" {
+			log.Printf("忽略sing-box实例 %s: 未指定singbox_config", ic.Name)
+			continue
+		}
+		if owner, exists := configPaths[absPath(ic.SingBoxConfig)]; exists {
+			log.Printf("忽略sing-box实例 %s: 配置文件与实例 %s 相同", ic.Name, owner)
+			continue
+		}
+
+		opts := instanceOptions{
+			name:        ic.Name,
+			binaryPath:  ic.SingBoxBinary,
+			configPath:  ic.SingBoxConfig,
+			filterPath:  fmt.Sprintf("./configs/filter-%s.json", ic.Name),
+			dnsPath:     fmt.Sprintf("./configs/dns-%s.json", ic.Name),
+			processMode: ic.ProcessMode,
+			systemdUnit: "sing-box-" + ic.Name,
+		}
+		if opts.binaryPath == "" {
+			opts.binaryPath = cfg.Agent.SingBoxBinary
+		}
+		if opts.processMode == "" {
+			opts.processMode = cfg.Agent.ProcessMode
+		}
+		if cfg.Agent.ClashAPI.Enabled && ic.ClashAPIListen != "" {
+			opts.clashAPIListen = loopbackListen(ic.ClashAPIListen, "")
+		}
+		if cfg.Agent.V2RayAPI.Enabled && ic.V2RayAPIListen != "" {
+			opts.v2rayAPIListen = loopbackListen(ic.V2RayAPIListen, "")
+		}
+		if cfg.Agent.Probe.Enabled && ic.ProbeListen != "" {
+			opts.probeListen = loopbackListen(ic.ProbeListen, "")
+		}
+
+		instances[ic.Name] = newInstance(cfg, opts)
+		names = append(names, ic.Name)
+		configPaths[absPath(ic.SingBoxConfig)] = ic.Name
+		log.Printf("已加载sing-box实例: %s (配置: %s)", ic.Name, ic.SingBoxConfig)
+	}
+
+	return instances, names
+}
+
+// newInstance 创建sing-box实例
+func newInstance(cfg *config.Config, opts instanceOptions) *Instance {
+	singboxMgr := singbox.NewManager(opts.binaryPath, opts.configPath)
+	singboxMgr.SetRestartPolicy(restartPolicyFromConfig(cfg.Agent.Supervisor))
+	singboxMgr.SetHistoryLimit(cfg.Agent.ConfigHistory)
+	singboxMgr.SetLogBufferSize(cfg.Agent.LogBufferLines)
+	if err := singboxMgr.SetProcessMode(singbox.ProcessMode(opts.processMode), singbox.SystemdOptions{
+		Unit:    opts.systemdUnit,
+		UnitDir: cfg.Agent.Systemd.UnitDir,
+	}); err != nil {
+		log.Printf("设置sing-box实例 %s 托管方式失败，使用子进程方式: %v", opts.name, err)
+	}
+	if cfg.Agent.Resources.Enabled {
+		if err := setupCgroup(singboxMgr, opts.name, cfg.Agent.Resources); err != nil {
+			log.Printf("设置sing-box实例 %s 的cgroup资源限制失败，不限制资源: %v", opts.name, err)
+		}
+	}
+
+	// 自动注入本地Clash API并采集连接与流量
+	var clashCollector *clashapi.Collector
+	if opts.clashAPIListen != "" {
+		singboxMgr.SetClashAPIListen(opts.clashAPIListen)
+		clashCollector = clashapi.NewCollector(func() (clashapi.Endpoint, bool) {
+			addr, secret, ok := singboxMgr.ClashAPIEndpoint()
+			return clashapi.Endpoint{Addr: addr, Secret: secret}, ok
+		}, time.Duration(cfg.Agent.ClashAPI.PollInterval)*time.Second)
+	}
+
+	// 启用V2Ray API统计服务并按用户采集流量
+	var usageCollector *usage.Collector
+	if opts.v2rayAPIListen != "" {
+		singboxMgr.SetV2RayAPIListen(opts.v2rayAPIListen)
+		usageCollector = usage.NewCollector(singboxMgr.V2RayAPIEndpoint)
+	}
+
+	// 注入探测入站并定期探测各出站
+	var prober *probe.Prober
+	if opts.probeListen != "" {
+		singboxMgr.SetProbeListen(opts.probeListen)
+		prober = probe.NewProber(func() (probe.Endpoint, bool) {
+			ep, ok := singboxMgr.ProbeEndpoint()
+			return probe.Endpoint{Addr: ep.Addr, Password: ep.Password, Outbounds: ep.Outbounds}, ok
+		}, probeOptions(cfg.Agent.Probe))
+	}
+
+	return &Instance{
+		name:           opts.name,
+		binaryPath:     opts.binaryPath,
+		configPath:     opts.configPath,
+		singboxMgr:     singboxMgr,
+		filterMgr:      filter.NewFilterManager(opts.filterPath),
+		dnsMgr:         dns.NewDNSManager(opts.dnsPath),
+		clashCollector: clashCollector,
+		usageCollector: usageCollector,
+		prober:         prober,
+		certStore:      certs.NewStore(filepath.Join(cfg.Agent.CertDir, opts.name)),
+		installSource: singbox.InstallOptions{
+			Source:    cfg.Agent.Install.Source,
+			MirrorURL: cfg.Agent.Install.MirrorURL,
+			LocalDir:  cfg.Agent.Install.LocalDir,
+			PublicKey: cfg.Agent.Install.PublicKey,
+		},
+	}
+}
+
+// probeOptions 转换出站探测配置
+func probeOptions(cfg config.ProbeConfig) probe.Options {
+	opts := probe.Options{
+		Interval:  time.Duration(cfg.Interval) * time.Second,
+		Timeout:   time.Duration(cfg.Timeout) * time.Second,
+		Outbounds: cfg.Outbounds,
+	}
+	for _, target := range cfg.Targets {
+		opts.Targets = append(opts.Targets, probe.Target{
+			Type:    target.Type,
+			Address: target.Address,
+			Domain:  target.Domain,
+		})
+	}
+	return opts
+}
+
+// setupCgroup 将实例放入独立cgroup，cgroup名称与实例名称相同
+func setupCgroup(singboxMgr *singbox.Manager, name string, cfg config.ResourcesConfig) error {
+	limits, err := singbox.ParseResourceLimits(cfg.MemoryMax, cfg.CPUMax, cfg.PidsMax)
+	if err != nil {
+		return err
+	}
+	return singboxMgr.SetCgroup(singbox.CgroupOptions{
+		Root:   cfg.CgroupRoot,
+		Parent: cfg.CgroupParent,
+		Name:   name,
+		Limits: limits,
+	})
+}
+
+// absPath 返回绝对路径，失败时原样返回
+func absPath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return path
+}
+
+// Instance 按名称获取sing-box实例，名称为空时返回默认实例
+func (c *Client) Instance(name string) (*Instance, error) {
+	if name == "" {
+		name = DefaultInstance
+	}
+	inst, ok := c.instances[name]
+	if !ok {
+		return nil, fmt.Errorf("sing-box实例不存在: %s", name)
+	}
+	return inst, nil
+}
+
+// Instances 返回按配置顺序排列的全部sing-box实例
+func (c *Client) Instances() []*Instance {
+	result := make([]*Instance, 0, len(c.instanceNames))
+	for _, name := range c.instanceNames {
+		result = append(result, c.instances[name])
+	}
+	return result
+}
+
+// defaultInstance 返回默认实例
+func (c *Client) defaultInstance() *Instance {
+	return c.instances[DefaultInstance]
+}
+
+// Name 返回实例名称
+func (i *Instance) Name() string {
+	return i.name
+}
+
+// BinaryPath 返回实例使用的sing-box二进制路径
+func (i *Instance) BinaryPath() string {
+	return i.binaryPath
+}
+
+// ConfigPath 返回实例的sing-box配置文件路径
+func (i *Instance) ConfigPath() string {
+	return i.configPath
+}
+
+// Status 返回实例状态
+func (i *Instance) Status() *pb.InstanceStatus {
+	status := i.singboxMgr.GetStatus()
+	sup := i.singboxMgr.SupervisorStatus()
+	pid, _ := strconv.Atoi(status["pid"])
+
+	result := &pb.InstanceStatus{
+		Name:        i.name,
+		State:       string(sup.State),
+		Running:     status["running"] == "true",
+		Pid:         int32(pid),
+		ProcessMode: status["process_mode"],
+		BinaryPath:  status["binary_path"],
+		ConfigPath:  status["config_path"],
+		Restarts:    int32(sup.Restarts),
+	}
+	if gens, err := i.singboxMgr.ListGenerations(); err == nil && len(gens) > 0 {
+		result.ConfigVersion = gens[len(gens)-1].Version
+	}
+	if i.clashCollector != nil {
+		result.ConnectionStats = convertConnectionStats(i.clashCollector.Stats())
+	}
+	if usage, err := i.singboxMgr.ResourceUsage(); err == nil {
+		result.ResourceUsage = &pb.ResourceUsage{
+			Cgroup:           usage.Cgroup,
+			MemoryCurrent:    usage.MemoryCurrent,
+			MemoryMax:        usage.MemoryMax,
+			CpuUsageUsec:     usage.CPUUsageUsec,
+			CpuThrottledUsec: usage.CPUThrottledUsec,
+			NrThrottled:      usage.NrThrottled,
+			PidsCurrent:      usage.PidsCurrent,
+			PidsMax:          usage.PidsMax,
+			OomKills:         usage.OOMKills,
+		}
+	}
+	if sup.LastExit != nil {
+		result.LastExitOomKilled = sup.LastExit.OOMKilled
+	}
+	return result
+}
@@ -2,13 +2,20 @@ package grpc
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net"
+	"strconv"
 	"time"
 
+	"github.com/xbox/sing-box-manager/internal/agent/clashapi"
+	"github.com/xbox/sing-box-manager/internal/agent/singbox"
 	pb "github.com/xbox/sing-box-manager/proto/agent"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 // Server Agent gRPC服务器
@@ -83,6 +90,14 @@ func (s *Server) UpdateMultiplexConfig(ctx context.Context, req *pb.MultiplexCon
 		}, nil
 	}
 
+	inst, err := s.client.Instance(req.Instance)
+	if err != nil {
+		return &pb.MultiplexConfigResponse{
+			Success: false,
+			Message: err.Error(),
+		}, nil
+	}
+
 	// 转换protobuf配置为map格式
 	config := make(map[string]interface{})
 	if req.MultiplexConfig != nil {
@@ -103,7 +118,7 @@ func (s *Server) UpdateMultiplexConfig(ctx context.Context, req *pb.MultiplexCon
 
 	// 调用客户端的多路复用配置更新方法
 	startTime := time.Now()
-	err := s.client.UpdateMultiplexConfig(req.Protocol, config)
+	err = inst.UpdateMultiplexConfig(req.Protocol, config)
 	duration := time.Since(startTime)
 
 	configVersion := fmt.Sprintf("v%d", time.Now().Unix())
@@ -143,8 +158,16 @@ func (s *Server) GetMultiplexConfig(ctx context.Context, req *pb.MultiplexStatus
 		}, nil
 	}
 
+	inst, err := s.client.Instance(req.Instance)
+	if err != nil {
+		return &pb.MultiplexStatusResponse{
+			Success: false,
+			Message: err.Error(),
+		}, nil
+	}
+
 	// 获取多路复用配置
-	configs, err := s.client.GetMultiplexConfig(req.Protocol)
+	configs, err := inst.GetMultiplexConfig(req.Protocol)
 	if err != nil {
 		log.Printf("获取多路复用配置失败: %v", err)
 		return &pb.MultiplexStatusResponse{
@@ -226,7 +249,7 @@ func (s *Server) GetMultiplexConfig(ctx context.Context, req *pb.MultiplexStatus
 
 // UpdateConfig 处理配置更新请求（保留原有接口兼容性）
 func (s *Server) UpdateConfig(ctx context.Context, req *pb.ConfigRequest) (*pb.ConfigResponse, error) {
-	log.Printf("收到配置更新请求: Agent=%s, Version=%s", req.AgentId, req.ConfigVersion)
+	log.Printf("收到配置更新请求: Agent=%s, Version=%s, Force=%t", req.AgentId, req.ConfigVersion, req.ForceUpdate)
 	
 	if req.AgentId != s.client.GetAgentID() {
 		return &pb.ConfigResponse{
@@ -235,50 +258,1233 @@ func (s *Server) UpdateConfig(ctx context.Context, req *pb.ConfigRequest) (*pb.C
 		}, nil
 	}
 
-	// 调用客户端的配置更新方法
-	err := s.client.UpdateConfig(req.ConfigContent)
+	inst, err := s.client.Instance(req.Instance)
 	if err != nil {
-		log.Printf("配置更新失败: %v", err)
 		return &pb.ConfigResponse{
 			Success:        false,
-			Message:        fmt.Sprintf("配置更新失败: %v", err),
+			Message:        err.Error(),
 			AppliedVersion: req.ConfigVersion,
 		}, nil
 	}
 
+	// 调用实例的配置更新方法
+	result, err := inst.UpdateConfig(req.ConfigContent, req.ForceUpdate)
+	if err != nil {
+		log.Printf("配置更新失败: %v", err)
+		resp := &pb.ConfigResponse{
+			Success:        false,
+			Message:        fmt.Sprintf("配置更新失败: %v", err),
+			AppliedVersion: req.ConfigVersion,
+		}
+		if result != nil {
+			resp.Phases = convertApplyPhases(result.Phases)
+			resp.Reverted = result.Reverted
+			resp.PortConflicts = convertPortConflicts(result.Conflicts)
+		}
+		return resp, nil
+	}
+
 	log.Printf("配置更新成功: Version=%s", req.ConfigVersion)
 	return &pb.ConfigResponse{
 		Success:        true,
 		Message:        "配置更新成功",
 		AppliedVersion: req.ConfigVersion,
+		Phases:         convertApplyPhases(result.Phases),
+		PortConflicts:  convertPortConflicts(result.Conflicts),
 	}, nil
 }
 
-// UpdateRules 处理规则更新请求
-func (s *Server) UpdateRules(ctx context.Context, req *pb.RulesRequest) (*pb.RulesResponse, error) {
-	log.Printf("收到规则更新请求: Agent=%s, Operation=%s, Rules=%d", 
-		req.AgentId, req.Operation, len(req.Rules))
-	
+// RollbackConfig 处理配置回滚请求
+func (s *Server) RollbackConfig(ctx context.Context, req *pb.RollbackRequest) (*pb.RollbackResponse, error) {
+	log.Printf("收到配置回滚请求: Agent=%s, Scope=%s, TargetVersion=%s, Reason=%s",
+		req.AgentId, req.Scope, req.TargetVersion, req.Reason)
+
 	if req.AgentId != s.client.GetAgentID() {
-		return &pb.RulesResponse{
+		return &pb.RollbackResponse{
 			Success: false,
 			Message: "Agent ID不匹配",
 		}, nil
 	}
 
-	// 调用客户端的规则更新方法
-	err := s.client.UpdateRules(req.Rules)
+	inst, err := s.client.Instance(req.Instance)
 	if err != nil {
-		log.Printf("规则更新失败: %v", err)
-		return &pb.RulesResponse{
+		return &pb.RollbackResponse{
 			Success: false,
-			Message: fmt.Sprintf("规则更新失败: %v", err),
+			Message: err.Error(),
 		}, nil
 	}
 
-	log.Printf("规则更新成功")
-	return &pb.RulesResponse{
+	switch req.Scope {
+	case "", "filter":
+		if err := inst.RollbackConfig(req.TargetVersion, req.Reason); err != nil {
+			log.Printf("过滤器配置回滚失败: %v", err)
+			return &pb.RollbackResponse{
+				Success:        false,
+				Message:        fmt.Sprintf("过滤器配置回滚失败: %v", err),
+				CurrentVersion: inst.GetFilterVersion(),
+			}, nil
+		}
+		return &pb.RollbackResponse{
+			Success:           true,
+			Message:           "过滤器配置回滚成功",
+			RolledBackVersion: req.TargetVersion,
+			CurrentVersion:    inst.GetFilterVersion(),
+		}, nil
+
+	case "singbox":
+		result, err := inst.RollbackSingboxConfig(req.TargetVersion, req.Reason)
+		resp := &pb.RollbackResponse{
+			Success:           err == nil,
+			Message:           "sing-box配置回滚成功",
+			RolledBackVersion: req.TargetVersion,
+		}
+		if err != nil {
+			log.Printf("sing-box配置回滚失败: %v", err)
+			resp.Message = err.Error()
+		}
+		if result != nil {
+			resp.Phases = convertApplyPhases(result.Phases)
+		}
+		if gens, err := inst.ListConfigGenerations(); err == nil && len(gens) > 0 {
+			resp.CurrentVersion = strconv.FormatInt(gens[len(gens)-1].Version, 10)
+		}
+		return resp, nil
+
+	case "dns":
+		result, err := inst.RollbackDNSConfig(req.TargetVersion, req.Reason)
+		resp := &pb.RollbackResponse{
+			Success:           err == nil,
+			Message:           "DNS配置回滚成功",
+			RolledBackVersion: req.TargetVersion,
+			CurrentVersion:    inst.GetDNSVersion(),
+		}
+		if err != nil {
+			log.Printf("DNS配置回滚失败: %v", err)
+			resp.Message = err.Error()
+		}
+		if result != nil {
+			resp.Phases = convertApplyPhases(result.Phases)
+		}
+		return resp, nil
+
+	default:
+		return &pb.RollbackResponse{
+			Success: false,
+			Message: fmt.Sprintf("不支持的回滚范围: %s", req.Scope),
+		}, nil
+	}
+}
+
+// ListConfigGenerations 处理配置历史列表请求
+func (s *Server) ListConfigGenerations(ctx context.Context, req *pb.ConfigGenerationsRequest) (*pb.ConfigGenerationsResponse, error) {
+	if req.AgentId != s.client.GetAgentID() {
+		return &pb.ConfigGenerationsResponse{
+			Success: false,
+			Message: "Agent ID不匹配",
+		}, nil
+	}
+
+	inst, err := s.client.Instance(req.Instance)
+	if err != nil {
+		return &pb.ConfigGenerationsResponse{
+			Success: false,
+			Message: err.Error(),
+		}, nil
+	}
+
+	gens, err := inst.ListConfigGenerations()
+	if err != nil {
+		return &pb.ConfigGenerationsResponse{
+			Success: false,
+			Message: fmt.Sprintf("读取配置历史失败: %v", err),
+		}, nil
+	}
+
+	resp := &pb.ConfigGenerationsResponse{
+		Success:     true,
+		Message:     fmt.Sprintf("共 %d 代配置", len(gens)),
+		Generations: make([]*pb.ConfigGeneration, 0, len(gens)),
+	}
+	for i, gen := range gens {
+		current := i == len(gens)-1
+		if current {
+			resp.CurrentVersion = gen.Version
+		}
+		resp.Generations = append(resp.Generations, &pb.ConfigGeneration{
+			Version:   gen.Version,
+			AppliedAt: gen.AppliedAt.Format(time.RFC3339),
+			Source:    gen.Source,
+			Sha256:    gen.SHA256,
+			Size:      gen.Size,
+			Current:   current,
+		})
+	}
+
+	return resp, nil
+}
+
+// DiffConfigGenerations 处理配置diff请求
+func (s *Server) DiffConfigGenerations(ctx context.Context, req *pb.ConfigDiffRequest) (*pb.ConfigDiffResponse, error) {
+	if req.AgentId != s.client.GetAgentID() {
+		return &pb.ConfigDiffResponse{
+			Success: false,
+			Message: "Agent ID不匹配",
+		}, nil
+	}
+
+	inst, err := s.client.Instance(req.Instance)
+	if err != nil {
+		return &pb.ConfigDiffResponse{
+			Success: false,
+			Message: err.Error(),
+		}, nil
+	}
+
+	diff, err := inst.DiffConfigGenerations(req.FromVersion, req.ToVersion)
+	if err != nil {
+		return &pb.ConfigDiffResponse{
+			Success: false,
+			Message: fmt.Sprintf("比较配置失败: %v", err),
+		}, nil
+	}
+
+	message := "配置存在差异"
+	if diff == "" {
+		message = "配置内容相同"
+	}
+	return &pb.ConfigDiffResponse{
 		Success: true,
-		Message: "规则更新成功",
+		Message: message,
+		Diff:    diff,
+	}, nil
+}
+
+// StreamSingboxLogs 推送sing-box日志，follow为true时持续推送直到客户端断开
+func (s *Server) StreamSingboxLogs(req *pb.LogStreamRequest, stream pb.AgentService_StreamSingboxLogsServer) error {
+	if req.AgentId != s.client.GetAgentID() {
+		return status.Error(codes.PermissionDenied, "Agent ID不匹配")
+	}
+	inst, err := s.client.Instance(req.Instance)
+	if err != nil {
+		return status.Error(codes.NotFound, err.Error())
+	}
+	if req.Level != "" && !singbox.ValidLogLevel(req.Level) {
+		return status.Errorf(codes.InvalidArgument, "不支持的日志级别: %s", req.Level)
+	}
+
+	filter := singbox.LogFilter{
+		MinLevel: req.Level,
+		Keyword:  req.Keyword,
+	}
+	logs := inst.SingboxLogs()
+
+	if !req.Follow {
+		for _, entry := range logs.Tail(int(req.Tail), filter) {
+			if err := stream.Send(convertLogEntry(entry)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	log.Printf("开始推送sing-box日志: Level=%s, Keyword=%s, Tail=%d", req.Level, req.Keyword, req.Tail)
+	backlog, entries, cancel := logs.Subscribe(int(req.Tail), filter)
+	defer cancel()
+
+	for _, entry := range backlog {
+		if err := stream.Send(convertLogEntry(entry)); err != nil {
+			return err
+		}
+	}
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			log.Printf("sing-box日志推送结束")
+			return nil
+		case entry := <-entries:
+			if err := stream.Send(convertLogEntry(entry)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// UpdateInboundUsers 处理入站用户更新请求
+func (s *Server) UpdateInboundUsers(ctx context.Context, req *pb.InboundUsersRequest) (*pb.InboundUsersResponse, error) {
+	log.Printf("收到入站用户更新请求: Agent=%s, Inbound=%s, Operation=%s, Users=%d",
+		req.AgentId, req.InboundTag, req.Operation, len(req.Users))
+
+	if req.AgentId != s.client.GetAgentID() {
+		return &pb.InboundUsersResponse{
+			Success: false,
+			Message: "Agent ID不匹配",
+		}, nil
+	}
+
+	inst, err := s.client.Instance(req.Instance)
+	if err != nil {
+		return &pb.InboundUsersResponse{
+			Success:    false,
+			Message:    err.Error(),
+			InboundTag: req.InboundTag,
+		}, nil
+	}
+
+	users, err := convertPBInboundUsers(req.Users)
+	if err != nil {
+		return &pb.InboundUsersResponse{
+			Success:    false,
+			Message:    err.Error(),
+			InboundTag: req.InboundTag,
+		}, nil
+	}
+
+	resp := &pb.InboundUsersResponse{
+		Success:    true,
+		Message:    "入站用户更新成功",
+		InboundTag: req.InboundTag,
+	}
+
+	result, err := inst.UpdateInboundUsers(req.InboundTag, req.Operation, users, req.Revision)
+	if result != nil {
+		resp.Phases = convertApplyPhases(result.Phases)
+	}
+	if err != nil {
+		log.Printf("入站用户更新失败: %v", err)
+		resp.Success = false
+		resp.Message = err.Error()
+		resp.RevisionMismatch = errors.Is(err, singbox.ErrUsersRevisionMismatch)
+	}
+
+	// 返回当前生效的用户列表
+	if inboundType, current, err := inst.GetInboundUsers(req.InboundTag); err == nil {
+		resp.InboundType = inboundType
+		resp.Users = convertInboundUsers(current)
+		resp.Revision = singbox.UsersRevision(current)
+	}
+
+	return resp, nil
+}
+
+// GetInboundUsers 处理入站用户查询请求
+func (s *Server) GetInboundUsers(ctx context.Context, req *pb.InboundUsersQuery) (*pb.InboundUsersResponse, error) {
+	if req.AgentId != s.client.GetAgentID() {
+		return &pb.InboundUsersResponse{
+			Success: false,
+			Message: "Agent ID不匹配",
+		}, nil
+	}
+
+	inst, err := s.client.Instance(req.Instance)
+	if err != nil {
+		return &pb.InboundUsersResponse{
+			Success:    false,
+			Message:    err.Error(),
+			InboundTag: req.InboundTag,
+		}, nil
+	}
+
+	inboundType, users, err := inst.GetInboundUsers(req.InboundTag)
+	if err != nil {
+		return &pb.InboundUsersResponse{
+			Success:    false,
+			Message:    fmt.Sprintf("获取入站用户失败: %v", err),
+			InboundTag: req.InboundTag,
+		}, nil
+	}
+
+	return &pb.InboundUsersResponse{
+		Success:     true,
+		Message:     fmt.Sprintf("共 %d 个用户", len(users)),
+		InboundTag:  req.InboundTag,
+		InboundType: inboundType,
+		Users:       convertInboundUsers(users),
+		Revision:    singbox.UsersRevision(users),
 	}, nil
-}
\ No newline at end of file
+}
+
+// GetInbounds 处理入站定义查询请求，Reality私钥不离开Agent
+func (s *Server) GetInbounds(ctx context.Context, req *pb.InboundsQuery) (*pb.InboundsResponse, error) {
+	if req.AgentId != s.client.GetAgentID() {
+		return &pb.InboundsResponse{
+			Success: false,
+			Message: "Agent ID不匹配",
+		}, nil
+	}
+
+	resp := &pb.InboundsResponse{Success: true}
+	for _, inst := range s.client.Instances() {
+		inbounds, err := inst.GetInbounds()
+		if err != nil {
+			log.Printf("获取实例 %s 的入站失败，跳过: %v", inst.Name(), err)
+			continue
+		}
+		for _, inbound := range inbounds {
+			definition := &pb.InboundDefinition{
+				Instance: inst.Name(),
+				Tag:      inbound.Tag,
+				Type:     inbound.Type,
+			}
+			if inbound.TLS != nil && inbound.TLS.Reality != nil {
+				if publicKey, err := singbox.RealityPublicKey(inbound.TLS.Reality.PrivateKey); err == nil {
+					definition.RealityPublicKey = publicKey
+				}
+				inbound.TLS.Reality.PrivateKey = ""
+			}
+			data, err := json.Marshal(&inbound)
+			if err != nil {
+				return &pb.InboundsResponse{
+					Success: false,
+					Message: fmt.Sprintf("序列化入站 %s 失败: %v", inbound.Tag, err),
+				}, nil
+			}
+			definition.Config = string(data)
+			resp.Inbounds = append(resp.Inbounds, definition)
+		}
+	}
+	resp.Message = fmt.Sprintf("共 %d 个入站", len(resp.Inbounds))
+	return resp, nil
+}
+
+// UpdateCertificate 处理入站TLS证书下发请求
+func (s *Server) UpdateCertificate(ctx context.Context, req *pb.CertificateRequest) (*pb.CertificateResponse, error) {
+	log.Printf("收到证书下发请求: Agent=%s, Inbound=%s, Name=%s, Fingerprint=%s",
+		req.AgentId, req.InboundTag, req.Name, req.Fingerprint)
+
+	if req.AgentId != s.client.GetAgentID() {
+		return &pb.CertificateResponse{
+			Success: false,
+			Message: "Agent ID不匹配",
+		}, nil
+	}
+
+	inst, err := s.client.Instance(req.Instance)
+	if err != nil {
+		return &pb.CertificateResponse{
+			Success: false,
+			Message: err.Error(),
+		}, nil
+	}
+
+	certPath, keyPath, result, err := inst.UpdateCertificate(req.InboundTag, req.Name, req.Fingerprint,
+		[]byte(req.CertificatePem), []byte(req.KeyPem))
+	resp := &pb.CertificateResponse{
+		Success:         true,
+		Message:         "证书更新成功",
+		CertificatePath: certPath,
+		KeyPath:         keyPath,
+	}
+	if result != nil {
+		resp.Phases = convertApplyPhases(result.Phases)
+	}
+	if err != nil {
+		log.Printf("证书更新失败: %v", err)
+		resp.Success = false
+		resp.Message = err.Error()
+	}
+	return resp, nil
+}
+
+// UpdateReality 处理入站Reality密钥下发请求
+func (s *Server) UpdateReality(ctx context.Context, req *pb.RealityRequest) (*pb.RealityResponse, error) {
+	log.Printf("收到Reality密钥下发请求: Agent=%s, Inbound=%s, ShortIDs=%d",
+		req.AgentId, req.InboundTag, len(req.ShortIds))
+
+	if req.AgentId != s.client.GetAgentID() {
+		return &pb.RealityResponse{
+			Success: false,
+			Message: "Agent ID不匹配",
+		}, nil
+	}
+
+	inst, err := s.client.Instance(req.Instance)
+	if err != nil {
+		return &pb.RealityResponse{
+			Success: false,
+			Message: err.Error(),
+		}, nil
+	}
+
+	var handshake *singbox.RealityHandshake
+	if req.HandshakeServer != "" {
+		handshake = &singbox.RealityHandshake{Server: req.HandshakeServer, ServerPort: uint16(req.HandshakePort)}
+		if handshake.ServerPort == 0 {
+			handshake.ServerPort = 443
+		}
+	}
+
+	tls, result, err := inst.UpdateReality(req.InboundTag, req.PrivateKey, req.ShortIds, handshake)
+	resp := realityResponse(tls)
+	resp.Message = "Reality密钥更新成功"
+	if result != nil {
+		resp.Phases = convertApplyPhases(result.Phases)
+	}
+	if err != nil {
+		log.Printf("Reality密钥更新失败: %v", err)
+		resp.Success = false
+		resp.Message = err.Error()
+	}
+	return resp, nil
+}
+
+// GetReality 处理入站Reality查询请求
+func (s *Server) GetReality(ctx context.Context, req *pb.RealityQuery) (*pb.RealityResponse, error) {
+	if req.AgentId != s.client.GetAgentID() {
+		return &pb.RealityResponse{
+			Success: false,
+			Message: "Agent ID不匹配",
+		}, nil
+	}
+
+	inst, err := s.client.Instance(req.Instance)
+	if err != nil {
+		return &pb.RealityResponse{
+			Success: false,
+			Message: err.Error(),
+		}, nil
+	}
+
+	tls, err := inst.GetInboundTLS(req.InboundTag)
+	if err != nil {
+		return &pb.RealityResponse{
+			Success: false,
+			Message: fmt.Sprintf("获取入站TLS配置失败: %v", err),
+		}, nil
+	}
+	resp := realityResponse(tls)
+	if !resp.Enabled {
+		resp.Message = fmt.Sprintf("入站 %s 未启用Reality", req.InboundTag)
+	}
+	return resp, nil
+}
+
+// GetDNSConfig 处理DNS配置查询请求
+func (s *Server) GetDNSConfig(ctx context.Context, req *pb.DNSConfigQuery) (*pb.DNSConfigResponse, error) {
+	if req.AgentId != s.client.GetAgentID() {
+		return &pb.DNSConfigResponse{
+			Success: false,
+			Message: "Agent ID不匹配",
+		}, nil
+	}
+
+	inst, err := s.client.Instance(req.Instance)
+	if err != nil {
+		return &pb.DNSConfigResponse{
+			Success: false,
+			Message: err.Error(),
+		}, nil
+	}
+
+	resp := &pb.DNSConfigResponse{
+		Success: true,
+		Message: "获取DNS配置成功",
+	}
+	fillDNSConfigResponse(inst, resp)
+	return resp, nil
+}
+
+// UpdateDNSServers 处理DNS服务器更新请求
+func (s *Server) UpdateDNSServers(ctx context.Context, req *pb.DNSServersRequest) (*pb.DNSConfigResponse, error) {
+	log.Printf("收到DNS服务器更新请求: Agent=%s, Operation=%s, Tags=%v, Final=%s, Strategy=%s",
+		req.AgentId, req.Operation, req.Tags, req.Final, req.Strategy)
+
+	if req.AgentId != s.client.GetAgentID() {
+		return &pb.DNSConfigResponse{
+			Success: false,
+			Message: "Agent ID不匹配",
+		}, nil
+	}
+
+	inst, err := s.client.Instance(req.Instance)
+	if err != nil {
+		return &pb.DNSConfigResponse{
+			Success: false,
+			Message: err.Error(),
+		}, nil
+	}
+
+	var servers []singbox.DNSServer
+	if req.Servers != "" {
+		if err := json.Unmarshal([]byte(req.Servers), &servers); err != nil {
+			return &pb.DNSConfigResponse{
+				Success: false,
+				Message: fmt.Sprintf("解析DNS服务器失败: %v", err),
+			}, nil
+		}
+	}
+
+	result, err := inst.UpdateDNSServers(req.Operation, servers, req.Tags, int(req.Position), req.Final, req.Strategy)
+	return dnsUpdateResponse(inst, "DNS服务器更新成功", result, err), nil
+}
+
+// UpdateDNSRules 处理DNS规则更新请求
+func (s *Server) UpdateDNSRules(ctx context.Context, req *pb.DNSRulesRequest) (*pb.DNSConfigResponse, error) {
+	log.Printf("收到DNS规则更新请求: Agent=%s, Operation=%s, Positions=%v, Position=%d",
+		req.AgentId, req.Operation, req.Positions, req.Position)
+
+	if req.AgentId != s.client.GetAgentID() {
+		return &pb.DNSConfigResponse{
+			Success: false,
+			Message: "Agent ID不匹配",
+		}, nil
+	}
+
+	inst, err := s.client.Instance(req.Instance)
+	if err != nil {
+		return &pb.DNSConfigResponse{
+			Success: false,
+			Message: err.Error(),
+		}, nil
+	}
+
+	var rules []singbox.DNSRule
+	if req.Rules != "" {
+		if err := json.Unmarshal([]byte(req.Rules), &rules); err != nil {
+			return &pb.DNSConfigResponse{
+				Success: false,
+				Message: fmt.Sprintf("解析DNS规则失败: %v", err),
+			}, nil
+		}
+	}
+	positions := make([]int, 0, len(req.Positions))
+	for _, p := range req.Positions {
+		positions = append(positions, int(p))
+	}
+
+	result, err := inst.UpdateDNSRules(req.Operation, rules, positions, int(req.Position))
+	return dnsUpdateResponse(inst, "DNS规则更新成功", result, err), nil
+}
+
+// UpdateFakeIP 处理FakeIP更新请求
+func (s *Server) UpdateFakeIP(ctx context.Context, req *pb.FakeIPRequest) (*pb.DNSConfigResponse, error) {
+	log.Printf("收到FakeIP更新请求: Agent=%s, Enabled=%t, Inet4Range=%s, Inet6Range=%s",
+		req.AgentId, req.Enabled, req.Inet4Range, req.Inet6Range)
+
+	if req.AgentId != s.client.GetAgentID() {
+		return &pb.DNSConfigResponse{
+			Success: false,
+			Message: "Agent ID不匹配",
+		}, nil
+	}
+
+	inst, err := s.client.Instance(req.Instance)
+	if err != nil {
+		return &pb.DNSConfigResponse{
+			Success: false,
+			Message: err.Error(),
+		}, nil
+	}
+
+	result, err := inst.UpdateFakeIP(req.Enabled, req.Inet4Range, req.Inet6Range)
+	return dnsUpdateResponse(inst, "FakeIP更新成功", result, err), nil
+}
+
+// dnsUpdateResponse 构造DNS更新响应，附带应用阶段结果和更新后生效的DNS配置
+func dnsUpdateResponse(inst *Instance, message string, result *singbox.ApplyResult, err error) *pb.DNSConfigResponse {
+	resp := &pb.DNSConfigResponse{
+		Success: true,
+		Message: message,
+	}
+	if result != nil {
+		resp.Phases = convertApplyPhases(result.Phases)
+	}
+	if err != nil {
+		log.Printf("%v", err)
+		resp.Success = false
+		resp.Message = err.Error()
+	}
+	fillDNSConfigResponse(inst, resp)
+	return resp
+}
+
+// fillDNSConfigResponse 填充当前生效的DNS配置和版本信息
+func fillDNSConfigResponse(inst *Instance, resp *pb.DNSConfigResponse) {
+	resp.Version = inst.GetDNSVersion()
+	resp.Versions = inst.GetDNSVersions()
+
+	config, err := inst.GetDNSConfig()
+	if err != nil {
+		if resp.Success {
+			resp.Success = false
+			resp.Message = err.Error()
+		}
+		return
+	}
+	data, err := json.Marshal(config)
+	if err != nil {
+		log.Printf("序列化DNS配置失败: %v", err)
+		return
+	}
+	resp.DnsConfig = string(data)
+}
+
+// CloseConnections 按条件关闭sing-box连接
+func (s *Server) CloseConnections(ctx context.Context, req *pb.CloseConnectionsRequest) (*pb.CloseConnectionsResponse, error) {
+	if req.AgentId != s.client.GetAgentID() {
+		return &pb.CloseConnectionsResponse{
+			Success: false,
+			Message: "Agent ID不匹配",
+		}, nil
+	}
+
+	inst, err := s.client.Instance(req.Instance)
+	if err != nil {
+		return &pb.CloseConnectionsResponse{
+			Success: false,
+			Message: err.Error(),
+		}, nil
+	}
+
+	filter := clashapi.CloseFilter{
+		Host:     req.Host,
+		SourceIP: req.SourceIp,
+		Rule:     req.Rule,
+		Inbound:  req.Inbound,
+		Outbound: req.Outbound,
+		All:      req.All,
+	}
+	closed, err := inst.CloseConnections(filter)
+	if err != nil {
+		log.Printf("关闭连接失败: %v", err)
+		return &pb.CloseConnectionsResponse{
+			Success:       false,
+			Message:       fmt.Sprintf("关闭连接失败: %v", err),
+			Closed:        int32(len(closed)),
+			ConnectionIds: closed,
+		}, nil
+	}
+
+	log.Printf("已关闭 %d 个连接", len(closed))
+	return &pb.CloseConnectionsResponse{
+		Success:       true,
+		Message:       fmt.Sprintf("已关闭 %d 个连接", len(closed)),
+		Closed:        int32(len(closed)),
+		ConnectionIds: closed,
+	}, nil
+}
+
+// GetOutboundGroups 处理出站组查询请求，Clash API可用时附带当前选择与成员延迟
+func (s *Server) GetOutboundGroups(ctx context.Context, req *pb.OutboundGroupsQuery) (*pb.OutboundGroupsResponse, error) {
+	if req.AgentId != s.client.GetAgentID() {
+		return &pb.OutboundGroupsResponse{
+			Success: false,
+			Message: "Agent ID不匹配",
+		}, nil
+	}
+
+	inst, err := s.client.Instance(req.Instance)
+	if err != nil {
+		return &pb.OutboundGroupsResponse{
+			Success: false,
+			Message: err.Error(),
+		}, nil
+	}
+
+	groups, err := inst.GetOutboundGroups()
+	if err != nil {
+		return &pb.OutboundGroupsResponse{
+			Success: false,
+			Message: fmt.Sprintf("获取出站组失败: %v", err),
+		}, nil
+	}
+	if req.Tag != "" {
+		var matched []singbox.Outbound
+		for _, group := range groups {
+			if group.Tag == req.Tag {
+				matched = append(matched, group)
+			}
+		}
+		if len(matched) == 0 {
+			return &pb.OutboundGroupsResponse{
+				Success: false,
+				Message: fmt.Sprintf("出站组 %s 不存在", req.Tag),
+			}, nil
+		}
+		groups = matched
+	}
+
+	resp := &pb.OutboundGroupsResponse{
+		Success: true,
+		Message: fmt.Sprintf("共 %d 个出站组", len(groups)),
+	}
+
+	var failed map[string]error
+	if req.TestDelay {
+		timeout := 5 * time.Second
+		if req.TimeoutMs > 0 {
+			timeout = time.Duration(req.TimeoutMs) * time.Millisecond
+		}
+		failed, err = inst.TestGroupDelays(groups, req.TestUrl, timeout)
+		if err != nil {
+			resp.RuntimeError = fmt.Sprintf("延迟测试失败: %v", err)
+		}
+	}
+
+	proxies, err := inst.OutboundProxies()
+	if err != nil {
+		if resp.RuntimeError == "" {
+			resp.RuntimeError = err.Error()
+		}
+	} else {
+		resp.RuntimeAvailable = true
+	}
+
+	for _, group := range groups {
+		status := &pb.OutboundGroupStatus{Group: convertOutboundGroupToPB(group)}
+		if proxy, ok := proxies[group.Tag]; ok {
+			status.Now = proxy.Now
+		}
+		for _, member := range group.Outbounds {
+			delay := &pb.MemberDelay{Tag: member}
+			if last, ok := proxies[member].LastDelay(); ok {
+				delay.Delay = int32(last.Delay)
+				delay.TestedAt = last.Time.Unix()
+			}
+			if err := failed[member]; err != nil {
+				delay.Error = err.Error()
+			}
+			status.Members = append(status.Members, delay)
+		}
+		resp.Groups = append(resp.Groups, status)
+	}
+
+	return resp, nil
+}
+
+// UpdateOutboundGroup 处理出站组创建、修改与删除请求
+func (s *Server) UpdateOutboundGroup(ctx context.Context, req *pb.OutboundGroupRequest) (*pb.OutboundGroupResponse, error) {
+	group := convertOutboundGroup(req.Group)
+	log.Printf("收到出站组更新请求: Agent=%s, Operation=%s, Tag=%s, Type=%s",
+		req.AgentId, req.Operation, group.Tag, group.Type)
+
+	if req.AgentId != s.client.GetAgentID() {
+		return &pb.OutboundGroupResponse{
+			Success: false,
+			Message: "Agent ID不匹配",
+		}, nil
+	}
+
+	inst, err := s.client.Instance(req.Instance)
+	if err != nil {
+		return &pb.OutboundGroupResponse{
+			Success: false,
+			Message: err.Error(),
+		}, nil
+	}
+
+	result, err := inst.UpdateOutboundGroup(req.Operation, group)
+	return outboundGroupResponse(inst, group.Tag, "出站组更新成功", result, err), nil
+}
+
+// UpdateGroupMembers 处理出站组成员更新请求
+func (s *Server) UpdateGroupMembers(ctx context.Context, req *pb.GroupMembersRequest) (*pb.OutboundGroupResponse, error) {
+	log.Printf("收到出站组成员更新请求: Agent=%s, Tag=%s, Operation=%s, Members=%v",
+		req.AgentId, req.Tag, req.Operation, req.Members)
+
+	if req.AgentId != s.client.GetAgentID() {
+		return &pb.OutboundGroupResponse{
+			Success: false,
+			Message: "Agent ID不匹配",
+		}, nil
+	}
+
+	inst, err := s.client.Instance(req.Instance)
+	if err != nil {
+		return &pb.OutboundGroupResponse{
+			Success: false,
+			Message: err.Error(),
+		}, nil
+	}
+
+	result, err := inst.UpdateGroupMembers(req.Tag, req.Operation, req.Members)
+	return outboundGroupResponse(inst, req.Tag, "出站组成员更新成功", result, err), nil
+}
+
+// outboundGroupResponse 构造出站组更新响应，附带应用阶段结果和当前生效的出站组
+func outboundGroupResponse(inst *Instance, tag, message string, result *singbox.ApplyResult, err error) *pb.OutboundGroupResponse {
+	resp := &pb.OutboundGroupResponse{
+		Success: true,
+		Message: message,
+	}
+	if result != nil {
+		resp.Phases = convertApplyPhases(result.Phases)
+	}
+	if err != nil {
+		log.Printf("%v", err)
+		resp.Success = false
+		resp.Message = err.Error()
+	}
+
+	if groups, err := inst.GetOutboundGroups(); err == nil {
+		for _, group := range groups {
+			if group.Tag == tag {
+				resp.Group = convertOutboundGroupToPB(group)
+				break
+			}
+		}
+	}
+	return resp
+}
+
+// convertOutboundGroup 将protobuf出站组转换为sing-box出站
+func convertOutboundGroup(group *pb.OutboundGroup) singbox.Outbound {
+	if group == nil {
+		return singbox.Outbound{}
+	}
+	return singbox.Outbound{
+		Type:                      group.Type,
+		Tag:                       group.Tag,
+		Outbounds:                 group.Outbounds,
+		Default:                   group.Default,
+		URL:                       group.Url,
+		Interval:                  group.Interval,
+		Tolerance:                 uint16(group.Tolerance),
+		IdleTimeout:               group.IdleTimeout,
+		InterruptExistConnections: group.InterruptExistConnections,
+	}
+}
+
+// convertOutboundGroupToPB 将sing-box出站组转换为protobuf格式
+func convertOutboundGroupToPB(group singbox.Outbound) *pb.OutboundGroup {
+	return &pb.OutboundGroup{
+		Tag:                       group.Tag,
+		Type:                      group.Type,
+		Outbounds:                 group.Outbounds,
+		Default:                   group.Default,
+		Url:                       group.URL,
+		Interval:                  group.Interval,
+		Tolerance:                 uint32(group.Tolerance),
+		IdleTimeout:               group.IdleTimeout,
+		InterruptExistConnections: group.InterruptExistConnections,
+	}
+}
+
+// UpgradeSingbox 处理sing-box版本切换请求
+func (s *Server) UpgradeSingbox(ctx context.Context, req *pb.UpgradeRequest) (*pb.UpgradeResponse, error) {
+	log.Printf("收到sing-box版本切换请求: Agent=%s, Instance=%s, Version=%s",
+		req.AgentId, req.Instance, req.Version)
+
+	if req.AgentId != s.client.GetAgentID() {
+		return &pb.UpgradeResponse{
+			Success: false,
+			Message: "Agent ID不匹配",
+		}, nil
+	}
+
+	inst, err := s.client.Instance(req.Instance)
+	if err != nil {
+		return &pb.UpgradeResponse{
+			Success: false,
+			Message: err.Error(),
+		}, nil
+	}
+
+	result, err := inst.UpgradeSingbox(req.Version, req.Sha256)
+	resp := &pb.UpgradeResponse{
+		Success:        err == nil,
+		Message:        fmt.Sprintf("sing-box已切换到 %s", result.CurrentVersion),
+		FromVersion:    result.FromVersion,
+		CurrentVersion: result.CurrentVersion,
+		RolledBack:     result.RolledBack,
+		Phases:         convertApplyPhases(result.Phases),
+	}
+	if err != nil {
+		log.Printf("sing-box版本切换失败: %v", err)
+		resp.Message = err.Error()
+	}
+	return resp, nil
+}
+
+// convertInboundUsers 将入站用户转换为protobuf格式
+func convertInboundUsers(users []singbox.InboundUser) []*pb.InboundUser {
+	result := make([]*pb.InboundUser, 0, len(users))
+	for _, user := range users {
+		result = append(result, &pb.InboundUser{
+			Name:     user.Name,
+			Username: user.Username,
+			Password: user.Password,
+			Uuid:     user.UUID,
+			Flow:     user.Flow,
+			AlterId:  int32(user.AlterID),
+			Extra:    inboundUserExtra(user),
+		})
+	}
+	return result
+}
+
+// inboundUserExtra 将用户未建模的字段编码为JSON对象，没有时返回空串
+func inboundUserExtra(user singbox.InboundUser) string {
+	if len(user.Extra.Fields) == 0 {
+		return ""
+	}
+	data, err := user.Extra.MarshalObject()
+	if err != nil {
+		log.Printf("编码用户未建模字段失败: %v", err)
+		return ""
+	}
+	return string(data)
+}
+
+// convertPBInboundUsers 将protobuf格式的入站用户转换为sing-box用户
+func convertPBInboundUsers(users []*pb.InboundUser) ([]singbox.InboundUser, error) {
+	result := make([]singbox.InboundUser, 0, len(users))
+	for i, user := range users {
+		converted := singbox.InboundUser{
+			Name:     user.Name,
+			Username: user.Username,
+			Password: user.Password,
+			UUID:     user.Uuid,
+			Flow:     user.Flow,
+			AlterID:  int(user.AlterId),
+		}
+		if user.Extra != "" {
+			extra, err := singbox.ParseRawFields([]byte(user.Extra))
+			if err != nil {
+				return nil, fmt.Errorf("第 %d 个用户的extra不是合法的JSON对象: %v", i+1, err)
+			}
+			converted.Extra = extra
+		}
+		result = append(result, converted)
+	}
+	return result, nil
+}
+
+// convertLogEntry 将日志条目转换为protobuf格式
+func convertLogEntry(entry singbox.LogEntry) *pb.SingboxLogEntry {
+	return &pb.SingboxLogEntry{
+		Seq:       entry.Seq,
+		Timestamp: entry.Time.Format(time.RFC3339Nano),
+		Level:     entry.Level,
+		Message:   entry.Message,
+		Stream:    entry.Stream,
+	}
+}
+
+// realityResponse 按入站TLS配置生成Reality响应，私钥只用于推导公钥，不返回
+func realityResponse(tls *singbox.InboundTLS) *pb.RealityResponse {
+	resp := &pb.RealityResponse{Success: true}
+	if tls == nil || !tls.Enabled || tls.Reality == nil || !tls.Reality.Enabled {
+		return resp
+	}
+	resp.Enabled = true
+	resp.ShortIds = tls.Reality.ShortID
+	resp.ServerName = singbox.RealityServerName(tls)
+	if publicKey, err := singbox.RealityPublicKey(tls.Reality.PrivateKey); err == nil {
+		resp.PublicKey = publicKey
+	}
+	return resp
+}
+
+// convertApplyPhases 将配置应用阶段结果转换为protobuf格式
+func convertApplyPhases(phases []singbox.PhaseResult) []*pb.ApplyPhase {
+	result := make([]*pb.ApplyPhase, 0, len(phases))
+	for _, phase := range phases {
+		result = append(result, &pb.ApplyPhase{
+			Phase:      phase.Phase,
+			Success:    phase.Success,
+			Skipped:    phase.Skipped,
+			Message:    phase.Message,
+			DurationMs: phase.Duration.Milliseconds(),
+		})
+	}
+	return result
+}
+
+// convertPortConflicts 将端口冲突转换为protobuf格式
+func convertPortConflicts(conflicts []singbox.PortConflict) []*pb.PortConflict {
+	result := make([]*pb.PortConflict, 0, len(conflicts))
+	for _, conflict := range conflicts {
+		result = append(result, &pb.PortConflict{
+			Inbound:  conflict.Inbound,
+			Protocol: conflict.Protocol,
+			Listen:   conflict.Listen,
+			Port:     int32(conflict.Port),
+			Address:  conflict.Address,
+			Pid:      int32(conflict.PID),
+			Process:  conflict.Process,
+		})
+	}
+	return result
+}
+
+// UpdateRules 处理规则更新请求
+func (s *Server) UpdateRules(ctx context.Context, req *pb.RulesRequest) (*pb.RulesResponse, error) {
+	log.Printf("收到规则更新请求: Agent=%s, Operation=%s, Rules=%d", 
+		req.AgentId, req.Operation, len(req.Rules))
+	
+	if req.AgentId != s.client.GetAgentID() {
+		return &pb.RulesResponse{
+			Success: false,
+			Message: "Agent ID不匹配",
+		}, nil
+	}
+
+	inst, err := s.client.Instance(req.Instance)
+	if err != nil {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+
+	// 调用实例的规则更新方法
+	err = inst.UpdateRules(req.Rules)
+	if err != nil {
+		log.Printf("规则更新失败: %v", err)
+		return &pb.RulesResponse{
+			Success: false,
+			Message: fmt.Sprintf("规则更新失败: %v", err),
+		}, nil
+	}
+
+	log.Printf("规则更新成功")
+	return &pb.RulesResponse{
+		Success: true,
+		Message: "规则更新成功",
+	}, nil
+}
+
+// GetStatus 处理状态查询请求
+func (s *Server) GetStatus(ctx context.Context, req *pb.StatusRequest) (*pb.StatusResponse, error) {
+	if req.AgentId != s.client.GetAgentID() {
+		return &pb.StatusResponse{
+			Success: false,
+			AgentId: s.client.GetAgentID(),
+		}, nil
+	}
+
+	inst, err := s.client.Instance(req.Instance)
+	if err != nil {
+		return &pb.StatusResponse{
+			Success: false,
+			AgentId: s.client.GetAgentID(),
+		}, nil
+	}
+
+	status := s.client.GetStatus()
+	instStatus := inst.Status()
+
+	resp := &pb.StatusResponse{
+		Success:       true,
+		AgentId:       s.client.GetAgentID(),
+		Status:        instStatus.State,
+		ConfigVersion: inst.GetFilterVersion(),
+		SystemInfo:    status,
+		ActiveConnections: inst.ActiveConnections(),
+	}
+	for _, other := range s.client.Instances() {
+		if other == inst {
+			resp.Instances = append(resp.Instances, instStatus)
+			continue
+		}
+		resp.Instances = append(resp.Instances, other.Status())
+	}
+	return resp, nil
+}
+
+// UpdateBlacklist 处理黑名单更新请求
+func (s *Server) UpdateBlacklist(ctx context.Context, req *pb.BlacklistRequest) (*pb.BlacklistResponse, error) {
+	log.Printf("收到黑名单更新请求: Agent=%s, Instance=%s, Protocol=%s, Operation=%s",
+		req.AgentId, req.Instance, req.Protocol, req.Operation)
+
+	if req.AgentId != s.client.GetAgentID() {
+		return &pb.BlacklistResponse{
+			Success: false,
+			Message: "Agent ID不匹配",
+		}, nil
+	}
+
+	inst, err := s.client.Instance(req.Instance)
+	if err != nil {
+		return &pb.BlacklistResponse{
+			Success: false,
+			Message: err.Error(),
+		}, nil
+	}
+
+	if err := inst.UpdateBlacklist(req.Protocol, req.Domains, req.Ips, req.Ports, req.Operation); err != nil {
+		log.Printf("黑名单更新失败: %v", err)
+		return &pb.BlacklistResponse{
+			Success:       false,
+			Message:       err.Error(),
+			ConfigVersion: inst.GetFilterVersion(),
+		}, nil
+	}
+
+	return &pb.BlacklistResponse{
+		Success:       true,
+		Message:       "黑名单更新成功",
+		ConfigVersion: inst.GetFilterVersion(),
+	}, nil
+}
+
+// UpdateWhitelist 处理白名单更新请求
+func (s *Server) UpdateWhitelist(ctx context.Context, req *pb.WhitelistRequest) (*pb.WhitelistResponse, error) {
+	log.Printf("收到白名单更新请求: Agent=%s, Instance=%s, Protocol=%s, Operation=%s",
+		req.AgentId, req.Instance, req.Protocol, req.Operation)
+
+	if req.AgentId != s.client.GetAgentID() {
+		return &pb.WhitelistResponse{
+			Success: false,
+			Message: "Agent ID不匹配",
+		}, nil
+	}
+
+	inst, err := s.client.Instance(req.Instance)
+	if err != nil {
+		return &pb.WhitelistResponse{
+			Success: false,
+			Message: err.Error(),
+		}, nil
+	}
+
+	if err := inst.UpdateWhitelist(req.Protocol, req.Domains, req.Ips, req.Ports, req.Operation); err != nil {
+		log.Printf("白名单更新失败: %v", err)
+		return &pb.WhitelistResponse{
+			Success:       false,
+			Message:       err.Error(),
+			ConfigVersion: inst.GetFilterVersion(),
+		}, nil
+	}
+
+	return &pb.WhitelistResponse{
+		Success:       true,
+		Message:       "白名单更新成功",
+		ConfigVersion: inst.GetFilterVersion(),
+	}, nil
+}
+
+// GetFilterConfig 处理过滤器配置查询请求，协议为空时返回全部协议
+func (s *Server) GetFilterConfig(ctx context.Context, req *pb.FilterConfigRequest) (*pb.FilterConfigResponse, error) {
+	if req.AgentId != s.client.GetAgentID() {
+		return &pb.FilterConfigResponse{
+			Success: false,
+			Message: "Agent ID不匹配",
+		}, nil
+	}
+
+	inst, err := s.client.Instance(req.Instance)
+	if err != nil {
+		return &pb.FilterConfigResponse{
+			Success: false,
+			Message: err.Error(),
+		}, nil
+	}
+
+	resp := &pb.FilterConfigResponse{
+		Success: true,
+		Message: "获取过滤器配置成功",
+	}
+	for protocol, f := range inst.GetFilterConfig(req.Protocol) {
+		resp.Filters = append(resp.Filters, &pb.ProtocolFilter{
+			Protocol:         protocol,
+			BlacklistDomains: f.BlacklistDomains,
+			BlacklistIps:     f.BlacklistIPs,
+			BlacklistPorts:   f.BlacklistPorts,
+			WhitelistDomains: f.WhitelistDomains,
+			WhitelistIps:     f.WhitelistIPs,
+			WhitelistPorts:   f.WhitelistPorts,
+			Enabled:          f.Enabled,
+			LastUpdated:      f.LastUpdated.Format(time.RFC3339),
+		})
+	}
+	return resp, nil
+}
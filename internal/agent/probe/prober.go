@@ -0,0 +1,423 @@
+package probe
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/proxy"
+)
+
+// 探测类型
+const (
+	TypeHTTP = "http" // 请求URL，状态码小于400即成功
+	TypeTCP  = "tcp"  // 建立TCP连接
+	TypeDNS  = "dns"  // 经出站以TCP向DNS服务器查询域名
+)
+
+const (
+	defaultInterval    = time.Minute
+	defaultTimeout     = 5 * time.Second
+	defaultDNSDomain   = "www.google.com"
+	maxConcurrentProbe = 8
+)
+
+// DefaultTarget 未配置探测目标时使用的目标
+var DefaultTarget = Target{Type: TypeHTTP, Address: "https://www.gstatic.com/generate_204"}
+
+// Target 探测目标
+type Target struct {
+	Type    string `json:"type"`
+	Address string `json:"address"`          // http为URL，tcp为host:port，dns为DNS服务器host:port
+	Domain  string `json:"domain,omitempty"` // dns探测查询的域名
+}
+
+// Validate 校验探测目标
+func (t Target) Validate() error {
+	switch t.Type {
+	case TypeHTTP:
+		u, err := url.Parse(t.Address)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("HTTP探测地址无效: %s", t.Address)
+		}
+	case TypeTCP, TypeDNS:
+		if _, port, err := net.SplitHostPort(t.Address); err != nil || port == "" {
+			return fmt.Errorf("%s探测地址需为host:port: %s", t.Type, t.Address)
+		}
+	default:
+		return fmt.Errorf("不支持的探测类型: %s", t.Type)
+	}
+	return nil
+}
+
+// Key 目标标识，dns探测包含查询的域名
+func (t Target) Key() string {
+	if t.Type == TypeDNS {
+		return t.Address + "/" + t.domain()
+	}
+	return t.Address
+}
+
+// domain 返回dns探测查询的域名
+func (t Target) domain() string {
+	if t.Domain == "" {
+		return defaultDNSDomain
+	}
+	return t.Domain
+}
+
+// Endpoint 探测入站的访问信息，以出站tag作为SOCKS用户名
+type Endpoint struct {
+	Addr      string
+	Password  string
+	Outbounds []string
+}
+
+// Options 探测参数
+type Options struct {
+	Interval  time.Duration // 探测间隔
+	Timeout   time.Duration // 单次探测超时
+	Targets   []Target      // 探测目标，为空时使用DefaultTarget
+	Outbounds []string      // 仅探测这些出站，为空时探测全部
+}
+
+// Result 一个出站对一个目标在统计周期内的探测结果
+type Result struct {
+	Outbound      string    `json:"outbound"`
+	TargetType    string    `json:"target_type"`
+	Target        string    `json:"target"`
+	Probes        int       `json:"probes"`
+	Successes     int       `json:"successes"`
+	LatencySumMs  int64     `json:"latency_sum_ms"` // 成功探测的延迟合计
+	MaxLatencyMs  int64     `json:"max_latency_ms"`
+	LastLatencyMs int64     `json:"last_latency_ms"`      // 最近一次成功探测的延迟
+	LastError     string    `json:"last_error,omitempty"` // 最近一次失败的原因
+	LastProbe     time.Time `json:"last_probe"`
+}
+
+// AvgLatencyMs 成功探测的平均延迟
+func (r Result) AvgLatencyMs() float64 {
+	if r.Successes == 0 {
+		return 0
+	}
+	return float64(r.LatencySumMs) / float64(r.Successes)
+}
+
+// Batch 待上报的探测结果
+type Batch struct {
+	Start   time.Time
+	End     time.Time
+	Results []Result
+}
+
+// resultKey 探测结果标识
+type resultKey struct {
+	outbound   string
+	targetType string
+	target     string
+}
+
+// Prober 经由探测入站定期探测各出站，累积尚未成功上报的结果
+type Prober struct {
+	endpoint  func() (Endpoint, bool)
+	interval  time.Duration
+	timeout   time.Duration
+	targets   []Target
+	outbounds map[string]bool
+
+	mu           sync.Mutex
+	pending      map[resultKey]*Result // 尚未取出上报的结果
+	pendingStart time.Time
+	sending      map[resultKey]*Result // 已取出但尚未确认上报成功的结果
+	sendingStart time.Time
+
+	stopOnce sync.Once
+	stop     chan struct{}
+}
+
+// NewProber 创建出站探测器，endpoint返回当前探测入站的访问信息，无效的目标会被忽略
+func NewProber(endpoint func() (Endpoint, bool), opts Options) *Prober {
+	p := &Prober{
+		endpoint: endpoint,
+		interval: opts.Interval,
+		timeout:  opts.Timeout,
+		pending:  make(map[resultKey]*Result),
+		stop:     make(chan struct{}),
+	}
+	if p.interval <= 0 {
+		p.interval = defaultInterval
+	}
+	if p.timeout <= 0 {
+		p.timeout = defaultTimeout
+	}
+	for _, target := range opts.Targets {
+		if err := target.Validate(); err != nil {
+			log.Printf("忽略出站探测目标: %v", err)
+			continue
+		}
+		p.targets = append(p.targets, target)
+	}
+	if len(p.targets) == 0 {
+		p.targets = []Target{DefaultTarget}
+	}
+	if len(opts.Outbounds) > 0 {
+		p.outbounds = make(map[string]bool, len(opts.Outbounds))
+		for _, tag := range opts.Outbounds {
+			p.outbounds[tag] = true
+		}
+	}
+	return p
+}
+
+// Start 启动后台探测循环
+func (p *Prober) Start() {
+	go func() {
+		ticker := time.NewTicker(p.interval)
+		defer ticker.Stop()
+
+		for {
+			if err := p.RunOnce(context.Background()); err != nil {
+				log.Printf("出站探测未执行: %v", err)
+			}
+			select {
+			case <-ticker.C:
+			case <-p.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop 停止探测
+func (p *Prober) Stop() {
+	p.stopOnce.Do(func() {
+		close(p.stop)
+	})
+}
+
+// RunOnce 对每个出站探测一遍全部目标，结果累加到待上报数据中
+func (p *Prober) RunOnce(ctx context.Context) error {
+	ep, ok := p.endpoint()
+	if !ok {
+		return fmt.Errorf("出站探测入站未启用")
+	}
+
+	type job struct {
+		outbound string
+		target   Target
+	}
+	var jobs []job
+	for _, outbound := range ep.Outbounds {
+		if p.outbounds != nil && !p.outbounds[outbound] {
+			continue
+		}
+		for _, target := range p.targets {
+			jobs = append(jobs, job{outbound: outbound, target: target})
+		}
+	}
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, maxConcurrentProbe)
+	for _, j := range jobs {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(j job) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			probeCtx, cancel := context.WithTimeout(ctx, p.timeout)
+			defer cancel()
+			latency, err := Probe(probeCtx, ep, j.outbound, j.target)
+			p.record(j.outbound, j.target, latency, err)
+		}(j)
+	}
+	wg.Wait()
+	return nil
+}
+
+// record 累加一次探测结果
+func (p *Prober) record(outbound string, target Target, latency time.Duration, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+	if p.pendingStart.IsZero() {
+		p.pendingStart = now
+	}
+	result := &Result{
+		Outbound:   outbound,
+		TargetType: target.Type,
+		Target:     target.Key(),
+		Probes:     1,
+		LastProbe:  now,
+	}
+	if err != nil {
+		result.LastError = err.Error()
+	} else {
+		ms := latency.Milliseconds()
+		result.Successes = 1
+		result.LatencySumMs = ms
+		result.MaxLatencyMs = ms
+		result.LastLatencyMs = ms
+	}
+	mergeResult(p.pending, *result)
+}
+
+// Pending 返回待上报的探测结果，包含上次未能上报的结果，截止时间为当前时间
+func (p *Prober) Pending() Batch {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.sending == nil {
+		p.sending = make(map[resultKey]*Result)
+		p.sendingStart = p.pendingStart
+	}
+	for _, result := range p.pending {
+		mergeResult(p.sending, *result)
+	}
+	p.pending = make(map[resultKey]*Result)
+	p.pendingStart = time.Time{}
+
+	batch := Batch{Start: p.sendingStart, End: time.Now()}
+	for _, result := range p.sending {
+		batch.Results = append(batch.Results, *result)
+	}
+	sort.Slice(batch.Results, func(i, j int) bool {
+		a, b := batch.Results[i], batch.Results[j]
+		if a.Outbound != b.Outbound {
+			return a.Outbound < b.Outbound
+		}
+		if a.TargetType != b.TargetType {
+			return a.TargetType < b.TargetType
+		}
+		return a.Target < b.Target
+	})
+	return batch
+}
+
+// Commit 上报成功后丢弃已上报的结果，之后的探测结果保留到下一次上报
+func (p *Prober) Commit() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.sending = nil
+	p.sendingStart = time.Time{}
+}
+
+// mergeResult 将较新的探测结果累加到results中
+func mergeResult(results map[resultKey]*Result, r Result) {
+	key := resultKey{outbound: r.Outbound, targetType: r.TargetType, target: r.Target}
+	result, ok := results[key]
+	if !ok {
+		results[key] = &r
+		return
+	}
+
+	result.Probes += r.Probes
+	result.Successes += r.Successes
+	result.LatencySumMs += r.LatencySumMs
+	if r.MaxLatencyMs > result.MaxLatencyMs {
+		result.MaxLatencyMs = r.MaxLatencyMs
+	}
+	if r.Successes > 0 {
+		result.LastLatencyMs = r.LastLatencyMs
+	}
+	if r.LastError != "" {
+		result.LastError = r.LastError
+	}
+	if r.LastProbe.After(result.LastProbe) {
+		result.LastProbe = r.LastProbe
+	}
+}
+
+// Probe 经由指定出站探测一次目标，返回成功时的延迟
+func Probe(ctx context.Context, ep Endpoint, outbound string, target Target) (time.Duration, error) {
+	dialer, err := proxy.SOCKS5("tcp", ep.Addr, &proxy.Auth{User: outbound, Password: ep.Password}, &net.Dialer{})
+	if err != nil {
+		return 0, fmt.Errorf("创建探测连接失败: %v", err)
+	}
+	contextDialer, ok := dialer.(proxy.ContextDialer)
+	if !ok {
+		return 0, fmt.Errorf("探测连接不支持超时控制")
+	}
+
+	start := time.Now()
+	switch target.Type {
+	case TypeHTTP:
+		err = probeHTTP(ctx, contextDialer, target.Address)
+	case TypeTCP:
+		err = probeTCP(ctx, contextDialer, target.Address)
+	case TypeDNS:
+		err = probeDNS(ctx, contextDialer, target.Address, target.domain())
+	default:
+		err = fmt.Errorf("不支持的探测类型: %s", target.Type)
+	}
+	if err != nil {
+		return 0, err
+	}
+	return time.Since(start), nil
+}
+
+// probeHTTP 请求URL，不跟随重定向，状态码小于400即成功
+func probeHTTP(ctx context.Context, dialer proxy.ContextDialer, address string) error {
+	client := &http.Client{
+		Transport: &http.Transport{
+			DialContext:       dialer.DialContext,
+			DisableKeepAlives: true,
+		},
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, address, nil)
+	if err != nil {
+		return fmt.Errorf("创建HTTP请求失败: %v", err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("HTTP请求失败: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("HTTP状态码异常: %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// probeTCP 建立TCP连接
+func probeTCP(ctx context.Context, dialer proxy.ContextDialer, address string) error {
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return fmt.Errorf("TCP连接失败: %v", err)
+	}
+	conn.Close()
+	return nil
+}
+
+// probeDNS 经出站以TCP向DNS服务器查询域名，至少返回一个地址即成功
+func probeDNS(ctx context.Context, dialer proxy.ContextDialer, server, domain string) error {
+	resolver := &net.Resolver{
+		PreferGo: true,
+		// 返回流式连接时解析器使用TCP格式的DNS报文
+		Dial: func(ctx context.Context, network, address string) (net.Conn, error) {
+			return dialer.DialContext(ctx, "tcp", server)
+		},
+	}
+	if !strings.HasSuffix(domain, ".") {
+		domain += "."
+	}
+	addrs, err := resolver.LookupHost(ctx, domain)
+	if err != nil {
+		return fmt.Errorf("DNS查询失败: %v", err)
+	}
+	if len(addrs) == 0 {
+		return fmt.Errorf("DNS查询无结果: %s", domain)
+	}
+	return nil
+}
@@ -0,0 +1,356 @@
+package probe
+
+import (
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// socksStub 代替sing-box探测入站的最小SOCKS5服务器，只接受用户名/密码认证和CONNECT，
+// 校验密码后直接连接目标并记录各用户名（出站tag）的连接次数
+type socksStub struct {
+	ln       net.Listener
+	password string
+
+	mu    sync.Mutex
+	users map[string]int
+}
+
+func newSocksStub(t *testing.T, password string) *socksStub {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("监听失败: %v", err)
+	}
+	s := &socksStub{ln: ln, password: password, users: make(map[string]int)}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+	return s
+}
+
+func (s *socksStub) addr() string {
+	return s.ln.Addr().String()
+}
+
+func (s *socksStub) count(user string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.users[user]
+}
+
+func (s *socksStub) serve(conn net.Conn) {
+	defer conn.Close()
+
+	// 方法协商：只接受用户名/密码认证
+	head := make([]byte, 2)
+	if _, err := io.ReadFull(conn, head); err != nil || head[0] != 5 {
+		return
+	}
+	methods := make([]byte, head[1])
+	if _, err := io.ReadFull(conn, methods); err != nil {
+		return
+	}
+	conn.Write([]byte{5, 2})
+
+	// 用户名/密码认证
+	if _, err := io.ReadFull(conn, head); err != nil {
+		return
+	}
+	user := make([]byte, head[1])
+	if _, err := io.ReadFull(conn, user); err != nil {
+		return
+	}
+	passLen := make([]byte, 1)
+	if _, err := io.ReadFull(conn, passLen); err != nil {
+		return
+	}
+	pass := make([]byte, passLen[0])
+	if _, err := io.ReadFull(conn, pass); err != nil {
+		return
+	}
+	if string(pass) != s.password {
+		conn.Write([]byte{1, 1})
+		return
+	}
+	conn.Write([]byte{1, 0})
+
+	// CONNECT请求
+	req := make([]byte, 4)
+	if _, err := io.ReadFull(conn, req); err != nil || req[1] != 1 {
+		return
+	}
+	var host string
+	switch req[3] {
+	case 1:
+		ip := make([]byte, 4)
+		if _, err := io.ReadFull(conn, ip); err != nil {
+			return
+		}
+		host = net.IP(ip).String()
+	case 3:
+		n := make([]byte, 1)
+		if _, err := io.ReadFull(conn, n); err != nil {
+			return
+		}
+		name := make([]byte, n[0])
+		if _, err := io.ReadFull(conn, name); err != nil {
+			return
+		}
+		host = string(name)
+	case 4:
+		ip := make([]byte, 16)
+		if _, err := io.ReadFull(conn, ip); err != nil {
+			return
+		}
+		host = net.IP(ip).String()
+	default:
+		return
+	}
+	portBuf := make([]byte, 2)
+	if _, err := io.ReadFull(conn, portBuf); err != nil {
+		return
+	}
+	target := net.JoinHostPort(host, strconv.Itoa(int(binary.BigEndian.Uint16(portBuf))))
+
+	s.mu.Lock()
+	s.users[string(user)]++
+	s.mu.Unlock()
+
+	upstream, err := net.DialTimeout("tcp", target, time.Second)
+	if err != nil {
+		// 主机不可达
+		conn.Write([]byte{5, 4, 0, 1, 0, 0, 0, 0, 0, 0})
+		return
+	}
+	defer upstream.Close()
+	conn.Write([]byte{5, 0, 0, 1, 0, 0, 0, 0, 0, 0})
+
+	go io.Copy(upstream, conn)
+	io.Copy(conn, upstream)
+}
+
+// closedAddr 返回一个当前没有监听的本地地址
+func closedAddr(t *testing.T) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("监听失败: %v", err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+	return addr
+}
+
+func TestTargetValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		target  Target
+		wantErr bool
+	}{
+		{"http", Target{Type: TypeHTTP, Address: "http://127.0.0.1:8080/generate_204"}, false},
+		{"https", Target{Type: TypeHTTP, Address: "https://www.gstatic.com/generate_204"}, false},
+		{"http缺少scheme", Target{Type: TypeHTTP, Address: "www.gstatic.com/generate_204"}, true},
+		{"http不支持的scheme", Target{Type: TypeHTTP, Address: "ftp://example.com"}, true},
+		{"tcp", Target{Type: TypeTCP, Address: "1.1.1.1:443"}, false},
+		{"tcp缺少端口", Target{Type: TypeTCP, Address: "1.1.1.1"}, true},
+		{"dns", Target{Type: TypeDNS, Address: "8.8.8.8:53", Domain: "example.com"}, false},
+		{"dns缺少端口", Target{Type: TypeDNS, Address: "8.8.8.8"}, true},
+		{"未知类型", Target{Type: "icmp", Address: "1.1.1.1"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.target.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTargetKey(t *testing.T) {
+	tests := []struct {
+		target Target
+		want   string
+	}{
+		{Target{Type: TypeHTTP, Address: "http://a/b"}, "http://a/b"},
+		{Target{Type: TypeTCP, Address: "1.1.1.1:443"}, "1.1.1.1:443"},
+		{Target{Type: TypeDNS, Address: "8.8.8.8:53"}, "8.8.8.8:53/" + defaultDNSDomain},
+		{Target{Type: TypeDNS, Address: "8.8.8.8:53", Domain: "example.com"}, "8.8.8.8:53/example.com"},
+	}
+	for _, tt := range tests {
+		if got := tt.target.Key(); got != tt.want {
+			t.Errorf("Key() = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestProbe(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/generate_204":
+			w.WriteHeader(http.StatusNoContent)
+		case "/redirect":
+			http.Redirect(w, r, "/elsewhere", http.StatusFound)
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	stub := newSocksStub(t, "secret")
+	ep := Endpoint{Addr: stub.addr(), Password: "secret"}
+	tcpAddr := strings.TrimPrefix(server.URL, "http://")
+
+	tests := []struct {
+		name     string
+		endpoint Endpoint
+		target   Target
+		wantErr  bool
+	}{
+		{"http 204", ep, Target{Type: TypeHTTP, Address: server.URL + "/generate_204"}, false},
+		{"http重定向不跟随", ep, Target{Type: TypeHTTP, Address: server.URL + "/redirect"}, false},
+		{"http 500", ep, Target{Type: TypeHTTP, Address: server.URL + "/error"}, true},
+		{"tcp", ep, Target{Type: TypeTCP, Address: tcpAddr}, false},
+		{"tcp目标未监听", ep, Target{Type: TypeTCP, Address: closedAddr(t)}, true},
+		{"探测入站密码错误", Endpoint{Addr: stub.addr(), Password: "wrong"}, Target{Type: TypeTCP, Address: tcpAddr}, true},
+		{"探测入站未监听", Endpoint{Addr: closedAddr(t), Password: "secret"}, Target{Type: TypeTCP, Address: tcpAddr}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+			defer cancel()
+			latency, err := Probe(ctx, tt.endpoint, "direct", tt.target)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Probe() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && latency <= 0 {
+				t.Errorf("Probe() latency = %v, want > 0", latency)
+			}
+		})
+	}
+}
+
+func TestProberRunOnce(t *testing.T) {
+	var hits int
+	var mu sync.Mutex
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		hits++
+		mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	stub := newSocksStub(t, "secret")
+	down := closedAddr(t)
+	endpoint := func() (Endpoint, bool) {
+		return Endpoint{Addr: stub.addr(), Password: "secret", Outbounds: []string{"direct", "relay", "skipped"}}, true
+	}
+	prober := NewProber(endpoint, Options{
+		Timeout: 3 * time.Second,
+		Targets: []Target{
+			{Type: TypeHTTP, Address: server.URL + "/generate_204"},
+			{Type: TypeTCP, Address: down},
+			{Type: "icmp", Address: "ignored"},
+		},
+		Outbounds: []string{"direct", "relay"},
+	})
+
+	for i := 0; i < 2; i++ {
+		if err := prober.RunOnce(context.Background()); err != nil {
+			t.Fatalf("RunOnce() error = %v", err)
+		}
+	}
+
+	if got := stub.count("skipped"); got != 0 {
+		t.Errorf("未选择的出站被探测了 %d 次", got)
+	}
+	for _, outbound := range []string{"direct", "relay"} {
+		if got := stub.count(outbound); got != 4 {
+			t.Errorf("出站 %s 的探测连接数 = %d, want 4", outbound, got)
+		}
+	}
+	if hits != 4 {
+		t.Errorf("HTTP目标请求次数 = %d, want 4", hits)
+	}
+
+	batch := prober.Pending()
+	if len(batch.Results) != 4 {
+		t.Fatalf("结果数 = %d, want 4: %+v", len(batch.Results), batch.Results)
+	}
+	if batch.Start.IsZero() || batch.End.Before(batch.Start) {
+		t.Errorf("统计周期无效: %v - %v", batch.Start, batch.End)
+	}
+	for _, result := range batch.Results {
+		if result.Probes != 2 {
+			t.Errorf("%s/%s 探测次数 = %d, want 2", result.Outbound, result.Target, result.Probes)
+		}
+		switch result.TargetType {
+		case TypeHTTP:
+			if result.Successes != 2 || result.LastError != "" {
+				t.Errorf("%s HTTP探测结果 = %+v, want 全部成功", result.Outbound, result)
+			}
+			if result.MaxLatencyMs < result.LastLatencyMs || result.AvgLatencyMs() > float64(result.MaxLatencyMs) {
+				t.Errorf("%s 延迟统计不一致: %+v", result.Outbound, result)
+			}
+		case TypeTCP:
+			if result.Successes != 0 || result.LastError == "" || result.AvgLatencyMs() != 0 {
+				t.Errorf("%s TCP探测结果 = %+v, want 全部失败", result.Outbound, result)
+			}
+		default:
+			t.Errorf("意外的探测类型: %s", result.TargetType)
+		}
+	}
+}
+
+func TestProberPendingCommit(t *testing.T) {
+	prober := NewProber(func() (Endpoint, bool) { return Endpoint{}, false }, Options{})
+	if err := prober.RunOnce(context.Background()); err == nil {
+		t.Fatal("探测入站未启用时 RunOnce() 应返回错误")
+	}
+
+	target := Target{Type: TypeTCP, Address: "127.0.0.1:1"}
+	prober.record("direct", target, 10*time.Millisecond, nil)
+
+	// 上报失败时结果保留，并与之后的探测结果合并
+	first := prober.Pending()
+	if len(first.Results) != 1 || first.Results[0].Probes != 1 {
+		t.Fatalf("第一次取出的结果 = %+v", first.Results)
+	}
+	prober.record("direct", target, 30*time.Millisecond, nil)
+	prober.record("direct", target, 0, io.EOF)
+
+	second := prober.Pending()
+	if len(second.Results) != 1 {
+		t.Fatalf("第二次取出的结果 = %+v", second.Results)
+	}
+	got := second.Results[0]
+	if got.Probes != 3 || got.Successes != 2 || got.LatencySumMs != 40 || got.MaxLatencyMs != 30 || got.LastError == "" {
+		t.Errorf("合并后的结果 = %+v", got)
+	}
+	if !second.Start.Equal(first.Start) {
+		t.Errorf("未上报的统计周期起点被重置: %v != %v", second.Start, first.Start)
+	}
+
+	// 上报成功后丢弃
+	prober.Commit()
+	if third := prober.Pending(); len(third.Results) != 0 {
+		t.Errorf("Commit 后仍有结果: %+v", third.Results)
+	}
+}
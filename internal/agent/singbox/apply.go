@@ -0,0 +1,458 @@
+package singbox
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 配置应用流水线的阶段名称
+const (
+	PhaseStage     = "stage"     // 写入暂存文件
+	PhaseValidate  = "validate"  // 语义校验后由sing-box check校验暂存文件
+	PhasePreflight = "preflight" // 检查入站端口是否已被占用
+	PhaseSwap      = "swap"      // 原子替换正式配置
+	PhaseReload    = "reload"    // 向sing-box发送SIGHUP热重载
+	PhaseRestart   = "restart"   // 重启sing-box
+	PhaseProbe     = "probe"     // 启动后健康探测
+	PhaseRevert    = "revert"    // 探测失败后回退到上一代配置
+)
+
+// ApplyOptions 配置应用选项
+type ApplyOptions struct {
+	Source        string        // 配置来源，记录到配置历史
+	Reload        bool          // 优先热重载，无法确认生效时回退到完整重启
+	ProbeGrace    time.Duration // 健康探测宽限期，进程需在此期间保持存活且入站端口就绪
+	ProbeInterval time.Duration // 探测间隔
+	Force         bool          // 入站端口与已有监听冲突时仍然应用
+}
+
+// DefaultApplyOptions 默认应用选项
+func DefaultApplyOptions() ApplyOptions {
+	return ApplyOptions{
+		Reload:        true,
+		ProbeGrace:    5 * time.Second,
+		ProbeInterval: 500 * time.Millisecond,
+	}
+}
+
+// PhaseResult 单个阶段的执行结果
+type PhaseResult struct {
+	Phase    string        `json:"phase"`
+	Success  bool          `json:"success"`
+	Skipped  bool          `json:"skipped,omitempty"`
+	Message  string        `json:"message,omitempty"`
+	Duration time.Duration `json:"duration"`
+}
+
+// ApplyResult 配置应用结果
+type ApplyResult struct {
+	Phases    []PhaseResult  `json:"phases"`
+	Applied   bool           `json:"applied"`             // 新配置是否最终生效
+	Reverted  bool           `json:"reverted"`            // 是否已回退到上一代配置
+	Version   int64          `json:"version"`             // 生效后记录的配置代版本
+	Conflicts []PortConflict `json:"conflicts,omitempty"` // 预检发现的端口冲突
+}
+
+// Err 返回导致应用失败的首个阶段错误
+func (r *ApplyResult) Err() error {
+	if r.Applied {
+		return nil
+	}
+	for _, phase := range r.Phases {
+		// 热重载失败会回退到完整重启，不作为失败原因
+		if phase.Phase == PhaseReload {
+			continue
+		}
+		if !phase.Success && !phase.Skipped {
+			return fmt.Errorf("%s阶段失败: %s", phase.Phase, phase.Message)
+		}
+	}
+	return fmt.Errorf("配置未生效")
+}
+
+// run 执行一个阶段并记录结果
+func (r *ApplyResult) run(phase string, fn func() (string, error)) bool {
+	return runPhase(&r.Phases, "配置应用", phase, fn)
+}
+
+// runPhase 执行一个阶段，将结果追加到phases，label用于日志
+func runPhase(phases *[]PhaseResult, label, phase string, fn func() (string, error)) bool {
+	start := time.Now()
+	message, err := fn()
+	result := PhaseResult{
+		Phase:    phase,
+		Success:  err == nil,
+		Message:  message,
+		Duration: time.Since(start),
+	}
+	if err != nil {
+		result.Message = err.Error()
+	}
+	*phases = append(*phases, result)
+
+	if err != nil {
+		log.Printf("%s[%s]失败: %v", label, phase, err)
+	} else {
+		log.Printf("%s[%s]完成: %s (耗时: %v)", label, phase, message, result.Duration)
+	}
+	return err == nil
+}
+
+// skip 记录被跳过的阶段
+func (r *ApplyResult) skip(phase, reason string) {
+	r.Phases = append(r.Phases, PhaseResult{
+		Phase:   phase,
+		Success: true,
+		Skipped: true,
+		Message: reason,
+	})
+}
+
+// ApplyConfig 通过暂存-校验-替换-重启-探测流水线应用配置，探测失败时自动回退
+func (m *Manager) ApplyConfig(config *Config, opts ApplyOptions) *ApplyResult {
+	m.ensureClashAPI(config)
+	m.ensureProbeInbound(config)
+	m.ensureV2RayStats(config)
+	data, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		result := &ApplyResult{}
+		result.run(PhaseStage, func() (string, error) {
+			return "", fmt.Errorf("序列化配置失败: %v", err)
+		})
+		return result
+	}
+	return m.applyData(data, config, opts)
+}
+
+// applyData 应用已序列化的配置内容，config为其解析结果
+func (m *Manager) applyData(data []byte, config *Config, opts ApplyOptions) *ApplyResult {
+	m.applyMu.Lock()
+	defer m.applyMu.Unlock()
+
+	result := &ApplyResult{}
+	stagingPath := m.configPath + ".staging"
+	defer os.Remove(stagingPath)
+
+	// 1. 写入暂存文件
+	if !result.run(PhaseStage, func() (string, error) {
+		if err := writeFileSync(stagingPath, data, 0600); err != nil {
+			return "", fmt.Errorf("写入暂存配置失败: %v", err)
+		}
+		return fmt.Sprintf("已写入 %s (%d 字节)", stagingPath, len(data)), nil
+	}) {
+		return result
+	}
+
+	// 2. 先做语义校验，再由sing-box check校验暂存文件
+	if !result.run(PhaseValidate, func() (string, error) {
+		if errs := Validate(config); errs != nil {
+			return "", errs
+		}
+		return m.checkConfigFile(stagingPath)
+	}) {
+		return result
+	}
+
+	// 3. 检查入站端口占用，存在冲突时拒绝应用（Force时仅记录）
+	conflicts, portErr := m.CheckPorts(config)
+	result.Conflicts = conflicts
+	if portErr != nil {
+		result.skip(PhasePreflight, fmt.Sprintf("无法检查端口占用: %v", portErr))
+	} else if !result.run(PhasePreflight, func() (string, error) {
+		switch {
+		case len(conflicts) == 0:
+			return "入站端口均可用", nil
+		case opts.Force:
+			return fmt.Sprintf("强制应用，忽略 %d 个端口冲突", len(conflicts)), nil
+		default:
+			return "", &PortConflictError{Conflicts: conflicts}
+		}
+	}) {
+		return result
+	}
+
+	// 4. 原子替换正式配置，历史为空时先将现有配置记为初始代
+	previous, prevErr := os.ReadFile(m.configPath)
+	if !result.run(PhaseSwap, func() (string, error) {
+		if _, ok := m.history.Latest(); !ok && prevErr == nil {
+			if _, err := m.history.Record(previous, "initial"); err != nil {
+				log.Printf("记录初始配置代失败: %v", err)
+			}
+		}
+		if err := os.Rename(stagingPath, m.configPath); err != nil {
+			return "", fmt.Errorf("替换配置文件失败: %v", err)
+		}
+		return fmt.Sprintf("已替换 %s", m.configPath), nil
+	}) {
+		return result
+	}
+
+	m.mu.Lock()
+	previousConfig := m.lastConfig
+	m.lastConfig = config
+	m.mu.Unlock()
+
+	// 5. 热重载或重启sing-box（未运行时仅替换配置文件）
+	if !m.IsRunning() {
+		if opts.Reload {
+			result.skip(PhaseReload, "sing-box未运行")
+		}
+		result.skip(PhaseRestart, "sing-box未运行")
+		result.skip(PhaseProbe, "sing-box未运行")
+		m.recordGeneration(result, data, opts.Source)
+		return result
+	}
+
+	// 热重载失败时回退到完整重启
+	reloaded := opts.Reload && result.run(PhaseReload, func() (string, error) {
+		if err := m.Reload(); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("已热重载，PID: %d", m.GetPID()), nil
+	})
+
+	ok := true
+	if reloaded {
+		result.skip(PhaseRestart, "已热重载")
+	} else {
+		ok = result.run(PhaseRestart, func() (string, error) {
+			if err := m.Restart(); err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("PID: %d", m.GetPID()), nil
+		})
+	}
+
+	// 6. 启动后健康探测
+	if ok {
+		ok = result.run(PhaseProbe, func() (string, error) {
+			return m.probeHealth(config, opts)
+		})
+	} else {
+		result.skip(PhaseProbe, "重启失败")
+	}
+
+	if ok {
+		m.recordGeneration(result, data, opts.Source)
+		return result
+	}
+
+	// 7. 回退到上一代配置
+	if prevErr != nil {
+		result.skip(PhaseRevert, "不存在上一代配置")
+		return result
+	}
+	result.Reverted = result.run(PhaseRevert, func() (string, error) {
+		if err := writeFileSync(stagingPath, previous, 0600); err != nil {
+			return "", fmt.Errorf("写入上一代配置失败: %v", err)
+		}
+		if err := os.Rename(stagingPath, m.configPath); err != nil {
+			return "", fmt.Errorf("恢复配置文件失败: %v", err)
+		}
+
+		m.mu.Lock()
+		m.lastConfig = previousConfig
+		m.mu.Unlock()
+
+		if err := m.Restart(); err != nil {
+			return "", fmt.Errorf("以上一代配置重启失败: %v", err)
+		}
+		return "已恢复上一代配置并重启", nil
+	})
+
+	return result
+}
+
+// checkConfigFile 使用sing-box check校验配置文件
+func (m *Manager) checkConfigFile(path string) (string, error) {
+	return checkConfigWith(m.binaryPath, path)
+}
+
+// checkConfigWith 使用指定的sing-box二进制校验配置文件
+func checkConfigWith(binary, path string) (string, error) {
+	cmd := exec.Command(binary, "check", "-c", path)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		detail := strings.TrimSpace(string(output))
+		if detail == "" {
+			return "", fmt.Errorf("配置验证失败: %v", err)
+		}
+		return "", fmt.Errorf("配置验证失败: %v: %s", err, detail)
+	}
+	return "sing-box check通过", nil
+}
+
+// probeHealth 在宽限期内探测进程存活及入站端口监听情况
+func (m *Manager) probeHealth(config *Config, opts ApplyOptions) (string, error) {
+	if opts.ProbeGrace <= 0 {
+		opts.ProbeGrace = DefaultApplyOptions().ProbeGrace
+	}
+	if opts.ProbeInterval <= 0 {
+		opts.ProbeInterval = DefaultApplyOptions().ProbeInterval
+	}
+
+	targets := probeTargets(config)
+	pending := make(map[string]bool, len(targets))
+	for _, target := range targets {
+		pending[target] = true
+	}
+
+	deadline := time.Now().Add(opts.ProbeGrace)
+	for {
+		if !m.IsRunning() {
+			status := m.SupervisorStatus()
+			if status.LastExit != nil {
+				return "", fmt.Errorf("sing-box进程已退出: 退出码=%d %s",
+					status.LastExit.ExitCode, strings.Join(status.LastExit.StderrTail, "; "))
+			}
+			return "", fmt.Errorf("sing-box进程已退出")
+		}
+
+		for target := range pending {
+			conn, err := net.DialTimeout("tcp", target, opts.ProbeInterval)
+			if err == nil {
+				conn.Close()
+				delete(pending, target)
+			}
+		}
+
+		if time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(opts.ProbeInterval)
+	}
+
+	if len(pending) > 0 {
+		missing := make([]string, 0, len(pending))
+		for target := range pending {
+			missing = append(missing, target)
+		}
+		return "", fmt.Errorf("入站端口未就绪: %s", strings.Join(missing, ", "))
+	}
+
+	return fmt.Sprintf("进程存活，%d 个入站端口就绪", len(targets)), nil
+}
+
+// probeTargets 返回需要探测的TCP入站地址
+func probeTargets(config *Config) []string {
+	var targets []string
+	for _, inbound := range config.Inbounds {
+		if inbound.ListenPort == 0 || !inboundUsesTCP(inbound.Type) {
+			continue
+		}
+
+		host := inbound.Listen
+		switch host {
+		case "", "0.0.0.0":
+			host = "127.0.0.1"
+		case "::":
+			host = "::1"
+		}
+		targets = append(targets, net.JoinHostPort(host, strconv.Itoa(int(inbound.ListenPort))))
+	}
+	return targets
+}
+
+// inboundUsesTCP 判断入站类型是否监听TCP端口
+func inboundUsesTCP(inboundType string) bool {
+	switch inboundType {
+	case "hysteria", "hysteria2", "tuic", "tun":
+		return false
+	default:
+		return true
+	}
+}
+
+// recordGeneration 标记配置已生效并记录为新的配置代
+func (m *Manager) recordGeneration(result *ApplyResult, data []byte, source string) {
+	result.Applied = true
+	if source == "" {
+		source = "unknown"
+	}
+
+	gen, err := m.history.Record(data, source)
+	if err != nil {
+		log.Printf("记录配置代失败: %v", err)
+		return
+	}
+	result.Version = gen.Version
+	log.Printf("配置已记录为第 %d 代 (来源: %s, sha256: %s)", gen.Version, gen.Source, gen.SHA256[:12])
+}
+
+// ListGenerations 列出已记录的配置代
+func (m *Manager) ListGenerations() ([]Generation, error) {
+	return m.history.List()
+}
+
+// DiffGenerations 比较两代配置，toVersion为0时与当前代比较
+func (m *Manager) DiffGenerations(fromVersion, toVersion int64) (string, error) {
+	if toVersion == 0 {
+		latest, ok := m.history.Latest()
+		if !ok {
+			return "", fmt.Errorf("尚无配置历史")
+		}
+		toVersion = latest.Version
+	}
+	return m.history.Diff(fromVersion, toVersion)
+}
+
+// RollbackToGeneration 将sing-box配置回滚到指定代，version为0时回滚到上一代
+func (m *Manager) RollbackToGeneration(version int64, opts ApplyOptions) (*ApplyResult, error) {
+	if version == 0 {
+		previous, err := m.history.Previous()
+		if err != nil {
+			return nil, err
+		}
+		version = previous
+	}
+
+	_, data, err := m.history.Get(version)
+	if err != nil {
+		return nil, err
+	}
+
+	var config Config
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("解析配置代 %d 失败: %v", version, err)
+	}
+
+	if opts.Source == "" {
+		opts.Source = fmt.Sprintf("rollback:%d", version)
+	}
+	result := m.applyData(data, &config, opts)
+	return result, result.Err()
+}
+
+// SetHistoryLimit 设置保留的配置代数
+func (m *Manager) SetHistoryLimit(limit int) {
+	m.history.SetLimit(limit)
+}
+
+// writeFileSync 写入文件并刷盘
+func writeFileSync(path string, data []byte, perm os.FileMode) error {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+	// 已存在的文件不受OpenFile的perm影响，需显式收紧权限
+	if err := file.Chmod(perm); err != nil {
+		file.Close()
+		return err
+	}
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
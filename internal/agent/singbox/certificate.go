@@ -0,0 +1,75 @@
+package singbox
+
+import "fmt"
+
+// tlsInboundTypes 支持服务端TLS证书的入站类型
+var tlsInboundTypes = map[string]bool{
+	"http":      true,
+	"vmess":     true,
+	"vless":     true,
+	"trojan":    true,
+	"naive":     true,
+	"hysteria":  true,
+	"hysteria2": true,
+	"tuic":      true,
+	"anytls":    true,
+}
+
+// CertificatePaths 返回当前配置中各入站引用的证书和私钥文件路径
+func (m *Manager) CertificatePaths() map[string]bool {
+	paths := make(map[string]bool)
+	config := m.GetConfig()
+	if config == nil {
+		return paths
+	}
+	for _, inbound := range config.Inbounds {
+		if inbound.TLS == nil {
+			continue
+		}
+		if inbound.TLS.CertificatePath != "" {
+			paths[inbound.TLS.CertificatePath] = true
+		}
+		if inbound.TLS.KeyPath != "" {
+			paths[inbound.TLS.KeyPath] = true
+		}
+	}
+	return paths
+}
+
+// SetInboundCertificate 将入站TLS切换为指定的证书文件并应用配置，
+// 内联证书和ACME配置会被清除，其余TLS参数保持不变
+func (m *Manager) SetInboundCertificate(tag, certPath, keyPath string) (*ApplyResult, error) {
+	if certPath == "" || keyPath == "" {
+		return nil, fmt.Errorf("证书和私钥路径不能为空")
+	}
+	config := m.GetConfig()
+	if config == nil {
+		return nil, fmt.Errorf("sing-box配置未加载")
+	}
+
+	inbound, err := findInbound(config, tag)
+	if err != nil {
+		return nil, err
+	}
+	if !tlsInboundTypes[inbound.Type] {
+		return nil, fmt.Errorf("入站类型 %s 不支持TLS证书", inbound.Type)
+	}
+	if inbound.TLS == nil {
+		inbound.TLS = &InboundTLS{}
+	}
+	if inbound.TLS.Reality != nil && inbound.TLS.Reality.Enabled {
+		return nil, fmt.Errorf("入站 %s 已启用Reality，不能使用证书", tag)
+	}
+
+	inbound.TLS.Enabled = true
+	inbound.TLS.Certificate = nil
+	inbound.TLS.Key = nil
+	inbound.TLS.ACME = nil
+	inbound.TLS.CertificatePath = certPath
+	inbound.TLS.KeyPath = keyPath
+
+	opts := DefaultApplyOptions()
+	opts.Source = "certificate:" + tag
+	result := m.ApplyConfig(config, opts)
+	return result, result.Err()
+}
@@ -0,0 +1,350 @@
+package singbox
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const (
+	defaultCgroupRoot   = "/sys/fs/cgroup"
+	defaultCgroupParent = "xbox-singbox"
+	defaultCPUPeriod    = 100000 // cpu.max默认周期（微秒）
+)
+
+// cgroupControllers sing-box的cgroup需要启用的控制器
+var cgroupControllers = []string{"cpu", "memory", "pids"}
+
+// ResourceLimits sing-box的cgroup v2资源限制，零值表示不限制
+type ResourceLimits struct {
+	MemoryMax int64 // memory.max（字节）
+	CPUQuota  int64 // cpu.max配额（微秒/周期）
+	CPUPeriod int64 // cpu.max周期（微秒）
+	PidsMax   int64 // pids.max
+}
+
+// CgroupOptions cgroup托管配置
+type CgroupOptions struct {
+	Root   string // cgroup v2挂载点
+	Parent string // 父cgroup名称，exec模式下进程位于 <Root>/<Parent>/<Name>，systemd模式下使用 <Parent>.slice
+	Name   string // 实例cgroup名称
+	Limits ResourceLimits
+}
+
+// ResourceUsage 从cgroup读取的sing-box资源用量
+type ResourceUsage struct {
+	Cgroup           string `json:"cgroup"`             // cgroup目录
+	MemoryCurrent    int64  `json:"memory_current"`     // 当前内存用量（字节）
+	MemoryMax        int64  `json:"memory_max"`         // 内存上限，0表示不限制
+	CPUUsageUsec     int64  `json:"cpu_usage_usec"`     // 累计CPU时间
+	CPUThrottledUsec int64  `json:"cpu_throttled_usec"` // 因cpu.max被限流的累计时间
+	NrThrottled      int64  `json:"nr_throttled"`       // 被限流的周期数
+	PidsCurrent      int64  `json:"pids_current"`
+	PidsMax          int64  `json:"pids_max"`  // 0表示不限制
+	OOMKills         int64  `json:"oom_kills"` // memory.events中的oom_kill计数
+}
+
+// cgroup sing-box所在的cgroup
+type cgroup struct {
+	root     string
+	parent   string
+	path     string // exec模式下的叶子cgroup目录，systemd模式下由systemd创建
+	limits   ResourceLimits
+	oomKills int64 // 上次读取的oom_kill计数，用于判断进程退出是否由OOM导致，由Manager.mu保护
+}
+
+// ParseResourceLimits 解析资源限制配置：memory支持K/M/G后缀，cpu为百分比（如150%表示1.5个CPU）或cpu.max格式"配额 周期"，
+// 空值或max表示不限制
+func ParseResourceLimits(memory, cpu string, pids int64) (ResourceLimits, error) {
+	var limits ResourceLimits
+
+	memory = strings.TrimSpace(memory)
+	if memory != "" && memory != "max" {
+		size, err := parseByteSize(memory)
+		if err != nil {
+			return limits, fmt.Errorf("memory_max无效: %v", err)
+		}
+		limits.MemoryMax = size
+	}
+
+	cpu = strings.TrimSpace(cpu)
+	if cpu != "" && cpu != "max" {
+		if percent, ok := strings.CutSuffix(cpu, "%"); ok {
+			value, err := strconv.ParseFloat(strings.TrimSpace(percent), 64)
+			if err != nil || value <= 0 {
+				return limits, fmt.Errorf("cpu_max无效: %s", cpu)
+			}
+			limits.CPUPeriod = defaultCPUPeriod
+			limits.CPUQuota = int64(value / 100 * defaultCPUPeriod)
+		} else {
+			fields := strings.Fields(cpu)
+			if len(fields) != 2 {
+				return limits, fmt.Errorf("cpu_max无效: %s，应为百分比或\"配额 周期\"", cpu)
+			}
+			quota, err1 := strconv.ParseInt(fields[0], 10, 64)
+			period, err2 := strconv.ParseInt(fields[1], 10, 64)
+			if err1 != nil || err2 != nil || quota <= 0 || period <= 0 {
+				return limits, fmt.Errorf("cpu_max无效: %s", cpu)
+			}
+			limits.CPUQuota = quota
+			limits.CPUPeriod = period
+		}
+		// 内核要求配额不小于1ms
+		if limits.CPUQuota < 1000 {
+			return limits, fmt.Errorf("cpu_max过小: %s", cpu)
+		}
+	}
+
+	if pids < 0 {
+		return limits, fmt.Errorf("pids_max不能为负数")
+	}
+	limits.PidsMax = pids
+
+	return limits, nil
+}
+
+// parseByteSize 解析带K/M/G/T后缀（1024进制）的字节数
+func parseByteSize(value string) (int64, error) {
+	units := map[string]int64{
+		"K": 1 << 10, "KB": 1 << 10, "KI": 1 << 10, "KIB": 1 << 10,
+		"M": 1 << 20, "MB": 1 << 20, "MI": 1 << 20, "MIB": 1 << 20,
+		"G": 1 << 30, "GB": 1 << 30, "GI": 1 << 30, "GIB": 1 << 30,
+		"T": 1 << 40, "TB": 1 << 40, "TI": 1 << 40, "TIB": 1 << 40,
+	}
+
+	upper := strings.ToUpper(value)
+	end := len(upper)
+	for end > 0 && (upper[end-1] < '0' || upper[end-1] > '9') {
+		end--
+	}
+	multiplier := int64(1)
+	if suffix := strings.TrimSpace(upper[end:]); suffix != "" && suffix != "B" {
+		m, ok := units[suffix]
+		if !ok {
+			return 0, fmt.Errorf("未知单位: %s", suffix)
+		}
+		multiplier = m
+	}
+
+	number, err := strconv.ParseInt(strings.TrimSpace(upper[:end]), 10, 64)
+	if err != nil || number <= 0 {
+		return 0, fmt.Errorf("无效的大小: %s", value)
+	}
+	return number * multiplier, nil
+}
+
+// SetCgroup 将sing-box放入独立的cgroup v2并应用资源限制，需在SetProcessMode之后、Start之前调用。
+// exec模式下由Agent创建cgroup并在进程启动后迁入；systemd模式下写入unit的Slice与资源限制指令
+func (m *Manager) SetCgroup(opts CgroupOptions) error {
+	root := opts.Root
+	if root == "" {
+		root = defaultCgroupRoot
+	}
+	parent := opts.Parent
+	if parent == "" {
+		parent = defaultCgroupParent
+	}
+	if strings.ContainsAny(parent+opts.Name, "/ \t\n") || opts.Name == "" {
+		return fmt.Errorf("cgroup名称无效: %s/%s", parent, opts.Name)
+	}
+	if _, err := os.Stat(filepath.Join(root, "cgroup.controllers")); err != nil {
+		return fmt.Errorf("未检测到cgroup v2挂载点 %s: %v", root, err)
+	}
+
+	cg := &cgroup{
+		root:   root,
+		parent: parent,
+		limits: opts.Limits,
+	}
+
+	if m.systemd() == nil {
+		cg.path = filepath.Join(root, parent, opts.Name)
+		if err := cg.create(); err != nil {
+			return err
+		}
+		if err := cg.apply(); err != nil {
+			return err
+		}
+		if usage, err := readResourceUsage(cg.path); err == nil {
+			cg.oomKills = usage.OOMKills
+		}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.running {
+		return fmt.Errorf("sing-box已在运行，无法设置cgroup")
+	}
+	m.cg = cg
+	return nil
+}
+
+// create 创建父cgroup和叶子cgroup，并为其启用所需的控制器
+func (cg *cgroup) create() error {
+	parent := filepath.Join(cg.root, cg.parent)
+	if err := os.MkdirAll(cg.path, 0755); err != nil {
+		return fmt.Errorf("创建cgroup失败: %v", err)
+	}
+
+	// cgroup v2要求控制器在各级祖先的subtree_control中启用，父cgroup本身不放置进程
+	for _, dir := range []string{cg.root, parent} {
+		for _, controller := range cgroupControllers {
+			if err := enableController(dir, controller); err != nil {
+				return fmt.Errorf("启用cgroup控制器%s失败(%s): %v", controller, dir, err)
+			}
+		}
+	}
+	return nil
+}
+
+// enableController 在cgroup的subtree_control中启用控制器，已启用时跳过
+func enableController(dir, controller string) error {
+	current, err := os.ReadFile(filepath.Join(dir, "cgroup.subtree_control"))
+	if err != nil {
+		return err
+	}
+	for _, enabled := range strings.Fields(string(current)) {
+		if enabled == controller {
+			return nil
+		}
+	}
+	return os.WriteFile(filepath.Join(dir, "cgroup.subtree_control"), []byte("+"+controller), 0644)
+}
+
+// apply 写入资源限制，未设置的项写入max以清除之前的限制
+func (cg *cgroup) apply() error {
+	memory := "max"
+	if cg.limits.MemoryMax > 0 {
+		memory = strconv.FormatInt(cg.limits.MemoryMax, 10)
+	}
+	cpu := fmt.Sprintf("max %d", defaultCPUPeriod)
+	if cg.limits.CPUQuota > 0 {
+		cpu = fmt.Sprintf("%d %d", cg.limits.CPUQuota, cg.limits.CPUPeriod)
+	}
+	pids := "max"
+	if cg.limits.PidsMax > 0 {
+		pids = strconv.FormatInt(cg.limits.PidsMax, 10)
+	}
+
+	for file, value := range map[string]string{
+		"memory.max": memory,
+		"cpu.max":    cpu,
+		"pids.max":   pids,
+	} {
+		if err := os.WriteFile(filepath.Join(cg.path, file), []byte(value), 0644); err != nil {
+			return fmt.Errorf("写入%s失败: %v", file, err)
+		}
+	}
+	return nil
+}
+
+// attach 将进程迁入cgroup
+func (cg *cgroup) attach(pid int) error {
+	return os.WriteFile(filepath.Join(cg.path, "cgroup.procs"), []byte(strconv.Itoa(pid)), 0644)
+}
+
+// checkOOM 读取oom_kill计数，计数增加说明期间发生过OOM终止
+func (cg *cgroup) checkOOM() bool {
+	usage, err := readResourceUsage(cg.path)
+	if err != nil {
+		return false
+	}
+	killed := usage.OOMKills > cg.oomKills
+	cg.oomKills = usage.OOMKills
+	return killed
+}
+
+// slice 返回systemd模式下使用的slice名称
+func (cg *cgroup) slice() string {
+	return cg.parent + ".slice"
+}
+
+// cgroupDir 返回sing-box当前所在的cgroup目录，未放入独立cgroup时返回空
+func (m *Manager) cgroupDir() string {
+	if unit := m.systemd(); unit != nil {
+		status, err := unit.status()
+		if err != nil || status.ControlGroup == "" {
+			return ""
+		}
+		root := defaultCgroupRoot
+		m.mu.RLock()
+		if m.cg != nil {
+			root = m.cg.root
+		}
+		m.mu.RUnlock()
+		return filepath.Join(root, status.ControlGroup)
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if m.cg == nil {
+		return ""
+	}
+	return m.cg.path
+}
+
+// ResourceUsage 读取sing-box所在cgroup的资源用量
+func (m *Manager) ResourceUsage() (*ResourceUsage, error) {
+	dir := m.cgroupDir()
+	if dir == "" {
+		return nil, fmt.Errorf("sing-box未放入独立cgroup")
+	}
+	return readResourceUsage(dir)
+}
+
+// readResourceUsage 读取cgroup的memory、cpu和pids统计，不存在的文件（控制器未启用）对应项保持为0
+func readResourceUsage(dir string) (*ResourceUsage, error) {
+	if _, err := os.Stat(dir); err != nil {
+		return nil, fmt.Errorf("读取cgroup失败: %v", err)
+	}
+
+	usage := &ResourceUsage{Cgroup: dir}
+	usage.MemoryCurrent = readCgroupInt(dir, "memory.current")
+	usage.MemoryMax = readCgroupInt(dir, "memory.max")
+	usage.PidsCurrent = readCgroupInt(dir, "pids.current")
+	usage.PidsMax = readCgroupInt(dir, "pids.max")
+
+	cpuStat := readCgroupKeyed(dir, "cpu.stat")
+	usage.CPUUsageUsec = cpuStat["usage_usec"]
+	usage.CPUThrottledUsec = cpuStat["throttled_usec"]
+	usage.NrThrottled = cpuStat["nr_throttled"]
+
+	usage.OOMKills = readCgroupKeyed(dir, "memory.events")["oom_kill"]
+
+	return usage, nil
+}
+
+// readCgroupInt 读取单值cgroup文件，max或读取失败时返回0
+func readCgroupInt(dir, file string) int64 {
+	data, err := os.ReadFile(filepath.Join(dir, file))
+	if err != nil {
+		return 0
+	}
+	value, _ := strconv.ParseInt(strings.TrimSpace(string(data)), 10, 64)
+	return value
+}
+
+// readCgroupKeyed 读取"键 值"格式的cgroup文件
+func readCgroupKeyed(dir, file string) map[string]int64 {
+	result := make(map[string]int64)
+	f, err := os.Open(filepath.Join(dir, file))
+	if err != nil {
+		return result
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 {
+			continue
+		}
+		if value, err := strconv.ParseInt(fields[1], 10, 64); err == nil {
+			result[fields[0]] = value
+		}
+	}
+	return result
+}
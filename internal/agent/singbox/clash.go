@@ -0,0 +1,81 @@
+package singbox
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"log"
+	"net"
+)
+
+// SetClashAPIListen 设置自动注入的本地Clash API监听地址，为空时不注入
+func (m *Manager) SetClashAPIListen(listen string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.clashAPIListen = listen
+}
+
+// ensureClashAPI 配置未启用Clash API时注入仅本机访问的Clash API，沿用当前配置中的secret以免产生无意义的配置差异
+func (m *Manager) ensureClashAPI(config *Config) {
+	m.mu.RLock()
+	listen := m.clashAPIListen
+	current := m.lastConfig
+	m.mu.RUnlock()
+
+	if listen == "" {
+		return
+	}
+	if config.Experimental != nil && config.Experimental.ClashAPI != nil && config.Experimental.ClashAPI.ExternalController != "" {
+		return
+	}
+
+	secret := ""
+	if current != nil && current.Experimental != nil && current.Experimental.ClashAPI != nil &&
+		current.Experimental.ClashAPI.ExternalController == listen {
+		secret = current.Experimental.ClashAPI.Secret
+	}
+	if secret == "" {
+		buf := make([]byte, 16)
+		if _, err := rand.Read(buf); err != nil {
+			log.Printf("生成Clash API密钥失败: %v", err)
+			return
+		}
+		secret = hex.EncodeToString(buf)
+	}
+
+	if config.Experimental == nil {
+		config.Experimental = &ExperimentalConfig{}
+	}
+	if config.Experimental.ClashAPI == nil {
+		config.Experimental.ClashAPI = &ClashAPIConfig{}
+	}
+	config.Experimental.ClashAPI.ExternalController = listen
+	config.Experimental.ClashAPI.Secret = secret
+}
+
+// ClashAPIEndpoint 返回当前配置中Clash API的本机访问地址与secret
+func (m *Manager) ClashAPIEndpoint() (string, string, bool) {
+	m.mu.RLock()
+	config := m.lastConfig
+	m.mu.RUnlock()
+
+	if config == nil {
+		loaded, err := m.LoadConfigFromFile()
+		if err != nil {
+			return "", "", false
+		}
+		config = loaded
+	}
+	if config.Experimental == nil || config.Experimental.ClashAPI == nil || config.Experimental.ClashAPI.ExternalController == "" {
+		return "", "", false
+	}
+
+	host, port, err := net.SplitHostPort(config.Experimental.ClashAPI.ExternalController)
+	if err != nil {
+		return "", "", false
+	}
+	// 监听在通配地址时通过本机回环访问
+	if host == "" || host == "0.0.0.0" || host == "::" {
+		host = "127.0.0.1"
+	}
+	return net.JoinHostPort(host, port), config.Experimental.ClashAPI.Secret, true
+}
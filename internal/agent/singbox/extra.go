@@ -0,0 +1,661 @@
+package singbox
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// 类型化配置结构只覆盖sing-box配置的一部分。每个结构通过Extra字段保存未建模的
+// JSON字段（如 rule_set、endpoints、services、新版传输选项），序列化时原样写回，
+// 保证控制端下发的配置在过滤器、多路复用等读改写路径中不丢失字段。
+//
+// 以下内容同样按原样保留：
+//   - 类型不符的已建模字段（如sing-box允许的单字符串形式的列表），不会导致整份配置解析失败
+//   - 显式写出的零值（如 "sniff": false），不会因omitempty被省略
+//   - 原始的键顺序，未修改的部分序列化后与原文保持一致的结构
+
+// RawFields 类型化结构之外的原始JSON字段
+type RawFields struct {
+	Fields map[string]json.RawMessage // 未建模、类型不符或显式零值的字段
+	order  []string                   // 解析时的键顺序
+}
+
+// Get 获取原始字段
+func (r RawFields) Get(key string) (json.RawMessage, bool) {
+	value, ok := r.Fields[key]
+	return value, ok
+}
+
+// Set 设置原始字段，value需为合法JSON
+func (r *RawFields) Set(key string, value json.RawMessage) {
+	if r.Fields == nil {
+		r.Fields = make(map[string]json.RawMessage)
+	}
+	r.Fields[key] = append(json.RawMessage(nil), value...)
+}
+
+// Delete 删除原始字段
+func (r *RawFields) Delete(key string) {
+	delete(r.Fields, key)
+}
+
+// MarshalObject 将原始字段编码为JSON对象，保持解析时的键顺序
+func (r RawFields) MarshalObject() ([]byte, error) {
+	return marshalWithExtra(struct{}{}, r)
+}
+
+// ParseRawFields 将JSON对象解析为原始字段
+func ParseRawFields(data []byte) (RawFields, error) {
+	keys, values, err := decodeObject(data)
+	if err != nil {
+		return RawFields{}, err
+	}
+	fields := RawFields{order: keys}
+	if len(values) > 0 {
+		fields.Fields = values
+	}
+	return fields, nil
+}
+
+// knownFieldCache 缓存各结构类型的JSON字段名
+var knownFieldCache sync.Map // map[reflect.Type]map[string]bool
+
+// unmarshalWithExtra 将data解析到v（不带自定义方法的别名类型指针），其余字段写入extra
+func unmarshalWithExtra(data []byte, v interface{}, extra *RawFields) error {
+	if bytes.Equal(bytes.TrimSpace(data), []byte("null")) {
+		return nil
+	}
+
+	keys, raw, err := decodeObject(data)
+	if err != nil {
+		return err
+	}
+
+	t := reflect.TypeOf(v).Elem()
+	known := knownFields(t)
+	fields := make(map[string]json.RawMessage)
+
+	if err := json.Unmarshal(data, v); err != nil {
+		// 整体解析失败时逐字段解析，无法解析的已建模字段原样保留
+		reflect.ValueOf(v).Elem().Set(reflect.Zero(t))
+		for _, key := range keys {
+			if !known[key] {
+				continue
+			}
+			field, err := json.Marshal(map[string]json.RawMessage{key: raw[key]})
+			if err != nil {
+				return err
+			}
+			if err := json.Unmarshal(field, reflect.New(t).Interface()); err != nil {
+				fields[key] = raw[key]
+				continue
+			}
+			if err := json.Unmarshal(field, v); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, key := range keys {
+		if !known[key] || isZeroJSON(raw[key]) {
+			fields[key] = raw[key]
+		}
+	}
+
+	*extra = RawFields{order: keys}
+	if len(fields) > 0 {
+		extra.Fields = fields
+	}
+	return nil
+}
+
+// marshalWithExtra 序列化v，合并extra中未被已建模字段输出的键，并尽量保持原始键顺序
+func marshalWithExtra(v interface{}, extra RawFields) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil || (len(extra.Fields) == 0 && len(extra.order) == 0) {
+		return data, err
+	}
+
+	typedKeys, values, err := decodeObject(data)
+	if err != nil {
+		return nil, err
+	}
+	var extraKeys []string
+	for key, value := range extra.Fields {
+		if _, ok := values[key]; !ok {
+			values[key] = value
+			extraKeys = append(extraKeys, key)
+		}
+	}
+	sort.Strings(extraKeys)
+
+	// 原始顺序优先，其次为新增的已建模字段，最后为新增的原始字段
+	order := make([]string, 0, len(values))
+	seen := make(map[string]bool, len(values))
+	for _, group := range [][]string{extra.order, typedKeys, extraKeys} {
+		for _, key := range group {
+			if _, ok := values[key]; ok && !seen[key] {
+				seen[key] = true
+				order = append(order, key)
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, key := range order {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		name, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(name)
+		buf.WriteByte(':')
+		buf.Write(values[key])
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// decodeObject 按顺序解析JSON对象的键值
+func decodeObject(data []byte) ([]string, map[string]json.RawMessage, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, nil, err
+	}
+	if delim, ok := tok.(json.Delim); !ok || delim != '{' {
+		return nil, nil, fmt.Errorf("期望JSON对象")
+	}
+
+	var keys []string
+	values := make(map[string]json.RawMessage)
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, nil, err
+		}
+		key := tok.(string)
+		var value json.RawMessage
+		if err := dec.Decode(&value); err != nil {
+			return nil, nil, err
+		}
+		if _, dup := values[key]; !dup {
+			keys = append(keys, key)
+		}
+		values[key] = value
+	}
+	return keys, values, nil
+}
+
+// isZeroJSON 判断是否为JSON零值（会被omitempty省略的值）
+func isZeroJSON(value json.RawMessage) bool {
+	switch string(bytes.TrimSpace(value)) {
+	case "false", "0", `""`, "null", "[]", "{}":
+		return true
+	}
+	return false
+}
+
+// knownFields 返回结构类型的JSON字段名集合（包含匿名嵌入结构的字段）
+func knownFields(t reflect.Type) map[string]bool {
+	if cached, ok := knownFieldCache.Load(t); ok {
+		return cached.(map[string]bool)
+	}
+
+	fields := make(map[string]bool)
+	collectFields(t, fields)
+	knownFieldCache.Store(t, fields)
+	return fields
+}
+
+// collectFields 递归收集JSON字段名
+func collectFields(t reflect.Type, fields map[string]bool) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+
+		name := strings.Split(tag, ",")[0]
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			collectFields(field.Type, fields)
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		fields[name] = true
+	}
+}
+
+// UnmarshalJSON 解析Config并保留未建模字段
+func (c *Config) UnmarshalJSON(data []byte) error {
+	type plain Config
+	return unmarshalWithExtra(data, (*plain)(c), &c.Extra)
+}
+
+// MarshalJSON 序列化Config并写回未建模字段
+func (c Config) MarshalJSON() ([]byte, error) {
+	type plain Config
+	return marshalWithExtra(plain(c), c.Extra)
+}
+
+// UnmarshalJSON 解析LogConfig并保留未建模字段
+func (l *LogConfig) UnmarshalJSON(data []byte) error {
+	type plain LogConfig
+	return unmarshalWithExtra(data, (*plain)(l), &l.Extra)
+}
+
+// MarshalJSON 序列化LogConfig并写回未建模字段
+func (l LogConfig) MarshalJSON() ([]byte, error) {
+	type plain LogConfig
+	return marshalWithExtra(plain(l), l.Extra)
+}
+
+// UnmarshalJSON 解析DNSConfig并保留未建模字段
+func (d *DNSConfig) UnmarshalJSON(data []byte) error {
+	type plain DNSConfig
+	return unmarshalWithExtra(data, (*plain)(d), &d.Extra)
+}
+
+// MarshalJSON 序列化DNSConfig并写回未建模字段
+func (d DNSConfig) MarshalJSON() ([]byte, error) {
+	type plain DNSConfig
+	return marshalWithExtra(plain(d), d.Extra)
+}
+
+// UnmarshalJSON 解析DNSServer并保留未建模字段
+func (d *DNSServer) UnmarshalJSON(data []byte) error {
+	type plain DNSServer
+	return unmarshalWithExtra(data, (*plain)(d), &d.Extra)
+}
+
+// MarshalJSON 序列化DNSServer并写回未建模字段
+func (d DNSServer) MarshalJSON() ([]byte, error) {
+	type plain DNSServer
+	return marshalWithExtra(plain(d), d.Extra)
+}
+
+// UnmarshalJSON 解析DNSRule并保留未建模字段
+func (d *DNSRule) UnmarshalJSON(data []byte) error {
+	type plain DNSRule
+	return unmarshalWithExtra(data, (*plain)(d), &d.Extra)
+}
+
+// MarshalJSON 序列化DNSRule并写回未建模字段
+func (d DNSRule) MarshalJSON() ([]byte, error) {
+	type plain DNSRule
+	return marshalWithExtra(plain(d), d.Extra)
+}
+
+// UnmarshalJSON 解析FakeIPConfig并保留未建模字段
+func (f *FakeIPConfig) UnmarshalJSON(data []byte) error {
+	type plain FakeIPConfig
+	return unmarshalWithExtra(data, (*plain)(f), &f.Extra)
+}
+
+// MarshalJSON 序列化FakeIPConfig并写回未建模字段
+func (f FakeIPConfig) MarshalJSON() ([]byte, error) {
+	type plain FakeIPConfig
+	return marshalWithExtra(plain(f), f.Extra)
+}
+
+// UnmarshalJSON 解析Inbound并保留未建模字段
+func (i *Inbound) UnmarshalJSON(data []byte) error {
+	type plain Inbound
+	return unmarshalWithExtra(data, (*plain)(i), &i.Extra)
+}
+
+// MarshalJSON 序列化Inbound并写回未建模字段
+func (i Inbound) MarshalJSON() ([]byte, error) {
+	type plain Inbound
+	return marshalWithExtra(plain(i), i.Extra)
+}
+
+// UnmarshalJSON 解析InboundUser并保留未建模字段
+func (i *InboundUser) UnmarshalJSON(data []byte) error {
+	type plain InboundUser
+	return unmarshalWithExtra(data, (*plain)(i), &i.Extra)
+}
+
+// MarshalJSON 序列化InboundUser并写回未建模字段
+func (i InboundUser) MarshalJSON() ([]byte, error) {
+	type plain InboundUser
+	return marshalWithExtra(plain(i), i.Extra)
+}
+
+// UnmarshalJSON 解析InboundTLS并保留未建模字段
+func (i *InboundTLS) UnmarshalJSON(data []byte) error {
+	type plain InboundTLS
+	return unmarshalWithExtra(data, (*plain)(i), &i.Extra)
+}
+
+// MarshalJSON 序列化InboundTLS并写回未建模字段
+func (i InboundTLS) MarshalJSON() ([]byte, error) {
+	type plain InboundTLS
+	return marshalWithExtra(plain(i), i.Extra)
+}
+
+// UnmarshalJSON 解析InboundReality并保留未建模字段
+func (i *InboundReality) UnmarshalJSON(data []byte) error {
+	type plain InboundReality
+	return unmarshalWithExtra(data, (*plain)(i), &i.Extra)
+}
+
+// MarshalJSON 序列化InboundReality并写回未建模字段
+func (i InboundReality) MarshalJSON() ([]byte, error) {
+	type plain InboundReality
+	return marshalWithExtra(plain(i), i.Extra)
+}
+
+// UnmarshalJSON 解析RealityHandshake并保留未建模字段
+func (r *RealityHandshake) UnmarshalJSON(data []byte) error {
+	type plain RealityHandshake
+	return unmarshalWithExtra(data, (*plain)(r), &r.Extra)
+}
+
+// MarshalJSON 序列化RealityHandshake并写回未建模字段
+func (r RealityHandshake) MarshalJSON() ([]byte, error) {
+	type plain RealityHandshake
+	return marshalWithExtra(plain(r), r.Extra)
+}
+
+// UnmarshalJSON 解析Hysteria2Obfs并保留未建模字段
+func (h *Hysteria2Obfs) UnmarshalJSON(data []byte) error {
+	type plain Hysteria2Obfs
+	return unmarshalWithExtra(data, (*plain)(h), &h.Extra)
+}
+
+// MarshalJSON 序列化Hysteria2Obfs并写回未建模字段
+func (h Hysteria2Obfs) MarshalJSON() ([]byte, error) {
+	type plain Hysteria2Obfs
+	return marshalWithExtra(plain(h), h.Extra)
+}
+
+// UnmarshalJSON 解析InboundMultiplex并保留未建模字段
+func (i *InboundMultiplex) UnmarshalJSON(data []byte) error {
+	type plain InboundMultiplex
+	return unmarshalWithExtra(data, (*plain)(i), &i.Extra)
+}
+
+// MarshalJSON 序列化InboundMultiplex并写回未建模字段
+func (i InboundMultiplex) MarshalJSON() ([]byte, error) {
+	type plain InboundMultiplex
+	return marshalWithExtra(plain(i), i.Extra)
+}
+
+// UnmarshalJSON 解析InboundBrutal并保留未建模字段
+func (i *InboundBrutal) UnmarshalJSON(data []byte) error {
+	type plain InboundBrutal
+	return unmarshalWithExtra(data, (*plain)(i), &i.Extra)
+}
+
+// MarshalJSON 序列化InboundBrutal并写回未建模字段
+func (i InboundBrutal) MarshalJSON() ([]byte, error) {
+	type plain InboundBrutal
+	return marshalWithExtra(plain(i), i.Extra)
+}
+
+// UnmarshalJSON 解析ACME并保留未建模字段
+func (a *ACME) UnmarshalJSON(data []byte) error {
+	type plain ACME
+	return unmarshalWithExtra(data, (*plain)(a), &a.Extra)
+}
+
+// MarshalJSON 序列化ACME并写回未建模字段
+func (a ACME) MarshalJSON() ([]byte, error) {
+	type plain ACME
+	return marshalWithExtra(plain(a), a.Extra)
+}
+
+// UnmarshalJSON 解析ExternalAccount并保留未建模字段
+func (e *ExternalAccount) UnmarshalJSON(data []byte) error {
+	type plain ExternalAccount
+	return unmarshalWithExtra(data, (*plain)(e), &e.Extra)
+}
+
+// MarshalJSON 序列化ExternalAccount并写回未建模字段
+func (e ExternalAccount) MarshalJSON() ([]byte, error) {
+	type plain ExternalAccount
+	return marshalWithExtra(plain(e), e.Extra)
+}
+
+// UnmarshalJSON 解析Transport并保留未建模字段
+func (t *Transport) UnmarshalJSON(data []byte) error {
+	type plain Transport
+	return unmarshalWithExtra(data, (*plain)(t), &t.Extra)
+}
+
+// MarshalJSON 序列化Transport并写回未建模字段
+func (t Transport) MarshalJSON() ([]byte, error) {
+	type plain Transport
+	return marshalWithExtra(plain(t), t.Extra)
+}
+
+// UnmarshalJSON 解析Outbound并保留未建模字段
+func (o *Outbound) UnmarshalJSON(data []byte) error {
+	type plain Outbound
+	return unmarshalWithExtra(data, (*plain)(o), &o.Extra)
+}
+
+// MarshalJSON 序列化Outbound并写回未建模字段
+func (o Outbound) MarshalJSON() ([]byte, error) {
+	type plain Outbound
+	return marshalWithExtra(plain(o), o.Extra)
+}
+
+// UnmarshalJSON 解析MultiplexConfig并保留未建模字段
+func (m *MultiplexConfig) UnmarshalJSON(data []byte) error {
+	type plain MultiplexConfig
+	return unmarshalWithExtra(data, (*plain)(m), &m.Extra)
+}
+
+// MarshalJSON 序列化MultiplexConfig并写回未建模字段
+func (m MultiplexConfig) MarshalJSON() ([]byte, error) {
+	type plain MultiplexConfig
+	return marshalWithExtra(plain(m), m.Extra)
+}
+
+// UnmarshalJSON 解析Brutal并保留未建模字段
+func (b *Brutal) UnmarshalJSON(data []byte) error {
+	type plain Brutal
+	return unmarshalWithExtra(data, (*plain)(b), &b.Extra)
+}
+
+// MarshalJSON 序列化Brutal并写回未建模字段
+func (b Brutal) MarshalJSON() ([]byte, error) {
+	type plain Brutal
+	return marshalWithExtra(plain(b), b.Extra)
+}
+
+// UnmarshalJSON 解析OutboundTLS并保留未建模字段
+func (o *OutboundTLS) UnmarshalJSON(data []byte) error {
+	type plain OutboundTLS
+	return unmarshalWithExtra(data, (*plain)(o), &o.Extra)
+}
+
+// MarshalJSON 序列化OutboundTLS并写回未建模字段
+func (o OutboundTLS) MarshalJSON() ([]byte, error) {
+	type plain OutboundTLS
+	return marshalWithExtra(plain(o), o.Extra)
+}
+
+// UnmarshalJSON 解析ECHConfig并保留未建模字段
+func (e *ECHConfig) UnmarshalJSON(data []byte) error {
+	type plain ECHConfig
+	return unmarshalWithExtra(data, (*plain)(e), &e.Extra)
+}
+
+// MarshalJSON 序列化ECHConfig并写回未建模字段
+func (e ECHConfig) MarshalJSON() ([]byte, error) {
+	type plain ECHConfig
+	return marshalWithExtra(plain(e), e.Extra)
+}
+
+// UnmarshalJSON 解析UTLSConfig并保留未建模字段
+func (u *UTLSConfig) UnmarshalJSON(data []byte) error {
+	type plain UTLSConfig
+	return unmarshalWithExtra(data, (*plain)(u), &u.Extra)
+}
+
+// MarshalJSON 序列化UTLSConfig并写回未建模字段
+func (u UTLSConfig) MarshalJSON() ([]byte, error) {
+	type plain UTLSConfig
+	return marshalWithExtra(plain(u), u.Extra)
+}
+
+// UnmarshalJSON 解析RealityConfig并保留未建模字段
+func (r *RealityConfig) UnmarshalJSON(data []byte) error {
+	type plain RealityConfig
+	return unmarshalWithExtra(data, (*plain)(r), &r.Extra)
+}
+
+// MarshalJSON 序列化RealityConfig并写回未建模字段
+func (r RealityConfig) MarshalJSON() ([]byte, error) {
+	type plain RealityConfig
+	return marshalWithExtra(plain(r), r.Extra)
+}
+
+// UnmarshalJSON 解析RouteConfig并保留未建模字段
+func (r *RouteConfig) UnmarshalJSON(data []byte) error {
+	type plain RouteConfig
+	return unmarshalWithExtra(data, (*plain)(r), &r.Extra)
+}
+
+// MarshalJSON 序列化RouteConfig并写回未建模字段
+func (r RouteConfig) MarshalJSON() ([]byte, error) {
+	type plain RouteConfig
+	return marshalWithExtra(plain(r), r.Extra)
+}
+
+// UnmarshalJSON 解析GeoIPConfig并保留未建模字段
+func (g *GeoIPConfig) UnmarshalJSON(data []byte) error {
+	type plain GeoIPConfig
+	return unmarshalWithExtra(data, (*plain)(g), &g.Extra)
+}
+
+// MarshalJSON 序列化GeoIPConfig并写回未建模字段
+func (g GeoIPConfig) MarshalJSON() ([]byte, error) {
+	type plain GeoIPConfig
+	return marshalWithExtra(plain(g), g.Extra)
+}
+
+// UnmarshalJSON 解析GeositeConfig并保留未建模字段
+func (g *GeositeConfig) UnmarshalJSON(data []byte) error {
+	type plain GeositeConfig
+	return unmarshalWithExtra(data, (*plain)(g), &g.Extra)
+}
+
+// MarshalJSON 序列化GeositeConfig并写回未建模字段
+func (g GeositeConfig) MarshalJSON() ([]byte, error) {
+	type plain GeositeConfig
+	return marshalWithExtra(plain(g), g.Extra)
+}
+
+// UnmarshalJSON 解析RouteRule并保留未建模字段
+func (r *RouteRule) UnmarshalJSON(data []byte) error {
+	type plain RouteRule
+	return unmarshalWithExtra(data, (*plain)(r), &r.Extra)
+}
+
+// MarshalJSON 序列化RouteRule并写回未建模字段
+func (r RouteRule) MarshalJSON() ([]byte, error) {
+	type plain RouteRule
+	return marshalWithExtra(plain(r), r.Extra)
+}
+
+// UnmarshalJSON 解析ExperimentalConfig并保留未建模字段
+func (e *ExperimentalConfig) UnmarshalJSON(data []byte) error {
+	type plain ExperimentalConfig
+	return unmarshalWithExtra(data, (*plain)(e), &e.Extra)
+}
+
+// MarshalJSON 序列化ExperimentalConfig并写回未建模字段
+func (e ExperimentalConfig) MarshalJSON() ([]byte, error) {
+	type plain ExperimentalConfig
+	return marshalWithExtra(plain(e), e.Extra)
+}
+
+// UnmarshalJSON 解析CacheFileConfig并保留未建模字段
+func (c *CacheFileConfig) UnmarshalJSON(data []byte) error {
+	type plain CacheFileConfig
+	return unmarshalWithExtra(data, (*plain)(c), &c.Extra)
+}
+
+// MarshalJSON 序列化CacheFileConfig并写回未建模字段
+func (c CacheFileConfig) MarshalJSON() ([]byte, error) {
+	type plain CacheFileConfig
+	return marshalWithExtra(plain(c), c.Extra)
+}
+
+// UnmarshalJSON 解析ClashAPIConfig并保留未建模字段
+func (c *ClashAPIConfig) UnmarshalJSON(data []byte) error {
+	type plain ClashAPIConfig
+	return unmarshalWithExtra(data, (*plain)(c), &c.Extra)
+}
+
+// MarshalJSON 序列化ClashAPIConfig并写回未建模字段
+func (c ClashAPIConfig) MarshalJSON() ([]byte, error) {
+	type plain ClashAPIConfig
+	return marshalWithExtra(plain(c), c.Extra)
+}
+
+// UnmarshalJSON 解析V2RayAPIConfig并保留未建模字段
+func (v *V2RayAPIConfig) UnmarshalJSON(data []byte) error {
+	type plain V2RayAPIConfig
+	return unmarshalWithExtra(data, (*plain)(v), &v.Extra)
+}
+
+// MarshalJSON 序列化V2RayAPIConfig并写回未建模字段
+func (v V2RayAPIConfig) MarshalJSON() ([]byte, error) {
+	type plain V2RayAPIConfig
+	return marshalWithExtra(plain(v), v.Extra)
+}
+
+// UnmarshalJSON 解析V2RayAPIStatsConfig并保留未建模字段
+func (v *V2RayAPIStatsConfig) UnmarshalJSON(data []byte) error {
+	type plain V2RayAPIStatsConfig
+	return unmarshalWithExtra(data, (*plain)(v), &v.Extra)
+}
+
+// MarshalJSON 序列化V2RayAPIStatsConfig并写回未建模字段
+func (v V2RayAPIStatsConfig) MarshalJSON() ([]byte, error) {
+	type plain V2RayAPIStatsConfig
+	return marshalWithExtra(plain(v), v.Extra)
+}
+
+// UnmarshalJSON 解析DebugConfig并保留未建模字段
+func (d *DebugConfig) UnmarshalJSON(data []byte) error {
+	type plain DebugConfig
+	return unmarshalWithExtra(data, (*plain)(d), &d.Extra)
+}
+
+// MarshalJSON 序列化DebugConfig并写回未建模字段
+func (d DebugConfig) MarshalJSON() ([]byte, error) {
+	type plain DebugConfig
+	return marshalWithExtra(plain(d), d.Extra)
+}
+
+// UnmarshalJSON 解析NTPConfig并保留未建模字段
+func (n *NTPConfig) UnmarshalJSON(data []byte) error {
+	type plain NTPConfig
+	return unmarshalWithExtra(data, (*plain)(n), &n.Extra)
+}
+
+// MarshalJSON 序列化NTPConfig并写回未建模字段
+func (n NTPConfig) MarshalJSON() ([]byte, error) {
+	type plain NTPConfig
+	return marshalWithExtra(plain(n), n.Extra)
+}
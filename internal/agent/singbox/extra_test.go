@@ -0,0 +1,265 @@
+package singbox
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestConfigRoundTrip(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+	}{
+		{
+			name: "各层级的未建模字段",
+			config: `{
+				"log": {"level": "info", "x_log": 1},
+				"dns": {
+					"servers": [{"tag": "local", "address": "223.5.5.5", "x_server": {"a": 1}}],
+					"rules": [{"server": "local", "x_rule": [1, 2]}],
+					"fakeip": {"enabled": true, "inet4_range": "198.18.0.0/15", "x_fakeip": "v"},
+					"x_dns": true
+				},
+				"inbounds": [{
+					"type": "vless", "tag": "vless-in", "listen_port": 443,
+					"users": [{"name": "alice", "uuid": "bf000d23-0752-40b4-affe-68f7707a9661", "x_user": "v"}],
+					"tls": {
+						"enabled": true,
+						"reality": {"enabled": true, "private_key": "k", "handshake": {"server": "example.com", "server_port": 443, "x_handshake": 1}, "x_reality": 1},
+						"acme": {"domain": ["example.com"], "external_account": {"key_id": "id", "x_eab": 1}, "x_acme": 1},
+						"x_tls": 1
+					},
+					"transport": {"type": "ws", "path": "/ws", "x_transport": 1},
+					"multiplex": {"enabled": true, "brutal": {"enabled": true, "up_mbps": 10, "x_brutal": 1}, "x_mux": 1},
+					"x_inbound": 1
+				}],
+				"outbounds": [{
+					"type": "vless", "tag": "proxy", "server": "example.com", "server_port": 443,
+					"tls": {"enabled": true, "utls": {"enabled": true, "fingerprint": "chrome", "x_utls": 1}, "reality": {"enabled": true, "public_key": "p", "x_reality": 1}, "ech": {"enabled": true, "x_ech": 1}, "x_tls": 1},
+					"multiplex": {"enabled": true, "brutal": {"enabled": true, "x_brutal": 1}, "x_mux": 1},
+					"x_outbound": 1
+				}],
+				"route": {
+					"geoip": {"path": "geoip.db", "x_geoip": 1},
+					"geosite": {"path": "geosite.db", "x_geosite": 1},
+					"rules": [{"outbound": "proxy", "x_rule": 1}],
+					"rule_set": [{"tag": "cn", "type": "local", "path": "cn.srs"}],
+					"final": "proxy"
+				},
+				"experimental": {
+					"cache_file": {"enabled": true, "x_cache": 1},
+					"clash_api": {"external_controller": "127.0.0.1:9090", "x_clash": 1},
+					"v2ray_api": {"listen": "127.0.0.1:8080", "stats": {"enabled": true, "x_stats": 1}, "x_v2ray": 1},
+					"debug": {"gc_percent": 50, "x_debug": 1},
+					"x_experimental": 1
+				},
+				"ntp": {"enabled": true, "server": "time.apple.com", "x_ntp": 1},
+				"endpoints": [{"type": "wireguard", "tag": "wg"}],
+				"services": []
+			}`,
+		},
+		{
+			name:   "匿名嵌入结构的字段与其他字段交错",
+			config: `{"outbounds": [{"detour": "direct", "type": "vless", "bind_interface": "eth0", "tag": "proxy", "connect_timeout": "5s", "x": 1}]}`,
+		},
+		{
+			name:   "显式零值",
+			config: `{"inbounds": [{"type": "mixed", "tag": "", "listen_port": 0, "sniff": false, "users": [], "tls": null, "udp_timeout": ""}]}`,
+		},
+		{
+			name:   "类型不符的已建模字段",
+			config: `{"route": {"rules": [{"ip_cidr": "10.0.0.0/8", "domain": "example.com", "outbound": "direct"}]}}`,
+		},
+		{
+			name:   "非ASCII与嵌套数组",
+			config: `{"outbounds": [{"type": "selector", "tag": "节点选择", "outbounds": ["香港", "direct"], "x_nested": [[1, {"b": [true]}]]}]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var want bytes.Buffer
+			if err := json.Compact(&want, []byte(tt.config)); err != nil {
+				t.Fatalf("测试配置无效: %v", err)
+			}
+
+			var config Config
+			if err := json.Unmarshal([]byte(tt.config), &config); err != nil {
+				t.Fatalf("Unmarshal() error = %v", err)
+			}
+			got, err := json.Marshal(&config)
+			if err != nil {
+				t.Fatalf("Marshal() error = %v", err)
+			}
+			if !bytes.Equal(got, want.Bytes()) {
+				t.Errorf("往返结果不一致\n got: %s\nwant: %s", got, want.Bytes())
+			}
+
+			// 再次往返结果不变
+			var again Config
+			if err := json.Unmarshal(got, &again); err != nil {
+				t.Fatalf("第二次Unmarshal() error = %v", err)
+			}
+			if second, _ := json.Marshal(&again); !bytes.Equal(second, got) {
+				t.Errorf("第二次往返结果不一致\n got: %s\nwant: %s", second, got)
+			}
+		})
+	}
+}
+
+func TestConfigModify(t *testing.T) {
+	data := `{"x_first":1,"listen_port":443,"type":"vless","tag":"in","users":[{"x_user":true,"name":"alice","uuid":"u1"}],"sniff":false}`
+	var inbound Inbound
+	if err := json.Unmarshal([]byte(data), &inbound); err != nil {
+		t.Fatal(err)
+	}
+
+	// 修改已建模字段后，未修改的键保持原顺序，新增的已建模字段排在后面
+	inbound.ListenPort = 8443
+	inbound.Users[0].UUID = "u2"
+	inbound.Users = append(inbound.Users, InboundUser{Name: "bob", UUID: "u3"})
+	inbound.Listen = "::"
+	inbound.Extra.Delete("x_first")
+
+	got, err := json.Marshal(&inbound)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"listen_port":8443,"type":"vless","tag":"in","users":[{"x_user":true,"name":"alice","uuid":"u2"},{"name":"bob","uuid":"u3"}],"sniff":false,"listen":"::"}`
+	if string(got) != want {
+		t.Errorf("Marshal() =\n%s\nwant\n%s", got, want)
+	}
+
+	// 清空的已建模字段被省略
+	inbound.Listen = ""
+	inbound.Users = nil
+	got, err = json.Marshal(&inbound)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = `{"listen_port":8443,"type":"vless","tag":"in","sniff":false}`
+	if string(got) != want {
+		t.Errorf("Marshal() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestRawFields(t *testing.T) {
+	var inbound Inbound
+	if err := json.Unmarshal([]byte(`{"type":"tun","tag":"tun-in","x":1}`), &inbound); err != nil {
+		t.Fatal(err)
+	}
+	if value, ok := inbound.Extra.Get("x"); !ok || string(value) != "1" {
+		t.Errorf("Get(x) = %s, %v", value, ok)
+	}
+	if _, ok := inbound.Extra.Get("type"); ok {
+		t.Error("已建模字段不应出现在Extra中")
+	}
+
+	inbound.Extra.Set("auto_route", json.RawMessage("true"))
+	inbound.Extra.Set("x", json.RawMessage(`"changed"`))
+	got, err := json.Marshal(&inbound)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"type":"tun","tag":"tun-in","x":"changed","auto_route":true}`; string(got) != want {
+		t.Errorf("Marshal() = %s, want %s", got, want)
+	}
+
+	// 未经解析构造的结构不带Extra
+	got, err = json.Marshal(&InboundUser{Name: "alice", Password: "p"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"name":"alice","password":"p"}`; string(got) != want {
+		t.Errorf("Marshal() = %s, want %s", got, want)
+	}
+
+	// null与非对象
+	var user InboundUser
+	if err := json.Unmarshal([]byte("null"), &user); err != nil || !reflect.DeepEqual(user, InboundUser{}) {
+		t.Errorf("Unmarshal(null) = %+v, %v", user, err)
+	}
+	if err := json.Unmarshal([]byte(`["a"]`), &user); err == nil {
+		t.Error("Unmarshal(数组) 应返回错误")
+	}
+}
+
+func TestKnownFields(t *testing.T) {
+	fields := knownFields(reflect.TypeOf(Outbound{}))
+	for _, name := range []string{"type", "tag", "tls", "detour", "bind_interface", "fallback_delay"} {
+		if !fields[name] {
+			t.Errorf("knownFields(Outbound) 缺少 %s", name)
+		}
+	}
+	for _, name := range []string{"Extra", "DialerOptions", "-"} {
+		if fields[name] {
+			t.Errorf("knownFields(Outbound) 不应包含 %s", name)
+		}
+	}
+}
+
+func TestIsZeroJSON(t *testing.T) {
+	tests := []struct {
+		value string
+		want  bool
+	}{
+		{"false", true},
+		{"0", true},
+		{`""`, true},
+		{"null", true},
+		{"[]", true},
+		{"{}", true},
+		{" 0 ", true},
+		{"0.0", false},
+		{"true", false},
+		{`"0"`, false},
+		{"[0]", false},
+		{`{"a":0}`, false},
+	}
+	for _, tt := range tests {
+		if got := isZeroJSON(json.RawMessage(tt.value)); got != tt.want {
+			t.Errorf("isZeroJSON(%s) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestRawFieldsObject(t *testing.T) {
+	data := `{"name":"alice","x_limit":{"up":10},"uuid":"u1","flow":"","x_tag":"vip"}`
+	var user InboundUser
+	if err := json.Unmarshal([]byte(data), &user); err != nil {
+		t.Fatal(err)
+	}
+
+	object, err := user.Extra.MarshalObject()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"x_limit":{"up":10},"flow":"","x_tag":"vip"}`; string(object) != want {
+		t.Errorf("MarshalObject() = %s, want %s", object, want)
+	}
+
+	// 经对象形式传递后重建的用户与原配置一致
+	extra, err := ParseRawFields(object)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rebuilt := InboundUser{Name: user.Name, UUID: user.UUID, Extra: extra}
+	got, err := json.Marshal(&rebuilt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"x_limit":{"up":10},"flow":"","x_tag":"vip","name":"alice","uuid":"u1"}`; string(got) != want {
+		t.Errorf("Marshal() = %s, want %s", got, want)
+	}
+
+	if object, _ := (RawFields{}).MarshalObject(); string(object) != "{}" {
+		t.Errorf("空字段 MarshalObject() = %s", object)
+	}
+	for _, invalid := range []string{"", "[]", `"x"`, "{"} {
+		if _, err := ParseRawFields([]byte(invalid)); err == nil {
+			t.Errorf("ParseRawFields(%q) 应返回错误", invalid)
+		}
+	}
+}
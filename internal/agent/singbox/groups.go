@@ -0,0 +1,224 @@
+package singbox
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// 出站组操作类型
+const (
+	GroupOpCreate = "create" // 创建出站组
+	GroupOpUpdate = "update" // 修改出站组
+	GroupOpDelete = "delete" // 删除出站组
+)
+
+// 出站组成员操作类型
+const (
+	MemberOpAdd     = "add"     // 追加成员
+	MemberOpRemove  = "remove"  // 删除成员
+	MemberOpReplace = "replace" // 替换全部成员
+)
+
+// IsGroupOutbound 判断出站类型是否为selector或urltest组
+func IsGroupOutbound(outboundType string) bool {
+	return groupOutboundTypes[outboundType]
+}
+
+// ValidateGroup 校验出站组自身的字段，成员出站是否存在在应用配置时由Validate检查
+func ValidateGroup(group Outbound) error {
+	if group.Tag == "" {
+		return fmt.Errorf("出站组缺少tag")
+	}
+	if !groupOutboundTypes[group.Type] {
+		return fmt.Errorf("出站组 %s 的类型无效: %s", group.Tag, group.Type)
+	}
+	if len(group.Outbounds) == 0 {
+		return fmt.Errorf("出站组 %s 缺少成员", group.Tag)
+	}
+
+	seen := make(map[string]bool, len(group.Outbounds))
+	for _, member := range group.Outbounds {
+		if member == "" {
+			return fmt.Errorf("出站组 %s 的成员tag不能为空", group.Tag)
+		}
+		if member == group.Tag {
+			return fmt.Errorf("出站组 %s 不能包含自身", group.Tag)
+		}
+		if seen[member] {
+			return fmt.Errorf("出站组 %s 的成员重复: %s", group.Tag, member)
+		}
+		seen[member] = true
+	}
+
+	switch group.Type {
+	case "selector":
+		if group.Default != "" && !seen[group.Default] {
+			return fmt.Errorf("出站组 %s 的默认出站 %s 不在成员列表中", group.Tag, group.Default)
+		}
+		if group.URL != "" || group.Interval != "" || group.Tolerance != 0 || group.IdleTimeout != "" {
+			return fmt.Errorf("selector出站组 %s 不支持url、interval、tolerance和idle_timeout", group.Tag)
+		}
+	case "urltest":
+		if group.Default != "" {
+			return fmt.Errorf("urltest出站组 %s 不支持default", group.Tag)
+		}
+		if group.URL != "" {
+			u, err := url.Parse(group.URL)
+			if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				return fmt.Errorf("出站组 %s 的测试地址无效: %s", group.Tag, group.URL)
+			}
+		}
+		if group.Interval != "" {
+			if d, err := time.ParseDuration(group.Interval); err != nil || d <= 0 {
+				return fmt.Errorf("出站组 %s 的测试间隔无效: %s", group.Tag, group.Interval)
+			}
+		}
+		if group.IdleTimeout != "" {
+			if d, err := time.ParseDuration(group.IdleTimeout); err != nil || d <= 0 {
+				return fmt.Errorf("出站组 %s 的空闲超时无效: %s", group.Tag, group.IdleTimeout)
+			}
+		}
+	}
+	return nil
+}
+
+// findOutbound 按tag查找出站，返回其位置
+func findOutbound(config *Config, tag string) (int, error) {
+	for i := range config.Outbounds {
+		if config.Outbounds[i].Tag == tag {
+			return i, nil
+		}
+	}
+	return -1, fmt.Errorf("出站 %s 不存在", tag)
+}
+
+// findGroup 按tag查找出站组，返回其位置
+func findGroup(config *Config, tag string) (int, error) {
+	index, err := findOutbound(config, tag)
+	if err != nil {
+		return -1, fmt.Errorf("出站组 %s 不存在", tag)
+	}
+	if !groupOutboundTypes[config.Outbounds[index].Type] {
+		return -1, fmt.Errorf("出站 %s 不是出站组(类型: %s)", tag, config.Outbounds[index].Type)
+	}
+	return index, nil
+}
+
+// GetOutboundGroups 获取全部selector/urltest出站组，按配置顺序排列
+func (m *Manager) GetOutboundGroups() ([]Outbound, error) {
+	config := m.GetConfig()
+	if config == nil {
+		return nil, fmt.Errorf("sing-box配置未加载")
+	}
+
+	var groups []Outbound
+	for _, outbound := range config.Outbounds {
+		if groupOutboundTypes[outbound.Type] {
+			groups = append(groups, outbound)
+		}
+	}
+	return groups, nil
+}
+
+// UpdateOutboundGroup 创建、修改或删除出站组并应用配置，其余配置保持不变。
+// 删除仍被路由规则或其他出站引用的组时，配置校验失败且不会应用
+func (m *Manager) UpdateOutboundGroup(operation string, group Outbound) (*ApplyResult, error) {
+	config := m.GetConfig()
+	if config == nil {
+		return nil, fmt.Errorf("sing-box配置未加载")
+	}
+
+	switch operation {
+	case GroupOpCreate:
+		if err := ValidateGroup(group); err != nil {
+			return nil, err
+		}
+		if _, err := findOutbound(config, group.Tag); err == nil {
+			return nil, fmt.Errorf("出站 %s 已存在", group.Tag)
+		}
+		config.Outbounds = append(config.Outbounds, group)
+	case GroupOpUpdate:
+		if err := ValidateGroup(group); err != nil {
+			return nil, err
+		}
+		index, err := findGroup(config, group.Tag)
+		if err != nil {
+			return nil, err
+		}
+		config.Outbounds[index] = group
+	case GroupOpDelete:
+		index, err := findGroup(config, group.Tag)
+		if err != nil {
+			return nil, err
+		}
+		config.Outbounds = append(config.Outbounds[:index], config.Outbounds[index+1:]...)
+	default:
+		return nil, fmt.Errorf("不支持的出站组操作: %s", operation)
+	}
+
+	opts := DefaultApplyOptions()
+	opts.Source = "group:" + group.Tag
+	result := m.ApplyConfig(config, opts)
+	return result, result.Err()
+}
+
+// UpdateGroupMembers 增删或替换出站组成员并应用配置。删除selector的默认成员时同时清除default
+func (m *Manager) UpdateGroupMembers(tag, operation string, members []string) (*ApplyResult, error) {
+	config := m.GetConfig()
+	if config == nil {
+		return nil, fmt.Errorf("sing-box配置未加载")
+	}
+
+	index, err := findGroup(config, tag)
+	if err != nil {
+		return nil, err
+	}
+	group := config.Outbounds[index]
+
+	switch operation {
+	case MemberOpAdd:
+		if len(members) == 0 {
+			return nil, fmt.Errorf("成员列表不能为空")
+		}
+		group.Outbounds = append(append([]string(nil), group.Outbounds...), members...)
+	case MemberOpRemove:
+		if len(members) == 0 {
+			return nil, fmt.Errorf("成员列表不能为空")
+		}
+		remove := make(map[string]bool, len(members))
+		for _, member := range members {
+			if !containsString(group.Outbounds, member) {
+				return nil, fmt.Errorf("出站组 %s 不包含成员 %s", tag, member)
+			}
+			remove[member] = true
+		}
+		kept := make([]string, 0, len(group.Outbounds))
+		for _, member := range group.Outbounds {
+			if !remove[member] {
+				kept = append(kept, member)
+			}
+		}
+		group.Outbounds = kept
+		if remove[group.Default] {
+			group.Default = ""
+		}
+	case MemberOpReplace:
+		group.Outbounds = append([]string(nil), members...)
+		if !containsString(group.Outbounds, group.Default) {
+			group.Default = ""
+		}
+	default:
+		return nil, fmt.Errorf("不支持的成员操作: %s", operation)
+	}
+
+	if err := ValidateGroup(group); err != nil {
+		return nil, err
+	}
+	config.Outbounds[index] = group
+
+	opts := DefaultApplyOptions()
+	opts.Source = "group-members:" + tag
+	result := m.ApplyConfig(config, opts)
+	return result, result.Err()
+}
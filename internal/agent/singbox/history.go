@@ -0,0 +1,401 @@
+package singbox
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultHistoryLimit = 20           // 默认保留的配置代数
+	historyIndexFile    = "index.json" // 代索引文件名
+	diffContextLines    = 3            // diff上下文行数
+	maxDiffCells        = 4000000      // LCS矩阵规模上限，超出时按整体替换输出
+)
+
+// Generation 一代已生效的sing-box配置
+type Generation struct {
+	Version   int64     `json:"version"`
+	AppliedAt time.Time `json:"applied_at"`
+	Source    string    `json:"source"` // 配置来源，如 grpc、filter、multiplex、rollback:3
+	SHA256    string    `json:"sha256"`
+	Size      int64     `json:"size"`
+}
+
+// History 磁盘上的多代配置存储
+type History struct {
+	mu     sync.Mutex
+	dir    string
+	limit  int
+	index  []Generation
+	loaded bool
+}
+
+// NewHistory 创建配置历史存储
+func NewHistory(dir string, limit int) *History {
+	if limit <= 0 {
+		limit = defaultHistoryLimit
+	}
+	return &History{
+		dir:   dir,
+		limit: limit,
+	}
+}
+
+// SetLimit 设置保留的配置代数
+func (h *History) SetLimit(limit int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if limit > 0 {
+		h.limit = limit
+	}
+}
+
+// Record 记录一代新配置并返回其元数据
+func (h *History) Record(data []byte, source string) (Generation, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if err := h.loadLocked(); err != nil {
+		return Generation{}, err
+	}
+	// 配置代包含用户凭据和私钥，目录和文件仅所有者可访问
+	if err := os.MkdirAll(h.dir, 0700); err != nil {
+		return Generation{}, fmt.Errorf("创建配置历史目录失败: %v", err)
+	}
+	if err := os.Chmod(h.dir, 0700); err != nil {
+		return Generation{}, fmt.Errorf("设置配置历史目录权限失败: %v", err)
+	}
+
+	var version int64 = 1
+	if len(h.index) > 0 {
+		version = h.index[len(h.index)-1].Version + 1
+	}
+
+	sum := sha256.Sum256(data)
+	gen := Generation{
+		Version:   version,
+		AppliedAt: time.Now(),
+		Source:    source,
+		SHA256:    hex.EncodeToString(sum[:]),
+		Size:      int64(len(data)),
+	}
+
+	if err := writeFileSync(h.generationPath(version), data, 0600); err != nil {
+		return Generation{}, fmt.Errorf("写入配置代失败: %v", err)
+	}
+
+	index := append(h.index, gen)
+	var pruned []Generation
+	if len(index) > h.limit {
+		pruned = index[:len(index)-h.limit]
+		index = index[len(index)-h.limit:]
+	}
+	if err := h.saveIndex(index); err != nil {
+		return Generation{}, err
+	}
+	h.index = index
+
+	for _, old := range pruned {
+		os.Remove(h.generationPath(old.Version))
+	}
+
+	return gen, nil
+}
+
+// List 按版本升序返回所有配置代
+func (h *History) List() ([]Generation, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if err := h.loadLocked(); err != nil {
+		return nil, err
+	}
+	return append([]Generation(nil), h.index...), nil
+}
+
+// Latest 返回最新一代配置
+func (h *History) Latest() (Generation, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if err := h.loadLocked(); err != nil || len(h.index) == 0 {
+		return Generation{}, false
+	}
+	return h.index[len(h.index)-1], true
+}
+
+// Get 读取指定版本的配置内容，并校验sha256
+func (h *History) Get(version int64) (Generation, []byte, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if err := h.loadLocked(); err != nil {
+		return Generation{}, nil, err
+	}
+
+	for _, gen := range h.index {
+		if gen.Version != version {
+			continue
+		}
+		data, err := os.ReadFile(h.generationPath(version))
+		if err != nil {
+			return Generation{}, nil, fmt.Errorf("读取配置代 %d 失败: %v", version, err)
+		}
+		sum := sha256.Sum256(data)
+		if hex.EncodeToString(sum[:]) != gen.SHA256 {
+			return Generation{}, nil, fmt.Errorf("配置代 %d 校验和不匹配", version)
+		}
+		return gen, data, nil
+	}
+
+	return Generation{}, nil, fmt.Errorf("配置代 %d 不存在", version)
+}
+
+// Previous 返回最新一代之前的版本号
+func (h *History) Previous() (int64, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if err := h.loadLocked(); err != nil {
+		return 0, err
+	}
+	if len(h.index) < 2 {
+		return 0, fmt.Errorf("没有可回滚的上一代配置")
+	}
+	return h.index[len(h.index)-2].Version, nil
+}
+
+// Diff 生成两代配置之间的unified diff
+func (h *History) Diff(fromVersion, toVersion int64) (string, error) {
+	_, from, err := h.Get(fromVersion)
+	if err != nil {
+		return "", err
+	}
+	_, to, err := h.Get(toVersion)
+	if err != nil {
+		return "", err
+	}
+
+	return unifiedDiff(
+		fmt.Sprintf("generation/%d", fromVersion), string(from),
+		fmt.Sprintf("generation/%d", toVersion), string(to),
+	), nil
+}
+
+// loadLocked 首次访问时加载索引，调用方需持有h.mu
+func (h *History) loadLocked() error {
+	if h.loaded {
+		return nil
+	}
+
+	data, err := os.ReadFile(filepath.Join(h.dir, historyIndexFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			h.loaded = true
+			return nil
+		}
+		return fmt.Errorf("读取配置历史索引失败: %v", err)
+	}
+
+	var index []Generation
+	if err := json.Unmarshal(data, &index); err != nil {
+		return fmt.Errorf("解析配置历史索引失败: %v", err)
+	}
+
+	h.index = index
+	h.loaded = true
+	return nil
+}
+
+// saveIndex 原子写入索引文件
+func (h *History) saveIndex(index []Generation) error {
+	data, err := json.MarshalIndent(index, "", "  ")
+	if err != nil {
+		return fmt.Errorf("序列化配置历史索引失败: %v", err)
+	}
+
+	path := filepath.Join(h.dir, historyIndexFile)
+	tmpPath := path + ".tmp"
+	if err := writeFileSync(tmpPath, data, 0600); err != nil {
+		return fmt.Errorf("写入配置历史索引失败: %v", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return fmt.Errorf("替换配置历史索引失败: %v", err)
+	}
+	return nil
+}
+
+// generationPath 配置代文件路径
+func (h *History) generationPath(version int64) string {
+	return filepath.Join(h.dir, fmt.Sprintf("%d.json", version))
+}
+
+// diffOp 行级diff操作
+type diffOp struct {
+	kind byte // ' ' 相同, '-' 删除, '+' 新增
+	line string
+}
+
+// unifiedDiff 生成带上下文的unified diff文本，内容相同时返回空字符串
+func unifiedDiff(fromName, from, toName, to string) string {
+	if from == to {
+		return ""
+	}
+
+	ops := diffLines(splitLines(from), splitLines(to))
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "--- %s\n+++ %s\n", fromName, toName)
+
+	// 按上下文行数将变更分组为hunk
+	for i := 0; i < len(ops); {
+		if ops[i].kind == ' ' {
+			i++
+			continue
+		}
+
+		start := i - diffContextLines
+		if start < 0 {
+			start = 0
+		}
+		end := i
+		for end < len(ops) {
+			if ops[end].kind != ' ' {
+				end++
+				continue
+			}
+			// 连续相同行超过两倍上下文时结束当前hunk
+			run := end
+			for run < len(ops) && ops[run].kind == ' ' {
+				run++
+			}
+			if run == len(ops) || run-end > 2*diffContextLines {
+				end += diffContextLines
+				if end > run {
+					end = run
+				}
+				break
+			}
+			end = run
+		}
+
+		fromStart, toStart := 1, 1
+		for _, op := range ops[:start] {
+			if op.kind != '+' {
+				fromStart++
+			}
+			if op.kind != '-' {
+				toStart++
+			}
+		}
+		fromCount, toCount := 0, 0
+		for _, op := range ops[start:end] {
+			if op.kind != '+' {
+				fromCount++
+			}
+			if op.kind != '-' {
+				toCount++
+			}
+		}
+
+		fmt.Fprintf(&b, "@@ -%d,%d +%d,%d @@\n", fromStart, fromCount, toStart, toCount)
+		for _, op := range ops[start:end] {
+			b.WriteByte(op.kind)
+			b.WriteString(op.line)
+			b.WriteByte('\n')
+		}
+		i = end
+	}
+
+	return b.String()
+}
+
+// diffLines 基于最长公共子序列计算行级差异
+func diffLines(a, b []string) []diffOp {
+	// 去掉公共前缀和后缀以缩小LCS规模
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix &&
+		a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	ops := make([]diffOp, 0, len(a)+len(b))
+	for _, line := range a[:prefix] {
+		ops = append(ops, diffOp{' ', line})
+	}
+
+	midA := a[prefix : len(a)-suffix]
+	midB := b[prefix : len(b)-suffix]
+	n, m := len(midA), len(midB)
+
+	if n*m > maxDiffCells {
+		for _, line := range midA {
+			ops = append(ops, diffOp{'-', line})
+		}
+		for _, line := range midB {
+			ops = append(ops, diffOp{'+', line})
+		}
+	} else {
+		lcs := make([][]int, n+1)
+		for i := range lcs {
+			lcs[i] = make([]int, m+1)
+		}
+		for i := n - 1; i >= 0; i-- {
+			for j := m - 1; j >= 0; j-- {
+				if midA[i] == midB[j] {
+					lcs[i][j] = lcs[i+1][j+1] + 1
+				} else if lcs[i+1][j] >= lcs[i][j+1] {
+					lcs[i][j] = lcs[i+1][j]
+				} else {
+					lcs[i][j] = lcs[i][j+1]
+				}
+			}
+		}
+
+		i, j := 0, 0
+		for i < n && j < m {
+			switch {
+			case midA[i] == midB[j]:
+				ops = append(ops, diffOp{' ', midA[i]})
+				i++
+				j++
+			case lcs[i+1][j] >= lcs[i][j+1]:
+				ops = append(ops, diffOp{'-', midA[i]})
+				i++
+			default:
+				ops = append(ops, diffOp{'+', midB[j]})
+				j++
+			}
+		}
+		for ; i < n; i++ {
+			ops = append(ops, diffOp{'-', midA[i]})
+		}
+		for ; j < m; j++ {
+			ops = append(ops, diffOp{'+', midB[j]})
+		}
+	}
+
+	for _, line := range a[len(a)-suffix:] {
+		ops = append(ops, diffOp{' ', line})
+	}
+	return ops
+}
+
+// splitLines 按行拆分文本
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
@@ -0,0 +1,150 @@
+package singbox
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// numberedLines 生成内容为1..n的多行文本，replace中的行号替换为指定内容
+func numberedLines(n int, replace map[int]string) string {
+	var b strings.Builder
+	for i := 1; i <= n; i++ {
+		line, ok := replace[i]
+		if !ok {
+			line = fmt.Sprint(i)
+		}
+		b.WriteString(line)
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+func TestUnifiedDiff(t *testing.T) {
+	tests := []struct {
+		name string
+		from string
+		to   string
+		want string
+	}{
+		{
+			name: "内容相同",
+			from: numberedLines(5, nil),
+			to:   numberedLines(5, nil),
+			want: "",
+		},
+		{
+			name: "修改一行带上下文",
+			from: numberedLines(10, nil),
+			to:   numberedLines(10, map[int]string{5: "five"}),
+			want: "--- a\n+++ b\n@@ -2,7 +2,7 @@\n 2\n 3\n 4\n-5\n+five\n 6\n 7\n 8\n",
+		},
+		{
+			name: "开头新增一行",
+			from: "b\nc\n",
+			to:   "a\nb\nc\n",
+			want: "--- a\n+++ b\n@@ -1,2 +1,3 @@\n+a\n b\n c\n",
+		},
+		{
+			name: "末尾删除一行",
+			from: numberedLines(6, nil),
+			to:   numberedLines(5, nil),
+			want: "--- a\n+++ b\n@@ -3,4 +3,3 @@\n 3\n 4\n 5\n-6\n",
+		},
+		{
+			name: "相距较远的修改分为两个hunk",
+			from: numberedLines(20, nil),
+			to:   numberedLines(20, map[int]string{2: "two", 18: "eighteen"}),
+			want: "--- a\n+++ b\n" +
+				"@@ -1,5 +1,5 @@\n 1\n-2\n+two\n 3\n 4\n 5\n" +
+				"@@ -15,6 +15,6 @@\n 15\n 16\n 17\n-18\n+eighteen\n 19\n 20\n",
+		},
+		{
+			name: "相距较近的修改合并为一个hunk",
+			from: numberedLines(12, nil),
+			to:   numberedLines(12, map[int]string{3: "three", 8: "eight"}),
+			want: "--- a\n+++ b\n@@ -1,11 +1,11 @@\n 1\n 2\n-3\n+three\n 4\n 5\n 6\n 7\n-8\n+eight\n 9\n 10\n 11\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := unifiedDiff("a", tt.from, "b", tt.to); got != tt.want {
+				t.Errorf("unifiedDiff() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHistory(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "history")
+	h := NewHistory(dir, 2)
+
+	configs := []string{"{\"v\": 1}\n", "{\"v\": 2}\n", "{\"v\": 3}\n"}
+	for i, data := range configs {
+		gen, err := h.Record([]byte(data), "grpc")
+		if err != nil {
+			t.Fatalf("Record() error = %v", err)
+		}
+		if gen.Version != int64(i+1) || gen.Size != int64(len(data)) {
+			t.Errorf("Record() = %+v", gen)
+		}
+	}
+
+	// 超出保留代数的旧配置被清理
+	gens, err := h.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(gens) != 2 || gens[0].Version != 2 || gens[1].Version != 3 {
+		t.Fatalf("List() = %+v, want 版本2、3", gens)
+	}
+	if _, _, err := h.Get(1); err == nil {
+		t.Error("已清理的配置代仍可读取")
+	}
+	if prev, err := h.Previous(); err != nil || prev != 2 {
+		t.Errorf("Previous() = %d, %v, want 2", prev, err)
+	}
+
+	diff, err := h.Diff(2, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "--- generation/2\n+++ generation/3\n@@ -1,1 +1,1 @@\n-{\"v\": 2}\n+{\"v\": 3}\n"
+	if diff != want {
+		t.Errorf("Diff() = %q, want %q", diff, want)
+	}
+
+	// 配置代包含凭据，仅所有者可访问
+	info, err := os.Stat(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0700 {
+		t.Errorf("目录权限 = %o, want 700", perm)
+	}
+	for _, name := range []string{"2.json", "3.json", historyIndexFile} {
+		info, err := os.Stat(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if perm := info.Mode().Perm(); perm != 0600 {
+			t.Errorf("%s 权限 = %o, want 600", name, perm)
+		}
+	}
+
+	// 被篡改的配置代校验失败
+	if err := os.WriteFile(filepath.Join(dir, "3.json"), []byte("{}"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := h.Get(3); err == nil || !strings.Contains(err.Error(), "校验和不匹配") {
+		t.Errorf("Get() error = %v, want 校验和不匹配", err)
+	}
+
+	// 重新加载索引
+	reloaded := NewHistory(dir, 2)
+	if latest, ok := reloaded.Latest(); !ok || latest.Version != 3 {
+		t.Errorf("Latest() = %+v, %v, want 版本3", latest, ok)
+	}
+}
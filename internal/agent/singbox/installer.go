@@ -1,21 +1,57 @@
 package singbox
 
 import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
 	"os"
 	"os/exec"
+	"path"
 	"path/filepath"
 	"runtime"
 	"strings"
+	"time"
 )
 
+// 制品来源
+const (
+	SourceGitHub = "github" // GitHub Releases
+	SourceMirror = "mirror" // HTTP镜像，如Controller托管的制品目录
+	SourceLocal  = "local"  // 本地目录，用于离线节点
+)
+
+// 来源中的校验和文件及其ed25519签名
+const (
+	checksumFile  = "SHA256SUMS"
+	signatureFile = "SHA256SUMS.sig"
+)
+
+// InstallOptions 版本固定安装参数
+type InstallOptions struct {
+	Version   string // 固定的sing-box版本，如1.11.4
+	Source    string // github、mirror或local
+	MirrorURL string // mirror来源的基础地址
+	LocalDir  string // local来源的目录
+	SHA256    string // 制品sha256，为空时从来源的SHA256SUMS读取
+	PublicKey string // ed25519公钥(base64)，设置后SHA256SUMS必须带有效签名
+}
+
 // Installer sing-box安装器
 type Installer struct {
 	installDir string
 	binaryPath string
+	binaryName string
+	options    InstallOptions
+	version    string // 当前二进制的版本
+	checksum   string // 当前二进制的sha256
+	httpClient *http.Client
 }
 
 // NewInstaller 创建安装器
@@ -28,157 +64,344 @@ func NewInstaller(installDir string) *Installer {
 	return &Installer{
 		installDir: installDir,
 		binaryPath: filepath.Join(installDir, binaryName),
+		binaryName: binaryName,
+		options:    InstallOptions{Source: SourceGitHub},
+		httpClient: &http.Client{Timeout: 5 * time.Minute},
+	}
+}
+
+// SetOptions 设置版本固定安装参数
+func (i *Installer) SetOptions(opts InstallOptions) {
+	opts.Version = strings.TrimPrefix(strings.TrimSpace(opts.Version), "v")
+	opts.SHA256 = strings.ToLower(strings.TrimSpace(opts.SHA256))
+	if opts.Source == "" {
+		opts.Source = SourceGitHub
 	}
+	i.options = opts
+}
+
+// PinnedVersion 返回固定的sing-box版本，未固定时为空
+func (i *Installer) PinnedVersion() string {
+	return i.options.Version
 }
 
-// Check 检查sing-box是否已安装
+// Check 检查sing-box是否已安装，同时计算二进制的sha256
 func (i *Installer) Check() (bool, string, error) {
 	// 检查指定路径的二进制文件
 	if stat, err := os.Stat(i.binaryPath); err == nil && !stat.IsDir() {
-		version, err := i.getVersion(i.binaryPath)
-		if err != nil {
-			log.Printf("获取sing-box版本失败: %v", err)
-			return true, "unknown", nil
-		}
-		return true, version, nil
+		return true, i.inspect(i.binaryPath), nil
 	}
 	
 	// 检查系统PATH中的sing-box
 	if path, err := exec.LookPath("sing-box"); err == nil {
-		version, err := i.getVersion(path)
-		if err != nil {
-			log.Printf("获取系统sing-box版本失败: %v", err)
-			return true, "unknown", nil
-		}
 		// 如果系统中存在sing-box，更新二进制路径
 		i.binaryPath = path
-		return true, version, nil
+		return true, i.inspect(path), nil
 	}
 	
 	return false, "", nil
 }
 
-// Install 安装sing-box
-func (i *Installer) Install() error {
-	log.Println("开始安装sing-box...")
-	
-	// 创建安装目录
-	if err := os.MkdirAll(i.installDir, 0755); err != nil {
-		return fmt.Errorf("创建安装目录失败: %v", err)
+// inspect 读取二进制版本和sha256
+func (i *Installer) inspect(binaryPath string) string {
+	if sum, err := fileSHA256(binaryPath); err == nil {
+		i.checksum = sum
+	} else {
+		log.Printf("计算sing-box校验和失败: %v", err)
+		i.checksum = ""
 	}
 	
-	// 使用官方安装脚本
-	if err := i.installWithScript(); err != nil {
-		log.Printf("使用安装脚本失败: %v", err)
-		// 尝试手动下载安装
-		return i.installManually()
+	version, err := i.getVersion(binaryPath)
+	if err != nil {
+		log.Printf("获取sing-box版本失败: %v", err)
+		version = "unknown"
 	}
-	
+	i.version = version
+	return version
+}
+
+// Install 安装固定版本的sing-box，制品校验通过后替换安装目录中的二进制
+func (i *Installer) Install() error {
+	target := filepath.Join(i.installDir, i.binaryName)
+	if err := i.install(i.options, target); err != nil {
+		return err
+	}
+	i.binaryPath = target
 	return nil
 }
 
-// GetBinaryPath 获取二进制文件路径
-func (i *Installer) GetBinaryPath() string {
-	return i.binaryPath
+// InstallSideBySide 将指定版本安装到 versions/<版本>/ 下，不影响当前二进制，返回新二进制路径。
+// checksum为空时，固定版本使用配置中的sha256，其他版本读取来源中的SHA256SUMS
+func (i *Installer) InstallSideBySide(version, checksum string) (string, error) {
+	opts := i.options
+	opts.Version = strings.TrimPrefix(strings.TrimSpace(version), "v")
+	opts.SHA256 = strings.ToLower(strings.TrimSpace(checksum))
+	if opts.SHA256 == "" && opts.Version == i.options.Version {
+		opts.SHA256 = i.options.SHA256
+	}
+	if opts.Version == "" {
+		return "", fmt.Errorf("未指定要安装的sing-box版本")
+	}
+	
+	target := filepath.Join(i.installDir, "versions", opts.Version, i.binaryName)
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return "", fmt.Errorf("创建版本目录失败: %v", err)
+	}
+	if err := i.install(opts, target); err != nil {
+		return "", err
+	}
+	return target, nil
 }
 
-// installWithScript 使用官方安装脚本安装
-func (i *Installer) installWithScript() error {
-	log.Println("使用官方安装脚本安装sing-box...")
+// install 下载并校验指定版本的制品，解压后原子替换到target
+func (i *Installer) install(opts InstallOptions, target string) error {
+	version := opts.Version
+	if version == "" {
+		return fmt.Errorf("未指定要安装的sing-box版本")
+	}
+	
+	arch := i.getArch()
+	if arch == "" {
+		return fmt.Errorf("不支持的系统架构: %s/%s", runtime.GOOS, runtime.GOARCH)
+	}
+	artifact := fmt.Sprintf("sing-box-%s-%s-%s.tar.gz", version, runtime.GOOS, arch)
+	log.Printf("开始安装sing-box %s (来源: %s, 制品: %s)", version, opts.Source, artifact)
 	
-	// 下载安装脚本
-	resp, err := http.Get("https://sing-box.app/install.sh")
+	// 创建安装目录
+	dir := filepath.Dir(target)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("创建安装目录失败: %v", err)
+	}
+	
+	expected, err := i.expectedChecksum(opts, artifact)
 	if err != nil {
-		return fmt.Errorf("下载安装脚本失败: %v", err)
+		return err
 	}
-	defer resp.Body.Close()
 	
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("下载安装脚本失败: HTTP %d", resp.StatusCode)
+	// 下载制品到安装目录下的临时文件，边下载边计算sha256
+	tmpArchive, err := os.CreateTemp(dir, ".sing-box-*.tar.gz")
+	if err != nil {
+		return fmt.Errorf("创建临时文件失败: %v", err)
 	}
+	defer os.Remove(tmpArchive.Name())
+	defer tmpArchive.Close()
 	
-	// 创建临时脚本文件
-	tmpScript := filepath.Join(os.TempDir(), "install-sing-box.sh")
-	file, err := os.Create(tmpScript)
+	body, err := i.fetch(opts, artifact)
+	if err != nil {
+		return err
+	}
+	hash := sha256.New()
+	_, err = io.Copy(io.MultiWriter(tmpArchive, hash), body)
+	body.Close()
 	if err != nil {
-		return fmt.Errorf("创建临时脚本失败: %v", err)
+		return fmt.Errorf("下载制品失败: %v", err)
+	}
+	
+	if actual := hex.EncodeToString(hash.Sum(nil)); actual != expected {
+		return fmt.Errorf("制品校验失败: 期望sha256=%s, 实际sha256=%s", expected, actual)
+	}
+	log.Printf("制品sha256校验通过: %s", expected)
+	
+	if _, err := tmpArchive.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("读取制品失败: %v", err)
 	}
-	defer os.Remove(tmpScript)
 	
-	// 写入脚本内容
-	if _, err := io.Copy(file, resp.Body); err != nil {
-		file.Close()
-		return fmt.Errorf("写入脚本内容失败: %v", err)
+	// 解压到临时文件后原子替换，避免覆盖过程中留下不完整的二进制
+	tmpBinary, err := os.CreateTemp(dir, ".sing-box-*")
+	if err != nil {
+		return fmt.Errorf("创建临时文件失败: %v", err)
 	}
-	file.Close()
+	defer os.Remove(tmpBinary.Name())
 	
-	// 设置执行权限
-	if err := os.Chmod(tmpScript, 0755); err != nil {
-		return fmt.Errorf("设置脚本权限失败: %v", err)
+	err = extractBinary(tmpArchive, i.binaryName, tmpBinary)
+	tmpBinary.Close()
+	if err != nil {
+		return fmt.Errorf("解压制品失败: %v", err)
+	}
+	if err := os.Chmod(tmpBinary.Name(), 0755); err != nil {
+		return fmt.Errorf("设置执行权限失败: %v", err)
 	}
 	
-	// 执行安装脚本
-	cmd := exec.Command("/bin/sh", tmpScript)
-	cmd.Env = append(os.Environ(), fmt.Sprintf("PREFIX=%s", i.installDir))
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
+	// 校验解压出的二进制版本与固定版本一致
+	installedVersion, err := i.getVersion(tmpBinary.Name())
+	if err != nil {
+		return fmt.Errorf("新二进制无法运行: %v", err)
+	}
+	if installedVersion != version {
+		return fmt.Errorf("制品版本不匹配: 期望%s, 实际%s", version, installedVersion)
+	}
 	
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("执行安装脚本失败: %v", err)
+	if err := os.Rename(tmpBinary.Name(), target); err != nil {
+		return fmt.Errorf("替换sing-box二进制失败: %v", err)
 	}
 	
-	log.Println("sing-box安装完成")
+	log.Printf("sing-box %s 安装完成: %s", version, target)
 	return nil
 }
 
-// installManually 手动下载安装
-func (i *Installer) installManually() error {
-	log.Println("尝试手动下载安装sing-box...")
-	
-	// 获取系统架构信息
-	arch := i.getArch()
-	if arch == "" {
-		return fmt.Errorf("不支持的系统架构: %s/%s", runtime.GOOS, runtime.GOARCH)
+// GetBinaryPath 获取二进制文件路径
+func (i *Installer) GetBinaryPath() string {
+	return i.binaryPath
+}
+
+// GetVersion 获取最近一次检查的二进制版本
+func (i *Installer) GetVersion() string {
+	return i.version
+}
+
+// GetChecksum 获取最近一次检查的二进制sha256
+func (i *Installer) GetChecksum() string {
+	return i.checksum
+}
+
+// expectedChecksum 获取制品的期望sha256，优先使用配置中固定的值
+func (i *Installer) expectedChecksum(opts InstallOptions, artifact string) (string, error) {
+	if opts.SHA256 != "" {
+		return opts.SHA256, nil
+	}
+	if opts.Source == SourceGitHub {
+		return "", fmt.Errorf("GitHub来源未提供校验和文件，请配置制品的sha256")
 	}
 	
-	// 构建下载URL（这里使用GitHub releases作为备选）
-	downloadURL := fmt.Sprintf("https://github.com/SagerNet/sing-box/releases/latest/download/sing-box-%s-%s.tar.gz", runtime.GOOS, arch)
+	sums, err := i.readSourceFile(opts, checksumFile)
+	if err != nil {
+		return "", fmt.Errorf("读取%s失败: %v", checksumFile, err)
+	}
 	
-	log.Printf("从 %s 下载sing-box...", downloadURL)
+	if opts.PublicKey != "" {
+		sig, err := i.readSourceFile(opts, signatureFile)
+		if err != nil {
+			return "", fmt.Errorf("读取%s失败: %v", signatureFile, err)
+		}
+		if err := verifySignature(opts.PublicKey, sums, sig); err != nil {
+			return "", err
+		}
+		log.Printf("%s签名校验通过", checksumFile)
+	}
 	
-	// 下载文件
-	resp, err := http.Get(downloadURL)
+	for _, line := range strings.Split(string(sums), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 2 && strings.TrimPrefix(fields[1], "*") == artifact {
+			return strings.ToLower(fields[0]), nil
+		}
+	}
+	return "", fmt.Errorf("%s中没有制品 %s 的校验和", checksumFile, artifact)
+}
+
+// fetch 从配置的来源读取制品
+func (i *Installer) fetch(opts InstallOptions, artifact string) (io.ReadCloser, error) {
+	switch opts.Source {
+	case SourceGitHub:
+		return i.httpGet(fmt.Sprintf("https://github.com/SagerNet/sing-box/releases/download/v%s/%s", opts.Version, artifact))
+	case SourceMirror, SourceLocal:
+		return i.openSourceFile(opts, artifact)
+	default:
+		return nil, fmt.Errorf("不支持的制品来源: %s", opts.Source)
+	}
+}
+
+// readSourceFile 读取mirror或local来源中的小文件
+func (i *Installer) readSourceFile(opts InstallOptions, name string) ([]byte, error) {
+	body, err := i.openSourceFile(opts, name)
 	if err != nil {
-		return fmt.Errorf("下载失败: %v", err)
+		return nil, err
+	}
+	defer body.Close()
+	return io.ReadAll(io.LimitReader(body, 1<<20))
+}
+
+// openSourceFile 打开mirror或local来源中的文件
+func (i *Installer) openSourceFile(opts InstallOptions, name string) (io.ReadCloser, error) {
+	switch opts.Source {
+	case SourceMirror:
+		if opts.MirrorURL == "" {
+			return nil, fmt.Errorf("未配置镜像地址")
+		}
+		return i.httpGet(strings.TrimRight(opts.MirrorURL, "/") + "/" + name)
+	case SourceLocal:
+		if opts.LocalDir == "" {
+			return nil, fmt.Errorf("未配置本地制品目录")
+		}
+		file, err := os.Open(filepath.Join(opts.LocalDir, name))
+		if err != nil {
+			return nil, fmt.Errorf("打开本地制品失败: %v", err)
+		}
+		return file, nil
+	default:
+		return nil, fmt.Errorf("来源 %s 不支持读取 %s", opts.Source, name)
+	}
+}
+
+// httpGet 下载文件，非200响应视为失败
+func (i *Installer) httpGet(url string) (io.ReadCloser, error) {
+	log.Printf("从 %s 下载...", url)
+	resp, err := i.httpClient.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("下载失败: %v", err)
 	}
-	defer resp.Body.Close()
-	
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("下载失败: HTTP %d", resp.StatusCode)
+		resp.Body.Close()
+		return nil, fmt.Errorf("下载 %s 失败: HTTP %d", url, resp.StatusCode)
 	}
-	
-	// 创建临时文件
-	tmpFile := filepath.Join(os.TempDir(), "sing-box.tar.gz")
-	file, err := os.Create(tmpFile)
+	return resp.Body, nil
+}
+
+// verifySignature 使用ed25519公钥校验签名，签名可以是原始字节或base64
+func verifySignature(publicKey string, message, sig []byte) error {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(publicKey))
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		return fmt.Errorf("ed25519公钥无效")
+	}
+	if len(sig) != ed25519.SignatureSize {
+		decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(sig)))
+		if err != nil {
+			return fmt.Errorf("%s格式无效", signatureFile)
+		}
+		sig = decoded
+	}
+	if !ed25519.Verify(ed25519.PublicKey(key), message, sig) {
+		return fmt.Errorf("%s签名校验失败", checksumFile)
+	}
+	return nil
+}
+
+// extractBinary 从tar.gz制品中提取sing-box二进制
+func extractBinary(archive io.Reader, binaryName string, dst io.Writer) error {
+	gz, err := gzip.NewReader(archive)
 	if err != nil {
-		return fmt.Errorf("创建临时文件失败: %v", err)
+		return err
 	}
-	defer os.Remove(tmpFile)
-	defer file.Close()
+	defer gz.Close()
 	
-	// 保存下载内容
-	if _, err := io.Copy(file, resp.Body); err != nil {
-		return fmt.Errorf("保存文件失败: %v", err)
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return fmt.Errorf("制品中没有%s", binaryName)
+		}
+		if err != nil {
+			return err
+		}
+		if header.Typeflag == tar.TypeReg && path.Base(header.Name) == binaryName {
+			_, err = io.Copy(dst, tr)
+			return err
+		}
 	}
-	file.Close()
-	
-	// 解压文件
-	if err := i.extractTarGz(tmpFile, i.installDir); err != nil {
-		return fmt.Errorf("解压文件失败: %v", err)
+}
+
+// fileSHA256 计算文件sha256
+func fileSHA256(filePath string) (string, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return "", err
 	}
+	defer file.Close()
 	
-	log.Println("sing-box手动安装完成")
-	return nil
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
 }
 
 // getVersion 获取sing-box版本
@@ -218,10 +441,3 @@ func (i *Installer) getArch() string {
 		return ""
 	}
 }
-
-// extractTarGz 解压tar.gz文件
-func (i *Installer) extractTarGz(src, dest string) error {
-	// 使用系统命令解压（简化实现）
-	cmd := exec.Command("tar", "-xzf", src, "-C", dest, "--strip-components=1")
-	return cmd.Run()
-}
\ No newline at end of file
@@ -0,0 +1,254 @@
+package singbox
+
+import (
+	"io"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultLogBufferSize = 1000 // 默认保留的日志行数
+	logLineLimit         = 4096 // 单行日志最大长度
+	logSubscriberBuffer  = 256  // 订阅者通道容量
+)
+
+// 日志级别，按严重程度升序
+var logLevels = []string{"trace", "debug", "info", "warn", "error", "fatal", "panic"}
+
+// ansiPattern 匹配终端颜色控制符
+var ansiPattern = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+
+// LogEntry 一行sing-box日志
+type LogEntry struct {
+	Seq     uint64    `json:"seq"`
+	Time    time.Time `json:"time"`
+	Level   string    `json:"level"`
+	Message string    `json:"message"`
+	Stream  string    `json:"stream"` // stdout、stderr 或 journal（systemd托管时）
+}
+
+// LogFilter 日志过滤条件
+type LogFilter struct {
+	MinLevel string // 最低级别，为空时不过滤
+	Keyword  string // 关键字（不区分大小写），为空时不过滤
+}
+
+// Match 判断日志是否满足过滤条件
+func (f LogFilter) Match(entry LogEntry) bool {
+	if f.MinLevel != "" && levelRank(entry.Level) < levelRank(f.MinLevel) {
+		return false
+	}
+	if f.Keyword != "" && !strings.Contains(strings.ToLower(entry.Message), strings.ToLower(f.Keyword)) {
+		return false
+	}
+	return true
+}
+
+// logSubscriber 日志订阅者
+type logSubscriber struct {
+	filter LogFilter
+	ch     chan LogEntry
+}
+
+// LogBuffer sing-box日志环形缓冲区
+type LogBuffer struct {
+	mu          sync.Mutex
+	entries     []LogEntry
+	size        int
+	next        int // 下一次写入位置
+	full        bool
+	seq         uint64
+	subscribers map[*logSubscriber]struct{}
+}
+
+// NewLogBuffer 创建日志缓冲区
+func NewLogBuffer(size int) *LogBuffer {
+	if size <= 0 {
+		size = defaultLogBufferSize
+	}
+	return &LogBuffer{
+		entries:     make([]LogEntry, size),
+		size:        size,
+		subscribers: make(map[*logSubscriber]struct{}),
+	}
+}
+
+// Writer 返回写入指定输出流的io.Writer
+func (b *LogBuffer) Writer(stream string) io.Writer {
+	return &logWriter{buffer: b, stream: stream}
+}
+
+// Append 追加一行日志并分发给订阅者
+func (b *LogBuffer) Append(stream, line string) {
+	line = ansiPattern.ReplaceAllString(line, "")
+	if len(line) > logLineLimit {
+		line = line[:logLineLimit]
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.seq++
+	entry := LogEntry{
+		Seq:     b.seq,
+		Time:    time.Now(),
+		Level:   parseLogLevel(line),
+		Message: line,
+		Stream:  stream,
+	}
+
+	b.entries[b.next] = entry
+	b.next = (b.next + 1) % b.size
+	if b.next == 0 {
+		b.full = true
+	}
+
+	for sub := range b.subscribers {
+		if !sub.filter.Match(entry) {
+			continue
+		}
+		select {
+		case sub.ch <- entry:
+		default:
+			// 订阅者消费过慢时丢弃，避免阻塞sing-box输出
+		}
+	}
+}
+
+// Tail 返回最近n行满足过滤条件的日志，n<=0时返回全部
+func (b *LogBuffer) Tail(n int, filter LogFilter) []LogEntry {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.tailLocked(n, filter)
+}
+
+// Subscribe 订阅新日志，同时返回订阅时刻之前最近tail行日志，保证两者之间不丢不重
+func (b *LogBuffer) Subscribe(tail int, filter LogFilter) ([]LogEntry, <-chan LogEntry, func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var backlog []LogEntry
+	if tail > 0 {
+		backlog = b.tailLocked(tail, filter)
+	}
+
+	sub := &logSubscriber{
+		filter: filter,
+		ch:     make(chan LogEntry, logSubscriberBuffer),
+	}
+	b.subscribers[sub] = struct{}{}
+
+	var once sync.Once
+	cancel := func() {
+		once.Do(func() {
+			b.mu.Lock()
+			delete(b.subscribers, sub)
+			b.mu.Unlock()
+			close(sub.ch)
+		})
+	}
+
+	return backlog, sub.ch, cancel
+}
+
+// tailLocked 调用方需持有b.mu
+func (b *LogBuffer) tailLocked(n int, filter LogFilter) []LogEntry {
+	count := b.next
+	start := 0
+	if b.full {
+		count = b.size
+		start = b.next
+	}
+
+	var result []LogEntry
+	for i := count - 1; i >= 0; i-- {
+		entry := b.entries[(start+i)%b.size]
+		if !filter.Match(entry) {
+			continue
+		}
+		result = append(result, entry)
+		if n > 0 && len(result) >= n {
+			break
+		}
+	}
+
+	// 反转为时间升序
+	for i, j := 0, len(result)-1; i < j; i, j = i+1, j-1 {
+		result[i], result[j] = result[j], result[i]
+	}
+	return result
+}
+
+// logWriter 按行写入LogBuffer的io.Writer
+type logWriter struct {
+	buffer  *LogBuffer
+	stream  string
+	mu      sync.Mutex
+	partial string
+}
+
+// Write 实现io.Writer接口
+func (w *logWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	data := w.partial + string(p)
+	parts := strings.Split(data, "\n")
+	w.partial = parts[len(parts)-1]
+
+	for _, line := range parts[:len(parts)-1] {
+		line = strings.TrimRight(line, "\r")
+		if line == "" {
+			continue
+		}
+		w.buffer.Append(w.stream, line)
+	}
+
+	// 超长且未换行的内容直接作为一行写入
+	if len(w.partial) > logLineLimit {
+		w.buffer.Append(w.stream, w.partial)
+		w.partial = ""
+	}
+
+	return len(p), nil
+}
+
+// parseLogLevel 从sing-box日志行中解析级别，兼容
+// "+0800 2024-01-15 10:35:00 INFO [...] ..." 与 "FATAL[0000] ..." 两种格式
+func parseLogLevel(line string) string {
+	fields := strings.Fields(line)
+	if len(fields) > 6 {
+		fields = fields[:6]
+	}
+	for _, field := range fields {
+		if idx := strings.IndexByte(field, '['); idx > 0 {
+			field = field[:idx]
+		}
+		level := strings.ToLower(strings.Trim(field, "[]:"))
+		if level == "warning" {
+			level = "warn"
+		}
+		if levelRank(level) >= 0 {
+			return level
+		}
+	}
+	return "info"
+}
+
+// ValidLogLevel 判断是否为支持的日志级别
+func ValidLogLevel(level string) bool {
+	return levelRank(level) >= 0
+}
+
+// levelRank 返回日志级别的严重程度，未知级别返回-1
+func levelRank(level string) int {
+	level = strings.ToLower(level)
+	for i, l := range logLevels {
+		if l == level {
+			return i
+		}
+	}
+	return -1
+}
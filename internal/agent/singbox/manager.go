@@ -3,9 +3,11 @@ package singbox
 import (
 	"encoding/json"
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"os/exec"
+	"strings"
 	"sync"
 	"syscall"
 	"time"
@@ -19,6 +21,18 @@ type Manager struct {
 	binaryPath  string
 	running     bool
 	lastConfig  *Config
+	startedAt   time.Time
+	exited      chan struct{} // 当前进程退出时关闭
+	sup         supervisor
+	applyMu     sync.Mutex // 串行化配置应用流水线
+	usersMu     sync.Mutex // 串行化入站用户的读改写，保证修订检查与应用之间用户不被其他更新修改
+	history     *History   // 多代配置历史
+	logs        *LogBuffer // sing-box输出日志
+	clashAPIListen string  // 自动注入的本地Clash API监听地址
+	v2rayAPIListen string  // 自动启用的V2Ray API统计服务监听地址
+	probeListen    string  // 自动注入的出站探测入站监听地址
+	unit        *systemdUnit // systemd托管时的unit，exec模式下为nil
+	cg          *cgroup      // 独立cgroup，未启用资源限制时为nil
 }
 
 // Config sing-box配置结构
@@ -31,6 +45,8 @@ type Config struct {
 	Route       *RouteConfig       `json:"route,omitempty"`
 	Experimental *ExperimentalConfig `json:"experimental,omitempty"`
 	NTP         *NTPConfig         `json:"ntp,omitempty"`
+
+	Extra RawFields `json:"-"` // 未建模的字段，序列化时原样写回
 }
 
 // LogConfig 日志配置
@@ -39,6 +55,8 @@ type LogConfig struct {
 	Level     string `json:"level,omitempty"`
 	Output    string `json:"output,omitempty"`
 	Timestamp bool   `json:"timestamp,omitempty"`
+
+	Extra RawFields `json:"-"` // 未建模的字段，序列化时原样写回
 }
 
 // DNSConfig DNS配置
@@ -52,6 +70,8 @@ type DNSConfig struct {
 	IndependentCache bool         `json:"independent_cache,omitempty"`
 	ReverseMapping bool           `json:"reverse_mapping,omitempty"`
 	FakeIP         *FakeIPConfig  `json:"fakeip,omitempty"`
+
+	Extra RawFields `json:"-"` // 未建模的字段，序列化时原样写回
 }
 
 // DNSServer DNS服务器配置
@@ -63,6 +83,8 @@ type DNSServer struct {
 	Strategy           string   `json:"strategy,omitempty"`
 	Detour             string   `json:"detour,omitempty"`
 	ClientSubnet       string   `json:"client_subnet,omitempty"`
+
+	Extra RawFields `json:"-"` // 未建模的字段，序列化时原样写回
 }
 
 // DNSRule DNS规则
@@ -95,6 +117,8 @@ type DNSRule struct {
 	DisableCache bool   `json:"disable_cache,omitempty"`
 	RewriteTTL *uint32  `json:"rewrite_ttl,omitempty"`
 	ClientSubnet string `json:"client_subnet,omitempty"`
+
+	Extra RawFields `json:"-"` // 未建模的字段，序列化时原样写回
 }
 
 // FakeIPConfig FakeIP配置
@@ -102,6 +126,8 @@ type FakeIPConfig struct {
 	Enabled    bool     `json:"enabled,omitempty"`
 	Inet4Range string   `json:"inet4_range,omitempty"`
 	Inet6Range string   `json:"inet6_range,omitempty"`
+
+	Extra RawFields `json:"-"` // 未建模的字段，序列化时原样写回
 }
 
 // Inbound 入站配置
@@ -121,20 +147,51 @@ type Inbound struct {
 	DomainStrategy  string         `json:"domain_strategy,omitempty"`
 	UDPDisableDomainUnmapping bool `json:"udp_disable_domain_unmapping,omitempty"`
 	
-	// Mixed/HTTP/SOCKS specific
+	// 用户列表（Mixed/HTTP/SOCKS/VMess/VLESS/Trojan/Shadowsocks/Hysteria2/TUIC）
 	Users     []InboundUser `json:"users,omitempty"`
 	
+	// Shadowsocks specific
+	Method    string        `json:"method,omitempty"`
+	Password  string        `json:"password,omitempty"`
+	Network   string        `json:"network,omitempty"`
+	
+	// Hysteria2 specific
+	UpMbps                int            `json:"up_mbps,omitempty"`
+	DownMbps              int            `json:"down_mbps,omitempty"`
+	Obfs                  *Hysteria2Obfs `json:"obfs,omitempty"`
+	IgnoreClientBandwidth bool           `json:"ignore_client_bandwidth,omitempty"`
+	Masquerade            string         `json:"masquerade,omitempty"`
+	
+	// TUIC specific
+	CongestionControl string `json:"congestion_control,omitempty"`
+	AuthTimeout       string `json:"auth_timeout,omitempty"`
+	ZeroRTTHandshake  bool   `json:"zero_rtt_handshake,omitempty"`
+	Heartbeat         string `json:"heartbeat,omitempty"`
+	
+	// Multiplex（VMess/VLESS/Trojan/Shadowsocks）
+	Multiplex *InboundMultiplex `json:"multiplex,omitempty"`
+	
 	// TLS config
 	TLS       *InboundTLS   `json:"tls,omitempty"`
 	
 	// Transport config
 	Transport *Transport    `json:"transport,omitempty"`
+
+	Extra RawFields `json:"-"` // 未建模的字段，序列化时原样写回
 }
 
-// InboundUser 入站用户配置
+// InboundUser 入站用户配置，不同协议使用的字段不同：
+// mixed/http/socks: username+password; vmess: name+uuid+alterId; vless: name+uuid+flow;
+// trojan/shadowsocks/hysteria2: name+password; tuic: name+uuid+password
 type InboundUser struct {
+	Name     string `json:"name,omitempty"`
 	Username string `json:"username,omitempty"`
 	Password string `json:"password,omitempty"`
+	UUID     string `json:"uuid,omitempty"`
+	Flow     string `json:"flow,omitempty"`
+	AlterID  int    `json:"alterId,omitempty"`
+
+	Extra RawFields `json:"-"` // 未建模的字段，序列化时原样写回
 }
 
 // InboundTLS 入站TLS配置
@@ -151,6 +208,54 @@ type InboundTLS struct {
 	Key             []string `json:"key,omitempty"`
 	KeyPath         string   `json:"key_path,omitempty"`
 	ACME            *ACME    `json:"acme,omitempty"`
+	Reality         *InboundReality `json:"reality,omitempty"`
+
+	Extra RawFields `json:"-"` // 未建模的字段，序列化时原样写回
+}
+
+// InboundReality 入站Reality服务端配置
+type InboundReality struct {
+	Enabled           bool              `json:"enabled,omitempty"`
+	Handshake         *RealityHandshake `json:"handshake,omitempty"`
+	PrivateKey        string            `json:"private_key,omitempty"`
+	ShortID           []string          `json:"short_id,omitempty"`
+	MaxTimeDifference string            `json:"max_time_difference,omitempty"`
+
+	Extra RawFields `json:"-"` // 未建模的字段，序列化时原样写回
+}
+
+// RealityHandshake Reality握手目标服务器
+type RealityHandshake struct {
+	Server     string `json:"server,omitempty"`
+	ServerPort uint16 `json:"server_port,omitempty"`
+
+	Extra RawFields `json:"-"` // 未建模的字段，序列化时原样写回
+}
+
+// Hysteria2Obfs Hysteria2混淆配置
+type Hysteria2Obfs struct {
+	Type     string `json:"type,omitempty"`
+	Password string `json:"password,omitempty"`
+
+	Extra RawFields `json:"-"` // 未建模的字段，序列化时原样写回
+}
+
+// InboundMultiplex 入站多路复用配置
+type InboundMultiplex struct {
+	Enabled bool           `json:"enabled,omitempty"`
+	Padding bool           `json:"padding,omitempty"`
+	Brutal  *InboundBrutal `json:"brutal,omitempty"`
+
+	Extra RawFields `json:"-"` // 未建模的字段，序列化时原样写回
+}
+
+// InboundBrutal 入站TCP Brutal配置
+type InboundBrutal struct {
+	Enabled  bool `json:"enabled,omitempty"`
+	UpMbps   int  `json:"up_mbps,omitempty"`
+	DownMbps int  `json:"down_mbps,omitempty"`
+
+	Extra RawFields `json:"-"` // 未建模的字段，序列化时原样写回
 }
 
 // ACME 自动证书配置
@@ -165,12 +270,16 @@ type ACME struct {
 	AlternativeHTTPPort   uint16   `json:"alternative_http_port,omitempty"`
 	AlternativeTLSPort    uint16   `json:"alternative_tls_port,omitempty"`
 	ExternalAccount       *ExternalAccount `json:"external_account,omitempty"`
+
+	Extra RawFields `json:"-"` // 未建模的字段，序列化时原样写回
 }
 
 // ExternalAccount 外部账户配置
 type ExternalAccount struct {
 	KeyID  string `json:"key_id,omitempty"`
 	MACKey string `json:"mac_key,omitempty"`
+
+	Extra RawFields `json:"-"` // 未建模的字段，序列化时原样写回
 }
 
 // Transport 传输层配置
@@ -186,6 +295,8 @@ type Transport struct {
 	MaxEarlyData     uint32            `json:"max_early_data,omitempty"`
 	EarlyDataHeaderName string         `json:"early_data_header_name,omitempty"`
 	ServiceName      string            `json:"service_name,omitempty"`
+
+	Extra RawFields `json:"-"` // 未建模的字段，序列化时原样写回
 }
 
 // Outbound 出站配置
@@ -253,8 +364,19 @@ type Outbound struct {
 	ReceiveWindow  uint64           `json:"recv_window,omitempty"`
 	DisableMTUDiscovery bool        `json:"disable_mtu_discovery,omitempty"`
 	
+	// Selector / URLTest specific
+	Outbounds      []string         `json:"outbounds,omitempty"`
+	Default        string           `json:"default,omitempty"`
+	URL            string           `json:"url,omitempty"`
+	Interval       string           `json:"interval,omitempty"`
+	Tolerance      uint16           `json:"tolerance,omitempty"`
+	IdleTimeout    string           `json:"idle_timeout,omitempty"`
+	InterruptExistConnections bool  `json:"interrupt_exist_connections,omitempty"`
+	
 	// Common outbound fields
 	DialerOptions
+
+	Extra RawFields `json:"-"` // 未建模的字段，序列化时原样写回
 }
 
 // MultiplexConfig 多路复用配置
@@ -266,6 +388,8 @@ type MultiplexConfig struct {
 	MaxStreams     int      `json:"max_streams,omitempty"`
 	Padding        bool     `json:"padding,omitempty"`
 	Brutal         *Brutal  `json:"brutal,omitempty"`
+
+	Extra RawFields `json:"-"` // 未建模的字段，序列化时原样写回
 }
 
 // Brutal Brutal配置
@@ -273,6 +397,8 @@ type Brutal struct {
 	Enabled bool   `json:"enabled,omitempty"`
 	Up      string `json:"up,omitempty"`
 	Down    string `json:"down,omitempty"`
+
+	Extra RawFields `json:"-"` // 未建模的字段，序列化时原样写回
 }
 
 // OutboundTLS 出站TLS配置
@@ -290,6 +416,8 @@ type OutboundTLS struct {
 	ECH                  *ECHConfig `json:"ech,omitempty"`
 	UTLS                 *UTLSConfig `json:"utls,omitempty"`
 	Reality              *RealityConfig `json:"reality,omitempty"`
+
+	Extra RawFields `json:"-"` // 未建模的字段，序列化时原样写回
 }
 
 // ECHConfig ECH配置
@@ -298,12 +426,16 @@ type ECHConfig struct {
 	PQSignatureSchemesEnabled bool `json:"pq_signature_schemes_enabled,omitempty"`
 	DynamicRecordSizingDisabled bool `json:"dynamic_record_sizing_disabled,omitempty"`
 	Config                 []string `json:"config,omitempty"`
+
+	Extra RawFields `json:"-"` // 未建模的字段，序列化时原样写回
 }
 
 // UTLSConfig uTLS配置
 type UTLSConfig struct {
 	Enabled     bool   `json:"enabled,omitempty"`
 	Fingerprint string `json:"fingerprint,omitempty"`
+
+	Extra RawFields `json:"-"` // 未建模的字段，序列化时原样写回
 }
 
 // RealityConfig Reality配置
@@ -311,6 +443,8 @@ type RealityConfig struct {
 	Enabled   bool   `json:"enabled,omitempty"`
 	PublicKey string `json:"public_key,omitempty"`
 	ShortID   string `json:"short_id,omitempty"`
+
+	Extra RawFields `json:"-"` // 未建模的字段，序列化时原样写回
 }
 
 // DialerOptions 拨号器选项
@@ -341,6 +475,8 @@ type RouteConfig struct {
 	OverrideAndroidVPN  bool            `json:"override_android_vpn,omitempty"`
 	DefaultInterface    string          `json:"default_interface,omitempty"`
 	DefaultMark         int             `json:"default_mark,omitempty"`
+
+	Extra RawFields `json:"-"` // 未建模的字段，序列化时原样写回
 }
 
 // GeoIPConfig GeoIP配置
@@ -348,6 +484,8 @@ type GeoIPConfig struct {
 	Path           string `json:"path,omitempty"`
 	DownloadURL    string `json:"download_url,omitempty"`
 	DownloadDetour string `json:"download_detour,omitempty"`
+
+	Extra RawFields `json:"-"` // 未建模的字段，序列化时原样写回
 }
 
 // GeositeConfig Geosite配置
@@ -355,6 +493,8 @@ type GeositeConfig struct {
 	Path           string `json:"path,omitempty"`
 	DownloadURL    string `json:"download_url,omitempty"`
 	DownloadDetour string `json:"download_detour,omitempty"`
+
+	Extra RawFields `json:"-"` // 未建模的字段，序列化时原样写回
 }
 
 // RouteRule 路由规则
@@ -392,6 +532,8 @@ type RouteRule struct {
 	RuleSetIPCIDRMatchSource bool `json:"rule_set_ip_cidr_match_source,omitempty"`
 	Invert            bool     `json:"invert,omitempty"`
 	Outbound          string   `json:"outbound,omitempty"`
+
+	Extra RawFields `json:"-"` // 未建模的字段，序列化时原样写回
 }
 
 // ExperimentalConfig 实验性配置
@@ -400,6 +542,8 @@ type ExperimentalConfig struct {
 	ClashAPI           *ClashAPIConfig           `json:"clash_api,omitempty"`
 	V2RayAPI           *V2RayAPIConfig           `json:"v2ray_api,omitempty"`
 	Debug              *DebugConfig              `json:"debug,omitempty"`
+
+	Extra RawFields `json:"-"` // 未建模的字段，序列化时原样写回
 }
 
 // CacheFileConfig 缓存文件配置
@@ -410,6 +554,8 @@ type CacheFileConfig struct {
 	StoreFakeIP bool `json:"store_fakeip,omitempty"`
 	StoreRDRC  bool   `json:"store_rdrc,omitempty"`
 	RDRCTimeout string `json:"rdrc_timeout,omitempty"`
+
+	Extra RawFields `json:"-"` // 未建模的字段，序列化时原样写回
 }
 
 // ClashAPIConfig Clash API配置
@@ -428,12 +574,16 @@ type ClashAPIConfig struct {
 	CacheID            string             `json:"cache_id,omitempty"`
 	AccessControlAllowOrigin []string     `json:"access_control_allow_origin,omitempty"`
 	AccessControlAllowPrivateNetwork bool `json:"access_control_allow_private_network,omitempty"`
+
+	Extra RawFields `json:"-"` // 未建模的字段，序列化时原样写回
 }
 
 // V2RayAPIConfig V2Ray API配置
 type V2RayAPIConfig struct {
 	Listen string                `json:"listen,omitempty"`
 	Stats  *V2RayAPIStatsConfig  `json:"stats,omitempty"`
+
+	Extra RawFields `json:"-"` // 未建模的字段，序列化时原样写回
 }
 
 // V2RayAPIStatsConfig V2Ray API统计配置
@@ -442,6 +592,8 @@ type V2RayAPIStatsConfig struct {
 	Inbounds  []string `json:"inbounds,omitempty"`
 	Outbounds []string `json:"outbounds,omitempty"`
 	Users     []string `json:"users,omitempty"`
+
+	Extra RawFields `json:"-"` // 未建模的字段，序列化时原样写回
 }
 
 // DebugConfig 调试配置
@@ -454,6 +606,8 @@ type DebugConfig struct {
 	TraceBack          string `json:"trace_back,omitempty"`
 	MemoryLimit        string `json:"memory_limit,omitempty"`
 	OOMKiller          bool   `json:"oom_killer,omitempty"`
+
+	Extra RawFields `json:"-"` // 未建模的字段，序列化时原样写回
 }
 
 // NTPConfig NTP配置
@@ -464,6 +618,8 @@ type NTPConfig struct {
 	Interval      string   `json:"interval,omitempty"`
 	WriteToSystem bool     `json:"write_to_system,omitempty"`
 	Detour        string   `json:"detour,omitempty"`
+
+	Extra RawFields `json:"-"` // 未建模的字段，序列化时原样写回
 }
 
 // NewManager 创建sing-box管理器
@@ -472,11 +628,42 @@ func NewManager(binaryPath, configPath string) *Manager {
 		binaryPath: binaryPath,
 		configPath: configPath,
 		running:    false,
+		history:    NewHistory(configPath+".history", defaultHistoryLimit),
+		logs:       NewLogBuffer(defaultLogBufferSize),
+		sup: supervisor{
+			policy: DefaultRestartPolicy(),
+			state:  StateStopped,
+		},
 	}
 }
 
-// Start 启动sing-box进程
+// Logs 获取sing-box日志缓冲区
+func (m *Manager) Logs() *LogBuffer {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.logs
+}
+
+// SetLogBufferSize 设置日志缓冲区保留的行数，需在Start之前调用
+func (m *Manager) SetLogBufferSize(size int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.logs = NewLogBuffer(size)
+}
+
+// SetRestartPolicy 设置崩溃重启策略
+func (m *Manager) SetRestartPolicy(policy RestartPolicy) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sup.policy = policy
+}
+
+// Start 启动sing-box进程并开始监管
 func (m *Manager) Start() error {
+	if unit := m.systemd(); unit != nil {
+		return m.startUnit(unit)
+	}
+
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
@@ -484,15 +671,32 @@ func (m *Manager) Start() error {
 		return fmt.Errorf("sing-box已在运行")
 	}
 
+	// 手动启动会清除之前的退避和崩溃循环状态
+	m.sup.cancelTimer()
+	m.sup.backoff = 0
+	m.sup.restartTimes = nil
+
+	if err := m.startLocked(); err != nil {
+		m.sup.state = StateStopped
+		return err
+	}
+
+	m.sup.active = true
+	return nil
+}
+
+// startLocked 启动sing-box进程，调用方需持有m.mu
+func (m *Manager) startLocked() error {
 	// 检查配置文件是否存在
 	if _, err := os.Stat(m.configPath); os.IsNotExist(err) {
 		return fmt.Errorf("配置文件不存在: %s", m.configPath)
 	}
 
-	// 启动sing-box进程
+	// 启动sing-box进程，输出写入日志缓冲区，stderr同时保留最近的输出用于退出诊断
+	stderrTail := newTailBuffer(stderrTailLines)
 	cmd := exec.Command(m.binaryPath, "run", "-c", m.configPath)
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
+	cmd.Stdout = io.MultiWriter(os.Stdout, m.logs.Writer("stdout"))
+	cmd.Stderr = io.MultiWriter(os.Stderr, stderrTail, m.logs.Writer("stderr"))
 
 	if err := cmd.Start(); err != nil {
 		return fmt.Errorf("启动sing-box失败: %v", err)
@@ -500,49 +704,72 @@ func (m *Manager) Start() error {
 
 	m.process = cmd.Process
 	m.running = true
+	m.startedAt = time.Now()
+	m.exited = make(chan struct{})
+	m.sup.state = StateRunning
+	m.sup.stopping = false
 
 	log.Printf("sing-box进程已启动, PID: %d", m.process.Pid)
 
+	// 迁入独立cgroup，失败时进程仍继续运行但不受资源限制
+	if m.cg != nil {
+		if err := m.cg.attach(m.process.Pid); err != nil {
+			log.Printf("将sing-box迁入cgroup %s 失败: %v", m.cg.path, err)
+		}
+	}
+
 	// 启动进程监控
-	go m.monitorProcess(cmd)
+	go m.monitorProcess(cmd, m.startedAt, m.exited, stderrTail)
 
 	return nil
 }
 
-// Stop 停止sing-box进程
+// Stop 停止sing-box进程并结束监管
 func (m *Manager) Stop() error {
+	if unit := m.systemd(); unit != nil {
+		return m.stopUnit(unit)
+	}
+
 	m.mu.Lock()
-	defer m.mu.Unlock()
+
+	// 取消待执行的自动重启
+	wasPending := m.sup.state == StateBackingOff
+	m.sup.active = false
+	m.sup.cancelTimer()
 
 	if !m.running || m.process == nil {
+		m.sup.state = StateStopped
+		m.mu.Unlock()
+		if wasPending {
+			log.Println("已取消待执行的sing-box自动重启")
+			return nil
+		}
 		return fmt.Errorf("sing-box未运行")
 	}
 
+	m.sup.stopping = true
+	process := m.process
+	exited := m.exited
+
 	// 发送SIGTERM信号
-	if err := m.process.Signal(syscall.SIGTERM); err != nil {
+	if err := process.Signal(syscall.SIGTERM); err != nil {
+		m.sup.stopping = false
+		m.mu.Unlock()
 		return fmt.Errorf("停止sing-box失败: %v", err)
 	}
+	m.mu.Unlock()
 
-	// 等待进程退出
-	done := make(chan error, 1)
-	go func() {
-		_, err := m.process.Wait()
-		done <- err
-	}()
-
+	// 等待进程退出（由monitorProcess回收）
 	select {
-	case err := <-done:
-		m.running = false
-		m.process = nil
+	case <-exited:
 		log.Println("sing-box进程已停止")
-		return err
+		return nil
 	case <-time.After(10 * time.Second):
 		// 强制杀死进程
-		if err := m.process.Kill(); err != nil {
+		if err := process.Kill(); err != nil {
 			return fmt.Errorf("强制终止sing-box失败: %v", err)
 		}
-		m.running = false
-		m.process = nil
+		<-exited
 		log.Println("sing-box进程已被强制终止")
 		return nil
 	}
@@ -550,7 +777,16 @@ func (m *Manager) Stop() error {
 
 // Restart 重启sing-box进程
 func (m *Manager) Restart() error {
-	if m.IsRunning() {
+	if unit := m.systemd(); unit != nil {
+		return m.restartUnit(unit)
+	}
+
+	// 退避等待中的进程同样需要Stop，以取消待执行的自动重启
+	m.mu.RLock()
+	active := m.running || m.sup.state == StateBackingOff
+	m.mu.RUnlock()
+
+	if active {
 		if err := m.Stop(); err != nil {
 			return fmt.Errorf("停止进程失败: %v", err)
 		}
@@ -563,6 +799,11 @@ func (m *Manager) Restart() error {
 
 // IsRunning 检查进程是否运行
 func (m *Manager) IsRunning() bool {
+	if unit := m.systemd(); unit != nil {
+		status, err := unit.status()
+		return err == nil && status.running()
+	}
+
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 	return m.running
@@ -570,6 +811,11 @@ func (m *Manager) IsRunning() bool {
 
 // GetPID 获取进程ID
 func (m *Manager) GetPID() int {
+	if unit := m.systemd(); unit != nil {
+		status, _ := unit.status()
+		return status.MainPID
+	}
+
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 	if m.process != nil {
@@ -578,40 +824,41 @@ func (m *Manager) GetPID() int {
 	return 0
 }
 
-// UpdateConfig 更新配置并重启
-func (m *Manager) UpdateConfig(config *Config) error {
-	// 备份当前配置
-	if err := m.backupConfig(); err != nil {
-		log.Printf("备份配置失败: %v", err)
-	}
-
-	// 写入新配置
-	if err := m.writeConfig(config); err != nil {
-		return fmt.Errorf("写入配置失败: %v", err)
-	}
-
-	// 验证配置
-	if err := m.validateConfig(); err != nil {
-		// 配置无效，恢复备份
-		m.restoreConfig()
-		return fmt.Errorf("配置验证失败: %v", err)
-	}
+// UpdateConfig 更新配置并重启（经由暂存-校验-替换-重启-探测流水线），source记录到配置历史
+func (m *Manager) UpdateConfig(config *Config, source string) error {
+	opts := DefaultApplyOptions()
+	opts.Source = source
+	return m.ApplyConfig(config, opts).Err()
+}
 
-	m.lastConfig = config
+// GetConfig 获取当前配置的副本，尚未加载时从配置文件读取，调用方可自由修改
+func (m *Manager) GetConfig() *Config {
+	m.mu.RLock()
+	current := m.lastConfig
+	m.mu.RUnlock()
 
-	// 重启服务应用新配置
-	if m.IsRunning() {
-		return m.Restart()
+	if current == nil {
+		loaded, err := m.LoadConfigFromFile()
+		if err != nil {
+			return nil
+		}
+		current = loaded
 	}
 
-	return nil
+	return current.Clone()
 }
 
-// GetConfig 获取当前配置
-func (m *Manager) GetConfig() *Config {
-	m.mu.RLock()
-	defer m.mu.RUnlock()
-	return m.lastConfig
+// Clone 深拷贝配置（经由JSON往返，包含未建模字段）
+func (c *Config) Clone() *Config {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return nil
+	}
+	var clone Config
+	if err := json.Unmarshal(data, &clone); err != nil {
+		return nil
+	}
+	return &clone
 }
 
 // LoadConfigFromFile 从文件加载配置
@@ -873,83 +1120,154 @@ func (m *Manager) PrintConfigInfo() error {
 	return nil
 }
 
-// monitorProcess 监控进程状态
-func (m *Manager) monitorProcess(cmd *exec.Cmd) {
+// monitorProcess 监控进程状态，异常退出时按重启策略自动拉起
+func (m *Manager) monitorProcess(cmd *exec.Cmd, startedAt time.Time, exited chan struct{}, stderrTail *tailBuffer) {
 	err := cmd.Wait()
-	
+	close(exited)
+
+	record := newExitRecord(cmd.Process.Pid, startedAt, err, stderrTail.Lines())
+
 	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.process != cmd.Process {
+		// 已被新进程替换
+		return
+	}
+
 	m.running = false
 	m.process = nil
-	m.mu.Unlock()
+	if m.cg != nil && m.cg.checkOOM() {
+		record.OOMKilled = true
+		log.Printf("sing-box进程因内存超过memory.max被OOM终止: PID=%d", record.PID)
+	}
+	m.sup.recordExit(record)
 
-	if err != nil {
-		log.Printf("sing-box进程异常退出: %v", err)
-	} else {
-		log.Println("sing-box进程正常退出")
+	if m.sup.stopping || !m.sup.active {
+		m.sup.stopping = false
+		m.sup.state = StateStopped
+		log.Printf("sing-box进程已退出: PID=%d, 退出码=%d", record.PID, record.ExitCode)
+		return
 	}
-}
 
-// writeConfig 写入配置文件
-func (m *Manager) writeConfig(config *Config) error {
-	data, err := json.MarshalIndent(config, "", "  ")
-	if err != nil {
-		return fmt.Errorf("序列化配置失败: %v", err)
+	log.Printf("sing-box进程异常退出: PID=%d, 退出码=%d, 运行时长=%v, 错误=%s",
+		record.PID, record.ExitCode, record.Uptime.Round(time.Second), record.Error)
+	for _, line := range record.StderrTail {
+		log.Printf("  [sing-box stderr] %s", line)
 	}
 
-	return os.WriteFile(m.configPath, data, 0644)
+	m.scheduleRestartLocked(record.Uptime)
 }
 
-// validateConfig 验证配置文件
-func (m *Manager) validateConfig() error {
-	// 使用sing-box检查配置
-	cmd := exec.Command(m.binaryPath, "check", "-c", m.configPath)
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("配置验证失败: %v", err)
+// scheduleRestartLocked 按退避策略安排重启，调用方需持有m.mu
+func (m *Manager) scheduleRestartLocked(uptime time.Duration) {
+	if !m.sup.policy.Enabled {
+		m.sup.state = StateStopped
+		log.Println("sing-box自动重启未启用，进程保持停止状态")
+		return
 	}
-	return nil
-}
 
-// backupConfig 备份配置文件
-func (m *Manager) backupConfig() error {
-	backupPath := m.configPath + ".backup"
-	
-	data, err := os.ReadFile(m.configPath)
-	if err != nil {
-		return err
+	now := time.Now()
+	if m.sup.policy.MaxRestarts > 0 && m.sup.recentRestarts(now) >= m.sup.policy.MaxRestarts {
+		m.sup.state = StateCrashLooping
+		m.sup.nextRestartAt = time.Time{}
+		log.Printf("sing-box在%v内已重启%d次，判定为崩溃循环，停止自动重启",
+			m.sup.policy.Window, m.sup.policy.MaxRestarts)
+		return
 	}
-	
-	return os.WriteFile(backupPath, data, 0644)
+
+	backoff := m.sup.nextBackoff(uptime)
+	m.sup.state = StateBackingOff
+	m.sup.nextRestartAt = now.Add(backoff)
+	m.sup.timer = time.AfterFunc(backoff, m.restartAfterBackoff)
+
+	log.Printf("sing-box将在%v后自动重启", backoff)
 }
 
-// restoreConfig 恢复配置文件
-func (m *Manager) restoreConfig() error {
-	backupPath := m.configPath + ".backup"
-	
-	if _, err := os.Stat(backupPath); os.IsNotExist(err) {
-		return fmt.Errorf("备份文件不存在")
+// restartAfterBackoff 退避结束后重启进程
+func (m *Manager) restartAfterBackoff() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !m.sup.active || m.sup.state != StateBackingOff || m.running {
+		return
 	}
-	
-	data, err := os.ReadFile(backupPath)
-	if err != nil {
-		return err
+
+	m.sup.timer = nil
+	m.sup.nextRestartAt = time.Time{}
+	m.sup.restartTimes = append(m.sup.restartTimes, time.Now())
+	m.sup.restarts++
+
+	log.Printf("正在自动重启sing-box（第%d次）...", m.sup.restarts)
+
+	if err := m.startLocked(); err != nil {
+		log.Printf("自动重启sing-box失败: %v", err)
+		m.sup.recordExit(newExitRecord(0, time.Now(), err, nil))
+		m.scheduleRestartLocked(0)
 	}
-	
-	return os.WriteFile(m.configPath, data, 0644)
+}
+
+// SupervisorStatus 获取进程监管状态
+func (m *Manager) SupervisorStatus() SupervisorStatus {
+	if unit := m.systemd(); unit != nil {
+		return m.unitSupervisorStatus(unit)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.sup.snapshot()
 }
 
 // GetStatus 获取进程状态信息
 func (m *Manager) GetStatus() map[string]string {
+	sup := m.SupervisorStatus()
+
 	status := map[string]string{
-		"running":     fmt.Sprintf("%t", m.IsRunning()),
-		"pid":         fmt.Sprintf("%d", m.GetPID()),
-		"config_path": m.configPath,
-		"binary_path": m.binaryPath,
+		"running":         fmt.Sprintf("%t", m.IsRunning()),
+		"pid":             fmt.Sprintf("%d", m.GetPID()),
+		"config_path":     m.configPath,
+		"binary_path":     m.binaryPath,
+		"status":          string(sup.State),
+		"state":           string(sup.State),
+		"restarts":        fmt.Sprintf("%d", sup.Restarts),
+		"recent_restarts": fmt.Sprintf("%d", sup.RecentRestarts),
+		"process_mode":    string(m.ProcessMode()),
 	}
 
-	if m.IsRunning() {
-		status["status"] = "running"
-	} else {
-		status["status"] = "stopped"
+	if unit := m.systemd(); unit != nil {
+		status["systemd_unit"] = unit.name
+		status["unit_file"] = unit.path
+	}
+
+	if sup.State == StateBackingOff {
+		status["backoff"] = sup.Backoff.String()
+		status["next_restart_at"] = sup.NextRestartAt.Format(time.RFC3339)
+	}
+
+	if sup.LastExit != nil {
+		status["last_exit_code"] = fmt.Sprintf("%d", sup.LastExit.ExitCode)
+		status["last_exit_at"] = sup.LastExit.ExitedAt.Format(time.RFC3339)
+		if sup.LastExit.Error != "" {
+			status["last_exit_error"] = sup.LastExit.Error
+		}
+		if sup.LastExit.OOMKilled {
+			status["last_exit_oom_killed"] = "true"
+		}
+		if len(sup.LastExit.StderrTail) > 0 {
+			status["last_exit_stderr"] = strings.Join(sup.LastExit.StderrTail, "\n")
+		}
+	}
+
+	// cgroup资源用量
+	if usage, err := m.ResourceUsage(); err == nil {
+		status["cgroup"] = usage.Cgroup
+		status["memory_current"] = fmt.Sprintf("%d", usage.MemoryCurrent)
+		status["memory_max"] = fmt.Sprintf("%d", usage.MemoryMax)
+		status["cpu_usage_usec"] = fmt.Sprintf("%d", usage.CPUUsageUsec)
+		status["cpu_throttled_usec"] = fmt.Sprintf("%d", usage.CPUThrottledUsec)
+		status["pids_current"] = fmt.Sprintf("%d", usage.PidsCurrent)
+		status["pids_max"] = fmt.Sprintf("%d", usage.PidsMax)
+		status["oom_kills"] = fmt.Sprintf("%d", usage.OOMKills)
 	}
 
 	// 获取配置文件修改时间
@@ -958,4 +1276,4 @@ func (m *Manager) GetStatus() map[string]string {
 	}
 
 	return status
-}
\ No newline at end of file
+}
@@ -0,0 +1,294 @@
+package singbox
+
+import (
+	"bufio"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// procNetFiles 需要检查的/proc/net监听表及其协议
+var procNetFiles = []struct {
+	file     string
+	protocol string
+}{
+	{"tcp", "tcp"},
+	{"tcp6", "tcp"},
+	{"udp", "udp"},
+	{"udp6", "udp"},
+}
+
+const (
+	tcpStateListen = "0A" // TCP_LISTEN
+	udpStateBound  = "07" // 未连接的UDP套接字（TCP_CLOSE）
+)
+
+// Listener 本机监听中的套接字
+type Listener struct {
+	Protocol string `json:"protocol"` // tcp 或 udp
+	Address  string `json:"address"`
+	Port     int    `json:"port"`
+	PID      int    `json:"pid,omitempty"` // 0表示无法确定所属进程（如非root运行）
+	Process  string `json:"process,omitempty"`
+	inode    string
+}
+
+// PortConflict 入站与已有监听的冲突
+type PortConflict struct {
+	Inbound  string `json:"inbound"`  // 入站tag
+	Protocol string `json:"protocol"` // tcp 或 udp
+	Listen   string `json:"listen"`   // 入站监听地址
+	Port     int    `json:"port"`
+	Address  string `json:"address"`           // 已占用端口的监听地址
+	PID      int    `json:"pid,omitempty"`     // 占用进程，0表示未知
+	Process  string `json:"process,omitempty"` // 占用进程名
+}
+
+// String 返回冲突描述
+func (c PortConflict) String() string {
+	owner := c.Process
+	if owner == "" {
+		owner = "未知进程"
+	}
+	if c.PID > 0 {
+		owner = fmt.Sprintf("%s(PID %d)", owner, c.PID)
+	}
+	return fmt.Sprintf("入站 %s 的 %s %s 已被 %s 占用", c.Inbound, c.Protocol,
+		net.JoinHostPort(c.Listen, strconv.Itoa(c.Port)), owner)
+}
+
+// PortConflictError 端口冲突导致配置被拒绝
+type PortConflictError struct {
+	Conflicts []PortConflict
+}
+
+// Error 实现error接口
+func (e *PortConflictError) Error() string {
+	messages := make([]string, 0, len(e.Conflicts))
+	for _, conflict := range e.Conflicts {
+		messages = append(messages, conflict.String())
+	}
+	return fmt.Sprintf("%d 个端口冲突: %s", len(e.Conflicts), strings.Join(messages, "; "))
+}
+
+// CheckPorts 检查配置中的入站端口是否已被其他进程占用，当前sing-box进程自身的监听不视为冲突。
+// 同一配置内入站之间的端口冲突由Validate检查
+func (m *Manager) CheckPorts(config *Config) ([]PortConflict, error) {
+	listeners, err := ListListeners()
+	if err != nil {
+		return nil, err
+	}
+
+	self := m.GetPID()
+	var conflicts []PortConflict
+
+	for _, inbound := range config.Inbounds {
+		if inbound.ListenPort == 0 {
+			continue
+		}
+		port := int(inbound.ListenPort)
+
+		for _, protocol := range inboundProtocols(inbound) {
+			for _, listener := range listeners {
+				if listener.Protocol != protocol || listener.Port != port {
+					continue
+				}
+				if self > 0 && listener.PID == self {
+					continue
+				}
+				if !addressesOverlap(inbound.Listen, listener.Address) {
+					continue
+				}
+				conflicts = append(conflicts, PortConflict{
+					Inbound:  inbound.Tag,
+					Protocol: protocol,
+					Listen:   inbound.Listen,
+					Port:     port,
+					Address:  listener.Address,
+					PID:      listener.PID,
+					Process:  listener.Process,
+				})
+			}
+		}
+	}
+
+	return conflicts, nil
+}
+
+// inboundProtocols 返回入站监听的传输协议
+func inboundProtocols(inbound Inbound) []string {
+	switch inbound.Type {
+	case "tun":
+		return nil
+	case "hysteria", "hysteria2", "tuic":
+		return []string{"udp"}
+	case "shadowsocks", "direct":
+		switch inbound.Network {
+		case "tcp":
+			return []string{"tcp"}
+		case "udp":
+			return []string{"udp"}
+		default:
+			return []string{"tcp", "udp"}
+		}
+	default:
+		return []string{"tcp"}
+	}
+}
+
+// addressesOverlap 判断两个监听地址是否会争用同一端口，任一方为通配地址时视为重叠
+func addressesOverlap(a, b string) bool {
+	if isWildcardAddress(a) || isWildcardAddress(b) {
+		return true
+	}
+	ipA, ipB := net.ParseIP(a), net.ParseIP(b)
+	if ipA == nil || ipB == nil {
+		return a == b
+	}
+	return ipA.Equal(ipB)
+}
+
+// isWildcardAddress 判断是否为通配监听地址
+func isWildcardAddress(address string) bool {
+	if address == "" {
+		return true
+	}
+	ip := net.ParseIP(address)
+	return ip != nil && ip.IsUnspecified()
+}
+
+// ListListeners 读取/proc/net中的TCP监听和UDP绑定，并尽可能关联到所属进程
+func ListListeners() ([]Listener, error) {
+	var listeners []Listener
+	found := false
+	for _, entry := range procNetFiles {
+		items, err := readProcNet(filepath.Join("/proc/net", entry.file), entry.protocol)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+		found = true
+		listeners = append(listeners, items...)
+	}
+	if !found {
+		return nil, fmt.Errorf("无法读取/proc/net监听表")
+	}
+
+	owners := socketOwners()
+	for i := range listeners {
+		if owner, ok := owners[listeners[i].inode]; ok {
+			listeners[i].PID = owner.pid
+			listeners[i].Process = owner.name
+		}
+	}
+	return listeners, nil
+}
+
+// readProcNet 解析/proc/net/{tcp,tcp6,udp,udp6}
+func readProcNet(path, protocol string) ([]Listener, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	state := tcpStateListen
+	if protocol == "udp" {
+		state = udpStateBound
+	}
+
+	var listeners []Listener
+	scanner := bufio.NewScanner(f)
+	scanner.Scan() // 表头
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 10 || fields[3] != state {
+			continue
+		}
+		address, port, err := parseProcNetAddress(fields[1])
+		if err != nil {
+			continue
+		}
+		listeners = append(listeners, Listener{
+			Protocol: protocol,
+			Address:  address,
+			Port:     port,
+			inode:    fields[9],
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("读取%s失败: %v", path, err)
+	}
+	return listeners, nil
+}
+
+// parseProcNetAddress 解析"十六进制地址:十六进制端口"，地址按32位字为主机字节序（小端）
+func parseProcNetAddress(value string) (string, int, error) {
+	hexAddr, hexPort, ok := strings.Cut(value, ":")
+	if !ok {
+		return "", 0, fmt.Errorf("地址格式无效: %s", value)
+	}
+	port, err := strconv.ParseUint(hexPort, 16, 16)
+	if err != nil {
+		return "", 0, err
+	}
+	raw, err := hex.DecodeString(hexAddr)
+	if err != nil || (len(raw) != net.IPv4len && len(raw) != net.IPv6len) {
+		return "", 0, fmt.Errorf("地址格式无效: %s", value)
+	}
+	ip := make(net.IP, len(raw))
+	for i := 0; i < len(raw); i += 4 {
+		ip[i], ip[i+1], ip[i+2], ip[i+3] = raw[i+3], raw[i+2], raw[i+1], raw[i]
+	}
+	return ip.String(), int(port), nil
+}
+
+// socketOwner 套接字所属进程
+type socketOwner struct {
+	pid  int
+	name string
+}
+
+// socketOwners 扫描/proc/<pid>/fd建立套接字inode到进程的映射，无权限读取的进程会被跳过
+func socketOwners() map[string]socketOwner {
+	owners := make(map[string]socketOwner)
+	entries, err := os.ReadDir("/proc")
+	if err != nil {
+		return owners
+	}
+
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil {
+			continue
+		}
+		fdDir := filepath.Join("/proc", entry.Name(), "fd")
+		fds, err := os.ReadDir(fdDir)
+		if err != nil {
+			continue
+		}
+
+		var name string
+		for _, fd := range fds {
+			link, err := os.Readlink(filepath.Join(fdDir, fd.Name()))
+			if err != nil || !strings.HasPrefix(link, "socket:[") {
+				continue
+			}
+			if name == "" {
+				comm, _ := os.ReadFile(filepath.Join("/proc", entry.Name(), "comm"))
+				name = strings.TrimSpace(string(comm))
+			}
+			inode := strings.TrimSuffix(strings.TrimPrefix(link, "socket:["), "]")
+			if _, exists := owners[inode]; !exists {
+				owners[inode] = socketOwner{pid: pid, name: name}
+			}
+		}
+	}
+	return owners
+}
@@ -0,0 +1,178 @@
+package singbox
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseProcNetAddress(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		wantAddr string
+		wantPort int
+		wantErr  bool
+	}{
+		{"IPv4回环", "0100007F:0050", "127.0.0.1", 80, false},
+		{"IPv4通配", "00000000:01BB", "0.0.0.0", 443, false},
+		{"IPv4私网", "0101A8C0:1F90", "192.168.1.1", 8080, false},
+		{"IPv6通配", "00000000000000000000000000000000:0035", "::", 53, false},
+		{"IPv6回环", "00000000000000000000000001000000:1F90", "::1", 8080, false},
+		{"IPv4映射IPv6", "0000000000000000FFFF00000100007F:0016", "127.0.0.1", 22, false},
+		{"缺少端口", "0100007F", "", 0, true},
+		{"端口非十六进制", "0100007F:ZZ", "", 0, true},
+		{"端口超出范围", "0100007F:10000", "", 0, true},
+		{"地址长度无效", "01007F:0050", "", 0, true},
+		{"地址非十六进制", "GG00007F:0050", "", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			addr, port, err := parseProcNetAddress(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseProcNetAddress(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			}
+			if addr != tt.wantAddr || port != tt.wantPort {
+				t.Errorf("parseProcNetAddress(%q) = (%q, %d), want (%q, %d)", tt.value, addr, port, tt.wantAddr, tt.wantPort)
+			}
+		})
+	}
+}
+
+func TestReadProcNet(t *testing.T) {
+	content := strings.Join([]string{
+		"  sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode",
+		"   0: 0100007F:0050 00000000:0000 0A 00000000:00000000 00:00000000 00000000     0        0 1001 1 0000000000000000 100 0 0 10 0",
+		"   1: 0100007F:A1B2 0100007F:0050 01 00000000:00000000 00:00000000 00000000  1000        0 1002 1 0000000000000000 20 4 30 10 -1",
+		"   2: 00000000:01BB 00000000:0000 0A 00000000:00000000 00:00000000 00000000     0        0 1003 1 0000000000000000 100 0 0 10 0",
+		"   3: 00000000:0035 00000000:0000 07 00000000:00000000 00:00000000 00000000     0        0 1004 2 0000000000000000 0",
+		"   4: bad",
+		"",
+	}, "\n")
+	path := filepath.Join(t.TempDir(), "tcp")
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		protocol string
+		want     []Listener
+	}{
+		{"tcp", []Listener{
+			{Protocol: "tcp", Address: "127.0.0.1", Port: 80, inode: "1001"},
+			{Protocol: "tcp", Address: "0.0.0.0", Port: 443, inode: "1003"},
+		}},
+		{"udp", []Listener{
+			{Protocol: "udp", Address: "0.0.0.0", Port: 53, inode: "1004"},
+		}},
+	}
+	for _, tt := range tests {
+		got, err := readProcNet(path, tt.protocol)
+		if err != nil {
+			t.Fatalf("readProcNet(%s) error = %v", tt.protocol, err)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("readProcNet(%s) = %+v, want %+v", tt.protocol, got, tt.want)
+		}
+	}
+
+	if _, err := readProcNet(filepath.Join(t.TempDir(), "missing"), "tcp"); !os.IsNotExist(err) {
+		t.Errorf("文件不存在时 error = %v, want IsNotExist", err)
+	}
+}
+
+func TestAddressesOverlap(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"", "127.0.0.1", true},
+		{"0.0.0.0", "10.0.0.1", true},
+		{"::", "127.0.0.1", true},
+		{"127.0.0.1", "::", true},
+		{"127.0.0.1", "127.0.0.1", true},
+		{"127.0.0.1", "::ffff:127.0.0.1", true},
+		{"127.0.0.1", "127.0.0.2", false},
+		{"::1", "127.0.0.1", false},
+		{"localhost", "localhost", true},
+		{"localhost", "127.0.0.1", false},
+	}
+	for _, tt := range tests {
+		if got := addressesOverlap(tt.a, tt.b); got != tt.want {
+			t.Errorf("addressesOverlap(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestInboundProtocols(t *testing.T) {
+	tests := []struct {
+		inbound Inbound
+		want    []string
+	}{
+		{Inbound{Type: "vless"}, []string{"tcp"}},
+		{Inbound{Type: "mixed"}, []string{"tcp"}},
+		{Inbound{Type: "hysteria2"}, []string{"udp"}},
+		{Inbound{Type: "tuic"}, []string{"udp"}},
+		{Inbound{Type: "tun"}, nil},
+		{Inbound{Type: "shadowsocks"}, []string{"tcp", "udp"}},
+		{Inbound{Type: "shadowsocks", Network: "udp"}, []string{"udp"}},
+		{Inbound{Type: "direct", Network: "tcp"}, []string{"tcp"}},
+	}
+	for _, tt := range tests {
+		if got := inboundProtocols(tt.inbound); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("inboundProtocols(%s/%s) = %v, want %v", tt.inbound.Type, tt.inbound.Network, got, tt.want)
+		}
+	}
+}
+
+func TestCheckPorts(t *testing.T) {
+	if _, err := os.Stat("/proc/net/tcp"); err != nil {
+		t.Skip("当前系统没有/proc/net")
+	}
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	port := uint16(ln.Addr().(*net.TCPAddr).Port)
+
+	m := NewManager("sing-box", filepath.Join(t.TempDir(), "config.json"))
+	tests := []struct {
+		name    string
+		inbound Inbound
+		want    bool
+	}{
+		{"同地址同协议", Inbound{Type: "vless", Tag: "a", Listen: "127.0.0.1", ListenPort: port}, true},
+		{"通配地址", Inbound{Type: "vless", Tag: "b", Listen: "::", ListenPort: port}, true},
+		{"不同地址", Inbound{Type: "vless", Tag: "c", Listen: "127.0.0.2", ListenPort: port}, false},
+		{"UDP入站", Inbound{Type: "hysteria2", Tag: "d", Listen: "127.0.0.1", ListenPort: port}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conflicts, err := m.CheckPorts(&Config{Inbounds: []Inbound{tt.inbound}})
+			if err != nil {
+				t.Fatalf("CheckPorts() error = %v", err)
+			}
+			if got := len(conflicts) > 0; got != tt.want {
+				t.Fatalf("CheckPorts() = %+v, want 冲突 %v", conflicts, tt.want)
+			}
+			if tt.want && (conflicts[0].Inbound != tt.inbound.Tag || conflicts[0].Port != int(port) || conflicts[0].Protocol != "tcp") {
+				t.Errorf("冲突信息 = %+v", conflicts[0])
+			}
+		})
+	}
+}
+
+func TestPortConflictError(t *testing.T) {
+	err := &PortConflictError{Conflicts: []PortConflict{
+		{Inbound: "vless-in", Protocol: "tcp", Listen: "0.0.0.0", Port: 443, PID: 812, Process: "nginx"},
+		{Inbound: "hy2-in", Protocol: "udp", Listen: "::", Port: 443},
+	}}
+	want := "2 个端口冲突: 入站 vless-in 的 tcp 0.0.0.0:443 已被 nginx(PID 812) 占用; 入站 hy2-in 的 udp [::]:443 已被 未知进程 占用"
+	if got := err.Error(); got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,166 @@
+package singbox
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"log"
+	"net"
+	"strconv"
+)
+
+// ProbeInboundTag 自动注入的出站探测入站tag
+const ProbeInboundTag = "xbox-probe"
+
+// probeSkipOutboundTypes 不需要探测的出站类型
+var probeSkipOutboundTypes = map[string]bool{
+	"block": true,
+	"dns":   true,
+}
+
+// ProbeEndpoint 出站探测入站的访问信息，以出站tag作为SOCKS用户名即可经由该出站连接
+type ProbeEndpoint struct {
+	Addr      string   // 本机访问地址
+	Password  string   // 各用户共用的密码
+	Outbounds []string // 可探测的出站tag，按配置顺序排列
+}
+
+// SetProbeListen 设置自动注入的出站探测入站监听地址，为空时不注入
+func (m *Manager) SetProbeListen(listen string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.probeListen = listen
+}
+
+// ensureProbeInbound 注入仅本机访问的SOCKS探测入站，每个出站对应一个用户，
+// 并在路由规则最前面按用户名将连接路由到同名出站。已有的探测入站和规则先移除再按当前出站重新生成
+func (m *Manager) ensureProbeInbound(config *Config) {
+	m.mu.RLock()
+	listen := m.probeListen
+	current := m.lastConfig
+	m.mu.RUnlock()
+
+	// 沿用当前配置中的密码以免产生无意义的配置差异
+	secret := probeSecret(current)
+	stripProbeInbound(config)
+
+	if listen == "" {
+		return
+	}
+	host, portStr, err := net.SplitHostPort(listen)
+	if err != nil {
+		log.Printf("出站探测监听地址无效(%s): %v", listen, err)
+		return
+	}
+	port, err := strconv.ParseUint(portStr, 10, 16)
+	if err != nil || port == 0 {
+		log.Printf("出站探测监听端口无效(%s)", listen)
+		return
+	}
+
+	if secret == "" {
+		buf := make([]byte, 16)
+		if _, err := rand.Read(buf); err != nil {
+			log.Printf("生成出站探测密码失败: %v", err)
+			return
+		}
+		secret = hex.EncodeToString(buf)
+	}
+
+	inbound := Inbound{
+		Type:       "socks",
+		Tag:        ProbeInboundTag,
+		Listen:     host,
+		ListenPort: uint16(port),
+	}
+	var rules []RouteRule
+	for _, outbound := range config.Outbounds {
+		if outbound.Tag == "" || probeSkipOutboundTypes[outbound.Type] {
+			continue
+		}
+		inbound.Users = append(inbound.Users, InboundUser{Username: outbound.Tag, Password: secret})
+		rules = append(rules, RouteRule{
+			Inbound:  []string{ProbeInboundTag},
+			AuthUser: []string{outbound.Tag},
+			Outbound: outbound.Tag,
+		})
+	}
+	if len(inbound.Users) == 0 {
+		return
+	}
+
+	config.Inbounds = append(config.Inbounds, inbound)
+	if config.Route == nil {
+		config.Route = &RouteConfig{}
+	}
+	config.Route.Rules = append(rules, config.Route.Rules...)
+}
+
+// probeSecret 返回配置中探测入站使用的密码
+func probeSecret(config *Config) string {
+	if config == nil {
+		return ""
+	}
+	for _, inbound := range config.Inbounds {
+		if inbound.Tag == ProbeInboundTag && len(inbound.Users) > 0 {
+			return inbound.Users[0].Password
+		}
+	}
+	return ""
+}
+
+// stripProbeInbound 移除配置中的探测入站及其路由规则
+func stripProbeInbound(config *Config) {
+	inbounds := config.Inbounds[:0:0]
+	for _, inbound := range config.Inbounds {
+		if inbound.Tag != ProbeInboundTag {
+			inbounds = append(inbounds, inbound)
+		}
+	}
+	config.Inbounds = inbounds
+
+	if config.Route != nil {
+		rules := config.Route.Rules[:0:0]
+		for _, rule := range config.Route.Rules {
+			if len(rule.Inbound) == 1 && rule.Inbound[0] == ProbeInboundTag {
+				continue
+			}
+			rules = append(rules, rule)
+		}
+		config.Route.Rules = rules
+	}
+}
+
+// ProbeEndpoint 返回当前配置中探测入站的访问信息
+func (m *Manager) ProbeEndpoint() (ProbeEndpoint, bool) {
+	m.mu.RLock()
+	config := m.lastConfig
+	m.mu.RUnlock()
+
+	if config == nil {
+		loaded, err := m.LoadConfigFromFile()
+		if err != nil {
+			return ProbeEndpoint{}, false
+		}
+		config = loaded
+	}
+
+	for _, inbound := range config.Inbounds {
+		if inbound.Tag != ProbeInboundTag || len(inbound.Users) == 0 {
+			continue
+		}
+		host := inbound.Listen
+		// 监听在通配地址时通过本机回环访问
+		if host == "" || host == "0.0.0.0" || host == "::" {
+			host = "127.0.0.1"
+		}
+		ep := ProbeEndpoint{
+			Addr:     net.JoinHostPort(host, strconv.Itoa(int(inbound.ListenPort))),
+			Password: inbound.Users[0].Password,
+		}
+		for _, user := range inbound.Users {
+			ep.Outbounds = append(ep.Outbounds, user.Username)
+		}
+		return ep, true
+	}
+	return ProbeEndpoint{}, false
+}
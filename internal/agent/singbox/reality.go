@@ -0,0 +1,103 @@
+package singbox
+
+import (
+	"crypto/ecdh"
+	"encoding/base64"
+	"fmt"
+	"strings"
+)
+
+// realityInboundTypes 支持Reality的入站类型
+var realityInboundTypes = map[string]bool{
+	"vless":  true,
+	"trojan": true,
+	"anytls": true,
+}
+
+// GetInboundTLS 获取指定入站的TLS配置，未配置TLS时返回nil
+func (m *Manager) GetInboundTLS(tag string) (*InboundTLS, error) {
+	config := m.GetConfig()
+	if config == nil {
+		return nil, fmt.Errorf("sing-box配置未加载")
+	}
+
+	inbound, err := findInbound(config, tag)
+	if err != nil {
+		return nil, err
+	}
+	return inbound.TLS, nil
+}
+
+// SetInboundReality 设置入站的Reality私钥和short_id并应用配置，证书和ACME配置会被清除，
+// handshake为nil时保留入站当前的握手服务器，其余配置保持不变
+func (m *Manager) SetInboundReality(tag, privateKey string, shortIDs []string, handshake *RealityHandshake) (*ApplyResult, error) {
+	if _, err := RealityPublicKey(privateKey); err != nil {
+		return nil, err
+	}
+	config := m.GetConfig()
+	if config == nil {
+		return nil, fmt.Errorf("sing-box配置未加载")
+	}
+
+	inbound, err := findInbound(config, tag)
+	if err != nil {
+		return nil, err
+	}
+	if !realityInboundTypes[inbound.Type] {
+		return nil, fmt.Errorf("入站类型 %s 不支持Reality", inbound.Type)
+	}
+	if inbound.TLS == nil {
+		inbound.TLS = &InboundTLS{}
+	}
+	if inbound.TLS.Reality == nil {
+		inbound.TLS.Reality = &InboundReality{}
+	}
+	if handshake != nil {
+		inbound.TLS.Reality.Handshake = handshake
+	}
+	if inbound.TLS.Reality.Handshake == nil || inbound.TLS.Reality.Handshake.Server == "" {
+		return nil, fmt.Errorf("入站 %s 未配置Reality握手服务器", tag)
+	}
+
+	inbound.TLS.Enabled = true
+	inbound.TLS.Certificate = nil
+	inbound.TLS.CertificatePath = ""
+	inbound.TLS.Key = nil
+	inbound.TLS.KeyPath = ""
+	inbound.TLS.ACME = nil
+	inbound.TLS.Reality.Enabled = true
+	inbound.TLS.Reality.PrivateKey = privateKey
+	inbound.TLS.Reality.ShortID = shortIDs
+
+	opts := DefaultApplyOptions()
+	opts.Source = "reality:" + tag
+	result := m.ApplyConfig(config, opts)
+	return result, result.Err()
+}
+
+// RealityPublicKey 由Reality私钥（base64url）推导X25519公钥
+func RealityPublicKey(privateKey string) (string, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(privateKey, "="))
+	if err != nil {
+		return "", fmt.Errorf("Reality私钥格式无效: %v", err)
+	}
+	key, err := ecdh.X25519().NewPrivateKey(raw)
+	if err != nil {
+		return "", fmt.Errorf("Reality私钥无效: %v", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(key.PublicKey().Bytes()), nil
+}
+
+// RealityServerName 返回客户端使用的SNI，入站server_name优先，其次为握手服务器
+func RealityServerName(tls *InboundTLS) string {
+	if tls == nil {
+		return ""
+	}
+	if tls.ServerName != "" {
+		return tls.ServerName
+	}
+	if tls.Reality != nil && tls.Reality.Handshake != nil {
+		return tls.Reality.Handshake.Server
+	}
+	return ""
+}
@@ -0,0 +1,62 @@
+package singbox
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"testing"
+)
+
+func TestRealityPublicKey(t *testing.T) {
+	// RFC 7748 6.1 的X25519测试向量
+	decode := func(s string) string {
+		raw, err := hex.DecodeString(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return base64.RawURLEncoding.EncodeToString(raw)
+	}
+	privateKey := decode("77076d0a7318a57d3c16c17251b26645df4c2f87ebc0992ab177fba51db92c2a")
+	publicKey := decode("8520f0098930a754748b7ddcb43ef75a0dbf3a0d26381af4eba4a98eaa9b4e6a")
+
+	tests := []struct {
+		name       string
+		privateKey string
+		want       string
+		wantErr    bool
+	}{
+		{"base64url无填充", privateKey, publicKey, false},
+		{"带填充", privateKey + "=", publicKey, false},
+		{"非base64", "not base64!", "", true},
+		{"长度错误", base64.RawURLEncoding.EncodeToString([]byte("short")), "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := RealityPublicKey(tt.privateKey)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("RealityPublicKey() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("RealityPublicKey() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRealityServerName(t *testing.T) {
+	handshake := &InboundReality{Handshake: &RealityHandshake{Server: "www.microsoft.com", ServerPort: 443}}
+	tests := []struct {
+		name string
+		tls  *InboundTLS
+		want string
+	}{
+		{"未配置TLS", nil, ""},
+		{"server_name优先", &InboundTLS{ServerName: "cdn.example.com", Reality: handshake}, "cdn.example.com"},
+		{"使用握手服务器", &InboundTLS{Reality: handshake}, "www.microsoft.com"},
+		{"没有握手服务器", &InboundTLS{Reality: &InboundReality{}}, ""},
+	}
+	for _, tt := range tests {
+		if got := RealityServerName(tt.tls); got != tt.want {
+			t.Errorf("%s: RealityServerName() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
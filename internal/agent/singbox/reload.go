@@ -0,0 +1,109 @@
+package singbox
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// 热重载确认超时
+const reloadTimeout = 10 * time.Second
+
+// Reload 向运行中的sing-box发送SIGHUP重新加载配置文件，并通过sing-box输出确认重载结果
+func (m *Manager) Reload() error {
+	if !m.IsRunning() {
+		return fmt.Errorf("sing-box未运行")
+	}
+	if reason := reloadUnobservable(m.GetConfig()); reason != "" {
+		return fmt.Errorf("无法确认热重载结果: %s", reason)
+	}
+
+	m.mu.RLock()
+	exited := m.exited
+	m.mu.RUnlock()
+
+	// 先订阅日志再发送信号，避免错过重载输出
+	_, updates, cancel := m.Logs().Subscribe(0, LogFilter{})
+	defer cancel()
+
+	if err := m.signalReload(); err != nil {
+		return err
+	}
+
+	deadline := time.NewTimer(reloadTimeout)
+	defer deadline.Stop()
+
+	for {
+		select {
+		case entry, ok := <-updates:
+			if !ok {
+				return fmt.Errorf("日志订阅已关闭")
+			}
+			message := strings.ToLower(entry.Message)
+			switch {
+			case strings.Contains(message, "reload service"):
+				return fmt.Errorf("sing-box拒绝重载: %s", entry.Message)
+			case strings.Contains(message, "start service"):
+				return fmt.Errorf("sing-box重载后启动失败: %s", entry.Message)
+			case strings.Contains(message, "sing-box started"):
+				log.Printf("sing-box已热重载配置, PID: %d", m.GetPID())
+				return nil
+			}
+		case <-exited:
+			return fmt.Errorf("sing-box在重载过程中退出")
+		case <-deadline.C:
+			return fmt.Errorf("%v内未确认热重载完成", reloadTimeout)
+		}
+	}
+}
+
+// ReloadOrRestart 优先热重载，无法确认重载生效时回退到完整重启
+func (m *Manager) ReloadOrRestart() error {
+	err := m.Reload()
+	if err == nil {
+		return nil
+	}
+	log.Printf("热重载失败，回退到完整重启: %v", err)
+	return m.Restart()
+}
+
+// signalReload 通知sing-box重新加载配置，systemd托管时经由ExecReload发送SIGHUP
+func (m *Manager) signalReload() error {
+	if unit := m.systemd(); unit != nil {
+		if _, err := unit.runner.Systemctl("reload", unit.name); err != nil {
+			return fmt.Errorf("重载sing-box服务失败: %v", err)
+		}
+		return nil
+	}
+
+	m.mu.RLock()
+	process := m.process
+	m.mu.RUnlock()
+
+	if process == nil {
+		return fmt.Errorf("sing-box未运行")
+	}
+	if err := process.Signal(syscall.SIGHUP); err != nil {
+		return fmt.Errorf("发送SIGHUP失败: %v", err)
+	}
+	return nil
+}
+
+// reloadUnobservable 检查sing-box输出能否用于确认重载结果，可以时返回空字符串
+func reloadUnobservable(config *Config) string {
+	if config == nil || config.Log == nil {
+		return ""
+	}
+	if config.Log.Disabled {
+		return "sing-box日志已禁用"
+	}
+	if config.Log.Output != "" && config.Log.Output != "stderr" && config.Log.Output != "stdout" {
+		return fmt.Sprintf("sing-box日志输出到文件 %s", config.Log.Output)
+	}
+	if config.Log.Level != "" && levelRank(config.Log.Level) > levelRank("info") {
+		return fmt.Sprintf("sing-box日志级别为%s，不输出启动信息", config.Log.Level)
+	}
+	return ""
+}
@@ -0,0 +1,240 @@
+package singbox
+
+import (
+	"errors"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SupervisorState sing-box进程监管状态
+type SupervisorState string
+
+const (
+	StateStopped      SupervisorState = "stopped"       // 已停止（未启动或被主动停止）
+	StateRunning      SupervisorState = "running"       // 运行中
+	StateBackingOff   SupervisorState = "backing_off"   // 异常退出，等待退避后重启
+	StateCrashLooping SupervisorState = "crash_looping" // 崩溃循环，重启预算已耗尽
+)
+
+const (
+	maxExitRecords  = 10  // 保留的退出记录数
+	stderrTailLines = 20  // 每次退出保留的stderr行数
+	stderrLineLimit = 512 // 单行stderr最大长度
+)
+
+// RestartPolicy 崩溃重启策略
+type RestartPolicy struct {
+	Enabled        bool          // 是否在异常退出后自动重启
+	InitialBackoff time.Duration // 首次重启前的退避时间
+	MaxBackoff     time.Duration // 退避时间上限
+	Multiplier     float64       // 退避倍数
+	MaxRestarts    int           // 检测窗口内允许的最大重启次数（重启预算）
+	Window         time.Duration // 崩溃循环检测窗口
+	StableAfter    time.Duration // 进程持续运行超过该时间后重置退避
+}
+
+// DefaultRestartPolicy 默认重启策略
+func DefaultRestartPolicy() RestartPolicy {
+	return RestartPolicy{
+		Enabled:        true,
+		InitialBackoff: 1 * time.Second,
+		MaxBackoff:     60 * time.Second,
+		Multiplier:     2,
+		MaxRestarts:    5,
+		Window:         10 * time.Minute,
+		StableAfter:    2 * time.Minute,
+	}
+}
+
+// ExitRecord sing-box进程退出记录
+type ExitRecord struct {
+	PID        int           `json:"pid"`
+	ExitCode   int           `json:"exit_code"`
+	Signal     string        `json:"signal,omitempty"`
+	Error      string        `json:"error,omitempty"`
+	StartedAt  time.Time     `json:"started_at"`
+	ExitedAt   time.Time     `json:"exited_at"`
+	Uptime     time.Duration `json:"uptime"`
+	OOMKilled  bool          `json:"oom_killed,omitempty"` // 是否因超过memory.max被OOM终止
+	StderrTail []string      `json:"stderr_tail,omitempty"`
+}
+
+// SupervisorStatus 监管状态快照
+type SupervisorStatus struct {
+	State          SupervisorState `json:"state"`
+	Restarts       int             `json:"restarts"`        // 累计自动重启次数
+	RecentRestarts int             `json:"recent_restarts"` // 检测窗口内的重启次数
+	Backoff        time.Duration   `json:"backoff"`
+	NextRestartAt  time.Time       `json:"next_restart_at"`
+	LastExit       *ExitRecord     `json:"last_exit,omitempty"`
+	Exits          []ExitRecord    `json:"exits,omitempty"`
+}
+
+// supervisor 进程监管的内部状态，由Manager.mu保护
+type supervisor struct {
+	policy        RestartPolicy
+	state         SupervisorState
+	active        bool // 是否处于监管中（Start后为true，Stop后为false）
+	stopping      bool // 是否为主动停止
+	exits         []ExitRecord
+	restartTimes  []time.Time
+	restarts      int
+	backoff       time.Duration
+	nextRestartAt time.Time
+	timer         *time.Timer
+}
+
+// nextBackoff 计算下一次退避时间
+func (s *supervisor) nextBackoff(uptime time.Duration) time.Duration {
+	if s.backoff == 0 || (s.policy.StableAfter > 0 && uptime >= s.policy.StableAfter) {
+		s.backoff = s.policy.InitialBackoff
+		return s.backoff
+	}
+
+	multiplier := s.policy.Multiplier
+	if multiplier < 1 {
+		multiplier = 1
+	}
+	s.backoff = time.Duration(float64(s.backoff) * multiplier)
+	if s.policy.MaxBackoff > 0 && s.backoff > s.policy.MaxBackoff {
+		s.backoff = s.policy.MaxBackoff
+	}
+	return s.backoff
+}
+
+// recentRestarts 清理窗口外的重启记录并返回窗口内的重启次数
+func (s *supervisor) recentRestarts(now time.Time) int {
+	if s.policy.Window <= 0 {
+		return len(s.restartTimes)
+	}
+
+	kept := s.restartTimes[:0]
+	for _, t := range s.restartTimes {
+		if now.Sub(t) <= s.policy.Window {
+			kept = append(kept, t)
+		}
+	}
+	s.restartTimes = kept
+	return len(kept)
+}
+
+// recordExit 保存退出记录
+func (s *supervisor) recordExit(record ExitRecord) {
+	s.exits = append(s.exits, record)
+	if len(s.exits) > maxExitRecords {
+		s.exits = s.exits[len(s.exits)-maxExitRecords:]
+	}
+}
+
+// cancelTimer 取消待执行的重启
+func (s *supervisor) cancelTimer() {
+	if s.timer != nil {
+		s.timer.Stop()
+		s.timer = nil
+	}
+	s.nextRestartAt = time.Time{}
+}
+
+// snapshot 生成状态快照
+func (s *supervisor) snapshot() SupervisorStatus {
+	status := SupervisorStatus{
+		State:          s.state,
+		Restarts:       s.restarts,
+		RecentRestarts: s.recentRestarts(time.Now()),
+		Backoff:        s.backoff,
+		NextRestartAt:  s.nextRestartAt,
+		Exits:          append([]ExitRecord(nil), s.exits...),
+	}
+	if len(s.exits) > 0 {
+		last := s.exits[len(s.exits)-1]
+		status.LastExit = &last
+	}
+	return status
+}
+
+// newExitRecord 根据cmd.Wait的结果构建退出记录
+func newExitRecord(pid int, startedAt time.Time, waitErr error, stderr []string) ExitRecord {
+	now := time.Now()
+	record := ExitRecord{
+		PID:        pid,
+		StartedAt:  startedAt,
+		ExitedAt:   now,
+		Uptime:     now.Sub(startedAt),
+		StderrTail: stderr,
+	}
+
+	var exitErr *exec.ExitError
+	switch {
+	case waitErr == nil:
+		record.ExitCode = 0
+	case errors.As(waitErr, &exitErr):
+		record.ExitCode = exitErr.ExitCode()
+		if ws, ok := exitErr.Sys().(interface {
+			Signaled() bool
+		}); ok && ws.Signaled() {
+			record.Signal = exitErr.String()
+		}
+		record.Error = waitErr.Error()
+	default:
+		record.ExitCode = -1
+		record.Error = waitErr.Error()
+	}
+
+	return record
+}
+
+// tailBuffer 保留最近若干行输出的io.Writer
+type tailBuffer struct {
+	mu      sync.Mutex
+	lines   []string
+	partial string
+	max     int
+}
+
+// newTailBuffer 创建tail缓冲区
+func newTailBuffer(max int) *tailBuffer {
+	return &tailBuffer{max: max}
+}
+
+// Write 实现io.Writer接口
+func (t *tailBuffer) Write(p []byte) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	data := t.partial + string(p)
+	parts := strings.Split(data, "\n")
+	t.partial = parts[len(parts)-1]
+	if len(t.partial) > stderrLineLimit {
+		t.partial = t.partial[:stderrLineLimit]
+	}
+
+	for _, line := range parts[:len(parts)-1] {
+		line = strings.TrimRight(line, "\r")
+		if line == "" {
+			continue
+		}
+		if len(line) > stderrLineLimit {
+			line = line[:stderrLineLimit]
+		}
+		t.lines = append(t.lines, line)
+	}
+	if len(t.lines) > t.max {
+		t.lines = t.lines[len(t.lines)-t.max:]
+	}
+
+	return len(p), nil
+}
+
+// Lines 返回缓冲的行（包含未换行的残余内容）
+func (t *tailBuffer) Lines() []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	lines := append([]string(nil), t.lines...)
+	if t.partial != "" {
+		lines = append(lines, t.partial)
+	}
+	return lines
+}
@@ -0,0 +1,463 @@
+package singbox
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ProcessMode sing-box进程托管方式
+type ProcessMode string
+
+const (
+	ProcessModeExec    ProcessMode = "exec"    // Agent直接启动子进程，Agent退出时sing-box随之停止
+	ProcessModeSystemd ProcessMode = "systemd" // 由systemd托管，Agent重启或升级不中断代理流量
+)
+
+const (
+	defaultSystemdUnit    = "sing-box"
+	defaultSystemdUnitDir = "/etc/systemd/system"
+	journalRetryInterval  = 5 * time.Second
+	systemdTimeLayout     = "Mon 2006-01-02 15:04:05 MST"
+)
+
+// SystemdRunner 执行systemd操作的接口，默认通过systemctl/journalctl命令实现，可替换为D-Bus实现
+type SystemdRunner interface {
+	// Systemctl 执行systemctl子命令并返回输出
+	Systemctl(args ...string) (string, error)
+	// FollowJournal 持续读取unit的日志输出，Close时停止
+	FollowJournal(unit string) (io.ReadCloser, error)
+}
+
+// SystemdOptions systemd托管配置
+type SystemdOptions struct {
+	Unit    string        // unit名称（不含.service后缀）
+	UnitDir string        // unit文件目录
+	Runner  SystemdRunner // 为nil时使用systemctl命令
+}
+
+// systemdUnit sing-box的systemd unit
+type systemdUnit struct {
+	name      string // 含.service后缀
+	path      string
+	runner    SystemdRunner
+	mu        sync.Mutex // 串行化unit文件写入
+	installed bool       // 本次运行中是否已确认unit文件为最新并启用，由mu保护
+	following bool       // 是否已开始跟随journal日志，由Manager.mu保护
+}
+
+// unitStatus systemctl show读取的unit状态
+type unitStatus struct {
+	ActiveState  string
+	SubState     string
+	Result       string
+	MainPID      int
+	NRestarts    int
+	ExitStatus   int
+	ControlGroup string
+	StartedAt    time.Time
+	ExitedAt     time.Time
+}
+
+// running 判断unit是否处于运行状态
+func (s unitStatus) running() bool {
+	return s.ActiveState == "active" || s.ActiveState == "reloading"
+}
+
+// SetProcessMode 设置sing-box进程托管方式，需在Start之前调用
+func (m *Manager) SetProcessMode(mode ProcessMode, opts SystemdOptions) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	switch mode {
+	case "", ProcessModeExec:
+		m.unit = nil
+		return nil
+	case ProcessModeSystemd:
+	default:
+		return fmt.Errorf("不支持的进程托管方式: %s", mode)
+	}
+
+	if m.running {
+		return fmt.Errorf("sing-box正以子进程方式运行，无法切换为systemd托管")
+	}
+	if _, err := exec.LookPath("systemctl"); err != nil && opts.Runner == nil {
+		return fmt.Errorf("未找到systemctl: %v", err)
+	}
+
+	name := strings.TrimSuffix(opts.Unit, ".service")
+	if name == "" {
+		name = defaultSystemdUnit
+	}
+	dir := opts.UnitDir
+	if dir == "" {
+		dir = defaultSystemdUnitDir
+	}
+	runner := opts.Runner
+	if runner == nil {
+		runner = execSystemdRunner{}
+	}
+
+	m.unit = &systemdUnit{
+		name:   name + ".service",
+		path:   filepath.Join(dir, name+".service"),
+		runner: runner,
+	}
+	return nil
+}
+
+// ProcessMode 返回当前进程托管方式
+func (m *Manager) ProcessMode() ProcessMode {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if m.unit != nil {
+		return ProcessModeSystemd
+	}
+	return ProcessModeExec
+}
+
+// systemd 返回systemd unit，exec模式下返回nil
+func (m *Manager) systemd() *systemdUnit {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.unit
+}
+
+// startUnit 安装unit并通过systemctl启动，unit已在运行时直接接管
+func (m *Manager) startUnit(unit *systemdUnit) error {
+	if _, err := os.Stat(m.configPath); os.IsNotExist(err) {
+		return fmt.Errorf("配置文件不存在: %s", m.configPath)
+	}
+	if err := m.ensureUnit(unit); err != nil {
+		return err
+	}
+
+	m.followJournal(unit)
+
+	status, err := unit.status()
+	if err != nil {
+		return err
+	}
+	if status.running() {
+		log.Printf("接管已运行的systemd服务 %s, PID: %d", unit.name, status.MainPID)
+	} else {
+		if _, err := unit.runner.Systemctl("start", unit.name); err != nil {
+			return fmt.Errorf("启动sing-box服务失败: %v", err)
+		}
+		log.Printf("已通过systemd启动 %s", unit.name)
+	}
+
+	m.mu.Lock()
+	m.sup.active = true
+	m.mu.Unlock()
+	return nil
+}
+
+// stopUnit 通过systemctl停止unit
+func (m *Manager) stopUnit(unit *systemdUnit) error {
+	m.mu.Lock()
+	m.sup.active = false
+	m.mu.Unlock()
+
+	if _, err := unit.runner.Systemctl("stop", unit.name); err != nil {
+		return fmt.Errorf("停止sing-box服务失败: %v", err)
+	}
+	log.Printf("已通过systemd停止 %s", unit.name)
+	return nil
+}
+
+// restartUnit 通过systemctl重启unit，unit文件有变化时一并生效
+func (m *Manager) restartUnit(unit *systemdUnit) error {
+	if err := m.ensureUnit(unit); err != nil {
+		return err
+	}
+	m.followJournal(unit)
+
+	if _, err := unit.runner.Systemctl("restart", unit.name); err != nil {
+		return fmt.Errorf("重启sing-box服务失败: %v", err)
+	}
+
+	m.mu.Lock()
+	m.sup.active = true
+	m.mu.Unlock()
+	log.Printf("已通过systemd重启 %s", unit.name)
+	return nil
+}
+
+// ensureUnit 渲染unit文件，内容变化时写入并重新加载systemd
+func (m *Manager) ensureUnit(unit *systemdUnit) error {
+	m.mu.RLock()
+	policy := m.sup.policy
+	cg := m.cg
+	m.mu.RUnlock()
+
+	content, err := renderSystemdUnit(m.binaryPath, m.configPath, policy, cg)
+	if err != nil {
+		return err
+	}
+
+	unit.mu.Lock()
+	defer unit.mu.Unlock()
+
+	current, err := os.ReadFile(unit.path)
+	if err == nil && string(current) == content && unit.installed {
+		return nil
+	}
+
+	if err != nil || string(current) != content {
+		if err := os.MkdirAll(filepath.Dir(unit.path), 0755); err != nil {
+			return fmt.Errorf("创建unit目录失败: %v", err)
+		}
+		if err := writeFileSync(unit.path, []byte(content), 0644); err != nil {
+			return fmt.Errorf("写入unit文件失败: %v", err)
+		}
+		if _, err := unit.runner.Systemctl("daemon-reload"); err != nil {
+			return fmt.Errorf("重新加载systemd配置失败: %v", err)
+		}
+		log.Printf("已更新systemd unit: %s", unit.path)
+	}
+
+	if _, err := unit.runner.Systemctl("enable", unit.name); err != nil {
+		return fmt.Errorf("启用sing-box服务失败: %v", err)
+	}
+	unit.installed = true
+	return nil
+}
+
+// followJournal 将unit的journal日志写入日志缓冲区，journalctl退出后自动重连
+func (m *Manager) followJournal(unit *systemdUnit) {
+	m.mu.Lock()
+	if unit.following {
+		m.mu.Unlock()
+		return
+	}
+	unit.following = true
+	m.mu.Unlock()
+
+	go func() {
+		for {
+			reader, err := unit.runner.FollowJournal(unit.name)
+			if err != nil {
+				log.Printf("读取sing-box服务日志失败: %v", err)
+			} else {
+				io.Copy(m.Logs().Writer("journal"), reader)
+				reader.Close()
+			}
+			time.Sleep(journalRetryInterval)
+		}
+	}()
+}
+
+// unitSupervisorStatus 将unit状态转换为监管状态
+func (m *Manager) unitSupervisorStatus(unit *systemdUnit) SupervisorStatus {
+	status, err := unit.status()
+	if err != nil {
+		return SupervisorStatus{
+			State:    StateStopped,
+			LastExit: &ExitRecord{Error: err.Error(), ExitedAt: time.Now()},
+		}
+	}
+
+	result := SupervisorStatus{Restarts: status.NRestarts}
+	switch {
+	case status.running():
+		result.State = StateRunning
+	case status.ActiveState == "activating" && status.SubState == "auto-restart":
+		result.State = StateBackingOff
+	case status.ActiveState == "activating":
+		result.State = StateRunning
+	case status.ActiveState == "failed" && status.Result == "start-limit-hit":
+		result.State = StateCrashLooping
+	default:
+		result.State = StateStopped
+	}
+
+	if !status.ExitedAt.IsZero() && status.ExitedAt.After(status.StartedAt) {
+		record := ExitRecord{
+			ExitCode:  status.ExitStatus,
+			StartedAt: status.StartedAt,
+			ExitedAt:  status.ExitedAt,
+			Uptime:    status.ExitedAt.Sub(status.StartedAt),
+		}
+		if status.Result != "" && status.Result != "success" {
+			record.Error = status.Result
+		}
+		record.OOMKilled = status.Result == "oom-kill"
+		for _, entry := range m.Logs().Tail(stderrTailLines, LogFilter{}) {
+			record.StderrTail = append(record.StderrTail, entry.Message)
+		}
+		result.LastExit = &record
+	}
+	return result
+}
+
+// status 读取unit当前状态
+func (u *systemdUnit) status() (unitStatus, error) {
+	output, err := u.runner.Systemctl("show", u.name,
+		"--property=ActiveState,SubState,Result,MainPID,NRestarts,ExecMainStatus,ExecMainStartTimestamp,ExecMainExitTimestamp,ControlGroup")
+	if err != nil {
+		return unitStatus{}, fmt.Errorf("读取sing-box服务状态失败: %v", err)
+	}
+
+	var status unitStatus
+	for _, line := range strings.Split(output, "\n") {
+		key, value, ok := strings.Cut(strings.TrimSpace(line), "=")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "ActiveState":
+			status.ActiveState = value
+		case "SubState":
+			status.SubState = value
+		case "Result":
+			status.Result = value
+		case "MainPID":
+			status.MainPID, _ = strconv.Atoi(value)
+		case "NRestarts":
+			status.NRestarts, _ = strconv.Atoi(value)
+		case "ExecMainStatus":
+			status.ExitStatus, _ = strconv.Atoi(value)
+		case "ControlGroup":
+			status.ControlGroup = value
+		case "ExecMainStartTimestamp":
+			status.StartedAt, _ = time.Parse(systemdTimeLayout, value)
+		case "ExecMainExitTimestamp":
+			status.ExitedAt, _ = time.Parse(systemdTimeLayout, value)
+		}
+	}
+	return status, nil
+}
+
+// renderSystemdUnit 渲染sing-box的systemd unit文件，重启策略与exec模式的监管策略对应，cg不为nil时写入slice与资源限制
+func renderSystemdUnit(binaryPath, configPath string, policy RestartPolicy, cg *cgroup) (string, error) {
+	binary, err := filepath.Abs(binaryPath)
+	if err != nil {
+		return "", fmt.Errorf("解析sing-box路径失败: %v", err)
+	}
+	config, err := filepath.Abs(configPath)
+	if err != nil {
+		return "", fmt.Errorf("解析配置文件路径失败: %v", err)
+	}
+	if strings.ContainsAny(binary+config, " \t\n") {
+		return "", fmt.Errorf("sing-box路径和配置文件路径不能包含空白字符")
+	}
+
+	restart := "no"
+	if policy.Enabled {
+		restart = "on-failure"
+	}
+	restartSec := int(policy.InitialBackoff / time.Second)
+	if restartSec < 1 {
+		restartSec = 1
+	}
+
+	var b strings.Builder
+	b.WriteString("# 由xbox-agent生成，请勿手动修改\n")
+	b.WriteString("[Unit]\n")
+	b.WriteString("Description=sing-box service (managed by xbox-agent)\n")
+	b.WriteString("Documentation=https://sing-box.sagernet.org\n")
+	b.WriteString("After=network-online.target nss-lookup.target\n")
+	b.WriteString("Wants=network-online.target\n")
+	if policy.MaxRestarts > 0 && policy.Window > 0 {
+		fmt.Fprintf(&b, "StartLimitIntervalSec=%d\n", int(policy.Window/time.Second))
+		fmt.Fprintf(&b, "StartLimitBurst=%d\n", policy.MaxRestarts)
+	} else {
+		b.WriteString("StartLimitIntervalSec=0\n")
+	}
+	b.WriteString("\n[Service]\n")
+	b.WriteString("Type=simple\n")
+	fmt.Fprintf(&b, "WorkingDirectory=%s\n", filepath.Dir(config))
+	fmt.Fprintf(&b, "ExecStart=%s run -c %s\n", binary, config)
+	b.WriteString("ExecReload=/bin/kill -HUP $MAINPID\n")
+	fmt.Fprintf(&b, "Restart=%s\n", restart)
+	fmt.Fprintf(&b, "RestartSec=%d\n", restartSec)
+	// 指数退避需要systemd 254+，旧版本会忽略这两项
+	if steps := backoffSteps(policy); steps > 0 {
+		fmt.Fprintf(&b, "RestartSteps=%d\n", steps)
+		fmt.Fprintf(&b, "RestartMaxDelaySec=%d\n", int(policy.MaxBackoff/time.Second))
+	}
+	if cg != nil {
+		fmt.Fprintf(&b, "Slice=%s\n", cg.slice())
+		b.WriteString("OOMPolicy=stop\n")
+		if cg.limits.MemoryMax > 0 {
+			fmt.Fprintf(&b, "MemoryMax=%d\n", cg.limits.MemoryMax)
+		}
+		if cg.limits.CPUQuota > 0 {
+			fmt.Fprintf(&b, "CPUQuota=%d%%\n", cg.limits.CPUQuota*100/cg.limits.CPUPeriod)
+			if cg.limits.CPUPeriod != defaultCPUPeriod {
+				fmt.Fprintf(&b, "CPUQuotaPeriodSec=%dus\n", cg.limits.CPUPeriod)
+			}
+		}
+		if cg.limits.PidsMax > 0 {
+			fmt.Fprintf(&b, "TasksMax=%d\n", cg.limits.PidsMax)
+		}
+	}
+	b.WriteString("LimitNOFILE=infinity\n")
+	b.WriteString("CapabilityBoundingSet=CAP_NET_ADMIN CAP_NET_BIND_SERVICE CAP_NET_RAW\n")
+	b.WriteString("AmbientCapabilities=CAP_NET_ADMIN CAP_NET_BIND_SERVICE CAP_NET_RAW\n")
+	b.WriteString("\n[Install]\n")
+	b.WriteString("WantedBy=multi-user.target\n")
+	return b.String(), nil
+}
+
+// backoffSteps 计算从首次退避增长到最大退避所需的步数
+func backoffSteps(policy RestartPolicy) int {
+	if policy.Multiplier <= 1 || policy.InitialBackoff <= 0 || policy.MaxBackoff <= policy.InitialBackoff {
+		return 0
+	}
+	ratio := float64(policy.MaxBackoff) / float64(policy.InitialBackoff)
+	return int(math.Ceil(math.Log(ratio) / math.Log(policy.Multiplier)))
+}
+
+// execSystemdRunner 通过systemctl和journalctl命令操作systemd
+type execSystemdRunner struct{}
+
+// Systemctl 执行systemctl命令
+func (execSystemdRunner) Systemctl(args ...string) (string, error) {
+	output, err := exec.Command("systemctl", args...).CombinedOutput()
+	out := strings.TrimSpace(string(output))
+	if err != nil {
+		if out == "" {
+			return "", fmt.Errorf("systemctl %s: %v", strings.Join(args, " "), err)
+		}
+		return out, fmt.Errorf("systemctl %s: %v: %s", strings.Join(args, " "), err, out)
+	}
+	return out, nil
+}
+
+// FollowJournal 通过journalctl -f读取unit日志
+func (execSystemdRunner) FollowJournal(unit string) (io.ReadCloser, error) {
+	cmd := exec.Command("journalctl", "-u", unit, "-f", "-o", "cat", "-n", "0")
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("启动journalctl失败: %v", err)
+	}
+	return &journalReader{Reader: bufio.NewReader(stdout), cmd: cmd}, nil
+}
+
+// journalReader journalctl输出，关闭时结束journalctl进程
+type journalReader struct {
+	io.Reader
+	cmd *exec.Cmd
+}
+
+// Close 结束journalctl进程
+func (r *journalReader) Close() error {
+	if r.cmd.Process != nil {
+		r.cmd.Process.Kill()
+	}
+	return r.cmd.Wait()
+}
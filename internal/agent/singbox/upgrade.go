@@ -0,0 +1,178 @@
+package singbox
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+)
+
+// 二进制升级流水线的阶段名称，重启和健康探测沿用PhaseRestart与PhaseProbe
+const (
+	PhaseInstall = "install" // 并行安装目标版本
+	PhaseCheck   = "check"   // 用目标版本校验当前配置
+	PhaseSwitch  = "switch"  // 替换二进制
+	PhaseRestore = "restore" // 失败后恢复原二进制
+)
+
+// UpgradeResult sing-box版本切换结果
+type UpgradeResult struct {
+	Phases         []PhaseResult `json:"phases"`
+	FromVersion    string        `json:"from_version"`    // 切换前的版本
+	CurrentVersion string        `json:"current_version"` // 流水线结束后实际运行的版本
+	Upgraded       bool          `json:"upgraded"`        // 目标版本是否最终生效
+	RolledBack     bool          `json:"rolled_back"`     // 是否已恢复原二进制
+}
+
+// Err 返回导致切换失败的首个阶段错误
+func (r *UpgradeResult) Err() error {
+	if r.Upgraded {
+		return nil
+	}
+	for _, phase := range r.Phases {
+		if !phase.Success && !phase.Skipped {
+			return fmt.Errorf("%s阶段失败: %s", phase.Phase, phase.Message)
+		}
+	}
+	return fmt.Errorf("版本未切换")
+}
+
+// run 执行一个阶段并记录结果
+func (r *UpgradeResult) run(phase string, fn func() (string, error)) bool {
+	return runPhase(&r.Phases, "sing-box版本切换", phase, fn)
+}
+
+// skip 记录被跳过的阶段
+func (r *UpgradeResult) skip(phase, reason string) {
+	r.Phases = append(r.Phases, PhaseResult{
+		Phase:   phase,
+		Success: true,
+		Skipped: true,
+		Message: reason,
+	})
+}
+
+// Upgrade 将sing-box切换到指定版本（升级或降级）：并行安装目标版本，用其校验当前配置，
+// 替换二进制后重启并健康探测，任一步骤失败时恢复原二进制。source提供制品来源，checksum为空时按来源规则校验
+func (m *Manager) Upgrade(version, checksum string, source InstallOptions) *UpgradeResult {
+	m.applyMu.Lock()
+	defer m.applyMu.Unlock()
+
+	result := &UpgradeResult{}
+
+	binary, err := exec.LookPath(m.binaryPath)
+	if err != nil {
+		result.run(PhaseInstall, func() (string, error) {
+			return "", fmt.Errorf("找不到当前sing-box二进制: %v", err)
+		})
+		return result
+	}
+
+	installer := NewInstaller(filepath.Dir(binary))
+	installer.SetOptions(source)
+	result.FromVersion = installer.inspect(binary)
+	result.CurrentVersion = result.FromVersion
+
+	// 1. 并行安装目标版本，不影响运行中的二进制
+	var staged string
+	if !result.run(PhaseInstall, func() (string, error) {
+		path, err := installer.InstallSideBySide(version, checksum)
+		if err != nil {
+			return "", err
+		}
+		staged = path
+		return fmt.Sprintf("已安装到 %s", path), nil
+	}) {
+		return result
+	}
+	target := installer.inspect(staged)
+
+	// 2. 用目标版本校验当前配置
+	if _, err := os.Stat(m.configPath); err != nil {
+		result.skip(PhaseCheck, "配置文件不存在")
+	} else if !result.run(PhaseCheck, func() (string, error) {
+		return checkConfigWith(staged, m.configPath)
+	}) {
+		return result
+	}
+
+	// 3. 替换二进制，原二进制保留为 .prev 用于恢复
+	backup := binary + ".prev"
+	if !result.run(PhaseSwitch, func() (string, error) {
+		if err := copyBinary(binary, backup); err != nil {
+			return "", fmt.Errorf("备份当前二进制失败: %v", err)
+		}
+		if err := copyBinary(staged, binary); err != nil {
+			return "", fmt.Errorf("替换二进制失败: %v", err)
+		}
+		return fmt.Sprintf("%s -> %s", result.FromVersion, target), nil
+	}) {
+		return result
+	}
+	result.CurrentVersion = target
+
+	// 4. 重启并健康探测（未运行时仅替换二进制）
+	if !m.IsRunning() {
+		result.skip(PhaseRestart, "sing-box未运行")
+		result.skip(PhaseProbe, "sing-box未运行")
+		result.Upgraded = true
+		return result
+	}
+
+	ok := result.run(PhaseRestart, func() (string, error) {
+		if err := m.Restart(); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("PID: %d", m.GetPID()), nil
+	})
+	if ok {
+		ok = result.run(PhaseProbe, func() (string, error) {
+			config := m.GetConfig()
+			if config == nil {
+				config = &Config{}
+			}
+			return m.probeHealth(config, DefaultApplyOptions())
+		})
+	} else {
+		result.skip(PhaseProbe, "重启失败")
+	}
+
+	if ok {
+		result.Upgraded = true
+		return result
+	}
+
+	// 5. 恢复原二进制并重启
+	result.RolledBack = result.run(PhaseRestore, func() (string, error) {
+		if err := copyBinary(backup, binary); err != nil {
+			return "", fmt.Errorf("恢复原二进制失败: %v", err)
+		}
+		if err := m.Restart(); err != nil {
+			return "", fmt.Errorf("以原二进制重启失败: %v", err)
+		}
+		return fmt.Sprintf("已恢复 %s 并重启", result.FromVersion), nil
+	})
+	if result.RolledBack {
+		result.CurrentVersion = result.FromVersion
+	}
+
+	return result
+}
+
+// copyBinary 复制二进制到dst，先写临时文件再原子替换，运行中的进程不受影响
+func copyBinary(src, dst string) error {
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return err
+	}
+	tmp := dst + ".tmp"
+	if err := writeFileSync(tmp, data, 0755); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, dst); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,231 @@
+package singbox
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// 入站用户操作类型
+const (
+	UserOpAdd     = "add"     // 追加用户
+	UserOpRemove  = "remove"  // 删除用户
+	UserOpReplace = "replace" // 替换全部用户
+)
+
+// ErrUsersRevisionMismatch 入站用户已被修改，读改写的调用方需重新获取用户后重试
+var ErrUsersRevisionMismatch = errors.New("入站用户已被修改，请重新获取后重试")
+
+// uuidPattern UUID格式
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// multiUserInboundTypes 支持用户列表的入站类型
+var multiUserInboundTypes = map[string]bool{
+	"mixed":       true,
+	"http":        true,
+	"socks":       true,
+	"vmess":       true,
+	"vless":       true,
+	"trojan":      true,
+	"shadowsocks": true,
+	"hysteria2":   true,
+	"tuic":        true,
+}
+
+// UserKey 返回用户在入站内的标识：mixed/http/socks使用username，其余使用name
+func UserKey(inboundType string, user InboundUser) string {
+	switch inboundType {
+	case "mixed", "http", "socks":
+		return user.Username
+	default:
+		return user.Name
+	}
+}
+
+// ValidateInboundUser 按入站类型校验用户字段
+func ValidateInboundUser(inboundType string, user InboundUser) error {
+	if UserKey(inboundType, user) == "" {
+		if inboundType == "mixed" || inboundType == "http" || inboundType == "socks" {
+			return fmt.Errorf("%s用户缺少username", inboundType)
+		}
+		return fmt.Errorf("%s用户缺少name", inboundType)
+	}
+
+	switch inboundType {
+	case "mixed", "http", "socks", "trojan", "shadowsocks", "hysteria2":
+		if user.Password == "" {
+			return fmt.Errorf("%s用户 %s 缺少password", inboundType, UserKey(inboundType, user))
+		}
+	case "vmess", "vless", "tuic":
+		if !uuidPattern.MatchString(user.UUID) {
+			return fmt.Errorf("%s用户 %s 的uuid格式无效: %q", inboundType, user.Name, user.UUID)
+		}
+		if inboundType == "vless" && user.Flow != "" && user.Flow != "xtls-rprx-vision" {
+			return fmt.Errorf("vless用户 %s 的flow无效: %s", user.Name, user.Flow)
+		}
+		if inboundType == "vmess" && user.AlterID < 0 {
+			return fmt.Errorf("vmess用户 %s 的alterId无效: %d", user.Name, user.AlterID)
+		}
+	default:
+		return fmt.Errorf("入站类型 %s 不支持用户列表", inboundType)
+	}
+	return nil
+}
+
+// findInbound 按tag查找入站
+func findInbound(config *Config, tag string) (*Inbound, error) {
+	for i := range config.Inbounds {
+		if config.Inbounds[i].Tag == tag {
+			return &config.Inbounds[i], nil
+		}
+	}
+	return nil, fmt.Errorf("入站 %s 不存在", tag)
+}
+
+// GetInboundUsers 获取指定入站的类型和用户列表
+func (m *Manager) GetInboundUsers(tag string) (string, []InboundUser, error) {
+	config := m.GetConfig()
+	if config == nil {
+		return "", nil, fmt.Errorf("sing-box配置未加载")
+	}
+
+	inbound, err := findInbound(config, tag)
+	if err != nil {
+		return "", nil, err
+	}
+	return inbound.Type, inbound.Users, nil
+}
+
+// UsersRevision 返回用户列表的修订标识（含未建模字段的内容哈希）
+func UsersRevision(users []InboundUser) string {
+	if len(users) == 0 {
+		users = nil
+	}
+	data, err := json.Marshal(users)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:8])
+}
+
+// UpdateInboundUsers 增删或替换指定入站的用户并应用配置，其余配置保持不变。
+// revision非空时需与入站用户的当前修订一致，否则返回ErrUsersRevisionMismatch
+func (m *Manager) UpdateInboundUsers(tag, operation string, users []InboundUser, revision string) (*ApplyResult, error) {
+	m.usersMu.Lock()
+	defer m.usersMu.Unlock()
+
+	config := m.GetConfig()
+	if config == nil {
+		return nil, fmt.Errorf("sing-box配置未加载")
+	}
+
+	inbound, err := findInbound(config, tag)
+	if err != nil {
+		return nil, err
+	}
+	if !multiUserInboundTypes[inbound.Type] {
+		return nil, fmt.Errorf("入站类型 %s 不支持用户列表", inbound.Type)
+	}
+	if inbound.Type == "shadowsocks" && !strings.HasPrefix(inbound.Method, "2022-") {
+		return nil, fmt.Errorf("shadowsocks仅2022系列加密方法支持多用户，当前: %s", inbound.Method)
+	}
+	if revision != "" && UsersRevision(inbound.Users) != revision {
+		return nil, ErrUsersRevisionMismatch
+	}
+
+	switch operation {
+	case UserOpAdd:
+		updated, err := addInboundUsers(inbound.Type, inbound.Users, users)
+		if err != nil {
+			return nil, err
+		}
+		inbound.Users = updated
+	case UserOpRemove:
+		updated, err := removeInboundUsers(inbound.Type, inbound.Users, users)
+		if err != nil {
+			return nil, err
+		}
+		inbound.Users = updated
+	case UserOpReplace:
+		updated, err := addInboundUsers(inbound.Type, nil, users)
+		if err != nil {
+			return nil, err
+		}
+		inbound.Users = updated
+	default:
+		return nil, fmt.Errorf("不支持的用户操作: %s", operation)
+	}
+
+	opts := DefaultApplyOptions()
+	opts.Source = "users:" + tag
+	result := m.ApplyConfig(config, opts)
+	return result, result.Err()
+}
+
+// addInboundUsers 校验并追加用户，标识或uuid重复时报错
+func addInboundUsers(inboundType string, existing, users []InboundUser) ([]InboundUser, error) {
+	keys := make(map[string]bool, len(existing)+len(users))
+	uuids := make(map[string]bool, len(existing)+len(users))
+	for _, user := range existing {
+		keys[UserKey(inboundType, user)] = true
+		if user.UUID != "" {
+			uuids[strings.ToLower(user.UUID)] = true
+		}
+	}
+
+	result := append([]InboundUser(nil), existing...)
+	for _, user := range users {
+		if err := ValidateInboundUser(inboundType, user); err != nil {
+			return nil, err
+		}
+		key := UserKey(inboundType, user)
+		if keys[key] {
+			return nil, fmt.Errorf("用户 %s 已存在", key)
+		}
+		if user.UUID != "" && uuids[strings.ToLower(user.UUID)] {
+			return nil, fmt.Errorf("uuid %s 已被使用", user.UUID)
+		}
+		keys[key] = true
+		if user.UUID != "" {
+			uuids[strings.ToLower(user.UUID)] = true
+		}
+		result = append(result, user)
+	}
+	return result, nil
+}
+
+// removeInboundUsers 按标识删除用户，请求中携带uuid或password时需同时匹配
+func removeInboundUsers(inboundType string, existing, users []InboundUser) ([]InboundUser, error) {
+	result := append([]InboundUser(nil), existing...)
+	for _, target := range users {
+		key := UserKey(inboundType, target)
+		if key == "" {
+			return nil, fmt.Errorf("删除用户时必须指定name或username")
+		}
+
+		found := false
+		for i, user := range result {
+			if UserKey(inboundType, user) != key {
+				continue
+			}
+			if target.UUID != "" && !strings.EqualFold(target.UUID, user.UUID) {
+				continue
+			}
+			if target.Password != "" && target.Password != user.Password {
+				continue
+			}
+			result = append(result[:i], result[i+1:]...)
+			found = true
+			break
+		}
+		if !found {
+			return nil, fmt.Errorf("用户 %s 不存在", key)
+		}
+	}
+	return result, nil
+}
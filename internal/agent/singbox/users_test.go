@@ -0,0 +1,39 @@
+package singbox
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestUsersRevision(t *testing.T) {
+	var withExtra []InboundUser
+	if err := json.Unmarshal([]byte(`[{"name":"alice","uuid":"u1","x_limit":10}]`), &withExtra); err != nil {
+		t.Fatal(err)
+	}
+	var changedExtra []InboundUser
+	if err := json.Unmarshal([]byte(`[{"name":"alice","uuid":"u1","x_limit":20}]`), &changedExtra); err != nil {
+		t.Fatal(err)
+	}
+	plain := []InboundUser{{Name: "alice", UUID: "u1"}}
+
+	tests := []struct {
+		name  string
+		a, b  []InboundUser
+		equal bool
+	}{
+		{"相同用户", plain, []InboundUser{{Name: "alice", UUID: "u1"}}, true},
+		{"空列表与nil", nil, []InboundUser{}, true},
+		{"凭据不同", plain, []InboundUser{{Name: "alice", UUID: "u2"}}, false},
+		{"顺序不同", []InboundUser{{Name: "a"}, {Name: "b"}}, []InboundUser{{Name: "b"}, {Name: "a"}}, false},
+		{"未建模字段不同", withExtra, changedExtra, false},
+		{"新增未建模字段", plain, withExtra, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, b := UsersRevision(tt.a), UsersRevision(tt.b)
+			if a == "" || (a == b) != tt.equal {
+				t.Errorf("UsersRevision() = %s, %s, equal want %v", a, b, tt.equal)
+			}
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package singbox
+
+// SetV2RayAPIListen 设置自动启用的V2Ray API统计服务监听地址，为空时不注入
+func (m *Manager) SetV2RayAPIListen(listen string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.v2rayAPIListen = listen
+}
+
+// ensureV2RayStats 启用V2Ray API统计服务，并按当前配置重建入站与入站用户的统计范围，
+// 已删除的入站和用户不会随读改写的配置残留在统计列表中
+func (m *Manager) ensureV2RayStats(config *Config) {
+	m.mu.RLock()
+	listen := m.v2rayAPIListen
+	m.mu.RUnlock()
+
+	if listen == "" {
+		return
+	}
+
+	if config.Experimental == nil {
+		config.Experimental = &ExperimentalConfig{}
+	}
+	if config.Experimental.V2RayAPI == nil {
+		config.Experimental.V2RayAPI = &V2RayAPIConfig{}
+	}
+	api := config.Experimental.V2RayAPI
+	if api.Listen == "" {
+		api.Listen = listen
+	}
+	if api.Stats == nil {
+		api.Stats = &V2RayAPIStatsConfig{}
+	}
+	api.Stats.Enabled = true
+	api.Stats.Inbounds = nil
+	api.Stats.Users = nil
+
+	for _, inbound := range config.Inbounds {
+		// 出站探测流量不计入用户和入站统计
+		if inbound.Tag == ProbeInboundTag {
+			continue
+		}
+		if inbound.Tag != "" {
+			api.Stats.Inbounds = appendUnique(api.Stats.Inbounds, inbound.Tag)
+		}
+		if !multiUserInboundTypes[inbound.Type] {
+			continue
+		}
+		for _, user := range inbound.Users {
+			if key := UserKey(inbound.Type, user); key != "" {
+				api.Stats.Users = appendUnique(api.Stats.Users, key)
+			}
+		}
+	}
+}
+
+// V2RayAPIEndpoint 返回当前配置中已启用统计的V2Ray API地址
+func (m *Manager) V2RayAPIEndpoint() (string, bool) {
+	m.mu.RLock()
+	config := m.lastConfig
+	m.mu.RUnlock()
+
+	if config == nil {
+		loaded, err := m.LoadConfigFromFile()
+		if err != nil {
+			return "", false
+		}
+		config = loaded
+	}
+	if config.Experimental == nil || config.Experimental.V2RayAPI == nil {
+		return "", false
+	}
+	api := config.Experimental.V2RayAPI
+	if api.Listen == "" || api.Stats == nil || !api.Stats.Enabled {
+		return "", false
+	}
+	return api.Listen, true
+}
+
+// appendUnique 追加不存在的元素
+func appendUnique(list []string, value string) []string {
+	if containsString(list, value) {
+		return list
+	}
+	return append(list, value)
+}
@@ -0,0 +1,45 @@
+package singbox
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestEnsureV2RayStats(t *testing.T) {
+	m := &Manager{v2rayAPIListen: "127.0.0.1:18080"}
+	config := &Config{
+		Inbounds: []Inbound{
+			{Type: "vless", Tag: "vless-in", Users: []InboundUser{{Name: "alice"}, {Name: "bob"}}},
+			{Type: "socks", Tag: "socks-in", Users: []InboundUser{{Username: "carol"}, {Username: "alice"}}},
+			{Type: "mixed", Tag: ProbeInboundTag},
+		},
+	}
+
+	m.ensureV2RayStats(config)
+	stats := config.Experimental.V2RayAPI.Stats
+	if !stats.Enabled || config.Experimental.V2RayAPI.Listen != "127.0.0.1:18080" {
+		t.Fatalf("统计服务未启用: %+v", config.Experimental.V2RayAPI)
+	}
+	if want := []string{"vless-in", "socks-in"}; !reflect.DeepEqual(stats.Inbounds, want) {
+		t.Errorf("Inbounds = %v, want %v", stats.Inbounds, want)
+	}
+	if want := []string{"alice", "bob", "carol"}; !reflect.DeepEqual(stats.Users, want) {
+		t.Errorf("Users = %v, want %v", stats.Users, want)
+	}
+
+	// 删除入站和用户后重新应用，统计范围随之收缩
+	config.Inbounds = []Inbound{
+		{Type: "vless", Tag: "vless-in", Users: []InboundUser{{Name: "bob"}}},
+	}
+	stats.Outbounds = []string{"proxy"}
+	m.ensureV2RayStats(config)
+	if want := []string{"vless-in"}; !reflect.DeepEqual(stats.Inbounds, want) {
+		t.Errorf("Inbounds = %v, want %v", stats.Inbounds, want)
+	}
+	if want := []string{"bob"}; !reflect.DeepEqual(stats.Users, want) {
+		t.Errorf("Users = %v, want %v", stats.Users, want)
+	}
+	if want := []string{"proxy"}; !reflect.DeepEqual(stats.Outbounds, want) {
+		t.Errorf("Outbounds = %v, want %v", stats.Outbounds, want)
+	}
+}
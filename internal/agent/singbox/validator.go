@@ -0,0 +1,543 @@
+package singbox
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/netip"
+	"strconv"
+	"strings"
+)
+
+// ValidationError 带路径的配置校验错误，路径形如 route.rules[2].outbound
+type ValidationError struct {
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+// Error 实现error接口
+func (e ValidationError) Error() string {
+	if e.Path == "" {
+		return e.Message
+	}
+	return e.Path + ": " + e.Message
+}
+
+// ValidationErrors 配置校验错误列表
+type ValidationErrors []ValidationError
+
+// Error 实现error接口
+func (e ValidationErrors) Error() string {
+	messages := make([]string, 0, len(e))
+	for _, err := range e {
+		messages = append(messages, err.Error())
+	}
+	return fmt.Sprintf("配置校验失败(%d处): %s", len(e), strings.Join(messages, "; "))
+}
+
+// 组出站类型，其成员列表在outbounds字段中
+var groupOutboundTypes = map[string]bool{
+	"selector": true,
+	"urltest":  true,
+}
+
+// validator 配置校验上下文
+type validator struct {
+	config     *Config
+	errs       ValidationErrors
+	inbounds   map[string]bool
+	outbounds  map[string]bool
+	dnsServers map[string]bool
+	ruleSets   map[string]bool
+}
+
+// Validate 在交给sing-box check之前对配置做语义校验，返回全部问题，无问题时返回nil
+func Validate(config *Config) ValidationErrors {
+	if config == nil {
+		return ValidationErrors{{Message: "配置为空"}}
+	}
+
+	v := &validator{
+		config:     config,
+		inbounds:   make(map[string]bool),
+		outbounds:  make(map[string]bool),
+		dnsServers: make(map[string]bool),
+		ruleSets:   make(map[string]bool),
+	}
+
+	v.collectTags()
+	v.checkInbounds()
+	v.checkOutbounds()
+	v.checkDetourCycles()
+	v.checkRoute()
+	v.checkDNS()
+
+	if len(v.errs) == 0 {
+		return nil
+	}
+	return v.errs
+}
+
+// addf 记录一条校验错误
+func (v *validator) addf(path, format string, args ...interface{}) {
+	v.errs = append(v.errs, ValidationError{Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+// collectTags 收集各类tag并检查重复
+func (v *validator) collectTags() {
+	for i, inbound := range v.config.Inbounds {
+		path := fmt.Sprintf("inbounds[%d].tag", i)
+		if inbound.Tag == "" {
+			continue
+		}
+		if v.inbounds[inbound.Tag] {
+			v.addf(path, "入站tag重复: %s", inbound.Tag)
+		}
+		v.inbounds[inbound.Tag] = true
+	}
+
+	for i, outbound := range v.config.Outbounds {
+		path := fmt.Sprintf("outbounds[%d].tag", i)
+		if outbound.Tag == "" {
+			continue
+		}
+		if v.outbounds[outbound.Tag] {
+			v.addf(path, "出站tag重复: %s", outbound.Tag)
+		}
+		v.outbounds[outbound.Tag] = true
+	}
+
+	if dns := v.config.DNS; dns != nil {
+		for i, server := range dns.Servers {
+			path := fmt.Sprintf("dns.servers[%d].tag", i)
+			if server.Tag == "" {
+				continue
+			}
+			if v.dnsServers[server.Tag] {
+				v.addf(path, "DNS服务器tag重复: %s", server.Tag)
+			}
+			v.dnsServers[server.Tag] = true
+		}
+	}
+
+	if route := v.config.Route; route != nil {
+		for i, tag := range rawObjectTags(route.Extra, "rule_set") {
+			if tag == "" {
+				continue
+			}
+			if v.ruleSets[tag] {
+				v.addf(fmt.Sprintf("route.rule_set[%d].tag", i), "规则集tag重复: %s", tag)
+			}
+			v.ruleSets[tag] = true
+		}
+	}
+}
+
+// checkInbounds 检查监听地址、端口冲突与TLS
+func (v *validator) checkInbounds() {
+	type listener struct {
+		index    int
+		listen   string
+		networks []string
+	}
+	byPort := make(map[uint16][]listener)
+
+	for i, inbound := range v.config.Inbounds {
+		path := fmt.Sprintf("inbounds[%d]", i)
+		if inbound.Type == "" {
+			v.addf(path+".type", "入站缺少type")
+		}
+
+		if inbound.Listen != "" {
+			if _, err := netip.ParseAddr(inbound.Listen); err != nil {
+				v.addf(path+".listen", "监听地址无效: %s", inbound.Listen)
+			}
+		}
+
+		networks := inboundNetworks(inbound)
+		if len(networks) > 0 {
+			if inbound.ListenPort == 0 {
+				v.addf(path+".listen_port", "%s入站缺少listen_port", inbound.Type)
+			} else {
+				for _, other := range byPort[inbound.ListenPort] {
+					if listenOverlaps(inbound.Listen, other.listen) && networksOverlap(networks, other.networks) {
+						v.addf(path+".listen_port", "监听端口 %d 与 inbounds[%d] 冲突", inbound.ListenPort, other.index)
+						break
+					}
+				}
+				byPort[inbound.ListenPort] = append(byPort[inbound.ListenPort], listener{index: i, listen: inbound.Listen, networks: networks})
+			}
+		}
+
+		v.checkInboundTLS(path+".tls", inbound.TLS)
+	}
+}
+
+// checkInboundTLS 检查启用TLS时是否提供了证书、ACME或Reality
+func (v *validator) checkInboundTLS(path string, tls *InboundTLS) {
+	if tls == nil || !tls.Enabled {
+		return
+	}
+
+	if tls.Reality != nil && tls.Reality.Enabled {
+		if tls.Reality.PrivateKey == "" {
+			v.addf(path+".reality.private_key", "启用Reality但未配置private_key")
+		}
+		if tls.Reality.Handshake == nil || tls.Reality.Handshake.Server == "" {
+			v.addf(path+".reality.handshake.server", "启用Reality但未配置握手服务器")
+		}
+		return
+	}
+
+	if tls.ACME != nil && len(tls.ACME.Domain) > 0 {
+		return
+	}
+	if tls.ACME != nil {
+		v.addf(path+".acme.domain", "启用ACME但未配置域名")
+		return
+	}
+
+	if len(tls.Certificate) == 0 && tls.CertificatePath == "" {
+		v.addf(path, "启用TLS但未配置证书(certificate/certificate_path)或ACME")
+	}
+	if len(tls.Key) == 0 && tls.KeyPath == "" {
+		v.addf(path, "启用TLS但未配置私钥(key/key_path)或ACME")
+	}
+}
+
+// checkOutbounds 检查出站引用与组成员
+func (v *validator) checkOutbounds() {
+	for i, outbound := range v.config.Outbounds {
+		path := fmt.Sprintf("outbounds[%d]", i)
+		if outbound.Type == "" {
+			v.addf(path+".type", "出站缺少type")
+		}
+		if outbound.Detour != "" && !v.outbounds[outbound.Detour] {
+			v.addf(path+".detour", "引用的出站不存在: %s", outbound.Detour)
+		}
+		for _, cidr := range outbound.LocalAddress {
+			if _, err := netip.ParsePrefix(cidr); err != nil {
+				v.addf(path+".local_address", "CIDR无效: %s", cidr)
+			}
+		}
+
+		if !groupOutboundTypes[outbound.Type] {
+			continue
+		}
+		members := outbound.Outbounds
+		if len(members) == 0 {
+			v.addf(path+".outbounds", "%s出站缺少成员", outbound.Type)
+		}
+		for j, member := range members {
+			if !v.outbounds[member] {
+				v.addf(fmt.Sprintf("%s.outbounds[%d]", path, j), "引用的出站不存在: %s", member)
+			}
+		}
+		if outbound.Default != "" && !containsString(members, outbound.Default) {
+			v.addf(path+".default", "默认出站 %s 不在成员列表中", outbound.Default)
+		}
+	}
+}
+
+// checkDetourCycles 检查detour与组成员构成的引用环
+func (v *validator) checkDetourCycles() {
+	edges := make(map[string][]string)
+	index := make(map[string]int)
+	for i, outbound := range v.config.Outbounds {
+		if _, ok := index[outbound.Tag]; !ok {
+			index[outbound.Tag] = i
+		}
+		if outbound.Detour != "" {
+			edges[outbound.Tag] = append(edges[outbound.Tag], outbound.Detour)
+		}
+		if groupOutboundTypes[outbound.Type] {
+			edges[outbound.Tag] = append(edges[outbound.Tag], outbound.Outbounds...)
+		}
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		done
+	)
+	state := make(map[string]int)
+	var stack []string
+
+	var visit func(tag string)
+	visit = func(tag string) {
+		state[tag] = visiting
+		stack = append(stack, tag)
+		for _, next := range edges[tag] {
+			switch state[next] {
+			case visiting:
+				start := 0
+				for i, t := range stack {
+					if t == next {
+						start = i
+						break
+					}
+				}
+				cycle := append(append([]string(nil), stack[start:]...), next)
+				v.addf(fmt.Sprintf("outbounds[%d]", index[tag]), "出站引用成环: %s", strings.Join(cycle, " -> "))
+			case unvisited:
+				if _, ok := index[next]; ok {
+					visit(next)
+				}
+			}
+		}
+		stack = stack[:len(stack)-1]
+		state[tag] = done
+	}
+
+	for _, outbound := range v.config.Outbounds {
+		if state[outbound.Tag] == unvisited {
+			visit(outbound.Tag)
+		}
+	}
+}
+
+// checkRoute 检查路由规则引用、CIDR与端口
+func (v *validator) checkRoute() {
+	route := v.config.Route
+	if route == nil {
+		return
+	}
+
+	if route.Final != "" && !v.outbounds[route.Final] {
+		v.addf("route.final", "引用的出站不存在: %s", route.Final)
+	}
+	if route.GeoIP != nil && route.GeoIP.DownloadDetour != "" && !v.outbounds[route.GeoIP.DownloadDetour] {
+		v.addf("route.geoip.download_detour", "引用的出站不存在: %s", route.GeoIP.DownloadDetour)
+	}
+	if route.Geosite != nil && route.Geosite.DownloadDetour != "" && !v.outbounds[route.Geosite.DownloadDetour] {
+		v.addf("route.geosite.download_detour", "引用的出站不存在: %s", route.Geosite.DownloadDetour)
+	}
+
+	for i, rule := range route.Rules {
+		path := fmt.Sprintf("route.rules[%d]", i)
+		// 使用action的规则没有outbound字段
+		if rule.Outbound != "" && !v.outbounds[rule.Outbound] {
+			v.addf(path+".outbound", "引用的出站不存在: %s", rule.Outbound)
+		}
+		v.checkInboundRefs(path+".inbound", rule.Inbound)
+		v.checkRuleSetRefs(path+".rule_set", rule.RuleSet)
+		v.checkCIDRs(path+".ip_cidr", rule.IP)
+		v.checkCIDRs(path+".source_ip_cidr", rule.SourceIP)
+		v.checkPorts(path+".port", rule.Port)
+		v.checkPorts(path+".source_port", rule.SourcePort)
+		v.checkPortRanges(path+".port_range", rule.PortRange)
+		v.checkPortRanges(path+".source_port_range", rule.SourcePortRange)
+	}
+}
+
+// checkDNS 检查DNS规则与服务器引用
+func (v *validator) checkDNS() {
+	dns := v.config.DNS
+	if dns == nil {
+		return
+	}
+
+	if dns.Final != "" && !v.dnsServers[dns.Final] {
+		v.addf("dns.final", "引用的DNS服务器不存在: %s", dns.Final)
+	}
+
+	for i, server := range dns.Servers {
+		path := fmt.Sprintf("dns.servers[%d]", i)
+		if server.Detour != "" && !v.outbounds[server.Detour] {
+			v.addf(path+".detour", "引用的出站不存在: %s", server.Detour)
+		}
+		if server.AddressResolver != "" && !v.dnsServers[server.AddressResolver] {
+			v.addf(path+".address_resolver", "引用的DNS服务器不存在: %s", server.AddressResolver)
+		}
+		if server.AddressResolver != "" && server.AddressResolver == server.Tag {
+			v.addf(path+".address_resolver", "DNS服务器不能解析自身地址")
+		}
+		v.checkClientSubnet(path+".client_subnet", server.ClientSubnet)
+	}
+
+	for i, rule := range dns.Rules {
+		path := fmt.Sprintf("dns.rules[%d]", i)
+		// 使用action的规则可能没有server字段
+		if rule.Server != "" && !v.dnsServers[rule.Server] {
+			v.addf(path+".server", "引用的DNS服务器不存在: %s", rule.Server)
+		}
+		v.checkInboundRefs(path+".inbound", rule.Inbound)
+		v.checkCIDRs(path+".ip_cidr", rule.IP)
+		v.checkCIDRs(path+".source_ip_cidr", rule.SourceIP)
+		v.checkPorts(path+".port", rule.Port)
+		v.checkPorts(path+".source_port", rule.SourcePort)
+		v.checkClientSubnet(path+".client_subnet", rule.ClientSubnet)
+	}
+
+	if fakeIP := dns.FakeIP; fakeIP != nil && fakeIP.Enabled {
+		if fakeIP.Inet4Range != "" {
+			if prefix, err := netip.ParsePrefix(fakeIP.Inet4Range); err != nil || !prefix.Addr().Is4() {
+				v.addf("dns.fakeip.inet4_range", "IPv4 CIDR无效: %s", fakeIP.Inet4Range)
+			}
+		}
+		if fakeIP.Inet6Range != "" {
+			if prefix, err := netip.ParsePrefix(fakeIP.Inet6Range); err != nil || !prefix.Addr().Is6() {
+				v.addf("dns.fakeip.inet6_range", "IPv6 CIDR无效: %s", fakeIP.Inet6Range)
+			}
+		}
+	}
+}
+
+// checkInboundRefs 检查规则引用的入站是否存在
+func (v *validator) checkInboundRefs(path string, tags []string) {
+	for i, tag := range tags {
+		if !v.inbounds[tag] {
+			v.addf(fmt.Sprintf("%s[%d]", path, i), "引用的入站不存在: %s", tag)
+		}
+	}
+}
+
+// checkRuleSetRefs 检查规则引用的规则集是否存在
+func (v *validator) checkRuleSetRefs(path string, tags []string) {
+	for i, tag := range tags {
+		if !v.ruleSets[tag] {
+			v.addf(fmt.Sprintf("%s[%d]", path, i), "引用的规则集不存在: %s", tag)
+		}
+	}
+}
+
+// checkCIDRs 检查CIDR列表，兼容不带前缀长度的单个IP
+func (v *validator) checkCIDRs(path string, cidrs []string) {
+	for i, cidr := range cidrs {
+		if _, err := netip.ParsePrefix(cidr); err == nil {
+			continue
+		}
+		if _, err := netip.ParseAddr(cidr); err == nil {
+			continue
+		}
+		v.addf(fmt.Sprintf("%s[%d]", path, i), "CIDR无效: %s", cidr)
+	}
+}
+
+// checkClientSubnet 检查EDNS client subnet，允许前缀或单个IP
+func (v *validator) checkClientSubnet(path, subnet string) {
+	if subnet == "" {
+		return
+	}
+	v.checkCIDRs(path, []string{subnet})
+}
+
+// checkPorts 检查端口列表
+func (v *validator) checkPorts(path string, ports []string) {
+	for i, port := range ports {
+		if _, ok := parsePort(port); !ok {
+			v.addf(fmt.Sprintf("%s[%d]", path, i), "端口无效: %s", port)
+		}
+	}
+}
+
+// checkPortRanges 检查端口范围列表，格式为 start:end、:end 或 start:
+func (v *validator) checkPortRanges(path string, ranges []string) {
+	for i, r := range ranges {
+		elemPath := fmt.Sprintf("%s[%d]", path, i)
+		start, end, found := strings.Cut(r, ":")
+		if !found || (start == "" && end == "") {
+			v.addf(elemPath, "端口范围格式无效: %s", r)
+			continue
+		}
+		low, high := uint16(0), uint16(65535)
+		ok := true
+		if start != "" {
+			low, ok = parsePort(start)
+		}
+		if ok && end != "" {
+			high, ok = parsePort(end)
+		}
+		if !ok {
+			v.addf(elemPath, "端口范围无效: %s", r)
+		} else if low > high {
+			v.addf(elemPath, "端口范围起点大于终点: %s", r)
+		}
+	}
+}
+
+// parsePort 解析端口号
+func parsePort(s string) (uint16, bool) {
+	port, err := strconv.ParseUint(strings.TrimSpace(s), 10, 16)
+	if err != nil {
+		return 0, false
+	}
+	return uint16(port), true
+}
+
+// inboundNetworks 返回入站监听的网络，不监听端口的入站返回nil
+func inboundNetworks(inbound Inbound) []string {
+	switch inbound.Type {
+	case "tun":
+		return nil
+	case "shadowsocks", "direct":
+		switch inbound.Network {
+		case "tcp":
+			return []string{"tcp"}
+		case "udp":
+			return []string{"udp"}
+		default:
+			return []string{"tcp", "udp"}
+		}
+	}
+	if !inboundUsesTCP(inbound.Type) {
+		return []string{"udp"}
+	}
+	return []string{"tcp"}
+}
+
+// listenOverlaps 判断两个监听地址是否会争用同一端口
+func listenOverlaps(a, b string) bool {
+	if isWildcardListen(a) || isWildcardListen(b) {
+		return true
+	}
+	addrA, errA := netip.ParseAddr(a)
+	addrB, errB := netip.ParseAddr(b)
+	if errA != nil || errB != nil {
+		return a == b
+	}
+	return addrA.Unmap() == addrB.Unmap()
+}
+
+// isWildcardListen 判断是否为通配监听地址
+func isWildcardListen(listen string) bool {
+	return listen == "" || listen == "0.0.0.0" || listen == "::"
+}
+
+// networksOverlap 判断两个网络列表是否有交集
+func networksOverlap(a, b []string) bool {
+	for _, x := range a {
+		if containsString(b, x) {
+			return true
+		}
+	}
+	return false
+}
+
+// containsString 判断字符串切片是否包含指定值
+func containsString(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+// rawObjectTags 读取未建模字段中对象数组的tag
+func rawObjectTags(extra RawFields, key string) []string {
+	raw, ok := extra.Get(key)
+	if !ok {
+		return nil
+	}
+	var items []struct {
+		Tag string `json:"tag"`
+	}
+	if err := json.Unmarshal(raw, &items); err != nil {
+		return nil
+	}
+	tags := make([]string, 0, len(items))
+	for _, item := range items {
+		tags = append(tags, item.Tag)
+	}
+	return tags
+}
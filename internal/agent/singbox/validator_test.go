@@ -0,0 +1,200 @@
+package singbox
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name      string
+		config    string
+		wantPaths []string // 期望的错误路径，为空时期望校验通过
+		wantMsg   string   // 期望第一条错误包含的内容
+	}{
+		{
+			name: "有效配置",
+			config: `{
+				"inbounds": [
+					{"type": "vless", "tag": "vless-in", "listen": "::", "listen_port": 443,
+					 "tls": {"enabled": true, "reality": {"enabled": true, "private_key": "k", "handshake": {"server": "www.microsoft.com", "server_port": 443}}}},
+					{"type": "shadowsocks", "tag": "ss-in", "listen_port": 8388, "method": "2022-blake3-aes-128-gcm", "password": "p"},
+					{"type": "tun", "tag": "tun-in"}
+				],
+				"outbounds": [
+					{"type": "direct", "tag": "direct"},
+					{"type": "vless", "tag": "relay", "detour": "direct"},
+					{"type": "selector", "tag": "proxy", "outbounds": ["relay", "direct"], "default": "relay"}
+				],
+				"route": {
+					"rule_set": [{"tag": "geosite-cn", "type": "remote", "format": "binary", "url": "https://example.com/cn.srs"}],
+					"rules": [
+						{"inbound": ["vless-in"], "rule_set": ["geosite-cn"], "outbound": "direct"},
+						{"ip_cidr": ["10.0.0.0/8", "192.168.1.1"], "port": ["443"], "port_range": ["1000:2000", ":100", "60000:"], "outbound": "proxy"},
+						{"action": "sniff"}
+					],
+					"final": "proxy"
+				},
+				"dns": {
+					"servers": [
+						{"tag": "local", "address": "223.5.5.5", "detour": "direct"},
+						{"tag": "remote", "address": "tls://dns.google", "address_resolver": "local", "client_subnet": "1.2.3.0/24"}
+					],
+					"rules": [{"inbound": ["vless-in"], "server": "remote"}],
+					"final": "remote",
+					"fakeip": {"enabled": true, "inet4_range": "198.18.0.0/15", "inet6_range": "fc00::/18"}
+				}
+			}`,
+		},
+		{
+			name:      "入站缺少type和端口",
+			config:    `{"inbounds": [{"tag": "a"}, {"type": "vmess", "tag": "b"}]}`,
+			wantPaths: []string{"inbounds[0].listen_port", "inbounds[0].type", "inbounds[1].listen_port"},
+		},
+		{
+			name:      "tag重复",
+			config:    `{"inbounds": [{"type": "socks", "tag": "in", "listen_port": 1080}, {"type": "http", "tag": "in", "listen_port": 8080}], "outbounds": [{"type": "direct", "tag": "out"}, {"type": "block", "tag": "out"}]}`,
+			wantPaths: []string{"inbounds[1].tag", "outbounds[1].tag"},
+			wantMsg:   "入站tag重复: in",
+		},
+		{
+			name:      "监听地址无效",
+			config:    `{"inbounds": [{"type": "socks", "tag": "in", "listen": "localhost", "listen_port": 1080}]}`,
+			wantPaths: []string{"inbounds[0].listen"},
+		},
+		{
+			name:      "通配地址与具体地址端口冲突",
+			config:    `{"inbounds": [{"type": "socks", "tag": "a", "listen": "0.0.0.0", "listen_port": 1080}, {"type": "http", "tag": "b", "listen": "127.0.0.1", "listen_port": 1080}]}`,
+			wantPaths: []string{"inbounds[1].listen_port"},
+			wantMsg:   "监听端口 1080 与 inbounds[0] 冲突",
+		},
+		{
+			name:   "TCP与UDP入站可共用端口",
+			config: `{"inbounds": [{"type": "trojan", "tag": "a", "listen_port": 443}, {"type": "hysteria2", "tag": "b", "listen_port": 443}]}`,
+		},
+		{
+			name:   "不同地址可共用端口",
+			config: `{"inbounds": [{"type": "socks", "tag": "a", "listen": "127.0.0.1", "listen_port": 1080}, {"type": "socks", "tag": "b", "listen": "127.0.0.2", "listen_port": 1080}]}`,
+		},
+		{
+			name:      "TLS缺少证书和私钥",
+			config:    `{"inbounds": [{"type": "trojan", "tag": "a", "listen_port": 443, "tls": {"enabled": true}}]}`,
+			wantPaths: []string{"inbounds[0].tls", "inbounds[0].tls"},
+		},
+		{
+			name:      "ACME缺少域名",
+			config:    `{"inbounds": [{"type": "trojan", "tag": "a", "listen_port": 443, "tls": {"enabled": true, "acme": {}}}]}`,
+			wantPaths: []string{"inbounds[0].tls.acme.domain"},
+		},
+		{
+			name:      "Reality缺少私钥和握手服务器",
+			config:    `{"inbounds": [{"type": "vless", "tag": "a", "listen_port": 443, "tls": {"enabled": true, "reality": {"enabled": true}}}]}`,
+			wantPaths: []string{"inbounds[0].tls.reality.handshake.server", "inbounds[0].tls.reality.private_key"},
+		},
+		{
+			name:      "未启用的TLS不检查",
+			config:    `{"inbounds": [{"type": "trojan", "tag": "a", "listen_port": 443, "tls": {"enabled": false}}]}`,
+			wantPaths: nil,
+		},
+		{
+			name:      "出站引用不存在",
+			config:    `{"outbounds": [{"type": "vless", "tag": "a", "detour": "missing", "local_address": ["bad"]}, {"type": "urltest", "tag": "auto", "outbounds": ["a", "ghost"]}, {"type": "selector", "tag": "sel", "outbounds": ["a"], "default": "auto"}, {"type": "selector", "tag": "empty"}]}`,
+			wantPaths: []string{"outbounds[0].detour", "outbounds[0].local_address", "outbounds[1].outbounds[1]", "outbounds[2].default", "outbounds[3].outbounds"},
+		},
+		{
+			name:      "detour成环",
+			config:    `{"outbounds": [{"type": "vless", "tag": "a", "detour": "b"}, {"type": "vless", "tag": "b", "detour": "a"}]}`,
+			wantPaths: []string{"outbounds[1]"},
+			wantMsg:   "出站引用成环: a -> b -> a",
+		},
+		{
+			name:      "组成员成环",
+			config:    `{"outbounds": [{"type": "selector", "tag": "g", "outbounds": ["g2"]}, {"type": "selector", "tag": "g2", "outbounds": ["g"]}]}`,
+			wantPaths: []string{"outbounds[1]"},
+		},
+		{
+			name: "路由规则引用与格式",
+			config: `{"outbounds": [{"type": "direct", "tag": "direct"}], "route": {
+				"final": "missing",
+				"rules": [
+					{"outbound": "ghost", "inbound": ["no-in"], "rule_set": ["no-set"]},
+					{"outbound": "direct", "ip_cidr": ["10.0.0.0/33"], "source_ip_cidr": ["x"], "port": ["70000"], "source_port": ["a"]},
+					{"outbound": "direct", "port_range": ["2000:1000", "1000", ":", "a:b"]}
+				]}}`,
+			wantPaths: []string{
+				"route.final",
+				"route.rules[0].inbound[0]", "route.rules[0].outbound", "route.rules[0].rule_set[0]",
+				"route.rules[1].ip_cidr[0]", "route.rules[1].port[0]", "route.rules[1].source_ip_cidr[0]", "route.rules[1].source_port[0]",
+				"route.rules[2].port_range[0]", "route.rules[2].port_range[1]", "route.rules[2].port_range[2]", "route.rules[2].port_range[3]",
+			},
+		},
+		{
+			name:      "规则集tag重复",
+			config:    `{"route": {"rule_set": [{"tag": "a", "type": "local", "path": "a.srs"}, {"tag": "a", "type": "local", "path": "b.srs"}]}}`,
+			wantPaths: []string{"route.rule_set[1].tag"},
+		},
+		{
+			name: "DNS引用与FakeIP",
+			config: `{"dns": {
+				"servers": [
+					{"tag": "a", "address": "1.1.1.1", "detour": "ghost", "address_resolver": "a"},
+					{"tag": "a", "address": "8.8.8.8", "address_resolver": "none", "client_subnet": "bad"}
+				],
+				"rules": [{"server": "none"}],
+				"final": "none",
+				"fakeip": {"enabled": true, "inet4_range": "fc00::/18", "inet6_range": "198.18.0.0/15"}
+			}}`,
+			wantPaths: []string{
+				"dns.fakeip.inet4_range", "dns.fakeip.inet6_range", "dns.final",
+				"dns.rules[0].server",
+				"dns.servers[0].address_resolver", "dns.servers[0].detour",
+				"dns.servers[1].address_resolver", "dns.servers[1].client_subnet[0]", "dns.servers[1].tag",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var config Config
+			if err := json.Unmarshal([]byte(tt.config), &config); err != nil {
+				t.Fatalf("解析测试配置失败: %v", err)
+			}
+
+			errs := Validate(&config)
+			var paths []string
+			for _, err := range errs {
+				paths = append(paths, err.Path)
+			}
+			sort.Strings(paths)
+			want := append([]string(nil), tt.wantPaths...)
+			sort.Strings(want)
+			if !reflect.DeepEqual(paths, want) {
+				t.Fatalf("错误路径 = %v, want %v\n%v", paths, want, errs)
+			}
+			if tt.wantMsg != "" && !strings.Contains(errs[0].Message, tt.wantMsg) {
+				t.Errorf("错误信息 = %q, want 包含 %q", errs[0].Message, tt.wantMsg)
+			}
+		})
+	}
+}
+
+func TestValidateNil(t *testing.T) {
+	errs := Validate(nil)
+	if len(errs) != 1 || errs[0].Message != "配置为空" {
+		t.Errorf("Validate(nil) = %v", errs)
+	}
+}
+
+func TestValidationErrorsError(t *testing.T) {
+	errs := ValidationErrors{
+		{Path: "route.final", Message: "引用的出站不存在: x"},
+		{Message: "配置为空"},
+	}
+	want := "配置校验失败(2处): route.final: 引用的出站不存在: x; 配置为空"
+	if got := errs.Error(); got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+}
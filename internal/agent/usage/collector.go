@@ -0,0 +1,198 @@
+package usage
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	statspb "github.com/xbox/sing-box-manager/proto/v2raystats"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// 统计对象类型
+const (
+	ScopeUser     = "user"
+	ScopeInbound  = "inbound"
+	ScopeOutbound = "outbound"
+)
+
+// Record 一个统计对象的流量增量
+type Record struct {
+	Scope    string `json:"scope"`
+	Name     string `json:"name"`
+	Uplink   int64  `json:"uplink"`
+	Downlink int64  `json:"downlink"`
+}
+
+// Batch 待上报的流量增量
+type Batch struct {
+	Start   time.Time
+	End     time.Time
+	Records []Record
+}
+
+// recordKey 统计对象标识
+type recordKey struct {
+	scope string
+	name  string
+}
+
+// Collector 通过V2Ray API StatsService读取并清零计数器，累积尚未成功上报的增量
+type Collector struct {
+	endpoint func() (string, bool)
+
+	mu           sync.Mutex
+	conn         *grpc.ClientConn
+	connAddr     string
+	pending      map[recordKey]*Record
+	pendingStart time.Time
+	sending      *Batch // 已取出但尚未确认上报成功的批次，确认前原样重发，Controller按统计周期去重
+}
+
+// NewCollector 创建用量采集器，endpoint返回当前V2Ray API地址
+func NewCollector(endpoint func() (string, bool)) *Collector {
+	return &Collector{
+		endpoint: endpoint,
+		pending:  make(map[recordKey]*Record),
+	}
+}
+
+// Poll 读取并清零全部流量计数器，增量累加到待上报数据中
+func (c *Collector) Poll(ctx context.Context) error {
+	addr, ok := c.endpoint()
+	if !ok {
+		return fmt.Errorf("V2Ray API统计服务未启用")
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	client, err := c.clientLocked(addr)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	resp, err := client.QueryStats(ctx, &statspb.QueryStatsRequest{
+		Pattern: ">>>traffic>>>",
+		Reset_:  true,
+	})
+	if err != nil {
+		return fmt.Errorf("查询V2Ray API计数器失败: %v", err)
+	}
+
+	if c.pendingStart.IsZero() {
+		c.pendingStart = now
+	}
+	for _, stat := range resp.Stat {
+		scope, name, direction, ok := parseStatName(stat.Name)
+		if !ok || stat.Value <= 0 {
+			continue
+		}
+		key := recordKey{scope: scope, name: name}
+		record, exists := c.pending[key]
+		if !exists {
+			record = &Record{Scope: scope, Name: name}
+			c.pending[key] = record
+		}
+		if direction == "uplink" {
+			record.Uplink += stat.Value
+		} else {
+			record.Downlink += stat.Value
+		}
+	}
+	return nil
+}
+
+// Pending 返回待上报的增量。上一批尚未确认上报成功时原样返回该批，
+// 否则将截止当前时间的增量封存为新的一批，之后采集的数据计入下一批
+func (c *Collector) Pending() Batch {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.sending != nil {
+		return *c.sending
+	}
+
+	batch := Batch{Start: c.pendingStart, End: time.Now()}
+	for _, record := range c.pending {
+		if record.Uplink == 0 && record.Downlink == 0 {
+			continue
+		}
+		batch.Records = append(batch.Records, *record)
+	}
+	if len(batch.Records) == 0 {
+		return batch
+	}
+	sort.Slice(batch.Records, func(i, j int) bool {
+		if batch.Records[i].Scope != batch.Records[j].Scope {
+			return batch.Records[i].Scope < batch.Records[j].Scope
+		}
+		return batch.Records[i].Name < batch.Records[j].Name
+	})
+
+	c.sending = &batch
+	c.pending = make(map[recordKey]*Record)
+	c.pendingStart = batch.End
+	return batch
+}
+
+// Commit 上报成功后丢弃已上报的批次，下一次Pending封存新采集的增量
+func (c *Collector) Commit(batch Batch) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.sending != nil && c.sending.Start.Equal(batch.Start) && c.sending.End.Equal(batch.End) {
+		c.sending = nil
+	}
+}
+
+// Close 关闭到V2Ray API的连接
+func (c *Collector) Close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.conn != nil {
+		c.conn.Close()
+		c.conn = nil
+	}
+}
+
+// clientLocked 返回到指定地址的StatsService客户端，地址变化时重建连接，调用方需持有c.mu
+func (c *Collector) clientLocked(addr string) (statspb.StatsServiceClient, error) {
+	if c.conn != nil && c.connAddr != addr {
+		c.conn.Close()
+		c.conn = nil
+	}
+	if c.conn == nil {
+		conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+		if err != nil {
+			return nil, fmt.Errorf("连接V2Ray API失败: %v", err)
+		}
+		c.conn = conn
+		c.connAddr = addr
+	}
+	return statspb.NewStatsServiceClient(c.conn), nil
+}
+
+// parseStatName 解析计数器名称，格式为 user>>>alice>>>traffic>>>uplink
+func parseStatName(name string) (scope, target, direction string, ok bool) {
+	parts := strings.Split(name, ">>>")
+	if len(parts) != 4 || parts[2] != "traffic" {
+		return "", "", "", false
+	}
+	switch parts[0] {
+	case ScopeUser, ScopeInbound, ScopeOutbound:
+	default:
+		return "", "", "", false
+	}
+	switch parts[3] {
+	case "uplink", "downlink":
+	default:
+		return "", "", "", false
+	}
+	return parts[0], parts[1], parts[3], true
+}
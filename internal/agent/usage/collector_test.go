@@ -0,0 +1,103 @@
+package usage
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseStatName(t *testing.T) {
+	tests := []struct {
+		name          string
+		stat          string
+		wantScope     string
+		wantTarget    string
+		wantDirection string
+		wantOK        bool
+	}{
+		{"用户上行", "user>>>alice>>>traffic>>>uplink", ScopeUser, "alice", "uplink", true},
+		{"入站下行", "inbound>>>vless-in>>>traffic>>>downlink", ScopeInbound, "vless-in", "downlink", true},
+		{"出站", "outbound>>>direct>>>traffic>>>uplink", ScopeOutbound, "direct", "uplink", true},
+		{"名称含特殊字符", "user>>>alice@example.com>>>traffic>>>downlink", ScopeUser, "alice@example.com", "downlink", true},
+		{"未知统计对象", "node>>>a>>>traffic>>>uplink", "", "", "", false},
+		{"非流量计数器", "user>>>alice>>>online>>>uplink", "", "", "", false},
+		{"未知方向", "user>>>alice>>>traffic>>>total", "", "", "", false},
+		{"段数过少", "user>>>alice>>>traffic", "", "", "", false},
+		{"段数过多", "user>>>a>>>b>>>traffic>>>uplink", "", "", "", false},
+		{"空字符串", "", "", "", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scope, target, direction, ok := parseStatName(tt.stat)
+			if scope != tt.wantScope || target != tt.wantTarget || direction != tt.wantDirection || ok != tt.wantOK {
+				t.Errorf("parseStatName(%q) = (%q, %q, %q, %v), want (%q, %q, %q, %v)",
+					tt.stat, scope, target, direction, ok, tt.wantScope, tt.wantTarget, tt.wantDirection, tt.wantOK)
+			}
+		})
+	}
+}
+
+// add 模拟一次Poll读取到的计数器增量
+func (c *Collector) add(scope, name string, uplink, downlink int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.pendingStart.IsZero() {
+		c.pendingStart = time.Now()
+	}
+	key := recordKey{scope: scope, name: name}
+	record, ok := c.pending[key]
+	if !ok {
+		record = &Record{Scope: scope, Name: name}
+		c.pending[key] = record
+	}
+	record.Uplink += uplink
+	record.Downlink += downlink
+}
+
+func TestCollectorPendingCommit(t *testing.T) {
+	c := NewCollector(func() (string, bool) { return "", false })
+
+	if batch := c.Pending(); len(batch.Records) != 0 {
+		t.Fatalf("没有增量时 Pending() = %+v", batch.Records)
+	}
+
+	c.add(ScopeUser, "bob", 5, 6)
+	c.add(ScopeUser, "alice", 1, 2)
+	first := c.Pending()
+	want := []Record{
+		{Scope: ScopeUser, Name: "alice", Uplink: 1, Downlink: 2},
+		{Scope: ScopeUser, Name: "bob", Uplink: 5, Downlink: 6},
+	}
+	if !reflect.DeepEqual(first.Records, want) {
+		t.Fatalf("Pending() = %+v, want %+v", first.Records, want)
+	}
+
+	// 上报未确认时原样重发同一批，之后采集的增量不并入
+	c.add(ScopeUser, "alice", 10, 20)
+	resend := c.Pending()
+	if !resend.Start.Equal(first.Start) || !resend.End.Equal(first.End) || !reflect.DeepEqual(resend.Records, first.Records) {
+		t.Fatalf("重发的批次 = %+v, want %+v", resend, first)
+	}
+
+	// 与当前批次不符的确认被忽略
+	c.Commit(Batch{Start: first.Start, End: first.End.Add(time.Second)})
+	if again := c.Pending(); !again.End.Equal(first.End) {
+		t.Fatalf("错误的确认丢弃了未上报的批次")
+	}
+
+	// 确认后封存新的增量，统计周期紧接上一批
+	c.Commit(first)
+	second := c.Pending()
+	want = []Record{{Scope: ScopeUser, Name: "alice", Uplink: 10, Downlink: 20}}
+	if !reflect.DeepEqual(second.Records, want) {
+		t.Fatalf("第二批 = %+v, want %+v", second.Records, want)
+	}
+	if !second.Start.Equal(first.End) {
+		t.Errorf("第二批起点 = %v, want %v", second.Start, first.End)
+	}
+
+	c.Commit(second)
+	if third := c.Pending(); len(third.Records) != 0 {
+		t.Errorf("全部确认后 Pending() = %+v", third.Records)
+	}
+}
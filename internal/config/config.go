@@ -10,19 +10,23 @@ import (
 
 // Config 应用配置结构
 type Config struct {
-	Server   ServerConfig   `mapstructure:"server"`
-	Database DatabaseConfig `mapstructure:"database"`
-	GRPC     GRPCConfig     `mapstructure:"grpc"`
-	Log      LogConfig      `mapstructure:"log"`
-	Agent    AgentConfig    `mapstructure:"agent"`
-	Report   ReportConfig   `mapstructure:"report"`
+	Server      ServerConfig      `mapstructure:"server"`
+	Database    DatabaseConfig    `mapstructure:"database"`
+	GRPC        GRPCConfig        `mapstructure:"grpc"`
+	Log         LogConfig         `mapstructure:"log"`
+	Agent       AgentConfig       `mapstructure:"agent"`
+	Report      ReportConfig      `mapstructure:"report"`
+	Certificate CertificateConfig `mapstructure:"certificate"`
+	Reality     RealityConfig     `mapstructure:"reality"`
+	Credential  CredentialConfig  `mapstructure:"credential_rotation"`
 }
 
 // ServerConfig HTTP服务器配置
 type ServerConfig struct {
-	Host string `mapstructure:"host"`
-	Port int    `mapstructure:"port"`
-	Mode string `mapstructure:"mode"` // debug, release, test
+	Host         string `mapstructure:"host"`
+	Port         int    `mapstructure:"port"`
+	Mode         string `mapstructure:"mode"`          // debug, release, test
+	ArtifactsDir string `mapstructure:"artifacts_dir"` // Controller托管的sing-box制品目录，为空时不提供制品下载
 }
 
 // DatabaseConfig 数据库配置
@@ -71,6 +75,97 @@ type AgentConfig struct {
 	HeartbeatInterval int   `mapstructure:"heartbeat_interval"` // 秒
 	SingBoxConfig    string `mapstructure:"singbox_config"`
 	SingBoxBinary    string `mapstructure:"singbox_binary"`
+	Install          InstallConfig `mapstructure:"install"` // sing-box版本固定安装
+	ProcessMode      string `mapstructure:"process_mode"` // sing-box托管方式: exec（子进程）或 systemd
+	Systemd          SystemdConfig `mapstructure:"systemd"` // systemd托管配置
+	Supervisor       SupervisorConfig `mapstructure:"supervisor"` // sing-box进程监管配置
+	Resources        ResourcesConfig `mapstructure:"resources"` // sing-box的cgroup v2资源限制
+	ConfigHistory    int    `mapstructure:"config_history"` // 保留的sing-box配置代数
+	LogBufferLines   int    `mapstructure:"log_buffer_lines"` // sing-box日志缓冲行数
+	ClashAPI         ClashAPIConfig `mapstructure:"clash_api"` // 本地Clash API连接与流量采集
+	V2RayAPI         V2RayAPIConfig `mapstructure:"v2ray_api"` // V2Ray API按用户流量统计
+	Probe            ProbeConfig    `mapstructure:"probe"`     // 出站可达性探测
+	CertDir          string `mapstructure:"cert_dir"` // Controller下发的TLS证书目录，各实例使用以实例名命名的子目录
+	Instances        []InstanceConfig `mapstructure:"instances"` // 默认实例之外的sing-box实例
+}
+
+// InstanceConfig 额外sing-box实例配置，未设置的项沿用顶层配置
+type InstanceConfig struct {
+	Name           string `mapstructure:"name"`             // 实例名称，仅允许字母、数字、-和_
+	SingBoxConfig  string `mapstructure:"singbox_config"`   // 配置文件路径，各实例不能相同
+	SingBoxBinary  string `mapstructure:"singbox_binary"`   // sing-box二进制，可使用与默认实例不同的版本
+	ProcessMode    string `mapstructure:"process_mode"`     // exec 或 systemd，systemd模式下unit名为 sing-box-<name>
+	ClashAPIListen string `mapstructure:"clash_api_listen"` // 本地Clash API监听地址，为空时不采集连接统计
+	V2RayAPIListen string `mapstructure:"v2ray_api_listen"` // V2Ray API统计服务监听地址，为空时不统计用户流量
+	ProbeListen    string `mapstructure:"probe_listen"`     // 出站探测入站监听地址，为空时不探测出站
+}
+
+// InstallConfig sing-box版本固定安装配置，制品校验sha256后才会安装
+type InstallConfig struct {
+	Version   string `mapstructure:"version"`    // 固定版本，已安装版本不一致时自动安装；为空时仅使用已安装的sing-box
+	Source    string `mapstructure:"source"`     // 制品来源: github、mirror 或 local
+	MirrorURL string `mapstructure:"mirror_url"` // mirror来源地址，如Controller托管的 http://controller:9000/api/v1/artifacts/sing-box
+	LocalDir  string `mapstructure:"local_dir"`  // local来源目录，用于离线节点
+	SHA256    string `mapstructure:"sha256"`     // 制品sha256，为空时读取来源中的SHA256SUMS（github来源必须配置）
+	PublicKey string `mapstructure:"public_key"` // ed25519公钥(base64)，设置后要求SHA256SUMS.sig签名有效
+}
+
+// SystemdConfig sing-box的systemd托管配置
+type SystemdConfig struct {
+	Unit    string `mapstructure:"unit"`     // unit名称（不含.service后缀）
+	UnitDir string `mapstructure:"unit_dir"` // unit文件目录
+}
+
+// V2RayAPIConfig sing-box V2Ray API流量统计配置
+type V2RayAPIConfig struct {
+	Enabled        bool   `mapstructure:"enabled"`         // 启用统计服务并上报用户/入站流量，需要with_v2ray_api构建的sing-box
+	Listen         string `mapstructure:"listen"`          // 统计服务监听地址，仅允许回环地址
+	ReportInterval int    `mapstructure:"report_interval"` // 采集并上报间隔（秒）
+}
+
+// ClashAPIConfig sing-box Clash API采集配置
+type ClashAPIConfig struct {
+	Enabled      bool   `mapstructure:"enabled"`       // 自动启用本地Clash API并采集连接与流量
+	Listen       string `mapstructure:"listen"`        // 注入的监听地址，仅允许回环地址
+	PollInterval int    `mapstructure:"poll_interval"` // 采集间隔（秒）
+}
+
+// ProbeConfig 出站可达性探测配置，探测经由注入的本地SOCKS入站按出站tag路由
+type ProbeConfig struct {
+	Enabled   bool                `mapstructure:"enabled"`   // 定期探测各出站并随心跳上报延迟与成功率
+	Listen    string              `mapstructure:"listen"`    // 注入的探测入站监听地址，仅允许回环地址
+	Interval  int                 `mapstructure:"interval"`  // 探测间隔（秒）
+	Timeout   int                 `mapstructure:"timeout"`   // 单次探测超时（秒）
+	Outbounds []string            `mapstructure:"outbounds"` // 仅探测这些出站tag，为空时探测除block、dns外的全部出站
+	Targets   []ProbeTargetConfig `mapstructure:"targets"`   // 探测目标，为空时请求 https://www.gstatic.com/generate_204
+}
+
+// ProbeTargetConfig 出站探测目标
+type ProbeTargetConfig struct {
+	Type    string `mapstructure:"type"`    // http、tcp 或 dns
+	Address string `mapstructure:"address"` // http为URL，tcp为host:port，dns为DNS服务器host:port（经出站以TCP查询）
+	Domain  string `mapstructure:"domain"`  // dns探测查询的域名，默认www.google.com
+}
+
+// SupervisorConfig sing-box进程崩溃重启配置
+type SupervisorConfig struct {
+	Enabled        bool    `mapstructure:"enabled"`         // 异常退出后自动重启
+	InitialBackoff int     `mapstructure:"initial_backoff"` // 首次退避时间（秒）
+	MaxBackoff     int     `mapstructure:"max_backoff"`     // 最大退避时间（秒）
+	Multiplier     float64 `mapstructure:"multiplier"`      // 退避倍数
+	MaxRestarts    int     `mapstructure:"max_restarts"`    // 检测窗口内最大重启次数
+	RestartWindow  int     `mapstructure:"restart_window"`  // 崩溃循环检测窗口（秒）
+	StableAfter    int     `mapstructure:"stable_after"`    // 稳定运行多久后重置退避（秒）
+}
+
+// ResourcesConfig sing-box的cgroup v2资源限制配置，各实例使用独立的cgroup和相同的限制
+type ResourcesConfig struct {
+	Enabled      bool   `mapstructure:"enabled"`       // 将sing-box放入独立cgroup并上报资源用量
+	CgroupRoot   string `mapstructure:"cgroup_root"`   // cgroup v2挂载点
+	CgroupParent string `mapstructure:"cgroup_parent"` // exec模式下为父cgroup名，systemd模式下为slice名（不含.slice后缀）
+	MemoryMax    string `mapstructure:"memory_max"`    // memory.max，如512M，为空或max时不限制
+	CPUMax       string `mapstructure:"cpu_max"`       // cpu.max，百分比（如150%）或"配额 周期"，为空或max时不限制
+	PidsMax      int64  `mapstructure:"pids_max"`      // pids.max，0表示不限制
 }
 
 // ReportConfig 节点上报配置
@@ -83,6 +178,24 @@ type ReportConfig struct {
 	RetryDelay   int    `mapstructure:"retry_delay"`      // 重试延迟（秒）
 }
 
+// CertificateConfig Controller托管TLS证书的配置
+type CertificateConfig struct {
+	SecretKey     string `mapstructure:"secret_key"`     // 加密保存TLS私钥的密钥，可通过XBOX_CERTIFICATE_SECRET_KEY设置
+	WarnDays      int    `mapstructure:"warn_days"`      // 到期前多少天开始告警
+	CheckInterval int    `mapstructure:"check_interval"` // 到期检查间隔（秒）
+}
+
+// RealityConfig Controller托管的Reality密钥配置
+type RealityConfig struct {
+	SecretKey     string `mapstructure:"secret_key"`     // 加密保存Reality私钥的密钥，可通过XBOX_REALITY_SECRET_KEY设置
+	CheckInterval int    `mapstructure:"check_interval"` // 定期轮换检查间隔（秒）
+}
+
+// CredentialConfig 入站用户凭据轮换配置
+type CredentialConfig struct {
+	CheckInterval int `mapstructure:"check_interval"` // 检查到期轮换和待吊销旧凭据的间隔（秒）
+}
+
 var globalConfig *Config
 
 // LoadConfig 加载配置文件
@@ -166,6 +279,33 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("agent.controller_addr", "localhost:9090")
 	v.SetDefault("agent.singbox_config", "./sing-box.json")
 	v.SetDefault("agent.singbox_binary", "sing-box")
+	v.SetDefault("agent.install.source", "github")
+	v.SetDefault("agent.process_mode", "exec")
+	v.SetDefault("agent.systemd.unit", "sing-box")
+	v.SetDefault("agent.systemd.unit_dir", "/etc/systemd/system")
+	v.SetDefault("agent.supervisor.enabled", true)
+	v.SetDefault("agent.supervisor.initial_backoff", 1)
+	v.SetDefault("agent.supervisor.max_backoff", 60)
+	v.SetDefault("agent.supervisor.multiplier", 2.0)
+	v.SetDefault("agent.supervisor.max_restarts", 5)
+	v.SetDefault("agent.supervisor.restart_window", 600)
+	v.SetDefault("agent.supervisor.stable_after", 120)
+	v.SetDefault("agent.resources.enabled", false)
+	v.SetDefault("agent.resources.cgroup_root", "/sys/fs/cgroup")
+	v.SetDefault("agent.resources.cgroup_parent", "xbox-singbox")
+	v.SetDefault("agent.config_history", 20)
+	v.SetDefault("agent.log_buffer_lines", 1000)
+	v.SetDefault("agent.clash_api.enabled", false)
+	v.SetDefault("agent.clash_api.listen", "127.0.0.1:19090")
+	v.SetDefault("agent.clash_api.poll_interval", 5)
+	v.SetDefault("agent.v2ray_api.enabled", false)
+	v.SetDefault("agent.v2ray_api.listen", "127.0.0.1:10085")
+	v.SetDefault("agent.v2ray_api.report_interval", 60)
+	v.SetDefault("agent.probe.enabled", false)
+	v.SetDefault("agent.probe.listen", "127.0.0.1:19080")
+	v.SetDefault("agent.probe.interval", 60)
+	v.SetDefault("agent.probe.timeout", 5)
+	v.SetDefault("agent.cert_dir", "./configs/certs")
 	
 	// Report默认配置
 	v.SetDefault("report.enabled", true)
@@ -174,6 +314,18 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("report.timeout", 30)
 	v.SetDefault("report.retry_count", 3)
 	v.SetDefault("report.retry_delay", 5)
+	
+	// 证书到期检查默认配置
+	v.SetDefault("certificate.secret_key", "")
+	v.SetDefault("certificate.warn_days", 30)
+	v.SetDefault("certificate.check_interval", 3600)
+	
+	// Reality密钥默认配置
+	v.SetDefault("reality.secret_key", "")
+	v.SetDefault("reality.check_interval", 300)
+	
+	// 凭据轮换默认配置
+	v.SetDefault("credential_rotation.check_interval", 300)
 }
 
 // GetDSN 获取数据库连接字符串
@@ -14,12 +14,16 @@ import (
 type AgentServiceServer struct {
 	pb.UnimplementedAgentServiceServer
 	agentService service.AgentService
+	usageService service.UsageService
+	probeService service.ProbeService
 }
 
 // NewAgentServiceServer 创建AgentService服务实例
-func NewAgentServiceServer(agentService service.AgentService) *AgentServiceServer {
+func NewAgentServiceServer(agentService service.AgentService, usageService service.UsageService, probeService service.ProbeService) *AgentServiceServer {
 	return &AgentServiceServer{
 		agentService: agentService,
+		usageService: usageService,
+		probeService: probeService,
 	}
 }
 
@@ -58,6 +62,13 @@ func (s *AgentServiceServer) Heartbeat(ctx context.Context, req *pb.HeartbeatReq
 		log.Printf("心跳处理响应: Success=%v, Message=%s", resp.Success, resp.Message)
 	}
 	
+	// 保存出站探测结果，保存失败不影响心跳
+	if resp.Success && len(req.OutboundProbes) > 0 {
+		if err := s.probeService.RecordProbes(req.AgentId, req.OutboundProbes); err != nil {
+			log.Printf("保存出站探测结果失败: AgentID=%s, %v", req.AgentId, err)
+		}
+	}
+	
 	return resp, nil
 }
 
@@ -187,4 +198,20 @@ func (s *AgentServiceServer) RollbackConfig(ctx context.Context, req *pb.Rollbac
 		RolledBackVersion:   req.TargetVersion,
 		CurrentVersion:      "v" + fmt.Sprintf("%d", time.Now().Unix()),
 	}, nil
-}
\ No newline at end of file
+}
+
+// ReportTrafficUsage 实现用户流量上报
+func (s *AgentServiceServer) ReportTrafficUsage(ctx context.Context, req *pb.TrafficUsageReport) (*pb.TrafficUsageResponse, error) {
+	if err := s.usageService.RecordUsage(req); err != nil {
+		log.Printf("保存流量上报失败: AgentID=%s, %v", req.AgentId, err)
+		return &pb.TrafficUsageResponse{
+			Success: false,
+			Message: err.Error(),
+		}, nil
+	}
+
+	return &pb.TrafficUsageResponse{
+		Success: true,
+		Message: fmt.Sprintf("已记录 %d 条流量数据", len(req.Usages)),
+	}, nil
+}
@@ -19,6 +19,7 @@ type BackendServiceServer struct {
 	agentService      service.AgentService
 	multiplexService  service.MultiplexService
 	reportService     *service.NodeReportService
+	probeService      service.ProbeService
 }
 
 // NewBackendServiceServer 创建新的 BackendService 服务器
@@ -26,11 +27,13 @@ func NewBackendServiceServer(
 	agentService service.AgentService,
 	multiplexService service.MultiplexService,
 	reportService *service.NodeReportService,
+	probeService service.ProbeService,
 ) *BackendServiceServer {
 	return &BackendServiceServer{
 		agentService:     agentService,
 		multiplexService: multiplexService,
 		reportService:    reportService,
+		probeService:     probeService,
 	}
 }
 
@@ -277,8 +280,23 @@ func (s *BackendServiceServer) GetAgentMonitoring(ctx context.Context, req *back
 		}, nil
 	}
 
-	// 获取监控数据
-	monitoringData, err := s.agentService.GetAgentMonitoring(req.AgentId, int(req.DurationMinutes))
+	agent, err := s.agentService.GetAgent(req.AgentId)
+	if err != nil {
+		return &backendpb.GetAgentMonitoringResponse{
+			Success: false,
+			Message: fmt.Sprintf("获取Agent失败: %v", err),
+		}, nil
+	}
+
+	// 默认返回最近一小时的出站探测数据
+	duration := time.Duration(req.DurationMinutes) * time.Minute
+	if duration <= 0 {
+		duration = time.Hour
+	}
+	series, err := s.probeService.GetSeries(service.ProbeQuery{
+		AgentID: req.AgentId,
+		From:    time.Now().Add(-duration),
+	})
 	if err != nil {
 		return &backendpb.GetAgentMonitoringResponse{
 			Success: false,
@@ -289,7 +307,7 @@ func (s *BackendServiceServer) GetAgentMonitoring(ctx context.Context, req *back
 	return &backendpb.GetAgentMonitoringResponse{
 		Success:        true,
 		Message:        "获取监控数据成功",
-		MonitoringData: s.convertMonitoringDataToProto(monitoringData),
+		MonitoringData: s.convertMonitoringDataToProto(agent, series),
 	}, nil
 }
 
@@ -510,16 +528,54 @@ func (s *BackendServiceServer) calculateAgentStats(agents []*models.Agent) *back
 }
 
 // convertMonitoringDataToProto 转换监控数据为protobuf格式
-func (s *BackendServiceServer) convertMonitoringDataToProto(data interface{}) *backendpb.AgentMonitoringData {
-	// 这里需要根据实际的监控数据结构进行转换
-	// 假设data是一个包含监控信息的结构体
-	return &backendpb.AgentMonitoringData{
-		AgentId:             "placeholder",
-		SingboxStatus:       "running",
-		LastUpdate:          timestamppb.Now(),
-		ActiveConnections:   0,
-		NetworkLatencyMs:    0.0,
+func (s *BackendServiceServer) convertMonitoringDataToProto(agent *models.Agent, series []service.ProbeSeries) *backendpb.AgentMonitoringData {
+	data := &backendpb.AgentMonitoringData{
+		AgentId:           agent.ID,
+		SingboxStatus:     "unknown",
+		ActiveConnections: int32(agent.CurrentConnections),
+		NetworkLatencyMs:  float64(agent.NetworkLatency),
+	}
+	if agent.LastHeartbeat != nil && !agent.LastHeartbeat.IsZero() {
+		data.LastUpdate = timestamppb.New(*agent.LastHeartbeat)
+	}
+	if instances, ok := s.agentService.GetInstances(agent.ID); ok {
+		for _, inst := range instances {
+			if inst.Name == "default" {
+				data.SingboxStatus = inst.State
+			}
+		}
+	}
+	if stats, ok := s.agentService.GetConnectionStats(agent.ID); ok && stats.Available {
+		data.ActiveConnections = stats.TotalConnections
+	}
+
+	for _, item := range series {
+		probeSeries := &backendpb.OutboundProbeSeries{
+			Instance:     item.Instance,
+			Outbound:     item.Outbound,
+			TargetType:   item.TargetType,
+			Target:       item.Target,
+			Probes:       int32(item.Probes),
+			Successes:    int32(item.Successes),
+			AvgLatencyMs: item.AvgLatencyMs,
+			LastError:    item.LastError,
+		}
+		for _, point := range item.Points {
+			timestamp := timestamppb.New(point.PeriodEnd)
+			if point.Successes > 0 {
+				probeSeries.LatencyMs = append(probeSeries.LatencyMs, &backendpb.MonitoringDataPoint{
+					Timestamp: timestamp,
+					Value:     point.AvgLatencyMs,
+				})
+			}
+			probeSeries.SuccessRate = append(probeSeries.SuccessRate, &backendpb.MonitoringDataPoint{
+				Timestamp: timestamp,
+				Value:     float64(point.Successes) / float64(point.Probes),
+			})
+		}
+		data.OutboundProbes = append(data.OutboundProbes, probeSeries)
 	}
+	return data
 }
 
 // convertIPRangeToProto 转换IP段信息为protobuf格式
@@ -24,15 +24,19 @@ type Server struct {
 	agentService     service.AgentService
 	multiplexService service.MultiplexService
 	reportService    *service.NodeReportService
+	usageService     service.UsageService
+	probeService     service.ProbeService
 }
 
 // NewServer 创建gRPC服务器实例
-func NewServer(cfg *config.Config, agentService service.AgentService, multiplexService service.MultiplexService, reportService *service.NodeReportService) *Server {
+func NewServer(cfg *config.Config, agentService service.AgentService, multiplexService service.MultiplexService, reportService *service.NodeReportService, usageService service.UsageService, probeService service.ProbeService) *Server {
 	return &Server{
 		config:           cfg,
 		agentService:     agentService,
 		multiplexService: multiplexService,
 		reportService:    reportService,
+		usageService:     usageService,
+		probeService:     probeService,
 	}
 }
 
@@ -69,11 +73,11 @@ func (s *Server) Start() error {
 	s.grpcServer = grpc.NewServer(opts...)
 
 	// 注册服务
-	agentServiceServer := NewAgentServiceServer(s.agentService)
+	agentServiceServer := NewAgentServiceServer(s.agentService, s.usageService, s.probeService)
 	pb.RegisterAgentServiceServer(s.grpcServer, agentServiceServer)
 	
 	// 注册后端服务接口
-	backendServiceServer := NewBackendServiceServer(s.agentService, s.multiplexService, s.reportService)
+	backendServiceServer := NewBackendServiceServer(s.agentService, s.multiplexService, s.reportService, s.probeService)
 	backendpb.RegisterBackendServiceServer(s.grpcServer, backendServiceServer)
 
 	// 启用反射服务(用于调试)
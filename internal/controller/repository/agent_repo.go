@@ -23,6 +23,8 @@ type AgentRepository interface {
 	UpdateStatus(id string, status string) error
 	// 更新心跳时间
 	UpdateHeartbeat(id string) error
+	// 更新当前连接数
+	UpdateConnections(id string, connections int) error
 	// 获取在线Agent数量
 	GetOnlineCount() (int64, error)
 	// 获取离线Agent列表
@@ -105,6 +107,13 @@ func (r *agentRepository) UpdateHeartbeat(id string) error {
 		}).Error
 }
 
+// UpdateConnections 更新当前连接数
+func (r *agentRepository) UpdateConnections(id string, connections int) error {
+	return r.db.Model(&models.Agent{}).
+		Where("id = ?", id).
+		Update("current_connections", connections).Error
+}
+
 // GetOnlineCount 获取在线Agent数量
 func (r *agentRepository) GetOnlineCount() (int64, error) {
 	var count int64
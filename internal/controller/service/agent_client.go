@@ -2,7 +2,9 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"time"
 
 	pb "github.com/xbox/sing-box-manager/proto/agent"
@@ -14,10 +16,45 @@ import (
 type AgentClient interface {
 	UpdateMultiplexConfig(agentID, protocol, configJSON string) error
 	GetMultiplexConfig(agentID, protocol string) (string, error)
-	UpdateConfig(agentID, configContent, configVersion string) error
+	UpdateConfig(agentID, instance, configContent, configVersion string, force bool) error
 	UpdateBlacklist(agentID, protocol string, domains, ips, ports []string, operation string) error
 	UpdateWhitelist(agentID, protocol string, domains, ips, ports []string, operation string) error
-	RollbackConfig(agentID, targetVersion, reason string) error
+	RollbackConfig(agentID, instance, scope, targetVersion, reason string) error
+	ListConfigGenerations(agentID, instance string) ([]*pb.ConfigGeneration, error)
+	DiffConfigGenerations(agentID, instance string, fromVersion, toVersion int64) (string, error)
+	StreamSingboxLogs(ctx context.Context, req *pb.LogStreamRequest, handler func(*pb.SingboxLogEntry) error) error
+	UpdateInboundUsers(req *pb.InboundUsersRequest) (*pb.InboundUsersResponse, error)
+	GetInboundUsers(agentID, instance, inboundTag string) (*pb.InboundUsersResponse, error)
+	CloseConnections(req *pb.CloseConnectionsRequest) (*pb.CloseConnectionsResponse, error)
+	UpgradeSingbox(agentID, instance, version, sha256 string) (*pb.UpgradeResponse, error)
+	GetDNSConfig(agentID, instance string) (*pb.DNSConfigResponse, error)
+	UpdateDNSServers(req *pb.DNSServersRequest) (*pb.DNSConfigResponse, error)
+	UpdateDNSRules(req *pb.DNSRulesRequest) (*pb.DNSConfigResponse, error)
+	UpdateFakeIP(req *pb.FakeIPRequest) (*pb.DNSConfigResponse, error)
+	GetOutboundGroups(req *pb.OutboundGroupsQuery) (*pb.OutboundGroupsResponse, error)
+	UpdateOutboundGroup(req *pb.OutboundGroupRequest) (*pb.OutboundGroupResponse, error)
+	UpdateGroupMembers(req *pb.GroupMembersRequest) (*pb.OutboundGroupResponse, error)
+	UpdateCertificate(req *pb.CertificateRequest) (*pb.CertificateResponse, error)
+	UpdateReality(req *pb.RealityRequest) (*pb.RealityResponse, error)
+	GetReality(agentID, instance, inboundTag string) (*pb.RealityResponse, error)
+	GetInbounds(agentID string) ([]*pb.InboundDefinition, error)
+}
+
+// 版本切换包含制品下载和重启探测，超时时间长于普通调用
+const upgradeTimeout = 10 * time.Minute
+
+// ErrInboundUsersChanged 请求携带的修订与入站用户的当前修订不一致，用户未更新
+var ErrInboundUsersChanged = errors.New("入站用户已被修改")
+
+// PortConflictError Agent预检发现入站端口已被占用，配置未应用
+type PortConflictError struct {
+	Conflicts []*pb.PortConflict
+	Message   string
+}
+
+// Error 实现error接口
+func (e *PortConflictError) Error() string {
+	return fmt.Sprintf("Agent返回错误: %s", e.Message)
 }
 
 // agentClient Agent gRPC客户端实现
@@ -123,8 +160,8 @@ func (c *agentClient) GetMultiplexConfig(agentID, protocol string) (string, erro
 	return fmt.Sprintf(`{"success": true, "configs": %v}`, resp.MultiplexConfigs), nil
 }
 
-// UpdateConfig 更新Agent配置
-func (c *agentClient) UpdateConfig(agentID, configContent, configVersion string) error {
+// UpdateConfig 更新Agent配置，instance为空时更新默认实例，force为true时忽略端口冲突
+func (c *agentClient) UpdateConfig(agentID, instance, configContent, configVersion string, force bool) error {
 	conn, err := c.getConnection(agentID)
 	if err != nil {
 		return err
@@ -138,7 +175,8 @@ func (c *agentClient) UpdateConfig(agentID, configContent, configVersion string)
 		AgentId:       agentID,
 		ConfigContent: configContent,
 		ConfigVersion: configVersion,
-		ForceUpdate:   false,
+		ForceUpdate:   force,
+		Instance:      instance,
 	}
 
 	resp, err := client.UpdateConfig(ctx, req)
@@ -147,6 +185,12 @@ func (c *agentClient) UpdateConfig(agentID, configContent, configVersion string)
 	}
 
 	if !resp.Success {
+		if len(resp.PortConflicts) > 0 {
+			return &PortConflictError{Conflicts: resp.PortConflicts, Message: resp.Message}
+		}
+		if resp.Reverted {
+			return fmt.Errorf("Agent返回错误(已回退到上一代配置): %s", resp.Message)
+		}
 		return fmt.Errorf("Agent返回错误: %s", resp.Message)
 	}
 
@@ -217,8 +261,8 @@ func (c *agentClient) UpdateWhitelist(agentID, protocol string, domains, ips, po
 	return nil
 }
 
-// RollbackConfig 回滚Agent配置
-func (c *agentClient) RollbackConfig(agentID, targetVersion, reason string) error {
+// RollbackConfig 回滚Agent配置，scope为filter(默认)或singbox
+func (c *agentClient) RollbackConfig(agentID, instance, scope, targetVersion, reason string) error {
 	conn, err := c.getConnection(agentID)
 	if err != nil {
 		return err
@@ -232,6 +276,8 @@ func (c *agentClient) RollbackConfig(agentID, targetVersion, reason string) erro
 		AgentId:       agentID,
 		TargetVersion: targetVersion,
 		Reason:        reason,
+		Scope:         scope,
+		Instance:      instance,
 	}
 
 	resp, err := client.RollbackConfig(ctx, req)
@@ -246,6 +292,480 @@ func (c *agentClient) RollbackConfig(agentID, targetVersion, reason string) erro
 	return nil
 }
 
+// ListConfigGenerations 获取Agent的sing-box配置历史代
+func (c *agentClient) ListConfigGenerations(agentID, instance string) ([]*pb.ConfigGeneration, error) {
+	conn, err := c.getConnection(agentID)
+	if err != nil {
+		return nil, err
+	}
+
+	client := pb.NewAgentServiceClient(conn)
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	resp, err := client.ListConfigGenerations(ctx, &pb.ConfigGenerationsRequest{AgentId: agentID, Instance: instance})
+	if err != nil {
+		return nil, fmt.Errorf("调用Agent ListConfigGenerations失败: %w", err)
+	}
+
+	if !resp.Success {
+		return nil, fmt.Errorf("Agent返回错误: %s", resp.Message)
+	}
+
+	return resp.Generations, nil
+}
+
+// DiffConfigGenerations 比较Agent的两代sing-box配置
+func (c *agentClient) DiffConfigGenerations(agentID, instance string, fromVersion, toVersion int64) (string, error) {
+	conn, err := c.getConnection(agentID)
+	if err != nil {
+		return "", err
+	}
+
+	client := pb.NewAgentServiceClient(conn)
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	req := &pb.ConfigDiffRequest{
+		AgentId:     agentID,
+		FromVersion: fromVersion,
+		ToVersion:   toVersion,
+		Instance:    instance,
+	}
+
+	resp, err := client.DiffConfigGenerations(ctx, req)
+	if err != nil {
+		return "", fmt.Errorf("调用Agent DiffConfigGenerations失败: %w", err)
+	}
+
+	if !resp.Success {
+		return "", fmt.Errorf("Agent返回错误: %s", resp.Message)
+	}
+
+	return resp.Diff, nil
+}
+
+// StreamSingboxLogs 拉取Agent的sing-box日志流，每条日志调用一次handler，ctx取消时结束
+func (c *agentClient) StreamSingboxLogs(ctx context.Context, req *pb.LogStreamRequest, handler func(*pb.SingboxLogEntry) error) error {
+	conn, err := c.getConnection(req.AgentId)
+	if err != nil {
+		return err
+	}
+
+	client := pb.NewAgentServiceClient(conn)
+
+	// 非follow模式只需读取缓冲区，沿用普通超时
+	if !req.Follow {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+
+	stream, err := client.StreamSingboxLogs(ctx, req)
+	if err != nil {
+		return fmt.Errorf("调用Agent StreamSingboxLogs失败: %w", err)
+	}
+
+	for {
+		entry, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("接收Agent日志失败: %w", err)
+		}
+		if err := handler(entry); err != nil {
+			return err
+		}
+	}
+}
+
+// UpdateInboundUsers 增删或替换Agent入站用户，修订不一致时返回ErrInboundUsersChanged
+func (c *agentClient) UpdateInboundUsers(req *pb.InboundUsersRequest) (*pb.InboundUsersResponse, error) {
+	conn, err := c.getConnection(req.AgentId)
+	if err != nil {
+		return nil, err
+	}
+
+	client := pb.NewAgentServiceClient(conn)
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	resp, err := client.UpdateInboundUsers(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("调用Agent UpdateInboundUsers失败: %w", err)
+	}
+
+	if !resp.Success {
+		if resp.RevisionMismatch {
+			return resp, fmt.Errorf("%w: %s", ErrInboundUsersChanged, resp.Message)
+		}
+		return resp, fmt.Errorf("Agent返回错误: %s", resp.Message)
+	}
+
+	return resp, nil
+}
+
+// GetInboundUsers 获取Agent入站用户列表
+func (c *agentClient) GetInboundUsers(agentID, instance, inboundTag string) (*pb.InboundUsersResponse, error) {
+	conn, err := c.getConnection(agentID)
+	if err != nil {
+		return nil, err
+	}
+
+	client := pb.NewAgentServiceClient(conn)
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	req := &pb.InboundUsersQuery{
+		AgentId:    agentID,
+		InboundTag: inboundTag,
+		Instance:   instance,
+	}
+
+	resp, err := client.GetInboundUsers(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("调用Agent GetInboundUsers失败: %w", err)
+	}
+
+	if !resp.Success {
+		return nil, fmt.Errorf("Agent返回错误: %s", resp.Message)
+	}
+
+	return resp, nil
+}
+
+// CloseConnections 按条件关闭Agent上的sing-box连接
+func (c *agentClient) CloseConnections(req *pb.CloseConnectionsRequest) (*pb.CloseConnectionsResponse, error) {
+	conn, err := c.getConnection(req.AgentId)
+	if err != nil {
+		return nil, err
+	}
+
+	client := pb.NewAgentServiceClient(conn)
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	resp, err := client.CloseConnections(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("调用Agent CloseConnections失败: %w", err)
+	}
+
+	if !resp.Success {
+		return resp, fmt.Errorf("Agent返回错误: %s", resp.Message)
+	}
+
+	return resp, nil
+}
+
+// UpgradeSingbox 切换Agent上的sing-box版本
+func (c *agentClient) UpgradeSingbox(agentID, instance, version, sha256 string) (*pb.UpgradeResponse, error) {
+	conn, err := c.getConnection(agentID)
+	if err != nil {
+		return nil, err
+	}
+
+	client := pb.NewAgentServiceClient(conn)
+	ctx, cancel := context.WithTimeout(context.Background(), upgradeTimeout)
+	defer cancel()
+
+	req := &pb.UpgradeRequest{
+		AgentId:  agentID,
+		Version:  version,
+		Sha256:   sha256,
+		Instance: instance,
+	}
+
+	resp, err := client.UpgradeSingbox(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("调用Agent UpgradeSingbox失败: %w", err)
+	}
+
+	if !resp.Success {
+		return resp, fmt.Errorf("Agent返回错误: %s", resp.Message)
+	}
+
+	return resp, nil
+}
+
+// GetDNSConfig 获取Agent当前生效的DNS配置
+func (c *agentClient) GetDNSConfig(agentID, instance string) (*pb.DNSConfigResponse, error) {
+	conn, err := c.getConnection(agentID)
+	if err != nil {
+		return nil, err
+	}
+
+	client := pb.NewAgentServiceClient(conn)
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	req := &pb.DNSConfigQuery{
+		AgentId:  agentID,
+		Instance: instance,
+	}
+
+	resp, err := client.GetDNSConfig(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("调用Agent GetDNSConfig失败: %w", err)
+	}
+
+	if !resp.Success {
+		return resp, fmt.Errorf("Agent返回错误: %s", resp.Message)
+	}
+
+	return resp, nil
+}
+
+// UpdateDNSServers 更新Agent的DNS服务器
+func (c *agentClient) UpdateDNSServers(req *pb.DNSServersRequest) (*pb.DNSConfigResponse, error) {
+	conn, err := c.getConnection(req.AgentId)
+	if err != nil {
+		return nil, err
+	}
+
+	client := pb.NewAgentServiceClient(conn)
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	resp, err := client.UpdateDNSServers(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("调用Agent UpdateDNSServers失败: %w", err)
+	}
+
+	if !resp.Success {
+		return resp, fmt.Errorf("Agent返回错误: %s", resp.Message)
+	}
+
+	return resp, nil
+}
+
+// UpdateDNSRules 更新Agent的DNS规则
+func (c *agentClient) UpdateDNSRules(req *pb.DNSRulesRequest) (*pb.DNSConfigResponse, error) {
+	conn, err := c.getConnection(req.AgentId)
+	if err != nil {
+		return nil, err
+	}
+
+	client := pb.NewAgentServiceClient(conn)
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	resp, err := client.UpdateDNSRules(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("调用Agent UpdateDNSRules失败: %w", err)
+	}
+
+	if !resp.Success {
+		return resp, fmt.Errorf("Agent返回错误: %s", resp.Message)
+	}
+
+	return resp, nil
+}
+
+// UpdateFakeIP 启用或关闭Agent的FakeIP
+func (c *agentClient) UpdateFakeIP(req *pb.FakeIPRequest) (*pb.DNSConfigResponse, error) {
+	conn, err := c.getConnection(req.AgentId)
+	if err != nil {
+		return nil, err
+	}
+
+	client := pb.NewAgentServiceClient(conn)
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	resp, err := client.UpdateFakeIP(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("调用Agent UpdateFakeIP失败: %w", err)
+	}
+
+	if !resp.Success {
+		return resp, fmt.Errorf("Agent返回错误: %s", resp.Message)
+	}
+
+	return resp, nil
+}
+
+// GetOutboundGroups 获取Agent的出站组及当前选择与成员延迟
+func (c *agentClient) GetOutboundGroups(req *pb.OutboundGroupsQuery) (*pb.OutboundGroupsResponse, error) {
+	conn, err := c.getConnection(req.AgentId)
+	if err != nil {
+		return nil, err
+	}
+
+	client := pb.NewAgentServiceClient(conn)
+	ctx, cancel := context.WithTimeout(context.Background(), groupsQueryTimeout(c.timeout, req))
+	defer cancel()
+
+	resp, err := client.GetOutboundGroups(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("调用Agent GetOutboundGroups失败: %w", err)
+	}
+
+	if !resp.Success {
+		return resp, fmt.Errorf("Agent返回错误: %s", resp.Message)
+	}
+
+	return resp, nil
+}
+
+// groupsQueryTimeout 查询出站组时预留的调用超时，延迟测试按请求的单次超时延长
+func groupsQueryTimeout(base time.Duration, req *pb.OutboundGroupsQuery) time.Duration {
+	if !req.TestDelay {
+		return base
+	}
+	if req.TimeoutMs > 0 {
+		return base + time.Duration(req.TimeoutMs)*time.Millisecond
+	}
+	return base + 5*time.Second
+}
+
+// UpdateOutboundGroup 创建、修改或删除Agent的出站组
+func (c *agentClient) UpdateOutboundGroup(req *pb.OutboundGroupRequest) (*pb.OutboundGroupResponse, error) {
+	conn, err := c.getConnection(req.AgentId)
+	if err != nil {
+		return nil, err
+	}
+
+	client := pb.NewAgentServiceClient(conn)
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	resp, err := client.UpdateOutboundGroup(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("调用Agent UpdateOutboundGroup失败: %w", err)
+	}
+
+	if !resp.Success {
+		return resp, fmt.Errorf("Agent返回错误: %s", resp.Message)
+	}
+
+	return resp, nil
+}
+
+// UpdateGroupMembers 增删或替换Agent出站组成员
+func (c *agentClient) UpdateGroupMembers(req *pb.GroupMembersRequest) (*pb.OutboundGroupResponse, error) {
+	conn, err := c.getConnection(req.AgentId)
+	if err != nil {
+		return nil, err
+	}
+
+	client := pb.NewAgentServiceClient(conn)
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	resp, err := client.UpdateGroupMembers(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("调用Agent UpdateGroupMembers失败: %w", err)
+	}
+
+	if !resp.Success {
+		return resp, fmt.Errorf("Agent返回错误: %s", resp.Message)
+	}
+
+	return resp, nil
+}
+
+// UpdateCertificate 向Agent下发入站TLS证书
+func (c *agentClient) UpdateCertificate(req *pb.CertificateRequest) (*pb.CertificateResponse, error) {
+	conn, err := c.getConnection(req.AgentId)
+	if err != nil {
+		return nil, err
+	}
+
+	client := pb.NewAgentServiceClient(conn)
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	resp, err := client.UpdateCertificate(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("调用Agent UpdateCertificate失败: %w", err)
+	}
+
+	if !resp.Success {
+		return resp, fmt.Errorf("Agent返回错误: %s", resp.Message)
+	}
+
+	return resp, nil
+}
+
+// UpdateReality 向Agent下发入站Reality密钥
+func (c *agentClient) UpdateReality(req *pb.RealityRequest) (*pb.RealityResponse, error) {
+	conn, err := c.getConnection(req.AgentId)
+	if err != nil {
+		return nil, err
+	}
+
+	client := pb.NewAgentServiceClient(conn)
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	resp, err := client.UpdateReality(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("调用Agent UpdateReality失败: %w", err)
+	}
+
+	if !resp.Success {
+		return resp, fmt.Errorf("Agent返回错误: %s", resp.Message)
+	}
+
+	return resp, nil
+}
+
+// GetReality 获取Agent入站当前的Reality公钥和short_id
+func (c *agentClient) GetReality(agentID, instance, inboundTag string) (*pb.RealityResponse, error) {
+	conn, err := c.getConnection(agentID)
+	if err != nil {
+		return nil, err
+	}
+
+	client := pb.NewAgentServiceClient(conn)
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	req := &pb.RealityQuery{
+		AgentId:    agentID,
+		Instance:   instance,
+		InboundTag: inboundTag,
+	}
+
+	resp, err := client.GetReality(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("调用Agent GetReality失败: %w", err)
+	}
+
+	if !resp.Success {
+		return nil, fmt.Errorf("Agent返回错误: %s", resp.Message)
+	}
+
+	return resp, nil
+}
+
+// GetInbounds 获取Agent全部实例当前生效的入站定义
+func (c *agentClient) GetInbounds(agentID string) ([]*pb.InboundDefinition, error) {
+	conn, err := c.getConnection(agentID)
+	if err != nil {
+		return nil, err
+	}
+
+	client := pb.NewAgentServiceClient(conn)
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	resp, err := client.GetInbounds(ctx, &pb.InboundsQuery{AgentId: agentID})
+	if err != nil {
+		return nil, fmt.Errorf("调用Agent GetInbounds失败: %w", err)
+	}
+
+	if !resp.Success {
+		return nil, fmt.Errorf("Agent返回错误: %s", resp.Message)
+	}
+
+	return resp.Inbounds, nil
+}
+
 // Close 关闭所有连接
 func (c *agentClient) Close() {
 	for agentID, conn := range c.connections {
@@ -254,4 +774,4 @@ func (c *agentClient) Close() {
 		}
 	}
 	c.connections = make(map[string]*grpc.ClientConn)
-}
\ No newline at end of file
+}
@@ -3,6 +3,7 @@ package service
 import (
 	"fmt"
 	"log"
+	"sync"
 	"time"
 
 	"github.com/xbox/sing-box-manager/internal/controller/repository"
@@ -46,6 +47,10 @@ type AgentService interface {
 	DeleteAgent(agentID string) error
 	// 更新Agent信息
 	UpdateAgent(agent *models.Agent) error
+	// 获取Agent最近一次心跳上报的连接与流量统计
+	GetConnectionStats(agentID string) (*pb.ConnectionStats, bool)
+	// 获取Agent最近一次心跳上报的sing-box实例状态
+	GetInstances(agentID string) ([]*pb.InstanceStatus, bool)
 }
 
 // agentService Agent业务逻辑实现
@@ -53,6 +58,10 @@ type agentService struct {
 	agentRepo         repository.AgentRepository
 	heartbeatInterval time.Duration
 	maxOfflineTime    time.Duration
+
+	statsMu         sync.RWMutex
+	connectionStats map[string]*pb.ConnectionStats // Agent ID -> 最近一次上报的连接统计
+	instances       map[string][]*pb.InstanceStatus // Agent ID -> 最近一次上报的实例状态
 }
 
 // NewAgentService 创建Agent业务逻辑实例
@@ -61,6 +70,8 @@ func NewAgentService(agentRepo repository.AgentRepository) AgentService {
 		agentRepo:         agentRepo,
 		heartbeatInterval: 30 * time.Second,  // 默认30秒心跳间隔
 		maxOfflineTime:    5 * time.Minute,   // 默认5分钟超时
+		connectionStats:   make(map[string]*pb.ConnectionStats),
+		instances:         make(map[string][]*pb.InstanceStatus),
 	}
 }
 
@@ -203,6 +214,15 @@ func (s *agentService) ProcessHeartbeat(req *pb.HeartbeatRequest) (*pb.Heartbeat
 		}, nil
 	}
 
+	// sing-box崩溃循环时Agent上报error状态，覆盖默认的online
+	if req.Status == "error" {
+		log.Printf("Agent上报sing-box异常: AgentID=%s, State=%s, LastExitCode=%s",
+			req.AgentId, req.Metrics["singbox_state"], req.Metrics["singbox_last_exit_code"])
+		if err := s.agentRepo.UpdateStatus(req.AgentId, "error"); err != nil {
+			log.Printf("更新Agent状态失败: %v", err)
+		}
+	}
+
 	// 如果有指标数据，可以在这里处理
 	if req.Metrics != nil {
 		// 检查是否为卸载状态上报
@@ -221,6 +241,18 @@ func (s *agentService) ProcessHeartbeat(req *pb.HeartbeatRequest) (*pb.Heartbeat
 		// s.processMetrics(req.AgentId, req.Metrics)
 	}
 
+	if req.ConnectionStats != nil {
+		s.processConnectionStats(req.AgentId, req.ConnectionStats)
+	}
+
+	if len(req.Instances) > 0 {
+		s.statsMu.Lock()
+		previous := s.instances[req.AgentId]
+		s.instances[req.AgentId] = req.Instances
+		s.statsMu.Unlock()
+		s.detectOOMKills(req.AgentId, previous, req.Instances)
+	}
+
 	return &pb.HeartbeatResponse{
 		Success:               true,
 		Message:               "心跳处理成功",
@@ -228,6 +260,55 @@ func (s *agentService) ProcessHeartbeat(req *pb.HeartbeatRequest) (*pb.Heartbeat
 	}, nil
 }
 
+// processConnectionStats 缓存连接统计并更新Agent当前连接数
+func (s *agentService) processConnectionStats(agentID string, stats *pb.ConnectionStats) {
+	s.statsMu.Lock()
+	s.connectionStats[agentID] = stats
+	s.statsMu.Unlock()
+
+	if !stats.Available {
+		return
+	}
+	if err := s.agentRepo.UpdateConnections(agentID, int(stats.TotalConnections)); err != nil {
+		log.Printf("更新Agent连接数失败: AgentID=%s, Error=%v", agentID, err)
+	}
+}
+
+// GetConnectionStats 获取Agent最近一次心跳上报的连接与流量统计
+func (s *agentService) GetConnectionStats(agentID string) (*pb.ConnectionStats, bool) {
+	s.statsMu.RLock()
+	defer s.statsMu.RUnlock()
+	stats, ok := s.connectionStats[agentID]
+	return stats, ok
+}
+
+// detectOOMKills 比较前后两次心跳的oom_kill计数，记录新发生的sing-box OOM终止
+func (s *agentService) detectOOMKills(agentID string, previous, current []*pb.InstanceStatus) {
+	before := make(map[string]int64, len(previous))
+	for _, inst := range previous {
+		if inst.ResourceUsage != nil {
+			before[inst.Name] = inst.ResourceUsage.OomKills
+		}
+	}
+	for _, inst := range current {
+		if inst.ResourceUsage == nil {
+			continue
+		}
+		if last, ok := before[inst.Name]; ok && inst.ResourceUsage.OomKills > last {
+			log.Printf("Agent上报sing-box OOM终止: AgentID=%s, Instance=%s, OOMKills=%d, MemoryMax=%d",
+				agentID, inst.Name, inst.ResourceUsage.OomKills, inst.ResourceUsage.MemoryMax)
+		}
+	}
+}
+
+// GetInstances 获取Agent最近一次心跳上报的sing-box实例状态
+func (s *agentService) GetInstances(agentID string) ([]*pb.InstanceStatus, bool) {
+	s.statsMu.RLock()
+	defer s.statsMu.RUnlock()
+	instances, ok := s.instances[agentID]
+	return instances, ok
+}
+
 // GetAgentStatus 获取Agent状态
 func (s *agentService) GetAgentStatus(agentID string) (*pb.StatusResponse, error) {
 	agent, err := s.agentRepo.GetByID(agentID)
@@ -360,6 +441,11 @@ func (s *agentService) DeleteAgent(agentID string) error {
 		return fmt.Errorf("Agent不存在: %v", err)
 	}
 
+	s.statsMu.Lock()
+	delete(s.connectionStats, agentID)
+	delete(s.instances, agentID)
+	s.statsMu.Unlock()
+
 	return s.agentRepo.Delete(agentID)
 }
 
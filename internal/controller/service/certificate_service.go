@@ -0,0 +1,522 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/xbox/sing-box-manager/internal/controller/repository"
+	"github.com/xbox/sing-box-manager/internal/models"
+	pb "github.com/xbox/sing-box-manager/proto/agent"
+	"gorm.io/gorm"
+)
+
+// 证书分配状态
+const (
+	CertAssignmentPending = "pending" // 尚未下发或证书续期后待重新下发
+	CertAssignmentApplied = "applied" // Agent已应用当前证书
+	CertAssignmentFailed  = "failed"  // 最近一次下发失败
+)
+
+// pemPrefix PEM编码的开头，用于识别启用加密前明文保存的私钥
+const pemPrefix = "-----BEGIN"
+
+// certificateNamePattern 证书名称格式，名称会用于Agent上的文件名
+var certificateNamePattern = regexp.MustCompile(`^[A-Za-z0-9._-]{1,128}$`)
+
+// CertificateRequest 上传或续期证书请求
+type CertificateRequest struct {
+	Name           string `json:"name"`                               // 上传时必填，仅允许字母、数字、.、-和_
+	CertificatePEM string `json:"certificate_pem" binding:"required"` // 证书链，叶子证书在前
+	KeyPEM         string `json:"key_pem" binding:"required"`
+	Push           bool   `json:"push"` // 续期后立即下发到全部已分配的入站
+}
+
+// CertificateAssignRequest 证书分配请求
+type CertificateAssignRequest struct {
+	AgentID    string `json:"agent_id" binding:"required"`
+	Instance   string `json:"instance"` // sing-box实例名称，默认default
+	InboundTag string `json:"inbound_tag" binding:"required"`
+}
+
+// CertificateInfo 证书及到期状态
+type CertificateInfo struct {
+	models.Certificate
+	DaysLeft    int                            `json:"days_left"` // 距到期的整天数，已过期时为负数
+	Expiring    bool                           `json:"expiring"`  // 在告警天数内到期
+	Expired     bool                           `json:"expired"`
+	Assignments []models.CertificateAssignment `json:"assignments"`
+}
+
+// CertificatePushResult 一个分配的下发结果
+type CertificatePushResult struct {
+	models.CertificateAssignment
+	Phases []*pb.ApplyPhase `json:"phases,omitempty"` // Agent应用配置的各阶段结果
+}
+
+// CertificateService TLS证书托管服务接口
+type CertificateService interface {
+	// 上传证书，证书与私钥需匹配且未过期
+	CreateCertificate(req *CertificateRequest) (*CertificateInfo, error)
+	// 以新证书替换已有证书，名称不变，req.Push时下发到全部已分配的入站
+	RenewCertificate(id uint, req *CertificateRequest) (*CertificateInfo, []CertificatePushResult, error)
+	GetCertificate(id uint) (*CertificateInfo, error)
+	ListCertificates() ([]CertificateInfo, error)
+	// 获取days天内到期的证书，days不大于0时使用配置的告警天数
+	ListExpiring(days int) ([]CertificateInfo, error)
+	// 删除证书，仍有分配时删除失败
+	DeleteCertificate(id uint) error
+	// 将证书分配给Agent入站并立即下发，入站已分配其他证书时改为使用该证书
+	AssignCertificate(id uint, req *CertificateAssignRequest) (*CertificatePushResult, error)
+	// 取消分配，Agent上的证书文件和入站配置保持不变
+	UnassignCertificate(id, assignmentID uint) error
+	// 将证书重新下发到全部已分配的入站
+	PushCertificate(id uint) ([]CertificatePushResult, error)
+	// 定期检查证书到期并输出告警，ctx取消时返回
+	StartExpiryCheck(ctx context.Context, interval time.Duration)
+}
+
+// certificateService TLS证书托管服务实现
+type certificateService struct {
+	db          *gorm.DB
+	agentRepo   repository.AgentRepository
+	agentClient AgentClient
+	warnDays    int
+	sealer      secretSealer
+}
+
+// NewCertificateService 创建TLS证书托管服务，warnDays为到期告警天数，secretKey用于加密保存私钥
+func NewCertificateService(db *gorm.DB, agentRepo repository.AgentRepository, agentClient AgentClient, warnDays int, secretKey string) CertificateService {
+	if warnDays <= 0 {
+		warnDays = 30
+	}
+	return &certificateService{
+		db:          db,
+		agentRepo:   agentRepo,
+		agentClient: agentClient,
+		warnDays:    warnDays,
+		sealer:      secretSealer{secretKey: secretKey, setting: "certificate.secret_key", subject: "TLS私钥"},
+	}
+}
+
+// CreateCertificate 上传证书
+func (s *certificateService) CreateCertificate(req *CertificateRequest) (*CertificateInfo, error) {
+	if !certificateNamePattern.MatchString(req.Name) {
+		return nil, fmt.Errorf("证书名称无效: %q", req.Name)
+	}
+	var count int64
+	if err := s.db.Model(&models.Certificate{}).Where("name = ?", req.Name).Count(&count).Error; err != nil {
+		return nil, fmt.Errorf("查询证书失败: %w", err)
+	}
+	if count > 0 {
+		return nil, fmt.Errorf("证书 %s 已存在", req.Name)
+	}
+
+	cert := &models.Certificate{Name: req.Name}
+	if err := parseCertificate(cert, req.CertificatePEM, req.KeyPEM); err != nil {
+		return nil, err
+	}
+	if err := s.sealKey(cert, req.KeyPEM); err != nil {
+		return nil, err
+	}
+	if err := s.db.Create(cert).Error; err != nil {
+		return nil, fmt.Errorf("保存证书失败: %w", err)
+	}
+	return s.certificateInfo(*cert, nil), nil
+}
+
+// RenewCertificate 续期证书，指纹变化的分配标记为待下发
+func (s *certificateService) RenewCertificate(id uint, req *CertificateRequest) (*CertificateInfo, []CertificatePushResult, error) {
+	var cert models.Certificate
+	if err := s.db.First(&cert, id).Error; err != nil {
+		return nil, nil, fmt.Errorf("证书 %d 不存在: %w", id, err)
+	}
+	if err := parseCertificate(&cert, req.CertificatePEM, req.KeyPEM); err != nil {
+		return nil, nil, err
+	}
+	if err := s.sealKey(&cert, req.KeyPEM); err != nil {
+		return nil, nil, err
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&cert).Error; err != nil {
+			return fmt.Errorf("保存证书失败: %w", err)
+		}
+		if err := tx.Model(&models.CertificateAssignment{}).
+			Where("certificate_id = ? AND fingerprint <> ?", cert.ID, cert.Fingerprint).
+			Updates(map[string]interface{}{"status": CertAssignmentPending, "error_message": ""}).Error; err != nil {
+			return fmt.Errorf("更新证书分配状态失败: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var results []CertificatePushResult
+	if req.Push {
+		results, err = s.PushCertificate(id)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	info, err := s.GetCertificate(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	return info, results, nil
+}
+
+// GetCertificate 获取证书及其分配
+func (s *certificateService) GetCertificate(id uint) (*CertificateInfo, error) {
+	var cert models.Certificate
+	if err := s.db.First(&cert, id).Error; err != nil {
+		return nil, fmt.Errorf("证书 %d 不存在: %w", id, err)
+	}
+	assignments, err := s.assignments(id)
+	if err != nil {
+		return nil, err
+	}
+	return s.certificateInfo(cert, assignments), nil
+}
+
+// ListCertificates 获取全部证书，按到期时间排序
+func (s *certificateService) ListCertificates() ([]CertificateInfo, error) {
+	return s.listCertificates(s.db)
+}
+
+// ListExpiring 获取即将到期或已过期的证书
+func (s *certificateService) ListExpiring(days int) ([]CertificateInfo, error) {
+	if days <= 0 {
+		days = s.warnDays
+	}
+	return s.listCertificates(s.db.Where("not_after < ?", time.Now().AddDate(0, 0, days)))
+}
+
+// listCertificates 按条件查询证书并附带分配
+func (s *certificateService) listCertificates(db *gorm.DB) ([]CertificateInfo, error) {
+	var certs []models.Certificate
+	if err := db.Order("not_after, id").Find(&certs).Error; err != nil {
+		return nil, fmt.Errorf("查询证书失败: %w", err)
+	}
+	if len(certs) == 0 {
+		return []CertificateInfo{}, nil
+	}
+
+	ids := make([]uint, 0, len(certs))
+	for _, cert := range certs {
+		ids = append(ids, cert.ID)
+	}
+	var assignments []models.CertificateAssignment
+	if err := s.db.Where("certificate_id IN ?", ids).Order("id").Find(&assignments).Error; err != nil {
+		return nil, fmt.Errorf("查询证书分配失败: %w", err)
+	}
+	byCert := make(map[uint][]models.CertificateAssignment)
+	for _, a := range assignments {
+		byCert[a.CertificateID] = append(byCert[a.CertificateID], a)
+	}
+
+	result := make([]CertificateInfo, 0, len(certs))
+	for _, cert := range certs {
+		result = append(result, *s.certificateInfo(cert, byCert[cert.ID]))
+	}
+	return result, nil
+}
+
+// DeleteCertificate 删除未分配的证书
+func (s *certificateService) DeleteCertificate(id uint) error {
+	var count int64
+	if err := s.db.Model(&models.CertificateAssignment{}).Where("certificate_id = ?", id).Count(&count).Error; err != nil {
+		return fmt.Errorf("查询证书分配失败: %w", err)
+	}
+	if count > 0 {
+		return fmt.Errorf("证书 %d 仍分配给 %d 个入站，请先取消分配", id, count)
+	}
+
+	result := s.db.Delete(&models.Certificate{}, id)
+	if result.Error != nil {
+		return fmt.Errorf("删除证书失败: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("证书 %d 不存在", id)
+	}
+	return nil
+}
+
+// AssignCertificate 分配证书并下发
+func (s *certificateService) AssignCertificate(id uint, req *CertificateAssignRequest) (*CertificatePushResult, error) {
+	var cert models.Certificate
+	if err := s.db.First(&cert, id).Error; err != nil {
+		return nil, fmt.Errorf("证书 %d 不存在: %w", id, err)
+	}
+	if _, err := s.agentRepo.GetByID(req.AgentID); err != nil {
+		return nil, fmt.Errorf("Agent %s 不存在: %w", req.AgentID, err)
+	}
+	if req.InboundTag == "" {
+		return nil, fmt.Errorf("入站tag不能为空")
+	}
+	instance := req.Instance
+	if instance == "" {
+		instance = "default"
+	}
+
+	var assignment models.CertificateAssignment
+	err := s.db.Where("agent_id = ? AND instance = ? AND inbound_tag = ?", req.AgentID, instance, req.InboundTag).
+		First(&assignment).Error
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		assignment = models.CertificateAssignment{
+			CertificateID: id,
+			AgentID:       req.AgentID,
+			Instance:      instance,
+			InboundTag:    req.InboundTag,
+			Status:        CertAssignmentPending,
+		}
+		if err := s.db.Create(&assignment).Error; err != nil {
+			return nil, fmt.Errorf("保存证书分配失败: %w", err)
+		}
+	case err != nil:
+		return nil, fmt.Errorf("查询证书分配失败: %w", err)
+	case assignment.CertificateID != id:
+		log.Printf("入站 %s/%s/%s 的证书由 %d 改为 %d", req.AgentID, instance, req.InboundTag, assignment.CertificateID, id)
+		assignment.CertificateID = id
+		assignment.Status = CertAssignmentPending
+		if err := s.db.Save(&assignment).Error; err != nil {
+			return nil, fmt.Errorf("保存证书分配失败: %w", err)
+		}
+	}
+
+	result := s.push(&cert, assignment)
+	return &result, nil
+}
+
+// UnassignCertificate 取消证书分配
+func (s *certificateService) UnassignCertificate(id, assignmentID uint) error {
+	result := s.db.Where("certificate_id = ?", id).Delete(&models.CertificateAssignment{}, assignmentID)
+	if result.Error != nil {
+		return fmt.Errorf("取消证书分配失败: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("证书 %d 的分配 %d 不存在", id, assignmentID)
+	}
+	return nil
+}
+
+// PushCertificate 下发证书到全部已分配的入站，单个分配失败不影响其余分配
+func (s *certificateService) PushCertificate(id uint) ([]CertificatePushResult, error) {
+	var cert models.Certificate
+	if err := s.db.First(&cert, id).Error; err != nil {
+		return nil, fmt.Errorf("证书 %d 不存在: %w", id, err)
+	}
+	assignments, err := s.assignments(id)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]CertificatePushResult, 0, len(assignments))
+	for _, assignment := range assignments {
+		results = append(results, s.push(&cert, assignment))
+	}
+	return results, nil
+}
+
+// push 向Agent下发证书并记录结果
+func (s *certificateService) push(cert *models.Certificate, assignment models.CertificateAssignment) CertificatePushResult {
+	var resp *pb.CertificateResponse
+	keyPEM, err := s.openKey(cert)
+	if err == nil {
+		resp, err = s.agentClient.UpdateCertificate(&pb.CertificateRequest{
+			AgentId:        assignment.AgentID,
+			Instance:       assignment.Instance,
+			InboundTag:     assignment.InboundTag,
+			Name:           cert.Name,
+			CertificatePem: cert.CertPEM,
+			KeyPem:         keyPEM,
+			Fingerprint:    cert.Fingerprint,
+		})
+	}
+
+	now := time.Now()
+	assignment.PushedAt = &now
+	if err != nil {
+		log.Printf("下发证书 %s 到 %s/%s/%s 失败: %v", cert.Name, assignment.AgentID, assignment.Instance, assignment.InboundTag, err)
+		assignment.Status = CertAssignmentFailed
+		assignment.ErrorMessage = err.Error()
+	} else {
+		assignment.Status = CertAssignmentApplied
+		assignment.Fingerprint = cert.Fingerprint
+		assignment.ErrorMessage = ""
+	}
+	if err := s.db.Save(&assignment).Error; err != nil {
+		log.Printf("保存证书分配 %d 的下发结果失败: %v", assignment.ID, err)
+	}
+
+	result := CertificatePushResult{CertificateAssignment: assignment}
+	if resp != nil {
+		result.Phases = resp.Phases
+	}
+	return result
+}
+
+// StartExpiryCheck 定期检查证书到期
+func (s *certificateService) StartExpiryCheck(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = time.Hour
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	s.sealPlaintextKeys()
+	s.checkExpiry()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.checkExpiry()
+		}
+	}
+}
+
+// checkExpiry 对即将到期的证书和尚未应用当前证书的分配输出告警
+func (s *certificateService) checkExpiry() {
+	certs, err := s.ListExpiring(s.warnDays)
+	if err != nil {
+		log.Printf("证书到期检查失败: %v", err)
+		return
+	}
+	for _, cert := range certs {
+		if cert.Expired {
+			log.Printf("证书已过期: %s (到期时间 %s，分配 %d 个入站)", cert.Name, cert.NotAfter.Format(time.RFC3339), len(cert.Assignments))
+		} else {
+			log.Printf("证书即将到期: %s (剩余 %d 天，到期时间 %s，分配 %d 个入站)", cert.Name, cert.DaysLeft, cert.NotAfter.Format(time.RFC3339), len(cert.Assignments))
+		}
+	}
+
+	var stale []models.CertificateAssignment
+	if err := s.db.Table("certificate_assignments").
+		Select("certificate_assignments.*").
+		Joins("JOIN certificates ON certificates.id = certificate_assignments.certificate_id").
+		Where("certificate_assignments.fingerprint <> certificates.fingerprint").
+		Find(&stale).Error; err != nil {
+		log.Printf("查询待下发的证书分配失败: %v", err)
+		return
+	}
+	for _, a := range stale {
+		log.Printf("入站 %s/%s/%s 尚未应用证书 %d 的当前版本，状态: %s", a.AgentID, a.Instance, a.InboundTag, a.CertificateID, a.Status)
+	}
+}
+
+// sealPlaintextKeys 加密启用私钥加密前保存的明文私钥
+func (s *certificateService) sealPlaintextKeys() {
+	if s.sealer.secretKey == "" {
+		return
+	}
+	var certs []models.Certificate
+	if err := s.db.Where("key_pem LIKE ?", pemPrefix+"%").Find(&certs).Error; err != nil {
+		log.Printf("查询明文保存的TLS私钥失败: %v", err)
+		return
+	}
+	for i := range certs {
+		if err := s.sealKey(&certs[i], certs[i].KeyPEM); err != nil {
+			log.Printf("加密证书 %s 的私钥失败: %v", certs[i].Name, err)
+			continue
+		}
+		if err := s.db.Model(&certs[i]).Update("key_pem", certs[i].KeyPEM).Error; err != nil {
+			log.Printf("保存证书 %s 的加密私钥失败: %v", certs[i].Name, err)
+			continue
+		}
+		log.Printf("已加密证书 %s 的私钥", certs[i].Name)
+	}
+}
+
+// sealKey 加密私钥并写入证书，密文与证书名称绑定
+func (s *certificateService) sealKey(cert *models.Certificate, keyPEM string) error {
+	sealed, err := s.sealer.seal(keyPEM, certificateKeyAD(cert))
+	if err != nil {
+		return err
+	}
+	cert.KeyPEM = sealed
+	return nil
+}
+
+// openKey 解密证书私钥，启用加密前保存的明文私钥原样返回
+func (s *certificateService) openKey(cert *models.Certificate) (string, error) {
+	if strings.HasPrefix(cert.KeyPEM, pemPrefix) {
+		return cert.KeyPEM, nil
+	}
+	return s.sealer.open(cert.KeyPEM, certificateKeyAD(cert))
+}
+
+// certificateKeyAD 加密附加数据，防止密文被挪用到其他证书
+func certificateKeyAD(cert *models.Certificate) []byte {
+	return []byte("certificate/" + cert.Name)
+}
+
+// assignments 获取证书的全部分配
+func (s *certificateService) assignments(id uint) ([]models.CertificateAssignment, error) {
+	var assignments []models.CertificateAssignment
+	if err := s.db.Where("certificate_id = ?", id).Order("id").Find(&assignments).Error; err != nil {
+		return nil, fmt.Errorf("查询证书分配失败: %w", err)
+	}
+	return assignments, nil
+}
+
+// certificateInfo 计算证书的到期状态
+func (s *certificateService) certificateInfo(cert models.Certificate, assignments []models.CertificateAssignment) *CertificateInfo {
+	remaining := time.Until(cert.NotAfter)
+	if assignments == nil {
+		assignments = []models.CertificateAssignment{}
+	}
+	return &CertificateInfo{
+		Certificate: cert,
+		DaysLeft:    int(math.Floor(remaining.Hours() / 24)),
+		Expiring:    remaining < time.Duration(s.warnDays)*24*time.Hour,
+		Expired:     remaining <= 0,
+		Assignments: assignments,
+	}
+}
+
+// parseCertificate 校验证书与私钥并解析证书信息，私钥由调用方加密后写入
+func parseCertificate(cert *models.Certificate, certPEM, keyPEM string) error {
+	pair, err := tls.X509KeyPair([]byte(certPEM), []byte(keyPEM))
+	if err != nil {
+		return fmt.Errorf("证书与私钥无效或不匹配: %w", err)
+	}
+	leaf, err := x509.ParseCertificate(pair.Certificate[0])
+	if err != nil {
+		return fmt.Errorf("解析证书失败: %w", err)
+	}
+	if time.Now().After(leaf.NotAfter) {
+		return fmt.Errorf("证书已于 %s 过期", leaf.NotAfter.Format(time.RFC3339))
+	}
+
+	domains := append([]string{}, leaf.DNSNames...)
+	for _, ip := range leaf.IPAddresses {
+		domains = append(domains, ip.String())
+	}
+	if len(domains) == 0 && leaf.Subject.CommonName != "" {
+		domains = append(domains, leaf.Subject.CommonName)
+	}
+	sum := sha256.Sum256(leaf.Raw)
+
+	cert.CertPEM = certPEM
+	cert.Domains = domains
+	cert.Subject = leaf.Subject.String()
+	cert.Issuer = leaf.Issuer.String()
+	cert.SerialNumber = leaf.SerialNumber.Text(16)
+	cert.Fingerprint = hex.EncodeToString(sum[:])
+	cert.NotBefore = leaf.NotBefore
+	cert.NotAfter = leaf.NotAfter
+	return nil
+}
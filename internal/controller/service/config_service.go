@@ -0,0 +1,230 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/xbox/sing-box-manager/internal/agent/singbox"
+	"github.com/xbox/sing-box-manager/internal/controller/repository"
+	"github.com/xbox/sing-box-manager/internal/converter"
+	"github.com/xbox/sing-box-manager/internal/models"
+	pb "github.com/xbox/sing-box-manager/proto/agent"
+	"gorm.io/gorm"
+)
+
+// ConfigService Agent配置管理服务接口
+type ConfigService interface {
+	// 列出Agent的sing-box配置历史代，instance为空时为默认实例
+	ListGenerations(agentID, instance string) ([]*pb.ConfigGeneration, error)
+	// 比较两代配置，toVersion为0表示当前代
+	DiffGenerations(agentID, instance string, fromVersion, toVersion int64) (string, error)
+	// 回滚配置，scope为filter、singbox或dns
+	Rollback(agentID, instance, scope, targetVersion, reason string) error
+	// 语义校验sing-box配置，无问题时返回nil
+	ValidateConfig(content string) singbox.ValidationErrors
+	// 写入托管的Reality密钥后校验并下发完整sing-box配置，校验失败时返回singbox.ValidationErrors，
+	// 入站端口冲突时返回*PortConflictError
+	PushConfig(agentID, instance, content string, force bool) (*models.Config, error)
+	// 下发由配置模板渲染的配置，配置记录中保存模板ID和修订版本
+	PushTemplateConfig(agentID, instance, content string, templateID uint, revision int, force bool) (*models.Config, error)
+	// 将Clash/V2Ray/Xray配置转换为sing-box配置，指定Agent且校验通过时下发
+	ImportConfig(format string, data []byte, agentIDs []string, instance string, force bool) (*ImportResult, error)
+}
+
+// ImportResult 配置导入结果
+type ImportResult struct {
+	Config           json.RawMessage          `json:"config"` // 转换后的sing-box配置
+	Report           *converter.Report        `json:"report"`
+	ValidationErrors singbox.ValidationErrors `json:"validation_errors,omitempty"`
+	Results          []ImportPushResult       `json:"results,omitempty"` // 各Agent下发结果
+}
+
+// ImportPushResult 导入的配置下发到单个Agent的结果
+type ImportPushResult struct {
+	AgentID string         `json:"agent_id"`
+	Success bool           `json:"success"`
+	Config  *models.Config `json:"config,omitempty"` // 配置记录
+	Error   string         `json:"error,omitempty"`
+}
+
+// configService Agent配置管理服务实现
+type configService struct {
+	db             *gorm.DB
+	agentRepo      repository.AgentRepository
+	agentClient    AgentClient
+	realityService RealityService
+}
+
+// NewConfigService 创建配置管理服务
+func NewConfigService(db *gorm.DB, agentRepo repository.AgentRepository, agentClient AgentClient, realityService RealityService) ConfigService {
+	return &configService{
+		db:             db,
+		agentRepo:      agentRepo,
+		agentClient:    agentClient,
+		realityService: realityService,
+	}
+}
+
+// ListGenerations 列出Agent的sing-box配置历史代
+func (s *configService) ListGenerations(agentID, instance string) ([]*pb.ConfigGeneration, error) {
+	if err := s.checkAgent(agentID); err != nil {
+		return nil, err
+	}
+	return s.agentClient.ListConfigGenerations(agentID, instance)
+}
+
+// DiffGenerations 比较Agent的两代sing-box配置
+func (s *configService) DiffGenerations(agentID, instance string, fromVersion, toVersion int64) (string, error) {
+	if err := s.checkAgent(agentID); err != nil {
+		return "", err
+	}
+	if fromVersion <= 0 {
+		return "", fmt.Errorf("from版本必须大于0")
+	}
+	return s.agentClient.DiffConfigGenerations(agentID, instance, fromVersion, toVersion)
+}
+
+// Rollback 回滚Agent配置
+func (s *configService) Rollback(agentID, instance, scope, targetVersion, reason string) error {
+	if err := s.checkAgent(agentID); err != nil {
+		return err
+	}
+
+	switch scope {
+	case "", "filter", "singbox", "dns":
+	default:
+		return fmt.Errorf("不支持的回滚范围: %s", scope)
+	}
+
+	if err := s.agentClient.RollbackConfig(agentID, instance, scope, targetVersion, reason); err != nil {
+		return fmt.Errorf("回滚Agent配置失败: %w", err)
+	}
+	return nil
+}
+
+// ValidateConfig 解析并语义校验sing-box配置
+func (s *configService) ValidateConfig(content string) singbox.ValidationErrors {
+	var config singbox.Config
+	if err := json.Unmarshal([]byte(content), &config); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			return singbox.ValidationErrors{{Path: typeErr.Field, Message: err.Error()}}
+		}
+		return singbox.ValidationErrors{{Message: fmt.Sprintf("配置不是合法的JSON: %v", err)}}
+	}
+	return singbox.Validate(&config)
+}
+
+// PushConfig 校验通过后记录配置并下发到Agent，force为true时Agent忽略入站端口冲突
+func (s *configService) PushConfig(agentID, instance, content string, force bool) (*models.Config, error) {
+	if err := s.checkAgent(agentID); err != nil {
+		return nil, err
+	}
+	return s.pushConfig(newConfigRecord(agentID, instance, content), force)
+}
+
+// PushTemplateConfig 校验通过后下发模板渲染的配置，记录模板来源
+func (s *configService) PushTemplateConfig(agentID, instance, content string, templateID uint, revision int, force bool) (*models.Config, error) {
+	if err := s.checkAgent(agentID); err != nil {
+		return nil, err
+	}
+
+	record := newConfigRecord(agentID, instance, content)
+	record.TemplateID = &templateID
+	record.TemplateRevision = revision
+	return s.pushConfig(record, force)
+}
+
+// ImportConfig 转换配置并校验，指定Agent时逐个下发；校验失败时不下发，结果中带校验错误
+func (s *configService) ImportConfig(format string, data []byte, agentIDs []string, instance string, force bool) (*ImportResult, error) {
+	config, report, err := converter.Convert(format, data)
+	if err != nil {
+		return nil, err
+	}
+	content, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("序列化sing-box配置失败: %w", err)
+	}
+
+	result := &ImportResult{
+		Config:           content,
+		Report:           report,
+		ValidationErrors: singbox.Validate(config),
+	}
+	if result.ValidationErrors != nil {
+		return result, nil
+	}
+
+	for _, agentID := range agentIDs {
+		item := ImportPushResult{AgentID: agentID}
+		record, err := s.PushConfig(agentID, instance, string(content), force)
+		item.Config = record
+		if err != nil {
+			item.Error = err.Error()
+		} else {
+			item.Success = true
+		}
+		result.Results = append(result.Results, item)
+	}
+	return result, nil
+}
+
+// newConfigRecord 创建待下发的配置记录
+func newConfigRecord(agentID, instance, content string) *models.Config {
+	if instance == "" {
+		instance = "default"
+	}
+	return &models.Config{
+		AgentID:       agentID,
+		Instance:      instance,
+		ConfigContent: content,
+		ConfigVersion: fmt.Sprintf("v%d", time.Now().Unix()),
+		Status:        "pending",
+	}
+}
+
+// pushConfig 写入托管的Reality密钥并校验，保存配置记录（不含私钥）后下发到Agent，根据结果更新记录状态
+func (s *configService) pushConfig(record *models.Config, force bool) (*models.Config, error) {
+	content, err := s.realityService.InjectKeys(record.AgentID, record.Instance, record.ConfigContent)
+	if err != nil {
+		return nil, err
+	}
+	if errs := s.ValidateConfig(content); errs != nil {
+		return nil, errs
+	}
+
+	if err := s.db.Create(record).Error; err != nil {
+		return nil, fmt.Errorf("保存配置记录失败: %w", err)
+	}
+
+	pushErr := s.agentClient.UpdateConfig(record.AgentID, record.Instance, content, record.ConfigVersion, force)
+
+	updates := map[string]interface{}{"status": "applied", "error_message": ""}
+	if pushErr != nil {
+		updates = map[string]interface{}{"status": "failed", "error_message": pushErr.Error()}
+	} else {
+		now := time.Now()
+		record.ApplyTime = &now
+		updates["apply_time"] = now
+	}
+	if err := s.db.Model(record).Updates(updates).Error; err != nil {
+		return nil, fmt.Errorf("更新配置记录状态失败: %w", err)
+	}
+	record.Status = updates["status"].(string)
+	record.ErrorMessage = updates["error_message"].(string)
+
+	if pushErr != nil {
+		return record, fmt.Errorf("下发配置到Agent失败: %w", pushErr)
+	}
+	return record, nil
+}
+
+// checkAgent 校验Agent是否存在
+func (s *configService) checkAgent(agentID string) error {
+	if _, err := s.agentRepo.GetByID(agentID); err != nil {
+		return fmt.Errorf("Agent %s 不存在: %w", agentID, err)
+	}
+	return nil
+}
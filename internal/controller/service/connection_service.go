@@ -0,0 +1,72 @@
+package service
+
+import (
+	"fmt"
+
+	"github.com/xbox/sing-box-manager/internal/controller/repository"
+	pb "github.com/xbox/sing-box-manager/proto/agent"
+)
+
+// ConnectionService sing-box连接管理服务接口
+type ConnectionService interface {
+	// 获取Agent最近一次心跳上报的连接与流量统计，instance为空时为默认实例
+	GetStats(agentID, instance string) (*pb.ConnectionStats, error)
+	// 按条件关闭Agent上的连接
+	CloseConnections(req *pb.CloseConnectionsRequest) (*pb.CloseConnectionsResponse, error)
+}
+
+// connectionService sing-box连接管理服务实现
+type connectionService struct {
+	agentRepo    repository.AgentRepository
+	agentService AgentService
+	agentClient  AgentClient
+}
+
+// NewConnectionService 创建连接管理服务
+func NewConnectionService(agentRepo repository.AgentRepository, agentService AgentService, agentClient AgentClient) ConnectionService {
+	return &connectionService{
+		agentRepo:    agentRepo,
+		agentService: agentService,
+		agentClient:  agentClient,
+	}
+}
+
+// GetStats 获取Agent最近一次心跳上报的连接与流量统计
+func (s *connectionService) GetStats(agentID, instance string) (*pb.ConnectionStats, error) {
+	if _, err := s.agentRepo.GetByID(agentID); err != nil {
+		return nil, fmt.Errorf("Agent %s 不存在: %w", agentID, err)
+	}
+
+	if instance != "" && instance != "default" {
+		instances, _ := s.agentService.GetInstances(agentID)
+		for _, inst := range instances {
+			if inst.Name != instance {
+				continue
+			}
+			if inst.ConnectionStats == nil {
+				return nil, fmt.Errorf("Agent %s 的实例 %s 未启用连接统计", agentID, instance)
+			}
+			return inst.ConnectionStats, nil
+		}
+		return nil, fmt.Errorf("Agent %s 尚未上报实例 %s 的状态", agentID, instance)
+	}
+
+	stats, ok := s.agentService.GetConnectionStats(agentID)
+	if !ok {
+		return nil, fmt.Errorf("Agent %s 尚未上报连接统计", agentID)
+	}
+	return stats, nil
+}
+
+// CloseConnections 按条件关闭Agent上的连接
+func (s *connectionService) CloseConnections(req *pb.CloseConnectionsRequest) (*pb.CloseConnectionsResponse, error) {
+	if _, err := s.agentRepo.GetByID(req.AgentId); err != nil {
+		return nil, fmt.Errorf("Agent %s 不存在: %w", req.AgentId, err)
+	}
+
+	if req.Host == "" && req.SourceIp == "" && req.Rule == "" && req.Inbound == "" && req.Outbound == "" && !req.All {
+		return nil, fmt.Errorf("未指定过滤条件，关闭全部连接需设置all")
+	}
+
+	return s.agentClient.CloseConnections(req)
+}
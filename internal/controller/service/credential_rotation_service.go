@@ -0,0 +1,777 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/xbox/sing-box-manager/internal/agent/singbox"
+	"github.com/xbox/sing-box-manager/internal/models"
+	pb "github.com/xbox/sing-box-manager/proto/agent"
+	"gorm.io/gorm"
+)
+
+// 凭据轮换执行状态
+const (
+	CredentialRunOverlap = "overlap" // 新旧凭据同时有效，等待吊销旧凭据
+	CredentialRunRevoked = "revoked" // 旧凭据已吊销
+	CredentialRunFailed  = "failed"  // 下发新凭据失败，Agent仍使用原凭据
+)
+
+// 凭据轮换操作日志类型
+const (
+	OpCredentialRotate = "credential_rotate"
+	OpCredentialRevoke = "credential_revoke"
+)
+
+// credentialOldSuffix 重叠期内旧凭据用户名称的后缀
+const credentialOldSuffix = "~old"
+
+// credentialReplaceAttempts 入站用户在读取与替换之间被修改时的最大尝试次数
+const credentialReplaceAttempts = 3
+
+const (
+	// credentialRunLease 执行租约时长，执行中断（如Controller重启）后租约到期即可再次执行
+	credentialRunLease = 30 * time.Minute
+	// credentialRetryDelay 执行全部失败后的重试间隔
+	credentialRetryDelay = time.Hour
+)
+
+// credentialInboundTypes 支持凭据轮换的入站类型。mixed/http/socks的用户名即凭据，无法新旧并存，不在其中
+var credentialInboundTypes = map[string]bool{
+	"vmess":       true,
+	"vless":       true,
+	"trojan":      true,
+	"shadowsocks": true,
+	"hysteria2":   true,
+	"tuic":        true,
+}
+
+// CredentialRotationRequest 创建或修改凭据轮换计划请求
+type CredentialRotationRequest struct {
+	Name         string   `json:"name" binding:"required"`
+	AgentIDs     []string `json:"agent_ids"`    // 为空时为全部Agent
+	InboundTags  []string `json:"inbound_tags"` // 为空时为全部支持轮换的入站
+	UserNames    []string `json:"user_names"`   // 为空时为入站的全部用户
+	IntervalDays int      `json:"interval_days" binding:"required,min=1"`
+	OverlapHours *int     `json:"overlap_hours"` // 新旧凭据同时有效的时长，默认24，0为立即吊销
+	Enabled      *bool    `json:"enabled"`       // 默认启用
+}
+
+// CredentialRotationResult 一次轮换的执行结果
+type CredentialRotationResult struct {
+	Runs            []models.CredentialRotationRun `json:"runs"`             // 各入站的执行记录
+	UnmatchedAgents []string                       `json:"unmatched_agents"` // 没有需要轮换的入站或用户的Agent
+	FailedAgents    map[string]string              `json:"failed_agents"`    // 无法获取入站或入站用户的Agent及原因
+}
+
+// CredentialRotationService 入站用户凭据轮换服务接口
+type CredentialRotationService interface {
+	CreateRotation(req *CredentialRotationRequest) (*models.CredentialRotation, error)
+	UpdateRotation(id uint, req *CredentialRotationRequest) (*models.CredentialRotation, error)
+	GetRotation(id uint) (*models.CredentialRotation, error)
+	ListRotations() ([]models.CredentialRotation, error)
+	// 删除轮换计划，已轮换的旧凭据仍按计划吊销
+	DeleteRotation(id uint) error
+	// 立即执行一次轮换，operator记录在操作日志中；同一计划正在执行时返回错误
+	RunRotation(id uint, operator string) (*CredentialRotationResult, error)
+	// 获取轮换计划的执行记录，按时间倒序
+	ListRuns(id uint, limit int) ([]models.CredentialRotationRun, error)
+	// 定期执行到期的轮换并吊销重叠期结束的旧凭据，ctx取消时返回
+	StartScheduler(ctx context.Context, interval time.Duration)
+}
+
+// credentialRotationService 入站用户凭据轮换服务实现
+type credentialRotationService struct {
+	db          *gorm.DB
+	agentClient AgentClient
+}
+
+// NewCredentialRotationService 创建入站用户凭据轮换服务
+func NewCredentialRotationService(db *gorm.DB, agentClient AgentClient) CredentialRotationService {
+	return &credentialRotationService{
+		db:          db,
+		agentClient: agentClient,
+	}
+}
+
+// CreateRotation 创建凭据轮换计划，首次轮换在一个周期后执行
+func (s *credentialRotationService) CreateRotation(req *CredentialRotationRequest) (*models.CredentialRotation, error) {
+	rotation := &models.CredentialRotation{Enabled: true, OverlapHours: 24}
+	if err := applyCredentialRotationRequest(rotation, req); err != nil {
+		return nil, err
+	}
+	next := time.Now().AddDate(0, 0, rotation.IntervalDays)
+	rotation.NextRunAt = &next
+	if err := s.db.Create(rotation).Error; err != nil {
+		return nil, fmt.Errorf("创建凭据轮换计划失败: %w", err)
+	}
+	return rotation, nil
+}
+
+// UpdateRotation 修改凭据轮换计划，下次轮换时间从上次轮换起重新计算
+func (s *credentialRotationService) UpdateRotation(id uint, req *CredentialRotationRequest) (*models.CredentialRotation, error) {
+	rotation, err := s.GetRotation(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := applyCredentialRotationRequest(rotation, req); err != nil {
+		return nil, err
+	}
+	from := rotation.CreatedAt
+	if rotation.LastRunAt != nil {
+		from = *rotation.LastRunAt
+	}
+	next := from.AddDate(0, 0, rotation.IntervalDays)
+	rotation.NextRunAt = &next
+	if err := s.db.Save(rotation).Error; err != nil {
+		return nil, fmt.Errorf("更新凭据轮换计划失败: %w", err)
+	}
+	return rotation, nil
+}
+
+// applyCredentialRotationRequest 校验请求并写入轮换计划
+func applyCredentialRotationRequest(rotation *models.CredentialRotation, req *CredentialRotationRequest) error {
+	if req.Name == "" {
+		return fmt.Errorf("轮换计划名称不能为空")
+	}
+	if req.IntervalDays < 1 {
+		return fmt.Errorf("轮换周期至少为1天")
+	}
+	if req.OverlapHours != nil {
+		if *req.OverlapHours < 0 {
+			return fmt.Errorf("重叠时长不能为负数")
+		}
+		if *req.OverlapHours >= req.IntervalDays*24 {
+			return fmt.Errorf("重叠时长需短于轮换周期")
+		}
+		rotation.OverlapHours = *req.OverlapHours
+	} else if rotation.OverlapHours >= req.IntervalDays*24 {
+		return fmt.Errorf("重叠时长需短于轮换周期")
+	}
+	for _, name := range req.UserNames {
+		if strings.HasSuffix(name, credentialOldSuffix) {
+			return fmt.Errorf("用户标识不能以 %s 结尾: %s", credentialOldSuffix, name)
+		}
+	}
+
+	rotation.Name = req.Name
+	rotation.AgentIDs = req.AgentIDs
+	rotation.InboundTags = req.InboundTags
+	rotation.UserNames = req.UserNames
+	rotation.IntervalDays = req.IntervalDays
+	if req.Enabled != nil {
+		rotation.Enabled = *req.Enabled
+	}
+	return nil
+}
+
+// GetRotation 获取凭据轮换计划
+func (s *credentialRotationService) GetRotation(id uint) (*models.CredentialRotation, error) {
+	var rotation models.CredentialRotation
+	if err := s.db.First(&rotation, id).Error; err != nil {
+		return nil, fmt.Errorf("凭据轮换计划 %d 不存在: %w", id, err)
+	}
+	return &rotation, nil
+}
+
+// ListRotations 获取全部凭据轮换计划
+func (s *credentialRotationService) ListRotations() ([]models.CredentialRotation, error) {
+	var rotations []models.CredentialRotation
+	if err := s.db.Order("id").Find(&rotations).Error; err != nil {
+		return nil, fmt.Errorf("查询凭据轮换计划失败: %w", err)
+	}
+	return rotations, nil
+}
+
+// DeleteRotation 删除凭据轮换计划
+func (s *credentialRotationService) DeleteRotation(id uint) error {
+	result := s.db.Delete(&models.CredentialRotation{}, id)
+	if result.Error != nil {
+		return fmt.Errorf("删除凭据轮换计划失败: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("凭据轮换计划 %d 不存在", id)
+	}
+	return nil
+}
+
+// ListRuns 获取轮换执行记录
+func (s *credentialRotationService) ListRuns(id uint, limit int) ([]models.CredentialRotationRun, error) {
+	if limit <= 0 {
+		limit = 100
+	}
+	var runs []models.CredentialRotationRun
+	if err := s.db.Where("rotation_id = ?", id).Order("id DESC").Limit(limit).Find(&runs).Error; err != nil {
+		return nil, fmt.Errorf("查询凭据轮换记录失败: %w", err)
+	}
+	return runs, nil
+}
+
+// StartScheduler 定期执行到期的轮换和吊销
+func (s *credentialRotationService) StartScheduler(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = 5 * time.Minute
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			// 先吊销到期的旧凭据，避免同一用户的旧凭据尚未吊销又开始新一轮轮换
+			s.revokeDue()
+			s.runDue()
+		}
+	}
+}
+
+// runDue 执行到期的轮换计划
+func (s *credentialRotationService) runDue() {
+	var rotations []models.CredentialRotation
+	if err := s.db.Where("enabled = ? AND next_run_at <= ?", true, time.Now()).Find(&rotations).Error; err != nil {
+		log.Printf("查询到期的凭据轮换计划失败: %v", err)
+		return
+	}
+	for _, rotation := range rotations {
+		if _, err := s.RunRotation(rotation.ID, "scheduler"); err != nil {
+			log.Printf("执行凭据轮换计划 %s 失败: %v", rotation.Name, err)
+		}
+	}
+}
+
+// RunRotation 对计划覆盖的每个Agent入站生成新凭据并下发，旧凭据改名保留至重叠期结束。
+// 入站和用户取自Agent当前生效的配置，没有匹配入站的Agent和无法获取入站的Agent记录在结果中。
+// 执行前以条件更新获取执行租约，同一计划不会并发执行
+func (s *credentialRotationService) RunRotation(id uint, operator string) (*CredentialRotationResult, error) {
+	rotation, err := s.GetRotation(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.claimRun(rotation); err != nil {
+		return nil, err
+	}
+
+	result, err := s.runRotation(rotation, operator)
+	if finishErr := s.finishRun(rotation, result, err); finishErr != nil && err == nil {
+		err = finishErr
+	}
+	return result, err
+}
+
+// claimRun 获取执行租约，租约未到期时说明计划正在执行
+func (s *credentialRotationService) claimRun(rotation *models.CredentialRotation) error {
+	now := time.Now()
+	result := s.db.Model(&models.CredentialRotation{}).
+		Where("id = ? AND (running_until IS NULL OR running_until < ?)", rotation.ID, now).
+		UpdateColumn("running_until", now.Add(credentialRunLease))
+	if result.Error != nil {
+		return fmt.Errorf("更新凭据轮换计划失败: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("凭据轮换计划 %s 正在执行", rotation.Name)
+	}
+	return nil
+}
+
+// finishRun 释放执行租约。至少一个Agent轮换成功或没有失败时按周期安排下次轮换，
+// 否则记录失败原因并在重试间隔后再次执行
+func (s *credentialRotationService) finishRun(rotation *models.CredentialRotation, result *CredentialRotationResult, runErr error) error {
+	now := time.Now()
+	updates := map[string]interface{}{"running_until": nil}
+	if runErr == nil && result.succeeded() {
+		updates["last_run_at"] = now
+		updates["next_run_at"] = now.AddDate(0, 0, rotation.IntervalDays)
+		updates["last_error"] = ""
+	} else {
+		var message string
+		if runErr != nil {
+			message = runErr.Error()
+		} else {
+			message = result.failureSummary()
+		}
+		retryAt := now.Add(credentialRetryDelay)
+		updates["next_run_at"] = retryAt
+		updates["last_error"] = message
+		log.Printf("凭据轮换计划 %s 没有成功轮换的Agent，将于 %s 重试: %s", rotation.Name, retryAt.Format(time.RFC3339), message)
+	}
+	if err := s.db.Model(rotation).UpdateColumns(updates).Error; err != nil {
+		return fmt.Errorf("更新凭据轮换计划失败: %w", err)
+	}
+	return nil
+}
+
+// runRotation 执行一次轮换
+func (s *credentialRotationService) runRotation(rotation *models.CredentialRotation, operator string) (*CredentialRotationResult, error) {
+	agentIDs := rotation.AgentIDs
+	if len(agentIDs) == 0 {
+		if err := s.db.Model(&models.Agent{}).Order("id").Pluck("id", &agentIDs).Error; err != nil {
+			return nil, fmt.Errorf("查询Agent列表失败: %w", err)
+		}
+	}
+
+	result := &CredentialRotationResult{FailedAgents: make(map[string]string)}
+	for _, agentID := range agentIDs {
+		// 逐个Agent续约，避免Agent较多时租约在执行中到期
+		if err := s.db.Model(rotation).UpdateColumn("running_until", time.Now().Add(credentialRunLease)).Error; err != nil {
+			log.Printf("凭据轮换计划 %s 续约失败: %v", rotation.Name, err)
+		}
+		definitions, err := s.agentClient.GetInbounds(agentID)
+		if err != nil {
+			log.Printf("凭据轮换计划 %s 无法获取Agent %s 的入站: %v", rotation.Name, agentID, err)
+			result.FailedAgents[agentID] = err.Error()
+			continue
+		}
+
+		matched := false
+		for _, definition := range definitions {
+			var inbound singbox.Inbound
+			if err := json.Unmarshal([]byte(definition.Config), &inbound); err != nil {
+				log.Printf("解析Agent %s 的入站 %s/%s 失败，跳过: %v", agentID, definition.Instance, definition.Tag, err)
+				continue
+			}
+			if !rotatableInbound(&inbound, rotation.InboundTags) {
+				continue
+			}
+			run, err := s.rotateInbound(rotation, agentID, definition.Instance, &inbound, operator)
+			if err != nil {
+				log.Printf("凭据轮换计划 %s 在 %s/%s/%s 上执行失败: %v", rotation.Name, agentID, definition.Instance, inbound.Tag, err)
+				if run == nil {
+					matched = true
+					result.FailedAgents[agentID] = fmt.Sprintf("%s/%s: %v", definition.Instance, inbound.Tag, err)
+				}
+			}
+			if run != nil {
+				matched = true
+				result.Runs = append(result.Runs, *run)
+			}
+		}
+		if !matched {
+			log.Printf("凭据轮换计划 %s 在Agent %s 上没有需要轮换的入站或用户", rotation.Name, agentID)
+			result.UnmatchedAgents = append(result.UnmatchedAgents, agentID)
+		}
+	}
+
+	log.Printf("凭据轮换计划 %s 执行完成: 轮换 %d 个入站，%d 个Agent没有匹配的入站或用户，%d 个Agent获取入站失败",
+		rotation.Name, len(result.Runs), len(result.UnmatchedAgents), len(result.FailedAgents))
+	return result, nil
+}
+
+// succeeded 至少一个入站轮换成功，或没有任何失败（各Agent均无需轮换）时视为执行成功
+func (r *CredentialRotationResult) succeeded() bool {
+	failed := len(r.FailedAgents) > 0
+	for _, run := range r.Runs {
+		if run.Status != CredentialRunFailed {
+			return true
+		}
+		failed = true
+	}
+	return !failed
+}
+
+// failureSummary 汇总失败的Agent和入站
+func (r *CredentialRotationResult) failureSummary() string {
+	var failures []string
+	for agentID, reason := range r.FailedAgents {
+		failures = append(failures, agentID+": "+reason)
+	}
+	for _, run := range r.Runs {
+		if run.Status == CredentialRunFailed {
+			failures = append(failures, fmt.Sprintf("%s/%s: %s", run.AgentID, run.Instance, run.ErrorMessage))
+		}
+	}
+	sort.Strings(failures)
+	return strings.Join(failures, "; ")
+}
+
+// rotateInbound 读取Agent入站当前的用户，生成新凭据后替换用户列表，没有需要轮换的用户时返回nil。
+// 新旧凭据在同一次替换中写入，Agent只应用一次配置，配置的其余部分保持不变
+func (s *credentialRotationService) rotateInbound(rotation *models.CredentialRotation, agentID, instance string, inbound *singbox.Inbound, operator string) (*models.CredentialRotationRun, error) {
+	rotated, resp, err := s.replaceRotatedUsers(rotation, agentID, instance, inbound)
+	if rotated == nil {
+		return nil, err
+	}
+
+	run := &models.CredentialRotationRun{
+		RotationID: rotation.ID,
+		AgentID:    agentID,
+		Instance:   instance,
+		Users:      rotated,
+	}
+	if err != nil {
+		run.Status = CredentialRunFailed
+		run.ErrorMessage = err.Error()
+	} else {
+		revokeAt := time.Now().Add(time.Duration(rotation.OverlapHours) * time.Hour)
+		run.Status = CredentialRunOverlap
+		run.RevokeAt = &revokeAt
+	}
+	if dbErr := s.db.Create(run).Error; dbErr != nil {
+		log.Printf("保存凭据轮换记录失败: %v", dbErr)
+	}
+
+	content := models.JSON{
+		"rotation_id":   rotation.ID,
+		"rotation_name": rotation.Name,
+		"run_id":        run.ID,
+		"instance":      instance,
+		"inbound_tag":   inbound.Tag,
+		"users":         rotated,
+		"revoke_at":     run.RevokeAt,
+	}
+	addUsersResponse(content, resp)
+	s.recordOpLog(agentID, OpCredentialRotate, content, operator, err)
+	if err != nil {
+		return run, err
+	}
+
+	// 不保留重叠期时立即吊销旧凭据
+	if rotation.OverlapHours == 0 {
+		if err := s.revoke(run, operator); err != nil {
+			return run, err
+		}
+	}
+	return run, nil
+}
+
+// replaceRotatedUsers 读取入站用户并生成新凭据，携带读取时的修订替换用户列表。
+// 用户在读取后被其他更新修改时Agent拒绝替换，重新读取后重试，不会覆盖并发的用户变更。
+// 没有需要轮换的用户或替换前失败时rotated为nil
+func (s *credentialRotationService) replaceRotatedUsers(rotation *models.CredentialRotation, agentID, instance string, inbound *singbox.Inbound) ([]string, *pb.InboundUsersResponse, error) {
+	for attempt := 1; ; attempt++ {
+		current, err := s.agentClient.GetInboundUsers(agentID, instance, inbound.Tag)
+		if err != nil {
+			return nil, nil, fmt.Errorf("获取入站用户失败: %w", err)
+		}
+		inbound.Users, err = inboundUsersFromPB(current.Users)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		rotated, err := rotateInboundUsers(inbound, rotation.UserNames)
+		if err != nil || len(rotated) == 0 {
+			return nil, nil, err
+		}
+		users, err := inboundUsersToPB(inbound.Users)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		resp, err := s.agentClient.UpdateInboundUsers(&pb.InboundUsersRequest{
+			AgentId:    agentID,
+			Instance:   instance,
+			InboundTag: inbound.Tag,
+			Operation:  singbox.UserOpReplace,
+			Users:      users,
+			Revision:   current.Revision,
+		})
+		if errors.Is(err, ErrInboundUsersChanged) && attempt < credentialReplaceAttempts {
+			log.Printf("入站 %s/%s/%s 的用户在轮换期间被修改，重新读取后重试", agentID, instance, inbound.Tag)
+			continue
+		}
+		return rotated, resp, err
+	}
+}
+
+// revokeDue 吊销重叠期已结束的旧凭据，失败时下次检查重试
+func (s *credentialRotationService) revokeDue() {
+	var runs []models.CredentialRotationRun
+	if err := s.db.Where("status = ? AND revoke_at <= ?", CredentialRunOverlap, time.Now()).Find(&runs).Error; err != nil {
+		log.Printf("查询待吊销的旧凭据失败: %v", err)
+		return
+	}
+	for i := range runs {
+		if err := s.revoke(&runs[i], "scheduler"); err != nil {
+			log.Printf("吊销 %s/%s 的旧凭据失败: %v", runs[i].AgentID, runs[i].Instance, err)
+		}
+	}
+}
+
+// revoke 从Agent入站中删除轮换留下的旧凭据，每个入站的删除结果记录一条操作日志
+func (s *credentialRotationService) revoke(run *models.CredentialRotationRun, operator string) error {
+	var errs []string
+	for _, tag := range credentialRunTags(run.Users) {
+		resp, err := s.removeOldCredentials(run, tag)
+		if resp == nil && err == nil {
+			continue
+		}
+		content := models.JSON{
+			"rotation_id": run.RotationID,
+			"run_id":      run.ID,
+			"instance":    run.Instance,
+			"inbound_tag": tag,
+			"users":       run.Users,
+		}
+		addUsersResponse(content, resp)
+		s.recordOpLog(run.AgentID, OpCredentialRevoke, content, operator, err)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", tag, err))
+		}
+	}
+
+	var err error
+	updates := map[string]interface{}{"error_message": ""}
+	if len(errs) > 0 {
+		err = fmt.Errorf("%s", strings.Join(errs, "; "))
+		updates["error_message"] = err.Error()
+	} else {
+		now := time.Now()
+		run.Status = CredentialRunRevoked
+		run.RevokedAt = &now
+		updates["status"] = CredentialRunRevoked
+		updates["revoked_at"] = now
+	}
+	if dbErr := s.db.Model(run).Updates(updates).Error; dbErr != nil {
+		log.Printf("更新凭据轮换记录 %d 失败: %v", run.ID, dbErr)
+	}
+	return err
+}
+
+// removeOldCredentials 删除入站中run各用户的旧凭据，入站中已没有旧凭据时返回nil, nil
+func (s *credentialRotationService) removeOldCredentials(run *models.CredentialRotationRun, tag string) (*pb.InboundUsersResponse, error) {
+	current, err := s.agentClient.GetInboundUsers(run.AgentID, run.Instance, tag)
+	if err != nil {
+		return nil, fmt.Errorf("获取入站用户失败: %w", err)
+	}
+
+	old := make(map[string]bool, len(run.Users))
+	for _, user := range run.Users {
+		old[user+credentialOldSuffix] = true
+	}
+	users, err := inboundUsersFromPB(current.Users)
+	if err != nil {
+		return nil, err
+	}
+	var remove []*pb.InboundUser
+	for _, user := range users {
+		key := singbox.UserKey(current.InboundType, user)
+		if old[tag+"/"+key] {
+			remove = append(remove, &pb.InboundUser{Name: user.Name, Username: user.Username})
+		}
+	}
+	if len(remove) == 0 {
+		return nil, nil
+	}
+
+	resp, err := s.agentClient.UpdateInboundUsers(&pb.InboundUsersRequest{
+		AgentId:    run.AgentID,
+		Instance:   run.Instance,
+		InboundTag: tag,
+		Operation:  singbox.UserOpRemove,
+		Users:      remove,
+	})
+	if err != nil {
+		return resp, fmt.Errorf("删除旧凭据失败: %w", err)
+	}
+	return resp, nil
+}
+
+// credentialRunTags 返回执行记录中的用户（入站tag/用户标识）涉及的入站tag
+func credentialRunTags(users []string) []string {
+	seen := make(map[string]bool)
+	var tags []string
+	for _, user := range users {
+		tag, _, ok := strings.Cut(user, "/")
+		if !ok || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		tags = append(tags, tag)
+	}
+	return tags
+}
+
+// addUsersResponse 将Agent更新入站用户的结果写入操作日志内容
+func addUsersResponse(content models.JSON, resp *pb.InboundUsersResponse) {
+	if resp == nil {
+		return
+	}
+	content["message"] = resp.Message
+	content["user_count"] = len(resp.Users)
+	if len(resp.Phases) > 0 {
+		content["phases"] = resp.Phases
+	}
+}
+
+// inboundUsersFromPB 将protobuf用户转换为sing-box用户，未建模的字段保存在Extra中
+func inboundUsersFromPB(users []*pb.InboundUser) ([]singbox.InboundUser, error) {
+	result := make([]singbox.InboundUser, 0, len(users))
+	for _, user := range users {
+		converted := singbox.InboundUser{
+			Name:     user.Name,
+			Username: user.Username,
+			Password: user.Password,
+			UUID:     user.Uuid,
+			Flow:     user.Flow,
+			AlterID:  int(user.AlterId),
+		}
+		if user.Extra != "" {
+			extra, err := singbox.ParseRawFields([]byte(user.Extra))
+			if err != nil {
+				return nil, fmt.Errorf("解析用户 %s%s 的未建模字段失败: %w", user.Name, user.Username, err)
+			}
+			converted.Extra = extra
+		}
+		result = append(result, converted)
+	}
+	return result, nil
+}
+
+// inboundUsersToPB 将sing-box用户转换为protobuf用户，Extra随用户写回
+func inboundUsersToPB(users []singbox.InboundUser) ([]*pb.InboundUser, error) {
+	result := make([]*pb.InboundUser, 0, len(users))
+	for _, user := range users {
+		converted := &pb.InboundUser{
+			Name:     user.Name,
+			Username: user.Username,
+			Password: user.Password,
+			Uuid:     user.UUID,
+			Flow:     user.Flow,
+			AlterId:  int32(user.AlterID),
+		}
+		if len(user.Extra.Fields) > 0 {
+			extra, err := user.Extra.MarshalObject()
+			if err != nil {
+				return nil, fmt.Errorf("编码用户 %s%s 的未建模字段失败: %w", user.Name, user.Username, err)
+			}
+			converted.Extra = string(extra)
+		}
+		result = append(result, converted)
+	}
+	return result, nil
+}
+
+// recordOpLog 记录凭据轮换操作日志
+func (s *credentialRotationService) recordOpLog(agentID, operationType string, content models.JSON, operator string, opErr error) {
+	entry := &models.OpLog{
+		AgentID:          &agentID,
+		OperationType:    operationType,
+		OperationContent: content,
+		Result:           "success",
+		Operator:         operator,
+	}
+	if opErr != nil {
+		entry.Result = "failed"
+		entry.ErrorMessage = opErr.Error()
+	}
+	if err := s.db.Create(entry).Error; err != nil {
+		log.Printf("记录操作日志失败: %v", err)
+	}
+}
+
+// rotatableInbound 判断入站是否支持轮换且在计划范围内，tags为空时不限
+func rotatableInbound(inbound *singbox.Inbound, tags []string) bool {
+	if !credentialInboundTypes[inbound.Type] {
+		return false
+	}
+	// 单用户或非2022系列的shadowsocks不支持多用户
+	if inbound.Type == "shadowsocks" && !strings.HasPrefix(inbound.Method, "2022-") {
+		return false
+	}
+	if len(tags) == 0 {
+		return true
+	}
+	for _, tag := range tags {
+		if tag == inbound.Tag {
+			return true
+		}
+	}
+	return false
+}
+
+// rotateInboundUsers 为入站中匹配的用户生成新凭据，原凭据以带后缀的名称保留，返回轮换的用户（入站tag/用户标识）。
+// userNames为空时为全部用户，上一次轮换的旧凭据尚未吊销的用户本次跳过
+func rotateInboundUsers(inbound *singbox.Inbound, userNames []string) ([]string, error) {
+	names := make(map[string]bool, len(userNames))
+	for _, name := range userNames {
+		names[name] = true
+	}
+	existing := make(map[string]bool, len(inbound.Users))
+	for _, user := range inbound.Users {
+		existing[singbox.UserKey(inbound.Type, user)] = true
+	}
+
+	var rotated []string
+	var oldUsers []singbox.InboundUser
+	for j := range inbound.Users {
+		user := &inbound.Users[j]
+		key := singbox.UserKey(inbound.Type, *user)
+		if key == "" || strings.HasSuffix(key, credentialOldSuffix) || existing[key+credentialOldSuffix] {
+			continue
+		}
+		if len(names) > 0 && !names[key] {
+			continue
+		}
+
+		old := *user
+		old.Name = key + credentialOldSuffix
+		updated, err := newCredentials(inbound, *user)
+		if err != nil {
+			return nil, err
+		}
+		if err := singbox.ValidateInboundUser(inbound.Type, updated); err != nil {
+			return nil, err
+		}
+		*user = updated
+		oldUsers = append(oldUsers, old)
+		rotated = append(rotated, inbound.Tag+"/"+key)
+	}
+	inbound.Users = append(inbound.Users, oldUsers...)
+	sort.Strings(rotated)
+	return rotated, nil
+}
+
+// newCredentials 按入站类型为用户生成新的uuid或密码，其余字段保持不变
+func newCredentials(inbound *singbox.Inbound, user singbox.InboundUser) (singbox.InboundUser, error) {
+	var err error
+	switch inbound.Type {
+	case "vmess", "vless":
+		user.UUID, err = newUUID()
+	case "tuic":
+		if user.UUID, err = newUUID(); err == nil {
+			user.Password, err = randomBase64(18, base64.RawURLEncoding)
+		}
+	case "shadowsocks":
+		// 2022系列的用户密码是与加密方法密钥长度相同的base64密钥
+		size := 32
+		if inbound.Method == "2022-blake3-aes-128-gcm" {
+			size = 16
+		}
+		user.Password, err = randomBase64(size, base64.StdEncoding)
+	default:
+		user.Password, err = randomBase64(18, base64.RawURLEncoding)
+	}
+	if err != nil {
+		return user, fmt.Errorf("生成凭据失败: %w", err)
+	}
+	return user, nil
+}
+
+// newUUID 生成随机UUID（版本4）
+func newUUID() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	buf[6] = buf[6]&0x0f | 0x40
+	buf[8] = buf[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", buf[0:4], buf[4:6], buf[6:8], buf[8:10], buf[10:16]), nil
+}
+
+// randomBase64 生成size字节的随机数并编码
+func randomBase64(size int, encoding *base64.Encoding) (string, error) {
+	buf := make([]byte, size)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return encoding.EncodeToString(buf), nil
+}
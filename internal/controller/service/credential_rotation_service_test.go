@@ -0,0 +1,254 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/xbox/sing-box-manager/internal/agent/singbox"
+	"github.com/xbox/sing-box-manager/internal/models"
+	pb "github.com/xbox/sing-box-manager/proto/agent"
+)
+
+// inboundUsersAgentClient 只实现入站用户查询和更新的Agent客户端
+type inboundUsersAgentClient struct {
+	AgentClient
+	current    *pb.InboundUsersResponse
+	updates    []string          // 收到的更新操作（入站tag/操作/用户名）
+	revisions  []string          // 更新请求携带的修订
+	concurrent []*pb.InboundUser // 每次更新前由其他调用方追加的用户，模拟并发修改
+}
+
+func (c *inboundUsersAgentClient) GetInboundUsers(agentID, instance, inboundTag string) (*pb.InboundUsersResponse, error) {
+	users := append([]*pb.InboundUser(nil), c.current.Users...)
+	return &pb.InboundUsersResponse{Success: true, InboundType: c.current.InboundType, Users: users, Revision: c.current.Revision}, nil
+}
+
+func (c *inboundUsersAgentClient) UpdateInboundUsers(req *pb.InboundUsersRequest) (*pb.InboundUsersResponse, error) {
+	c.revisions = append(c.revisions, req.Revision)
+	if len(c.concurrent) > 0 {
+		c.current.Users = append(c.current.Users, c.concurrent[0])
+		c.current.Revision += "+"
+		c.concurrent = c.concurrent[1:]
+	}
+	if req.Revision != "" && req.Revision != c.current.Revision {
+		return &pb.InboundUsersResponse{RevisionMismatch: true}, fmt.Errorf("%w: 修订不一致", ErrInboundUsersChanged)
+	}
+	for _, user := range req.Users {
+		c.updates = append(c.updates, req.InboundTag+"/"+req.Operation+"/"+user.Name)
+	}
+	if req.Operation == singbox.UserOpReplace {
+		c.current.Users = req.Users
+	}
+	return &pb.InboundUsersResponse{Success: true, Message: "ok"}, nil
+}
+
+func TestRotatableInbound(t *testing.T) {
+	tests := []struct {
+		name    string
+		inbound singbox.Inbound
+		tags    []string
+		want    bool
+	}{
+		{"vless不限tag", singbox.Inbound{Type: "vless", Tag: "a"}, nil, true},
+		{"tag在范围内", singbox.Inbound{Type: "trojan", Tag: "a"}, []string{"b", "a"}, true},
+		{"tag不在范围内", singbox.Inbound{Type: "trojan", Tag: "a"}, []string{"b"}, false},
+		{"用户名即凭据的入站", singbox.Inbound{Type: "socks", Tag: "a"}, nil, false},
+		{"shadowsocks 2022", singbox.Inbound{Type: "shadowsocks", Tag: "a", Method: "2022-blake3-aes-128-gcm"}, nil, true},
+		{"shadowsocks旧加密方法", singbox.Inbound{Type: "shadowsocks", Tag: "a", Method: "aes-128-gcm"}, nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := rotatableInbound(&tt.inbound, tt.tags); got != tt.want {
+				t.Errorf("rotatableInbound() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRotateInboundUsers(t *testing.T) {
+	inbound := &singbox.Inbound{Type: "vless", Tag: "vless-in", Users: []singbox.InboundUser{
+		{Name: "alice", UUID: "u1", Flow: "xtls-rprx-vision"},
+		{Name: "bob", UUID: "u2"},
+		{Name: "carol", UUID: "u3"},
+		{Name: "carol~old", UUID: "u0"},
+	}}
+
+	rotated, err := rotateInboundUsers(inbound, []string{"alice", "carol"})
+	if err != nil {
+		t.Fatalf("rotateInboundUsers() error = %v", err)
+	}
+	// carol上一次轮换的旧凭据尚未吊销，本次跳过
+	if want := []string{"vless-in/alice"}; !reflect.DeepEqual(rotated, want) {
+		t.Fatalf("rotated = %v, want %v", rotated, want)
+	}
+	if len(inbound.Users) != 5 {
+		t.Fatalf("用户数 = %d, want 5", len(inbound.Users))
+	}
+	alice, old := inbound.Users[0], inbound.Users[4]
+	if alice.Name != "alice" || alice.UUID == "u1" || alice.Flow != "xtls-rprx-vision" {
+		t.Errorf("新凭据 = %+v", alice)
+	}
+	if old.Name != "alice~old" || old.UUID != "u1" || old.Flow != "xtls-rprx-vision" {
+		t.Errorf("旧凭据 = %+v", old)
+	}
+	if inbound.Users[1].UUID != "u2" {
+		t.Errorf("不在范围内的用户被修改: %+v", inbound.Users[1])
+	}
+}
+
+func TestRemoveOldCredentials(t *testing.T) {
+	client := &inboundUsersAgentClient{current: &pb.InboundUsersResponse{
+		Success:     true,
+		InboundType: "vless",
+		Users: []*pb.InboundUser{
+			{Name: "alice", Uuid: "u2"},
+			{Name: "alice~old", Uuid: "u1"},
+			{Name: "bob~old", Uuid: "u3"},
+		},
+	}}
+	s := NewCredentialRotationService(nil, client).(*credentialRotationService)
+	run := &models.CredentialRotationRun{AgentID: "a", Instance: "default", Users: []string{"vless-in/alice", "vless-in/dave"}}
+
+	// 只删除本次轮换用户仍在入站中的旧凭据
+	resp, err := s.removeOldCredentials(run, "vless-in")
+	if err != nil || resp == nil || resp.Message != "ok" {
+		t.Fatalf("removeOldCredentials() = %v, %v", resp, err)
+	}
+	if want := []string{"vless-in/remove/alice~old"}; !reflect.DeepEqual(client.updates, want) {
+		t.Fatalf("updates = %v, want %v", client.updates, want)
+	}
+
+	// 旧凭据已不存在时不下发
+	client.current.Users = client.current.Users[:1]
+	client.updates = nil
+	if resp, err := s.removeOldCredentials(run, "vless-in"); resp != nil || err != nil || len(client.updates) > 0 {
+		t.Errorf("removeOldCredentials() = %v, %v, updates = %v", resp, err, client.updates)
+	}
+}
+
+func TestReplaceRotatedUsers(t *testing.T) {
+	client := &inboundUsersAgentClient{
+		current: &pb.InboundUsersResponse{
+			InboundType: "vless",
+			Revision:    "r1",
+			Users:       []*pb.InboundUser{{Name: "alice", Uuid: "u1", Extra: `{"x_limit":10}`}},
+		},
+		concurrent: []*pb.InboundUser{{Name: "carol", Uuid: "u3"}},
+	}
+	s := NewCredentialRotationService(nil, client).(*credentialRotationService)
+	rotation := &models.CredentialRotation{Name: "r"}
+
+	// 第一次替换前有用户被并发追加，Agent拒绝后重新读取，追加的用户不会丢失
+	rotated, resp, err := s.replaceRotatedUsers(rotation, "a", "default", &singbox.Inbound{Type: "vless", Tag: "vless-in"})
+	if err != nil || resp == nil {
+		t.Fatalf("replaceRotatedUsers() error = %v", err)
+	}
+	if want := []string{"vless-in/alice", "vless-in/carol"}; !reflect.DeepEqual(rotated, want) {
+		t.Errorf("rotated = %v, want %v", rotated, want)
+	}
+	if want := []string{"r1", "r1+"}; !reflect.DeepEqual(client.revisions, want) {
+		t.Errorf("revisions = %v, want %v", client.revisions, want)
+	}
+
+	users := make(map[string]*pb.InboundUser)
+	for _, user := range client.current.Users {
+		users[user.Name] = user
+	}
+	for _, name := range []string{"alice", "alice~old", "carol", "carol~old"} {
+		if users[name] == nil {
+			t.Errorf("替换后缺少用户 %s: %v", name, client.current.Users)
+		}
+	}
+	if users["alice"] != nil && (users["alice"].Extra != `{"x_limit":10}` || users["alice"].Uuid == "u1") {
+		t.Errorf("alice = %+v, want 新uuid且保留extra", users["alice"])
+	}
+	if users["alice~old"] != nil && (users["alice~old"].Extra != `{"x_limit":10}` || users["alice~old"].Uuid != "u1") {
+		t.Errorf("alice~old = %+v, want 原uuid且保留extra", users["alice~old"])
+	}
+
+	// 持续被修改时放弃，返回修订不一致
+	client.current.Users = []*pb.InboundUser{{Name: "dave", Uuid: "u4"}}
+	client.revisions = nil
+	client.concurrent = []*pb.InboundUser{{Name: "e1", Uuid: "u5"}, {Name: "e2", Uuid: "u6"}, {Name: "e3", Uuid: "u7"}}
+	rotated, _, err = s.replaceRotatedUsers(rotation, "a", "default", &singbox.Inbound{Type: "vless", Tag: "vless-in"})
+	if !errors.Is(err, ErrInboundUsersChanged) || rotated == nil {
+		t.Errorf("replaceRotatedUsers() = %v, %v, want ErrInboundUsersChanged", rotated, err)
+	}
+	if len(client.revisions) != credentialReplaceAttempts {
+		t.Errorf("尝试次数 = %d, want %d", len(client.revisions), credentialReplaceAttempts)
+	}
+}
+
+func TestInboundUsersPB(t *testing.T) {
+	users := []*pb.InboundUser{
+		{Name: "alice", Uuid: "u1", Flow: "xtls-rprx-vision", Extra: `{"x_b":[1],"x_a":"v"}`},
+		{Username: "bob", Password: "p"},
+	}
+	converted, err := inboundUsersFromPB(users)
+	if err != nil {
+		t.Fatal(err)
+	}
+	back, err := inboundUsersToPB(converted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(back, users) {
+		t.Errorf("往返结果 = %v, want %v", back, users)
+	}
+
+	if _, err := inboundUsersFromPB([]*pb.InboundUser{{Name: "x", Extra: "[]"}}); err == nil {
+		t.Error("extra不是JSON对象时应返回错误")
+	}
+}
+
+func TestCredentialRotationResultSucceeded(t *testing.T) {
+	overlap := models.CredentialRotationRun{AgentID: "a", Instance: "default", Status: CredentialRunOverlap}
+	failed := models.CredentialRotationRun{AgentID: "b", Instance: "default", Status: CredentialRunFailed, ErrorMessage: "超时"}
+
+	tests := []struct {
+		name        string
+		result      CredentialRotationResult
+		want        bool
+		wantSummary string
+	}{
+		{"均无需轮换", CredentialRotationResult{UnmatchedAgents: []string{"a"}}, true, ""},
+		{"部分Agent成功", CredentialRotationResult{Runs: []models.CredentialRotationRun{overlap, failed}, FailedAgents: map[string]string{"c": "离线"}}, true, "b/default: 超时; c: 离线"},
+		{"下发全部失败", CredentialRotationResult{Runs: []models.CredentialRotationRun{failed}}, false, "b/default: 超时"},
+		{"无法获取入站", CredentialRotationResult{FailedAgents: map[string]string{"c": "离线"}, UnmatchedAgents: []string{"a"}}, false, "c: 离线"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.result.succeeded(); got != tt.want {
+				t.Errorf("succeeded() = %v, want %v", got, tt.want)
+			}
+			if got := tt.result.failureSummary(); got != tt.wantSummary {
+				t.Errorf("failureSummary() = %q, want %q", got, tt.wantSummary)
+			}
+		})
+	}
+}
+
+func TestCredentialRunTags(t *testing.T) {
+	got := credentialRunTags([]string{"a/alice", "b/bob", "a/carol", "invalid"})
+	if want := []string{"a", "b"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("credentialRunTags() = %v, want %v", got, want)
+	}
+}
+
+func TestAddUsersResponse(t *testing.T) {
+	content := models.JSON{}
+	addUsersResponse(content, nil)
+	if len(content) != 0 {
+		t.Fatalf("resp为nil时不应写入: %v", content)
+	}
+	addUsersResponse(content, &pb.InboundUsersResponse{Message: "已替换 2 个用户", Users: []*pb.InboundUser{{}, {}}})
+	if !strings.Contains(content["message"].(string), "已替换") || content["user_count"] != 2 {
+		t.Errorf("content = %v", content)
+	}
+	if _, ok := content["phases"]; ok {
+		t.Errorf("没有阶段时不应写入phases: %v", content)
+	}
+}
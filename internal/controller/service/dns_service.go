@@ -0,0 +1,145 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/xbox/sing-box-manager/internal/controller/repository"
+	pb "github.com/xbox/sing-box-manager/proto/agent"
+)
+
+// DNSService DNS配置管理服务接口
+type DNSService interface {
+	// 获取当前生效的DNS配置和版本，instance为空时为默认实例
+	GetConfig(agentID, instance string) (*pb.DNSConfigResponse, error)
+	// 增删、修改、移动或替换DNS服务器，operation为空时只设置final和strategy
+	UpdateServers(agentID, instance, operation string, servers json.RawMessage, tags []string, position int, final, strategy string) (*pb.DNSConfigResponse, error)
+	// 增删、移动或替换DNS规则
+	UpdateRules(agentID, instance, operation string, rules json.RawMessage, positions []int, position int) (*pb.DNSConfigResponse, error)
+	// 启用或关闭FakeIP
+	UpdateFakeIP(agentID, instance string, enabled bool, inet4Range, inet6Range string) (*pb.DNSConfigResponse, error)
+}
+
+// dnsService DNS配置管理服务实现
+type dnsService struct {
+	agentRepo   repository.AgentRepository
+	agentClient AgentClient
+}
+
+// NewDNSService 创建DNS配置管理服务
+func NewDNSService(agentRepo repository.AgentRepository, agentClient AgentClient) DNSService {
+	return &dnsService{
+		agentRepo:   agentRepo,
+		agentClient: agentClient,
+	}
+}
+
+// GetConfig 获取当前生效的DNS配置
+func (s *dnsService) GetConfig(agentID, instance string) (*pb.DNSConfigResponse, error) {
+	if _, err := s.agentRepo.GetByID(agentID); err != nil {
+		return nil, fmt.Errorf("Agent %s 不存在: %w", agentID, err)
+	}
+	return s.agentClient.GetDNSConfig(agentID, instance)
+}
+
+// UpdateServers 更新DNS服务器
+func (s *dnsService) UpdateServers(agentID, instance, operation string, servers json.RawMessage, tags []string, position int, final, strategy string) (*pb.DNSConfigResponse, error) {
+	if _, err := s.agentRepo.GetByID(agentID); err != nil {
+		return nil, fmt.Errorf("Agent %s 不存在: %w", agentID, err)
+	}
+
+	switch operation {
+	case "add", "update", "replace":
+		if err := checkJSONArray(servers, operation != "replace"); err != nil {
+			return nil, fmt.Errorf("DNS服务器列表无效: %w", err)
+		}
+	case "remove", "move":
+		if len(tags) == 0 {
+			return nil, fmt.Errorf("服务器tag列表不能为空")
+		}
+	case "":
+		if final == "" && strategy == "" {
+			return nil, fmt.Errorf("未指定DNS服务器操作")
+		}
+	default:
+		return nil, fmt.Errorf("不支持的DNS服务器操作: %s", operation)
+	}
+	if position < 0 {
+		return nil, fmt.Errorf("无效的插入位置: %d", position)
+	}
+
+	return s.agentClient.UpdateDNSServers(&pb.DNSServersRequest{
+		AgentId:   agentID,
+		Instance:  instance,
+		Operation: operation,
+		Servers:   string(servers),
+		Tags:      tags,
+		Position:  int32(position),
+		Final:     final,
+		Strategy:  strategy,
+	})
+}
+
+// UpdateRules 更新DNS规则
+func (s *dnsService) UpdateRules(agentID, instance, operation string, rules json.RawMessage, positions []int, position int) (*pb.DNSConfigResponse, error) {
+	if _, err := s.agentRepo.GetByID(agentID); err != nil {
+		return nil, fmt.Errorf("Agent %s 不存在: %w", agentID, err)
+	}
+
+	switch operation {
+	case "add", "replace":
+		if err := checkJSONArray(rules, operation == "add"); err != nil {
+			return nil, fmt.Errorf("DNS规则列表无效: %w", err)
+		}
+	case "remove", "move":
+		if len(positions) == 0 {
+			return nil, fmt.Errorf("规则位置列表不能为空")
+		}
+	default:
+		return nil, fmt.Errorf("不支持的DNS规则操作: %s", operation)
+	}
+	if position < 0 {
+		return nil, fmt.Errorf("无效的目标位置: %d", position)
+	}
+
+	pbPositions := make([]int32, 0, len(positions))
+	for _, p := range positions {
+		pbPositions = append(pbPositions, int32(p))
+	}
+
+	return s.agentClient.UpdateDNSRules(&pb.DNSRulesRequest{
+		AgentId:   agentID,
+		Instance:  instance,
+		Operation: operation,
+		Rules:     string(rules),
+		Positions: pbPositions,
+		Position:  int32(position),
+	})
+}
+
+// UpdateFakeIP 启用或关闭FakeIP
+func (s *dnsService) UpdateFakeIP(agentID, instance string, enabled bool, inet4Range, inet6Range string) (*pb.DNSConfigResponse, error) {
+	if _, err := s.agentRepo.GetByID(agentID); err != nil {
+		return nil, fmt.Errorf("Agent %s 不存在: %w", agentID, err)
+	}
+
+	return s.agentClient.UpdateFakeIP(&pb.FakeIPRequest{
+		AgentId:    agentID,
+		Instance:   instance,
+		Enabled:    enabled,
+		Inet4Range: inet4Range,
+		Inet6Range: inet6Range,
+	})
+}
+
+// checkJSONArray 检查数据是否为JSON数组，nonEmpty时不允许为空数组
+func checkJSONArray(data json.RawMessage, nonEmpty bool) error {
+	var items []json.RawMessage
+	if err := json.Unmarshal(data, &items); err != nil {
+		return fmt.Errorf("需要JSON数组: %w", err)
+	}
+	if nonEmpty && len(items) == 0 {
+		return fmt.Errorf("列表不能为空")
+	}
+	return nil
+}
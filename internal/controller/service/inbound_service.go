@@ -0,0 +1,65 @@
+package service
+
+import (
+	"fmt"
+
+	"github.com/xbox/sing-box-manager/internal/controller/repository"
+	pb "github.com/xbox/sing-box-manager/proto/agent"
+)
+
+// InboundService 入站用户管理服务接口
+type InboundService interface {
+	// 获取入站用户列表，instance为空时为默认实例
+	GetUsers(agentID, instance, inboundTag string) (*pb.InboundUsersResponse, error)
+	// 增删或替换入站用户，operation为add、remove或replace；revision非空时需与入站用户的当前修订一致，
+	// 否则返回ErrInboundUsersChanged
+	UpdateUsers(agentID, instance, inboundTag, operation, revision string, users []*pb.InboundUser) (*pb.InboundUsersResponse, error)
+}
+
+// inboundService 入站用户管理服务实现
+type inboundService struct {
+	agentRepo   repository.AgentRepository
+	agentClient AgentClient
+}
+
+// NewInboundService 创建入站用户管理服务
+func NewInboundService(agentRepo repository.AgentRepository, agentClient AgentClient) InboundService {
+	return &inboundService{
+		agentRepo:   agentRepo,
+		agentClient: agentClient,
+	}
+}
+
+// GetUsers 获取入站用户列表
+func (s *inboundService) GetUsers(agentID, instance, inboundTag string) (*pb.InboundUsersResponse, error) {
+	if _, err := s.agentRepo.GetByID(agentID); err != nil {
+		return nil, fmt.Errorf("Agent %s 不存在: %w", agentID, err)
+	}
+	return s.agentClient.GetInboundUsers(agentID, instance, inboundTag)
+}
+
+// UpdateUsers 增删或替换入站用户
+func (s *inboundService) UpdateUsers(agentID, instance, inboundTag, operation, revision string, users []*pb.InboundUser) (*pb.InboundUsersResponse, error) {
+	if _, err := s.agentRepo.GetByID(agentID); err != nil {
+		return nil, fmt.Errorf("Agent %s 不存在: %w", agentID, err)
+	}
+
+	switch operation {
+	case "add", "remove":
+		if len(users) == 0 {
+			return nil, fmt.Errorf("用户列表不能为空")
+		}
+	case "replace":
+	default:
+		return nil, fmt.Errorf("不支持的用户操作: %s", operation)
+	}
+
+	return s.agentClient.UpdateInboundUsers(&pb.InboundUsersRequest{
+		AgentId:    agentID,
+		Instance:   instance,
+		InboundTag: inboundTag,
+		Operation:  operation,
+		Users:      users,
+		Revision:   revision,
+	})
+}
@@ -0,0 +1,55 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/xbox/sing-box-manager/internal/controller/repository"
+	pb "github.com/xbox/sing-box-manager/proto/agent"
+)
+
+// LogStreamOptions sing-box日志查询参数
+type LogStreamOptions struct {
+	Instance string // sing-box实例名称，为空时为默认实例
+	Level    string // 最低日志级别
+	Keyword  string // 关键字过滤
+	Tail     int    // 先返回最近的行数
+	Follow   bool   // 是否持续推送新日志
+}
+
+// LogService sing-box日志服务接口
+type LogService interface {
+	// 拉取Agent的sing-box日志，每条日志调用一次handler
+	StreamSingboxLogs(ctx context.Context, agentID string, opts LogStreamOptions, handler func(*pb.SingboxLogEntry) error) error
+}
+
+// logService sing-box日志服务实现
+type logService struct {
+	agentRepo   repository.AgentRepository
+	agentClient AgentClient
+}
+
+// NewLogService 创建日志服务
+func NewLogService(agentRepo repository.AgentRepository, agentClient AgentClient) LogService {
+	return &logService{
+		agentRepo:   agentRepo,
+		agentClient: agentClient,
+	}
+}
+
+// StreamSingboxLogs 拉取Agent的sing-box日志
+func (s *logService) StreamSingboxLogs(ctx context.Context, agentID string, opts LogStreamOptions, handler func(*pb.SingboxLogEntry) error) error {
+	if _, err := s.agentRepo.GetByID(agentID); err != nil {
+		return fmt.Errorf("Agent %s 不存在: %w", agentID, err)
+	}
+
+	req := &pb.LogStreamRequest{
+		AgentId:  agentID,
+		Level:    opts.Level,
+		Keyword:  opts.Keyword,
+		Tail:     int32(opts.Tail),
+		Follow:   opts.Follow,
+		Instance: opts.Instance,
+	}
+	return s.agentClient.StreamSingboxLogs(ctx, req, handler)
+}
@@ -0,0 +1,105 @@
+package service
+
+import (
+	"fmt"
+
+	"github.com/xbox/sing-box-manager/internal/controller/repository"
+	pb "github.com/xbox/sing-box-manager/proto/agent"
+)
+
+// OutboundGroupService 出站组（selector/urltest）管理服务接口
+type OutboundGroupService interface {
+	// 获取出站组及当前选择与成员延迟，tag为空时返回全部，testDelay时先测试一次延迟
+	GetGroups(agentID, instance, tag string, testDelay bool, testURL string, timeoutMs int) (*pb.OutboundGroupsResponse, error)
+	// 创建、修改或删除出站组，operation为create、update或delete
+	UpdateGroup(agentID, instance, operation string, group *pb.OutboundGroup) (*pb.OutboundGroupResponse, error)
+	// 增删或替换出站组成员，operation为add、remove或replace
+	UpdateMembers(agentID, instance, tag, operation string, members []string) (*pb.OutboundGroupResponse, error)
+}
+
+// outboundGroupService 出站组管理服务实现
+type outboundGroupService struct {
+	agentRepo   repository.AgentRepository
+	agentClient AgentClient
+}
+
+// NewOutboundGroupService 创建出站组管理服务
+func NewOutboundGroupService(agentRepo repository.AgentRepository, agentClient AgentClient) OutboundGroupService {
+	return &outboundGroupService{
+		agentRepo:   agentRepo,
+		agentClient: agentClient,
+	}
+}
+
+// GetGroups 获取出站组状态
+func (s *outboundGroupService) GetGroups(agentID, instance, tag string, testDelay bool, testURL string, timeoutMs int) (*pb.OutboundGroupsResponse, error) {
+	if _, err := s.agentRepo.GetByID(agentID); err != nil {
+		return nil, fmt.Errorf("Agent %s 不存在: %w", agentID, err)
+	}
+	if timeoutMs < 0 || timeoutMs > 60000 {
+		return nil, fmt.Errorf("延迟测试超时需在0到60000毫秒之间")
+	}
+
+	return s.agentClient.GetOutboundGroups(&pb.OutboundGroupsQuery{
+		AgentId:   agentID,
+		Instance:  instance,
+		Tag:       tag,
+		TestDelay: testDelay,
+		TestUrl:   testURL,
+		TimeoutMs: int32(timeoutMs),
+	})
+}
+
+// UpdateGroup 创建、修改或删除出站组
+func (s *outboundGroupService) UpdateGroup(agentID, instance, operation string, group *pb.OutboundGroup) (*pb.OutboundGroupResponse, error) {
+	if _, err := s.agentRepo.GetByID(agentID); err != nil {
+		return nil, fmt.Errorf("Agent %s 不存在: %w", agentID, err)
+	}
+	if group == nil || group.Tag == "" {
+		return nil, fmt.Errorf("出站组tag不能为空")
+	}
+
+	switch operation {
+	case "create", "update":
+		if group.Type != "selector" && group.Type != "urltest" {
+			return nil, fmt.Errorf("出站组类型必须为selector或urltest")
+		}
+		if len(group.Outbounds) == 0 {
+			return nil, fmt.Errorf("出站组成员不能为空")
+		}
+	case "delete":
+	default:
+		return nil, fmt.Errorf("不支持的出站组操作: %s", operation)
+	}
+
+	return s.agentClient.UpdateOutboundGroup(&pb.OutboundGroupRequest{
+		AgentId:   agentID,
+		Instance:  instance,
+		Operation: operation,
+		Group:     group,
+	})
+}
+
+// UpdateMembers 增删或替换出站组成员
+func (s *outboundGroupService) UpdateMembers(agentID, instance, tag, operation string, members []string) (*pb.OutboundGroupResponse, error) {
+	if _, err := s.agentRepo.GetByID(agentID); err != nil {
+		return nil, fmt.Errorf("Agent %s 不存在: %w", agentID, err)
+	}
+
+	switch operation {
+	case "add", "remove", "replace":
+		if len(members) == 0 {
+			return nil, fmt.Errorf("成员列表不能为空")
+		}
+	default:
+		return nil, fmt.Errorf("不支持的成员操作: %s", operation)
+	}
+
+	return s.agentClient.UpdateGroupMembers(&pb.GroupMembersRequest{
+		AgentId:   agentID,
+		Instance:  instance,
+		Tag:       tag,
+		Operation: operation,
+		Members:   members,
+	})
+}
@@ -0,0 +1,174 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/xbox/sing-box-manager/internal/models"
+	pb "github.com/xbox/sing-box-manager/proto/agent"
+	"gorm.io/gorm"
+)
+
+// ProbeQuery 出站探测查询条件，空值表示不过滤
+type ProbeQuery struct {
+	AgentID  string
+	Instance string
+	Outbound string
+	From     time.Time
+	To       time.Time
+}
+
+// ProbeSeries 一个出站对一个探测目标在查询区间内的探测记录，按时间顺序排列
+type ProbeSeries struct {
+	Instance     string                 `json:"instance"`
+	Outbound     string                 `json:"outbound"`
+	TargetType   string                 `json:"target_type"`
+	Target       string                 `json:"target"`
+	Probes       int                    `json:"probes"`
+	Successes    int                    `json:"successes"`
+	SuccessRate  float64                `json:"success_rate"`   // 0-1
+	AvgLatencyMs float64                `json:"avg_latency_ms"` // 成功探测的平均延迟
+	LastError    string                 `json:"last_error,omitempty"`
+	Points       []models.OutboundProbe `json:"points"`
+}
+
+// ProbeService 出站探测结果服务接口
+type ProbeService interface {
+	// 保存Agent随心跳上报的出站探测结果
+	RecordProbes(agentID string, results []*pb.OutboundProbeResult) error
+	// 按实例、出站和目标查询探测时间序列
+	GetSeries(query ProbeQuery) ([]ProbeSeries, error)
+}
+
+// probeService 出站探测结果服务实现
+type probeService struct {
+	db *gorm.DB
+}
+
+// NewProbeService 创建出站探测结果服务
+func NewProbeService(db *gorm.DB) ProbeService {
+	return &probeService{db: db}
+}
+
+// RecordProbes 保存Agent上报的出站探测结果
+func (s *probeService) RecordProbes(agentID string, results []*pb.OutboundProbeResult) error {
+	if agentID == "" {
+		return fmt.Errorf("Agent ID不能为空")
+	}
+	if len(results) == 0 {
+		return nil
+	}
+
+	rows := make([]models.OutboundProbe, 0, len(results))
+	for _, result := range results {
+		if result.Outbound == "" || result.Probes <= 0 {
+			continue
+		}
+		if result.Successes < 0 || result.Successes > result.Probes {
+			return fmt.Errorf("出站 %s 的探测成功次数无效: %d/%d", result.Outbound, result.Successes, result.Probes)
+		}
+		instance := result.Instance
+		if instance == "" {
+			instance = "default"
+		}
+		rows = append(rows, models.OutboundProbe{
+			AgentID:       agentID,
+			Instance:      instance,
+			Outbound:      result.Outbound,
+			TargetType:    result.TargetType,
+			Target:        result.Target,
+			Probes:        int(result.Probes),
+			Successes:     int(result.Successes),
+			AvgLatencyMs:  result.AvgLatencyMs,
+			MaxLatencyMs:  result.MaxLatencyMs,
+			LastLatencyMs: result.LastLatencyMs,
+			LastError:     result.LastError,
+			PeriodStart:   time.Unix(result.PeriodStart, 0),
+			PeriodEnd:     time.Unix(result.PeriodEnd, 0),
+		})
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+
+	if err := s.db.Create(&rows).Error; err != nil {
+		return fmt.Errorf("保存出站探测结果失败: %w", err)
+	}
+	return nil
+}
+
+// GetSeries 查询探测时间序列，按实例、出站和目标排序
+func (s *probeService) GetSeries(query ProbeQuery) ([]ProbeSeries, error) {
+	db := s.db.Model(&models.OutboundProbe{})
+	if query.AgentID != "" {
+		db = db.Where("agent_id = ?", query.AgentID)
+	}
+	if query.Instance != "" {
+		db = db.Where("instance = ?", query.Instance)
+	}
+	if query.Outbound != "" {
+		db = db.Where("outbound = ?", query.Outbound)
+	}
+	if !query.From.IsZero() {
+		db = db.Where("period_end >= ?", query.From)
+	}
+	if !query.To.IsZero() {
+		db = db.Where("period_end < ?", query.To)
+	}
+
+	var rows []models.OutboundProbe
+	if err := db.Order("period_end, id").Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("查询出站探测结果失败: %w", err)
+	}
+
+	index := make(map[[4]string]int)
+	var series []ProbeSeries
+	var latencySum []float64 // 各序列成功探测的延迟合计
+	for _, row := range rows {
+		key := [4]string{row.Instance, row.Outbound, row.TargetType, row.Target}
+		i, ok := index[key]
+		if !ok {
+			i = len(series)
+			index[key] = i
+			series = append(series, ProbeSeries{
+				Instance:   row.Instance,
+				Outbound:   row.Outbound,
+				TargetType: row.TargetType,
+				Target:     row.Target,
+			})
+			latencySum = append(latencySum, 0)
+		}
+		item := &series[i]
+		item.Probes += row.Probes
+		item.Successes += row.Successes
+		latencySum[i] += row.AvgLatencyMs * float64(row.Successes)
+		if row.LastError != "" {
+			item.LastError = row.LastError
+		}
+		item.Points = append(item.Points, row)
+	}
+
+	for i := range series {
+		if series[i].Probes > 0 {
+			series[i].SuccessRate = float64(series[i].Successes) / float64(series[i].Probes)
+		}
+		if series[i].Successes > 0 {
+			series[i].AvgLatencyMs = latencySum[i] / float64(series[i].Successes)
+		}
+	}
+	sort.SliceStable(series, func(i, j int) bool {
+		a, b := series[i], series[j]
+		if a.Instance != b.Instance {
+			return a.Instance < b.Instance
+		}
+		if a.Outbound != b.Outbound {
+			return a.Outbound < b.Outbound
+		}
+		if a.TargetType != b.TargetType {
+			return a.TargetType < b.TargetType
+		}
+		return a.Target < b.Target
+	})
+	return series, nil
+}
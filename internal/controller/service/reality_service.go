@@ -0,0 +1,591 @@
+package service
+
+import (
+	"context"
+	"crypto/ecdh"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/xbox/sing-box-manager/internal/agent/singbox"
+	"github.com/xbox/sing-box-manager/internal/controller/repository"
+	"github.com/xbox/sing-box-manager/internal/models"
+	pb "github.com/xbox/sing-box-manager/proto/agent"
+	"gorm.io/gorm"
+)
+
+// Reality密钥状态
+const (
+	RealityKeyPending = "pending" // 新密钥已保存，正在下发到Agent
+	RealityKeyApplied = "applied" // 当前密钥已下发到Agent
+	RealityKeyFailed  = "failed"  // 最近一次下发失败，Agent仍使用当前密钥
+)
+
+const (
+	// maxRealityShortIDs 每个入站最多生成的short_id数量
+	maxRealityShortIDs = 8
+	// realityRetryDelay 定期轮换失败后的重试间隔
+	realityRetryDelay = time.Hour
+	// realityPendingTimeout 下发中的记录超过该时间未更新时视为已中断，允许重新下发
+	realityPendingTimeout = 10 * time.Minute
+)
+
+// RealityKeyRequest 托管Reality密钥请求
+type RealityKeyRequest struct {
+	AgentID         string `json:"agent_id" binding:"required"`
+	Instance        string `json:"instance"` // sing-box实例名称，默认default
+	InboundTag      string `json:"inbound_tag" binding:"required"`
+	ShortIDCount    int    `json:"short_id_count"`   // 生成的short_id数量，默认1，最多8
+	RotationDays    int    `json:"rotation_days"`    // 自动轮换周期（天），0为不自动轮换
+	HandshakeServer string `json:"handshake_server"` // 入站尚未配置Reality时必填
+	HandshakePort   uint16 `json:"handshake_port"`   // 默认443
+}
+
+// RealityKeyInfo Reality密钥及对应的客户端TLS配置
+type RealityKeyInfo struct {
+	models.RealityKey
+	Client *singbox.OutboundTLS `json:"client"` // 客户端TLS配置，订阅输出与之一致
+}
+
+// RealityService Reality密钥托管服务接口
+type RealityService interface {
+	// 生成密钥对和short_id，保存后下发到Agent入站，下发失败时保留记录，可重新下发或轮换
+	CreateKey(req *RealityKeyRequest) (*RealityKeyInfo, error)
+	// 获取托管的密钥，agentID为空时返回全部
+	ListKeys(agentID string) ([]models.RealityKey, error)
+	GetKey(id uint) (*RealityKeyInfo, error)
+	// 修改自动轮换周期，0为不自动轮换
+	UpdateSchedule(id uint, rotationDays int) (*models.RealityKey, error)
+	// 立即轮换，生成新的密钥对和short_id并下发，下发失败时保留当前密钥
+	RotateKey(id uint) (*RealityKeyInfo, error)
+	// 将当前密钥重新下发到Agent入站，用于配置被其他方式覆盖后恢复
+	ReapplyKey(id uint) (*RealityKeyInfo, error)
+	// 停止托管，Agent配置中的密钥保持不变
+	DeleteKey(id uint) error
+	// 将托管的当前密钥写入即将下发的完整配置，配置记录不含私钥，下发完整配置前需调用
+	InjectKeys(agentID, instance, content string) (string, error)
+	// 定期轮换到期的密钥并检查Agent入站的密钥是否被覆盖，ctx取消时返回
+	StartRotation(ctx context.Context, interval time.Duration)
+}
+
+// realityService Reality密钥托管服务实现
+type realityService struct {
+	db          *gorm.DB
+	agentRepo   repository.AgentRepository
+	agentClient AgentClient
+	sealer      secretSealer
+}
+
+// NewRealityService 创建Reality密钥托管服务，secretKey用于加密保存私钥
+func NewRealityService(db *gorm.DB, agentRepo repository.AgentRepository, agentClient AgentClient, secretKey string) RealityService {
+	return &realityService{
+		db:          db,
+		agentRepo:   agentRepo,
+		agentClient: agentClient,
+		sealer:      secretSealer{secretKey: secretKey, setting: "reality.secret_key", subject: "Reality私钥"},
+	}
+}
+
+// CreateKey 托管入站的Reality密钥
+func (s *realityService) CreateKey(req *RealityKeyRequest) (*RealityKeyInfo, error) {
+	if _, err := s.agentRepo.GetByID(req.AgentID); err != nil {
+		return nil, fmt.Errorf("Agent %s 不存在: %w", req.AgentID, err)
+	}
+	if req.InboundTag == "" {
+		return nil, fmt.Errorf("入站tag不能为空")
+	}
+	if req.RotationDays < 0 {
+		return nil, fmt.Errorf("轮换周期不能为负数")
+	}
+	count := req.ShortIDCount
+	if count == 0 {
+		count = 1
+	}
+	if count < 0 || count > maxRealityShortIDs {
+		return nil, fmt.Errorf("short_id数量需在1到%d之间", maxRealityShortIDs)
+	}
+	instance := req.Instance
+	if instance == "" {
+		instance = "default"
+	}
+
+	var existing int64
+	if err := s.db.Model(&models.RealityKey{}).
+		Where("agent_id = ? AND instance = ? AND inbound_tag = ?", req.AgentID, instance, req.InboundTag).
+		Count(&existing).Error; err != nil {
+		return nil, fmt.Errorf("查询Reality密钥失败: %w", err)
+	}
+	if existing > 0 {
+		return nil, fmt.Errorf("入站 %s/%s/%s 的Reality密钥已托管，请使用轮换", req.AgentID, instance, req.InboundTag)
+	}
+
+	key := &models.RealityKey{
+		AgentID:      req.AgentID,
+		Instance:     instance,
+		InboundTag:   req.InboundTag,
+		RotationDays: req.RotationDays,
+	}
+	var handshake *singbox.RealityHandshake
+	if req.HandshakeServer != "" {
+		handshake = &singbox.RealityHandshake{Server: req.HandshakeServer, ServerPort: req.HandshakePort}
+		if handshake.ServerPort == 0 {
+			handshake.ServerPort = 443
+		}
+	}
+
+	privateKey, shortIDs, err := newRealityKeys(count)
+	if err != nil {
+		return nil, err
+	}
+	encrypted, err := s.encrypt(key, privateKey)
+	if err != nil {
+		return nil, err
+	}
+	key.Version = 1
+	key.Status = RealityKeyPending
+	key.PendingKeyEnc = encrypted
+	key.PendingShortIDs = shortIDs
+
+	// 先提交待下发的记录，保存失败时不下发；下发失败时记录保留，Agent可能已收到新密钥
+	if err := s.db.Create(key).Error; err != nil {
+		return nil, fmt.Errorf("保存Reality密钥失败: %w", err)
+	}
+	if err := s.deploy(key, privateKey, handshake); err != nil {
+		return nil, err
+	}
+	log.Printf("已托管Reality密钥: %s/%s/%s", key.AgentID, key.Instance, key.InboundTag)
+	return realityKeyInfo(key), nil
+}
+
+// ListKeys 获取托管的Reality密钥
+func (s *realityService) ListKeys(agentID string) ([]models.RealityKey, error) {
+	db := s.db.Order("agent_id, instance, inbound_tag")
+	if agentID != "" {
+		db = db.Where("agent_id = ?", agentID)
+	}
+	var keys []models.RealityKey
+	if err := db.Find(&keys).Error; err != nil {
+		return nil, fmt.Errorf("查询Reality密钥失败: %w", err)
+	}
+	return keys, nil
+}
+
+// GetKey 获取Reality密钥
+func (s *realityService) GetKey(id uint) (*RealityKeyInfo, error) {
+	key, err := s.getKey(id)
+	if err != nil {
+		return nil, err
+	}
+	return realityKeyInfo(key), nil
+}
+
+// UpdateSchedule 修改自动轮换周期，下次轮换时间从上次轮换起计算
+func (s *realityService) UpdateSchedule(id uint, rotationDays int) (*models.RealityKey, error) {
+	if rotationDays < 0 {
+		return nil, fmt.Errorf("轮换周期不能为负数")
+	}
+	key, err := s.getKey(id)
+	if err != nil {
+		return nil, err
+	}
+
+	key.RotationDays = rotationDays
+	key.NextRotationAt = nextRealityRotation(key.RotatedAt, rotationDays)
+	if err := s.db.Save(key).Error; err != nil {
+		return nil, fmt.Errorf("保存Reality密钥失败: %w", err)
+	}
+	return key, nil
+}
+
+// RotateKey 轮换Reality密钥
+func (s *realityService) RotateKey(id uint) (*RealityKeyInfo, error) {
+	key, err := s.getKey(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.rotate(key); err != nil {
+		return nil, err
+	}
+	return realityKeyInfo(key), nil
+}
+
+// ReapplyKey 重新下发当前密钥，托管时下发失败的记录下发其新密钥
+func (s *realityService) ReapplyKey(id uint) (*RealityKeyInfo, error) {
+	key, err := s.getKey(id)
+	if err != nil {
+		return nil, err
+	}
+	encrypted, shortIDs := key.PrivateKeyEnc, key.ShortIDs
+	if key.PublicKey == "" {
+		encrypted, shortIDs = key.PendingKeyEnc, key.PendingShortIDs
+	}
+	privateKey, err := s.decrypt(key, encrypted)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.stage(key, encrypted, shortIDs); err != nil {
+		return nil, err
+	}
+	if err := s.deploy(key, privateKey, nil); err != nil {
+		return nil, err
+	}
+	return realityKeyInfo(key), nil
+}
+
+// DeleteKey 停止托管Reality密钥
+func (s *realityService) DeleteKey(id uint) error {
+	result := s.db.Delete(&models.RealityKey{}, id)
+	if result.Error != nil {
+		return fmt.Errorf("删除Reality密钥失败: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("Reality密钥 %d 不存在", id)
+	}
+	return nil
+}
+
+// InjectKeys 将托管的当前密钥写入完整配置中仍启用Reality的入站，避免下发后托管密钥被覆盖
+func (s *realityService) InjectKeys(agentID, instance, content string) (string, error) {
+	if instance == "" {
+		instance = "default"
+	}
+	var keys []models.RealityKey
+	if err := s.db.Where("agent_id = ? AND instance = ? AND public_key <> ?", agentID, instance, "").
+		Find(&keys).Error; err != nil {
+		return "", fmt.Errorf("查询Reality密钥失败: %w", err)
+	}
+	if len(keys) == 0 {
+		return content, nil
+	}
+
+	var config singbox.Config
+	if err := json.Unmarshal([]byte(content), &config); err != nil {
+		// 由配置校验报告错误
+		return content, nil
+	}
+	injected, err := s.injectKeys(&config, keys)
+	if err != nil || injected == 0 {
+		return content, err
+	}
+	data, err := json.Marshal(&config)
+	if err != nil {
+		return "", fmt.Errorf("序列化sing-box配置失败: %w", err)
+	}
+	return string(data), nil
+}
+
+// injectKeys 将托管密钥写入对应入站的tls.reality，入站不存在或未启用Reality时跳过，返回写入的入站数
+func (s *realityService) injectKeys(config *singbox.Config, keys []models.RealityKey) (int, error) {
+	injected := 0
+	for i := range keys {
+		key := &keys[i]
+		var inbound *singbox.Inbound
+		for j := range config.Inbounds {
+			if config.Inbounds[j].Tag == key.InboundTag {
+				inbound = &config.Inbounds[j]
+				break
+			}
+		}
+		if inbound == nil || inbound.TLS == nil || inbound.TLS.Reality == nil || !inbound.TLS.Reality.Enabled {
+			continue
+		}
+
+		privateKey, err := s.decrypt(key, key.PrivateKeyEnc)
+		if err != nil {
+			return 0, fmt.Errorf("无法写入入站 %s 托管的Reality密钥: %w", key.InboundTag, err)
+		}
+		inbound.TLS.Reality.PrivateKey = privateKey
+		inbound.TLS.Reality.ShortID = key.ShortIDs
+		injected++
+	}
+	return injected, nil
+}
+
+// StartRotation 定期轮换到期的密钥
+func (s *realityService) StartRotation(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = 5 * time.Minute
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.rotateDue()
+			s.checkDrift()
+		}
+	}
+}
+
+// rotateDue 轮换到期的密钥，失败时推迟重试
+func (s *realityService) rotateDue() {
+	var keys []models.RealityKey
+	if err := s.db.Where("rotation_days > 0 AND next_rotation_at <= ?", time.Now()).Find(&keys).Error; err != nil {
+		log.Printf("查询待轮换的Reality密钥失败: %v", err)
+		return
+	}
+	for i := range keys {
+		key := &keys[i]
+		if err := s.rotate(key); err != nil {
+			retryAt := time.Now().Add(realityRetryDelay)
+			if err := s.db.Model(key).UpdateColumn("next_rotation_at", retryAt).Error; err != nil {
+				log.Printf("更新Reality密钥 %d 的轮换时间失败: %v", key.ID, err)
+			}
+			log.Printf("定期轮换Reality密钥失败: %s/%s/%s, 将于 %s 重试: %v",
+				key.AgentID, key.Instance, key.InboundTag, retryAt.Format(time.RFC3339), err)
+			continue
+		}
+		log.Printf("已定期轮换Reality密钥: %s/%s/%s, 版本 %d", key.AgentID, key.Instance, key.InboundTag, key.Version)
+	}
+}
+
+// checkDrift 检查在线Agent入站当前的Reality公钥是否仍为托管的密钥；
+// 下发失败的新密钥若已在Agent上生效（如响应丢失），将其确认为当前密钥
+func (s *realityService) checkDrift() {
+	var keys []models.RealityKey
+	err := s.db.Where("status <> ? OR updated_at < ?", RealityKeyPending, time.Now().Add(-realityPendingTimeout)).
+		Find(&keys).Error
+	if err != nil {
+		log.Printf("查询Reality密钥失败: %v", err)
+		return
+	}
+	for i := range keys {
+		key := &keys[i]
+		agent, err := s.agentRepo.GetByID(key.AgentID)
+		if err != nil || agent.Status != "online" {
+			continue
+		}
+		resp, err := s.agentClient.GetReality(key.AgentID, key.Instance, key.InboundTag)
+		if err != nil {
+			log.Printf("获取入站 %s/%s/%s 的Reality配置失败: %v", key.AgentID, key.Instance, key.InboundTag, err)
+			continue
+		}
+		if !resp.Enabled {
+			log.Printf("Agent配置中的入站 %s/%s/%s 已不再使用Reality", key.AgentID, key.Instance, key.InboundTag)
+			continue
+		}
+		if key.PendingKeyEnc != "" && s.pendingPublicKey(key) == resp.PublicKey {
+			if err := s.confirm(key, resp.PublicKey, resp.ServerName); err != nil {
+				log.Printf("确认Reality密钥 %d 失败: %v", key.ID, err)
+			} else {
+				log.Printf("Agent已使用入站 %s/%s/%s 下发失败的新密钥，已确认为当前密钥", key.AgentID, key.Instance, key.InboundTag)
+			}
+			continue
+		}
+		if key.PublicKey != "" && resp.PublicKey != key.PublicKey {
+			log.Printf("Agent配置中的入站 %s/%s/%s 的Reality密钥与托管的不一致，可调用重新下发恢复", key.AgentID, key.Instance, key.InboundTag)
+		}
+	}
+}
+
+// rotate 生成新密钥，提交待下发记录后下发，下发失败时记录原因，Agent继续使用当前密钥
+func (s *realityService) rotate(key *models.RealityKey) error {
+	count := len(key.ShortIDs)
+	if count == 0 {
+		count = 1
+	}
+	privateKey, shortIDs, err := newRealityKeys(count)
+	if err != nil {
+		return err
+	}
+	encrypted, err := s.encrypt(key, privateKey)
+	if err != nil {
+		return err
+	}
+
+	if err := s.stage(key, encrypted, shortIDs); err != nil {
+		return err
+	}
+	return s.deploy(key, privateKey, nil)
+}
+
+// stage 提交待下发的密钥，同一入站已有下发进行中时返回错误
+func (s *realityService) stage(key *models.RealityKey, encrypted string, shortIDs []string) error {
+	staged := *key
+	staged.Status = RealityKeyPending
+	staged.ErrorMessage = ""
+	staged.PendingKeyEnc = encrypted
+	staged.PendingShortIDs = shortIDs
+
+	result := s.db.Model(&staged).
+		Where("(status <> ? OR updated_at < ?)", RealityKeyPending, time.Now().Add(-realityPendingTimeout)).
+		Select("status", "error_message", "pending_key_enc", "pending_short_ids").
+		Updates(&staged)
+	if result.Error != nil {
+		return fmt.Errorf("保存Reality密钥失败: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("入站 %s/%s/%s 的Reality密钥正在下发，请稍后重试", key.AgentID, key.Instance, key.InboundTag)
+	}
+	*key = staged
+	return nil
+}
+
+// deploy 在事务外将已提交的待下发密钥发送到Agent，再按结果更新记录
+func (s *realityService) deploy(key *models.RealityKey, privateKey string, handshake *singbox.RealityHandshake) error {
+	resp, err := s.push(key, privateKey, handshake)
+	if err != nil {
+		if err := s.db.Model(&models.RealityKey{}).
+			Where("id = ? AND pending_key_enc = ?", key.ID, key.PendingKeyEnc).
+			Updates(map[string]interface{}{
+				"status":        RealityKeyFailed,
+				"error_message": err.Error(),
+			}).Error; err != nil {
+			log.Printf("更新Reality密钥 %d 状态失败: %v", key.ID, err)
+		}
+		key.Status = RealityKeyFailed
+		key.ErrorMessage = err.Error()
+		return err
+	}
+
+	publicKey, err := realityPublicKey(privateKey)
+	if err != nil {
+		return err
+	}
+	return s.confirm(key, publicKey, resp.ServerName)
+}
+
+// confirm 将Agent已生效的待下发密钥保存为当前密钥，记录已被其他下发更新时返回错误
+func (s *realityService) confirm(key *models.RealityKey, publicKey, serverName string) error {
+	confirmed := *key
+	promoteRealityKey(&confirmed, publicKey, serverName, time.Now())
+
+	result := s.db.Model(&confirmed).
+		Where("pending_key_enc = ?", key.PendingKeyEnc).
+		Select("private_key_enc", "public_key", "short_ids", "server_name", "version", "status",
+			"error_message", "pending_key_enc", "pending_short_ids", "rotated_at", "next_rotation_at").
+		Updates(&confirmed)
+	if result.Error != nil {
+		return fmt.Errorf("保存Reality密钥失败: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("入站 %s/%s/%s 的Reality密钥已被其他下发更新，请查询最新状态", key.AgentID, key.Instance, key.InboundTag)
+	}
+	*key = confirmed
+	return nil
+}
+
+// promoteRealityKey 待下发的密钥成为当前密钥；重新下发当前密钥不算轮换，保留版本和轮换时间
+func promoteRealityKey(key *models.RealityKey, publicKey, serverName string, now time.Time) {
+	if key.PendingKeyEnc != key.PrivateKeyEnc {
+		if key.PublicKey != "" {
+			key.Version++
+		}
+		key.RotatedAt = now
+	}
+	key.PrivateKeyEnc = key.PendingKeyEnc
+	key.PublicKey = publicKey
+	key.ShortIDs = key.PendingShortIDs
+	key.ServerName = serverName
+	key.Status = RealityKeyApplied
+	key.ErrorMessage = ""
+	key.PendingKeyEnc = ""
+	key.PendingShortIDs = nil
+	key.NextRotationAt = nextRealityRotation(key.RotatedAt, key.RotationDays)
+}
+
+// pendingPublicKey 返回待下发密钥的公钥，无法解密时返回空串
+func (s *realityService) pendingPublicKey(key *models.RealityKey) string {
+	privateKey, err := s.decrypt(key, key.PendingKeyEnc)
+	if err != nil {
+		log.Printf("解密Reality密钥 %d 失败: %v", key.ID, err)
+		return ""
+	}
+	publicKey, err := realityPublicKey(privateKey)
+	if err != nil {
+		return ""
+	}
+	return publicKey
+}
+
+// push 只更新Agent入站的tls.reality，配置的其余部分保持不变，私钥不写入配置记录
+func (s *realityService) push(key *models.RealityKey, privateKey string, handshake *singbox.RealityHandshake) (*pb.RealityResponse, error) {
+	req := &pb.RealityRequest{
+		AgentId:    key.AgentID,
+		Instance:   key.Instance,
+		InboundTag: key.InboundTag,
+		PrivateKey: privateKey,
+		ShortIds:   key.PendingShortIDs,
+	}
+	if handshake != nil {
+		req.HandshakeServer = handshake.Server
+		req.HandshakePort = uint32(handshake.ServerPort)
+	}
+	resp, err := s.agentClient.UpdateReality(req)
+	if err != nil {
+		return nil, fmt.Errorf("下发Reality密钥失败: %w", err)
+	}
+	return resp, nil
+}
+
+// getKey 按ID获取Reality密钥
+func (s *realityService) getKey(id uint) (*models.RealityKey, error) {
+	var key models.RealityKey
+	if err := s.db.First(&key, id).Error; err != nil {
+		return nil, fmt.Errorf("Reality密钥 %d 不存在: %w", id, err)
+	}
+	return &key, nil
+}
+
+// encrypt 加密私钥，密文与入站绑定
+func (s *realityService) encrypt(key *models.RealityKey, plaintext string) (string, error) {
+	return s.sealer.seal(plaintext, realityKeyAD(key))
+}
+
+// decrypt 解密私钥
+func (s *realityService) decrypt(key *models.RealityKey, encrypted string) (string, error) {
+	return s.sealer.open(encrypted, realityKeyAD(key))
+}
+
+// realityKeyAD 加密附加数据，防止密文被挪用到其他入站
+func realityKeyAD(key *models.RealityKey) []byte {
+	return []byte(key.AgentID + "/" + key.Instance + "/" + key.InboundTag)
+}
+
+// newRealityKeys 生成X25519私钥（base64url）和count个8字节的short_id
+func newRealityKeys(count int) (string, []string, error) {
+	privateKey, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return "", nil, fmt.Errorf("生成Reality密钥对失败: %w", err)
+	}
+	shortIDs := make([]string, 0, count)
+	for i := 0; i < count; i++ {
+		buf := make([]byte, 8)
+		if _, err := rand.Read(buf); err != nil {
+			return "", nil, fmt.Errorf("生成short_id失败: %w", err)
+		}
+		shortIDs = append(shortIDs, hex.EncodeToString(buf))
+	}
+	return base64.RawURLEncoding.EncodeToString(privateKey.Bytes()), shortIDs, nil
+}
+
+// nextRealityRotation 计算下次轮换时间，周期为0时不自动轮换
+func nextRealityRotation(from time.Time, days int) *time.Time {
+	if days <= 0 {
+		return nil
+	}
+	next := from.AddDate(0, 0, days)
+	return &next
+}
+
+// realityKeyInfo 附带客户端TLS配置
+func realityKeyInfo(key *models.RealityKey) *RealityKeyInfo {
+	client := &singbox.OutboundTLS{
+		Enabled:    true,
+		ServerName: key.ServerName,
+		Reality:    &singbox.RealityConfig{Enabled: true, PublicKey: key.PublicKey},
+		UTLS:       &singbox.UTLSConfig{Enabled: true, Fingerprint: clientFingerprint},
+	}
+	if len(key.ShortIDs) > 0 {
+		client.Reality.ShortID = key.ShortIDs[0]
+	}
+	return &RealityKeyInfo{RealityKey: *key, Client: client}
+}
@@ -0,0 +1,136 @@
+package service
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/xbox/sing-box-manager/internal/agent/singbox"
+	"github.com/xbox/sing-box-manager/internal/models"
+)
+
+func TestPromoteRealityKey(t *testing.T) {
+	rotatedAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	now := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		key         models.RealityKey
+		wantVersion int
+		wantRotated time.Time
+	}{
+		{
+			name:        "托管时首次下发",
+			key:         models.RealityKey{Version: 1, RotationDays: 30, PendingKeyEnc: "new", PendingShortIDs: []string{"aa"}, Status: RealityKeyPending},
+			wantVersion: 1,
+			wantRotated: now,
+		},
+		{
+			name:        "轮换",
+			key:         models.RealityKey{Version: 3, RotationDays: 30, PrivateKeyEnc: "old", PublicKey: "old-pub", ShortIDs: []string{"bb"}, RotatedAt: rotatedAt, PendingKeyEnc: "new", PendingShortIDs: []string{"aa"}, Status: RealityKeyPending},
+			wantVersion: 4,
+			wantRotated: now,
+		},
+		{
+			name:        "重新下发当前密钥",
+			key:         models.RealityKey{Version: 3, RotationDays: 30, PrivateKeyEnc: "new", PublicKey: "pub", ShortIDs: []string{"aa"}, RotatedAt: rotatedAt, PendingKeyEnc: "new", PendingShortIDs: []string{"aa"}, Status: RealityKeyPending},
+			wantVersion: 3,
+			wantRotated: rotatedAt,
+		},
+		{
+			name:        "确认下发失败但已生效的新密钥",
+			key:         models.RealityKey{Version: 3, PrivateKeyEnc: "old", PublicKey: "old-pub", RotatedAt: rotatedAt, PendingKeyEnc: "new", PendingShortIDs: []string{"aa"}, Status: RealityKeyFailed, ErrorMessage: "超时"},
+			wantVersion: 4,
+			wantRotated: now,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key := tt.key
+			promoteRealityKey(&key, "pub", "www.microsoft.com", now)
+
+			if key.PrivateKeyEnc != "new" || key.PublicKey != "pub" || !reflect.DeepEqual(key.ShortIDs, []string{"aa"}) {
+				t.Errorf("当前密钥 = %s/%s/%v, want new/pub/[aa]", key.PrivateKeyEnc, key.PublicKey, key.ShortIDs)
+			}
+			if key.PendingKeyEnc != "" || key.PendingShortIDs != nil {
+				t.Errorf("待下发密钥未清除: %s/%v", key.PendingKeyEnc, key.PendingShortIDs)
+			}
+			if key.Status != RealityKeyApplied || key.ErrorMessage != "" || key.ServerName != "www.microsoft.com" {
+				t.Errorf("状态 = %s/%q/%s", key.Status, key.ErrorMessage, key.ServerName)
+			}
+			if key.Version != tt.wantVersion || !key.RotatedAt.Equal(tt.wantRotated) {
+				t.Errorf("Version=%d RotatedAt=%s, want %d %s", key.Version, key.RotatedAt, tt.wantVersion, tt.wantRotated)
+			}
+			if want := nextRealityRotation(tt.wantRotated, key.RotationDays); !reflect.DeepEqual(key.NextRotationAt, want) {
+				t.Errorf("NextRotationAt = %v, want %v", key.NextRotationAt, want)
+			}
+		})
+	}
+}
+
+func TestInjectRealityKeys(t *testing.T) {
+	s := &realityService{sealer: secretSealer{secretKey: "secret", setting: "reality.secret_key", subject: "Reality私钥"}}
+	privateKey := "dwdtCnMYpX08FsFyUbJmRd9ML4frwJkqsXf7pR25LCo"
+	newKey := func(tag string) models.RealityKey {
+		key := models.RealityKey{AgentID: "agent-001", Instance: "default", InboundTag: tag, PublicKey: "pub", ShortIDs: []string{"0123456789abcdef"}}
+		encrypted, err := s.encrypt(&key, privateKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		key.PrivateKeyEnc = encrypted
+		return key
+	}
+
+	// 模板渲染的完整配置不含私钥，托管的入站需写回当前密钥才能通过校验
+	content := `{
+		"inbounds": [
+			{"type": "vless", "tag": "vless-reality", "listen_port": 443, "users": [{"name": "alice", "uuid": "bf000d23-0752-40b4-affe-68f7707a9661"}],
+			 "tls": {"enabled": true, "server_name": "www.microsoft.com", "reality": {"enabled": true, "handshake": {"server": "www.microsoft.com", "server_port": 443}, "x_extra": 1}}},
+			{"type": "vless", "tag": "vless-tls", "listen_port": 8443, "users": [{"name": "bob", "uuid": "bf000d23-0752-40b4-affe-68f7707a9662"}],
+			 "tls": {"enabled": true, "certificate_path": "/etc/cert.pem", "key_path": "/etc/key.pem"}}
+		],
+		"outbounds": [{"type": "direct", "tag": "direct"}]
+	}`
+	var config singbox.Config
+	if err := json.Unmarshal([]byte(content), &config); err != nil {
+		t.Fatal(err)
+	}
+	if errs := singbox.Validate(&config); errs == nil {
+		t.Fatal("缺少私钥的配置应校验失败")
+	}
+
+	keys := []models.RealityKey{newKey("vless-reality"), newKey("vless-tls"), newKey("removed")}
+	injected, err := s.injectKeys(&config, keys)
+	if err != nil {
+		t.Fatalf("injectKeys() error = %v", err)
+	}
+	if injected != 1 {
+		t.Errorf("injectKeys() = %d, want 1（未启用Reality或不存在的入站跳过）", injected)
+	}
+	reality := config.Inbounds[0].TLS.Reality
+	if reality.PrivateKey != privateKey || !reflect.DeepEqual(reality.ShortID, []string{"0123456789abcdef"}) {
+		t.Errorf("reality = %+v, want 托管的私钥和short_id", reality)
+	}
+	if config.Inbounds[1].TLS.Reality != nil {
+		t.Errorf("未启用Reality的入站被修改: %+v", config.Inbounds[1].TLS)
+	}
+	if errs := singbox.Validate(&config); errs != nil {
+		t.Errorf("写入密钥后校验失败: %v", errs)
+	}
+	data, err := json.Marshal(&config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"x_extra":1`) {
+		t.Errorf("未建模字段丢失: %s", data)
+	}
+
+	// 无法解密时拒绝下发
+	broken := newKey("vless-reality")
+	broken.PrivateKeyEnc = "invalid"
+	if _, err := s.injectKeys(&config, []models.RealityKey{broken}); err == nil {
+		t.Error("无法解密托管密钥时应返回错误")
+	}
+}
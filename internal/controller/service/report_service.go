@@ -87,7 +87,7 @@ func NewNodeReportService(db *gorm.DB, agentRepo repository.AgentRepository, bac
 
 // StartReporting 开始定时上报
 func (s *NodeReportService) StartReporting(ctx context.Context, interval time.Duration) {
-	s.logger.Info("开始启动节点信息定时上报服务，上报间隔: %s", interval)
+	s.logger.Infof("开始启动节点信息定时上报服务，上报间隔: %s", interval)
 	
 	// 立即执行一次上报
 	s.reportNodeInfo(ctx)
@@ -133,7 +133,7 @@ func (s *NodeReportService) reportNodeInfo(ctx context.Context) {
 	// 收集节点信息
 	nodes, stats, err := s.collectNodeInfo(ctx)
 	if err != nil {
-		s.logger.Error("收集节点信息失败: %v", err)
+		s.logger.Errorf("收集节点信息失败: %v", err)
 		return
 	}
 	
@@ -152,12 +152,12 @@ func (s *NodeReportService) reportNodeInfo(ctx context.Context) {
 	
 	// 发送上报请求
 	if err := s.sendReportRequest(ctx, reportRequest); err != nil {
-		s.logger.Error("发送节点信息上报失败: %v", err)
+		s.logger.Errorf("发送节点信息上报失败: %v", err)
 		return
 	}
 	
 	duration := time.Since(startTime)
-	s.logger.Info("节点信息上报完成，耗时: %v，上报节点数: %d", duration, len(nodes))
+	s.logger.Infof("节点信息上报完成，耗时: %v，上报节点数: %d", duration, len(nodes))
 }
 
 // collectNodeInfo 收集节点信息
@@ -199,7 +199,7 @@ func (s *NodeReportService) collectNodeInfo(ctx context.Context) ([]NodeInfo, Re
 	
 	stats.TotalIPRanges = len(ipRangeSet)
 	
-	s.logger.Debug("节点信息收集完成，总节点: %d，在线: %d，离线: %d，错误: %d，IP段: %d",
+	s.logger.Debugf("节点信息收集完成，总节点: %d，在线: %d，离线: %d，错误: %d，IP段: %d",
 		stats.TotalNodes, stats.OnlineNodes, stats.OfflineNodes, stats.ErrorNodes, stats.TotalIPRanges)
 	
 	return nodes, stats, nil
@@ -276,7 +276,7 @@ func (s *NodeReportService) sendReportRequest(ctx context.Context, request Repor
 	httpReq.Header.Set("Content-Type", "application/json")
 	httpReq.Header.Set("User-Agent", "Xbox-Controller/1.0")
 	
-	s.logger.Debug("发送节点信息上报请求到: %s，节点数量: %d", url, len(request.Nodes))
+	s.logger.Debugf("发送节点信息上报请求到: %s，节点数量: %d", url, len(request.Nodes))
 	
 	// 发送请求
 	resp, err := s.httpClient.Do(httpReq)
@@ -306,7 +306,7 @@ func (s *NodeReportService) sendReportRequest(ctx context.Context, request Repor
 		return fmt.Errorf("业务处理失败: %s", response.Message)
 	}
 	
-	s.logger.Info("节点信息上报成功: %s", response.Data)
+	s.logger.Infof("节点信息上报成功: %s", response.Data)
 	return nil
 }
 
@@ -0,0 +1,278 @@
+package service
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/xbox/sing-box-manager/internal/controller/repository"
+	"github.com/xbox/sing-box-manager/internal/models"
+	"gorm.io/gorm"
+)
+
+// RolloutRequest sing-box版本批量切换请求
+type RolloutRequest struct {
+	Version     string   `json:"version" binding:"required"`
+	SHA256      string   `json:"sha256"`       // 制品sha256，为空时由Agent按安装来源校验
+	Instance    string   `json:"instance"`     // sing-box实例名称，默认default
+	AgentIDs    []string `json:"agent_ids"`    // 目标Agent，为空时按Status选择
+	Status      string   `json:"status"`       // 按Agent状态选择目标，默认online
+	MaxParallel int      `json:"max_parallel"` // 同时切换的Agent数，默认1
+	MaxFailures int      `json:"max_failures"` // 失败数超过该值后停止调度剩余Agent，默认0
+}
+
+// RolloutService sing-box版本批量切换服务接口
+type RolloutService interface {
+	// 创建切换任务并在后台按并发度逐台执行
+	StartRollout(req *RolloutRequest) (*models.SingboxRollout, error)
+	// 获取切换任务及各Agent进度
+	GetRollout(id uint) (*models.SingboxRollout, error)
+	// 获取最近的切换任务
+	ListRollouts(limit int) ([]models.SingboxRollout, error)
+}
+
+// rolloutService sing-box版本批量切换服务实现
+type rolloutService struct {
+	db          *gorm.DB
+	agentRepo   repository.AgentRepository
+	agentClient AgentClient
+}
+
+// NewRolloutService 创建版本批量切换服务
+func NewRolloutService(db *gorm.DB, agentRepo repository.AgentRepository, agentClient AgentClient) RolloutService {
+	return &rolloutService{
+		db:          db,
+		agentRepo:   agentRepo,
+		agentClient: agentClient,
+	}
+}
+
+// StartRollout 创建切换任务并在后台执行
+func (s *rolloutService) StartRollout(req *RolloutRequest) (*models.SingboxRollout, error) {
+	if req.Version == "" {
+		return nil, fmt.Errorf("目标版本不能为空")
+	}
+	if req.MaxParallel <= 0 {
+		req.MaxParallel = 1
+	}
+	if req.MaxFailures < 0 {
+		req.MaxFailures = 0
+	}
+
+	agentIDs, err := s.resolveTargets(req)
+	if err != nil {
+		return nil, err
+	}
+	if len(agentIDs) == 0 {
+		return nil, fmt.Errorf("没有符合条件的Agent")
+	}
+
+	rollout := &models.SingboxRollout{
+		Version:     req.Version,
+		SHA256:      req.SHA256,
+		Instance:    req.Instance,
+		MaxParallel: req.MaxParallel,
+		MaxFailures: req.MaxFailures,
+		Status:      "running",
+		Total:       len(agentIDs),
+	}
+	for _, agentID := range agentIDs {
+		rollout.Targets = append(rollout.Targets, models.SingboxRolloutTarget{
+			AgentID: agentID,
+			Status:  "pending",
+		})
+	}
+
+	if err := s.db.Create(rollout).Error; err != nil {
+		return nil, fmt.Errorf("创建版本切换任务失败: %w", err)
+	}
+
+	log.Printf("创建sing-box版本切换任务: ID=%d, Version=%s, Agents=%d, 并发=%d, 容忍失败=%d",
+		rollout.ID, rollout.Version, rollout.Total, rollout.MaxParallel, rollout.MaxFailures)
+
+	// 后台执行会修改任务和目标状态，返回快照给调用方
+	snapshot := *rollout
+	snapshot.Targets = append([]models.SingboxRolloutTarget(nil), rollout.Targets...)
+
+	go s.execute(rollout)
+
+	return &snapshot, nil
+}
+
+// resolveTargets 确定切换目标，显式指定的Agent需存在
+func (s *rolloutService) resolveTargets(req *RolloutRequest) ([]string, error) {
+	if len(req.AgentIDs) > 0 {
+		seen := make(map[string]bool, len(req.AgentIDs))
+		var agentIDs []string
+		for _, agentID := range req.AgentIDs {
+			if seen[agentID] {
+				continue
+			}
+			seen[agentID] = true
+			if _, err := s.agentRepo.GetByID(agentID); err != nil {
+				return nil, fmt.Errorf("Agent %s 不存在: %w", agentID, err)
+			}
+			agentIDs = append(agentIDs, agentID)
+		}
+		return agentIDs, nil
+	}
+
+	status := req.Status
+	if status == "" {
+		status = "online"
+	}
+	agents, _, err := s.agentRepo.GetByStatus(status, -1, -1)
+	if err != nil {
+		return nil, fmt.Errorf("查询Agent列表失败: %w", err)
+	}
+	agentIDs := make([]string, 0, len(agents))
+	for _, agent := range agents {
+		agentIDs = append(agentIDs, agent.ID)
+	}
+	return agentIDs, nil
+}
+
+// execute 按并发度调度各Agent，失败数超过阈值后剩余Agent标记为skipped
+func (s *rolloutService) execute(rollout *models.SingboxRollout) {
+	var (
+		mu     sync.Mutex
+		wg     sync.WaitGroup
+		failed int
+	)
+	sem := make(chan struct{}, rollout.MaxParallel)
+
+	for i := range rollout.Targets {
+		target := &rollout.Targets[i]
+		sem <- struct{}{}
+
+		mu.Lock()
+		halted := failed > rollout.MaxFailures
+		mu.Unlock()
+		if halted {
+			<-sem
+			s.finishTarget(target, "skipped", fmt.Sprintf("失败数超过 %d，停止调度", rollout.MaxFailures))
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			if !s.upgradeTarget(rollout, target) {
+				mu.Lock()
+				failed++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	for _, target := range rollout.Targets {
+		switch target.Status {
+		case "succeeded":
+			rollout.Succeeded++
+		case "skipped":
+			rollout.Skipped++
+		default:
+			rollout.Failed++
+		}
+	}
+
+	now := time.Now()
+	rollout.FinishedAt = &now
+	rollout.Status = "completed"
+	if rollout.Failed > 0 || rollout.Skipped > 0 {
+		rollout.Status = "failed"
+	}
+
+	if err := s.db.Model(&models.SingboxRollout{}).Where("id = ?", rollout.ID).Updates(map[string]interface{}{
+		"status":      rollout.Status,
+		"succeeded":   rollout.Succeeded,
+		"failed":      rollout.Failed,
+		"skipped":     rollout.Skipped,
+		"finished_at": rollout.FinishedAt,
+	}).Error; err != nil {
+		log.Printf("更新版本切换任务失败: ID=%d, Error=%v", rollout.ID, err)
+	}
+
+	log.Printf("sing-box版本切换任务结束: ID=%d, Status=%s, 成功=%d, 失败=%d, 跳过=%d",
+		rollout.ID, rollout.Status, rollout.Succeeded, rollout.Failed, rollout.Skipped)
+}
+
+// upgradeTarget 切换单个Agent的版本，返回是否成功
+func (s *rolloutService) upgradeTarget(rollout *models.SingboxRollout, target *models.SingboxRolloutTarget) bool {
+	now := time.Now()
+	target.Status = "running"
+	target.StartedAt = &now
+	if err := s.db.Model(target).Updates(map[string]interface{}{
+		"status":     target.Status,
+		"started_at": target.StartedAt,
+	}).Error; err != nil {
+		log.Printf("更新版本切换进度失败: AgentID=%s, Error=%v", target.AgentID, err)
+	}
+
+	resp, err := s.agentClient.UpgradeSingbox(target.AgentID, rollout.Instance, rollout.Version, rollout.SHA256)
+	if resp != nil {
+		target.FromVersion = resp.FromVersion
+		target.CurrentVersion = resp.CurrentVersion
+	}
+
+	status := "succeeded"
+	message := ""
+	if resp != nil {
+		message = resp.Message
+	}
+	if err != nil {
+		status = "failed"
+		if resp != nil && resp.RolledBack {
+			status = "rolled_back"
+		}
+		message = err.Error()
+		log.Printf("Agent版本切换失败: AgentID=%s, Version=%s, Status=%s, Error=%v",
+			target.AgentID, rollout.Version, status, err)
+	}
+
+	s.finishTarget(target, status, message)
+	return status == "succeeded"
+}
+
+// finishTarget 保存单个Agent的最终状态
+func (s *rolloutService) finishTarget(target *models.SingboxRolloutTarget, status, message string) {
+	now := time.Now()
+	target.Status = status
+	target.Message = message
+	target.FinishedAt = &now
+	if err := s.db.Model(target).Updates(map[string]interface{}{
+		"status":          target.Status,
+		"from_version":    target.FromVersion,
+		"current_version": target.CurrentVersion,
+		"message":         target.Message,
+		"finished_at":     target.FinishedAt,
+	}).Error; err != nil {
+		log.Printf("更新版本切换进度失败: AgentID=%s, Error=%v", target.AgentID, err)
+	}
+}
+
+// GetRollout 获取切换任务及各Agent进度
+func (s *rolloutService) GetRollout(id uint) (*models.SingboxRollout, error) {
+	var rollout models.SingboxRollout
+	if err := s.db.Preload("Targets").First(&rollout, id).Error; err != nil {
+		return nil, fmt.Errorf("版本切换任务 %d 不存在: %w", id, err)
+	}
+	return &rollout, nil
+}
+
+// ListRollouts 获取最近的切换任务
+func (s *rolloutService) ListRollouts(limit int) ([]models.SingboxRollout, error) {
+	if limit <= 0 {
+		limit = 20
+	}
+	var rollouts []models.SingboxRollout
+	if err := s.db.Order("created_at DESC").Limit(limit).Find(&rollouts).Error; err != nil {
+		return nil, fmt.Errorf("查询版本切换任务失败: %w", err)
+	}
+	return rollouts, nil
+}
@@ -0,0 +1,61 @@
+package service
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+)
+
+// secretSealer 以配置的密钥派生的AES-256-GCM加密保存私钥，附加数据将密文绑定到所属对象
+type secretSealer struct {
+	secretKey string
+	setting   string // 密钥对应的配置项，用于错误提示
+	subject   string // 加密对象名称，用于错误提示
+}
+
+// aead 由配置的密钥派生AES-256-GCM
+func (s secretSealer) aead() (cipher.AEAD, error) {
+	if s.secretKey == "" {
+		return nil, fmt.Errorf("未配置%s，无法加密保存%s", s.setting, s.subject)
+	}
+	sum := sha256.Sum256([]byte(s.secretKey))
+	block, err := aes.NewCipher(sum[:])
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// seal 加密明文，返回base64编码的随机数与密文
+func (s secretSealer) seal(plaintext string, ad []byte) (string, error) {
+	aead, err := s.aead()
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("生成随机数失败: %w", err)
+	}
+	sealed := aead.Seal(nonce, nonce, []byte(plaintext), ad)
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// open 解密seal的结果，附加数据需与加密时一致
+func (s secretSealer) open(encrypted string, ad []byte) (string, error) {
+	aead, err := s.aead()
+	if err != nil {
+		return "", err
+	}
+	data, err := base64.StdEncoding.DecodeString(encrypted)
+	if err != nil || len(data) < aead.NonceSize() {
+		return "", fmt.Errorf("%s密文无效", s.subject)
+	}
+	plaintext, err := aead.Open(nil, data[:aead.NonceSize()], data[aead.NonceSize():], ad)
+	if err != nil {
+		return "", fmt.Errorf("解密%s失败，%s可能已变更: %w", s.subject, s.setting, err)
+	}
+	return string(plaintext), nil
+}
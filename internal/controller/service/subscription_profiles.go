@@ -0,0 +1,510 @@
+package service
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/xbox/sing-box-manager/internal/agent/singbox"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// clientFingerprint Reality客户端使用的uTLS指纹
+	clientFingerprint = "chrome"
+	// urlTestURL 自动选择节点时的测速地址
+	urlTestURL = "https://www.gstatic.com/generate_204"
+)
+
+// clientOutbound 按入站和用户凭据生成客户端出站
+func (n *subscriptionNode) clientOutbound() (*singbox.Outbound, error) {
+	in := &n.Inbound
+	out := &singbox.Outbound{
+		Type:       in.Type,
+		Tag:        n.Name,
+		Server:     n.Server,
+		ServerPort: in.ListenPort,
+	}
+
+	switch in.Type {
+	case "vmess":
+		out.UUID = n.User.UUID
+		out.AlterId = n.User.AlterID
+		out.Security = "auto"
+	case "vless":
+		out.UUID = n.User.UUID
+		out.Flow = n.User.Flow
+	case "trojan":
+		out.Password = n.User.Password
+	case "shadowsocks":
+		out.Method = in.Method
+		out.Password = n.shadowsocksPassword()
+		out.Network = in.Network
+	case "hysteria2":
+		out.Password = n.User.Password
+		if in.Obfs != nil && in.Obfs.Type != "" {
+			obfs, err := json.Marshal(in.Obfs)
+			if err != nil {
+				return nil, err
+			}
+			out.Extra.Set("obfs", obfs)
+		}
+	default:
+		return nil, fmt.Errorf("入站类型 %s 不支持生成客户端配置", in.Type)
+	}
+
+	if n.RealityPublicKey == "" && n.Reality != nil {
+		out.TLS = realityKeyInfo(n.Reality).Client
+		if in.TLS != nil {
+			out.TLS.ALPN = in.TLS.ALPN
+		}
+	} else {
+		tls, err := clientTLS(in.TLS, n.RealityPublicKey)
+		if err != nil {
+			return nil, err
+		}
+		out.TLS = tls
+	}
+	if in.Transport != nil && in.Transport.Type != "" {
+		transport := *in.Transport
+		out.Transport = &transport
+	}
+	return out, nil
+}
+
+// shadowsocksPassword 多用户Shadowsocks 2022的客户端密码为"服务端密码:用户密码"
+func (n *subscriptionNode) shadowsocksPassword() string {
+	if n.Inbound.Password != "" {
+		return n.Inbound.Password + ":" + n.User.Password
+	}
+	return n.User.Password
+}
+
+// clientTLS 按入站TLS生成客户端TLS，Reality公钥未给出时由私钥推导
+func clientTLS(in *singbox.InboundTLS, realityKey string) (*singbox.OutboundTLS, error) {
+	if in == nil || !in.Enabled {
+		return nil, nil
+	}
+
+	tls := &singbox.OutboundTLS{
+		Enabled:    true,
+		ServerName: in.ServerName,
+		ALPN:       in.ALPN,
+	}
+	if tls.ServerName == "" && in.ACME != nil && len(in.ACME.Domain) > 0 {
+		tls.ServerName = in.ACME.Domain[0]
+	}
+
+	if reality := in.Reality; reality != nil && reality.Enabled {
+		publicKey := realityKey
+		if publicKey == "" {
+			derived, err := realityPublicKey(reality.PrivateKey)
+			if err != nil {
+				return nil, err
+			}
+			publicKey = derived
+		}
+		if tls.ServerName == "" && reality.Handshake != nil {
+			tls.ServerName = reality.Handshake.Server
+		}
+		tls.Reality = &singbox.RealityConfig{Enabled: true, PublicKey: publicKey}
+		if len(reality.ShortID) > 0 {
+			tls.Reality.ShortID = reality.ShortID[0]
+		}
+		tls.UTLS = &singbox.UTLSConfig{Enabled: true, Fingerprint: clientFingerprint}
+	}
+	return tls, nil
+}
+
+// realityPublicKey 由Reality私钥（base64url）推导X25519公钥
+func realityPublicKey(privateKey string) (string, error) {
+	return singbox.RealityPublicKey(privateKey)
+}
+
+// transportParams 返回分享链接中的传输参数：网络类型、Host和路径（gRPC为服务名）
+func transportParams(t *singbox.Transport) (network, host, path string) {
+	if t == nil || t.Type == "" {
+		return "tcp", "", ""
+	}
+	if len(t.Host) > 0 {
+		host = t.Host[0]
+	} else if t.Headers != nil {
+		host = t.Headers["Host"]
+	}
+	if t.Type == "grpc" {
+		return "grpc", host, t.ServiceName
+	}
+	return t.Type, host, t.Path
+}
+
+// buildShareLinks 生成分享链接，每行一个，无法生成的节点跳过
+func buildShareLinks(nodes []subscriptionNode) []byte {
+	links := make([]string, 0, len(nodes))
+	for i := range nodes {
+		link, err := nodes[i].shareLink()
+		if err != nil {
+			log.Printf("生成分享链接失败，跳过节点 %s: %v", nodes[i].Name, err)
+			continue
+		}
+		links = append(links, link)
+	}
+	return []byte(strings.Join(links, "\n"))
+}
+
+// shareLink 生成节点的分享链接
+func (n *subscriptionNode) shareLink() (string, error) {
+	out, err := n.clientOutbound()
+	if err != nil {
+		return "", err
+	}
+	host := net.JoinHostPort(out.Server, strconv.Itoa(int(out.ServerPort)))
+
+	switch out.Type {
+	case "vmess":
+		return vmessLink(out)
+	case "vless":
+		query := linkQuery(out)
+		query.Set("encryption", "none")
+		if out.Flow != "" {
+			query.Set("flow", out.Flow)
+		}
+		return formatLink("vless", url.User(out.UUID), host, query, out.Tag), nil
+	case "trojan":
+		return formatLink("trojan", url.User(out.Password), host, linkQuery(out), out.Tag), nil
+	case "shadowsocks":
+		// SIP002：2022方法使用百分号编码的明文userinfo，其余方法使用base64url
+		userinfo := url.User(base64.RawURLEncoding.EncodeToString([]byte(out.Method + ":" + out.Password)))
+		if strings.HasPrefix(out.Method, "2022-") {
+			userinfo = url.UserPassword(out.Method, out.Password)
+		}
+		return formatLink("ss", userinfo, host, nil, out.Tag), nil
+	case "hysteria2":
+		query := url.Values{}
+		if out.TLS != nil {
+			if out.TLS.ServerName != "" {
+				query.Set("sni", out.TLS.ServerName)
+			}
+			if len(out.TLS.ALPN) > 0 {
+				query.Set("alpn", strings.Join(out.TLS.ALPN, ","))
+			}
+		}
+		if obfs := n.Inbound.Obfs; obfs != nil && obfs.Type != "" {
+			query.Set("obfs", obfs.Type)
+			query.Set("obfs-password", obfs.Password)
+		}
+		return formatLink("hysteria2", url.User(out.Password), host, query, out.Tag), nil
+	}
+	return "", fmt.Errorf("入站类型 %s 不支持分享链接", out.Type)
+}
+
+// linkQuery 生成vless/trojan链接的传输和TLS参数
+func linkQuery(out *singbox.Outbound) url.Values {
+	query := url.Values{}
+	network, host, path := transportParams(out.Transport)
+	query.Set("type", network)
+	if host != "" {
+		query.Set("host", host)
+	}
+	if path != "" {
+		if network == "grpc" {
+			query.Set("serviceName", path)
+		} else {
+			query.Set("path", path)
+		}
+	}
+
+	tls := out.TLS
+	switch {
+	case tls == nil:
+		query.Set("security", "none")
+	case tls.Reality != nil:
+		query.Set("security", "reality")
+		query.Set("pbk", tls.Reality.PublicKey)
+		if tls.Reality.ShortID != "" {
+			query.Set("sid", tls.Reality.ShortID)
+		}
+	default:
+		query.Set("security", "tls")
+	}
+	if tls != nil {
+		if tls.ServerName != "" {
+			query.Set("sni", tls.ServerName)
+		}
+		if len(tls.ALPN) > 0 {
+			query.Set("alpn", strings.Join(tls.ALPN, ","))
+		}
+		if tls.UTLS != nil && tls.UTLS.Fingerprint != "" {
+			query.Set("fp", tls.UTLS.Fingerprint)
+		}
+	}
+	return query
+}
+
+// formatLink 拼接scheme://userinfo@host:port?query#name
+func formatLink(scheme string, user *url.Userinfo, host string, query url.Values, name string) string {
+	link := url.URL{
+		Scheme:   scheme,
+		User:     user,
+		Host:     host,
+		Fragment: name,
+	}
+	if len(query) > 0 {
+		link.RawQuery = query.Encode()
+	}
+	return link.String()
+}
+
+// vmessShare v2rayN格式的vmess分享内容
+type vmessShare struct {
+	V    string `json:"v"`
+	PS   string `json:"ps"`
+	Add  string `json:"add"`
+	Port string `json:"port"`
+	ID   string `json:"id"`
+	Aid  string `json:"aid"`
+	Scy  string `json:"scy"`
+	Net  string `json:"net"`
+	Type string `json:"type"`
+	Host string `json:"host"`
+	Path string `json:"path"`
+	TLS  string `json:"tls"`
+	SNI  string `json:"sni"`
+	ALPN string `json:"alpn"`
+	FP   string `json:"fp"`
+}
+
+// vmessLink 生成vmess://base64(json)链接
+func vmessLink(out *singbox.Outbound) (string, error) {
+	network, host, path := transportParams(out.Transport)
+	link := vmessShare{
+		V:    "2",
+		PS:   out.Tag,
+		Add:  out.Server,
+		Port: strconv.Itoa(int(out.ServerPort)),
+		ID:   out.UUID,
+		Aid:  strconv.Itoa(out.AlterId),
+		Scy:  out.Security,
+		Net:  network,
+		Type: "none",
+		Host: host,
+		Path: path,
+	}
+	if network == "http" {
+		link.Net = "h2"
+	}
+	if out.TLS != nil {
+		link.TLS = "tls"
+		link.SNI = out.TLS.ServerName
+		link.ALPN = strings.Join(out.TLS.ALPN, ",")
+		if out.TLS.UTLS != nil {
+			link.FP = out.TLS.UTLS.Fingerprint
+		}
+	}
+
+	data, err := json.Marshal(link)
+	if err != nil {
+		return "", err
+	}
+	return "vmess://" + base64.StdEncoding.EncodeToString(data), nil
+}
+
+// buildSingboxProfile 生成sing-box客户端配置：本地mixed入站，proxy手动选择组和auto自动测速组
+func buildSingboxProfile(nodes []subscriptionNode) ([]byte, error) {
+	config := singbox.Config{
+		Log: &singbox.LogConfig{Level: "info", Timestamp: true},
+		Inbounds: []singbox.Inbound{{
+			Type:       "mixed",
+			Tag:        "mixed-in",
+			Listen:     "127.0.0.1",
+			ListenPort: 2080,
+		}},
+		Route: &singbox.RouteConfig{Final: "direct", AutoDetectInterface: true},
+	}
+
+	var tags []string
+	var proxies []singbox.Outbound
+	for i := range nodes {
+		out, err := nodes[i].clientOutbound()
+		if err != nil {
+			log.Printf("生成客户端出站失败，跳过节点 %s: %v", nodes[i].Name, err)
+			continue
+		}
+		tags = append(tags, out.Tag)
+		proxies = append(proxies, *out)
+	}
+
+	if len(tags) > 0 {
+		selector := singbox.Outbound{Type: "selector", Tag: "proxy", Outbounds: append([]string{"auto"}, tags...)}
+		urltest := singbox.Outbound{Type: "urltest", Tag: "auto", Outbounds: tags, URL: urlTestURL}
+		config.Outbounds = append(config.Outbounds, selector, urltest)
+		config.Outbounds = append(config.Outbounds, proxies...)
+		config.Route.Final = "proxy"
+	}
+	config.Outbounds = append(config.Outbounds, singbox.Outbound{Type: "direct", Tag: "direct"})
+
+	return json.MarshalIndent(&config, "", "  ")
+}
+
+// clashProfile Clash-Meta客户端配置
+type clashProfile struct {
+	MixedPort   int                      `yaml:"mixed-port"`
+	AllowLan    bool                     `yaml:"allow-lan"`
+	Mode        string                   `yaml:"mode"`
+	LogLevel    string                   `yaml:"log-level"`
+	Proxies     []map[string]interface{} `yaml:"proxies"`
+	ProxyGroups []clashProxyGroup        `yaml:"proxy-groups"`
+	Rules       []string                 `yaml:"rules"`
+}
+
+// clashProxyGroup Clash策略组
+type clashProxyGroup struct {
+	Name     string   `yaml:"name"`
+	Type     string   `yaml:"type"`
+	Proxies  []string `yaml:"proxies"`
+	URL      string   `yaml:"url,omitempty"`
+	Interval int      `yaml:"interval,omitempty"`
+}
+
+// buildClashProfile 生成Clash-Meta客户端配置，包含PROXY手动选择组和auto自动测速组
+func buildClashProfile(nodes []subscriptionNode) ([]byte, error) {
+	profile := clashProfile{
+		MixedPort: 7890,
+		Mode:      "rule",
+		LogLevel:  "info",
+		Proxies:   []map[string]interface{}{},
+		Rules:     []string{"MATCH,DIRECT"},
+	}
+
+	var names []string
+	for i := range nodes {
+		proxy, err := nodes[i].clashProxy()
+		if err != nil {
+			log.Printf("生成Clash节点失败，跳过节点 %s: %v", nodes[i].Name, err)
+			continue
+		}
+		names = append(names, nodes[i].Name)
+		profile.Proxies = append(profile.Proxies, proxy)
+	}
+
+	if len(names) > 0 {
+		profile.ProxyGroups = []clashProxyGroup{
+			{Name: "PROXY", Type: "select", Proxies: append([]string{"auto"}, names...)},
+			{Name: "auto", Type: "url-test", Proxies: names, URL: urlTestURL, Interval: 300},
+		}
+		profile.Rules = []string{"MATCH,PROXY"}
+	}
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&profile); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// clashProxy 生成Clash-Meta节点
+func (n *subscriptionNode) clashProxy() (map[string]interface{}, error) {
+	out, err := n.clientOutbound()
+	if err != nil {
+		return nil, err
+	}
+
+	proxy := map[string]interface{}{
+		"name":   out.Tag,
+		"type":   out.Type,
+		"server": out.Server,
+		"port":   int(out.ServerPort),
+		"udp":    true,
+	}
+
+	switch out.Type {
+	case "vmess":
+		proxy["uuid"] = out.UUID
+		proxy["alterId"] = out.AlterId
+		proxy["cipher"] = out.Security
+	case "vless":
+		proxy["uuid"] = out.UUID
+		if out.Flow != "" {
+			proxy["flow"] = out.Flow
+		}
+	case "trojan", "hysteria2":
+		proxy["password"] = out.Password
+	case "shadowsocks":
+		proxy["type"] = "ss"
+		proxy["cipher"] = out.Method
+		proxy["password"] = out.Password
+	}
+	if obfs := n.Inbound.Obfs; out.Type == "hysteria2" && obfs != nil && obfs.Type != "" {
+		proxy["obfs"] = obfs.Type
+		proxy["obfs-password"] = obfs.Password
+	}
+
+	if tls := out.TLS; tls != nil {
+		sniKey := "sni"
+		if out.Type == "vmess" || out.Type == "vless" {
+			proxy["tls"] = true
+			sniKey = "servername"
+		}
+		if tls.ServerName != "" {
+			proxy[sniKey] = tls.ServerName
+		}
+		if len(tls.ALPN) > 0 {
+			proxy["alpn"] = tls.ALPN
+		}
+		if tls.Reality != nil {
+			realityOpts := map[string]interface{}{"public-key": tls.Reality.PublicKey}
+			if tls.Reality.ShortID != "" {
+				realityOpts["short-id"] = tls.Reality.ShortID
+			}
+			proxy["reality-opts"] = realityOpts
+		}
+		if tls.UTLS != nil && tls.UTLS.Fingerprint != "" {
+			proxy["client-fingerprint"] = tls.UTLS.Fingerprint
+		}
+	}
+
+	if t := out.Transport; t != nil {
+		network, host, path := transportParams(t)
+		switch network {
+		case "ws", "httpupgrade":
+			wsOpts := map[string]interface{}{}
+			if path != "" {
+				wsOpts["path"] = path
+			}
+			if host != "" {
+				wsOpts["headers"] = map[string]string{"Host": host}
+			}
+			if network == "httpupgrade" {
+				wsOpts["v2ray-http-upgrade"] = true
+			}
+			proxy["network"] = "ws"
+			proxy["ws-opts"] = wsOpts
+		case "grpc":
+			proxy["network"] = "grpc"
+			proxy["grpc-opts"] = map[string]interface{}{"grpc-service-name": path}
+		case "http":
+			h2Opts := map[string]interface{}{}
+			if path != "" {
+				h2Opts["path"] = path
+			}
+			if len(t.Host) > 0 {
+				h2Opts["host"] = t.Host
+			}
+			proxy["network"] = "h2"
+			proxy["h2-opts"] = h2Opts
+		default:
+			return nil, fmt.Errorf("Clash不支持 %s 传输", network)
+		}
+	}
+	return proxy, nil
+}
@@ -0,0 +1,523 @@
+package service
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/xbox/sing-box-manager/internal/agent/singbox"
+	"github.com/xbox/sing-box-manager/internal/models"
+	"gorm.io/gorm"
+)
+
+// 订阅输出格式
+const (
+	SubscriptionFormatLinks   = "links"   // 分享链接，每行一个
+	SubscriptionFormatSingbox = "singbox" // sing-box客户端配置
+	SubscriptionFormatClash   = "clash"   // Clash-Meta客户端配置
+)
+
+// 订阅输出模式
+const (
+	SubscriptionModeBase64 = "base64"
+	SubscriptionModeRaw    = "raw"
+)
+
+// subscriptionCacheTTL 从Agent获取的入站定义的缓存时间
+const subscriptionCacheTTL = time.Minute
+
+// ErrSubscriptionNotFound 订阅令牌无效、已停用或已过期
+var ErrSubscriptionNotFound = errors.New("订阅不存在或已失效")
+
+// SubscriptionRequest 创建或修改订阅请求
+type SubscriptionRequest struct {
+	Name        string     `json:"name"`
+	UserName    string     `json:"user_name" binding:"required"` // 入站用户标识（name，mixed/http/socks为username）
+	Enabled     *bool      `json:"enabled"`                      // 默认启用
+	Regions     []string   `json:"regions"`                      // 允许的国家或地区，为空不限
+	AgentIDs    []string   `json:"agent_ids"`                    // 允许的Agent，为空不限
+	InboundTags []string   `json:"inbound_tags"`                 // 允许的入站tag，为空不限
+	ExpiresAt   *time.Time `json:"expires_at"`
+}
+
+// SubscriptionOptions 订阅输出选项
+type SubscriptionOptions struct {
+	Format  string   // links、singbox或clash，默认links
+	Mode    string   // base64或raw，分享链接默认base64，客户端配置默认raw
+	Regions []string // 在订阅授权范围内进一步按国家或地区过滤
+}
+
+// SubscriptionContent 订阅输出内容
+type SubscriptionContent struct {
+	Data        []byte
+	ContentType string
+	Filename    string
+	Nodes       int // 节点数
+}
+
+// SubscriptionService 客户端订阅服务接口
+type SubscriptionService interface {
+	CreateSubscription(req *SubscriptionRequest) (*models.Subscription, error)
+	UpdateSubscription(id uint, req *SubscriptionRequest) (*models.Subscription, error)
+	GetSubscription(id uint) (*models.Subscription, error)
+	ListSubscriptions() ([]models.Subscription, error)
+	DeleteSubscription(id uint) error
+	// 重新生成订阅令牌，旧令牌立即失效
+	ResetToken(id uint) (*models.Subscription, error)
+	// 按令牌生成订阅内容，令牌无效时返回ErrSubscriptionNotFound
+	Render(token string, opts SubscriptionOptions) (*SubscriptionContent, error)
+}
+
+// subscriptionService 客户端订阅服务实现
+type subscriptionService struct {
+	db          *gorm.DB
+	agentClient AgentClient
+
+	mu       sync.Mutex
+	inbounds map[string]*inboundsCache // 按Agent ID缓存的入站定义
+}
+
+// NewSubscriptionService 创建客户端订阅服务
+func NewSubscriptionService(db *gorm.DB, agentClient AgentClient) SubscriptionService {
+	return &subscriptionService{
+		db:          db,
+		agentClient: agentClient,
+		inbounds:    make(map[string]*inboundsCache),
+	}
+}
+
+// inboundsCache 从Agent获取的入站定义
+type inboundsCache struct {
+	inbounds  []agentInbound
+	fetchedAt time.Time
+}
+
+// agentInbound Agent某个实例上的入站
+type agentInbound struct {
+	Instance         string
+	Inbound          singbox.Inbound
+	RealityPublicKey string             // Agent返回的Reality公钥，Agent不返回私钥
+	Reality          *models.RealityKey // 托管的Reality密钥，仅在使用配置记录时设置
+}
+
+// subscriptionNode 订阅中的一个节点：Agent上某个入站及该用户在其中的凭据
+type subscriptionNode struct {
+	Name             string
+	Server           string
+	Inbound          singbox.Inbound
+	User             singbox.InboundUser
+	RealityPublicKey string             // Agent返回的Reality公钥
+	Reality          *models.RealityKey // 托管的Reality密钥，直接下发到Agent，不在配置记录中
+}
+
+// subscriptionInboundTypes 可生成客户端配置的入站类型
+var subscriptionInboundTypes = map[string]bool{
+	"vmess":       true,
+	"vless":       true,
+	"trojan":      true,
+	"shadowsocks": true,
+	"hysteria2":   true,
+}
+
+// CreateSubscription 创建订阅并生成令牌
+func (s *subscriptionService) CreateSubscription(req *SubscriptionRequest) (*models.Subscription, error) {
+	if req.UserName == "" {
+		return nil, fmt.Errorf("用户标识不能为空")
+	}
+	token, err := newSubscriptionToken()
+	if err != nil {
+		return nil, err
+	}
+
+	sub := &models.Subscription{Token: token, Enabled: true}
+	applySubscriptionRequest(sub, req)
+	if err := s.db.Create(sub).Error; err != nil {
+		return nil, fmt.Errorf("创建订阅失败: %w", err)
+	}
+	return sub, nil
+}
+
+// UpdateSubscription 修改订阅授权范围，令牌不变
+func (s *subscriptionService) UpdateSubscription(id uint, req *SubscriptionRequest) (*models.Subscription, error) {
+	sub, err := s.GetSubscription(id)
+	if err != nil {
+		return nil, err
+	}
+	if req.UserName == "" {
+		return nil, fmt.Errorf("用户标识不能为空")
+	}
+
+	applySubscriptionRequest(sub, req)
+	if err := s.db.Save(sub).Error; err != nil {
+		return nil, fmt.Errorf("更新订阅失败: %w", err)
+	}
+	return sub, nil
+}
+
+// applySubscriptionRequest 将请求字段写入订阅
+func applySubscriptionRequest(sub *models.Subscription, req *SubscriptionRequest) {
+	sub.Name = req.Name
+	sub.UserName = req.UserName
+	if req.Enabled != nil {
+		sub.Enabled = *req.Enabled
+	}
+	sub.Regions = req.Regions
+	sub.AgentIDs = req.AgentIDs
+	sub.InboundTags = req.InboundTags
+	sub.ExpiresAt = req.ExpiresAt
+}
+
+// GetSubscription 获取订阅
+func (s *subscriptionService) GetSubscription(id uint) (*models.Subscription, error) {
+	var sub models.Subscription
+	if err := s.db.First(&sub, id).Error; err != nil {
+		return nil, fmt.Errorf("订阅 %d 不存在: %w", id, err)
+	}
+	return &sub, nil
+}
+
+// ListSubscriptions 获取全部订阅
+func (s *subscriptionService) ListSubscriptions() ([]models.Subscription, error) {
+	var subs []models.Subscription
+	if err := s.db.Order("id").Find(&subs).Error; err != nil {
+		return nil, fmt.Errorf("查询订阅失败: %w", err)
+	}
+	return subs, nil
+}
+
+// DeleteSubscription 删除订阅
+func (s *subscriptionService) DeleteSubscription(id uint) error {
+	result := s.db.Delete(&models.Subscription{}, id)
+	if result.Error != nil {
+		return fmt.Errorf("删除订阅失败: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("订阅 %d 不存在", id)
+	}
+	return nil
+}
+
+// ResetToken 重新生成订阅令牌
+func (s *subscriptionService) ResetToken(id uint) (*models.Subscription, error) {
+	sub, err := s.GetSubscription(id)
+	if err != nil {
+		return nil, err
+	}
+	token, err := newSubscriptionToken()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.db.Model(sub).Update("token", token).Error; err != nil {
+		return nil, fmt.Errorf("重置订阅令牌失败: %w", err)
+	}
+	sub.Token = token
+	return sub, nil
+}
+
+// newSubscriptionToken 生成随机订阅令牌
+func newSubscriptionToken() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("生成订阅令牌失败: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// Render 按令牌收集用户可用节点并生成订阅内容
+func (s *subscriptionService) Render(token string, opts SubscriptionOptions) (*SubscriptionContent, error) {
+	if token == "" {
+		return nil, ErrSubscriptionNotFound
+	}
+	var sub models.Subscription
+	err := s.db.Where("token = ?", token).First(&sub).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrSubscriptionNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("查询订阅失败: %w", err)
+	}
+	if !sub.Enabled || (sub.ExpiresAt != nil && time.Now().After(*sub.ExpiresAt)) {
+		return nil, ErrSubscriptionNotFound
+	}
+
+	format := opts.Format
+	if format == "" {
+		format = SubscriptionFormatLinks
+	}
+	mode := opts.Mode
+	if mode == "" {
+		mode = SubscriptionModeRaw
+		if format == SubscriptionFormatLinks {
+			mode = SubscriptionModeBase64
+		}
+	}
+	if mode != SubscriptionModeBase64 && mode != SubscriptionModeRaw {
+		return nil, fmt.Errorf("不支持的输出模式: %s", mode)
+	}
+
+	nodes, err := s.collectNodes(&sub, opts.Regions)
+	if err != nil {
+		return nil, err
+	}
+
+	content := &SubscriptionContent{Nodes: len(nodes)}
+	switch format {
+	case SubscriptionFormatLinks:
+		content.Data = buildShareLinks(nodes)
+		content.ContentType = "text/plain; charset=utf-8"
+	case SubscriptionFormatSingbox:
+		content.Data, err = buildSingboxProfile(nodes)
+		content.ContentType = "application/json; charset=utf-8"
+		content.Filename = "sing-box.json"
+	case SubscriptionFormatClash:
+		content.Data, err = buildClashProfile(nodes)
+		content.ContentType = "text/yaml; charset=utf-8"
+		content.Filename = "clash.yaml"
+	default:
+		return nil, fmt.Errorf("不支持的订阅格式: %s", format)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("生成订阅内容失败: %w", err)
+	}
+
+	if mode == SubscriptionModeBase64 {
+		content.Data = []byte(base64.StdEncoding.EncodeToString(content.Data))
+		content.ContentType = "text/plain; charset=utf-8"
+	}
+
+	now := time.Now()
+	if err := s.db.Model(&sub).UpdateColumn("last_access_at", now).Error; err != nil {
+		log.Printf("更新订阅访问时间失败: ID=%d, Error=%v", sub.ID, err)
+	}
+	return content, nil
+}
+
+// collectNodes 收集用户有权使用的入站，入站和用户取自Agent当前生效的配置，
+// 无法从Agent获取且没有缓存时使用最近一次成功下发的配置
+func (s *subscriptionService) collectNodes(sub *models.Subscription, regions []string) ([]subscriptionNode, error) {
+	query := s.db.Model(&models.Agent{})
+	if len(sub.AgentIDs) > 0 {
+		query = query.Where("id IN ?", sub.AgentIDs)
+	}
+	var agents []models.Agent
+	if err := query.Order("country, hostname, id").Find(&agents).Error; err != nil {
+		return nil, fmt.Errorf("查询Agent列表失败: %w", err)
+	}
+
+	var matched []*models.Agent
+	for i := range agents {
+		agent := &agents[i]
+		if !matchRegion(agent, sub.Regions) || !matchRegion(agent, regions) {
+			continue
+		}
+		matched = append(matched, agent)
+	}
+	if len(matched) == 0 {
+		return nil, nil
+	}
+
+	inboundsByAgent := s.agentInbounds(matched)
+	var fallback []string
+	for _, agent := range matched {
+		if _, ok := inboundsByAgent[agent.ID]; !ok {
+			fallback = append(fallback, agent.ID)
+		}
+	}
+	if len(fallback) > 0 {
+		configured, err := s.configInbounds(fallback)
+		if err != nil {
+			return nil, err
+		}
+		for agentID, inbounds := range configured {
+			inboundsByAgent[agentID] = inbounds
+		}
+	}
+
+	allowedTags := make(map[string]bool, len(sub.InboundTags))
+	for _, tag := range sub.InboundTags {
+		allowedTags[tag] = true
+	}
+
+	var nodes []subscriptionNode
+	for _, agent := range matched {
+		for _, item := range inboundsByAgent[agent.ID] {
+			inbound := item.Inbound
+			if !subscriptionInboundTypes[inbound.Type] || inbound.ListenPort == 0 {
+				continue
+			}
+			if len(allowedTags) > 0 && !allowedTags[inbound.Tag] {
+				continue
+			}
+			for _, user := range inbound.Users {
+				if singbox.UserKey(inbound.Type, user) != sub.UserName {
+					continue
+				}
+				nodes = append(nodes, subscriptionNode{
+					Name:             nodeName(agent, item.Instance, inbound.Tag),
+					Server:           agentPublicAddress(agent),
+					Inbound:          inbound,
+					User:             user,
+					RealityPublicKey: item.RealityPublicKey,
+					Reality:          item.Reality,
+				})
+				break
+			}
+		}
+	}
+	return nodes, nil
+}
+
+// agentInbounds 获取各Agent当前生效的入站，缓存未过期时直接使用，获取失败或Agent离线时使用过期的缓存；
+// 从未获取成功的Agent不在结果中
+func (s *subscriptionService) agentInbounds(agents []*models.Agent) map[string][]agentInbound {
+	result := make(map[string][]agentInbound, len(agents))
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+	now := time.Now()
+	for _, agent := range agents {
+		s.mu.Lock()
+		cached := s.inbounds[agent.ID]
+		s.mu.Unlock()
+		if cached != nil && now.Sub(cached.fetchedAt) < subscriptionCacheTTL {
+			result[agent.ID] = cached.inbounds
+			continue
+		}
+		if agent.Status != "online" {
+			if cached != nil {
+				result[agent.ID] = cached.inbounds
+			}
+			continue
+		}
+
+		wg.Add(1)
+		go func(agentID string, cached *inboundsCache) {
+			defer wg.Done()
+			inbounds, err := s.fetchInbounds(agentID)
+			if err != nil {
+				log.Printf("获取Agent入站失败: AgentID=%s, Error=%v", agentID, err)
+				if cached != nil {
+					mu.Lock()
+					result[agentID] = cached.inbounds
+					mu.Unlock()
+				}
+				return
+			}
+			s.mu.Lock()
+			s.inbounds[agentID] = &inboundsCache{inbounds: inbounds, fetchedAt: time.Now()}
+			s.mu.Unlock()
+			mu.Lock()
+			result[agentID] = inbounds
+			mu.Unlock()
+		}(agent.ID, cached)
+	}
+	wg.Wait()
+	return result
+}
+
+// fetchInbounds 从Agent获取全部实例的入站定义
+func (s *subscriptionService) fetchInbounds(agentID string) ([]agentInbound, error) {
+	definitions, err := s.agentClient.GetInbounds(agentID)
+	if err != nil {
+		return nil, err
+	}
+	inbounds := make([]agentInbound, 0, len(definitions))
+	for _, definition := range definitions {
+		var inbound singbox.Inbound
+		if err := json.Unmarshal([]byte(definition.Config), &inbound); err != nil {
+			log.Printf("解析Agent入站失败，跳过: AgentID=%s, Instance=%s, Tag=%s, Error=%v",
+				agentID, definition.Instance, definition.Tag, err)
+			continue
+		}
+		inbounds = append(inbounds, agentInbound{
+			Instance:         definition.Instance,
+			Inbound:          inbound,
+			RealityPublicKey: definition.RealityPublicKey,
+		})
+	}
+	return inbounds, nil
+}
+
+// configInbounds 从各Agent每个实例最近一次成功下发的配置中读取入站，托管的Reality密钥以托管记录为准
+func (s *subscriptionService) configInbounds(agentIDs []string) (map[string][]agentInbound, error) {
+	var configs []models.Config
+	latest := s.db.Model(&models.Config{}).Select("MAX(id)").
+		Where("agent_id IN ? AND status = ?", agentIDs, "applied").
+		Group("agent_id, instance")
+	if err := s.db.Where("id IN (?)", latest).Order("agent_id, instance").Find(&configs).Error; err != nil {
+		return nil, fmt.Errorf("查询Agent配置失败: %w", err)
+	}
+
+	var realityKeys []models.RealityKey
+	// 托管时尚未下发成功的密钥没有当前公钥，不参与订阅
+	if err := s.db.Where("agent_id IN ? AND public_key <> ?", agentIDs, "").Find(&realityKeys).Error; err != nil {
+		return nil, fmt.Errorf("查询Reality密钥失败: %w", err)
+	}
+	realityByInbound := make(map[string]*models.RealityKey, len(realityKeys))
+	for i := range realityKeys {
+		key := &realityKeys[i]
+		realityByInbound[key.AgentID+"/"+key.Instance+"/"+key.InboundTag] = key
+	}
+
+	result := make(map[string][]agentInbound)
+	for _, record := range configs {
+		var config singbox.Config
+		if err := json.Unmarshal([]byte(record.ConfigContent), &config); err != nil {
+			log.Printf("解析Agent配置失败，跳过: AgentID=%s, Instance=%s, Error=%v", record.AgentID, record.Instance, err)
+			continue
+		}
+		for _, inbound := range config.Inbounds {
+			result[record.AgentID] = append(result[record.AgentID], agentInbound{
+				Instance: record.Instance,
+				Inbound:  inbound,
+				Reality:  realityByInbound[record.AgentID+"/"+record.Instance+"/"+inbound.Tag],
+			})
+		}
+	}
+	return result, nil
+}
+
+// matchRegion 判断Agent的国家或地区是否在列表中，列表为空时不限
+func matchRegion(agent *models.Agent, regions []string) bool {
+	if len(regions) == 0 {
+		return true
+	}
+	for _, region := range regions {
+		if strings.EqualFold(region, agent.Country) || strings.EqualFold(region, agent.Region) {
+			return true
+		}
+	}
+	return false
+}
+
+// agentPublicAddress 返回客户端连接Agent使用的地址，元数据public_address优先于上报的IP
+func agentPublicAddress(agent *models.Agent) string {
+	if address, ok := agent.Metadata["public_address"].(string); ok && address != "" {
+		return address
+	}
+	return agent.IPAddress
+}
+
+// nodeName 生成节点显示名称
+func nodeName(agent *models.Agent, instance, tag string) string {
+	name := agent.Hostname
+	if name == "" {
+		name = agent.ID
+	}
+	if instance != "" && instance != "default" {
+		name += "/" + instance
+	}
+	name += "-" + tag
+	if agent.Country != "" {
+		name = "[" + agent.Country + "] " + name
+	}
+	return name
+}
@@ -0,0 +1,112 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/xbox/sing-box-manager/internal/agent/singbox"
+	"github.com/xbox/sing-box-manager/internal/models"
+	pb "github.com/xbox/sing-box-manager/proto/agent"
+)
+
+// inboundsAgentClient 只实现GetInbounds的Agent客户端
+type inboundsAgentClient struct {
+	AgentClient
+	inbounds map[string][]*pb.InboundDefinition
+	err      error
+	calls    int
+}
+
+func (c *inboundsAgentClient) GetInbounds(agentID string) ([]*pb.InboundDefinition, error) {
+	c.calls++
+	if c.err != nil {
+		return nil, c.err
+	}
+	return c.inbounds[agentID], nil
+}
+
+func TestAgentInbounds(t *testing.T) {
+	config := `{"type": "vless", "tag": "vless-in", "listen_port": 443, "users": [{"name": "alice", "uuid": "u"}]}`
+	client := &inboundsAgentClient{inbounds: map[string][]*pb.InboundDefinition{
+		"a": {
+			{Instance: "default", Tag: "vless-in", Type: "vless", RealityPublicKey: "pub", Config: config},
+			{Instance: "default", Tag: "bad", Type: "vless", Config: `{`},
+		},
+	}}
+	s := NewSubscriptionService(nil, client).(*subscriptionService)
+	online := &models.Agent{ID: "a", Status: "online"}
+	offline := &models.Agent{ID: "b", Status: "offline"}
+
+	var inbound singbox.Inbound
+	if err := json.Unmarshal([]byte(config), &inbound); err != nil {
+		t.Fatal(err)
+	}
+	want := []agentInbound{{Instance: "default", Inbound: inbound, RealityPublicKey: "pub"}}
+
+	// 首次从Agent获取，解析失败的入站被跳过，离线且没有缓存的Agent不在结果中
+	got := s.agentInbounds([]*models.Agent{online, offline})
+	if !reflect.DeepEqual(got["a"], want) {
+		t.Fatalf("agentInbounds() = %+v, want %+v", got["a"], want)
+	}
+	if _, ok := got["b"]; ok || client.calls != 1 {
+		t.Fatalf("离线Agent不应获取: result=%v, calls=%d", got, client.calls)
+	}
+
+	// 缓存未过期时不再调用Agent
+	s.agentInbounds([]*models.Agent{online})
+	if client.calls != 1 {
+		t.Errorf("缓存未生效: calls=%d", client.calls)
+	}
+
+	// 缓存过期后获取失败时使用过期的缓存
+	s.inbounds["a"].fetchedAt = time.Now().Add(-2 * subscriptionCacheTTL)
+	client.err = errors.New("unavailable")
+	got = s.agentInbounds([]*models.Agent{online})
+	if client.calls != 2 || !reflect.DeepEqual(got["a"], want) {
+		t.Errorf("获取失败时 = %+v, calls=%d, want 过期缓存", got["a"], client.calls)
+	}
+
+	// Agent离线时使用过期的缓存
+	got = s.agentInbounds([]*models.Agent{{ID: "a", Status: "offline"}})
+	if client.calls != 2 || !reflect.DeepEqual(got["a"], want) {
+		t.Errorf("离线时 = %+v, calls=%d, want 过期缓存", got["a"], client.calls)
+	}
+}
+
+func TestClientTLSRealityKey(t *testing.T) {
+	privateKey := "dwdtCnMYpX08FsFyUbJmRd9ML4frwJkqsXf7pR25LCo"
+	derived, err := realityPublicKey(privateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	handshake := &singbox.RealityHandshake{Server: "www.microsoft.com", ServerPort: 443}
+
+	tests := []struct {
+		name      string
+		tls       *singbox.InboundTLS
+		publicKey string
+		want      string
+		wantErr   bool
+	}{
+		{"由私钥推导", &singbox.InboundTLS{Enabled: true, Reality: &singbox.InboundReality{Enabled: true, PrivateKey: privateKey, Handshake: handshake}}, "", derived, false},
+		{"使用Agent返回的公钥", &singbox.InboundTLS{Enabled: true, Reality: &singbox.InboundReality{Enabled: true, Handshake: handshake}}, "agent-pub", "agent-pub", false},
+		{"缺少私钥和公钥", &singbox.InboundTLS{Enabled: true, Reality: &singbox.InboundReality{Enabled: true, Handshake: handshake}}, "", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := clientTLS(tt.tls, tt.publicKey)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("clientTLS() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got.Reality == nil || got.Reality.PublicKey != tt.want || got.ServerName != "www.microsoft.com" {
+				t.Errorf("clientTLS() = %+v, want 公钥 %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,403 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"text/template"
+
+	"github.com/xbox/sing-box-manager/internal/agent/singbox"
+	"github.com/xbox/sing-box-manager/internal/controller/repository"
+	"github.com/xbox/sing-box-manager/internal/models"
+	"gorm.io/gorm"
+)
+
+// TemplateAgent 模板中可引用的Agent信息
+type TemplateAgent struct {
+	ID       string
+	Hostname string
+	IP       string
+	IPRange  string
+	Country  string
+	Region   string
+	City     string
+	ISP      string
+	Version  string
+}
+
+// TemplateData 渲染配置模板的数据：{{.Agent.IP}}、{{.Labels.role}}、{{.Vars.uuid}}
+type TemplateData struct {
+	Agent  TemplateAgent
+	Labels map[string]string // Agent元数据标签
+	Vars   map[string]string // Agent模板变量
+}
+
+// RenderedConfig 模板按Agent渲染的结果
+type RenderedConfig struct {
+	AgentID    string                   `json:"agent_id"`
+	TemplateID uint                     `json:"template_id"`
+	Revision   int                      `json:"revision"`
+	Config     json.RawMessage          `json:"config,omitempty"`            // 渲染并规范化后的sing-box配置
+	Errors     singbox.ValidationErrors `json:"validation_errors,omitempty"` // 语义校验问题
+	Error      string                   `json:"error,omitempty"`             // 渲染失败原因
+}
+
+// TemplateApplyResult 模板下发到单个Agent的结果
+type TemplateApplyResult struct {
+	AgentID string         `json:"agent_id"`
+	Success bool           `json:"success"`
+	Config  *models.Config `json:"config,omitempty"` // 配置记录
+	Error   string         `json:"error,omitempty"`
+}
+
+// TemplateService sing-box配置模板服务接口
+type TemplateService interface {
+	// 创建模板，内容需为合法的text/template
+	CreateTemplate(name, description, content string) (*models.ConfigTemplate, error)
+	// 修改模板，内容变化时生成新的修订版本
+	UpdateTemplate(id uint, description, content string) (*models.ConfigTemplate, error)
+	GetTemplate(id uint) (*models.ConfigTemplate, error)
+	ListTemplates() ([]models.ConfigTemplate, error)
+	DeleteTemplate(id uint) error
+	// 列出模板的全部修订版本
+	ListRevisions(id uint) ([]models.ConfigTemplateRevision, error)
+	// 获取和设置Agent的模板变量
+	GetAgentVariables(agentID string) (map[string]string, error)
+	SetAgentVariables(agentID string, vars map[string]string) error
+	// 按Agent预览渲染结果，revision为0时使用当前修订版本
+	Preview(id uint, revision int, agentIDs []string) ([]RenderedConfig, error)
+	// 渲染并通过UpdateConfig下发到各Agent
+	Apply(id uint, revision int, agentIDs []string, instance string, force bool) ([]TemplateApplyResult, error)
+}
+
+// templateService sing-box配置模板服务实现
+type templateService struct {
+	db            *gorm.DB
+	agentRepo     repository.AgentRepository
+	configService ConfigService
+}
+
+// NewTemplateService 创建配置模板服务
+func NewTemplateService(db *gorm.DB, agentRepo repository.AgentRepository, configService ConfigService) TemplateService {
+	return &templateService{
+		db:            db,
+		agentRepo:     agentRepo,
+		configService: configService,
+	}
+}
+
+// templateFuncs 模板可用的函数
+var templateFuncs = template.FuncMap{
+	// json 将值编码为JSON字面量，字符串会带引号并转义
+	"json": func(v interface{}) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+	// default 值为空时使用默认值：{{default "443" .Vars.port}}
+	"default": func(def, value string) string {
+		if value == "" {
+			return def
+		}
+		return value
+	},
+	// required 值为空时渲染失败：{{required "uuid" .Vars.uuid}}
+	"required": func(name, value string) (string, error) {
+		if value == "" {
+			return "", fmt.Errorf("缺少模板变量 %s", name)
+		}
+		return value, nil
+	},
+}
+
+// parseTemplate 解析模板内容
+func parseTemplate(name, content string) (*template.Template, error) {
+	tmpl, err := template.New(name).Funcs(templateFuncs).Parse(content)
+	if err != nil {
+		return nil, fmt.Errorf("模板语法错误: %w", err)
+	}
+	return tmpl, nil
+}
+
+// CreateTemplate 创建模板及其首个修订版本
+func (s *templateService) CreateTemplate(name, description, content string) (*models.ConfigTemplate, error) {
+	if name == "" {
+		return nil, fmt.Errorf("模板名称不能为空")
+	}
+	if _, err := parseTemplate(name, content); err != nil {
+		return nil, err
+	}
+
+	tmpl := &models.ConfigTemplate{
+		Name:        name,
+		Description: description,
+		Content:     content,
+		Revision:    1,
+	}
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(tmpl).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.ConfigTemplateRevision{
+			TemplateID: tmpl.ID,
+			Revision:   tmpl.Revision,
+			Content:    content,
+		}).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("创建配置模板失败: %w", err)
+	}
+	return tmpl, nil
+}
+
+// UpdateTemplate 修改模板描述和内容，内容变化时修订版本加1
+func (s *templateService) UpdateTemplate(id uint, description, content string) (*models.ConfigTemplate, error) {
+	tmpl, err := s.GetTemplate(id)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := parseTemplate(tmpl.Name, content); err != nil {
+		return nil, err
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		tmpl.Description = description
+		if content != tmpl.Content {
+			tmpl.Content = content
+			tmpl.Revision++
+			if err := tx.Create(&models.ConfigTemplateRevision{
+				TemplateID: tmpl.ID,
+				Revision:   tmpl.Revision,
+				Content:    content,
+			}).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Save(tmpl).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("更新配置模板失败: %w", err)
+	}
+	return tmpl, nil
+}
+
+// GetTemplate 获取模板
+func (s *templateService) GetTemplate(id uint) (*models.ConfigTemplate, error) {
+	var tmpl models.ConfigTemplate
+	if err := s.db.First(&tmpl, id).Error; err != nil {
+		return nil, fmt.Errorf("配置模板 %d 不存在: %w", id, err)
+	}
+	return &tmpl, nil
+}
+
+// ListTemplates 获取全部模板
+func (s *templateService) ListTemplates() ([]models.ConfigTemplate, error) {
+	var templates []models.ConfigTemplate
+	if err := s.db.Order("name").Find(&templates).Error; err != nil {
+		return nil, fmt.Errorf("查询配置模板失败: %w", err)
+	}
+	return templates, nil
+}
+
+// DeleteTemplate 删除模板及其修订版本，已下发的配置记录保留模板ID
+func (s *templateService) DeleteTemplate(id uint) error {
+	if _, err := s.GetTemplate(id); err != nil {
+		return err
+	}
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("template_id = ?", id).Delete(&models.ConfigTemplateRevision{}).Error; err != nil {
+			return fmt.Errorf("删除模板修订版本失败: %w", err)
+		}
+		if err := tx.Delete(&models.ConfigTemplate{}, id).Error; err != nil {
+			return fmt.Errorf("删除配置模板失败: %w", err)
+		}
+		return nil
+	})
+}
+
+// ListRevisions 列出模板的全部修订版本
+func (s *templateService) ListRevisions(id uint) ([]models.ConfigTemplateRevision, error) {
+	if _, err := s.GetTemplate(id); err != nil {
+		return nil, err
+	}
+	var revisions []models.ConfigTemplateRevision
+	if err := s.db.Where("template_id = ?", id).Order("revision DESC").Find(&revisions).Error; err != nil {
+		return nil, fmt.Errorf("查询模板修订版本失败: %w", err)
+	}
+	return revisions, nil
+}
+
+// GetAgentVariables 获取Agent的模板变量
+func (s *templateService) GetAgentVariables(agentID string) (map[string]string, error) {
+	if _, err := s.agentRepo.GetByID(agentID); err != nil {
+		return nil, fmt.Errorf("Agent %s 不存在: %w", agentID, err)
+	}
+
+	var record models.AgentTemplateVars
+	err := s.db.Where("agent_id = ?", agentID).First(&record).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return map[string]string{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("查询Agent模板变量失败: %w", err)
+	}
+	return stringMap(record.Variables), nil
+}
+
+// SetAgentVariables 替换Agent的全部模板变量
+func (s *templateService) SetAgentVariables(agentID string, vars map[string]string) error {
+	if _, err := s.agentRepo.GetByID(agentID); err != nil {
+		return fmt.Errorf("Agent %s 不存在: %w", agentID, err)
+	}
+
+	variables := make(models.JSON, len(vars))
+	for k, v := range vars {
+		variables[k] = v
+	}
+	record := &models.AgentTemplateVars{AgentID: agentID, Variables: variables}
+	if err := s.db.Save(record).Error; err != nil {
+		return fmt.Errorf("保存Agent模板变量失败: %w", err)
+	}
+	return nil
+}
+
+// Preview 按Agent渲染模板并做语义校验，不下发
+func (s *templateService) Preview(id uint, revision int, agentIDs []string) ([]RenderedConfig, error) {
+	tmpl, content, revision, err := s.loadRevision(id, revision)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]RenderedConfig, 0, len(agentIDs))
+	for _, agentID := range agentIDs {
+		results = append(results, s.render(tmpl, content, revision, agentID))
+	}
+	return results, nil
+}
+
+// Apply 按Agent渲染模板，校验通过后经UpdateConfig下发，配置记录中保存模板修订版本
+func (s *templateService) Apply(id uint, revision int, agentIDs []string, instance string, force bool) ([]TemplateApplyResult, error) {
+	if len(agentIDs) == 0 {
+		return nil, fmt.Errorf("未指定Agent")
+	}
+	tmpl, content, revision, err := s.loadRevision(id, revision)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]TemplateApplyResult, 0, len(agentIDs))
+	for _, agentID := range agentIDs {
+		result := TemplateApplyResult{AgentID: agentID}
+		rendered := s.render(tmpl, content, revision, agentID)
+		switch {
+		case rendered.Error != "":
+			result.Error = rendered.Error
+		case rendered.Errors != nil:
+			result.Error = rendered.Errors.Error()
+		default:
+			record, err := s.configService.PushTemplateConfig(agentID, instance, string(rendered.Config), tmpl.ID, revision, force)
+			result.Config = record
+			if err != nil {
+				result.Error = err.Error()
+			} else {
+				result.Success = true
+			}
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// loadRevision 读取模板指定修订版本的内容，revision为0时使用当前版本
+func (s *templateService) loadRevision(id uint, revision int) (*models.ConfigTemplate, string, int, error) {
+	tmpl, err := s.GetTemplate(id)
+	if err != nil {
+		return nil, "", 0, err
+	}
+	if revision == 0 || revision == tmpl.Revision {
+		return tmpl, tmpl.Content, tmpl.Revision, nil
+	}
+
+	var record models.ConfigTemplateRevision
+	if err := s.db.Where("template_id = ? AND revision = ?", id, revision).First(&record).Error; err != nil {
+		return nil, "", 0, fmt.Errorf("配置模板 %d 的修订版本 %d 不存在: %w", id, revision, err)
+	}
+	return tmpl, record.Content, revision, nil
+}
+
+// render 使用Agent信息、标签和变量渲染模板，解析为singbox.Config后规范化输出
+func (s *templateService) render(tmpl *models.ConfigTemplate, content string, revision int, agentID string) RenderedConfig {
+	result := RenderedConfig{AgentID: agentID, TemplateID: tmpl.ID, Revision: revision}
+
+	data, err := s.templateData(agentID)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	parsed, err := parseTemplate(tmpl.Name, content)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	var buf bytes.Buffer
+	if err := parsed.Option("missingkey=zero").Execute(&buf, data); err != nil {
+		result.Error = fmt.Sprintf("渲染模板失败: %v", err)
+		return result
+	}
+
+	var config singbox.Config
+	if err := json.Unmarshal(buf.Bytes(), &config); err != nil {
+		result.Error = fmt.Sprintf("渲染结果不是合法的sing-box配置: %v", err)
+		return result
+	}
+	normalized, err := json.MarshalIndent(&config, "", "  ")
+	if err != nil {
+		result.Error = fmt.Sprintf("序列化配置失败: %v", err)
+		return result
+	}
+	result.Config = normalized
+	result.Errors = singbox.Validate(&config)
+	return result
+}
+
+// templateData 汇总Agent信息、元数据标签和模板变量
+func (s *templateService) templateData(agentID string) (*TemplateData, error) {
+	agent, err := s.agentRepo.GetByID(agentID)
+	if err != nil {
+		return nil, fmt.Errorf("Agent %s 不存在: %w", agentID, err)
+	}
+	vars, err := s.GetAgentVariables(agentID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TemplateData{
+		Agent: TemplateAgent{
+			ID:       agent.ID,
+			Hostname: agent.Hostname,
+			IP:       agent.IPAddress,
+			IPRange:  agent.IPRange,
+			Country:  agent.Country,
+			Region:   agent.Region,
+			City:     agent.City,
+			ISP:      agent.ISP,
+			Version:  agent.Version,
+		},
+		Labels: stringMap(agent.Metadata),
+		Vars:   vars,
+	}, nil
+}
+
+// stringMap 将JSON对象转换为字符串映射，非字符串值按默认格式输出
+func stringMap(values models.JSON) map[string]string {
+	result := make(map[string]string, len(values))
+	for k, v := range values {
+		if str, ok := v.(string); ok {
+			result[k] = str
+		} else {
+			result[k] = fmt.Sprint(v)
+		}
+	}
+	return result
+}
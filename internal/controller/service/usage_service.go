@@ -0,0 +1,165 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/xbox/sing-box-manager/internal/models"
+	pb "github.com/xbox/sing-box-manager/proto/agent"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// UsageQuery 流量用量查询条件，空值表示不过滤
+type UsageQuery struct {
+	AgentID  string
+	Instance string
+	Scope    string
+	Name     string
+	From     time.Time
+	To       time.Time
+}
+
+// UsageTotal 统计对象在查询区间内的流量合计
+type UsageTotal struct {
+	AgentID  string `json:"agent_id"`
+	Instance string `json:"instance"`
+	Scope    string `json:"scope"`
+	Name     string `json:"name"`
+	Uplink   int64  `json:"uplink"`
+	Downlink int64  `json:"downlink"`
+}
+
+// UsageService 流量用量服务接口
+type UsageService interface {
+	// 保存Agent上报的流量增量并累加到小时汇总
+	RecordUsage(report *pb.TrafficUsageReport) error
+	// 查询小时汇总
+	GetHourly(query UsageQuery) ([]models.TrafficUsageHourly, error)
+	// 查询区间内各统计对象的流量合计
+	GetTotals(query UsageQuery) ([]UsageTotal, error)
+}
+
+// usageService 流量用量服务实现
+type usageService struct {
+	db *gorm.DB
+}
+
+// NewUsageService 创建流量用量服务
+func NewUsageService(db *gorm.DB) UsageService {
+	return &usageService{db: db}
+}
+
+// RecordUsage 保存Agent上报的流量增量，整批增量计入上报周期结束时间所在的小时。
+// Agent未收到响应时会原样重发同一批次，已记录的统计周期不再累加到小时汇总
+func (s *usageService) RecordUsage(report *pb.TrafficUsageReport) error {
+	if report.AgentId == "" {
+		return fmt.Errorf("Agent ID不能为空")
+	}
+	if len(report.Usages) == 0 {
+		return nil
+	}
+
+	periodStart := time.Unix(report.PeriodStart, 0)
+	periodEnd := time.Unix(report.PeriodEnd, 0)
+	hour := periodEnd.Truncate(time.Hour)
+	instance := report.Instance
+	if instance == "" {
+		instance = "default"
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		for _, usage := range report.Usages {
+			if usage.Uplink < 0 || usage.Downlink < 0 {
+				return fmt.Errorf("统计对象 %s/%s 的流量增量不能为负数", usage.Scope, usage.Name)
+			}
+
+			detail := &models.TrafficUsage{
+				AgentID:     report.AgentId,
+				Instance:    instance,
+				Scope:       usage.Scope,
+				Name:        usage.Name,
+				Uplink:      usage.Uplink,
+				Downlink:    usage.Downlink,
+				PeriodStart: periodStart,
+				PeriodEnd:   periodEnd,
+			}
+			result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(detail)
+			if result.Error != nil {
+				return fmt.Errorf("保存流量明细失败: %w", result.Error)
+			}
+			if result.RowsAffected == 0 {
+				continue
+			}
+
+			hourly := &models.TrafficUsageHourly{
+				AgentID:  report.AgentId,
+				Instance: instance,
+				Scope:    usage.Scope,
+				Name:     usage.Name,
+				Hour:     hour,
+				Uplink:   usage.Uplink,
+				Downlink: usage.Downlink,
+			}
+			err := tx.Clauses(clause.OnConflict{
+				Columns: []clause.Column{{Name: "agent_id"}, {Name: "instance"}, {Name: "scope"}, {Name: "name"}, {Name: "hour"}},
+				DoUpdates: clause.Assignments(map[string]interface{}{
+					"uplink":     gorm.Expr("uplink + ?", usage.Uplink),
+					"downlink":   gorm.Expr("downlink + ?", usage.Downlink),
+					"updated_at": time.Now(),
+				}),
+			}).Create(hourly).Error
+			if err != nil {
+				return fmt.Errorf("更新流量小时汇总失败: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// GetHourly 查询小时汇总，按时间倒序
+func (s *usageService) GetHourly(query UsageQuery) ([]models.TrafficUsageHourly, error) {
+	var rows []models.TrafficUsageHourly
+	if err := s.filter(s.db.Model(&models.TrafficUsageHourly{}), query).
+		Order("hour DESC, agent_id, instance, scope, name").
+		Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("查询流量小时汇总失败: %w", err)
+	}
+	return rows, nil
+}
+
+// GetTotals 查询区间内各统计对象的流量合计，按总流量倒序
+func (s *usageService) GetTotals(query UsageQuery) ([]UsageTotal, error) {
+	var totals []UsageTotal
+	if err := s.filter(s.db.Model(&models.TrafficUsageHourly{}), query).
+		Select("agent_id, instance, scope, name, SUM(uplink) AS uplink, SUM(downlink) AS downlink").
+		Group("agent_id, instance, scope, name").
+		Order("SUM(uplink) + SUM(downlink) DESC").
+		Scan(&totals).Error; err != nil {
+		return nil, fmt.Errorf("查询流量合计失败: %w", err)
+	}
+	return totals, nil
+}
+
+// filter 应用查询条件，时间区间按小时汇总的整点过滤
+func (s *usageService) filter(db *gorm.DB, query UsageQuery) *gorm.DB {
+	if query.AgentID != "" {
+		db = db.Where("agent_id = ?", query.AgentID)
+	}
+	if query.Instance != "" {
+		db = db.Where("instance = ?", query.Instance)
+	}
+	if query.Scope != "" {
+		db = db.Where("scope = ?", query.Scope)
+	}
+	if query.Name != "" {
+		db = db.Where("name = ?", query.Name)
+	}
+	if !query.From.IsZero() {
+		db = db.Where("hour >= ?", query.From.Truncate(time.Hour))
+	}
+	if !query.To.IsZero() {
+		db = db.Where("hour < ?", query.To)
+	}
+	return db
+}
@@ -0,0 +1,850 @@
+package converter
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/xbox/sing-box-manager/internal/agent/singbox"
+	"gopkg.in/yaml.v3"
+)
+
+// clashConfig Clash / Clash-Meta配置中可转换的部分
+type clashConfig struct {
+	Port           int                    `yaml:"port"`
+	SocksPort      int                    `yaml:"socks-port"`
+	MixedPort      int                    `yaml:"mixed-port"`
+	RedirPort      int                    `yaml:"redir-port"`
+	TProxyPort     int                    `yaml:"tproxy-port"`
+	AllowLan       bool                   `yaml:"allow-lan"`
+	BindAddress    string                 `yaml:"bind-address"`
+	Mode           string                 `yaml:"mode"`
+	DNS            *clashDNS              `yaml:"dns"`
+	Tun            *clashTun              `yaml:"tun"`
+	Proxies        []yaml.Node            `yaml:"proxies"`
+	ProxyGroups    []clashProxyGroup      `yaml:"proxy-groups"`
+	Rules          []string               `yaml:"rules"`
+	ProxyProviders map[string]interface{} `yaml:"proxy-providers"`
+	RuleProviders  map[string]interface{} `yaml:"rule-providers"`
+	Listeners      []interface{}          `yaml:"listeners"`
+	Hosts          map[string]interface{} `yaml:"hosts"`
+}
+
+// clashDNS Clash DNS配置
+type clashDNS struct {
+	Enable            bool                   `yaml:"enable"`
+	IPv6              bool                   `yaml:"ipv6"`
+	EnhancedMode      string                 `yaml:"enhanced-mode"`
+	FakeIPRange       string                 `yaml:"fake-ip-range"`
+	FakeIPFilter      []string               `yaml:"fake-ip-filter"`
+	DefaultNameserver []string               `yaml:"default-nameserver"`
+	Nameserver        []string               `yaml:"nameserver"`
+	Fallback          []string               `yaml:"fallback"`
+	NameserverPolicy  map[string]interface{} `yaml:"nameserver-policy"`
+}
+
+// clashTun Clash TUN配置
+type clashTun struct {
+	Enable      bool   `yaml:"enable"`
+	Stack       string `yaml:"stack"`
+	AutoRoute   bool   `yaml:"auto-route"`
+	StrictRoute bool   `yaml:"strict-route"`
+}
+
+// clashProxyGroup Clash策略组
+type clashProxyGroup struct {
+	Name      string   `yaml:"name"`
+	Type      string   `yaml:"type"`
+	Proxies   []string `yaml:"proxies"`
+	Use       []string `yaml:"use"`
+	URL       string   `yaml:"url"`
+	Interval  int      `yaml:"interval"`
+	Tolerance int      `yaml:"tolerance"`
+}
+
+// clashProxy Clash节点
+type clashProxy struct {
+	Name              string            `yaml:"name"`
+	Type              string            `yaml:"type"`
+	Server            string            `yaml:"server"`
+	Port              int               `yaml:"port"`
+	Cipher            string            `yaml:"cipher"`
+	Password          string            `yaml:"password"`
+	Username          string            `yaml:"username"`
+	UUID              string            `yaml:"uuid"`
+	AlterID           int               `yaml:"alterId"`
+	Flow              string            `yaml:"flow"`
+	TLS               bool              `yaml:"tls"`
+	SNI               string            `yaml:"sni"`
+	ServerName        string            `yaml:"servername"`
+	SkipCertVerify    bool              `yaml:"skip-cert-verify"`
+	ALPN              []string          `yaml:"alpn"`
+	ClientFingerprint string            `yaml:"client-fingerprint"`
+	RealityOpts       *clashRealityOpts `yaml:"reality-opts"`
+	Network           string            `yaml:"network"`
+	WSOpts            *clashWSOpts      `yaml:"ws-opts"`
+	GRPCOpts          *clashGRPCOpts    `yaml:"grpc-opts"`
+	H2Opts            *clashH2Opts      `yaml:"h2-opts"`
+	HTTPOpts          *clashHTTPOpts    `yaml:"http-opts"`
+	Plugin            string            `yaml:"plugin"`
+	PluginOpts        map[string]string `yaml:"plugin-opts"`
+	UDPOverTCP        bool              `yaml:"udp-over-tcp"`
+	Smux              *clashSmux        `yaml:"smux"`
+	AuthStr           string            `yaml:"auth-str"`
+	Obfs              string            `yaml:"obfs"`
+	ObfsPassword      string            `yaml:"obfs-password"`
+	Up                string            `yaml:"up"`
+	Down              string            `yaml:"down"`
+	CongestionControl string            `yaml:"congestion-controller"`
+	UDPRelayMode      string            `yaml:"udp-relay-mode"`
+	PrivateKey        string            `yaml:"private-key"`
+	PublicKey         string            `yaml:"public-key"`
+	PreSharedKey      string            `yaml:"pre-shared-key"`
+	IP                string            `yaml:"ip"`
+	IPv6              string            `yaml:"ipv6"`
+	MTU               uint32            `yaml:"mtu"`
+	DialerProxy       string            `yaml:"dialer-proxy"`
+}
+
+type clashRealityOpts struct {
+	PublicKey string `yaml:"public-key"`
+	ShortID   string `yaml:"short-id"`
+}
+
+type clashWSOpts struct {
+	Path                string            `yaml:"path"`
+	Headers             map[string]string `yaml:"headers"`
+	MaxEarlyData        uint32            `yaml:"max-early-data"`
+	EarlyDataHeaderName string            `yaml:"early-data-header-name"`
+	V2rayHTTPUpgrade    bool              `yaml:"v2ray-http-upgrade"`
+}
+
+type clashGRPCOpts struct {
+	ServiceName string `yaml:"grpc-service-name"`
+}
+
+type clashH2Opts struct {
+	Host []string `yaml:"host"`
+	Path string   `yaml:"path"`
+}
+
+type clashHTTPOpts struct {
+	Method  string              `yaml:"method"`
+	Path    []string            `yaml:"path"`
+	Headers map[string][]string `yaml:"headers"`
+}
+
+type clashSmux struct {
+	Enabled        bool   `yaml:"enabled"`
+	Protocol       string `yaml:"protocol"`
+	MaxConnections int    `yaml:"max-connections"`
+	MinStreams     int    `yaml:"min-streams"`
+	MaxStreams     int    `yaml:"max-streams"`
+	Padding        bool   `yaml:"padding"`
+}
+
+// clashConverter Clash转换上下文
+type clashConverter struct {
+	report *Report
+	tags   tagSet
+	names  map[string]string // Clash节点或策略组名称 -> sing-box出站tag
+	used   map[string]bool   // 被引用的内置出站
+}
+
+// convertClash 转换Clash YAML
+func convertClash(data []byte, report *Report) (*singbox.Config, error) {
+	var src clashConfig
+	if err := yaml.Unmarshal(data, &src); err != nil {
+		return nil, fmt.Errorf("解析Clash配置失败: %w", err)
+	}
+
+	c := &clashConverter{
+		report: report,
+		tags:   tagSet{TagDirect: true, TagBlock: true, TagDNS: true},
+		names: map[string]string{
+			"DIRECT":      TagDirect,
+			"REJECT":      TagBlock,
+			"REJECT-DROP": TagBlock,
+		},
+		used: map[string]bool{TagDirect: true},
+	}
+
+	config := &singbox.Config{
+		Log:      &singbox.LogConfig{Level: "info", Timestamp: true},
+		Inbounds: c.inbounds(&src),
+	}
+
+	proxies := c.proxies(src.Proxies)
+	groups := c.groups(src.ProxyGroups)
+	config.Outbounds = append(config.Outbounds, groups...)
+	config.Outbounds = append(config.Outbounds, proxies...)
+
+	config.Route = c.route(&src)
+	if src.DNS != nil && src.DNS.Enable {
+		config.DNS = c.dns(src.DNS)
+	}
+	config.Outbounds = append(config.Outbounds, builtinOutbounds(c.used)...)
+
+	if len(src.ProxyProviders) > 0 {
+		report.addf("proxy-providers", "不支持代理集（%d 个），其中的节点未导入", len(src.ProxyProviders))
+	}
+	if len(src.RuleProviders) > 0 {
+		report.addf("rule-providers", "不支持规则集（%d 个），RULE-SET规则未导入", len(src.RuleProviders))
+	}
+	if len(src.Hosts) > 0 {
+		report.addf("hosts", "不支持hosts（%d 条）", len(src.Hosts))
+	}
+	return config, nil
+}
+
+// inbounds 转换本地监听端口和TUN
+func (c *clashConverter) inbounds(src *clashConfig) []singbox.Inbound {
+	listen := "127.0.0.1"
+	if src.AllowLan {
+		listen = "::"
+		if src.BindAddress != "" && src.BindAddress != "*" {
+			listen = src.BindAddress
+		}
+	}
+
+	var inbounds []singbox.Inbound
+	for _, item := range []struct {
+		port int
+		typ  string
+		tag  string
+	}{
+		{src.MixedPort, "mixed", "mixed-in"},
+		{src.Port, "http", "http-in"},
+		{src.SocksPort, "socks", "socks-in"},
+		{src.RedirPort, "redirect", "redirect-in"},
+		{src.TProxyPort, "tproxy", "tproxy-in"},
+	} {
+		if item.port <= 0 {
+			continue
+		}
+		inbounds = append(inbounds, singbox.Inbound{
+			Type:       item.typ,
+			Tag:        item.tag,
+			Listen:     listen,
+			ListenPort: uint16(item.port),
+			Sniff:      true,
+		})
+	}
+
+	if src.Tun != nil && src.Tun.Enable {
+		tun := singbox.Inbound{Type: "tun", Tag: "tun-in", Sniff: true}
+		setRaw(&tun.Extra, "address", []string{"172.19.0.1/30"})
+		setRaw(&tun.Extra, "auto_route", src.Tun.AutoRoute)
+		if src.Tun.StrictRoute {
+			setRaw(&tun.Extra, "strict_route", true)
+		}
+		if src.Tun.Stack != "" {
+			setRaw(&tun.Extra, "stack", strings.ToLower(src.Tun.Stack))
+		}
+		inbounds = append(inbounds, tun)
+		c.report.addf("tun", "TUN地址使用默认的172.19.0.1/30，请按目标sing-box版本检查字段")
+	}
+
+	for i := range src.Listeners {
+		c.report.addf(fmt.Sprintf("listeners[%d]", i), "不支持listeners，未导入")
+	}
+	return inbounds
+}
+
+// proxies 转换节点，节点名称映射为出站tag
+func (c *clashConverter) proxies(nodes []yaml.Node) []singbox.Outbound {
+	var outbounds []singbox.Outbound
+	for i := range nodes {
+		path := fmt.Sprintf("proxies[%d]", i)
+		var proxy clashProxy
+		if err := nodes[i].Decode(&proxy); err != nil {
+			c.report.addf(path, "解析节点失败: %v", err)
+			continue
+		}
+		if proxy.Name != "" {
+			path = fmt.Sprintf("%s(%s)", path, proxy.Name)
+		}
+
+		outbound, err := c.proxy(&proxy, path)
+		if err != nil {
+			c.report.addf(path, "%v", err)
+			continue
+		}
+		outbound.Tag = c.tags.unique(proxy.Name)
+		c.names[proxy.Name] = outbound.Tag
+		outbounds = append(outbounds, *outbound)
+	}
+
+	// dialer-proxy引用的节点在全部节点映射完成后解析
+	for i := range outbounds {
+		if detour := outbounds[i].Detour; detour != "" {
+			if tag, ok := c.names[detour]; ok {
+				outbounds[i].Detour = tag
+			} else {
+				c.report.addf("proxies", "节点 %s 的dialer-proxy %s 不存在，已忽略", outbounds[i].Tag, detour)
+				outbounds[i].Detour = ""
+			}
+		}
+	}
+	return outbounds
+}
+
+// proxy 转换单个节点
+func (c *clashConverter) proxy(p *clashProxy, path string) (*singbox.Outbound, error) {
+	if p.Name == "" {
+		return nil, fmt.Errorf("节点缺少name")
+	}
+	out := &singbox.Outbound{
+		Server:     p.Server,
+		ServerPort: uint16(p.Port),
+		UDPOverTCP: p.UDPOverTCP,
+	}
+	out.Detour = p.DialerProxy
+
+	tls := p.TLS
+	switch p.Type {
+	case "ss":
+		out.Type = "shadowsocks"
+		out.Method = p.Cipher
+		out.Password = p.Password
+		if p.Plugin != "" {
+			plugin, opts, err := clashPlugin(p.Plugin, p.PluginOpts)
+			if err != nil {
+				return nil, err
+			}
+			out.Plugin = plugin
+			out.PluginOpts = opts
+		}
+	case "vmess":
+		out.Type = "vmess"
+		out.UUID = p.UUID
+		out.AlterId = p.AlterID
+		out.Security = p.Cipher
+	case "vless":
+		out.Type = "vless"
+		out.UUID = p.UUID
+		out.Flow = p.Flow
+	case "trojan":
+		out.Type = "trojan"
+		out.Password = p.Password
+		tls = true
+	case "socks5":
+		out.Type = "socks"
+		out.Version = "5"
+		out.Username = p.Username
+		out.Password = p.Password
+	case "http":
+		out.Type = "http"
+		out.Username = p.Username
+		out.Password = p.Password
+	case "hysteria2":
+		out.Type = "hysteria2"
+		out.Password = p.Password
+		if p.Obfs != "" {
+			setRaw(&out.Extra, "obfs", map[string]string{"type": p.Obfs, "password": p.ObfsPassword})
+		}
+		if mbps, ok := parseMbps(p.Up); ok {
+			setRaw(&out.Extra, "up_mbps", mbps)
+		}
+		if mbps, ok := parseMbps(p.Down); ok {
+			setRaw(&out.Extra, "down_mbps", mbps)
+		}
+		tls = true
+	case "hysteria":
+		out.Type = "hysteria"
+		out.AuthStr = p.AuthStr
+		out.Obfs = p.Obfs
+		out.Up = p.Up
+		out.Down = p.Down
+		tls = true
+	case "tuic":
+		out.Type = "tuic"
+		out.UUID = p.UUID
+		out.Password = p.Password
+		if p.CongestionControl != "" {
+			setRaw(&out.Extra, "congestion_control", p.CongestionControl)
+		}
+		if p.UDPRelayMode != "" {
+			setRaw(&out.Extra, "udp_relay_mode", p.UDPRelayMode)
+		}
+		tls = true
+	case "wireguard":
+		out.Type = "wireguard"
+		out.PrivateKey = p.PrivateKey
+		out.PeerPublicKey = p.PublicKey
+		out.PreSharedKey = p.PreSharedKey
+		out.MTU = p.MTU
+		if cidr, ok := normalizeCIDR(p.IP); ok {
+			out.LocalAddress = append(out.LocalAddress, cidr)
+		}
+		if cidr, ok := normalizeCIDR(p.IPv6); ok {
+			out.LocalAddress = append(out.LocalAddress, cidr)
+		}
+	default:
+		return nil, fmt.Errorf("不支持的节点类型: %s", p.Type)
+	}
+
+	if tls {
+		out.TLS = clashTLS(p)
+	}
+	transport, err := clashTransport(p)
+	if err != nil {
+		return nil, err
+	}
+	out.Transport = transport
+
+	if p.Smux != nil && p.Smux.Enabled {
+		out.Multiplex = &singbox.MultiplexConfig{
+			Enabled:        true,
+			Protocol:       p.Smux.Protocol,
+			MaxConnections: p.Smux.MaxConnections,
+			MinStreams:     p.Smux.MinStreams,
+			MaxStreams:     p.Smux.MaxStreams,
+			Padding:        p.Smux.Padding,
+		}
+	}
+	return out, nil
+}
+
+// clashTLS 转换节点TLS
+func clashTLS(p *clashProxy) *singbox.OutboundTLS {
+	tls := &singbox.OutboundTLS{
+		Enabled:    true,
+		ServerName: p.SNI,
+		Insecure:   p.SkipCertVerify,
+		ALPN:       p.ALPN,
+	}
+	if tls.ServerName == "" {
+		tls.ServerName = p.ServerName
+	}
+	fingerprint := p.ClientFingerprint
+	if p.RealityOpts != nil {
+		tls.Reality = &singbox.RealityConfig{
+			Enabled:   true,
+			PublicKey: p.RealityOpts.PublicKey,
+			ShortID:   p.RealityOpts.ShortID,
+		}
+		// Reality要求uTLS
+		if fingerprint == "" {
+			fingerprint = "chrome"
+		}
+	}
+	if fingerprint != "" {
+		tls.UTLS = &singbox.UTLSConfig{Enabled: true, Fingerprint: fingerprint}
+	}
+	return tls
+}
+
+// clashTransport 转换节点传输层
+func clashTransport(p *clashProxy) (*singbox.Transport, error) {
+	switch p.Network {
+	case "", "tcp":
+		return nil, nil
+	case "ws":
+		t := &singbox.Transport{Type: "ws"}
+		if opts := p.WSOpts; opts != nil {
+			t.Path = opts.Path
+			t.Headers = opts.Headers
+			t.MaxEarlyData = opts.MaxEarlyData
+			t.EarlyDataHeaderName = opts.EarlyDataHeaderName
+			if opts.V2rayHTTPUpgrade {
+				t = &singbox.Transport{Type: "httpupgrade", Path: opts.Path, Headers: opts.Headers}
+				if host := opts.Headers["Host"]; host != "" {
+					t.Host = []string{host}
+				}
+			}
+		}
+		return t, nil
+	case "grpc":
+		t := &singbox.Transport{Type: "grpc"}
+		if p.GRPCOpts != nil {
+			t.ServiceName = p.GRPCOpts.ServiceName
+		}
+		return t, nil
+	case "h2":
+		t := &singbox.Transport{Type: "http"}
+		if p.H2Opts != nil {
+			t.Host = p.H2Opts.Host
+			t.Path = p.H2Opts.Path
+		}
+		return t, nil
+	case "http":
+		t := &singbox.Transport{Type: "http"}
+		if opts := p.HTTPOpts; opts != nil {
+			t.Method = opts.Method
+			if len(opts.Path) > 0 {
+				t.Path = opts.Path[0]
+			}
+			t.Host = opts.Headers["Host"]
+		}
+		return t, nil
+	}
+	return nil, fmt.Errorf("不支持的传输类型: %s", p.Network)
+}
+
+// clashPlugin 转换Shadowsocks插件，sing-box支持obfs-local和v2ray-plugin
+func clashPlugin(plugin string, opts map[string]string) (string, string, error) {
+	var pairs []string
+	switch plugin {
+	case "obfs":
+		if mode := opts["mode"]; mode != "" {
+			pairs = append(pairs, "obfs="+mode)
+		}
+		if host := opts["host"]; host != "" {
+			pairs = append(pairs, "obfs-host="+host)
+		}
+		return "obfs-local", strings.Join(pairs, ";"), nil
+	case "v2ray-plugin":
+		if mode := opts["mode"]; mode != "" {
+			pairs = append(pairs, "mode="+mode)
+		}
+		if opts["tls"] == "true" {
+			pairs = append(pairs, "tls")
+		}
+		if host := opts["host"]; host != "" {
+			pairs = append(pairs, "host="+host)
+		}
+		if path := opts["path"]; path != "" {
+			pairs = append(pairs, "path="+path)
+		}
+		return "v2ray-plugin", strings.Join(pairs, ";"), nil
+	}
+	return "", "", fmt.Errorf("不支持的Shadowsocks插件: %s", plugin)
+}
+
+// parseMbps 解析 "100"、"100 Mbps" 形式的带宽
+func parseMbps(value string) (int, bool) {
+	value = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(strings.ToLower(value)), "mbps"))
+	if value == "" {
+		return 0, false
+	}
+	mbps, err := strconv.Atoi(value)
+	return mbps, err == nil && mbps > 0
+}
+
+// groups 转换策略组，先登记全部组名以支持组之间互相引用
+func (c *clashConverter) groups(groups []clashProxyGroup) []singbox.Outbound {
+	tags := make([]string, len(groups))
+	for i, group := range groups {
+		tags[i] = c.tags.unique(group.Name)
+		c.names[group.Name] = tags[i]
+	}
+
+	var outbounds []singbox.Outbound
+	for i, group := range groups {
+		path := fmt.Sprintf("proxy-groups[%d](%s)", i, group.Name)
+		out := singbox.Outbound{Tag: tags[i]}
+
+		switch group.Type {
+		case "select":
+			out.Type = "selector"
+		case "url-test", "fallback", "load-balance":
+			out.Type = "urltest"
+			if group.Type != "url-test" {
+				c.report.addf(path, "%s策略组近似转换为urltest", group.Type)
+			}
+			if group.URL != "" {
+				out.URL = group.URL
+			}
+			if group.Interval > 0 {
+				out.Interval = fmt.Sprintf("%ds", group.Interval)
+			}
+			if group.Tolerance > 0 {
+				out.Tolerance = uint16(group.Tolerance)
+			}
+		default:
+			c.report.addf(path, "不支持的策略组类型 %s，已转换为selector", group.Type)
+			out.Type = "selector"
+		}
+		if len(group.Use) > 0 {
+			c.report.addf(path, "不支持代理集引用（use），已忽略")
+		}
+
+		var members []string
+		for _, name := range group.Proxies {
+			tag, ok := c.names[name]
+			if !ok {
+				c.report.addf(path, "成员 %s 不存在，已忽略", name)
+				continue
+			}
+			c.used[tag] = true
+			members = append(members, tag)
+		}
+		if len(members) == 0 {
+			c.report.addf(path, "策略组没有可用成员，使用direct")
+			members = []string{TagDirect}
+		}
+		out.Outbounds = members
+		outbounds = append(outbounds, out)
+	}
+	return outbounds
+}
+
+// route 转换规则，MATCH规则作为final
+func (c *clashConverter) route(src *clashConfig) *singbox.RouteConfig {
+	route := &singbox.RouteConfig{AutoDetectInterface: true, Final: TagDirect}
+	if len(src.ProxyGroups) > 0 {
+		route.Final = c.names[src.ProxyGroups[0].Name]
+	}
+
+	for i, line := range src.Rules {
+		path := fmt.Sprintf("rules[%d]", i)
+		rule, final, err := c.rule(line)
+		if err != nil {
+			c.report.addf(path, "%s: %v", line, err)
+			continue
+		}
+		if final != "" {
+			route.Final = final
+			continue
+		}
+		route.Rules = appendRule(route.Rules, *rule)
+	}
+
+	switch strings.ToLower(src.Mode) {
+	case "direct":
+		route.Rules = nil
+		route.Final = TagDirect
+		c.report.addf("mode", "direct模式下规则未导入，全部流量直连")
+	case "global":
+		c.report.addf("mode", "global模式已按rule模式转换")
+	}
+	return route
+}
+
+// rule 转换单条规则，MATCH规则返回目标出站
+func (c *clashConverter) rule(line string) (*singbox.RouteRule, string, error) {
+	parts := strings.Split(line, ",")
+	for i := range parts {
+		parts[i] = strings.TrimSpace(parts[i])
+	}
+	kind := strings.ToUpper(parts[0])
+
+	switch kind {
+	case "MATCH", "FINAL":
+		if len(parts) < 2 {
+			return nil, "", fmt.Errorf("规则格式无效")
+		}
+		target, err := c.target(parts[1])
+		return nil, target, err
+	case "AND", "OR", "NOT":
+		return nil, "", fmt.Errorf("不支持逻辑规则")
+	}
+	if len(parts) < 3 {
+		return nil, "", fmt.Errorf("规则格式无效")
+	}
+
+	value := parts[1]
+	target, err := c.target(parts[2])
+	if err != nil {
+		return nil, "", err
+	}
+	rule := &singbox.RouteRule{Outbound: target}
+
+	switch kind {
+	case "DOMAIN":
+		rule.Domain = []string{value}
+	case "DOMAIN-SUFFIX":
+		rule.DomainSuffix = []string{value}
+	case "DOMAIN-KEYWORD":
+		rule.DomainKeyword = []string{value}
+	case "DOMAIN-REGEX":
+		rule.DomainRegex = []string{value}
+	case "GEOSITE":
+		rule.Geosite = []string{strings.ToLower(value)}
+	case "GEOIP":
+		if strings.EqualFold(value, "lan") || strings.EqualFold(value, "private") {
+			rule.IPIsPrivate = true
+		} else {
+			rule.GeoIP = []string{strings.ToLower(value)}
+		}
+	case "IP-CIDR", "IP-CIDR6":
+		cidr, ok := normalizeCIDR(value)
+		if !ok {
+			return nil, "", fmt.Errorf("CIDR无效: %s", value)
+		}
+		rule.IP = []string{cidr}
+	case "SRC-IP-CIDR":
+		cidr, ok := normalizeCIDR(value)
+		if !ok {
+			return nil, "", fmt.Errorf("CIDR无效: %s", value)
+		}
+		rule.SourceIP = []string{cidr}
+	case "DST-PORT":
+		if err := addPorts(value, &rule.Port, &rule.PortRange); err != nil {
+			return nil, "", err
+		}
+	case "SRC-PORT":
+		if err := addPorts(value, &rule.SourcePort, &rule.SourcePortRange); err != nil {
+			return nil, "", err
+		}
+	case "PROCESS-NAME":
+		rule.ProcessName = []string{value}
+	case "PROCESS-PATH":
+		rule.ProcessPath = []string{value}
+	case "NETWORK":
+		rule.Network = []string{strings.ToLower(value)}
+	default:
+		return nil, "", fmt.Errorf("不支持的规则类型 %s", kind)
+	}
+	return rule, "", nil
+}
+
+// target 将规则目标映射为出站tag
+func (c *clashConverter) target(name string) (string, error) {
+	tag, ok := c.names[name]
+	if !ok {
+		return "", fmt.Errorf("目标 %s 不存在", name)
+	}
+	c.used[tag] = true
+	return tag, nil
+}
+
+// dns 转换DNS：default-nameserver用于解析其他DNS服务器的域名，nameserver[0]为默认服务器
+func (c *clashConverter) dns(src *clashDNS) *singbox.DNSConfig {
+	dns := &singbox.DNSConfig{}
+	if !src.IPv6 {
+		dns.Strategy = "ipv4_only"
+	}
+
+	resolver := ""
+	if len(src.DefaultNameserver) > 0 {
+		resolver = "dns-bootstrap"
+		dns.Servers = append(dns.Servers, singbox.DNSServer{
+			Tag:     resolver,
+			Address: src.DefaultNameserver[0],
+			Detour:  TagDirect,
+		})
+	}
+	addServer := func(tag, address string) {
+		server := singbox.DNSServer{Tag: tag, Address: address, AddressResolver: resolver}
+		if resolver == "" || !dnsAddressNeedsResolver(address) {
+			server.AddressResolver = ""
+		}
+		dns.Servers = append(dns.Servers, server)
+	}
+
+	for i, address := range src.Nameserver {
+		addServer(fmt.Sprintf("dns-%d", i), address)
+	}
+	if len(src.Nameserver) > 0 {
+		dns.Final = "dns-0"
+	} else if resolver != "" {
+		dns.Final = resolver
+	}
+	if len(src.Fallback) > 0 {
+		c.report.addf("dns.fallback", "sing-box没有fallback机制，fallback服务器未导入")
+	}
+
+	// nameserver-policy按键排序以保证输出稳定
+	keys := make([]string, 0, len(src.NameserverPolicy))
+	for key := range src.NameserverPolicy {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for i, key := range keys {
+		path := fmt.Sprintf("dns.nameserver-policy[%s]", key)
+		address := ""
+		switch value := src.NameserverPolicy[key].(type) {
+		case string:
+			address = value
+		case []interface{}:
+			if len(value) > 0 {
+				address = fmt.Sprint(value[0])
+			}
+			if len(value) > 1 {
+				c.report.addf(path, "只使用第一个服务器")
+			}
+		}
+		if address == "" {
+			c.report.addf(path, "服务器无效，已忽略")
+			continue
+		}
+		rule := singbox.DNSRule{Server: fmt.Sprintf("dns-policy-%d", i)}
+		for _, pattern := range strings.Split(key, ",") {
+			if !addDNSPattern(&rule, strings.TrimSpace(pattern)) {
+				c.report.addf(path, "不支持的匹配: %s", pattern)
+			}
+		}
+		if len(rule.Domain)+len(rule.DomainSuffix)+len(rule.DomainRegex)+len(rule.Geosite) == 0 {
+			continue
+		}
+		addServer(rule.Server, address)
+		dns.Rules = append(dns.Rules, rule)
+	}
+
+	if strings.EqualFold(src.EnhancedMode, "fake-ip") {
+		fakeRange := src.FakeIPRange
+		if fakeRange == "" {
+			fakeRange = "198.18.0.1/16"
+		}
+		dns.FakeIP = &singbox.FakeIPConfig{Enabled: true, Inet4Range: fakeRange}
+		dns.Servers = append(dns.Servers, singbox.DNSServer{Tag: "dns-fakeip", Address: "fakeip"})
+
+		// fake-ip-filter中的域名使用真实解析
+		if len(src.FakeIPFilter) > 0 && dns.Final != "" {
+			filter := singbox.DNSRule{Server: dns.Final}
+			for _, pattern := range src.FakeIPFilter {
+				if !addDNSPattern(&filter, pattern) {
+					c.report.addf("dns.fake-ip-filter", "不支持的匹配: %s", pattern)
+				}
+			}
+			dns.Rules = append(dns.Rules, filter)
+		}
+		dns.Rules = append(dns.Rules, singbox.DNSRule{QueryType: []string{"A", "AAAA"}, Server: "dns-fakeip"})
+	}
+	return dns
+}
+
+// addDNSPattern 转换Clash域名通配：+.example.com 匹配自身及子域名，*.example.com 匹配一级子域名
+func addDNSPattern(rule *singbox.DNSRule, pattern string) bool {
+	switch {
+	case pattern == "":
+		return false
+	case strings.HasPrefix(pattern, "geosite:"):
+		rule.Geosite = append(rule.Geosite, strings.TrimPrefix(pattern, "geosite:"))
+	case strings.HasPrefix(pattern, "+."):
+		rule.DomainSuffix = append(rule.DomainSuffix, strings.TrimPrefix(pattern, "+."))
+	case strings.HasPrefix(pattern, "*."):
+		suffix := strings.ReplaceAll(strings.TrimPrefix(pattern, "*."), ".", `\.`)
+		rule.DomainRegex = append(rule.DomainRegex, `^[^.]+\.`+suffix+`$`)
+	case strings.Contains(pattern, "*"):
+		return false
+	default:
+		rule.Domain = append(rule.Domain, pattern)
+	}
+	return true
+}
+
+// dnsAddressNeedsResolver 判断DNS服务器地址是否为域名，需要先解析
+func dnsAddressNeedsResolver(address string) bool {
+	host := address
+	if i := strings.Index(host, "://"); i >= 0 {
+		host = host[i+3:]
+	}
+	if i := strings.IndexAny(host, "/"); i >= 0 {
+		host = host[:i]
+	}
+	if h, _, err := splitHostPortLoose(host); err == nil {
+		host = h
+	}
+	_, isIP := normalizeCIDR(host)
+	return host != "" && !isIP && host != "local" && host != "fakeip" && host != "dhcp"
+}
+
+// splitHostPortLoose 拆分可能不带端口的host:port
+func splitHostPortLoose(value string) (string, string, error) {
+	if strings.HasPrefix(value, "[") {
+		end := strings.Index(value, "]")
+		if end < 0 {
+			return "", "", fmt.Errorf("地址无效: %s", value)
+		}
+		return value[1:end], strings.TrimPrefix(value[end+1:], ":"), nil
+	}
+	if strings.Count(value, ":") == 1 {
+		host, port, _ := strings.Cut(value, ":")
+		return host, port, nil
+	}
+	return value, "", nil
+}
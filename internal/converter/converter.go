@@ -0,0 +1,215 @@
+// Package converter 将Clash、V2Ray和Xray配置转换为sing-box配置
+package converter
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/netip"
+	"strconv"
+	"strings"
+
+	"github.com/xbox/sing-box-manager/internal/agent/singbox"
+)
+
+// 支持的源配置格式
+const (
+	FormatClash = "clash" // Clash / Clash-Meta YAML
+	FormatV2Ray = "v2ray" // V2Ray JSON
+	FormatXray  = "xray"  // Xray JSON，在V2Ray基础上支持Reality和flow
+)
+
+// 内置出站tag
+const (
+	TagDirect = "direct"
+	TagBlock  = "block"
+	TagDNS    = "dns-out"
+)
+
+// Issue 未能转换或近似转换的配置项
+type Issue struct {
+	Path    string `json:"path"` // 源配置中的位置，如 proxies[3]、routing.rules[5]
+	Message string `json:"message"`
+}
+
+// Report 转换报告
+type Report struct {
+	Format     string  `json:"format"`
+	Inbounds   int     `json:"inbounds"`
+	Outbounds  int     `json:"outbounds"`
+	Rules      int     `json:"rules"` // 合并后的路由规则数
+	DNSServers int     `json:"dns_servers"`
+	Issues     []Issue `json:"issues,omitempty"`
+}
+
+// addf 记录一个转换问题
+func (r *Report) addf(path, format string, args ...interface{}) {
+	r.Issues = append(r.Issues, Issue{Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+// String 返回便于阅读的报告
+func (r *Report) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "格式: %s, 入站: %d, 出站: %d, 路由规则: %d, DNS服务器: %d\n",
+		r.Format, r.Inbounds, r.Outbounds, r.Rules, r.DNSServers)
+	if len(r.Issues) == 0 {
+		b.WriteString("全部配置项均已转换\n")
+		return b.String()
+	}
+	fmt.Fprintf(&b, "%d 项未能完整转换:\n", len(r.Issues))
+	for _, issue := range r.Issues {
+		fmt.Fprintf(&b, "  %s: %s\n", issue.Path, issue.Message)
+	}
+	return b.String()
+}
+
+// Convert 按格式转换配置，format为空时自动识别
+func Convert(format string, data []byte) (*singbox.Config, *Report, error) {
+	if format == "" {
+		format = DetectFormat(data)
+	}
+
+	var (
+		config *singbox.Config
+		report = &Report{Format: format}
+		err    error
+	)
+	switch format {
+	case FormatClash:
+		config, err = convertClash(data, report)
+	case FormatV2Ray, FormatXray:
+		config, err = convertV2Ray(data, report)
+	default:
+		return nil, nil, fmt.Errorf("不支持的配置格式: %s", format)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	report.Inbounds = len(config.Inbounds)
+	report.Outbounds = len(config.Outbounds)
+	if config.Route != nil {
+		report.Rules = len(config.Route.Rules)
+	}
+	if config.DNS != nil {
+		report.DNSServers = len(config.DNS.Servers)
+	}
+	return config, report, nil
+}
+
+// DetectFormat 按内容识别格式：JSON对象视为Xray（兼容V2Ray），其余视为Clash YAML
+func DetectFormat(data []byte) string {
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")) {
+		return FormatXray
+	}
+	return FormatClash
+}
+
+// tagSet 保证出站tag唯一
+type tagSet map[string]bool
+
+// unique 返回未被占用的tag，重复时追加序号
+func (s tagSet) unique(tag string) string {
+	candidate := tag
+	for i := 2; s[candidate]; i++ {
+		candidate = fmt.Sprintf("%s-%d", tag, i)
+	}
+	s[candidate] = true
+	return candidate
+}
+
+// setRaw 将值编码为JSON写入未建模字段
+func setRaw(extra *singbox.RawFields, key string, value interface{}) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return
+	}
+	extra.Set(key, data)
+}
+
+// normalizeCIDR 将单个IP转换为主机前缀，无效时返回false
+func normalizeCIDR(value string) (string, bool) {
+	value = strings.TrimSpace(value)
+	if prefix, err := netip.ParsePrefix(value); err == nil {
+		return prefix.String(), true
+	}
+	if addr, err := netip.ParseAddr(value); err == nil {
+		return netip.PrefixFrom(addr, addr.BitLen()).String(), true
+	}
+	return "", false
+}
+
+// addPorts 解析 "53,443,1000-2000" 形式的端口列表，写入端口和端口范围
+func addPorts(value string, ports, ranges *[]string) error {
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		if start, end, ok := strings.Cut(item, "-"); ok {
+			if _, err := strconv.ParseUint(start, 10, 16); err != nil {
+				return fmt.Errorf("端口范围无效: %s", item)
+			}
+			if _, err := strconv.ParseUint(end, 10, 16); err != nil {
+				return fmt.Errorf("端口范围无效: %s", item)
+			}
+			*ranges = append(*ranges, start+":"+end)
+			continue
+		}
+		if _, err := strconv.ParseUint(item, 10, 16); err != nil {
+			return fmt.Errorf("端口无效: %s", item)
+		}
+		*ports = append(*ports, item)
+	}
+	return nil
+}
+
+// isAddressRule 判断规则是否只包含目标地址类条件，这类条件在sing-box中为“或”关系，可安全合并
+func isAddressRule(rule *singbox.RouteRule) bool {
+	plain := singbox.RouteRule{
+		Domain:        rule.Domain,
+		DomainSuffix:  rule.DomainSuffix,
+		DomainKeyword: rule.DomainKeyword,
+		DomainRegex:   rule.DomainRegex,
+		Geosite:       rule.Geosite,
+		GeoIP:         rule.GeoIP,
+		IP:            rule.IP,
+		Outbound:      rule.Outbound,
+	}
+	a, errA := json.Marshal(&plain)
+	b, errB := json.Marshal(rule)
+	return errA == nil && errB == nil && bytes.Equal(a, b)
+}
+
+// appendRule 追加路由规则，与上一条同出站的地址类规则合并
+func appendRule(rules []singbox.RouteRule, rule singbox.RouteRule) []singbox.RouteRule {
+	if n := len(rules); n > 0 {
+		last := &rules[n-1]
+		if last.Outbound == rule.Outbound && isAddressRule(last) && isAddressRule(&rule) {
+			last.Domain = append(last.Domain, rule.Domain...)
+			last.DomainSuffix = append(last.DomainSuffix, rule.DomainSuffix...)
+			last.DomainKeyword = append(last.DomainKeyword, rule.DomainKeyword...)
+			last.DomainRegex = append(last.DomainRegex, rule.DomainRegex...)
+			last.Geosite = append(last.Geosite, rule.Geosite...)
+			last.GeoIP = append(last.GeoIP, rule.GeoIP...)
+			last.IP = append(last.IP, rule.IP...)
+			return rules
+		}
+	}
+	return append(rules, rule)
+}
+
+// builtinOutbounds 返回被引用的内置出站
+func builtinOutbounds(used map[string]bool) []singbox.Outbound {
+	var outbounds []singbox.Outbound
+	for _, builtin := range []struct{ tag, typ string }{
+		{TagDirect, "direct"},
+		{TagBlock, "block"},
+		{TagDNS, "dns"},
+	} {
+		if used[builtin.tag] {
+			outbounds = append(outbounds, singbox.Outbound{Type: builtin.typ, Tag: builtin.tag})
+		}
+	}
+	return outbounds
+}
@@ -0,0 +1,308 @@
+package converter
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/xbox/sing-box-manager/internal/agent/singbox"
+)
+
+const clashSample = `
+mixed-port: 7890
+allow-lan: true
+mode: rule
+dns:
+  enable: true
+  nameserver: [223.5.5.5, "https://dns.alidns.com/dns-query"]
+  fallback: ["tls://8.8.8.8"]
+  enhanced-mode: fake-ip
+  fake-ip-range: 198.18.0.1/16
+proxies:
+  - {name: ss1, type: ss, server: 1.2.3.4, port: 8388, cipher: aes-128-gcm, password: pw}
+  - {name: vm1, type: vmess, server: v.example.com, port: 443, uuid: bf000d23-0752-40b4-affe-68f7707a9661, alterId: 0, cipher: auto, tls: true, network: ws, ws-opts: {path: /ws, headers: {Host: v.example.com}}}
+  - {name: tr1, type: trojan, server: t.example.com, port: 443, password: pw, sni: t.example.com}
+  - {name: sn1, type: snell, server: s.example.com, port: 443, psk: x}
+proxy-groups:
+  - {name: PROXY, type: select, proxies: [auto, ss1, vm1, DIRECT]}
+  - {name: auto, type: url-test, proxies: [ss1, vm1, tr1], url: "http://www.gstatic.com/generate_204", interval: 300}
+rules:
+  - DOMAIN-SUFFIX,google.com,PROXY
+  - DOMAIN,ads.example.com,REJECT
+  - IP-CIDR,10.0.0.0/8,DIRECT,no-resolve
+  - GEOIP,CN,DIRECT
+  - AND,((DOMAIN,a.com),(NETWORK,UDP)),REJECT
+  - MATCH,PROXY
+`
+
+const xraySample = `{
+  "inbounds": [
+    {"tag": "vless-in", "port": 443, "protocol": "vless",
+     "settings": {"clients": [{"id": "bf000d23-0752-40b4-affe-68f7707a9661", "email": "alice", "flow": "xtls-rprx-vision"}], "decryption": "none", "fallbacks": [{"dest": 80}]},
+     "streamSettings": {"network": "tcp", "security": "reality", "realitySettings": {"dest": "www.microsoft.com:443", "serverNames": ["www.microsoft.com"], "privateKey": "priv", "shortIds": ["ab"]}},
+     "sniffing": {"enabled": true, "destOverride": ["http", "tls"]}},
+    {"port": "1000-2000", "protocol": "socks"}
+  ],
+  "outbounds": [
+    {"tag": "proxy-a", "protocol": "vmess", "settings": {"vnext": [{"address": "a.example.com", "port": 443, "users": [{"id": "bf000d23-0752-40b4-affe-68f7707a9661", "security": "auto"}]}]},
+     "streamSettings": {"network": "ws", "security": "tls", "tlsSettings": {"serverName": "a.example.com"}, "wsSettings": {"path": "/ws"}}},
+    {"tag": "proxy-b", "protocol": "trojan", "settings": {"servers": [{"address": "b.example.com", "port": 443, "password": "pw"}]}, "mux": {"enabled": true}},
+    {"tag": "direct", "protocol": "freedom"},
+    {"tag": "block", "protocol": "blackhole"},
+    {"tag": "wg", "protocol": "wireguard", "settings": {"address": "x"}}
+  ],
+  "routing": {
+    "domainStrategy": "IPIfNonMatch",
+    "balancers": [{"tag": "lb", "selector": ["proxy-"]}],
+    "rules": [
+      {"type": "field", "domain": ["geosite:category-ads-all"], "outboundTag": "block"},
+      {"type": "field", "domain": ["domain:cn", "full:www.baidu.com"], "outboundTag": "direct"},
+      {"type": "field", "ip": ["geoip:cn", "geoip:private"], "outboundTag": "direct"},
+      {"type": "field", "port": "53,1000-2000", "network": "udp", "outboundTag": "direct"},
+      {"type": "field", "domain": ["ext:custom.dat:foo"], "outboundTag": "direct"},
+      {"type": "field", "network": "tcp,udp", "balancerTag": "lb"},
+      {"type": "field", "ip": ["1.1.1.1"], "outboundTag": "missing"}
+    ]
+  },
+  "dns": {"servers": ["https+local://1.1.1.1/dns-query", {"address": "223.5.5.5", "port": 53, "domains": ["geosite:cn"]}], "hosts": {"a.com": "1.2.3.4"}},
+  "stats": {}
+}`
+
+// outboundTags 返回出站的 type/tag 列表
+func outboundTags(outbounds []singbox.Outbound) []string {
+	var tags []string
+	for _, out := range outbounds {
+		tags = append(tags, out.Type+"/"+out.Tag)
+	}
+	return tags
+}
+
+// issuePaths 返回转换问题的位置列表
+func issuePaths(issues []Issue) []string {
+	var paths []string
+	for _, issue := range issues {
+		paths = append(paths, issue.Path)
+	}
+	return paths
+}
+
+func TestConvert(t *testing.T) {
+	tests := []struct {
+		name          string
+		format        string
+		data          string
+		wantFormat    string
+		wantOutbounds []string
+		wantFinal     string
+		wantRules     []singbox.RouteRule
+		wantDNS       []string
+		wantIssues    []string
+	}{
+		{
+			name:          "Clash",
+			data:          clashSample,
+			wantFormat:    FormatClash,
+			wantOutbounds: []string{"selector/PROXY", "urltest/auto", "shadowsocks/ss1", "vmess/vm1", "trojan/tr1", "direct/direct", "block/block"},
+			wantFinal:     "PROXY",
+			wantRules: []singbox.RouteRule{
+				{DomainSuffix: []string{"google.com"}, Outbound: "PROXY"},
+				{Domain: []string{"ads.example.com"}, Outbound: TagBlock},
+				// 相邻的同出站地址规则合并
+				{IP: []string{"10.0.0.0/8"}, GeoIP: []string{"cn"}, Outbound: TagDirect},
+			},
+			wantDNS:    []string{"dns-0", "dns-1", "dns-fakeip"},
+			wantIssues: []string{"proxies[3](sn1)", "rules[4]", "dns.fallback"},
+		},
+		{
+			name:          "Xray",
+			data:          xraySample,
+			wantFormat:    FormatXray,
+			wantOutbounds: []string{"urltest/lb", "vmess/proxy-a", "trojan/proxy-b", "direct/direct", "block/block"},
+			wantFinal:     "proxy-a",
+			wantRules: []singbox.RouteRule{
+				{Geosite: []string{"category-ads-all"}, Outbound: TagBlock},
+				{Domain: []string{"www.baidu.com"}, DomainSuffix: []string{"cn"}, Outbound: TagDirect},
+				{GeoIP: []string{"cn"}, IPIsPrivate: true, Outbound: TagDirect},
+				{Network: []string{"udp"}, Port: []string{"53"}, PortRange: []string{"1000:2000"}, Outbound: TagDirect},
+			},
+			wantDNS: []string{"dns-0", "dns-1"},
+			wantIssues: []string{
+				"inbounds[0]", "inbounds[1]", "outbounds[1](proxy-b)", "outbounds[4](wg)",
+				"routing.balancers[0](lb)", "routing.rules[4]", "routing.rules[5]", "routing.rules[6]",
+				"routing.domainStrategy", "dns.hosts", "stats",
+			},
+		},
+		{
+			name:          "指定V2Ray格式",
+			format:        FormatV2Ray,
+			data:          `{"outbounds": [{"protocol": "freedom"}]}`,
+			wantFormat:    FormatV2Ray,
+			wantOutbounds: []string{"direct/direct"},
+			wantFinal:     TagDirect,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config, report, err := Convert(tt.format, []byte(tt.data))
+			if err != nil {
+				t.Fatalf("Convert() error = %v", err)
+			}
+			if report.Format != tt.wantFormat {
+				t.Errorf("Format = %s, want %s", report.Format, tt.wantFormat)
+			}
+			if got := outboundTags(config.Outbounds); !reflect.DeepEqual(got, tt.wantOutbounds) {
+				t.Errorf("出站 = %v, want %v", got, tt.wantOutbounds)
+			}
+			if config.Route.Final != tt.wantFinal {
+				t.Errorf("route.final = %s, want %s", config.Route.Final, tt.wantFinal)
+			}
+			if !reflect.DeepEqual(config.Route.Rules, tt.wantRules) {
+				t.Errorf("路由规则 = %+v, want %+v", config.Route.Rules, tt.wantRules)
+			}
+			var dnsTags []string
+			if config.DNS != nil {
+				for _, server := range config.DNS.Servers {
+					dnsTags = append(dnsTags, server.Tag)
+				}
+			}
+			if !reflect.DeepEqual(dnsTags, tt.wantDNS) {
+				t.Errorf("DNS服务器 = %v, want %v", dnsTags, tt.wantDNS)
+			}
+			if got := issuePaths(report.Issues); !reflect.DeepEqual(got, tt.wantIssues) {
+				t.Errorf("转换问题 = %v, want %v", got, tt.wantIssues)
+			}
+			if report.Outbounds != len(config.Outbounds) || report.Rules != len(config.Route.Rules) || report.DNSServers != len(dnsTags) {
+				t.Errorf("报告计数不一致: %+v", report)
+			}
+			// 转换结果应能通过下发前的语义校验
+			if errs := singbox.Validate(config); len(errs) > 0 {
+				t.Errorf("Validate() = %v", errs)
+			}
+		})
+	}
+}
+
+func TestConvertDetails(t *testing.T) {
+	config, _, err := Convert(FormatClash, []byte(clashSample))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Clash的DIRECT映射为内置direct出站
+	if got := config.Outbounds[0].Outbounds; !reflect.DeepEqual(got, []string{"auto", "ss1", "vm1", TagDirect}) {
+		t.Errorf("PROXY成员 = %v", got)
+	}
+	if vm := config.Outbounds[3]; vm.TLS == nil || !vm.TLS.Enabled || vm.Transport == nil || vm.Transport.Type != "ws" || vm.Transport.Path != "/ws" {
+		t.Errorf("vmess出站 = %+v", vm)
+	}
+	if fakeip := config.DNS.FakeIP; fakeip == nil || !fakeip.Enabled || fakeip.Inet4Range != "198.18.0.1/16" {
+		t.Errorf("fakeip = %+v", fakeip)
+	}
+	if in := config.Inbounds; len(in) != 1 || in[0].Type != "mixed" || in[0].ListenPort != 7890 || in[0].Listen != "::" {
+		t.Errorf("入站 = %+v", in)
+	}
+
+	config, _, err = Convert(FormatXray, []byte(xraySample))
+	if err != nil {
+		t.Fatal(err)
+	}
+	in := config.Inbounds[0]
+	if len(in.Users) != 1 || in.Users[0].Name != "alice" || in.Users[0].Flow != "xtls-rprx-vision" {
+		t.Errorf("入站用户 = %+v", in.Users)
+	}
+	if in.TLS == nil || in.TLS.Reality == nil || in.TLS.Reality.PrivateKey != "priv" || in.TLS.Reality.Handshake.Server != "www.microsoft.com" {
+		t.Errorf("Reality = %+v", in.TLS)
+	}
+	if got := config.DNS.Servers[0].Address; got != "https://1.1.1.1/dns-query" {
+		t.Errorf("DNS地址 = %s", got)
+	}
+}
+
+func TestConvertErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		format string
+		data   string
+	}{
+		{"不支持的格式", "surge", "[General]"},
+		{"Clash YAML无效", FormatClash, "proxies: [\n"},
+		{"Xray JSON无效", FormatXray, `{"outbounds": `},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if config, report, err := Convert(tt.format, []byte(tt.data)); err == nil {
+				t.Errorf("Convert() = %+v, %+v, want error", config, report)
+			}
+		})
+	}
+}
+
+func TestDetectFormat(t *testing.T) {
+	tests := []struct {
+		data string
+		want string
+	}{
+		{`{"outbounds": []}`, FormatXray},
+		{"\n  {\"inbounds\": []}", FormatXray},
+		{"proxies: []", FormatClash},
+		{"", FormatClash},
+	}
+	for _, tt := range tests {
+		if got := DetectFormat([]byte(tt.data)); got != tt.want {
+			t.Errorf("DetectFormat(%q) = %s, want %s", tt.data, got, tt.want)
+		}
+	}
+}
+
+func TestTagSetUnique(t *testing.T) {
+	s := tagSet{TagDirect: true}
+	var got []string
+	for _, tag := range []string{"a", "a", "direct", "a", "a-2"} {
+		got = append(got, s.unique(tag))
+	}
+	want := []string{"a", "a-2", "direct-2", "a-3", "a-2-2"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("unique() = %v, want %v", got, want)
+	}
+}
+
+func TestAddPorts(t *testing.T) {
+	tests := []struct {
+		value      string
+		wantPorts  []string
+		wantRanges []string
+		wantErr    bool
+	}{
+		{"443", []string{"443"}, nil, false},
+		{"53, 443,1000-2000", []string{"53", "443"}, []string{"1000:2000"}, false},
+		{"70000", nil, nil, true},
+		{"1-x", nil, nil, true},
+	}
+	for _, tt := range tests {
+		var ports, ranges []string
+		err := addPorts(tt.value, &ports, &ranges)
+		if (err != nil) != tt.wantErr {
+			t.Fatalf("addPorts(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+		}
+		if !tt.wantErr && (!reflect.DeepEqual(ports, tt.wantPorts) || !reflect.DeepEqual(ranges, tt.wantRanges)) {
+			t.Errorf("addPorts(%q) = %v, %v, want %v, %v", tt.value, ports, ranges, tt.wantPorts, tt.wantRanges)
+		}
+	}
+}
+
+func TestNormalizeCIDR(t *testing.T) {
+	tests := []struct {
+		value  string
+		want   string
+		wantOK bool
+	}{
+		{"10.0.0.0/8", "10.0.0.0/8", true},
+		{"1.1.1.1", "1.1.1.1/32", true},
+		{"2001:db8::1", "2001:db8::1/128", true},
+		{" 192.168.0.0/16 ", "192.168.0.0/16", true},
+		{"example.com", "", false},
+	}
+	for _, tt := range tests {
+		if got, ok := normalizeCIDR(tt.value); got != tt.want || ok != tt.wantOK {
+			t.Errorf("normalizeCIDR(%q) = %q, %v, want %q, %v", tt.value, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
@@ -0,0 +1,1027 @@
+package converter
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/xbox/sing-box-manager/internal/agent/singbox"
+)
+
+// v2rayConfig V2Ray / Xray配置中可转换的部分
+type v2rayConfig struct {
+	Log       json.RawMessage `json:"log"`
+	DNS       *v2rayDNS       `json:"dns"`
+	Routing   *v2rayRouting   `json:"routing"`
+	Inbounds  []v2rayInbound  `json:"inbounds"`
+	Outbounds []v2rayOutbound `json:"outbounds"`
+	FakeDNS   json.RawMessage `json:"fakedns"`
+	API       json.RawMessage `json:"api"`
+	Stats     json.RawMessage `json:"stats"`
+	Policy    json.RawMessage `json:"policy"`
+	Reverse   json.RawMessage `json:"reverse"`
+}
+
+// v2rayDNS V2Ray DNS配置，servers元素为字符串或对象
+type v2rayDNS struct {
+	Servers       []json.RawMessage `json:"servers"`
+	Hosts         map[string]any    `json:"hosts"`
+	QueryStrategy string            `json:"queryStrategy"`
+	ClientIP      string            `json:"clientIp"`
+	Tag           string            `json:"tag"`
+}
+
+// v2rayDNSServer 对象形式的DNS服务器
+type v2rayDNSServer struct {
+	Address      string   `json:"address"`
+	Port         int      `json:"port"`
+	Domains      []string `json:"domains"`
+	ExpectIPs    []string `json:"expectIPs"`
+	SkipFallback bool     `json:"skipFallback"`
+}
+
+// v2rayRouting 路由配置
+type v2rayRouting struct {
+	DomainStrategy string          `json:"domainStrategy"`
+	Rules          []v2rayRule     `json:"rules"`
+	Balancers      []v2rayBalancer `json:"balancers"`
+}
+
+// v2rayRule 路由规则，port可以是数字或字符串
+type v2rayRule struct {
+	Type        string          `json:"type"`
+	Domain      []string        `json:"domain"`
+	Domains     []string        `json:"domains"`
+	IP          []string        `json:"ip"`
+	Port        json.RawMessage `json:"port"`
+	SourcePort  json.RawMessage `json:"sourcePort"`
+	Network     string          `json:"network"`
+	Source      []string        `json:"source"`
+	User        []string        `json:"user"`
+	InboundTag  []string        `json:"inboundTag"`
+	Protocol    []string        `json:"protocol"`
+	Attrs       json.RawMessage `json:"attrs"`
+	OutboundTag string          `json:"outboundTag"`
+	BalancerTag string          `json:"balancerTag"`
+}
+
+// v2rayBalancer 负载均衡器
+type v2rayBalancer struct {
+	Tag      string          `json:"tag"`
+	Selector []string        `json:"selector"`
+	Strategy json.RawMessage `json:"strategy"`
+}
+
+// v2rayInbound 入站
+type v2rayInbound struct {
+	Tag            string          `json:"tag"`
+	Protocol       string          `json:"protocol"`
+	Listen         string          `json:"listen"`
+	Port           json.RawMessage `json:"port"`
+	Settings       json.RawMessage `json:"settings"`
+	StreamSettings *v2rayStream    `json:"streamSettings"`
+	Sniffing       *v2raySniffing  `json:"sniffing"`
+}
+
+// v2raySniffing 流量探测
+type v2raySniffing struct {
+	Enabled      bool `json:"enabled"`
+	RouteOnly    bool `json:"routeOnly"`
+	MetadataOnly bool `json:"metadataOnly"`
+}
+
+// v2rayOutbound 出站
+type v2rayOutbound struct {
+	Tag            string          `json:"tag"`
+	Protocol       string          `json:"protocol"`
+	Settings       json.RawMessage `json:"settings"`
+	StreamSettings *v2rayStream    `json:"streamSettings"`
+	ProxySettings  *struct {
+		Tag string `json:"tag"`
+	} `json:"proxySettings"`
+	Mux *struct {
+		Enabled bool `json:"enabled"`
+	} `json:"mux"`
+}
+
+// v2rayStream 传输层与安全层配置
+type v2rayStream struct {
+	Network             string            `json:"network"`
+	Security            string            `json:"security"`
+	TLSSettings         *v2rayTLS         `json:"tlsSettings"`
+	RealitySettings     *v2rayReality     `json:"realitySettings"`
+	WSSettings          *v2rayWS          `json:"wsSettings"`
+	GRPCSettings        *v2rayGRPC        `json:"grpcSettings"`
+	HTTPSettings        *v2rayHTTP        `json:"httpSettings"`
+	HTTPUpgradeSettings *v2rayHTTPUpgrade `json:"httpupgradeSettings"`
+	TCPSettings         json.RawMessage   `json:"tcpSettings"`
+	Sockopt             json.RawMessage   `json:"sockopt"`
+}
+
+type v2rayTLS struct {
+	ServerName    string   `json:"serverName"`
+	AllowInsecure bool     `json:"allowInsecure"`
+	ALPN          []string `json:"alpn"`
+	Fingerprint   string   `json:"fingerprint"`
+	Certificates  []struct {
+		CertificateFile string   `json:"certificateFile"`
+		KeyFile         string   `json:"keyFile"`
+		Certificate     []string `json:"certificate"`
+		Key             []string `json:"key"`
+	} `json:"certificates"`
+}
+
+type v2rayReality struct {
+	// 服务端
+	Dest        json.RawMessage `json:"dest"`
+	Target      json.RawMessage `json:"target"`
+	ServerNames []string        `json:"serverNames"`
+	PrivateKey  string          `json:"privateKey"`
+	ShortIDs    []string        `json:"shortIds"`
+	// 客户端
+	ServerName  string `json:"serverName"`
+	Fingerprint string `json:"fingerprint"`
+	PublicKey   string `json:"publicKey"`
+	ShortID     string `json:"shortId"`
+}
+
+type v2rayWS struct {
+	Path    string            `json:"path"`
+	Host    string            `json:"host"`
+	Headers map[string]string `json:"headers"`
+}
+
+type v2rayGRPC struct {
+	ServiceName string `json:"serviceName"`
+}
+
+type v2rayHTTP struct {
+	Host   []string `json:"host"`
+	Path   string   `json:"path"`
+	Method string   `json:"method"`
+}
+
+type v2rayHTTPUpgrade struct {
+	Path string `json:"path"`
+	Host string `json:"host"`
+}
+
+// v2rayUser 入站或出站用户
+type v2rayUser struct {
+	ID       string `json:"id"`
+	AlterID  int    `json:"alterId"`
+	Email    string `json:"email"`
+	Flow     string `json:"flow"`
+	Password string `json:"password"`
+	Method   string `json:"method"`
+	Security string `json:"security"`
+	User     string `json:"user"`
+	Pass     string `json:"pass"`
+}
+
+// v2rayInboundSettings 入站settings中可转换的字段
+type v2rayInboundSettings struct {
+	Clients    []v2rayUser `json:"clients"`
+	Accounts   []v2rayUser `json:"accounts"`
+	Method     string      `json:"method"`
+	Password   string      `json:"password"`
+	Network    string      `json:"network"`
+	Address    string      `json:"address"`
+	Port       int         `json:"port"`
+	Auth       string      `json:"auth"`
+	UDP        bool        `json:"udp"`
+	Decryption string      `json:"decryption"`
+	Fallbacks  []any       `json:"fallbacks"`
+}
+
+// v2rayServer 出站settings中的服务器
+type v2rayServer struct {
+	Address  string      `json:"address"`
+	Port     int         `json:"port"`
+	Users    []v2rayUser `json:"users"`
+	Password string      `json:"password"`
+	Method   string      `json:"method"`
+	Email    string      `json:"email"`
+	Flow     string      `json:"flow"`
+}
+
+// v2rayOutboundSettings 出站settings中可转换的字段
+type v2rayOutboundSettings struct {
+	Vnext   []v2rayServer `json:"vnext"`
+	Servers []v2rayServer `json:"servers"`
+	// Xray的vless/vmess扁平写法
+	Address string `json:"address"`
+	Port    int    `json:"port"`
+	ID      string `json:"id"`
+	Flow    string `json:"flow"`
+}
+
+// v2rayConverter V2Ray转换上下文
+type v2rayConverter struct {
+	report  *Report
+	tags    tagSet
+	targets map[string]string // V2Ray出站tag或balancer tag -> sing-box出站tag
+	used    map[string]bool
+}
+
+// convertV2Ray 转换V2Ray / Xray JSON
+func convertV2Ray(data []byte, report *Report) (*singbox.Config, error) {
+	var src v2rayConfig
+	if err := json.Unmarshal(data, &src); err != nil {
+		return nil, fmt.Errorf("解析V2Ray配置失败: %w", err)
+	}
+
+	c := &v2rayConverter{
+		report:  report,
+		tags:    tagSet{TagDirect: true, TagBlock: true, TagDNS: true},
+		targets: make(map[string]string),
+		used:    make(map[string]bool),
+	}
+
+	config := &singbox.Config{
+		Log: &singbox.LogConfig{Level: "info", Timestamp: true},
+	}
+	for i := range src.Inbounds {
+		path := fmt.Sprintf("inbounds[%d]", i)
+		inbound, err := c.inbound(&src.Inbounds[i], path)
+		if err != nil {
+			c.report.addf(path, "%v", err)
+			continue
+		}
+		config.Inbounds = append(config.Inbounds, *inbound)
+	}
+
+	outbounds := c.outbounds(src.Outbounds)
+	var balancers []singbox.Outbound
+	if src.Routing != nil {
+		balancers = c.balancers(src.Routing.Balancers)
+	}
+	config.Outbounds = append(balancers, outbounds...)
+
+	config.Route = &singbox.RouteConfig{AutoDetectInterface: true, Final: TagDirect}
+	if len(src.Outbounds) > 0 {
+		if tag, ok := c.targets[src.Outbounds[0].Tag]; ok {
+			config.Route.Final = tag
+		}
+	}
+	c.used[config.Route.Final] = true
+	if src.Routing != nil {
+		config.Route.Rules = c.rules(src.Routing.Rules)
+		if src.Routing.DomainStrategy != "" && src.Routing.DomainStrategy != "AsIs" {
+			c.report.addf("routing.domainStrategy", "sing-box按规则匹配时按需解析域名，%s未导入", src.Routing.DomainStrategy)
+		}
+	}
+	if src.DNS != nil {
+		config.DNS = c.dns(src.DNS, len(src.FakeDNS) > 0)
+	}
+	config.Outbounds = append(config.Outbounds, builtinOutbounds(c.used)...)
+
+	for _, item := range []struct {
+		name string
+		raw  json.RawMessage
+	}{
+		{"api", src.API},
+		{"stats", src.Stats},
+		{"policy", src.Policy},
+		{"reverse", src.Reverse},
+	} {
+		if len(item.raw) > 0 && string(item.raw) != "null" {
+			c.report.addf(item.name, "不支持%s配置，未导入", item.name)
+		}
+	}
+	return config, nil
+}
+
+// inbound 转换单个入站
+func (c *v2rayConverter) inbound(src *v2rayInbound, path string) (*singbox.Inbound, error) {
+	port, err := parseV2RayPort(src.Port)
+	if err != nil {
+		return nil, err
+	}
+	var settings v2rayInboundSettings
+	if len(src.Settings) > 0 {
+		if err := json.Unmarshal(src.Settings, &settings); err != nil {
+			return nil, fmt.Errorf("解析settings失败: %v", err)
+		}
+	}
+
+	listen := src.Listen
+	if listen == "" {
+		listen = "0.0.0.0"
+	}
+	in := &singbox.Inbound{
+		Tag:        src.Tag,
+		Listen:     listen,
+		ListenPort: port,
+	}
+	if in.Tag == "" {
+		in.Tag = fmt.Sprintf("%s-in-%d", src.Protocol, port)
+	}
+	if src.Sniffing != nil && src.Sniffing.Enabled {
+		in.Sniff = true
+		in.SniffOverride = !src.Sniffing.RouteOnly
+	}
+
+	userName := func(i int, email string) string {
+		if email != "" {
+			return email
+		}
+		return fmt.Sprintf("user-%d", i+1)
+	}
+
+	switch src.Protocol {
+	case "vmess":
+		in.Type = "vmess"
+		for i, client := range settings.Clients {
+			in.Users = append(in.Users, singbox.InboundUser{
+				Name:    userName(i, client.Email),
+				UUID:    client.ID,
+				AlterID: client.AlterID,
+			})
+		}
+	case "vless":
+		in.Type = "vless"
+		for i, client := range settings.Clients {
+			in.Users = append(in.Users, singbox.InboundUser{
+				Name: userName(i, client.Email),
+				UUID: client.ID,
+				Flow: client.Flow,
+			})
+		}
+	case "trojan":
+		in.Type = "trojan"
+		for i, client := range settings.Clients {
+			in.Users = append(in.Users, singbox.InboundUser{
+				Name:     userName(i, client.Email),
+				Password: client.Password,
+			})
+		}
+	case "shadowsocks":
+		in.Type = "shadowsocks"
+		in.Method = settings.Method
+		in.Password = settings.Password
+		for i, client := range settings.Clients {
+			if client.Method != "" && client.Method != settings.Method {
+				c.report.addf(path, "用户 %s 的加密方式与入站不同，sing-box多用户入站只支持统一的加密方式", userName(i, client.Email))
+			}
+			if in.Method == "" {
+				in.Method = client.Method
+			}
+			in.Users = append(in.Users, singbox.InboundUser{
+				Name:     userName(i, client.Email),
+				Password: client.Password,
+			})
+		}
+		// 单用户的旧式写法
+		if in.Password == "" && len(in.Users) == 1 {
+			in.Password = in.Users[0].Password
+			in.Users = nil
+		}
+	case "socks", "http":
+		in.Type = src.Protocol
+		if src.Protocol == "socks" && settings.Auth == "noauth" {
+			break
+		}
+		for _, account := range settings.Accounts {
+			in.Users = append(in.Users, singbox.InboundUser{Username: account.User, Password: account.Pass})
+		}
+	case "dokodemo-door":
+		in.Type = "direct"
+		if settings.Address != "" {
+			setRaw(&in.Extra, "override_address", settings.Address)
+		}
+		if settings.Port > 0 {
+			setRaw(&in.Extra, "override_port", settings.Port)
+		}
+		if settings.Network != "" && !strings.Contains(settings.Network, ",") {
+			in.Network = settings.Network
+		}
+	default:
+		return nil, fmt.Errorf("不支持的入站协议: %s", src.Protocol)
+	}
+	if len(settings.Fallbacks) > 0 {
+		c.report.addf(path, "sing-box不支持fallbacks，已忽略")
+	}
+
+	if src.StreamSettings != nil {
+		tls, err := c.inboundSecurity(src.StreamSettings)
+		if err != nil {
+			return nil, err
+		}
+		in.TLS = tls
+		transport, err := v2rayTransport(src.StreamSettings)
+		if err != nil {
+			return nil, err
+		}
+		in.Transport = transport
+	}
+	return in, nil
+}
+
+// inboundSecurity 转换入站TLS或Reality
+func (c *v2rayConverter) inboundSecurity(stream *v2rayStream) (*singbox.InboundTLS, error) {
+	switch stream.Security {
+	case "", "none":
+		return nil, nil
+	case "tls":
+		tls := &singbox.InboundTLS{Enabled: true}
+		if s := stream.TLSSettings; s != nil {
+			tls.ServerName = s.ServerName
+			tls.ALPN = s.ALPN
+			if len(s.Certificates) > 0 {
+				cert := s.Certificates[0]
+				tls.CertificatePath = cert.CertificateFile
+				tls.KeyPath = cert.KeyFile
+				tls.Certificate = cert.Certificate
+				tls.Key = cert.Key
+			}
+		}
+		return tls, nil
+	case "reality":
+		s := stream.RealitySettings
+		if s == nil {
+			return nil, fmt.Errorf("缺少realitySettings")
+		}
+		dest := s.Target
+		if len(dest) == 0 {
+			dest = s.Dest
+		}
+		server, port, err := parseRealityDest(dest)
+		if err != nil {
+			return nil, err
+		}
+		tls := &singbox.InboundTLS{
+			Enabled: true,
+			Reality: &singbox.InboundReality{
+				Enabled:    true,
+				Handshake:  &singbox.RealityHandshake{Server: server, ServerPort: port},
+				PrivateKey: s.PrivateKey,
+				ShortID:    s.ShortIDs,
+			},
+		}
+		if len(s.ServerNames) > 0 {
+			tls.ServerName = s.ServerNames[0]
+		}
+		return tls, nil
+	}
+	return nil, fmt.Errorf("不支持的安全类型: %s", stream.Security)
+}
+
+// parseRealityDest 解析Reality握手目标，支持 "example.com:443" 和纯端口
+func parseRealityDest(raw json.RawMessage) (string, uint16, error) {
+	var value string
+	if err := json.Unmarshal(raw, &value); err != nil {
+		var port uint16
+		if err := json.Unmarshal(raw, &port); err != nil {
+			return "", 0, fmt.Errorf("Reality dest无效: %s", raw)
+		}
+		return "127.0.0.1", port, nil
+	}
+	host, portStr, err := splitHostPortLoose(value)
+	if err != nil {
+		return "", 0, err
+	}
+	if portStr == "" {
+		if port, err := strconv.ParseUint(host, 10, 16); err == nil {
+			return "127.0.0.1", uint16(port), nil
+		}
+		return host, 443, nil
+	}
+	port, err := strconv.ParseUint(portStr, 10, 16)
+	if err != nil {
+		return "", 0, fmt.Errorf("Reality dest端口无效: %s", value)
+	}
+	return host, uint16(port), nil
+}
+
+// outboundSecurity 转换出站TLS或Reality
+func outboundSecurity(stream *v2rayStream) (*singbox.OutboundTLS, error) {
+	switch stream.Security {
+	case "", "none":
+		return nil, nil
+	case "tls":
+		tls := &singbox.OutboundTLS{Enabled: true}
+		if s := stream.TLSSettings; s != nil {
+			tls.ServerName = s.ServerName
+			tls.Insecure = s.AllowInsecure
+			tls.ALPN = s.ALPN
+			if s.Fingerprint != "" {
+				tls.UTLS = &singbox.UTLSConfig{Enabled: true, Fingerprint: s.Fingerprint}
+			}
+		}
+		return tls, nil
+	case "reality":
+		s := stream.RealitySettings
+		if s == nil {
+			return nil, fmt.Errorf("缺少realitySettings")
+		}
+		fingerprint := s.Fingerprint
+		if fingerprint == "" {
+			fingerprint = "chrome"
+		}
+		return &singbox.OutboundTLS{
+			Enabled:    true,
+			ServerName: s.ServerName,
+			UTLS:       &singbox.UTLSConfig{Enabled: true, Fingerprint: fingerprint},
+			Reality: &singbox.RealityConfig{
+				Enabled:   true,
+				PublicKey: s.PublicKey,
+				ShortID:   s.ShortID,
+			},
+		}, nil
+	}
+	return nil, fmt.Errorf("不支持的安全类型: %s", stream.Security)
+}
+
+// v2rayTransport 转换传输层，tcp返回nil
+func v2rayTransport(stream *v2rayStream) (*singbox.Transport, error) {
+	switch stream.Network {
+	case "", "tcp", "raw":
+		return nil, nil
+	case "ws":
+		t := &singbox.Transport{Type: "ws"}
+		if s := stream.WSSettings; s != nil {
+			t.Path = s.Path
+			t.Headers = s.Headers
+			if s.Host != "" {
+				if t.Headers == nil {
+					t.Headers = make(map[string]string)
+				}
+				t.Headers["Host"] = s.Host
+			}
+		}
+		return t, nil
+	case "grpc", "gun":
+		t := &singbox.Transport{Type: "grpc"}
+		if s := stream.GRPCSettings; s != nil {
+			t.ServiceName = s.ServiceName
+		}
+		return t, nil
+	case "http", "h2":
+		t := &singbox.Transport{Type: "http"}
+		if s := stream.HTTPSettings; s != nil {
+			t.Host = s.Host
+			t.Path = s.Path
+			t.Method = s.Method
+		}
+		return t, nil
+	case "httpupgrade":
+		t := &singbox.Transport{Type: "httpupgrade"}
+		if s := stream.HTTPUpgradeSettings; s != nil {
+			t.Path = s.Path
+			if s.Host != "" {
+				t.Host = []string{s.Host}
+			}
+		}
+		return t, nil
+	}
+	return nil, fmt.Errorf("不支持的传输类型: %s", stream.Network)
+}
+
+// outbounds 转换出站，先登记全部tag以便解析proxySettings
+func (c *v2rayConverter) outbounds(list []v2rayOutbound) []singbox.Outbound {
+	var outbounds []singbox.Outbound
+	for i := range list {
+		src := &list[i]
+		path := fmt.Sprintf("outbounds[%d]", i)
+		if src.Tag != "" {
+			path = fmt.Sprintf("%s(%s)", path, src.Tag)
+		}
+
+		switch src.Protocol {
+		case "freedom":
+			c.mapOutbound(src.Tag, TagDirect)
+			continue
+		case "blackhole":
+			c.mapOutbound(src.Tag, TagBlock)
+			continue
+		case "dns":
+			c.mapOutbound(src.Tag, TagDNS)
+			continue
+		}
+
+		out, err := c.outbound(src)
+		if err != nil {
+			c.report.addf(path, "%v", err)
+			continue
+		}
+		name := src.Tag
+		if name == "" {
+			name = fmt.Sprintf("%s-%d", src.Protocol, i)
+		}
+		out.Tag = c.tags.unique(name)
+		c.mapOutbound(src.Tag, out.Tag)
+		if src.Mux != nil && src.Mux.Enabled {
+			c.report.addf(path, "V2Ray mux与sing-box多路复用不兼容，未启用")
+		}
+		if src.ProxySettings != nil {
+			out.Detour = src.ProxySettings.Tag
+		}
+		outbounds = append(outbounds, *out)
+	}
+
+	for i := range outbounds {
+		if detour := outbounds[i].Detour; detour != "" {
+			if tag, ok := c.targets[detour]; ok {
+				outbounds[i].Detour = tag
+				c.used[tag] = true
+			} else {
+				c.report.addf("outbounds", "出站 %s 的proxySettings %s 不存在，已忽略", outbounds[i].Tag, detour)
+				outbounds[i].Detour = ""
+			}
+		}
+	}
+	return outbounds
+}
+
+// mapOutbound 登记V2Ray出站tag对应的sing-box出站，首个空tag出站也可作为默认出站
+func (c *v2rayConverter) mapOutbound(src, tag string) {
+	if _, ok := c.targets[src]; !ok {
+		c.targets[src] = tag
+	}
+}
+
+// outbound 转换单个代理出站
+func (c *v2rayConverter) outbound(src *v2rayOutbound) (*singbox.Outbound, error) {
+	var settings v2rayOutboundSettings
+	if len(src.Settings) > 0 {
+		if err := json.Unmarshal(src.Settings, &settings); err != nil {
+			return nil, fmt.Errorf("解析settings失败: %v", err)
+		}
+	}
+
+	// vmess/vless使用vnext，其余使用servers；Xray也允许扁平写法
+	var server v2rayServer
+	var user v2rayUser
+	switch {
+	case len(settings.Vnext) > 0:
+		server = settings.Vnext[0]
+		if len(server.Users) > 0 {
+			user = server.Users[0]
+		}
+		if len(settings.Vnext) > 1 || len(server.Users) > 1 {
+			c.report.addf("outbounds."+src.Tag, "只转换第一个服务器和用户")
+		}
+	case len(settings.Servers) > 0:
+		server = settings.Servers[0]
+		if len(server.Users) > 0 {
+			user = server.Users[0]
+		}
+		if len(settings.Servers) > 1 {
+			c.report.addf("outbounds."+src.Tag, "只转换第一个服务器")
+		}
+	case settings.Address != "":
+		server = v2rayServer{Address: settings.Address, Port: settings.Port}
+		user = v2rayUser{ID: settings.ID, Flow: settings.Flow}
+	default:
+		return nil, fmt.Errorf("缺少服务器地址")
+	}
+
+	out := &singbox.Outbound{Server: server.Address, ServerPort: uint16(server.Port)}
+	switch src.Protocol {
+	case "vmess":
+		out.Type = "vmess"
+		out.UUID = user.ID
+		out.AlterId = user.AlterID
+		out.Security = user.Security
+	case "vless":
+		out.Type = "vless"
+		out.UUID = user.ID
+		out.Flow = user.Flow
+	case "trojan":
+		out.Type = "trojan"
+		out.Password = server.Password
+	case "shadowsocks":
+		out.Type = "shadowsocks"
+		out.Method = server.Method
+		out.Password = server.Password
+	case "socks", "http":
+		out.Type = src.Protocol
+		if src.Protocol == "socks" {
+			out.Version = "5"
+		}
+		out.Username = user.User
+		out.Password = user.Pass
+	default:
+		return nil, fmt.Errorf("不支持的出站协议: %s", src.Protocol)
+	}
+
+	if src.StreamSettings != nil {
+		tls, err := outboundSecurity(src.StreamSettings)
+		if err != nil {
+			return nil, err
+		}
+		out.TLS = tls
+		transport, err := v2rayTransport(src.StreamSettings)
+		if err != nil {
+			return nil, err
+		}
+		out.Transport = transport
+	}
+	return out, nil
+}
+
+// balancers 将负载均衡器近似转换为urltest，selector按出站tag前缀匹配
+func (c *v2rayConverter) balancers(list []v2rayBalancer) []singbox.Outbound {
+	var outbounds []singbox.Outbound
+	for i, balancer := range list {
+		path := fmt.Sprintf("routing.balancers[%d](%s)", i, balancer.Tag)
+		var members []string
+		for src, tag := range c.targets {
+			for _, prefix := range balancer.Selector {
+				if src != "" && strings.HasPrefix(src, prefix) {
+					members = append(members, tag)
+					break
+				}
+			}
+		}
+		if len(members) == 0 {
+			c.report.addf(path, "没有匹配的出站，已忽略")
+			continue
+		}
+		sort.Strings(members)
+
+		out := singbox.Outbound{Type: "urltest", Tag: c.tags.unique(balancer.Tag)}
+		out.Outbounds = members
+		for _, member := range members {
+			c.used[member] = true
+		}
+		c.targets[balancer.Tag] = out.Tag
+		c.report.addf(path, "负载均衡器近似转换为urltest")
+		outbounds = append(outbounds, out)
+	}
+	return outbounds
+}
+
+// rules 转换路由规则
+func (c *v2rayConverter) rules(list []v2rayRule) []singbox.RouteRule {
+	var rules []singbox.RouteRule
+	for i := range list {
+		path := fmt.Sprintf("routing.rules[%d]", i)
+		rule, err := c.rule(&list[i])
+		if err != nil {
+			c.report.addf(path, "%v", err)
+			continue
+		}
+		rules = appendRule(rules, *rule)
+	}
+	return rules
+}
+
+// rule 转换单条路由规则
+func (c *v2rayConverter) rule(src *v2rayRule) (*singbox.RouteRule, error) {
+	target := src.OutboundTag
+	if target == "" {
+		target = src.BalancerTag
+	}
+	if target == "" {
+		return nil, fmt.Errorf("规则缺少outboundTag")
+	}
+	tag, ok := c.targets[target]
+	if !ok {
+		return nil, fmt.Errorf("目标出站 %s 不存在", target)
+	}
+	if len(src.Attrs) > 0 {
+		return nil, fmt.Errorf("不支持attrs条件")
+	}
+
+	rule := &singbox.RouteRule{Outbound: tag}
+	domains := append(append([]string(nil), src.Domain...), src.Domains...)
+	for _, domain := range domains {
+		if err := addV2RayDomain(&rule.Domain, &rule.DomainSuffix, &rule.DomainKeyword, &rule.DomainRegex, &rule.Geosite, domain); err != nil {
+			return nil, err
+		}
+	}
+	for _, ip := range src.IP {
+		private, err := addV2RayIP(&rule.IP, &rule.GeoIP, ip)
+		if err != nil {
+			return nil, err
+		}
+		rule.IPIsPrivate = rule.IPIsPrivate || private
+	}
+	for _, ip := range src.Source {
+		private, err := addV2RayIP(&rule.SourceIP, &rule.SourceGeoIP, ip)
+		if err != nil {
+			return nil, err
+		}
+		rule.SourceIPIsPrivate = rule.SourceIPIsPrivate || private
+	}
+	if err := addV2RayPorts(src.Port, &rule.Port, &rule.PortRange); err != nil {
+		return nil, err
+	}
+	if err := addV2RayPorts(src.SourcePort, &rule.SourcePort, &rule.SourcePortRange); err != nil {
+		return nil, err
+	}
+	if src.Network != "" {
+		for _, network := range strings.Split(src.Network, ",") {
+			rule.Network = append(rule.Network, strings.TrimSpace(network))
+		}
+		// 同时匹配tcp和udp等同于不限制
+		if len(rule.Network) >= 2 {
+			rule.Network = nil
+		}
+	}
+	rule.Inbound = append(rule.Inbound, src.InboundTag...)
+	for _, protocol := range src.Protocol {
+		if protocol == "bittorrent" || protocol == "http" || protocol == "tls" || protocol == "quic" {
+			rule.Protocol = append(rule.Protocol, protocol)
+			continue
+		}
+		return nil, fmt.Errorf("不支持的协议条件 %s", protocol)
+	}
+	rule.AuthUser = append(rule.AuthUser, src.User...)
+
+	if isEmptyRule(rule) {
+		return nil, fmt.Errorf("规则没有可转换的条件")
+	}
+	c.used[tag] = true
+	return rule, nil
+}
+
+// isEmptyRule 判断规则是否没有任何匹配条件
+func isEmptyRule(rule *singbox.RouteRule) bool {
+	conditions := *rule
+	conditions.Outbound = ""
+	data, err := json.Marshal(&conditions)
+	return err == nil && string(data) == "{}"
+}
+
+// addV2RayDomain 转换V2Ray域名匹配：domain:子域名、full:完整、regexp:正则、keyword:或无前缀为关键字、geosite:
+func addV2RayDomain(domain, suffix, keyword, regex, geosite *[]string, value string) error {
+	switch {
+	case strings.HasPrefix(value, "domain:"):
+		*suffix = append(*suffix, strings.TrimPrefix(value, "domain:"))
+	case strings.HasPrefix(value, "full:"):
+		*domain = append(*domain, strings.TrimPrefix(value, "full:"))
+	case strings.HasPrefix(value, "regexp:"):
+		*regex = append(*regex, strings.TrimPrefix(value, "regexp:"))
+	case strings.HasPrefix(value, "keyword:"):
+		*keyword = append(*keyword, strings.TrimPrefix(value, "keyword:"))
+	case strings.HasPrefix(value, "geosite:"):
+		name := strings.TrimPrefix(value, "geosite:")
+		if strings.Contains(name, "@") {
+			return fmt.Errorf("不支持geosite属性过滤: %s", value)
+		}
+		*geosite = append(*geosite, name)
+	case strings.HasPrefix(value, "ext:"):
+		return fmt.Errorf("不支持外部域名文件: %s", value)
+	case strings.HasPrefix(value, "dotless:"):
+		return fmt.Errorf("不支持dotless匹配: %s", value)
+	default:
+		*keyword = append(*keyword, value)
+	}
+	return nil
+}
+
+// addV2RayIP 转换V2Ray IP匹配，geoip:private返回true
+func addV2RayIP(cidrs, geoip *[]string, value string) (bool, error) {
+	switch {
+	case value == "geoip:private":
+		return true, nil
+	case strings.HasPrefix(value, "geoip:!"):
+		return false, fmt.Errorf("不支持geoip取反: %s", value)
+	case strings.HasPrefix(value, "geoip:"):
+		*geoip = append(*geoip, strings.TrimPrefix(value, "geoip:"))
+		return false, nil
+	case strings.HasPrefix(value, "ext:"):
+		return false, fmt.Errorf("不支持外部IP文件: %s", value)
+	}
+	cidr, ok := normalizeCIDR(value)
+	if !ok {
+		return false, fmt.Errorf("CIDR无效: %s", value)
+	}
+	*cidrs = append(*cidrs, cidr)
+	return false, nil
+}
+
+// addV2RayPorts 转换数字或字符串形式的端口条件
+func addV2RayPorts(raw json.RawMessage, ports, ranges *[]string) error {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil
+	}
+	var number uint16
+	if err := json.Unmarshal(raw, &number); err == nil {
+		*ports = append(*ports, strconv.Itoa(int(number)))
+		return nil
+	}
+	var value string
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return fmt.Errorf("端口无效: %s", raw)
+	}
+	return addPorts(value, ports, ranges)
+}
+
+// parseV2RayPort 解析入站端口，不支持端口范围
+func parseV2RayPort(raw json.RawMessage) (uint16, error) {
+	var number uint16
+	if err := json.Unmarshal(raw, &number); err == nil {
+		return number, nil
+	}
+	var value string
+	if err := json.Unmarshal(raw, &value); err == nil {
+		if port, err := strconv.ParseUint(value, 10, 16); err == nil {
+			return uint16(port), nil
+		}
+		return 0, fmt.Errorf("不支持入站端口范围: %s", value)
+	}
+	return 0, fmt.Errorf("入站端口无效: %s", raw)
+}
+
+// dns 转换DNS服务器，带domains的服务器转换为DNS规则
+func (c *v2rayConverter) dns(src *v2rayDNS, fakeDNS bool) *singbox.DNSConfig {
+	dns := &singbox.DNSConfig{}
+	switch src.QueryStrategy {
+	case "UseIPv4":
+		dns.Strategy = "ipv4_only"
+	case "UseIPv6":
+		dns.Strategy = "ipv6_only"
+	}
+
+	for i, raw := range src.Servers {
+		path := fmt.Sprintf("dns.servers[%d]", i)
+		var server v2rayDNSServer
+		if err := json.Unmarshal(raw, &server.Address); err != nil {
+			if err := json.Unmarshal(raw, &server); err != nil {
+				c.report.addf(path, "解析DNS服务器失败: %v", err)
+				continue
+			}
+		}
+
+		address := v2rayDNSAddress(server.Address, server.Port)
+		tag := fmt.Sprintf("dns-%d", i)
+		if address == "fakeip" {
+			if !fakeDNS {
+				c.report.addf(path, "缺少fakedns配置，使用默认地址池")
+			}
+			dns.FakeIP = &singbox.FakeIPConfig{Enabled: true, Inet4Range: "198.18.0.0/15"}
+			tag = "dns-fakeip"
+		}
+		dns.Servers = append(dns.Servers, singbox.DNSServer{Tag: tag, Address: address})
+		if len(server.ExpectIPs) > 0 {
+			c.report.addf(path, "不支持expectIPs，已忽略")
+		}
+
+		if len(server.Domains) == 0 {
+			if dns.Final == "" && address != "fakeip" {
+				dns.Final = tag
+			}
+			continue
+		}
+		rule := singbox.DNSRule{Server: tag}
+		for _, domain := range server.Domains {
+			if err := addV2RayDomain(&rule.Domain, &rule.DomainSuffix, &rule.DomainKeyword, &rule.DomainRegex, &rule.Geosite, domain); err != nil {
+				c.report.addf(path, "%v", err)
+			}
+		}
+		if address == "fakeip" {
+			rule.QueryType = []string{"A", "AAAA"}
+		}
+		dns.Rules = append(dns.Rules, rule)
+	}
+
+	// 未限定域名的fakedns服务器对全部A/AAAA查询生效
+	if dns.FakeIP != nil && !hasServerRule(dns.Rules, "dns-fakeip") {
+		dns.Rules = append(dns.Rules, singbox.DNSRule{QueryType: []string{"A", "AAAA"}, Server: "dns-fakeip"})
+	}
+	if len(src.Hosts) > 0 {
+		c.report.addf("dns.hosts", "不支持hosts（%d 条）", len(src.Hosts))
+	}
+	if src.ClientIP != "" {
+		for i := range dns.Servers {
+			dns.Servers[i].ClientSubnet = src.ClientIP
+		}
+	}
+	return dns
+}
+
+// v2rayDNSAddress 将V2Ray DNS地址转换为sing-box格式
+func v2rayDNSAddress(address string, port int) string {
+	switch {
+	case address == "localhost":
+		return "local"
+	case address == "fakedns":
+		return "fakeip"
+	case strings.HasPrefix(address, "https+local://"):
+		return "https://" + strings.TrimPrefix(address, "https+local://")
+	case strings.HasPrefix(address, "quic+local://"):
+		return "quic://" + strings.TrimPrefix(address, "quic+local://")
+	case strings.HasPrefix(address, "tcp+local://"):
+		return "tcp://" + strings.TrimPrefix(address, "tcp+local://")
+	}
+	if port > 0 && port != 53 && !strings.Contains(address, "://") {
+		return fmt.Sprintf("udp://%s:%d", address, port)
+	}
+	return address
+}
+
+// hasServerRule 判断是否已有指向server的DNS规则
+func hasServerRule(rules []singbox.DNSRule, server string) bool {
+	for _, rule := range rules {
+		if rule.Server == server {
+			return true
+		}
+	}
+	return false
+}
@@ -87,6 +87,20 @@ func autoMigrate() error {
 		&models.Monitor{},
 		&models.SystemConfig{},
 		&models.OpLog{},
+		&models.TrafficUsage{},
+		&models.TrafficUsageHourly{},
+		&models.SingboxRollout{},
+		&models.SingboxRolloutTarget{},
+		&models.ConfigTemplate{},
+		&models.ConfigTemplateRevision{},
+		&models.AgentTemplateVars{},
+		&models.Subscription{},
+		&models.OutboundProbe{},
+		&models.Certificate{},
+		&models.CertificateAssignment{},
+		&models.RealityKey{},
+		&models.CredentialRotation{},
+		&models.CredentialRotationRun{},
 	)
 	
 	if err != nil {
@@ -219,6 +233,9 @@ func CleanupOldData() error {
 	if err := db.Where("timestamp < ?", monitoringCutoff).Delete(&models.Monitor{}).Error; err != nil {
 		return fmt.Errorf("清理过期监控数据失败: %w", err)
 	}
+	if err := db.Where("period_end < ?", monitoringCutoff).Delete(&models.OutboundProbe{}).Error; err != nil {
+		return fmt.Errorf("清理过期出站探测数据失败: %w", err)
+	}
 	
 	return nil
 }
\ No newline at end of file
@@ -43,11 +43,14 @@ type Agent struct {
 type Config struct {
 	ID            uint      `gorm:"primaryKey" json:"id"`
 	AgentID       string    `gorm:"not null;size:64;index:idx_agent_version,priority:1" json:"agent_id"`
+	Instance      string    `gorm:"not null;size:64;default:'default'" json:"instance"` // sing-box实例名称
 	ConfigContent string    `gorm:"not null;type:text" json:"config_content"`
 	ConfigVersion string    `gorm:"not null;size:32;index:idx_agent_version,priority:2" json:"config_version"`
 	Status        string    `gorm:"type:enum('pending','applied','failed');default:'pending';index" json:"status"`
 	ApplyTime     *time.Time `json:"apply_time"`
 	ErrorMessage  string    `gorm:"type:text" json:"error_message"`
+	TemplateID    *uint     `gorm:"index" json:"template_id,omitempty"`                 // 由配置模板渲染时的模板ID
+	TemplateRevision int    `gorm:"default:0" json:"template_revision,omitempty"`       // 渲染使用的模板修订版本
 	CreatedAt     time.Time `gorm:"index" json:"created_at"`
 
 	// 关联关系
@@ -188,4 +191,273 @@ type MultiplexConfig struct {
 
 func (MultiplexConfig) TableName() string {
 	return "multiplex_configs"
-}
\ No newline at end of file
+}
+
+// TrafficUsage 流量用量明细模型，每条记录为Agent上报的一个统计周期内的增量。
+// 同一统计对象的同一统计周期只记录一次，Agent重发的批次被忽略
+type TrafficUsage struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	AgentID     string    `gorm:"not null;size:64;index:idx_usage_agent_scope,priority:1;uniqueIndex:uk_usage_period,priority:1" json:"agent_id"`
+	Instance    string    `gorm:"not null;size:64;default:'default';uniqueIndex:uk_usage_period,priority:2" json:"instance"` // sing-box实例名称
+	Scope       string    `gorm:"not null;size:16;index:idx_usage_agent_scope,priority:2;uniqueIndex:uk_usage_period,priority:3" json:"scope"` // user, inbound, outbound
+	Name        string    `gorm:"not null;size:128;index:idx_usage_agent_scope,priority:3;uniqueIndex:uk_usage_period,priority:4" json:"name"`
+	Uplink      int64     `gorm:"not null;default:0" json:"uplink"`   // 字节
+	Downlink    int64     `gorm:"not null;default:0" json:"downlink"` // 字节
+	PeriodStart time.Time `gorm:"uniqueIndex:uk_usage_period,priority:5" json:"period_start"`
+	PeriodEnd   time.Time `gorm:"index;uniqueIndex:uk_usage_period,priority:6" json:"period_end"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+func (TrafficUsage) TableName() string {
+	return "traffic_usage"
+}
+
+// TrafficUsageHourly 流量用量小时汇总模型
+type TrafficUsageHourly struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	AgentID   string    `gorm:"not null;size:64;uniqueIndex:uk_usage_hour,priority:1" json:"agent_id"`
+	Instance  string    `gorm:"not null;size:64;default:'default';uniqueIndex:uk_usage_hour,priority:2" json:"instance"`
+	Scope     string    `gorm:"not null;size:16;uniqueIndex:uk_usage_hour,priority:3" json:"scope"`
+	Name      string    `gorm:"not null;size:128;uniqueIndex:uk_usage_hour,priority:4" json:"name"`
+	Hour      time.Time `gorm:"not null;uniqueIndex:uk_usage_hour,priority:5;index" json:"hour"` // 整点
+	Uplink    int64     `gorm:"not null;default:0" json:"uplink"`
+	Downlink  int64     `gorm:"not null;default:0" json:"downlink"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (TrafficUsageHourly) TableName() string {
+	return "traffic_usage_hourly"
+}
+
+// SingboxRollout sing-box版本批量切换任务
+type SingboxRollout struct {
+	ID          uint       `gorm:"primaryKey" json:"id"`
+	Version     string     `gorm:"not null;size:32" json:"version"`
+	SHA256      string     `gorm:"size:64" json:"sha256"`
+	Instance    string     `gorm:"size:64" json:"instance"`
+	MaxParallel int        `gorm:"not null;default:1" json:"max_parallel"` // 同时切换的Agent数
+	MaxFailures int        `gorm:"not null;default:0" json:"max_failures"` // 失败数超过该值后停止调度剩余Agent
+	Status      string     `gorm:"type:enum('running','completed','failed');default:'running';index" json:"status"`
+	Total       int        `gorm:"not null;default:0" json:"total"`
+	Succeeded   int        `gorm:"not null;default:0" json:"succeeded"`
+	Failed      int        `gorm:"not null;default:0" json:"failed"`
+	Skipped     int        `gorm:"not null;default:0" json:"skipped"`
+	CreatedAt   time.Time  `gorm:"index" json:"created_at"`
+	FinishedAt  *time.Time `json:"finished_at"`
+
+	// 关联关系
+	Targets []SingboxRolloutTarget `gorm:"foreignKey:RolloutID;constraint:OnDelete:CASCADE" json:"targets,omitempty"`
+}
+
+func (SingboxRollout) TableName() string {
+	return "singbox_rollouts"
+}
+
+// SingboxRolloutTarget 版本切换任务中单个Agent的进度
+type SingboxRolloutTarget struct {
+	ID             uint       `gorm:"primaryKey" json:"id"`
+	RolloutID      uint       `gorm:"not null;index" json:"rollout_id"`
+	AgentID        string     `gorm:"not null;size:64;index" json:"agent_id"`
+	Status         string     `gorm:"type:enum('pending','running','succeeded','rolled_back','failed','skipped');default:'pending'" json:"status"`
+	FromVersion    string     `gorm:"size:32" json:"from_version"`
+	CurrentVersion string     `gorm:"size:32" json:"current_version"`
+	Message        string     `gorm:"type:text" json:"message"`
+	StartedAt      *time.Time `json:"started_at"`
+	FinishedAt     *time.Time `json:"finished_at"`
+}
+
+func (SingboxRolloutTarget) TableName() string {
+	return "singbox_rollout_targets"
+}
+
+// ConfigTemplate sing-box配置模板，内容为按Agent渲染的text/template
+type ConfigTemplate struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	Name        string    `gorm:"not null;size:128;uniqueIndex" json:"name"`
+	Description string    `gorm:"size:512" json:"description"`
+	Content     string    `gorm:"not null;type:text" json:"content"`
+	Revision    int       `gorm:"not null;default:1" json:"revision"` // 当前修订版本，每次修改内容时递增
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+
+	// 关联关系
+	Revisions []ConfigTemplateRevision `gorm:"foreignKey:TemplateID;constraint:OnDelete:CASCADE" json:"revisions,omitempty"`
+}
+
+func (ConfigTemplate) TableName() string {
+	return "config_templates"
+}
+
+// ConfigTemplateRevision 配置模板的历史修订
+type ConfigTemplateRevision struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	TemplateID uint      `gorm:"not null;uniqueIndex:uk_template_revision,priority:1" json:"template_id"`
+	Revision   int       `gorm:"not null;uniqueIndex:uk_template_revision,priority:2" json:"revision"`
+	Content    string    `gorm:"not null;type:text" json:"content"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+func (ConfigTemplateRevision) TableName() string {
+	return "config_template_revisions"
+}
+
+// AgentTemplateVars 渲染配置模板时使用的Agent变量
+type AgentTemplateVars struct {
+	AgentID   string    `gorm:"primaryKey;size:64" json:"agent_id"`
+	Variables JSON      `gorm:"type:json" json:"variables"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (AgentTemplateVars) TableName() string {
+	return "agent_template_vars"
+}
+
+// Subscription 客户端订阅，按令牌输出用户可用节点的分享链接或客户端配置
+type Subscription struct {
+	ID           uint       `gorm:"primaryKey" json:"id"`
+	Name         string     `gorm:"size:128" json:"name"`
+	UserName     string     `gorm:"not null;size:128;index" json:"user_name"`  // 入站用户标识（name，mixed/http/socks为username）
+	Token        string     `gorm:"not null;size:64;uniqueIndex" json:"token"` // 订阅令牌
+	Enabled      bool       `gorm:"not null;default:true" json:"enabled"`
+	Regions      []string   `gorm:"serializer:json;type:json" json:"regions"`      // 允许的国家或地区，为空不限
+	AgentIDs     []string   `gorm:"serializer:json;type:json" json:"agent_ids"`    // 允许的Agent，为空不限
+	InboundTags  []string   `gorm:"serializer:json;type:json" json:"inbound_tags"` // 允许的入站tag，为空不限
+	ExpiresAt    *time.Time `json:"expires_at"`
+	LastAccessAt *time.Time `json:"last_access_at"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+func (Subscription) TableName() string {
+	return "subscriptions"
+}
+
+// OutboundProbe 出站探测结果，每条记录为Agent一次心跳上报的一个出站对一个目标的探测汇总
+type OutboundProbe struct {
+	ID            uint      `gorm:"primaryKey" json:"id"`
+	AgentID       string    `gorm:"not null;size:64;index:idx_probe_agent_outbound,priority:1" json:"agent_id"`
+	Instance      string    `gorm:"not null;size:64;default:'default';index:idx_probe_agent_outbound,priority:2" json:"instance"`
+	Outbound      string    `gorm:"not null;size:128;index:idx_probe_agent_outbound,priority:3" json:"outbound"`
+	TargetType    string    `gorm:"not null;size:16" json:"target_type"` // http, tcp, dns
+	Target        string    `gorm:"not null;size:255" json:"target"`
+	Probes        int       `gorm:"not null;default:0" json:"probes"`
+	Successes     int       `gorm:"not null;default:0" json:"successes"`
+	AvgLatencyMs  float64   `gorm:"not null;default:0" json:"avg_latency_ms"` // 成功探测的平均延迟
+	MaxLatencyMs  int64     `gorm:"not null;default:0" json:"max_latency_ms"`
+	LastLatencyMs int64     `gorm:"not null;default:0" json:"last_latency_ms"`
+	LastError     string    `gorm:"type:text" json:"last_error"`
+	PeriodStart   time.Time `json:"period_start"`
+	PeriodEnd     time.Time `gorm:"index" json:"period_end"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+func (OutboundProbe) TableName() string {
+	return "outbound_probes"
+}
+
+// Certificate Controller托管的TLS证书，私钥不随接口返回
+type Certificate struct {
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	Name         string    `gorm:"not null;size:128;uniqueIndex" json:"name"` // 同时用作Agent上的证书文件名
+	CertPEM      string    `gorm:"type:text;not null" json:"certificate_pem"` // 证书链，叶子证书在前
+	KeyPEM       string    `gorm:"type:text;not null" json:"-"`
+	Domains      []string  `gorm:"serializer:json;type:json" json:"domains"` // SAN中的域名和IP
+	Subject      string    `gorm:"size:255" json:"subject"`
+	Issuer       string    `gorm:"size:255" json:"issuer"`
+	SerialNumber string    `gorm:"size:128" json:"serial_number"`
+	Fingerprint  string    `gorm:"not null;size:64" json:"fingerprint"` // 叶子证书sha256
+	NotBefore    time.Time `json:"not_before"`
+	NotAfter     time.Time `gorm:"index" json:"not_after"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+func (Certificate) TableName() string {
+	return "certificates"
+}
+
+// CertificateAssignment 证书分配，一个Agent实例的入站只能使用一张证书
+type CertificateAssignment struct {
+	ID            uint       `gorm:"primaryKey" json:"id"`
+	CertificateID uint       `gorm:"not null;index" json:"certificate_id"`
+	AgentID       string     `gorm:"not null;size:64;uniqueIndex:idx_cert_assignment_target,priority:1" json:"agent_id"`
+	Instance      string     `gorm:"not null;size:64;default:'default';uniqueIndex:idx_cert_assignment_target,priority:2" json:"instance"`
+	InboundTag    string     `gorm:"not null;size:128;uniqueIndex:idx_cert_assignment_target,priority:3" json:"inbound_tag"`
+	Status        string     `gorm:"not null;size:16;default:'pending'" json:"status"` // pending, applied, failed
+	Fingerprint   string     `gorm:"size:64" json:"fingerprint"`                       // 最近一次成功下发的证书指纹
+	ErrorMessage  string     `gorm:"type:text" json:"error_message"`
+	PushedAt      *time.Time `json:"pushed_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+func (CertificateAssignment) TableName() string {
+	return "certificate_assignments"
+}
+
+// RealityKey Controller托管的Reality入站密钥对和short_id，私钥加密保存
+type RealityKey struct {
+	ID              uint       `gorm:"primaryKey" json:"id"`
+	AgentID         string     `gorm:"not null;size:64;uniqueIndex:idx_reality_key_target,priority:1" json:"agent_id"`
+	Instance        string     `gorm:"not null;size:64;default:'default';uniqueIndex:idx_reality_key_target,priority:2" json:"instance"`
+	InboundTag      string     `gorm:"not null;size:128;uniqueIndex:idx_reality_key_target,priority:3" json:"inbound_tag"`
+	PrivateKeyEnc   string     `gorm:"type:text;not null" json:"-"`                      // AES-GCM加密的私钥
+	PublicKey       string     `gorm:"not null;size:64" json:"public_key"`               // base64url编码的X25519公钥
+	ShortIDs        []string   `gorm:"serializer:json;type:json" json:"short_ids"`       // 客户端使用第一个
+	ServerName      string     `gorm:"size:255" json:"server_name"`                      // 客户端使用的SNI
+	Version         int        `gorm:"not null;default:1" json:"version"`                // 每次轮换加1
+	RotationDays    int        `gorm:"not null;default:0" json:"rotation_days"`          // 自动轮换周期，0为不自动轮换
+	Status          string     `gorm:"not null;size:16;default:'applied'" json:"status"` // pending, applied, failed
+	ErrorMessage    string     `gorm:"type:text" json:"error_message"`                   // 最近一次下发失败的原因
+	PendingKeyEnc   string     `gorm:"type:text" json:"-"`                               // 正在下发或下发失败的新私钥（加密），Agent确认后成为当前密钥
+	PendingShortIDs []string   `gorm:"serializer:json;type:json" json:"-"`
+	RotatedAt       time.Time  `json:"rotated_at"`
+	NextRotationAt  *time.Time `gorm:"index" json:"next_rotation_at"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+func (RealityKey) TableName() string {
+	return "reality_keys"
+}
+
+// CredentialRotation 入站用户凭据定期轮换计划
+type CredentialRotation struct {
+	ID           uint       `gorm:"primaryKey" json:"id"`
+	Name         string     `gorm:"not null;size:128;uniqueIndex" json:"name"`
+	AgentIDs     []string   `gorm:"serializer:json;type:json" json:"agent_ids"`    // 为空时为全部Agent
+	InboundTags  []string   `gorm:"serializer:json;type:json" json:"inbound_tags"` // 为空时为全部支持轮换的入站
+	UserNames    []string   `gorm:"serializer:json;type:json" json:"user_names"`   // 为空时为入站的全部用户
+	IntervalDays int        `gorm:"not null" json:"interval_days"`                 // 轮换周期（天）
+	OverlapHours int        `gorm:"not null;default:24" json:"overlap_hours"`      // 新旧凭据同时有效的时长，0为立即吊销
+	Enabled      bool       `gorm:"not null;default:true" json:"enabled"`
+	LastRunAt    *time.Time `json:"last_run_at"` // 最近一次成功执行的时间
+	NextRunAt    *time.Time `gorm:"index" json:"next_run_at"`
+	LastError    string     `gorm:"type:text" json:"last_error"` // 最近一次执行全部失败的原因，成功后清空
+	RunningUntil *time.Time `json:"-"`                           // 执行租约，同一计划同时只有一次执行
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+func (CredentialRotation) TableName() string {
+	return "credential_rotations"
+}
+
+// CredentialRotationRun 一次轮换在一个Agent入站上的执行记录，旧凭据在重叠期结束后吊销
+type CredentialRotationRun struct {
+	ID           uint       `gorm:"primaryKey" json:"id"`
+	RotationID   uint       `gorm:"not null;index" json:"rotation_id"`
+	AgentID      string     `gorm:"not null;size:64;index" json:"agent_id"`
+	Instance     string     `gorm:"not null;size:64;default:'default'" json:"instance"`
+	Users        []string   `gorm:"serializer:json;type:json" json:"users"` // 轮换的用户，格式为 入站tag/用户标识
+	Status       string     `gorm:"not null;size:16;index" json:"status"`   // overlap, revoked, failed
+	ErrorMessage string     `gorm:"type:text" json:"error_message"`
+	RevokeAt     *time.Time `gorm:"index" json:"revoke_at"` // 计划吊销旧凭据的时间
+	RevokedAt    *time.Time `json:"revoked_at"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+func (CredentialRotationRun) TableName() string {
+	return "credential_rotation_runs"
+}
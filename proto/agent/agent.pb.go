@@ -169,13 +169,16 @@ func (x *RegisterResponse) GetToken() string {
 
 // 心跳请求
 type HeartbeatRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
-	Status        string                 `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`
-	Metrics       map[string]string      `protobuf:"bytes,3,rep,name=metrics,proto3" json:"metrics,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
-	IpRangeInfo   *IPRangeInfo           `protobuf:"bytes,4,opt,name=ip_range_info,json=ipRangeInfo,proto3" json:"ip_range_info,omitempty"` // IP段信息（可选，仅在变化时发送）
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	AgentId         string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	Status          string                 `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`
+	Metrics         map[string]string      `protobuf:"bytes,3,rep,name=metrics,proto3" json:"metrics,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	IpRangeInfo     *IPRangeInfo           `protobuf:"bytes,4,opt,name=ip_range_info,json=ipRangeInfo,proto3" json:"ip_range_info,omitempty"`           // IP段信息（可选，仅在变化时发送）
+	ConnectionStats *ConnectionStats       `protobuf:"bytes,5,opt,name=connection_stats,json=connectionStats,proto3" json:"connection_stats,omitempty"` // sing-box连接与流量统计（来自Clash API）
+	Instances       []*InstanceStatus      `protobuf:"bytes,6,rep,name=instances,proto3" json:"instances,omitempty"`                                    // 各sing-box实例状态
+	OutboundProbes  []*OutboundProbeResult `protobuf:"bytes,7,rep,name=outbound_probes,json=outboundProbes,proto3" json:"outbound_probes,omitempty"`    // 上次心跳以来的出站探测结果
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
 }
 
 func (x *HeartbeatRequest) Reset() {
@@ -236,6 +239,27 @@ func (x *HeartbeatRequest) GetIpRangeInfo() *IPRangeInfo {
 	return nil
 }
 
+func (x *HeartbeatRequest) GetConnectionStats() *ConnectionStats {
+	if x != nil {
+		return x.ConnectionStats
+	}
+	return nil
+}
+
+func (x *HeartbeatRequest) GetInstances() []*InstanceStatus {
+	if x != nil {
+		return x.Instances
+	}
+	return nil
+}
+
+func (x *HeartbeatRequest) GetOutboundProbes() []*OutboundProbeResult {
+	if x != nil {
+		return x.OutboundProbes
+	}
+	return nil
+}
+
 // 心跳响应
 type HeartbeatResponse struct {
 	state                 protoimpl.MessageState `protogen:"open.v1"`
@@ -304,6 +328,7 @@ type ConfigRequest struct {
 	ConfigContent string                 `protobuf:"bytes,2,opt,name=config_content,json=configContent,proto3" json:"config_content,omitempty"`
 	ConfigVersion string                 `protobuf:"bytes,3,opt,name=config_version,json=configVersion,proto3" json:"config_version,omitempty"`
 	ForceUpdate   bool                   `protobuf:"varint,4,opt,name=force_update,json=forceUpdate,proto3" json:"force_update,omitempty"`
+	Instance      string                 `protobuf:"bytes,5,opt,name=instance,proto3" json:"instance,omitempty"` // sing-box实例名称，为空时为默认实例
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -366,12 +391,22 @@ func (x *ConfigRequest) GetForceUpdate() bool {
 	return false
 }
 
+func (x *ConfigRequest) GetInstance() string {
+	if x != nil {
+		return x.Instance
+	}
+	return ""
+}
+
 // 配置响应
 type ConfigResponse struct {
 	state          protoimpl.MessageState `protogen:"open.v1"`
 	Success        bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
 	Message        string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
 	AppliedVersion string                 `protobuf:"bytes,3,opt,name=applied_version,json=appliedVersion,proto3" json:"applied_version,omitempty"`
+	Phases         []*ApplyPhase          `protobuf:"bytes,4,rep,name=phases,proto3" json:"phases,omitempty"`                                    // 应用流水线各阶段结果
+	Reverted       bool                   `protobuf:"varint,5,opt,name=reverted,proto3" json:"reverted,omitempty"`                               // 是否已回退到上一代配置
+	PortConflicts  []*PortConflict        `protobuf:"bytes,6,rep,name=port_conflicts,json=portConflicts,proto3" json:"port_conflicts,omitempty"` // 预检发现的入站端口冲突，未设置force_update时拒绝应用
 	unknownFields  protoimpl.UnknownFields
 	sizeCache      protoimpl.SizeCache
 }
@@ -427,19 +462,211 @@ func (x *ConfigResponse) GetAppliedVersion() string {
 	return ""
 }
 
+func (x *ConfigResponse) GetPhases() []*ApplyPhase {
+	if x != nil {
+		return x.Phases
+	}
+	return nil
+}
+
+func (x *ConfigResponse) GetReverted() bool {
+	if x != nil {
+		return x.Reverted
+	}
+	return false
+}
+
+func (x *ConfigResponse) GetPortConflicts() []*PortConflict {
+	if x != nil {
+		return x.PortConflicts
+	}
+	return nil
+}
+
+// 入站端口冲突
+type PortConflict struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Inbound       string                 `protobuf:"bytes,1,opt,name=inbound,proto3" json:"inbound,omitempty"`   // 入站tag
+	Protocol      string                 `protobuf:"bytes,2,opt,name=protocol,proto3" json:"protocol,omitempty"` // tcp, udp
+	Listen        string                 `protobuf:"bytes,3,opt,name=listen,proto3" json:"listen,omitempty"`     // 入站监听地址
+	Port          int32                  `protobuf:"varint,4,opt,name=port,proto3" json:"port,omitempty"`
+	Address       string                 `protobuf:"bytes,5,opt,name=address,proto3" json:"address,omitempty"` // 已占用端口的监听地址
+	Pid           int32                  `protobuf:"varint,6,opt,name=pid,proto3" json:"pid,omitempty"`        // 占用进程，0表示未知
+	Process       string                 `protobuf:"bytes,7,opt,name=process,proto3" json:"process,omitempty"` // 占用进程名
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PortConflict) Reset() {
+	*x = PortConflict{}
+	mi := &file_proto_agent_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PortConflict) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PortConflict) ProtoMessage() {}
+
+func (x *PortConflict) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_agent_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PortConflict.ProtoReflect.Descriptor instead.
+func (*PortConflict) Descriptor() ([]byte, []int) {
+	return file_proto_agent_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *PortConflict) GetInbound() string {
+	if x != nil {
+		return x.Inbound
+	}
+	return ""
+}
+
+func (x *PortConflict) GetProtocol() string {
+	if x != nil {
+		return x.Protocol
+	}
+	return ""
+}
+
+func (x *PortConflict) GetListen() string {
+	if x != nil {
+		return x.Listen
+	}
+	return ""
+}
+
+func (x *PortConflict) GetPort() int32 {
+	if x != nil {
+		return x.Port
+	}
+	return 0
+}
+
+func (x *PortConflict) GetAddress() string {
+	if x != nil {
+		return x.Address
+	}
+	return ""
+}
+
+func (x *PortConflict) GetPid() int32 {
+	if x != nil {
+		return x.Pid
+	}
+	return 0
+}
+
+func (x *PortConflict) GetProcess() string {
+	if x != nil {
+		return x.Process
+	}
+	return ""
+}
+
+// 配置应用阶段结果
+type ApplyPhase struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Phase         string                 `protobuf:"bytes,1,opt,name=phase,proto3" json:"phase,omitempty"` // stage, validate, preflight, swap, reload, restart, probe, revert
+	Success       bool                   `protobuf:"varint,2,opt,name=success,proto3" json:"success,omitempty"`
+	Skipped       bool                   `protobuf:"varint,3,opt,name=skipped,proto3" json:"skipped,omitempty"`
+	Message       string                 `protobuf:"bytes,4,opt,name=message,proto3" json:"message,omitempty"`
+	DurationMs    int64                  `protobuf:"varint,5,opt,name=duration_ms,json=durationMs,proto3" json:"duration_ms,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ApplyPhase) Reset() {
+	*x = ApplyPhase{}
+	mi := &file_proto_agent_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ApplyPhase) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ApplyPhase) ProtoMessage() {}
+
+func (x *ApplyPhase) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_agent_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ApplyPhase.ProtoReflect.Descriptor instead.
+func (*ApplyPhase) Descriptor() ([]byte, []int) {
+	return file_proto_agent_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *ApplyPhase) GetPhase() string {
+	if x != nil {
+		return x.Phase
+	}
+	return ""
+}
+
+func (x *ApplyPhase) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *ApplyPhase) GetSkipped() bool {
+	if x != nil {
+		return x.Skipped
+	}
+	return false
+}
+
+func (x *ApplyPhase) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *ApplyPhase) GetDurationMs() int64 {
+	if x != nil {
+		return x.DurationMs
+	}
+	return 0
+}
+
 // 规则请求
 type RulesRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
 	Rules         []*Rule                `protobuf:"bytes,2,rep,name=rules,proto3" json:"rules,omitempty"`
 	Operation     string                 `protobuf:"bytes,3,opt,name=operation,proto3" json:"operation,omitempty"` // add, delete, update, replace
+	Instance      string                 `protobuf:"bytes,4,opt,name=instance,proto3" json:"instance,omitempty"`   // sing-box实例名称，为空时为默认实例
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *RulesRequest) Reset() {
 	*x = RulesRequest{}
-	mi := &file_proto_agent_proto_msgTypes[6]
+	mi := &file_proto_agent_proto_msgTypes[8]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -451,7 +678,7 @@ func (x *RulesRequest) String() string {
 func (*RulesRequest) ProtoMessage() {}
 
 func (x *RulesRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_agent_proto_msgTypes[6]
+	mi := &file_proto_agent_proto_msgTypes[8]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -464,7 +691,7 @@ func (x *RulesRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use RulesRequest.ProtoReflect.Descriptor instead.
 func (*RulesRequest) Descriptor() ([]byte, []int) {
-	return file_proto_agent_proto_rawDescGZIP(), []int{6}
+	return file_proto_agent_proto_rawDescGZIP(), []int{8}
 }
 
 func (x *RulesRequest) GetAgentId() string {
@@ -488,6 +715,13 @@ func (x *RulesRequest) GetOperation() string {
 	return ""
 }
 
+func (x *RulesRequest) GetInstance() string {
+	if x != nil {
+		return x.Instance
+	}
+	return ""
+}
+
 // 规则响应
 type RulesResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -500,7 +734,7 @@ type RulesResponse struct {
 
 func (x *RulesResponse) Reset() {
 	*x = RulesResponse{}
-	mi := &file_proto_agent_proto_msgTypes[7]
+	mi := &file_proto_agent_proto_msgTypes[9]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -512,7 +746,7 @@ func (x *RulesResponse) String() string {
 func (*RulesResponse) ProtoMessage() {}
 
 func (x *RulesResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_agent_proto_msgTypes[7]
+	mi := &file_proto_agent_proto_msgTypes[9]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -525,7 +759,7 @@ func (x *RulesResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use RulesResponse.ProtoReflect.Descriptor instead.
 func (*RulesResponse) Descriptor() ([]byte, []int) {
-	return file_proto_agent_proto_rawDescGZIP(), []int{7}
+	return file_proto_agent_proto_rawDescGZIP(), []int{9}
 }
 
 func (x *RulesResponse) GetSuccess() bool {
@@ -553,13 +787,14 @@ func (x *RulesResponse) GetFailedRules() []string {
 type StatusRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	Instance      string                 `protobuf:"bytes,2,opt,name=instance,proto3" json:"instance,omitempty"` // sing-box实例名称，为空时为默认实例
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *StatusRequest) Reset() {
 	*x = StatusRequest{}
-	mi := &file_proto_agent_proto_msgTypes[8]
+	mi := &file_proto_agent_proto_msgTypes[10]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -571,7 +806,7 @@ func (x *StatusRequest) String() string {
 func (*StatusRequest) ProtoMessage() {}
 
 func (x *StatusRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_agent_proto_msgTypes[8]
+	mi := &file_proto_agent_proto_msgTypes[10]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -584,7 +819,7 @@ func (x *StatusRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use StatusRequest.ProtoReflect.Descriptor instead.
 func (*StatusRequest) Descriptor() ([]byte, []int) {
-	return file_proto_agent_proto_rawDescGZIP(), []int{8}
+	return file_proto_agent_proto_rawDescGZIP(), []int{10}
 }
 
 func (x *StatusRequest) GetAgentId() string {
@@ -594,6 +829,13 @@ func (x *StatusRequest) GetAgentId() string {
 	return ""
 }
 
+func (x *StatusRequest) GetInstance() string {
+	if x != nil {
+		return x.Instance
+	}
+	return ""
+}
+
 // 状态响应
 type StatusResponse struct {
 	state             protoimpl.MessageState `protogen:"open.v1"`
@@ -604,13 +846,14 @@ type StatusResponse struct {
 	RulesCount        int32                  `protobuf:"varint,5,opt,name=rules_count,json=rulesCount,proto3" json:"rules_count,omitempty"`
 	SystemInfo        map[string]string      `protobuf:"bytes,6,rep,name=system_info,json=systemInfo,proto3" json:"system_info,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
 	ActiveConnections []string               `protobuf:"bytes,7,rep,name=active_connections,json=activeConnections,proto3" json:"active_connections,omitempty"`
+	Instances         []*InstanceStatus      `protobuf:"bytes,8,rep,name=instances,proto3" json:"instances,omitempty"` // 各sing-box实例状态
 	unknownFields     protoimpl.UnknownFields
 	sizeCache         protoimpl.SizeCache
 }
 
 func (x *StatusResponse) Reset() {
 	*x = StatusResponse{}
-	mi := &file_proto_agent_proto_msgTypes[9]
+	mi := &file_proto_agent_proto_msgTypes[11]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -622,7 +865,7 @@ func (x *StatusResponse) String() string {
 func (*StatusResponse) ProtoMessage() {}
 
 func (x *StatusResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_agent_proto_msgTypes[9]
+	mi := &file_proto_agent_proto_msgTypes[11]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -635,7 +878,7 @@ func (x *StatusResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use StatusResponse.ProtoReflect.Descriptor instead.
 func (*StatusResponse) Descriptor() ([]byte, []int) {
-	return file_proto_agent_proto_rawDescGZIP(), []int{9}
+	return file_proto_agent_proto_rawDescGZIP(), []int{11}
 }
 
 func (x *StatusResponse) GetSuccess() bool {
@@ -687,6 +930,13 @@ func (x *StatusResponse) GetActiveConnections() []string {
 	return nil
 }
 
+func (x *StatusResponse) GetInstances() []*InstanceStatus {
+	if x != nil {
+		return x.Instances
+	}
+	return nil
+}
+
 // 规则定义
 type Rule struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -702,7 +952,7 @@ type Rule struct {
 
 func (x *Rule) Reset() {
 	*x = Rule{}
-	mi := &file_proto_agent_proto_msgTypes[10]
+	mi := &file_proto_agent_proto_msgTypes[12]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -714,7 +964,7 @@ func (x *Rule) String() string {
 func (*Rule) ProtoMessage() {}
 
 func (x *Rule) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_agent_proto_msgTypes[10]
+	mi := &file_proto_agent_proto_msgTypes[12]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -727,7 +977,7 @@ func (x *Rule) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Rule.ProtoReflect.Descriptor instead.
 func (*Rule) Descriptor() ([]byte, []int) {
-	return file_proto_agent_proto_rawDescGZIP(), []int{10}
+	return file_proto_agent_proto_rawDescGZIP(), []int{12}
 }
 
 func (x *Rule) GetId() string {
@@ -781,13 +1031,14 @@ type BlacklistRequest struct {
 	Ips           []string               `protobuf:"bytes,4,rep,name=ips,proto3" json:"ips,omitempty"`
 	Ports         []string               `protobuf:"bytes,5,rep,name=ports,proto3" json:"ports,omitempty"`
 	Operation     string                 `protobuf:"bytes,6,opt,name=operation,proto3" json:"operation,omitempty"` // add, remove, replace, clear
+	Instance      string                 `protobuf:"bytes,7,opt,name=instance,proto3" json:"instance,omitempty"`   // sing-box实例名称，为空时为默认实例
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *BlacklistRequest) Reset() {
 	*x = BlacklistRequest{}
-	mi := &file_proto_agent_proto_msgTypes[11]
+	mi := &file_proto_agent_proto_msgTypes[13]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -799,7 +1050,7 @@ func (x *BlacklistRequest) String() string {
 func (*BlacklistRequest) ProtoMessage() {}
 
 func (x *BlacklistRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_agent_proto_msgTypes[11]
+	mi := &file_proto_agent_proto_msgTypes[13]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -812,7 +1063,7 @@ func (x *BlacklistRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use BlacklistRequest.ProtoReflect.Descriptor instead.
 func (*BlacklistRequest) Descriptor() ([]byte, []int) {
-	return file_proto_agent_proto_rawDescGZIP(), []int{11}
+	return file_proto_agent_proto_rawDescGZIP(), []int{13}
 }
 
 func (x *BlacklistRequest) GetAgentId() string {
@@ -857,6 +1108,13 @@ func (x *BlacklistRequest) GetOperation() string {
 	return ""
 }
 
+func (x *BlacklistRequest) GetInstance() string {
+	if x != nil {
+		return x.Instance
+	}
+	return ""
+}
+
 // 黑名单响应
 type BlacklistResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -869,7 +1127,7 @@ type BlacklistResponse struct {
 
 func (x *BlacklistResponse) Reset() {
 	*x = BlacklistResponse{}
-	mi := &file_proto_agent_proto_msgTypes[12]
+	mi := &file_proto_agent_proto_msgTypes[14]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -881,7 +1139,7 @@ func (x *BlacklistResponse) String() string {
 func (*BlacklistResponse) ProtoMessage() {}
 
 func (x *BlacklistResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_agent_proto_msgTypes[12]
+	mi := &file_proto_agent_proto_msgTypes[14]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -894,7 +1152,7 @@ func (x *BlacklistResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use BlacklistResponse.ProtoReflect.Descriptor instead.
 func (*BlacklistResponse) Descriptor() ([]byte, []int) {
-	return file_proto_agent_proto_rawDescGZIP(), []int{12}
+	return file_proto_agent_proto_rawDescGZIP(), []int{14}
 }
 
 func (x *BlacklistResponse) GetSuccess() bool {
@@ -927,13 +1185,14 @@ type WhitelistRequest struct {
 	Ips           []string               `protobuf:"bytes,4,rep,name=ips,proto3" json:"ips,omitempty"`
 	Ports         []string               `protobuf:"bytes,5,rep,name=ports,proto3" json:"ports,omitempty"`
 	Operation     string                 `protobuf:"bytes,6,opt,name=operation,proto3" json:"operation,omitempty"` // add, remove, replace, clear
+	Instance      string                 `protobuf:"bytes,7,opt,name=instance,proto3" json:"instance,omitempty"`   // sing-box实例名称，为空时为默认实例
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *WhitelistRequest) Reset() {
 	*x = WhitelistRequest{}
-	mi := &file_proto_agent_proto_msgTypes[13]
+	mi := &file_proto_agent_proto_msgTypes[15]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -945,7 +1204,7 @@ func (x *WhitelistRequest) String() string {
 func (*WhitelistRequest) ProtoMessage() {}
 
 func (x *WhitelistRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_agent_proto_msgTypes[13]
+	mi := &file_proto_agent_proto_msgTypes[15]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -958,7 +1217,7 @@ func (x *WhitelistRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use WhitelistRequest.ProtoReflect.Descriptor instead.
 func (*WhitelistRequest) Descriptor() ([]byte, []int) {
-	return file_proto_agent_proto_rawDescGZIP(), []int{13}
+	return file_proto_agent_proto_rawDescGZIP(), []int{15}
 }
 
 func (x *WhitelistRequest) GetAgentId() string {
@@ -1003,6 +1262,13 @@ func (x *WhitelistRequest) GetOperation() string {
 	return ""
 }
 
+func (x *WhitelistRequest) GetInstance() string {
+	if x != nil {
+		return x.Instance
+	}
+	return ""
+}
+
 // 白名单响应
 type WhitelistResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -1015,7 +1281,7 @@ type WhitelistResponse struct {
 
 func (x *WhitelistResponse) Reset() {
 	*x = WhitelistResponse{}
-	mi := &file_proto_agent_proto_msgTypes[14]
+	mi := &file_proto_agent_proto_msgTypes[16]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1027,7 +1293,7 @@ func (x *WhitelistResponse) String() string {
 func (*WhitelistResponse) ProtoMessage() {}
 
 func (x *WhitelistResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_agent_proto_msgTypes[14]
+	mi := &file_proto_agent_proto_msgTypes[16]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1040,7 +1306,7 @@ func (x *WhitelistResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use WhitelistResponse.ProtoReflect.Descriptor instead.
 func (*WhitelistResponse) Descriptor() ([]byte, []int) {
-	return file_proto_agent_proto_rawDescGZIP(), []int{14}
+	return file_proto_agent_proto_rawDescGZIP(), []int{16}
 }
 
 func (x *WhitelistResponse) GetSuccess() bool {
@@ -1069,13 +1335,14 @@ type FilterConfigRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
 	Protocol      string                 `protobuf:"bytes,2,opt,name=protocol,proto3" json:"protocol,omitempty"` // 如果为空，返回所有协议的配置
+	Instance      string                 `protobuf:"bytes,3,opt,name=instance,proto3" json:"instance,omitempty"` // sing-box实例名称，为空时为默认实例
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *FilterConfigRequest) Reset() {
 	*x = FilterConfigRequest{}
-	mi := &file_proto_agent_proto_msgTypes[15]
+	mi := &file_proto_agent_proto_msgTypes[17]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1087,7 +1354,7 @@ func (x *FilterConfigRequest) String() string {
 func (*FilterConfigRequest) ProtoMessage() {}
 
 func (x *FilterConfigRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_agent_proto_msgTypes[15]
+	mi := &file_proto_agent_proto_msgTypes[17]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1100,7 +1367,7 @@ func (x *FilterConfigRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use FilterConfigRequest.ProtoReflect.Descriptor instead.
 func (*FilterConfigRequest) Descriptor() ([]byte, []int) {
-	return file_proto_agent_proto_rawDescGZIP(), []int{15}
+	return file_proto_agent_proto_rawDescGZIP(), []int{17}
 }
 
 func (x *FilterConfigRequest) GetAgentId() string {
@@ -1117,6 +1384,13 @@ func (x *FilterConfigRequest) GetProtocol() string {
 	return ""
 }
 
+func (x *FilterConfigRequest) GetInstance() string {
+	if x != nil {
+		return x.Instance
+	}
+	return ""
+}
+
 // 过滤配置响应
 type FilterConfigResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -1129,7 +1403,7 @@ type FilterConfigResponse struct {
 
 func (x *FilterConfigResponse) Reset() {
 	*x = FilterConfigResponse{}
-	mi := &file_proto_agent_proto_msgTypes[16]
+	mi := &file_proto_agent_proto_msgTypes[18]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1141,7 +1415,7 @@ func (x *FilterConfigResponse) String() string {
 func (*FilterConfigResponse) ProtoMessage() {}
 
 func (x *FilterConfigResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_agent_proto_msgTypes[16]
+	mi := &file_proto_agent_proto_msgTypes[18]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1154,7 +1428,7 @@ func (x *FilterConfigResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use FilterConfigResponse.ProtoReflect.Descriptor instead.
 func (*FilterConfigResponse) Descriptor() ([]byte, []int) {
-	return file_proto_agent_proto_rawDescGZIP(), []int{16}
+	return file_proto_agent_proto_rawDescGZIP(), []int{18}
 }
 
 func (x *FilterConfigResponse) GetSuccess() bool {
@@ -1196,7 +1470,7 @@ type ProtocolFilter struct {
 
 func (x *ProtocolFilter) Reset() {
 	*x = ProtocolFilter{}
-	mi := &file_proto_agent_proto_msgTypes[17]
+	mi := &file_proto_agent_proto_msgTypes[19]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1208,7 +1482,7 @@ func (x *ProtocolFilter) String() string {
 func (*ProtocolFilter) ProtoMessage() {}
 
 func (x *ProtocolFilter) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_agent_proto_msgTypes[17]
+	mi := &file_proto_agent_proto_msgTypes[19]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1221,7 +1495,7 @@ func (x *ProtocolFilter) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ProtocolFilter.ProtoReflect.Descriptor instead.
 func (*ProtocolFilter) Descriptor() ([]byte, []int) {
-	return file_proto_agent_proto_rawDescGZIP(), []int{17}
+	return file_proto_agent_proto_rawDescGZIP(), []int{19}
 }
 
 func (x *ProtocolFilter) GetProtocol() string {
@@ -1293,13 +1567,15 @@ type RollbackRequest struct {
 	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
 	TargetVersion string                 `protobuf:"bytes,2,opt,name=target_version,json=targetVersion,proto3" json:"target_version,omitempty"` // 回滚到的目标版本，如果为空则回滚到上一个版本
 	Reason        string                 `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"`                                    // 回滚原因
+	Scope         string                 `protobuf:"bytes,4,opt,name=scope,proto3" json:"scope,omitempty"`                                      // 回滚范围: filter(默认), singbox, dns
+	Instance      string                 `protobuf:"bytes,5,opt,name=instance,proto3" json:"instance,omitempty"`                                // sing-box实例名称，为空时为默认实例
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *RollbackRequest) Reset() {
 	*x = RollbackRequest{}
-	mi := &file_proto_agent_proto_msgTypes[18]
+	mi := &file_proto_agent_proto_msgTypes[20]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1311,7 +1587,7 @@ func (x *RollbackRequest) String() string {
 func (*RollbackRequest) ProtoMessage() {}
 
 func (x *RollbackRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_agent_proto_msgTypes[18]
+	mi := &file_proto_agent_proto_msgTypes[20]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1324,7 +1600,7 @@ func (x *RollbackRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use RollbackRequest.ProtoReflect.Descriptor instead.
 func (*RollbackRequest) Descriptor() ([]byte, []int) {
-	return file_proto_agent_proto_rawDescGZIP(), []int{18}
+	return file_proto_agent_proto_rawDescGZIP(), []int{20}
 }
 
 func (x *RollbackRequest) GetAgentId() string {
@@ -1348,6 +1624,20 @@ func (x *RollbackRequest) GetReason() string {
 	return ""
 }
 
+func (x *RollbackRequest) GetScope() string {
+	if x != nil {
+		return x.Scope
+	}
+	return ""
+}
+
+func (x *RollbackRequest) GetInstance() string {
+	if x != nil {
+		return x.Instance
+	}
+	return ""
+}
+
 // 回滚响应
 type RollbackResponse struct {
 	state             protoimpl.MessageState `protogen:"open.v1"`
@@ -1355,13 +1645,14 @@ type RollbackResponse struct {
 	Message           string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
 	RolledBackVersion string                 `protobuf:"bytes,3,opt,name=rolled_back_version,json=rolledBackVersion,proto3" json:"rolled_back_version,omitempty"`
 	CurrentVersion    string                 `protobuf:"bytes,4,opt,name=current_version,json=currentVersion,proto3" json:"current_version,omitempty"`
+	Phases            []*ApplyPhase          `protobuf:"bytes,5,rep,name=phases,proto3" json:"phases,omitempty"` // scope为singbox时的应用流水线阶段结果
 	unknownFields     protoimpl.UnknownFields
 	sizeCache         protoimpl.SizeCache
 }
 
 func (x *RollbackResponse) Reset() {
 	*x = RollbackResponse{}
-	mi := &file_proto_agent_proto_msgTypes[19]
+	mi := &file_proto_agent_proto_msgTypes[21]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1373,7 +1664,7 @@ func (x *RollbackResponse) String() string {
 func (*RollbackResponse) ProtoMessage() {}
 
 func (x *RollbackResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_agent_proto_msgTypes[19]
+	mi := &file_proto_agent_proto_msgTypes[21]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1386,7 +1677,7 @@ func (x *RollbackResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use RollbackResponse.ProtoReflect.Descriptor instead.
 func (*RollbackResponse) Descriptor() ([]byte, []int) {
-	return file_proto_agent_proto_rawDescGZIP(), []int{19}
+	return file_proto_agent_proto_rawDescGZIP(), []int{21}
 }
 
 func (x *RollbackResponse) GetSuccess() bool {
@@ -1417,19 +1708,27 @@ func (x *RollbackResponse) GetCurrentVersion() string {
 	return ""
 }
 
+func (x *RollbackResponse) GetPhases() []*ApplyPhase {
+	if x != nil {
+		return x.Phases
+	}
+	return nil
+}
+
 // 多路复用配置请求
 type MultiplexConfigRequest struct {
 	state           protoimpl.MessageState `protogen:"open.v1"`
 	AgentId         string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
 	Protocol        string                 `protobuf:"bytes,2,opt,name=protocol,proto3" json:"protocol,omitempty"` // 协议类型：vmess, vless, trojan, shadowsocks
 	MultiplexConfig *MultiplexConfig       `protobuf:"bytes,3,opt,name=multiplex_config,json=multiplexConfig,proto3" json:"multiplex_config,omitempty"`
+	Instance        string                 `protobuf:"bytes,4,opt,name=instance,proto3" json:"instance,omitempty"` // sing-box实例名称，为空时为默认实例
 	unknownFields   protoimpl.UnknownFields
 	sizeCache       protoimpl.SizeCache
 }
 
 func (x *MultiplexConfigRequest) Reset() {
 	*x = MultiplexConfigRequest{}
-	mi := &file_proto_agent_proto_msgTypes[20]
+	mi := &file_proto_agent_proto_msgTypes[22]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1441,7 +1740,7 @@ func (x *MultiplexConfigRequest) String() string {
 func (*MultiplexConfigRequest) ProtoMessage() {}
 
 func (x *MultiplexConfigRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_agent_proto_msgTypes[20]
+	mi := &file_proto_agent_proto_msgTypes[22]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1454,7 +1753,7 @@ func (x *MultiplexConfigRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use MultiplexConfigRequest.ProtoReflect.Descriptor instead.
 func (*MultiplexConfigRequest) Descriptor() ([]byte, []int) {
-	return file_proto_agent_proto_rawDescGZIP(), []int{20}
+	return file_proto_agent_proto_rawDescGZIP(), []int{22}
 }
 
 func (x *MultiplexConfigRequest) GetAgentId() string {
@@ -1478,6 +1777,13 @@ func (x *MultiplexConfigRequest) GetMultiplexConfig() *MultiplexConfig {
 	return nil
 }
 
+func (x *MultiplexConfigRequest) GetInstance() string {
+	if x != nil {
+		return x.Instance
+	}
+	return ""
+}
+
 // 多路复用配置响应
 type MultiplexConfigResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -1490,7 +1796,7 @@ type MultiplexConfigResponse struct {
 
 func (x *MultiplexConfigResponse) Reset() {
 	*x = MultiplexConfigResponse{}
-	mi := &file_proto_agent_proto_msgTypes[21]
+	mi := &file_proto_agent_proto_msgTypes[23]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1502,7 +1808,7 @@ func (x *MultiplexConfigResponse) String() string {
 func (*MultiplexConfigResponse) ProtoMessage() {}
 
 func (x *MultiplexConfigResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_agent_proto_msgTypes[21]
+	mi := &file_proto_agent_proto_msgTypes[23]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1515,7 +1821,7 @@ func (x *MultiplexConfigResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use MultiplexConfigResponse.ProtoReflect.Descriptor instead.
 func (*MultiplexConfigResponse) Descriptor() ([]byte, []int) {
-	return file_proto_agent_proto_rawDescGZIP(), []int{21}
+	return file_proto_agent_proto_rawDescGZIP(), []int{23}
 }
 
 func (x *MultiplexConfigResponse) GetSuccess() bool {
@@ -1544,13 +1850,14 @@ type MultiplexStatusRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
 	Protocol      string                 `protobuf:"bytes,2,opt,name=protocol,proto3" json:"protocol,omitempty"` // 如果为空，返回所有协议的配置
+	Instance      string                 `protobuf:"bytes,3,opt,name=instance,proto3" json:"instance,omitempty"` // sing-box实例名称，为空时为默认实例
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *MultiplexStatusRequest) Reset() {
 	*x = MultiplexStatusRequest{}
-	mi := &file_proto_agent_proto_msgTypes[22]
+	mi := &file_proto_agent_proto_msgTypes[24]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1562,7 +1869,7 @@ func (x *MultiplexStatusRequest) String() string {
 func (*MultiplexStatusRequest) ProtoMessage() {}
 
 func (x *MultiplexStatusRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_agent_proto_msgTypes[22]
+	mi := &file_proto_agent_proto_msgTypes[24]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1575,7 +1882,7 @@ func (x *MultiplexStatusRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use MultiplexStatusRequest.ProtoReflect.Descriptor instead.
 func (*MultiplexStatusRequest) Descriptor() ([]byte, []int) {
-	return file_proto_agent_proto_rawDescGZIP(), []int{22}
+	return file_proto_agent_proto_rawDescGZIP(), []int{24}
 }
 
 func (x *MultiplexStatusRequest) GetAgentId() string {
@@ -1592,6 +1899,13 @@ func (x *MultiplexStatusRequest) GetProtocol() string {
 	return ""
 }
 
+func (x *MultiplexStatusRequest) GetInstance() string {
+	if x != nil {
+		return x.Instance
+	}
+	return ""
+}
+
 // 多路复用状态响应
 type MultiplexStatusResponse struct {
 	state            protoimpl.MessageState `protogen:"open.v1"`
@@ -1604,7 +1918,7 @@ type MultiplexStatusResponse struct {
 
 func (x *MultiplexStatusResponse) Reset() {
 	*x = MultiplexStatusResponse{}
-	mi := &file_proto_agent_proto_msgTypes[23]
+	mi := &file_proto_agent_proto_msgTypes[25]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1616,7 +1930,7 @@ func (x *MultiplexStatusResponse) String() string {
 func (*MultiplexStatusResponse) ProtoMessage() {}
 
 func (x *MultiplexStatusResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_agent_proto_msgTypes[23]
+	mi := &file_proto_agent_proto_msgTypes[25]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1629,7 +1943,7 @@ func (x *MultiplexStatusResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use MultiplexStatusResponse.ProtoReflect.Descriptor instead.
 func (*MultiplexStatusResponse) Descriptor() ([]byte, []int) {
-	return file_proto_agent_proto_rawDescGZIP(), []int{23}
+	return file_proto_agent_proto_rawDescGZIP(), []int{25}
 }
 
 func (x *MultiplexStatusResponse) GetSuccess() bool {
@@ -1668,7 +1982,7 @@ type MultiplexConfig struct {
 
 func (x *MultiplexConfig) Reset() {
 	*x = MultiplexConfig{}
-	mi := &file_proto_agent_proto_msgTypes[24]
+	mi := &file_proto_agent_proto_msgTypes[26]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1680,7 +1994,7 @@ func (x *MultiplexConfig) String() string {
 func (*MultiplexConfig) ProtoMessage() {}
 
 func (x *MultiplexConfig) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_agent_proto_msgTypes[24]
+	mi := &file_proto_agent_proto_msgTypes[26]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1693,7 +2007,7 @@ func (x *MultiplexConfig) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use MultiplexConfig.ProtoReflect.Descriptor instead.
 func (*MultiplexConfig) Descriptor() ([]byte, []int) {
-	return file_proto_agent_proto_rawDescGZIP(), []int{24}
+	return file_proto_agent_proto_rawDescGZIP(), []int{26}
 }
 
 func (x *MultiplexConfig) GetEnabled() bool {
@@ -1751,7 +2065,7 @@ type ProtocolMultiplex struct {
 
 func (x *ProtocolMultiplex) Reset() {
 	*x = ProtocolMultiplex{}
-	mi := &file_proto_agent_proto_msgTypes[25]
+	mi := &file_proto_agent_proto_msgTypes[27]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1763,7 +2077,7 @@ func (x *ProtocolMultiplex) String() string {
 func (*ProtocolMultiplex) ProtoMessage() {}
 
 func (x *ProtocolMultiplex) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_agent_proto_msgTypes[25]
+	mi := &file_proto_agent_proto_msgTypes[27]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1776,7 +2090,7 @@ func (x *ProtocolMultiplex) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ProtocolMultiplex.ProtoReflect.Descriptor instead.
 func (*ProtocolMultiplex) Descriptor() ([]byte, []int) {
-	return file_proto_agent_proto_rawDescGZIP(), []int{25}
+	return file_proto_agent_proto_rawDescGZIP(), []int{27}
 }
 
 func (x *ProtocolMultiplex) GetProtocol() string {
@@ -1823,7 +2137,7 @@ type IPRangeInfo struct {
 
 func (x *IPRangeInfo) Reset() {
 	*x = IPRangeInfo{}
-	mi := &file_proto_agent_proto_msgTypes[26]
+	mi := &file_proto_agent_proto_msgTypes[28]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1835,7 +2149,7 @@ func (x *IPRangeInfo) String() string {
 func (*IPRangeInfo) ProtoMessage() {}
 
 func (x *IPRangeInfo) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_agent_proto_msgTypes[26]
+	mi := &file_proto_agent_proto_msgTypes[28]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1848,7 +2162,7 @@ func (x *IPRangeInfo) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use IPRangeInfo.ProtoReflect.Descriptor instead.
 func (*IPRangeInfo) Descriptor() ([]byte, []int) {
-	return file_proto_agent_proto_rawDescGZIP(), []int{26}
+	return file_proto_agent_proto_rawDescGZIP(), []int{28}
 }
 
 func (x *IPRangeInfo) GetIpRange() string {
@@ -1913,7 +2227,7 @@ type UninstallRequest struct {
 
 func (x *UninstallRequest) Reset() {
 	*x = UninstallRequest{}
-	mi := &file_proto_agent_proto_msgTypes[27]
+	mi := &file_proto_agent_proto_msgTypes[29]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1925,7 +2239,7 @@ func (x *UninstallRequest) String() string {
 func (*UninstallRequest) ProtoMessage() {}
 
 func (x *UninstallRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_agent_proto_msgTypes[27]
+	mi := &file_proto_agent_proto_msgTypes[29]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1938,7 +2252,7 @@ func (x *UninstallRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use UninstallRequest.ProtoReflect.Descriptor instead.
 func (*UninstallRequest) Descriptor() ([]byte, []int) {
-	return file_proto_agent_proto_rawDescGZIP(), []int{27}
+	return file_proto_agent_proto_rawDescGZIP(), []int{29}
 }
 
 func (x *UninstallRequest) GetAgentId() string {
@@ -1983,7 +2297,7 @@ type UninstallResponse struct {
 
 func (x *UninstallResponse) Reset() {
 	*x = UninstallResponse{}
-	mi := &file_proto_agent_proto_msgTypes[28]
+	mi := &file_proto_agent_proto_msgTypes[30]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1995,7 +2309,7 @@ func (x *UninstallResponse) String() string {
 func (*UninstallResponse) ProtoMessage() {}
 
 func (x *UninstallResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_agent_proto_msgTypes[28]
+	mi := &file_proto_agent_proto_msgTypes[30]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2008,7 +2322,7 @@ func (x *UninstallResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use UninstallResponse.ProtoReflect.Descriptor instead.
 func (*UninstallResponse) Descriptor() ([]byte, []int) {
-	return file_proto_agent_proto_rawDescGZIP(), []int{28}
+	return file_proto_agent_proto_rawDescGZIP(), []int{30}
 }
 
 func (x *UninstallResponse) GetSuccess() bool {
@@ -2046,192 +2360,4099 @@ func (x *UninstallResponse) GetCleanupTime() int64 {
 	return 0
 }
 
-var File_proto_agent_proto protoreflect.FileDescriptor
+// 配置历史列表请求
+type ConfigGenerationsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	Instance      string                 `protobuf:"bytes,2,opt,name=instance,proto3" json:"instance,omitempty"` // sing-box实例名称，为空时为默认实例
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
 
-const file_proto_agent_proto_rawDesc = "" +
-	"\n" +
-	"\x11proto/agent.proto\x12\x05agent\"\xb8\x02\n" +
-	"\x0fRegisterRequest\x12\x19\n" +
-	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\x1a\n" +
-	"\bhostname\x18\x02 \x01(\tR\bhostname\x12\x1d\n" +
-	"\n" +
-	"ip_address\x18\x03 \x01(\tR\tipAddress\x12\x18\n" +
-	"\aversion\x18\x04 \x01(\tR\aversion\x12@\n" +
-	"\bmetadata\x18\x05 \x03(\v2$.agent.RegisterRequest.MetadataEntryR\bmetadata\x126\n" +
-	"\rip_range_info\x18\x06 \x01(\v2\x12.agent.IPRangeInfoR\vipRangeInfo\x1a;\n" +
-	"\rMetadataEntry\x12\x10\n" +
-	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\\\n" +
-	"\x10RegisterResponse\x12\x18\n" +
-	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
-	"\amessage\x18\x02 \x01(\tR\amessage\x12\x14\n" +
-	"\x05token\x18\x03 \x01(\tR\x05token\"\xf9\x01\n" +
-	"\x10HeartbeatRequest\x12\x19\n" +
-	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\x16\n" +
-	"\x06status\x18\x02 \x01(\tR\x06status\x12>\n" +
-	"\ametrics\x18\x03 \x03(\v2$.agent.HeartbeatRequest.MetricsEntryR\ametrics\x126\n" +
-	"\rip_range_info\x18\x04 \x01(\v2\x12.agent.IPRangeInfoR\vipRangeInfo\x1a:\n" +
-	"\fMetricsEntry\x12\x10\n" +
-	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\x7f\n" +
-	"\x11HeartbeatResponse\x12\x18\n" +
-	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
-	"\amessage\x18\x02 \x01(\tR\amessage\x126\n" +
-	"\x17next_heartbeat_interval\x18\x03 \x01(\x03R\x15nextHeartbeatInterval\"\x9b\x01\n" +
-	"\rConfigRequest\x12\x19\n" +
-	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12%\n" +
-	"\x0econfig_content\x18\x02 \x01(\tR\rconfigContent\x12%\n" +
-	"\x0econfig_version\x18\x03 \x01(\tR\rconfigVersion\x12!\n" +
-	"\fforce_update\x18\x04 \x01(\bR\vforceUpdate\"m\n" +
-	"\x0eConfigResponse\x12\x18\n" +
-	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
-	"\amessage\x18\x02 \x01(\tR\amessage\x12'\n" +
-	"\x0fapplied_version\x18\x03 \x01(\tR\x0eappliedVersion\"j\n" +
-	"\fRulesRequest\x12\x19\n" +
-	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12!\n" +
-	"\x05rules\x18\x02 \x03(\v2\v.agent.RuleR\x05rules\x12\x1c\n" +
-	"\toperation\x18\x03 \x01(\tR\toperation\"f\n" +
-	"\rRulesResponse\x12\x18\n" +
-	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
-	"\amessage\x18\x02 \x01(\tR\amessage\x12!\n" +
-	"\ffailed_rules\x18\x03 \x03(\tR\vfailedRules\"*\n" +
-	"\rStatusRequest\x12\x19\n" +
-	"\bagent_id\x18\x01 \x01(\tR\aagentId\"\xdb\x02\n" +
-	"\x0eStatusResponse\x12\x18\n" +
-	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x19\n" +
-	"\bagent_id\x18\x02 \x01(\tR\aagentId\x12\x16\n" +
-	"\x06status\x18\x03 \x01(\tR\x06status\x12%\n" +
-	"\x0econfig_version\x18\x04 \x01(\tR\rconfigVersion\x12\x1f\n" +
-	"\vrules_count\x18\x05 \x01(\x05R\n" +
-	"rulesCount\x12F\n" +
-	"\vsystem_info\x18\x06 \x03(\v2%.agent.StatusResponse.SystemInfoEntryR\n" +
-	"systemInfo\x12-\n" +
-	"\x12active_connections\x18\a \x03(\tR\x11activeConnections\x1a=\n" +
-	"\x0fSystemInfoEntry\x12\x10\n" +
-	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xee\x01\n" +
-	"\x04Rule\x12\x0e\n" +
-	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
-	"\x04type\x18\x02 \x01(\tR\x04type\x12\x18\n" +
-	"\acontent\x18\x03 \x01(\tR\acontent\x12\x1a\n" +
-	"\bpriority\x18\x04 \x01(\x05R\bpriority\x12\x18\n" +
-	"\aenabled\x18\x05 \x01(\bR\aenabled\x125\n" +
-	"\bmetadata\x18\x06 \x03(\v2\x19.agent.Rule.MetadataEntryR\bmetadata\x1a;\n" +
-	"\rMetadataEntry\x12\x10\n" +
-	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xa9\x01\n" +
-	"\x10BlacklistRequest\x12\x19\n" +
-	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\x1a\n" +
-	"\bprotocol\x18\x02 \x01(\tR\bprotocol\x12\x18\n" +
-	"\adomains\x18\x03 \x03(\tR\adomains\x12\x10\n" +
-	"\x03ips\x18\x04 \x03(\tR\x03ips\x12\x14\n" +
-	"\x05ports\x18\x05 \x03(\tR\x05ports\x12\x1c\n" +
-	"\toperation\x18\x06 \x01(\tR\toperation\"n\n" +
-	"\x11BlacklistResponse\x12\x18\n" +
-	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
-	"\amessage\x18\x02 \x01(\tR\amessage\x12%\n" +
-	"\x0econfig_version\x18\x03 \x01(\tR\rconfigVersion\"\xa9\x01\n" +
-	"\x10WhitelistRequest\x12\x19\n" +
-	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\x1a\n" +
-	"\bprotocol\x18\x02 \x01(\tR\bprotocol\x12\x18\n" +
-	"\adomains\x18\x03 \x03(\tR\adomains\x12\x10\n" +
-	"\x03ips\x18\x04 \x03(\tR\x03ips\x12\x14\n" +
-	"\x05ports\x18\x05 \x03(\tR\x05ports\x12\x1c\n" +
-	"\toperation\x18\x06 \x01(\tR\toperation\"n\n" +
-	"\x11WhitelistResponse\x12\x18\n" +
-	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
-	"\amessage\x18\x02 \x01(\tR\amessage\x12%\n" +
-	"\x0econfig_version\x18\x03 \x01(\tR\rconfigVersion\"L\n" +
-	"\x13FilterConfigRequest\x12\x19\n" +
-	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\x1a\n" +
-	"\bprotocol\x18\x02 \x01(\tR\bprotocol\"{\n" +
-	"\x14FilterConfigResponse\x12\x18\n" +
-	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
-	"\amessage\x18\x02 \x01(\tR\amessage\x12/\n" +
-	"\afilters\x18\x03 \x03(\v2\x15.agent.ProtocolFilterR\afilters\"\xdf\x02\n" +
-	"\x0eProtocolFilter\x12\x1a\n" +
-	"\bprotocol\x18\x01 \x01(\tR\bprotocol\x12+\n" +
-	"\x11blacklist_domains\x18\x02 \x03(\tR\x10blacklistDomains\x12#\n" +
-	"\rblacklist_ips\x18\x03 \x03(\tR\fblacklistIps\x12'\n" +
-	"\x0fblacklist_ports\x18\x04 \x03(\tR\x0eblacklistPorts\x12+\n" +
-	"\x11whitelist_domains\x18\x05 \x03(\tR\x10whitelistDomains\x12#\n" +
-	"\rwhitelist_ips\x18\x06 \x03(\tR\fwhitelistIps\x12'\n" +
-	"\x0fwhitelist_ports\x18\a \x03(\tR\x0ewhitelistPorts\x12\x18\n" +
-	"\aenabled\x18\b \x01(\bR\aenabled\x12!\n" +
-	"\flast_updated\x18\t \x01(\tR\vlastUpdated\"k\n" +
-	"\x0fRollbackRequest\x12\x19\n" +
-	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12%\n" +
-	"\x0etarget_version\x18\x02 \x01(\tR\rtargetVersion\x12\x16\n" +
-	"\x06reason\x18\x03 \x01(\tR\x06reason\"\x9f\x01\n" +
-	"\x10RollbackResponse\x12\x18\n" +
-	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
-	"\amessage\x18\x02 \x01(\tR\amessage\x12.\n" +
-	"\x13rolled_back_version\x18\x03 \x01(\tR\x11rolledBackVersion\x12'\n" +
-	"\x0fcurrent_version\x18\x04 \x01(\tR\x0ecurrentVersion\"\x92\x01\n" +
-	"\x16MultiplexConfigRequest\x12\x19\n" +
-	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\x1a\n" +
-	"\bprotocol\x18\x02 \x01(\tR\bprotocol\x12A\n" +
-	"\x10multiplex_config\x18\x03 \x01(\v2\x16.agent.MultiplexConfigR\x0fmultiplexConfig\"t\n" +
-	"\x17MultiplexConfigResponse\x12\x18\n" +
-	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
-	"\amessage\x18\x02 \x01(\tR\amessage\x12%\n" +
-	"\x0econfig_version\x18\x03 \x01(\tR\rconfigVersion\"O\n" +
-	"\x16MultiplexStatusRequest\x12\x19\n" +
-	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\x1a\n" +
-	"\bprotocol\x18\x02 \x01(\tR\bprotocol\"\x94\x01\n" +
-	"\x17MultiplexStatusResponse\x12\x18\n" +
-	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
-	"\amessage\x18\x02 \x01(\tR\amessage\x12E\n" +
-	"\x11multiplex_configs\x18\x03 \x03(\v2\x18.agent.ProtocolMultiplexR\x10multiplexConfigs\"\xa2\x02\n" +
-	"\x0fMultiplexConfig\x12\x18\n" +
-	"\aenabled\x18\x01 \x01(\bR\aenabled\x12\x1a\n" +
-	"\bprotocol\x18\x02 \x01(\tR\bprotocol\x12'\n" +
-	"\x0fmax_connections\x18\x03 \x01(\x05R\x0emaxConnections\x12\x1f\n" +
-	"\vmin_streams\x18\x04 \x01(\x05R\n" +
-	"minStreams\x12\x18\n" +
-	"\apadding\x18\x05 \x01(\bR\apadding\x12:\n" +
-	"\x06brutal\x18\x06 \x03(\v2\".agent.MultiplexConfig.BrutalEntryR\x06brutal\x1a9\n" +
-	"\vBrutalEntry\x12\x10\n" +
-	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xaf\x01\n" +
-	"\x11ProtocolMultiplex\x12\x1a\n" +
-	"\bprotocol\x18\x01 \x01(\tR\bprotocol\x12A\n" +
-	"\x10multiplex_config\x18\x02 \x01(\v2\x16.agent.MultiplexConfigR\x0fmultiplexConfig\x12\x18\n" +
-	"\aenabled\x18\x03 \x01(\bR\aenabled\x12!\n" +
-	"\flast_updated\x18\x04 \x01(\tR\vlastUpdated\"\xcc\x01\n" +
-	"\vIPRangeInfo\x12\x19\n" +
-	"\bip_range\x18\x01 \x01(\tR\aipRange\x12\x18\n" +
-	"\acountry\x18\x02 \x01(\tR\acountry\x12\x16\n" +
-	"\x06region\x18\x03 \x01(\tR\x06region\x12\x12\n" +
-	"\x04city\x18\x04 \x01(\tR\x04city\x12\x10\n" +
-	"\x03isp\x18\x05 \x01(\tR\x03isp\x12)\n" +
-	"\x10detection_method\x18\x06 \x01(\tR\x0fdetectionMethod\x12\x1f\n" +
-	"\vdetected_at\x18\a \x01(\tR\n" +
-	"detectedAt\"\x97\x01\n" +
-	"\x10UninstallRequest\x12\x19\n" +
-	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12'\n" +
-	"\x0fforce_uninstall\x18\x02 \x01(\bR\x0eforceUninstall\x12\x16\n" +
-	"\x06reason\x18\x03 \x01(\tR\x06reason\x12'\n" +
-	"\x0ftimeout_seconds\x18\x04 \x01(\x05R\x0etimeoutSeconds\"\xba\x01\n" +
-	"\x11UninstallResponse\x12\x18\n" +
-	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
-	"\amessage\x18\x02 \x01(\tR\amessage\x12)\n" +
-	"\x10uninstall_status\x18\x03 \x01(\tR\x0funinstallStatus\x12#\n" +
-	"\rcleaned_files\x18\x04 \x03(\tR\fcleanedFiles\x12!\n" +
-	"\fcleanup_time\x18\x05 \x01(\x03R\vcleanupTime2\xce\x06\n" +
-	"\fAgentService\x12@\n" +
-	"\rRegisterAgent\x12\x16.agent.RegisterRequest\x1a\x17.agent.RegisterResponse\x12>\n" +
-	"\tHeartbeat\x12\x17.agent.HeartbeatRequest\x1a\x18.agent.HeartbeatResponse\x12;\n" +
-	"\fUpdateConfig\x12\x14.agent.ConfigRequest\x1a\x15.agent.ConfigResponse\x128\n" +
-	"\vUpdateRules\x12\x13.agent.RulesRequest\x1a\x14.agent.RulesResponse\x128\n" +
-	"\tGetStatus\x12\x14.agent.StatusRequest\x1a\x15.agent.StatusResponse\x12D\n" +
-	"\x0fUpdateBlacklist\x12\x17.agent.BlacklistRequest\x1a\x18.agent.BlacklistResponse\x12D\n" +
-	"\x0fUpdateWhitelist\x12\x17.agent.WhitelistRequest\x1a\x18.agent.WhitelistResponse\x12J\n" +
+func (x *ConfigGenerationsRequest) Reset() {
+	*x = ConfigGenerationsRequest{}
+	mi := &file_proto_agent_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ConfigGenerationsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConfigGenerationsRequest) ProtoMessage() {}
+
+func (x *ConfigGenerationsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_agent_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConfigGenerationsRequest.ProtoReflect.Descriptor instead.
+func (*ConfigGenerationsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_agent_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *ConfigGenerationsRequest) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *ConfigGenerationsRequest) GetInstance() string {
+	if x != nil {
+		return x.Instance
+	}
+	return ""
+}
+
+// 一代sing-box配置的元数据
+type ConfigGeneration struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Version       int64                  `protobuf:"varint,1,opt,name=version,proto3" json:"version,omitempty"`
+	AppliedAt     string                 `protobuf:"bytes,2,opt,name=applied_at,json=appliedAt,proto3" json:"applied_at,omitempty"` // 生效时间（RFC3339）
+	Source        string                 `protobuf:"bytes,3,opt,name=source,proto3" json:"source,omitempty"`                        // 配置来源: grpc, filter, multiplex, rollback:<version>, initial
+	Sha256        string                 `protobuf:"bytes,4,opt,name=sha256,proto3" json:"sha256,omitempty"`
+	Size          int64                  `protobuf:"varint,5,opt,name=size,proto3" json:"size,omitempty"`       // 字节数
+	Current       bool                   `protobuf:"varint,6,opt,name=current,proto3" json:"current,omitempty"` // 是否为当前生效的配置代
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ConfigGeneration) Reset() {
+	*x = ConfigGeneration{}
+	mi := &file_proto_agent_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ConfigGeneration) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConfigGeneration) ProtoMessage() {}
+
+func (x *ConfigGeneration) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_agent_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConfigGeneration.ProtoReflect.Descriptor instead.
+func (*ConfigGeneration) Descriptor() ([]byte, []int) {
+	return file_proto_agent_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *ConfigGeneration) GetVersion() int64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *ConfigGeneration) GetAppliedAt() string {
+	if x != nil {
+		return x.AppliedAt
+	}
+	return ""
+}
+
+func (x *ConfigGeneration) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *ConfigGeneration) GetSha256() string {
+	if x != nil {
+		return x.Sha256
+	}
+	return ""
+}
+
+func (x *ConfigGeneration) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *ConfigGeneration) GetCurrent() bool {
+	if x != nil {
+		return x.Current
+	}
+	return false
+}
+
+// 配置历史列表响应
+type ConfigGenerationsResponse struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Success        bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Message        string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	Generations    []*ConfigGeneration    `protobuf:"bytes,3,rep,name=generations,proto3" json:"generations,omitempty"` // 按版本升序
+	CurrentVersion int64                  `protobuf:"varint,4,opt,name=current_version,json=currentVersion,proto3" json:"current_version,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *ConfigGenerationsResponse) Reset() {
+	*x = ConfigGenerationsResponse{}
+	mi := &file_proto_agent_proto_msgTypes[33]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ConfigGenerationsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConfigGenerationsResponse) ProtoMessage() {}
+
+func (x *ConfigGenerationsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_agent_proto_msgTypes[33]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConfigGenerationsResponse.ProtoReflect.Descriptor instead.
+func (*ConfigGenerationsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_agent_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *ConfigGenerationsResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *ConfigGenerationsResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *ConfigGenerationsResponse) GetGenerations() []*ConfigGeneration {
+	if x != nil {
+		return x.Generations
+	}
+	return nil
+}
+
+func (x *ConfigGenerationsResponse) GetCurrentVersion() int64 {
+	if x != nil {
+		return x.CurrentVersion
+	}
+	return 0
+}
+
+// 配置diff请求
+type ConfigDiffRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	FromVersion   int64                  `protobuf:"varint,2,opt,name=from_version,json=fromVersion,proto3" json:"from_version,omitempty"`
+	ToVersion     int64                  `protobuf:"varint,3,opt,name=to_version,json=toVersion,proto3" json:"to_version,omitempty"` // 0表示当前代
+	Instance      string                 `protobuf:"bytes,4,opt,name=instance,proto3" json:"instance,omitempty"`                     // sing-box实例名称，为空时为默认实例
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ConfigDiffRequest) Reset() {
+	*x = ConfigDiffRequest{}
+	mi := &file_proto_agent_proto_msgTypes[34]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ConfigDiffRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConfigDiffRequest) ProtoMessage() {}
+
+func (x *ConfigDiffRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_agent_proto_msgTypes[34]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConfigDiffRequest.ProtoReflect.Descriptor instead.
+func (*ConfigDiffRequest) Descriptor() ([]byte, []int) {
+	return file_proto_agent_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *ConfigDiffRequest) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *ConfigDiffRequest) GetFromVersion() int64 {
+	if x != nil {
+		return x.FromVersion
+	}
+	return 0
+}
+
+func (x *ConfigDiffRequest) GetToVersion() int64 {
+	if x != nil {
+		return x.ToVersion
+	}
+	return 0
+}
+
+func (x *ConfigDiffRequest) GetInstance() string {
+	if x != nil {
+		return x.Instance
+	}
+	return ""
+}
+
+// 配置diff响应
+type ConfigDiffResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	Diff          string                 `protobuf:"bytes,3,opt,name=diff,proto3" json:"diff,omitempty"` // unified diff，内容相同时为空
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ConfigDiffResponse) Reset() {
+	*x = ConfigDiffResponse{}
+	mi := &file_proto_agent_proto_msgTypes[35]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ConfigDiffResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConfigDiffResponse) ProtoMessage() {}
+
+func (x *ConfigDiffResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_agent_proto_msgTypes[35]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConfigDiffResponse.ProtoReflect.Descriptor instead.
+func (*ConfigDiffResponse) Descriptor() ([]byte, []int) {
+	return file_proto_agent_proto_rawDescGZIP(), []int{35}
+}
+
+func (x *ConfigDiffResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *ConfigDiffResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *ConfigDiffResponse) GetDiff() string {
+	if x != nil {
+		return x.Diff
+	}
+	return ""
+}
+
+// sing-box日志流请求
+type LogStreamRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	Level         string                 `protobuf:"bytes,2,opt,name=level,proto3" json:"level,omitempty"`       // 最低日志级别: trace, debug, info, warn, error, fatal, panic，为空不过滤
+	Keyword       string                 `protobuf:"bytes,3,opt,name=keyword,proto3" json:"keyword,omitempty"`   // 关键字过滤（不区分大小写）
+	Tail          int32                  `protobuf:"varint,4,opt,name=tail,proto3" json:"tail,omitempty"`        // 先返回最近的行数
+	Follow        bool                   `protobuf:"varint,5,opt,name=follow,proto3" json:"follow,omitempty"`    // 是否持续推送新日志
+	Instance      string                 `protobuf:"bytes,6,opt,name=instance,proto3" json:"instance,omitempty"` // sing-box实例名称，为空时为默认实例
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LogStreamRequest) Reset() {
+	*x = LogStreamRequest{}
+	mi := &file_proto_agent_proto_msgTypes[36]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LogStreamRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LogStreamRequest) ProtoMessage() {}
+
+func (x *LogStreamRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_agent_proto_msgTypes[36]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LogStreamRequest.ProtoReflect.Descriptor instead.
+func (*LogStreamRequest) Descriptor() ([]byte, []int) {
+	return file_proto_agent_proto_rawDescGZIP(), []int{36}
+}
+
+func (x *LogStreamRequest) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *LogStreamRequest) GetLevel() string {
+	if x != nil {
+		return x.Level
+	}
+	return ""
+}
+
+func (x *LogStreamRequest) GetKeyword() string {
+	if x != nil {
+		return x.Keyword
+	}
+	return ""
+}
+
+func (x *LogStreamRequest) GetTail() int32 {
+	if x != nil {
+		return x.Tail
+	}
+	return 0
+}
+
+func (x *LogStreamRequest) GetFollow() bool {
+	if x != nil {
+		return x.Follow
+	}
+	return false
+}
+
+func (x *LogStreamRequest) GetInstance() string {
+	if x != nil {
+		return x.Instance
+	}
+	return ""
+}
+
+// 一行sing-box日志
+type SingboxLogEntry struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Seq           uint64                 `protobuf:"varint,1,opt,name=seq,proto3" json:"seq,omitempty"`
+	Timestamp     string                 `protobuf:"bytes,2,opt,name=timestamp,proto3" json:"timestamp,omitempty"` // RFC3339Nano
+	Level         string                 `protobuf:"bytes,3,opt,name=level,proto3" json:"level,omitempty"`
+	Message       string                 `protobuf:"bytes,4,opt,name=message,proto3" json:"message,omitempty"`
+	Stream        string                 `protobuf:"bytes,5,opt,name=stream,proto3" json:"stream,omitempty"` // stdout, stderr
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SingboxLogEntry) Reset() {
+	*x = SingboxLogEntry{}
+	mi := &file_proto_agent_proto_msgTypes[37]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SingboxLogEntry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SingboxLogEntry) ProtoMessage() {}
+
+func (x *SingboxLogEntry) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_agent_proto_msgTypes[37]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SingboxLogEntry.ProtoReflect.Descriptor instead.
+func (*SingboxLogEntry) Descriptor() ([]byte, []int) {
+	return file_proto_agent_proto_rawDescGZIP(), []int{37}
+}
+
+func (x *SingboxLogEntry) GetSeq() uint64 {
+	if x != nil {
+		return x.Seq
+	}
+	return 0
+}
+
+func (x *SingboxLogEntry) GetTimestamp() string {
+	if x != nil {
+		return x.Timestamp
+	}
+	return ""
+}
+
+func (x *SingboxLogEntry) GetLevel() string {
+	if x != nil {
+		return x.Level
+	}
+	return ""
+}
+
+func (x *SingboxLogEntry) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *SingboxLogEntry) GetStream() string {
+	if x != nil {
+		return x.Stream
+	}
+	return ""
+}
+
+// 入站用户，不同协议使用的字段不同
+type InboundUser struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`                       // vmess, vless, trojan, shadowsocks, hysteria2, tuic
+	Username      string                 `protobuf:"bytes,2,opt,name=username,proto3" json:"username,omitempty"`               // mixed, http, socks
+	Password      string                 `protobuf:"bytes,3,opt,name=password,proto3" json:"password,omitempty"`               // mixed, http, socks, trojan, shadowsocks, hysteria2, tuic
+	Uuid          string                 `protobuf:"bytes,4,opt,name=uuid,proto3" json:"uuid,omitempty"`                       // vmess, vless, tuic
+	Flow          string                 `protobuf:"bytes,5,opt,name=flow,proto3" json:"flow,omitempty"`                       // vless
+	AlterId       int32                  `protobuf:"varint,6,opt,name=alter_id,json=alterId,proto3" json:"alter_id,omitempty"` // vmess
+	Extra         string                 `protobuf:"bytes,7,opt,name=extra,proto3" json:"extra,omitempty"`                     // 未建模的用户字段（JSON对象），原样写回配置
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *InboundUser) Reset() {
+	*x = InboundUser{}
+	mi := &file_proto_agent_proto_msgTypes[38]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *InboundUser) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InboundUser) ProtoMessage() {}
+
+func (x *InboundUser) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_agent_proto_msgTypes[38]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InboundUser.ProtoReflect.Descriptor instead.
+func (*InboundUser) Descriptor() ([]byte, []int) {
+	return file_proto_agent_proto_rawDescGZIP(), []int{38}
+}
+
+func (x *InboundUser) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *InboundUser) GetUsername() string {
+	if x != nil {
+		return x.Username
+	}
+	return ""
+}
+
+func (x *InboundUser) GetPassword() string {
+	if x != nil {
+		return x.Password
+	}
+	return ""
+}
+
+func (x *InboundUser) GetUuid() string {
+	if x != nil {
+		return x.Uuid
+	}
+	return ""
+}
+
+func (x *InboundUser) GetFlow() string {
+	if x != nil {
+		return x.Flow
+	}
+	return ""
+}
+
+func (x *InboundUser) GetAlterId() int32 {
+	if x != nil {
+		return x.AlterId
+	}
+	return 0
+}
+
+func (x *InboundUser) GetExtra() string {
+	if x != nil {
+		return x.Extra
+	}
+	return ""
+}
+
+// 入站用户更新请求
+type InboundUsersRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	InboundTag    string                 `protobuf:"bytes,2,opt,name=inbound_tag,json=inboundTag,proto3" json:"inbound_tag,omitempty"`
+	Operation     string                 `protobuf:"bytes,3,opt,name=operation,proto3" json:"operation,omitempty"` // add, remove, replace
+	Users         []*InboundUser         `protobuf:"bytes,4,rep,name=users,proto3" json:"users,omitempty"`
+	Instance      string                 `protobuf:"bytes,5,opt,name=instance,proto3" json:"instance,omitempty"` // sing-box实例名称，为空时为默认实例
+	Revision      string                 `protobuf:"bytes,6,opt,name=revision,proto3" json:"revision,omitempty"` // 非空时需与入站用户的当前修订一致，否则拒绝更新
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *InboundUsersRequest) Reset() {
+	*x = InboundUsersRequest{}
+	mi := &file_proto_agent_proto_msgTypes[39]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *InboundUsersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InboundUsersRequest) ProtoMessage() {}
+
+func (x *InboundUsersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_agent_proto_msgTypes[39]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InboundUsersRequest.ProtoReflect.Descriptor instead.
+func (*InboundUsersRequest) Descriptor() ([]byte, []int) {
+	return file_proto_agent_proto_rawDescGZIP(), []int{39}
+}
+
+func (x *InboundUsersRequest) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *InboundUsersRequest) GetInboundTag() string {
+	if x != nil {
+		return x.InboundTag
+	}
+	return ""
+}
+
+func (x *InboundUsersRequest) GetOperation() string {
+	if x != nil {
+		return x.Operation
+	}
+	return ""
+}
+
+func (x *InboundUsersRequest) GetUsers() []*InboundUser {
+	if x != nil {
+		return x.Users
+	}
+	return nil
+}
+
+func (x *InboundUsersRequest) GetInstance() string {
+	if x != nil {
+		return x.Instance
+	}
+	return ""
+}
+
+func (x *InboundUsersRequest) GetRevision() string {
+	if x != nil {
+		return x.Revision
+	}
+	return ""
+}
+
+// 入站用户查询请求
+type InboundUsersQuery struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	InboundTag    string                 `protobuf:"bytes,2,opt,name=inbound_tag,json=inboundTag,proto3" json:"inbound_tag,omitempty"`
+	Instance      string                 `protobuf:"bytes,3,opt,name=instance,proto3" json:"instance,omitempty"` // sing-box实例名称，为空时为默认实例
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *InboundUsersQuery) Reset() {
+	*x = InboundUsersQuery{}
+	mi := &file_proto_agent_proto_msgTypes[40]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *InboundUsersQuery) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InboundUsersQuery) ProtoMessage() {}
+
+func (x *InboundUsersQuery) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_agent_proto_msgTypes[40]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InboundUsersQuery.ProtoReflect.Descriptor instead.
+func (*InboundUsersQuery) Descriptor() ([]byte, []int) {
+	return file_proto_agent_proto_rawDescGZIP(), []int{40}
+}
+
+func (x *InboundUsersQuery) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *InboundUsersQuery) GetInboundTag() string {
+	if x != nil {
+		return x.InboundTag
+	}
+	return ""
+}
+
+func (x *InboundUsersQuery) GetInstance() string {
+	if x != nil {
+		return x.Instance
+	}
+	return ""
+}
+
+// 入站用户响应
+type InboundUsersResponse struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Success          bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Message          string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	InboundTag       string                 `protobuf:"bytes,3,opt,name=inbound_tag,json=inboundTag,proto3" json:"inbound_tag,omitempty"`
+	InboundType      string                 `protobuf:"bytes,4,opt,name=inbound_type,json=inboundType,proto3" json:"inbound_type,omitempty"`
+	Users            []*InboundUser         `protobuf:"bytes,5,rep,name=users,proto3" json:"users,omitempty"`                                                // 操作后的用户列表
+	Phases           []*ApplyPhase          `protobuf:"bytes,6,rep,name=phases,proto3" json:"phases,omitempty"`                                              // 更新时的应用流水线阶段结果
+	Revision         string                 `protobuf:"bytes,7,opt,name=revision,proto3" json:"revision,omitempty"`                                          // 当前用户列表的修订，读改写时随更新请求带回
+	RevisionMismatch bool                   `protobuf:"varint,8,opt,name=revision_mismatch,json=revisionMismatch,proto3" json:"revision_mismatch,omitempty"` // 入站用户已被修改，更新被拒绝
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *InboundUsersResponse) Reset() {
+	*x = InboundUsersResponse{}
+	mi := &file_proto_agent_proto_msgTypes[41]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *InboundUsersResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InboundUsersResponse) ProtoMessage() {}
+
+func (x *InboundUsersResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_agent_proto_msgTypes[41]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InboundUsersResponse.ProtoReflect.Descriptor instead.
+func (*InboundUsersResponse) Descriptor() ([]byte, []int) {
+	return file_proto_agent_proto_rawDescGZIP(), []int{41}
+}
+
+func (x *InboundUsersResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *InboundUsersResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *InboundUsersResponse) GetInboundTag() string {
+	if x != nil {
+		return x.InboundTag
+	}
+	return ""
+}
+
+func (x *InboundUsersResponse) GetInboundType() string {
+	if x != nil {
+		return x.InboundType
+	}
+	return ""
+}
+
+func (x *InboundUsersResponse) GetUsers() []*InboundUser {
+	if x != nil {
+		return x.Users
+	}
+	return nil
+}
+
+func (x *InboundUsersResponse) GetPhases() []*ApplyPhase {
+	if x != nil {
+		return x.Phases
+	}
+	return nil
+}
+
+func (x *InboundUsersResponse) GetRevision() string {
+	if x != nil {
+		return x.Revision
+	}
+	return ""
+}
+
+func (x *InboundUsersResponse) GetRevisionMismatch() bool {
+	if x != nil {
+		return x.RevisionMismatch
+	}
+	return false
+}
+
+// sing-box实例状态
+type InstanceStatus struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	Name              string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	State             string                 `protobuf:"bytes,2,opt,name=state,proto3" json:"state,omitempty"` // stopped, running, backing_off, crash_looping
+	Running           bool                   `protobuf:"varint,3,opt,name=running,proto3" json:"running,omitempty"`
+	Pid               int32                  `protobuf:"varint,4,opt,name=pid,proto3" json:"pid,omitempty"`
+	ProcessMode       string                 `protobuf:"bytes,5,opt,name=process_mode,json=processMode,proto3" json:"process_mode,omitempty"` // exec, systemd
+	BinaryPath        string                 `protobuf:"bytes,6,opt,name=binary_path,json=binaryPath,proto3" json:"binary_path,omitempty"`
+	ConfigPath        string                 `protobuf:"bytes,7,opt,name=config_path,json=configPath,proto3" json:"config_path,omitempty"`
+	ConfigVersion     int64                  `protobuf:"varint,8,opt,name=config_version,json=configVersion,proto3" json:"config_version,omitempty"` // 当前配置代版本，0表示尚无记录
+	Restarts          int32                  `protobuf:"varint,9,opt,name=restarts,proto3" json:"restarts,omitempty"`
+	ConnectionStats   *ConnectionStats       `protobuf:"bytes,10,opt,name=connection_stats,json=connectionStats,proto3" json:"connection_stats,omitempty"`
+	ResourceUsage     *ResourceUsage         `protobuf:"bytes,11,opt,name=resource_usage,json=resourceUsage,proto3" json:"resource_usage,omitempty"`                  // cgroup资源用量，未放入独立cgroup时为空
+	LastExitOomKilled bool                   `protobuf:"varint,12,opt,name=last_exit_oom_killed,json=lastExitOomKilled,proto3" json:"last_exit_oom_killed,omitempty"` // 最近一次退出是否因超过memory.max被OOM终止
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *InstanceStatus) Reset() {
+	*x = InstanceStatus{}
+	mi := &file_proto_agent_proto_msgTypes[42]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *InstanceStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InstanceStatus) ProtoMessage() {}
+
+func (x *InstanceStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_agent_proto_msgTypes[42]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InstanceStatus.ProtoReflect.Descriptor instead.
+func (*InstanceStatus) Descriptor() ([]byte, []int) {
+	return file_proto_agent_proto_rawDescGZIP(), []int{42}
+}
+
+func (x *InstanceStatus) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *InstanceStatus) GetState() string {
+	if x != nil {
+		return x.State
+	}
+	return ""
+}
+
+func (x *InstanceStatus) GetRunning() bool {
+	if x != nil {
+		return x.Running
+	}
+	return false
+}
+
+func (x *InstanceStatus) GetPid() int32 {
+	if x != nil {
+		return x.Pid
+	}
+	return 0
+}
+
+func (x *InstanceStatus) GetProcessMode() string {
+	if x != nil {
+		return x.ProcessMode
+	}
+	return ""
+}
+
+func (x *InstanceStatus) GetBinaryPath() string {
+	if x != nil {
+		return x.BinaryPath
+	}
+	return ""
+}
+
+func (x *InstanceStatus) GetConfigPath() string {
+	if x != nil {
+		return x.ConfigPath
+	}
+	return ""
+}
+
+func (x *InstanceStatus) GetConfigVersion() int64 {
+	if x != nil {
+		return x.ConfigVersion
+	}
+	return 0
+}
+
+func (x *InstanceStatus) GetRestarts() int32 {
+	if x != nil {
+		return x.Restarts
+	}
+	return 0
+}
+
+func (x *InstanceStatus) GetConnectionStats() *ConnectionStats {
+	if x != nil {
+		return x.ConnectionStats
+	}
+	return nil
+}
+
+func (x *InstanceStatus) GetResourceUsage() *ResourceUsage {
+	if x != nil {
+		return x.ResourceUsage
+	}
+	return nil
+}
+
+func (x *InstanceStatus) GetLastExitOomKilled() bool {
+	if x != nil {
+		return x.LastExitOomKilled
+	}
+	return false
+}
+
+// sing-box所在cgroup的资源用量（cgroup v2）
+type ResourceUsage struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Cgroup           string                 `protobuf:"bytes,1,opt,name=cgroup,proto3" json:"cgroup,omitempty"`
+	MemoryCurrent    int64                  `protobuf:"varint,2,opt,name=memory_current,json=memoryCurrent,proto3" json:"memory_current,omitempty"` // 字节
+	MemoryMax        int64                  `protobuf:"varint,3,opt,name=memory_max,json=memoryMax,proto3" json:"memory_max,omitempty"`             // 0表示不限制
+	CpuUsageUsec     int64                  `protobuf:"varint,4,opt,name=cpu_usage_usec,json=cpuUsageUsec,proto3" json:"cpu_usage_usec,omitempty"`
+	CpuThrottledUsec int64                  `protobuf:"varint,5,opt,name=cpu_throttled_usec,json=cpuThrottledUsec,proto3" json:"cpu_throttled_usec,omitempty"`
+	NrThrottled      int64                  `protobuf:"varint,6,opt,name=nr_throttled,json=nrThrottled,proto3" json:"nr_throttled,omitempty"`
+	PidsCurrent      int64                  `protobuf:"varint,7,opt,name=pids_current,json=pidsCurrent,proto3" json:"pids_current,omitempty"`
+	PidsMax          int64                  `protobuf:"varint,8,opt,name=pids_max,json=pidsMax,proto3" json:"pids_max,omitempty"`    // 0表示不限制
+	OomKills         int64                  `protobuf:"varint,9,opt,name=oom_kills,json=oomKills,proto3" json:"oom_kills,omitempty"` // memory.events中的oom_kill计数
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *ResourceUsage) Reset() {
+	*x = ResourceUsage{}
+	mi := &file_proto_agent_proto_msgTypes[43]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ResourceUsage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResourceUsage) ProtoMessage() {}
+
+func (x *ResourceUsage) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_agent_proto_msgTypes[43]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResourceUsage.ProtoReflect.Descriptor instead.
+func (*ResourceUsage) Descriptor() ([]byte, []int) {
+	return file_proto_agent_proto_rawDescGZIP(), []int{43}
+}
+
+func (x *ResourceUsage) GetCgroup() string {
+	if x != nil {
+		return x.Cgroup
+	}
+	return ""
+}
+
+func (x *ResourceUsage) GetMemoryCurrent() int64 {
+	if x != nil {
+		return x.MemoryCurrent
+	}
+	return 0
+}
+
+func (x *ResourceUsage) GetMemoryMax() int64 {
+	if x != nil {
+		return x.MemoryMax
+	}
+	return 0
+}
+
+func (x *ResourceUsage) GetCpuUsageUsec() int64 {
+	if x != nil {
+		return x.CpuUsageUsec
+	}
+	return 0
+}
+
+func (x *ResourceUsage) GetCpuThrottledUsec() int64 {
+	if x != nil {
+		return x.CpuThrottledUsec
+	}
+	return 0
+}
+
+func (x *ResourceUsage) GetNrThrottled() int64 {
+	if x != nil {
+		return x.NrThrottled
+	}
+	return 0
+}
+
+func (x *ResourceUsage) GetPidsCurrent() int64 {
+	if x != nil {
+		return x.PidsCurrent
+	}
+	return 0
+}
+
+func (x *ResourceUsage) GetPidsMax() int64 {
+	if x != nil {
+		return x.PidsMax
+	}
+	return 0
+}
+
+func (x *ResourceUsage) GetOomKills() int64 {
+	if x != nil {
+		return x.OomKills
+	}
+	return 0
+}
+
+// sing-box连接与流量统计
+type ConnectionStats struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Available        bool                   `protobuf:"varint,1,opt,name=available,proto3" json:"available,omitempty"` // Clash API是否可用
+	Error            string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`          // 最近一次采集失败的原因
+	TotalConnections int32                  `protobuf:"varint,3,opt,name=total_connections,json=totalConnections,proto3" json:"total_connections,omitempty"`
+	UploadRate       int64                  `protobuf:"varint,4,opt,name=upload_rate,json=uploadRate,proto3" json:"upload_rate,omitempty"`       // 字节/秒
+	DownloadRate     int64                  `protobuf:"varint,5,opt,name=download_rate,json=downloadRate,proto3" json:"download_rate,omitempty"` // 字节/秒
+	UploadTotal      int64                  `protobuf:"varint,6,opt,name=upload_total,json=uploadTotal,proto3" json:"upload_total,omitempty"`    // sing-box启动以来的累计字节
+	DownloadTotal    int64                  `protobuf:"varint,7,opt,name=download_total,json=downloadTotal,proto3" json:"download_total,omitempty"`
+	Inbounds         []*TagTraffic          `protobuf:"bytes,8,rep,name=inbounds,proto3" json:"inbounds,omitempty"`
+	Outbounds        []*TagTraffic          `protobuf:"bytes,9,rep,name=outbounds,proto3" json:"outbounds,omitempty"`
+	CollectedAt      int64                  `protobuf:"varint,10,opt,name=collected_at,json=collectedAt,proto3" json:"collected_at,omitempty"` // 采集时间（Unix秒）
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *ConnectionStats) Reset() {
+	*x = ConnectionStats{}
+	mi := &file_proto_agent_proto_msgTypes[44]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ConnectionStats) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConnectionStats) ProtoMessage() {}
+
+func (x *ConnectionStats) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_agent_proto_msgTypes[44]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConnectionStats.ProtoReflect.Descriptor instead.
+func (*ConnectionStats) Descriptor() ([]byte, []int) {
+	return file_proto_agent_proto_rawDescGZIP(), []int{44}
+}
+
+func (x *ConnectionStats) GetAvailable() bool {
+	if x != nil {
+		return x.Available
+	}
+	return false
+}
+
+func (x *ConnectionStats) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *ConnectionStats) GetTotalConnections() int32 {
+	if x != nil {
+		return x.TotalConnections
+	}
+	return 0
+}
+
+func (x *ConnectionStats) GetUploadRate() int64 {
+	if x != nil {
+		return x.UploadRate
+	}
+	return 0
+}
+
+func (x *ConnectionStats) GetDownloadRate() int64 {
+	if x != nil {
+		return x.DownloadRate
+	}
+	return 0
+}
+
+func (x *ConnectionStats) GetUploadTotal() int64 {
+	if x != nil {
+		return x.UploadTotal
+	}
+	return 0
+}
+
+func (x *ConnectionStats) GetDownloadTotal() int64 {
+	if x != nil {
+		return x.DownloadTotal
+	}
+	return 0
+}
+
+func (x *ConnectionStats) GetInbounds() []*TagTraffic {
+	if x != nil {
+		return x.Inbounds
+	}
+	return nil
+}
+
+func (x *ConnectionStats) GetOutbounds() []*TagTraffic {
+	if x != nil {
+		return x.Outbounds
+	}
+	return nil
+}
+
+func (x *ConnectionStats) GetCollectedAt() int64 {
+	if x != nil {
+		return x.CollectedAt
+	}
+	return 0
+}
+
+// 按入站或出站tag聚合的连接与流量
+type TagTraffic struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Tag           string                 `protobuf:"bytes,1,opt,name=tag,proto3" json:"tag,omitempty"`
+	Connections   int32                  `protobuf:"varint,2,opt,name=connections,proto3" json:"connections,omitempty"`
+	UploadRate    int64                  `protobuf:"varint,3,opt,name=upload_rate,json=uploadRate,proto3" json:"upload_rate,omitempty"`       // 字节/秒
+	DownloadRate  int64                  `protobuf:"varint,4,opt,name=download_rate,json=downloadRate,proto3" json:"download_rate,omitempty"` // 字节/秒
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TagTraffic) Reset() {
+	*x = TagTraffic{}
+	mi := &file_proto_agent_proto_msgTypes[45]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TagTraffic) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TagTraffic) ProtoMessage() {}
+
+func (x *TagTraffic) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_agent_proto_msgTypes[45]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TagTraffic.ProtoReflect.Descriptor instead.
+func (*TagTraffic) Descriptor() ([]byte, []int) {
+	return file_proto_agent_proto_rawDescGZIP(), []int{45}
+}
+
+func (x *TagTraffic) GetTag() string {
+	if x != nil {
+		return x.Tag
+	}
+	return ""
+}
+
+func (x *TagTraffic) GetConnections() int32 {
+	if x != nil {
+		return x.Connections
+	}
+	return 0
+}
+
+func (x *TagTraffic) GetUploadRate() int64 {
+	if x != nil {
+		return x.UploadRate
+	}
+	return 0
+}
+
+func (x *TagTraffic) GetDownloadRate() int64 {
+	if x != nil {
+		return x.DownloadRate
+	}
+	return 0
+}
+
+// 一个出站对一个探测目标在[period_start, period_end)内的探测结果
+type OutboundProbeResult struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Instance      string                 `protobuf:"bytes,1,opt,name=instance,proto3" json:"instance,omitempty"`                                 // sing-box实例名称
+	Outbound      string                 `protobuf:"bytes,2,opt,name=outbound,proto3" json:"outbound,omitempty"`                                 // 出站tag
+	TargetType    string                 `protobuf:"bytes,3,opt,name=target_type,json=targetType,proto3" json:"target_type,omitempty"`           // http, tcp, dns
+	Target        string                 `protobuf:"bytes,4,opt,name=target,proto3" json:"target,omitempty"`                                     // 探测目标，dns为"服务器/域名"
+	Probes        int32                  `protobuf:"varint,5,opt,name=probes,proto3" json:"probes,omitempty"`                                    // 探测次数
+	Successes     int32                  `protobuf:"varint,6,opt,name=successes,proto3" json:"successes,omitempty"`                              // 成功次数
+	AvgLatencyMs  float64                `protobuf:"fixed64,7,opt,name=avg_latency_ms,json=avgLatencyMs,proto3" json:"avg_latency_ms,omitempty"` // 成功探测的平均延迟
+	MaxLatencyMs  int64                  `protobuf:"varint,8,opt,name=max_latency_ms,json=maxLatencyMs,proto3" json:"max_latency_ms,omitempty"`
+	LastLatencyMs int64                  `protobuf:"varint,9,opt,name=last_latency_ms,json=lastLatencyMs,proto3" json:"last_latency_ms,omitempty"` // 最近一次成功探测的延迟
+	LastError     string                 `protobuf:"bytes,10,opt,name=last_error,json=lastError,proto3" json:"last_error,omitempty"`               // 最近一次失败的原因
+	PeriodStart   int64                  `protobuf:"varint,11,opt,name=period_start,json=periodStart,proto3" json:"period_start,omitempty"`        // Unix秒
+	PeriodEnd     int64                  `protobuf:"varint,12,opt,name=period_end,json=periodEnd,proto3" json:"period_end,omitempty"`              // Unix秒
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OutboundProbeResult) Reset() {
+	*x = OutboundProbeResult{}
+	mi := &file_proto_agent_proto_msgTypes[46]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OutboundProbeResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OutboundProbeResult) ProtoMessage() {}
+
+func (x *OutboundProbeResult) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_agent_proto_msgTypes[46]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OutboundProbeResult.ProtoReflect.Descriptor instead.
+func (*OutboundProbeResult) Descriptor() ([]byte, []int) {
+	return file_proto_agent_proto_rawDescGZIP(), []int{46}
+}
+
+func (x *OutboundProbeResult) GetInstance() string {
+	if x != nil {
+		return x.Instance
+	}
+	return ""
+}
+
+func (x *OutboundProbeResult) GetOutbound() string {
+	if x != nil {
+		return x.Outbound
+	}
+	return ""
+}
+
+func (x *OutboundProbeResult) GetTargetType() string {
+	if x != nil {
+		return x.TargetType
+	}
+	return ""
+}
+
+func (x *OutboundProbeResult) GetTarget() string {
+	if x != nil {
+		return x.Target
+	}
+	return ""
+}
+
+func (x *OutboundProbeResult) GetProbes() int32 {
+	if x != nil {
+		return x.Probes
+	}
+	return 0
+}
+
+func (x *OutboundProbeResult) GetSuccesses() int32 {
+	if x != nil {
+		return x.Successes
+	}
+	return 0
+}
+
+func (x *OutboundProbeResult) GetAvgLatencyMs() float64 {
+	if x != nil {
+		return x.AvgLatencyMs
+	}
+	return 0
+}
+
+func (x *OutboundProbeResult) GetMaxLatencyMs() int64 {
+	if x != nil {
+		return x.MaxLatencyMs
+	}
+	return 0
+}
+
+func (x *OutboundProbeResult) GetLastLatencyMs() int64 {
+	if x != nil {
+		return x.LastLatencyMs
+	}
+	return 0
+}
+
+func (x *OutboundProbeResult) GetLastError() string {
+	if x != nil {
+		return x.LastError
+	}
+	return ""
+}
+
+func (x *OutboundProbeResult) GetPeriodStart() int64 {
+	if x != nil {
+		return x.PeriodStart
+	}
+	return 0
+}
+
+func (x *OutboundProbeResult) GetPeriodEnd() int64 {
+	if x != nil {
+		return x.PeriodEnd
+	}
+	return 0
+}
+
+// 关闭连接请求，各条件同时满足的连接会被关闭
+type CloseConnectionsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	Host          string                 `protobuf:"bytes,2,opt,name=host,proto3" json:"host,omitempty"`                         // 目标域名（含子域名）或目标IP
+	SourceIp      string                 `protobuf:"bytes,3,opt,name=source_ip,json=sourceIp,proto3" json:"source_ip,omitempty"` // 来源IP或CIDR
+	Rule          string                 `protobuf:"bytes,4,opt,name=rule,proto3" json:"rule,omitempty"`                         // 命中的规则（子串匹配）
+	Inbound       string                 `protobuf:"bytes,5,opt,name=inbound,proto3" json:"inbound,omitempty"`                   // 入站tag
+	Outbound      string                 `protobuf:"bytes,6,opt,name=outbound,proto3" json:"outbound,omitempty"`                 // 出站tag
+	All           bool                   `protobuf:"varint,7,opt,name=all,proto3" json:"all,omitempty"`                          // 未指定任何条件时必须显式设置才会关闭全部连接
+	Instance      string                 `protobuf:"bytes,8,opt,name=instance,proto3" json:"instance,omitempty"`                 // sing-box实例名称，为空时为默认实例
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CloseConnectionsRequest) Reset() {
+	*x = CloseConnectionsRequest{}
+	mi := &file_proto_agent_proto_msgTypes[47]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CloseConnectionsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CloseConnectionsRequest) ProtoMessage() {}
+
+func (x *CloseConnectionsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_agent_proto_msgTypes[47]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CloseConnectionsRequest.ProtoReflect.Descriptor instead.
+func (*CloseConnectionsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_agent_proto_rawDescGZIP(), []int{47}
+}
+
+func (x *CloseConnectionsRequest) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *CloseConnectionsRequest) GetHost() string {
+	if x != nil {
+		return x.Host
+	}
+	return ""
+}
+
+func (x *CloseConnectionsRequest) GetSourceIp() string {
+	if x != nil {
+		return x.SourceIp
+	}
+	return ""
+}
+
+func (x *CloseConnectionsRequest) GetRule() string {
+	if x != nil {
+		return x.Rule
+	}
+	return ""
+}
+
+func (x *CloseConnectionsRequest) GetInbound() string {
+	if x != nil {
+		return x.Inbound
+	}
+	return ""
+}
+
+func (x *CloseConnectionsRequest) GetOutbound() string {
+	if x != nil {
+		return x.Outbound
+	}
+	return ""
+}
+
+func (x *CloseConnectionsRequest) GetAll() bool {
+	if x != nil {
+		return x.All
+	}
+	return false
+}
+
+func (x *CloseConnectionsRequest) GetInstance() string {
+	if x != nil {
+		return x.Instance
+	}
+	return ""
+}
+
+// 关闭连接响应
+type CloseConnectionsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	Closed        int32                  `protobuf:"varint,3,opt,name=closed,proto3" json:"closed,omitempty"`
+	ConnectionIds []string               `protobuf:"bytes,4,rep,name=connection_ids,json=connectionIds,proto3" json:"connection_ids,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CloseConnectionsResponse) Reset() {
+	*x = CloseConnectionsResponse{}
+	mi := &file_proto_agent_proto_msgTypes[48]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CloseConnectionsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CloseConnectionsResponse) ProtoMessage() {}
+
+func (x *CloseConnectionsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_agent_proto_msgTypes[48]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CloseConnectionsResponse.ProtoReflect.Descriptor instead.
+func (*CloseConnectionsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_agent_proto_rawDescGZIP(), []int{48}
+}
+
+func (x *CloseConnectionsResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *CloseConnectionsResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *CloseConnectionsResponse) GetClosed() int32 {
+	if x != nil {
+		return x.Closed
+	}
+	return 0
+}
+
+func (x *CloseConnectionsResponse) GetConnectionIds() []string {
+	if x != nil {
+		return x.ConnectionIds
+	}
+	return nil
+}
+
+// 流量用量上报，usages为[period_start, period_end)内的增量
+type TrafficUsageReport struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	PeriodStart   int64                  `protobuf:"varint,2,opt,name=period_start,json=periodStart,proto3" json:"period_start,omitempty"` // Unix秒
+	PeriodEnd     int64                  `protobuf:"varint,3,opt,name=period_end,json=periodEnd,proto3" json:"period_end,omitempty"`       // Unix秒
+	Usages        []*TrafficUsage        `protobuf:"bytes,4,rep,name=usages,proto3" json:"usages,omitempty"`
+	Instance      string                 `protobuf:"bytes,5,opt,name=instance,proto3" json:"instance,omitempty"` // sing-box实例名称，为空时为默认实例
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TrafficUsageReport) Reset() {
+	*x = TrafficUsageReport{}
+	mi := &file_proto_agent_proto_msgTypes[49]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TrafficUsageReport) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TrafficUsageReport) ProtoMessage() {}
+
+func (x *TrafficUsageReport) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_agent_proto_msgTypes[49]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TrafficUsageReport.ProtoReflect.Descriptor instead.
+func (*TrafficUsageReport) Descriptor() ([]byte, []int) {
+	return file_proto_agent_proto_rawDescGZIP(), []int{49}
+}
+
+func (x *TrafficUsageReport) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *TrafficUsageReport) GetPeriodStart() int64 {
+	if x != nil {
+		return x.PeriodStart
+	}
+	return 0
+}
+
+func (x *TrafficUsageReport) GetPeriodEnd() int64 {
+	if x != nil {
+		return x.PeriodEnd
+	}
+	return 0
+}
+
+func (x *TrafficUsageReport) GetUsages() []*TrafficUsage {
+	if x != nil {
+		return x.Usages
+	}
+	return nil
+}
+
+func (x *TrafficUsageReport) GetInstance() string {
+	if x != nil {
+		return x.Instance
+	}
+	return ""
+}
+
+// 单个统计对象的流量增量
+type TrafficUsage struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Scope         string                 `protobuf:"bytes,1,opt,name=scope,proto3" json:"scope,omitempty"`        // user, inbound, outbound
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`          // 用户名或tag
+	Uplink        int64                  `protobuf:"varint,3,opt,name=uplink,proto3" json:"uplink,omitempty"`     // 字节
+	Downlink      int64                  `protobuf:"varint,4,opt,name=downlink,proto3" json:"downlink,omitempty"` // 字节
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TrafficUsage) Reset() {
+	*x = TrafficUsage{}
+	mi := &file_proto_agent_proto_msgTypes[50]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TrafficUsage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TrafficUsage) ProtoMessage() {}
+
+func (x *TrafficUsage) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_agent_proto_msgTypes[50]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TrafficUsage.ProtoReflect.Descriptor instead.
+func (*TrafficUsage) Descriptor() ([]byte, []int) {
+	return file_proto_agent_proto_rawDescGZIP(), []int{50}
+}
+
+func (x *TrafficUsage) GetScope() string {
+	if x != nil {
+		return x.Scope
+	}
+	return ""
+}
+
+func (x *TrafficUsage) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *TrafficUsage) GetUplink() int64 {
+	if x != nil {
+		return x.Uplink
+	}
+	return 0
+}
+
+func (x *TrafficUsage) GetDownlink() int64 {
+	if x != nil {
+		return x.Downlink
+	}
+	return 0
+}
+
+// 流量用量上报响应
+type TrafficUsageResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TrafficUsageResponse) Reset() {
+	*x = TrafficUsageResponse{}
+	mi := &file_proto_agent_proto_msgTypes[51]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TrafficUsageResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TrafficUsageResponse) ProtoMessage() {}
+
+func (x *TrafficUsageResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_agent_proto_msgTypes[51]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TrafficUsageResponse.ProtoReflect.Descriptor instead.
+func (*TrafficUsageResponse) Descriptor() ([]byte, []int) {
+	return file_proto_agent_proto_rawDescGZIP(), []int{51}
+}
+
+func (x *TrafficUsageResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *TrafficUsageResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+// sing-box版本切换请求
+type UpgradeRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	Version       string                 `protobuf:"bytes,2,opt,name=version,proto3" json:"version,omitempty"`   // 目标版本，如1.11.4
+	Sha256        string                 `protobuf:"bytes,3,opt,name=sha256,proto3" json:"sha256,omitempty"`     // 制品sha256，为空时按Agent配置的来源校验（SHA256SUMS）
+	Instance      string                 `protobuf:"bytes,4,opt,name=instance,proto3" json:"instance,omitempty"` // sing-box实例名称，为空时为默认实例
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpgradeRequest) Reset() {
+	*x = UpgradeRequest{}
+	mi := &file_proto_agent_proto_msgTypes[52]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpgradeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpgradeRequest) ProtoMessage() {}
+
+func (x *UpgradeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_agent_proto_msgTypes[52]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpgradeRequest.ProtoReflect.Descriptor instead.
+func (*UpgradeRequest) Descriptor() ([]byte, []int) {
+	return file_proto_agent_proto_rawDescGZIP(), []int{52}
+}
+
+func (x *UpgradeRequest) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *UpgradeRequest) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *UpgradeRequest) GetSha256() string {
+	if x != nil {
+		return x.Sha256
+	}
+	return ""
+}
+
+func (x *UpgradeRequest) GetInstance() string {
+	if x != nil {
+		return x.Instance
+	}
+	return ""
+}
+
+// sing-box版本切换响应
+type UpgradeResponse struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Success        bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Message        string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	FromVersion    string                 `protobuf:"bytes,3,opt,name=from_version,json=fromVersion,proto3" json:"from_version,omitempty"`          // 切换前的版本
+	CurrentVersion string                 `protobuf:"bytes,4,opt,name=current_version,json=currentVersion,proto3" json:"current_version,omitempty"` // 结束后实际运行的版本
+	RolledBack     bool                   `protobuf:"varint,5,opt,name=rolled_back,json=rolledBack,proto3" json:"rolled_back,omitempty"`            // 失败后是否已恢复原二进制
+	Phases         []*ApplyPhase          `protobuf:"bytes,6,rep,name=phases,proto3" json:"phases,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *UpgradeResponse) Reset() {
+	*x = UpgradeResponse{}
+	mi := &file_proto_agent_proto_msgTypes[53]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpgradeResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpgradeResponse) ProtoMessage() {}
+
+func (x *UpgradeResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_agent_proto_msgTypes[53]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpgradeResponse.ProtoReflect.Descriptor instead.
+func (*UpgradeResponse) Descriptor() ([]byte, []int) {
+	return file_proto_agent_proto_rawDescGZIP(), []int{53}
+}
+
+func (x *UpgradeResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *UpgradeResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *UpgradeResponse) GetFromVersion() string {
+	if x != nil {
+		return x.FromVersion
+	}
+	return ""
+}
+
+func (x *UpgradeResponse) GetCurrentVersion() string {
+	if x != nil {
+		return x.CurrentVersion
+	}
+	return ""
+}
+
+func (x *UpgradeResponse) GetRolledBack() bool {
+	if x != nil {
+		return x.RolledBack
+	}
+	return false
+}
+
+func (x *UpgradeResponse) GetPhases() []*ApplyPhase {
+	if x != nil {
+		return x.Phases
+	}
+	return nil
+}
+
+// DNS配置查询请求
+type DNSConfigQuery struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	Instance      string                 `protobuf:"bytes,2,opt,name=instance,proto3" json:"instance,omitempty"` // sing-box实例名称，为空时为默认实例
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DNSConfigQuery) Reset() {
+	*x = DNSConfigQuery{}
+	mi := &file_proto_agent_proto_msgTypes[54]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DNSConfigQuery) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DNSConfigQuery) ProtoMessage() {}
+
+func (x *DNSConfigQuery) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_agent_proto_msgTypes[54]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DNSConfigQuery.ProtoReflect.Descriptor instead.
+func (*DNSConfigQuery) Descriptor() ([]byte, []int) {
+	return file_proto_agent_proto_rawDescGZIP(), []int{54}
+}
+
+func (x *DNSConfigQuery) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *DNSConfigQuery) GetInstance() string {
+	if x != nil {
+		return x.Instance
+	}
+	return ""
+}
+
+// DNS服务器更新请求
+type DNSServersRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	Instance      string                 `protobuf:"bytes,2,opt,name=instance,proto3" json:"instance,omitempty"`   // sing-box实例名称，为空时为默认实例
+	Operation     string                 `protobuf:"bytes,3,opt,name=operation,proto3" json:"operation,omitempty"` // add, update, remove, move, replace；为空时只设置final和strategy
+	Servers       string                 `protobuf:"bytes,4,opt,name=servers,proto3" json:"servers,omitempty"`     // DNS服务器JSON数组，add、update、replace时使用
+	Tags          []string               `protobuf:"bytes,5,rep,name=tags,proto3" json:"tags,omitempty"`           // remove时为待删除的tag；move时为新的顺序，未列出的服务器保持原顺序排在后面
+	Position      int32                  `protobuf:"varint,6,opt,name=position,proto3" json:"position,omitempty"`  // add时插入的位置（从1开始），0表示追加到末尾
+	Final         string                 `protobuf:"bytes,7,opt,name=final,proto3" json:"final,omitempty"`         // 默认DNS服务器tag，为空时不修改
+	Strategy      string                 `protobuf:"bytes,8,opt,name=strategy,proto3" json:"strategy,omitempty"`   // 默认解析策略，为空时不修改
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DNSServersRequest) Reset() {
+	*x = DNSServersRequest{}
+	mi := &file_proto_agent_proto_msgTypes[55]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DNSServersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DNSServersRequest) ProtoMessage() {}
+
+func (x *DNSServersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_agent_proto_msgTypes[55]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DNSServersRequest.ProtoReflect.Descriptor instead.
+func (*DNSServersRequest) Descriptor() ([]byte, []int) {
+	return file_proto_agent_proto_rawDescGZIP(), []int{55}
+}
+
+func (x *DNSServersRequest) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *DNSServersRequest) GetInstance() string {
+	if x != nil {
+		return x.Instance
+	}
+	return ""
+}
+
+func (x *DNSServersRequest) GetOperation() string {
+	if x != nil {
+		return x.Operation
+	}
+	return ""
+}
+
+func (x *DNSServersRequest) GetServers() string {
+	if x != nil {
+		return x.Servers
+	}
+	return ""
+}
+
+func (x *DNSServersRequest) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *DNSServersRequest) GetPosition() int32 {
+	if x != nil {
+		return x.Position
+	}
+	return 0
+}
+
+func (x *DNSServersRequest) GetFinal() string {
+	if x != nil {
+		return x.Final
+	}
+	return ""
+}
+
+func (x *DNSServersRequest) GetStrategy() string {
+	if x != nil {
+		return x.Strategy
+	}
+	return ""
+}
+
+// DNS规则更新请求
+type DNSRulesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	Instance      string                 `protobuf:"bytes,2,opt,name=instance,proto3" json:"instance,omitempty"`           // sing-box实例名称，为空时为默认实例
+	Operation     string                 `protobuf:"bytes,3,opt,name=operation,proto3" json:"operation,omitempty"`         // add, remove, move, replace
+	Rules         string                 `protobuf:"bytes,4,opt,name=rules,proto3" json:"rules,omitempty"`                 // DNS规则JSON数组，add、replace时使用
+	Positions     []int32                `protobuf:"varint,5,rep,packed,name=positions,proto3" json:"positions,omitempty"` // remove时为待删除规则的位置（从1开始）；move时为被移动规则的位置
+	Position      int32                  `protobuf:"varint,6,opt,name=position,proto3" json:"position,omitempty"`          // add时插入的位置，move时的目标位置（从1开始），add时0表示追加到末尾
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DNSRulesRequest) Reset() {
+	*x = DNSRulesRequest{}
+	mi := &file_proto_agent_proto_msgTypes[56]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DNSRulesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DNSRulesRequest) ProtoMessage() {}
+
+func (x *DNSRulesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_agent_proto_msgTypes[56]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DNSRulesRequest.ProtoReflect.Descriptor instead.
+func (*DNSRulesRequest) Descriptor() ([]byte, []int) {
+	return file_proto_agent_proto_rawDescGZIP(), []int{56}
+}
+
+func (x *DNSRulesRequest) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *DNSRulesRequest) GetInstance() string {
+	if x != nil {
+		return x.Instance
+	}
+	return ""
+}
+
+func (x *DNSRulesRequest) GetOperation() string {
+	if x != nil {
+		return x.Operation
+	}
+	return ""
+}
+
+func (x *DNSRulesRequest) GetRules() string {
+	if x != nil {
+		return x.Rules
+	}
+	return ""
+}
+
+func (x *DNSRulesRequest) GetPositions() []int32 {
+	if x != nil {
+		return x.Positions
+	}
+	return nil
+}
+
+func (x *DNSRulesRequest) GetPosition() int32 {
+	if x != nil {
+		return x.Position
+	}
+	return 0
+}
+
+// FakeIP更新请求
+type FakeIPRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	Instance      string                 `protobuf:"bytes,2,opt,name=instance,proto3" json:"instance,omitempty"` // sing-box实例名称，为空时为默认实例
+	Enabled       bool                   `protobuf:"varint,3,opt,name=enabled,proto3" json:"enabled,omitempty"`
+	Inet4Range    string                 `protobuf:"bytes,4,opt,name=inet4_range,json=inet4Range,proto3" json:"inet4_range,omitempty"` // 为空时使用198.18.0.0/15
+	Inet6Range    string                 `protobuf:"bytes,5,opt,name=inet6_range,json=inet6Range,proto3" json:"inet6_range,omitempty"` // 为空时不分配IPv6地址
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FakeIPRequest) Reset() {
+	*x = FakeIPRequest{}
+	mi := &file_proto_agent_proto_msgTypes[57]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FakeIPRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FakeIPRequest) ProtoMessage() {}
+
+func (x *FakeIPRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_agent_proto_msgTypes[57]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FakeIPRequest.ProtoReflect.Descriptor instead.
+func (*FakeIPRequest) Descriptor() ([]byte, []int) {
+	return file_proto_agent_proto_rawDescGZIP(), []int{57}
+}
+
+func (x *FakeIPRequest) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *FakeIPRequest) GetInstance() string {
+	if x != nil {
+		return x.Instance
+	}
+	return ""
+}
+
+func (x *FakeIPRequest) GetEnabled() bool {
+	if x != nil {
+		return x.Enabled
+	}
+	return false
+}
+
+func (x *FakeIPRequest) GetInet4Range() string {
+	if x != nil {
+		return x.Inet4Range
+	}
+	return ""
+}
+
+func (x *FakeIPRequest) GetInet6Range() string {
+	if x != nil {
+		return x.Inet6Range
+	}
+	return ""
+}
+
+// DNS配置响应
+type DNSConfigResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	DnsConfig     string                 `protobuf:"bytes,3,opt,name=dns_config,json=dnsConfig,proto3" json:"dns_config,omitempty"` // 操作后生效的DNS配置JSON
+	Version       string                 `protobuf:"bytes,4,opt,name=version,proto3" json:"version,omitempty"`                      // 当前DNS配置版本，可用于scope=dns的回滚
+	Versions      []string               `protobuf:"bytes,5,rep,name=versions,proto3" json:"versions,omitempty"`                    // 可回滚的历史版本，从旧到新
+	Phases        []*ApplyPhase          `protobuf:"bytes,6,rep,name=phases,proto3" json:"phases,omitempty"`                        // 更新时的应用流水线阶段结果
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DNSConfigResponse) Reset() {
+	*x = DNSConfigResponse{}
+	mi := &file_proto_agent_proto_msgTypes[58]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DNSConfigResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DNSConfigResponse) ProtoMessage() {}
+
+func (x *DNSConfigResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_agent_proto_msgTypes[58]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DNSConfigResponse.ProtoReflect.Descriptor instead.
+func (*DNSConfigResponse) Descriptor() ([]byte, []int) {
+	return file_proto_agent_proto_rawDescGZIP(), []int{58}
+}
+
+func (x *DNSConfigResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *DNSConfigResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *DNSConfigResponse) GetDnsConfig() string {
+	if x != nil {
+		return x.DnsConfig
+	}
+	return ""
+}
+
+func (x *DNSConfigResponse) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *DNSConfigResponse) GetVersions() []string {
+	if x != nil {
+		return x.Versions
+	}
+	return nil
+}
+
+func (x *DNSConfigResponse) GetPhases() []*ApplyPhase {
+	if x != nil {
+		return x.Phases
+	}
+	return nil
+}
+
+// 出站组（selector/urltest）
+type OutboundGroup struct {
+	state                     protoimpl.MessageState `protogen:"open.v1"`
+	Tag                       string                 `protobuf:"bytes,1,opt,name=tag,proto3" json:"tag,omitempty"`
+	Type                      string                 `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`                                                                               // selector, urltest
+	Outbounds                 []string               `protobuf:"bytes,3,rep,name=outbounds,proto3" json:"outbounds,omitempty"`                                                                     // 成员出站tag
+	Default                   string                 `protobuf:"bytes,4,opt,name=default,proto3" json:"default,omitempty"`                                                                         // selector默认选择的成员
+	Url                       string                 `protobuf:"bytes,5,opt,name=url,proto3" json:"url,omitempty"`                                                                                 // urltest测试地址
+	Interval                  string                 `protobuf:"bytes,6,opt,name=interval,proto3" json:"interval,omitempty"`                                                                       // urltest测试间隔，如3m
+	Tolerance                 uint32                 `protobuf:"varint,7,opt,name=tolerance,proto3" json:"tolerance,omitempty"`                                                                    // urltest切换容差（毫秒）
+	IdleTimeout               string                 `protobuf:"bytes,8,opt,name=idle_timeout,json=idleTimeout,proto3" json:"idle_timeout,omitempty"`                                              // urltest空闲超时，如30m
+	InterruptExistConnections bool                   `protobuf:"varint,9,opt,name=interrupt_exist_connections,json=interruptExistConnections,proto3" json:"interrupt_exist_connections,omitempty"` // 选择变化时中断已有连接
+	unknownFields             protoimpl.UnknownFields
+	sizeCache                 protoimpl.SizeCache
+}
+
+func (x *OutboundGroup) Reset() {
+	*x = OutboundGroup{}
+	mi := &file_proto_agent_proto_msgTypes[59]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OutboundGroup) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OutboundGroup) ProtoMessage() {}
+
+func (x *OutboundGroup) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_agent_proto_msgTypes[59]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OutboundGroup.ProtoReflect.Descriptor instead.
+func (*OutboundGroup) Descriptor() ([]byte, []int) {
+	return file_proto_agent_proto_rawDescGZIP(), []int{59}
+}
+
+func (x *OutboundGroup) GetTag() string {
+	if x != nil {
+		return x.Tag
+	}
+	return ""
+}
+
+func (x *OutboundGroup) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *OutboundGroup) GetOutbounds() []string {
+	if x != nil {
+		return x.Outbounds
+	}
+	return nil
+}
+
+func (x *OutboundGroup) GetDefault() string {
+	if x != nil {
+		return x.Default
+	}
+	return ""
+}
+
+func (x *OutboundGroup) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *OutboundGroup) GetInterval() string {
+	if x != nil {
+		return x.Interval
+	}
+	return ""
+}
+
+func (x *OutboundGroup) GetTolerance() uint32 {
+	if x != nil {
+		return x.Tolerance
+	}
+	return 0
+}
+
+func (x *OutboundGroup) GetIdleTimeout() string {
+	if x != nil {
+		return x.IdleTimeout
+	}
+	return ""
+}
+
+func (x *OutboundGroup) GetInterruptExistConnections() bool {
+	if x != nil {
+		return x.InterruptExistConnections
+	}
+	return false
+}
+
+// 出站组更新请求
+type OutboundGroupRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	Instance      string                 `protobuf:"bytes,2,opt,name=instance,proto3" json:"instance,omitempty"`   // sing-box实例名称，为空时为默认实例
+	Operation     string                 `protobuf:"bytes,3,opt,name=operation,proto3" json:"operation,omitempty"` // create, update, delete
+	Group         *OutboundGroup         `protobuf:"bytes,4,opt,name=group,proto3" json:"group,omitempty"`         // delete时只需tag
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OutboundGroupRequest) Reset() {
+	*x = OutboundGroupRequest{}
+	mi := &file_proto_agent_proto_msgTypes[60]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OutboundGroupRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OutboundGroupRequest) ProtoMessage() {}
+
+func (x *OutboundGroupRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_agent_proto_msgTypes[60]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OutboundGroupRequest.ProtoReflect.Descriptor instead.
+func (*OutboundGroupRequest) Descriptor() ([]byte, []int) {
+	return file_proto_agent_proto_rawDescGZIP(), []int{60}
+}
+
+func (x *OutboundGroupRequest) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *OutboundGroupRequest) GetInstance() string {
+	if x != nil {
+		return x.Instance
+	}
+	return ""
+}
+
+func (x *OutboundGroupRequest) GetOperation() string {
+	if x != nil {
+		return x.Operation
+	}
+	return ""
+}
+
+func (x *OutboundGroupRequest) GetGroup() *OutboundGroup {
+	if x != nil {
+		return x.Group
+	}
+	return nil
+}
+
+// 出站组成员更新请求
+type GroupMembersRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	Instance      string                 `protobuf:"bytes,2,opt,name=instance,proto3" json:"instance,omitempty"`   // sing-box实例名称，为空时为默认实例
+	Tag           string                 `protobuf:"bytes,3,opt,name=tag,proto3" json:"tag,omitempty"`             // 出站组tag
+	Operation     string                 `protobuf:"bytes,4,opt,name=operation,proto3" json:"operation,omitempty"` // add, remove, replace
+	Members       []string               `protobuf:"bytes,5,rep,name=members,proto3" json:"members,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GroupMembersRequest) Reset() {
+	*x = GroupMembersRequest{}
+	mi := &file_proto_agent_proto_msgTypes[61]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GroupMembersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GroupMembersRequest) ProtoMessage() {}
+
+func (x *GroupMembersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_agent_proto_msgTypes[61]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GroupMembersRequest.ProtoReflect.Descriptor instead.
+func (*GroupMembersRequest) Descriptor() ([]byte, []int) {
+	return file_proto_agent_proto_rawDescGZIP(), []int{61}
+}
+
+func (x *GroupMembersRequest) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *GroupMembersRequest) GetInstance() string {
+	if x != nil {
+		return x.Instance
+	}
+	return ""
+}
+
+func (x *GroupMembersRequest) GetTag() string {
+	if x != nil {
+		return x.Tag
+	}
+	return ""
+}
+
+func (x *GroupMembersRequest) GetOperation() string {
+	if x != nil {
+		return x.Operation
+	}
+	return ""
+}
+
+func (x *GroupMembersRequest) GetMembers() []string {
+	if x != nil {
+		return x.Members
+	}
+	return nil
+}
+
+// 出站组更新响应
+type OutboundGroupResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	Group         *OutboundGroup         `protobuf:"bytes,3,opt,name=group,proto3" json:"group,omitempty"`   // 操作后的出站组，删除时为空
+	Phases        []*ApplyPhase          `protobuf:"bytes,4,rep,name=phases,proto3" json:"phases,omitempty"` // 应用流水线阶段结果
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OutboundGroupResponse) Reset() {
+	*x = OutboundGroupResponse{}
+	mi := &file_proto_agent_proto_msgTypes[62]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OutboundGroupResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OutboundGroupResponse) ProtoMessage() {}
+
+func (x *OutboundGroupResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_agent_proto_msgTypes[62]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OutboundGroupResponse.ProtoReflect.Descriptor instead.
+func (*OutboundGroupResponse) Descriptor() ([]byte, []int) {
+	return file_proto_agent_proto_rawDescGZIP(), []int{62}
+}
+
+func (x *OutboundGroupResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *OutboundGroupResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *OutboundGroupResponse) GetGroup() *OutboundGroup {
+	if x != nil {
+		return x.Group
+	}
+	return nil
+}
+
+func (x *OutboundGroupResponse) GetPhases() []*ApplyPhase {
+	if x != nil {
+		return x.Phases
+	}
+	return nil
+}
+
+// 出站组查询请求
+type OutboundGroupsQuery struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	Instance      string                 `protobuf:"bytes,2,opt,name=instance,proto3" json:"instance,omitempty"`                     // sing-box实例名称，为空时为默认实例
+	Tag           string                 `protobuf:"bytes,3,opt,name=tag,proto3" json:"tag,omitempty"`                               // 为空时返回全部出站组
+	TestDelay     bool                   `protobuf:"varint,4,opt,name=test_delay,json=testDelay,proto3" json:"test_delay,omitempty"` // 查询前对成员进行一次延迟测试
+	TestUrl       string                 `protobuf:"bytes,5,opt,name=test_url,json=testUrl,proto3" json:"test_url,omitempty"`        // 延迟测试地址，为空时使用组的url或默认地址
+	TimeoutMs     int32                  `protobuf:"varint,6,opt,name=timeout_ms,json=timeoutMs,proto3" json:"timeout_ms,omitempty"` // 单个成员的测试超时，0表示5000
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OutboundGroupsQuery) Reset() {
+	*x = OutboundGroupsQuery{}
+	mi := &file_proto_agent_proto_msgTypes[63]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OutboundGroupsQuery) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OutboundGroupsQuery) ProtoMessage() {}
+
+func (x *OutboundGroupsQuery) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_agent_proto_msgTypes[63]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OutboundGroupsQuery.ProtoReflect.Descriptor instead.
+func (*OutboundGroupsQuery) Descriptor() ([]byte, []int) {
+	return file_proto_agent_proto_rawDescGZIP(), []int{63}
+}
+
+func (x *OutboundGroupsQuery) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *OutboundGroupsQuery) GetInstance() string {
+	if x != nil {
+		return x.Instance
+	}
+	return ""
+}
+
+func (x *OutboundGroupsQuery) GetTag() string {
+	if x != nil {
+		return x.Tag
+	}
+	return ""
+}
+
+func (x *OutboundGroupsQuery) GetTestDelay() bool {
+	if x != nil {
+		return x.TestDelay
+	}
+	return false
+}
+
+func (x *OutboundGroupsQuery) GetTestUrl() string {
+	if x != nil {
+		return x.TestUrl
+	}
+	return ""
+}
+
+func (x *OutboundGroupsQuery) GetTimeoutMs() int32 {
+	if x != nil {
+		return x.TimeoutMs
+	}
+	return 0
+}
+
+// 成员延迟
+type MemberDelay struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Tag           string                 `protobuf:"bytes,1,opt,name=tag,proto3" json:"tag,omitempty"`
+	Delay         int32                  `protobuf:"varint,2,opt,name=delay,proto3" json:"delay,omitempty"`                       // 最近一次测试的延迟（毫秒），0表示未测试或不可用
+	TestedAt      int64                  `protobuf:"varint,3,opt,name=tested_at,json=testedAt,proto3" json:"tested_at,omitempty"` // 最近一次测试时间（Unix秒）
+	Error         string                 `protobuf:"bytes,4,opt,name=error,proto3" json:"error,omitempty"`                        // 本次测试失败的原因
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MemberDelay) Reset() {
+	*x = MemberDelay{}
+	mi := &file_proto_agent_proto_msgTypes[64]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MemberDelay) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MemberDelay) ProtoMessage() {}
+
+func (x *MemberDelay) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_agent_proto_msgTypes[64]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MemberDelay.ProtoReflect.Descriptor instead.
+func (*MemberDelay) Descriptor() ([]byte, []int) {
+	return file_proto_agent_proto_rawDescGZIP(), []int{64}
+}
+
+func (x *MemberDelay) GetTag() string {
+	if x != nil {
+		return x.Tag
+	}
+	return ""
+}
+
+func (x *MemberDelay) GetDelay() int32 {
+	if x != nil {
+		return x.Delay
+	}
+	return 0
+}
+
+func (x *MemberDelay) GetTestedAt() int64 {
+	if x != nil {
+		return x.TestedAt
+	}
+	return 0
+}
+
+func (x *MemberDelay) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+// 出站组运行状态
+type OutboundGroupStatus struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Group         *OutboundGroup         `protobuf:"bytes,1,opt,name=group,proto3" json:"group,omitempty"`
+	Now           string                 `protobuf:"bytes,2,opt,name=now,proto3" json:"now,omitempty"` // 当前选择的成员
+	Members       []*MemberDelay         `protobuf:"bytes,3,rep,name=members,proto3" json:"members,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OutboundGroupStatus) Reset() {
+	*x = OutboundGroupStatus{}
+	mi := &file_proto_agent_proto_msgTypes[65]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OutboundGroupStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OutboundGroupStatus) ProtoMessage() {}
+
+func (x *OutboundGroupStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_agent_proto_msgTypes[65]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OutboundGroupStatus.ProtoReflect.Descriptor instead.
+func (*OutboundGroupStatus) Descriptor() ([]byte, []int) {
+	return file_proto_agent_proto_rawDescGZIP(), []int{65}
+}
+
+func (x *OutboundGroupStatus) GetGroup() *OutboundGroup {
+	if x != nil {
+		return x.Group
+	}
+	return nil
+}
+
+func (x *OutboundGroupStatus) GetNow() string {
+	if x != nil {
+		return x.Now
+	}
+	return ""
+}
+
+func (x *OutboundGroupStatus) GetMembers() []*MemberDelay {
+	if x != nil {
+		return x.Members
+	}
+	return nil
+}
+
+// 出站组查询响应
+type OutboundGroupsResponse struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Success          bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Message          string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	Groups           []*OutboundGroupStatus `protobuf:"bytes,3,rep,name=groups,proto3" json:"groups,omitempty"`
+	RuntimeAvailable bool                   `protobuf:"varint,4,opt,name=runtime_available,json=runtimeAvailable,proto3" json:"runtime_available,omitempty"` // 是否从Clash API获取到了当前选择与延迟
+	RuntimeError     string                 `protobuf:"bytes,5,opt,name=runtime_error,json=runtimeError,proto3" json:"runtime_error,omitempty"`              // 未获取到运行状态的原因
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *OutboundGroupsResponse) Reset() {
+	*x = OutboundGroupsResponse{}
+	mi := &file_proto_agent_proto_msgTypes[66]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OutboundGroupsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OutboundGroupsResponse) ProtoMessage() {}
+
+func (x *OutboundGroupsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_agent_proto_msgTypes[66]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OutboundGroupsResponse.ProtoReflect.Descriptor instead.
+func (*OutboundGroupsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_agent_proto_rawDescGZIP(), []int{66}
+}
+
+func (x *OutboundGroupsResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *OutboundGroupsResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *OutboundGroupsResponse) GetGroups() []*OutboundGroupStatus {
+	if x != nil {
+		return x.Groups
+	}
+	return nil
+}
+
+func (x *OutboundGroupsResponse) GetRuntimeAvailable() bool {
+	if x != nil {
+		return x.RuntimeAvailable
+	}
+	return false
+}
+
+func (x *OutboundGroupsResponse) GetRuntimeError() string {
+	if x != nil {
+		return x.RuntimeError
+	}
+	return ""
+}
+
+// 证书下发请求，证书写入Agent本地文件后设置到入站的certificate_path/key_path
+type CertificateRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	AgentId        string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	Instance       string                 `protobuf:"bytes,2,opt,name=instance,proto3" json:"instance,omitempty"` // sing-box实例名称，为空时为默认实例
+	InboundTag     string                 `protobuf:"bytes,3,opt,name=inbound_tag,json=inboundTag,proto3" json:"inbound_tag,omitempty"`
+	Name           string                 `protobuf:"bytes,4,opt,name=name,proto3" json:"name,omitempty"`                                           // 证书名称，用于本地文件名
+	CertificatePem string                 `protobuf:"bytes,5,opt,name=certificate_pem,json=certificatePem,proto3" json:"certificate_pem,omitempty"` // 证书链PEM，叶子证书在前
+	KeyPem         string                 `protobuf:"bytes,6,opt,name=key_pem,json=keyPem,proto3" json:"key_pem,omitempty"`                         // 私钥PEM
+	Fingerprint    string                 `protobuf:"bytes,7,opt,name=fingerprint,proto3" json:"fingerprint,omitempty"`                             // 叶子证书的sha256指纹（hex），Agent校验后写入
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *CertificateRequest) Reset() {
+	*x = CertificateRequest{}
+	mi := &file_proto_agent_proto_msgTypes[67]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CertificateRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CertificateRequest) ProtoMessage() {}
+
+func (x *CertificateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_agent_proto_msgTypes[67]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CertificateRequest.ProtoReflect.Descriptor instead.
+func (*CertificateRequest) Descriptor() ([]byte, []int) {
+	return file_proto_agent_proto_rawDescGZIP(), []int{67}
+}
+
+func (x *CertificateRequest) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *CertificateRequest) GetInstance() string {
+	if x != nil {
+		return x.Instance
+	}
+	return ""
+}
+
+func (x *CertificateRequest) GetInboundTag() string {
+	if x != nil {
+		return x.InboundTag
+	}
+	return ""
+}
+
+func (x *CertificateRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *CertificateRequest) GetCertificatePem() string {
+	if x != nil {
+		return x.CertificatePem
+	}
+	return ""
+}
+
+func (x *CertificateRequest) GetKeyPem() string {
+	if x != nil {
+		return x.KeyPem
+	}
+	return ""
+}
+
+func (x *CertificateRequest) GetFingerprint() string {
+	if x != nil {
+		return x.Fingerprint
+	}
+	return ""
+}
+
+// 证书下发响应
+type CertificateResponse struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Success         bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Message         string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	CertificatePath string                 `protobuf:"bytes,3,opt,name=certificate_path,json=certificatePath,proto3" json:"certificate_path,omitempty"` // Agent上的证书文件路径
+	KeyPath         string                 `protobuf:"bytes,4,opt,name=key_path,json=keyPath,proto3" json:"key_path,omitempty"`                         // Agent上的私钥文件路径
+	Phases          []*ApplyPhase          `protobuf:"bytes,5,rep,name=phases,proto3" json:"phases,omitempty"`                                          // 应用流水线阶段结果
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *CertificateResponse) Reset() {
+	*x = CertificateResponse{}
+	mi := &file_proto_agent_proto_msgTypes[68]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CertificateResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CertificateResponse) ProtoMessage() {}
+
+func (x *CertificateResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_agent_proto_msgTypes[68]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CertificateResponse.ProtoReflect.Descriptor instead.
+func (*CertificateResponse) Descriptor() ([]byte, []int) {
+	return file_proto_agent_proto_rawDescGZIP(), []int{68}
+}
+
+func (x *CertificateResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *CertificateResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *CertificateResponse) GetCertificatePath() string {
+	if x != nil {
+		return x.CertificatePath
+	}
+	return ""
+}
+
+func (x *CertificateResponse) GetKeyPath() string {
+	if x != nil {
+		return x.KeyPath
+	}
+	return ""
+}
+
+func (x *CertificateResponse) GetPhases() []*ApplyPhase {
+	if x != nil {
+		return x.Phases
+	}
+	return nil
+}
+
+// Reality密钥下发请求，只修改指定入站的tls.reality，证书和ACME配置会被清除
+type RealityRequest struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	AgentId         string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	Instance        string                 `protobuf:"bytes,2,opt,name=instance,proto3" json:"instance,omitempty"` // sing-box实例名称，为空时为默认实例
+	InboundTag      string                 `protobuf:"bytes,3,opt,name=inbound_tag,json=inboundTag,proto3" json:"inbound_tag,omitempty"`
+	PrivateKey      string                 `protobuf:"bytes,4,opt,name=private_key,json=privateKey,proto3" json:"private_key,omitempty"` // base64url编码的X25519私钥
+	ShortIds        []string               `protobuf:"bytes,5,rep,name=short_ids,json=shortIds,proto3" json:"short_ids,omitempty"`
+	HandshakeServer string                 `protobuf:"bytes,6,opt,name=handshake_server,json=handshakeServer,proto3" json:"handshake_server,omitempty"` // 为空时保留入站当前的握手服务器
+	HandshakePort   uint32                 `protobuf:"varint,7,opt,name=handshake_port,json=handshakePort,proto3" json:"handshake_port,omitempty"`      // 默认443
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *RealityRequest) Reset() {
+	*x = RealityRequest{}
+	mi := &file_proto_agent_proto_msgTypes[69]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RealityRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RealityRequest) ProtoMessage() {}
+
+func (x *RealityRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_agent_proto_msgTypes[69]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RealityRequest.ProtoReflect.Descriptor instead.
+func (*RealityRequest) Descriptor() ([]byte, []int) {
+	return file_proto_agent_proto_rawDescGZIP(), []int{69}
+}
+
+func (x *RealityRequest) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *RealityRequest) GetInstance() string {
+	if x != nil {
+		return x.Instance
+	}
+	return ""
+}
+
+func (x *RealityRequest) GetInboundTag() string {
+	if x != nil {
+		return x.InboundTag
+	}
+	return ""
+}
+
+func (x *RealityRequest) GetPrivateKey() string {
+	if x != nil {
+		return x.PrivateKey
+	}
+	return ""
+}
+
+func (x *RealityRequest) GetShortIds() []string {
+	if x != nil {
+		return x.ShortIds
+	}
+	return nil
+}
+
+func (x *RealityRequest) GetHandshakeServer() string {
+	if x != nil {
+		return x.HandshakeServer
+	}
+	return ""
+}
+
+func (x *RealityRequest) GetHandshakePort() uint32 {
+	if x != nil {
+		return x.HandshakePort
+	}
+	return 0
+}
+
+// Reality查询请求
+type RealityQuery struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	Instance      string                 `protobuf:"bytes,2,opt,name=instance,proto3" json:"instance,omitempty"`
+	InboundTag    string                 `protobuf:"bytes,3,opt,name=inbound_tag,json=inboundTag,proto3" json:"inbound_tag,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RealityQuery) Reset() {
+	*x = RealityQuery{}
+	mi := &file_proto_agent_proto_msgTypes[70]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RealityQuery) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RealityQuery) ProtoMessage() {}
+
+func (x *RealityQuery) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_agent_proto_msgTypes[70]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RealityQuery.ProtoReflect.Descriptor instead.
+func (*RealityQuery) Descriptor() ([]byte, []int) {
+	return file_proto_agent_proto_rawDescGZIP(), []int{70}
+}
+
+func (x *RealityQuery) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+func (x *RealityQuery) GetInstance() string {
+	if x != nil {
+		return x.Instance
+	}
+	return ""
+}
+
+func (x *RealityQuery) GetInboundTag() string {
+	if x != nil {
+		return x.InboundTag
+	}
+	return ""
+}
+
+// Reality响应，公钥由入站当前的私钥推导
+type RealityResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	Enabled       bool                   `protobuf:"varint,3,opt,name=enabled,proto3" json:"enabled,omitempty"` // 入站是否启用了Reality
+	PublicKey     string                 `protobuf:"bytes,4,opt,name=public_key,json=publicKey,proto3" json:"public_key,omitempty"`
+	ShortIds      []string               `protobuf:"bytes,5,rep,name=short_ids,json=shortIds,proto3" json:"short_ids,omitempty"`
+	ServerName    string                 `protobuf:"bytes,6,opt,name=server_name,json=serverName,proto3" json:"server_name,omitempty"` // 客户端使用的SNI
+	Phases        []*ApplyPhase          `protobuf:"bytes,7,rep,name=phases,proto3" json:"phases,omitempty"`                           // 应用流水线阶段结果
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RealityResponse) Reset() {
+	*x = RealityResponse{}
+	mi := &file_proto_agent_proto_msgTypes[71]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RealityResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RealityResponse) ProtoMessage() {}
+
+func (x *RealityResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_agent_proto_msgTypes[71]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RealityResponse.ProtoReflect.Descriptor instead.
+func (*RealityResponse) Descriptor() ([]byte, []int) {
+	return file_proto_agent_proto_rawDescGZIP(), []int{71}
+}
+
+func (x *RealityResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *RealityResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *RealityResponse) GetEnabled() bool {
+	if x != nil {
+		return x.Enabled
+	}
+	return false
+}
+
+func (x *RealityResponse) GetPublicKey() string {
+	if x != nil {
+		return x.PublicKey
+	}
+	return ""
+}
+
+func (x *RealityResponse) GetShortIds() []string {
+	if x != nil {
+		return x.ShortIds
+	}
+	return nil
+}
+
+func (x *RealityResponse) GetServerName() string {
+	if x != nil {
+		return x.ServerName
+	}
+	return ""
+}
+
+func (x *RealityResponse) GetPhases() []*ApplyPhase {
+	if x != nil {
+		return x.Phases
+	}
+	return nil
+}
+
+// 入站定义查询请求
+type InboundsQuery struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AgentId       string                 `protobuf:"bytes,1,opt,name=agent_id,json=agentId,proto3" json:"agent_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *InboundsQuery) Reset() {
+	*x = InboundsQuery{}
+	mi := &file_proto_agent_proto_msgTypes[72]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *InboundsQuery) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InboundsQuery) ProtoMessage() {}
+
+func (x *InboundsQuery) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_agent_proto_msgTypes[72]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InboundsQuery.ProtoReflect.Descriptor instead.
+func (*InboundsQuery) Descriptor() ([]byte, []int) {
+	return file_proto_agent_proto_rawDescGZIP(), []int{72}
+}
+
+func (x *InboundsQuery) GetAgentId() string {
+	if x != nil {
+		return x.AgentId
+	}
+	return ""
+}
+
+// 入站定义
+type InboundDefinition struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Instance         string                 `protobuf:"bytes,1,opt,name=instance,proto3" json:"instance,omitempty"`
+	Tag              string                 `protobuf:"bytes,2,opt,name=tag,proto3" json:"tag,omitempty"`
+	Type             string                 `protobuf:"bytes,3,opt,name=type,proto3" json:"type,omitempty"`
+	Config           string                 `protobuf:"bytes,4,opt,name=config,proto3" json:"config,omitempty"`                                               // sing-box入站JSON，不含Reality私钥
+	RealityPublicKey string                 `protobuf:"bytes,5,opt,name=reality_public_key,json=realityPublicKey,proto3" json:"reality_public_key,omitempty"` // 由Reality私钥推导的公钥，未启用Reality时为空
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *InboundDefinition) Reset() {
+	*x = InboundDefinition{}
+	mi := &file_proto_agent_proto_msgTypes[73]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *InboundDefinition) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InboundDefinition) ProtoMessage() {}
+
+func (x *InboundDefinition) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_agent_proto_msgTypes[73]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InboundDefinition.ProtoReflect.Descriptor instead.
+func (*InboundDefinition) Descriptor() ([]byte, []int) {
+	return file_proto_agent_proto_rawDescGZIP(), []int{73}
+}
+
+func (x *InboundDefinition) GetInstance() string {
+	if x != nil {
+		return x.Instance
+	}
+	return ""
+}
+
+func (x *InboundDefinition) GetTag() string {
+	if x != nil {
+		return x.Tag
+	}
+	return ""
+}
+
+func (x *InboundDefinition) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *InboundDefinition) GetConfig() string {
+	if x != nil {
+		return x.Config
+	}
+	return ""
+}
+
+func (x *InboundDefinition) GetRealityPublicKey() string {
+	if x != nil {
+		return x.RealityPublicKey
+	}
+	return ""
+}
+
+// 入站定义查询响应
+type InboundsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	Inbounds      []*InboundDefinition   `protobuf:"bytes,3,rep,name=inbounds,proto3" json:"inbounds,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *InboundsResponse) Reset() {
+	*x = InboundsResponse{}
+	mi := &file_proto_agent_proto_msgTypes[74]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *InboundsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InboundsResponse) ProtoMessage() {}
+
+func (x *InboundsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_agent_proto_msgTypes[74]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InboundsResponse.ProtoReflect.Descriptor instead.
+func (*InboundsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_agent_proto_rawDescGZIP(), []int{74}
+}
+
+func (x *InboundsResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *InboundsResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *InboundsResponse) GetInbounds() []*InboundDefinition {
+	if x != nil {
+		return x.Inbounds
+	}
+	return nil
+}
+
+var File_proto_agent_proto protoreflect.FileDescriptor
+
+const file_proto_agent_proto_rawDesc = "" +
+	"\n" +
+	"\x11proto/agent.proto\x12\x05agent\"\xb8\x02\n" +
+	"\x0fRegisterRequest\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\x1a\n" +
+	"\bhostname\x18\x02 \x01(\tR\bhostname\x12\x1d\n" +
+	"\n" +
+	"ip_address\x18\x03 \x01(\tR\tipAddress\x12\x18\n" +
+	"\aversion\x18\x04 \x01(\tR\aversion\x12@\n" +
+	"\bmetadata\x18\x05 \x03(\v2$.agent.RegisterRequest.MetadataEntryR\bmetadata\x126\n" +
+	"\rip_range_info\x18\x06 \x01(\v2\x12.agent.IPRangeInfoR\vipRangeInfo\x1a;\n" +
+	"\rMetadataEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\\\n" +
+	"\x10RegisterResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12\x14\n" +
+	"\x05token\x18\x03 \x01(\tR\x05token\"\xb6\x03\n" +
+	"\x10HeartbeatRequest\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\x16\n" +
+	"\x06status\x18\x02 \x01(\tR\x06status\x12>\n" +
+	"\ametrics\x18\x03 \x03(\v2$.agent.HeartbeatRequest.MetricsEntryR\ametrics\x126\n" +
+	"\rip_range_info\x18\x04 \x01(\v2\x12.agent.IPRangeInfoR\vipRangeInfo\x12A\n" +
+	"\x10connection_stats\x18\x05 \x01(\v2\x16.agent.ConnectionStatsR\x0fconnectionStats\x123\n" +
+	"\tinstances\x18\x06 \x03(\v2\x15.agent.InstanceStatusR\tinstances\x12C\n" +
+	"\x0foutbound_probes\x18\a \x03(\v2\x1a.agent.OutboundProbeResultR\x0eoutboundProbes\x1a:\n" +
+	"\fMetricsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\x7f\n" +
+	"\x11HeartbeatResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x126\n" +
+	"\x17next_heartbeat_interval\x18\x03 \x01(\x03R\x15nextHeartbeatInterval\"\xb7\x01\n" +
+	"\rConfigRequest\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12%\n" +
+	"\x0econfig_content\x18\x02 \x01(\tR\rconfigContent\x12%\n" +
+	"\x0econfig_version\x18\x03 \x01(\tR\rconfigVersion\x12!\n" +
+	"\fforce_update\x18\x04 \x01(\bR\vforceUpdate\x12\x1a\n" +
+	"\binstance\x18\x05 \x01(\tR\binstance\"\xf0\x01\n" +
+	"\x0eConfigResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12'\n" +
+	"\x0fapplied_version\x18\x03 \x01(\tR\x0eappliedVersion\x12)\n" +
+	"\x06phases\x18\x04 \x03(\v2\x11.agent.ApplyPhaseR\x06phases\x12\x1a\n" +
+	"\breverted\x18\x05 \x01(\bR\breverted\x12:\n" +
+	"\x0eport_conflicts\x18\x06 \x03(\v2\x13.agent.PortConflictR\rportConflicts\"\xb6\x01\n" +
+	"\fPortConflict\x12\x18\n" +
+	"\ainbound\x18\x01 \x01(\tR\ainbound\x12\x1a\n" +
+	"\bprotocol\x18\x02 \x01(\tR\bprotocol\x12\x16\n" +
+	"\x06listen\x18\x03 \x01(\tR\x06listen\x12\x12\n" +
+	"\x04port\x18\x04 \x01(\x05R\x04port\x12\x18\n" +
+	"\aaddress\x18\x05 \x01(\tR\aaddress\x12\x10\n" +
+	"\x03pid\x18\x06 \x01(\x05R\x03pid\x12\x18\n" +
+	"\aprocess\x18\a \x01(\tR\aprocess\"\x91\x01\n" +
+	"\n" +
+	"ApplyPhase\x12\x14\n" +
+	"\x05phase\x18\x01 \x01(\tR\x05phase\x12\x18\n" +
+	"\asuccess\x18\x02 \x01(\bR\asuccess\x12\x18\n" +
+	"\askipped\x18\x03 \x01(\bR\askipped\x12\x18\n" +
+	"\amessage\x18\x04 \x01(\tR\amessage\x12\x1f\n" +
+	"\vduration_ms\x18\x05 \x01(\x03R\n" +
+	"durationMs\"\x86\x01\n" +
+	"\fRulesRequest\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12!\n" +
+	"\x05rules\x18\x02 \x03(\v2\v.agent.RuleR\x05rules\x12\x1c\n" +
+	"\toperation\x18\x03 \x01(\tR\toperation\x12\x1a\n" +
+	"\binstance\x18\x04 \x01(\tR\binstance\"f\n" +
+	"\rRulesResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12!\n" +
+	"\ffailed_rules\x18\x03 \x03(\tR\vfailedRules\"F\n" +
+	"\rStatusRequest\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\x1a\n" +
+	"\binstance\x18\x02 \x01(\tR\binstance\"\x90\x03\n" +
+	"\x0eStatusResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x19\n" +
+	"\bagent_id\x18\x02 \x01(\tR\aagentId\x12\x16\n" +
+	"\x06status\x18\x03 \x01(\tR\x06status\x12%\n" +
+	"\x0econfig_version\x18\x04 \x01(\tR\rconfigVersion\x12\x1f\n" +
+	"\vrules_count\x18\x05 \x01(\x05R\n" +
+	"rulesCount\x12F\n" +
+	"\vsystem_info\x18\x06 \x03(\v2%.agent.StatusResponse.SystemInfoEntryR\n" +
+	"systemInfo\x12-\n" +
+	"\x12active_connections\x18\a \x03(\tR\x11activeConnections\x123\n" +
+	"\tinstances\x18\b \x03(\v2\x15.agent.InstanceStatusR\tinstances\x1a=\n" +
+	"\x0fSystemInfoEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xee\x01\n" +
+	"\x04Rule\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04type\x18\x02 \x01(\tR\x04type\x12\x18\n" +
+	"\acontent\x18\x03 \x01(\tR\acontent\x12\x1a\n" +
+	"\bpriority\x18\x04 \x01(\x05R\bpriority\x12\x18\n" +
+	"\aenabled\x18\x05 \x01(\bR\aenabled\x125\n" +
+	"\bmetadata\x18\x06 \x03(\v2\x19.agent.Rule.MetadataEntryR\bmetadata\x1a;\n" +
+	"\rMetadataEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xc5\x01\n" +
+	"\x10BlacklistRequest\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\x1a\n" +
+	"\bprotocol\x18\x02 \x01(\tR\bprotocol\x12\x18\n" +
+	"\adomains\x18\x03 \x03(\tR\adomains\x12\x10\n" +
+	"\x03ips\x18\x04 \x03(\tR\x03ips\x12\x14\n" +
+	"\x05ports\x18\x05 \x03(\tR\x05ports\x12\x1c\n" +
+	"\toperation\x18\x06 \x01(\tR\toperation\x12\x1a\n" +
+	"\binstance\x18\a \x01(\tR\binstance\"n\n" +
+	"\x11BlacklistResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12%\n" +
+	"\x0econfig_version\x18\x03 \x01(\tR\rconfigVersion\"\xc5\x01\n" +
+	"\x10WhitelistRequest\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\x1a\n" +
+	"\bprotocol\x18\x02 \x01(\tR\bprotocol\x12\x18\n" +
+	"\adomains\x18\x03 \x03(\tR\adomains\x12\x10\n" +
+	"\x03ips\x18\x04 \x03(\tR\x03ips\x12\x14\n" +
+	"\x05ports\x18\x05 \x03(\tR\x05ports\x12\x1c\n" +
+	"\toperation\x18\x06 \x01(\tR\toperation\x12\x1a\n" +
+	"\binstance\x18\a \x01(\tR\binstance\"n\n" +
+	"\x11WhitelistResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12%\n" +
+	"\x0econfig_version\x18\x03 \x01(\tR\rconfigVersion\"h\n" +
+	"\x13FilterConfigRequest\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\x1a\n" +
+	"\bprotocol\x18\x02 \x01(\tR\bprotocol\x12\x1a\n" +
+	"\binstance\x18\x03 \x01(\tR\binstance\"{\n" +
+	"\x14FilterConfigResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12/\n" +
+	"\afilters\x18\x03 \x03(\v2\x15.agent.ProtocolFilterR\afilters\"\xdf\x02\n" +
+	"\x0eProtocolFilter\x12\x1a\n" +
+	"\bprotocol\x18\x01 \x01(\tR\bprotocol\x12+\n" +
+	"\x11blacklist_domains\x18\x02 \x03(\tR\x10blacklistDomains\x12#\n" +
+	"\rblacklist_ips\x18\x03 \x03(\tR\fblacklistIps\x12'\n" +
+	"\x0fblacklist_ports\x18\x04 \x03(\tR\x0eblacklistPorts\x12+\n" +
+	"\x11whitelist_domains\x18\x05 \x03(\tR\x10whitelistDomains\x12#\n" +
+	"\rwhitelist_ips\x18\x06 \x03(\tR\fwhitelistIps\x12'\n" +
+	"\x0fwhitelist_ports\x18\a \x03(\tR\x0ewhitelistPorts\x12\x18\n" +
+	"\aenabled\x18\b \x01(\bR\aenabled\x12!\n" +
+	"\flast_updated\x18\t \x01(\tR\vlastUpdated\"\x9d\x01\n" +
+	"\x0fRollbackRequest\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12%\n" +
+	"\x0etarget_version\x18\x02 \x01(\tR\rtargetVersion\x12\x16\n" +
+	"\x06reason\x18\x03 \x01(\tR\x06reason\x12\x14\n" +
+	"\x05scope\x18\x04 \x01(\tR\x05scope\x12\x1a\n" +
+	"\binstance\x18\x05 \x01(\tR\binstance\"\xca\x01\n" +
+	"\x10RollbackResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12.\n" +
+	"\x13rolled_back_version\x18\x03 \x01(\tR\x11rolledBackVersion\x12'\n" +
+	"\x0fcurrent_version\x18\x04 \x01(\tR\x0ecurrentVersion\x12)\n" +
+	"\x06phases\x18\x05 \x03(\v2\x11.agent.ApplyPhaseR\x06phases\"\xae\x01\n" +
+	"\x16MultiplexConfigRequest\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\x1a\n" +
+	"\bprotocol\x18\x02 \x01(\tR\bprotocol\x12A\n" +
+	"\x10multiplex_config\x18\x03 \x01(\v2\x16.agent.MultiplexConfigR\x0fmultiplexConfig\x12\x1a\n" +
+	"\binstance\x18\x04 \x01(\tR\binstance\"t\n" +
+	"\x17MultiplexConfigResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12%\n" +
+	"\x0econfig_version\x18\x03 \x01(\tR\rconfigVersion\"k\n" +
+	"\x16MultiplexStatusRequest\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\x1a\n" +
+	"\bprotocol\x18\x02 \x01(\tR\bprotocol\x12\x1a\n" +
+	"\binstance\x18\x03 \x01(\tR\binstance\"\x94\x01\n" +
+	"\x17MultiplexStatusResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12E\n" +
+	"\x11multiplex_configs\x18\x03 \x03(\v2\x18.agent.ProtocolMultiplexR\x10multiplexConfigs\"\xa2\x02\n" +
+	"\x0fMultiplexConfig\x12\x18\n" +
+	"\aenabled\x18\x01 \x01(\bR\aenabled\x12\x1a\n" +
+	"\bprotocol\x18\x02 \x01(\tR\bprotocol\x12'\n" +
+	"\x0fmax_connections\x18\x03 \x01(\x05R\x0emaxConnections\x12\x1f\n" +
+	"\vmin_streams\x18\x04 \x01(\x05R\n" +
+	"minStreams\x12\x18\n" +
+	"\apadding\x18\x05 \x01(\bR\apadding\x12:\n" +
+	"\x06brutal\x18\x06 \x03(\v2\".agent.MultiplexConfig.BrutalEntryR\x06brutal\x1a9\n" +
+	"\vBrutalEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xaf\x01\n" +
+	"\x11ProtocolMultiplex\x12\x1a\n" +
+	"\bprotocol\x18\x01 \x01(\tR\bprotocol\x12A\n" +
+	"\x10multiplex_config\x18\x02 \x01(\v2\x16.agent.MultiplexConfigR\x0fmultiplexConfig\x12\x18\n" +
+	"\aenabled\x18\x03 \x01(\bR\aenabled\x12!\n" +
+	"\flast_updated\x18\x04 \x01(\tR\vlastUpdated\"\xcc\x01\n" +
+	"\vIPRangeInfo\x12\x19\n" +
+	"\bip_range\x18\x01 \x01(\tR\aipRange\x12\x18\n" +
+	"\acountry\x18\x02 \x01(\tR\acountry\x12\x16\n" +
+	"\x06region\x18\x03 \x01(\tR\x06region\x12\x12\n" +
+	"\x04city\x18\x04 \x01(\tR\x04city\x12\x10\n" +
+	"\x03isp\x18\x05 \x01(\tR\x03isp\x12)\n" +
+	"\x10detection_method\x18\x06 \x01(\tR\x0fdetectionMethod\x12\x1f\n" +
+	"\vdetected_at\x18\a \x01(\tR\n" +
+	"detectedAt\"\x97\x01\n" +
+	"\x10UninstallRequest\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12'\n" +
+	"\x0fforce_uninstall\x18\x02 \x01(\bR\x0eforceUninstall\x12\x16\n" +
+	"\x06reason\x18\x03 \x01(\tR\x06reason\x12'\n" +
+	"\x0ftimeout_seconds\x18\x04 \x01(\x05R\x0etimeoutSeconds\"\xba\x01\n" +
+	"\x11UninstallResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12)\n" +
+	"\x10uninstall_status\x18\x03 \x01(\tR\x0funinstallStatus\x12#\n" +
+	"\rcleaned_files\x18\x04 \x03(\tR\fcleanedFiles\x12!\n" +
+	"\fcleanup_time\x18\x05 \x01(\x03R\vcleanupTime\"Q\n" +
+	"\x18ConfigGenerationsRequest\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\x1a\n" +
+	"\binstance\x18\x02 \x01(\tR\binstance\"\xa9\x01\n" +
+	"\x10ConfigGeneration\x12\x18\n" +
+	"\aversion\x18\x01 \x01(\x03R\aversion\x12\x1d\n" +
+	"\n" +
+	"applied_at\x18\x02 \x01(\tR\tappliedAt\x12\x16\n" +
+	"\x06source\x18\x03 \x01(\tR\x06source\x12\x16\n" +
+	"\x06sha256\x18\x04 \x01(\tR\x06sha256\x12\x12\n" +
+	"\x04size\x18\x05 \x01(\x03R\x04size\x12\x18\n" +
+	"\acurrent\x18\x06 \x01(\bR\acurrent\"\xb3\x01\n" +
+	"\x19ConfigGenerationsResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x129\n" +
+	"\vgenerations\x18\x03 \x03(\v2\x17.agent.ConfigGenerationR\vgenerations\x12'\n" +
+	"\x0fcurrent_version\x18\x04 \x01(\x03R\x0ecurrentVersion\"\x8c\x01\n" +
+	"\x11ConfigDiffRequest\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12!\n" +
+	"\ffrom_version\x18\x02 \x01(\x03R\vfromVersion\x12\x1d\n" +
+	"\n" +
+	"to_version\x18\x03 \x01(\x03R\ttoVersion\x12\x1a\n" +
+	"\binstance\x18\x04 \x01(\tR\binstance\"\\\n" +
+	"\x12ConfigDiffResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12\x12\n" +
+	"\x04diff\x18\x03 \x01(\tR\x04diff\"\xa5\x01\n" +
+	"\x10LogStreamRequest\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\x14\n" +
+	"\x05level\x18\x02 \x01(\tR\x05level\x12\x18\n" +
+	"\akeyword\x18\x03 \x01(\tR\akeyword\x12\x12\n" +
+	"\x04tail\x18\x04 \x01(\x05R\x04tail\x12\x16\n" +
+	"\x06follow\x18\x05 \x01(\bR\x06follow\x12\x1a\n" +
+	"\binstance\x18\x06 \x01(\tR\binstance\"\x89\x01\n" +
+	"\x0fSingboxLogEntry\x12\x10\n" +
+	"\x03seq\x18\x01 \x01(\x04R\x03seq\x12\x1c\n" +
+	"\ttimestamp\x18\x02 \x01(\tR\ttimestamp\x12\x14\n" +
+	"\x05level\x18\x03 \x01(\tR\x05level\x12\x18\n" +
+	"\amessage\x18\x04 \x01(\tR\amessage\x12\x16\n" +
+	"\x06stream\x18\x05 \x01(\tR\x06stream\"\xb2\x01\n" +
+	"\vInboundUser\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x1a\n" +
+	"\busername\x18\x02 \x01(\tR\busername\x12\x1a\n" +
+	"\bpassword\x18\x03 \x01(\tR\bpassword\x12\x12\n" +
+	"\x04uuid\x18\x04 \x01(\tR\x04uuid\x12\x12\n" +
+	"\x04flow\x18\x05 \x01(\tR\x04flow\x12\x19\n" +
+	"\balter_id\x18\x06 \x01(\x05R\aalterId\x12\x14\n" +
+	"\x05extra\x18\a \x01(\tR\x05extra\"\xd1\x01\n" +
+	"\x13InboundUsersRequest\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\x1f\n" +
+	"\vinbound_tag\x18\x02 \x01(\tR\n" +
+	"inboundTag\x12\x1c\n" +
+	"\toperation\x18\x03 \x01(\tR\toperation\x12(\n" +
+	"\x05users\x18\x04 \x03(\v2\x12.agent.InboundUserR\x05users\x12\x1a\n" +
+	"\binstance\x18\x05 \x01(\tR\binstance\x12\x1a\n" +
+	"\brevision\x18\x06 \x01(\tR\brevision\"k\n" +
+	"\x11InboundUsersQuery\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\x1f\n" +
+	"\vinbound_tag\x18\x02 \x01(\tR\n" +
+	"inboundTag\x12\x1a\n" +
+	"\binstance\x18\x03 \x01(\tR\binstance\"\xac\x02\n" +
+	"\x14InboundUsersResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12\x1f\n" +
+	"\vinbound_tag\x18\x03 \x01(\tR\n" +
+	"inboundTag\x12!\n" +
+	"\finbound_type\x18\x04 \x01(\tR\vinboundType\x12(\n" +
+	"\x05users\x18\x05 \x03(\v2\x12.agent.InboundUserR\x05users\x12)\n" +
+	"\x06phases\x18\x06 \x03(\v2\x11.agent.ApplyPhaseR\x06phases\x12\x1a\n" +
+	"\brevision\x18\a \x01(\tR\brevision\x12+\n" +
+	"\x11revision_mismatch\x18\b \x01(\bR\x10revisionMismatch\"\xbf\x03\n" +
+	"\x0eInstanceStatus\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x14\n" +
+	"\x05state\x18\x02 \x01(\tR\x05state\x12\x18\n" +
+	"\arunning\x18\x03 \x01(\bR\arunning\x12\x10\n" +
+	"\x03pid\x18\x04 \x01(\x05R\x03pid\x12!\n" +
+	"\fprocess_mode\x18\x05 \x01(\tR\vprocessMode\x12\x1f\n" +
+	"\vbinary_path\x18\x06 \x01(\tR\n" +
+	"binaryPath\x12\x1f\n" +
+	"\vconfig_path\x18\a \x01(\tR\n" +
+	"configPath\x12%\n" +
+	"\x0econfig_version\x18\b \x01(\x03R\rconfigVersion\x12\x1a\n" +
+	"\brestarts\x18\t \x01(\x05R\brestarts\x12A\n" +
+	"\x10connection_stats\x18\n" +
+	" \x01(\v2\x16.agent.ConnectionStatsR\x0fconnectionStats\x12;\n" +
+	"\x0eresource_usage\x18\v \x01(\v2\x14.agent.ResourceUsageR\rresourceUsage\x12/\n" +
+	"\x14last_exit_oom_killed\x18\f \x01(\bR\x11lastExitOomKilled\"\xbf\x02\n" +
+	"\rResourceUsage\x12\x16\n" +
+	"\x06cgroup\x18\x01 \x01(\tR\x06cgroup\x12%\n" +
+	"\x0ememory_current\x18\x02 \x01(\x03R\rmemoryCurrent\x12\x1d\n" +
+	"\n" +
+	"memory_max\x18\x03 \x01(\x03R\tmemoryMax\x12$\n" +
+	"\x0ecpu_usage_usec\x18\x04 \x01(\x03R\fcpuUsageUsec\x12,\n" +
+	"\x12cpu_throttled_usec\x18\x05 \x01(\x03R\x10cpuThrottledUsec\x12!\n" +
+	"\fnr_throttled\x18\x06 \x01(\x03R\vnrThrottled\x12!\n" +
+	"\fpids_current\x18\a \x01(\x03R\vpidsCurrent\x12\x19\n" +
+	"\bpids_max\x18\b \x01(\x03R\apidsMax\x12\x1b\n" +
+	"\toom_kills\x18\t \x01(\x03R\boomKills\"\x85\x03\n" +
+	"\x0fConnectionStats\x12\x1c\n" +
+	"\tavailable\x18\x01 \x01(\bR\tavailable\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\x12+\n" +
+	"\x11total_connections\x18\x03 \x01(\x05R\x10totalConnections\x12\x1f\n" +
+	"\vupload_rate\x18\x04 \x01(\x03R\n" +
+	"uploadRate\x12#\n" +
+	"\rdownload_rate\x18\x05 \x01(\x03R\fdownloadRate\x12!\n" +
+	"\fupload_total\x18\x06 \x01(\x03R\vuploadTotal\x12%\n" +
+	"\x0edownload_total\x18\a \x01(\x03R\rdownloadTotal\x12-\n" +
+	"\binbounds\x18\b \x03(\v2\x11.agent.TagTrafficR\binbounds\x12/\n" +
+	"\toutbounds\x18\t \x03(\v2\x11.agent.TagTrafficR\toutbounds\x12!\n" +
+	"\fcollected_at\x18\n" +
+	" \x01(\x03R\vcollectedAt\"\x86\x01\n" +
+	"\n" +
+	"TagTraffic\x12\x10\n" +
+	"\x03tag\x18\x01 \x01(\tR\x03tag\x12 \n" +
+	"\vconnections\x18\x02 \x01(\x05R\vconnections\x12\x1f\n" +
+	"\vupload_rate\x18\x03 \x01(\x03R\n" +
+	"uploadRate\x12#\n" +
+	"\rdownload_rate\x18\x04 \x01(\x03R\fdownloadRate\"\x91\x03\n" +
+	"\x13OutboundProbeResult\x12\x1a\n" +
+	"\binstance\x18\x01 \x01(\tR\binstance\x12\x1a\n" +
+	"\boutbound\x18\x02 \x01(\tR\boutbound\x12\x1f\n" +
+	"\vtarget_type\x18\x03 \x01(\tR\n" +
+	"targetType\x12\x16\n" +
+	"\x06target\x18\x04 \x01(\tR\x06target\x12\x16\n" +
+	"\x06probes\x18\x05 \x01(\x05R\x06probes\x12\x1c\n" +
+	"\tsuccesses\x18\x06 \x01(\x05R\tsuccesses\x12$\n" +
+	"\x0eavg_latency_ms\x18\a \x01(\x01R\favgLatencyMs\x12$\n" +
+	"\x0emax_latency_ms\x18\b \x01(\x03R\fmaxLatencyMs\x12&\n" +
+	"\x0flast_latency_ms\x18\t \x01(\x03R\rlastLatencyMs\x12\x1d\n" +
+	"\n" +
+	"last_error\x18\n" +
+	" \x01(\tR\tlastError\x12!\n" +
+	"\fperiod_start\x18\v \x01(\x03R\vperiodStart\x12\x1d\n" +
+	"\n" +
+	"period_end\x18\f \x01(\x03R\tperiodEnd\"\xdd\x01\n" +
+	"\x17CloseConnectionsRequest\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\x12\n" +
+	"\x04host\x18\x02 \x01(\tR\x04host\x12\x1b\n" +
+	"\tsource_ip\x18\x03 \x01(\tR\bsourceIp\x12\x12\n" +
+	"\x04rule\x18\x04 \x01(\tR\x04rule\x12\x18\n" +
+	"\ainbound\x18\x05 \x01(\tR\ainbound\x12\x1a\n" +
+	"\boutbound\x18\x06 \x01(\tR\boutbound\x12\x10\n" +
+	"\x03all\x18\a \x01(\bR\x03all\x12\x1a\n" +
+	"\binstance\x18\b \x01(\tR\binstance\"\x8d\x01\n" +
+	"\x18CloseConnectionsResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12\x16\n" +
+	"\x06closed\x18\x03 \x01(\x05R\x06closed\x12%\n" +
+	"\x0econnection_ids\x18\x04 \x03(\tR\rconnectionIds\"\xba\x01\n" +
+	"\x12TrafficUsageReport\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12!\n" +
+	"\fperiod_start\x18\x02 \x01(\x03R\vperiodStart\x12\x1d\n" +
+	"\n" +
+	"period_end\x18\x03 \x01(\x03R\tperiodEnd\x12+\n" +
+	"\x06usages\x18\x04 \x03(\v2\x13.agent.TrafficUsageR\x06usages\x12\x1a\n" +
+	"\binstance\x18\x05 \x01(\tR\binstance\"l\n" +
+	"\fTrafficUsage\x12\x14\n" +
+	"\x05scope\x18\x01 \x01(\tR\x05scope\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x16\n" +
+	"\x06uplink\x18\x03 \x01(\x03R\x06uplink\x12\x1a\n" +
+	"\bdownlink\x18\x04 \x01(\x03R\bdownlink\"J\n" +
+	"\x14TrafficUsageResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\"y\n" +
+	"\x0eUpgradeRequest\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\x18\n" +
+	"\aversion\x18\x02 \x01(\tR\aversion\x12\x16\n" +
+	"\x06sha256\x18\x03 \x01(\tR\x06sha256\x12\x1a\n" +
+	"\binstance\x18\x04 \x01(\tR\binstance\"\xdd\x01\n" +
+	"\x0fUpgradeResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12!\n" +
+	"\ffrom_version\x18\x03 \x01(\tR\vfromVersion\x12'\n" +
+	"\x0fcurrent_version\x18\x04 \x01(\tR\x0ecurrentVersion\x12\x1f\n" +
+	"\vrolled_back\x18\x05 \x01(\bR\n" +
+	"rolledBack\x12)\n" +
+	"\x06phases\x18\x06 \x03(\v2\x11.agent.ApplyPhaseR\x06phases\"G\n" +
+	"\x0eDNSConfigQuery\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\x1a\n" +
+	"\binstance\x18\x02 \x01(\tR\binstance\"\xe4\x01\n" +
+	"\x11DNSServersRequest\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\x1a\n" +
+	"\binstance\x18\x02 \x01(\tR\binstance\x12\x1c\n" +
+	"\toperation\x18\x03 \x01(\tR\toperation\x12\x18\n" +
+	"\aservers\x18\x04 \x01(\tR\aservers\x12\x12\n" +
+	"\x04tags\x18\x05 \x03(\tR\x04tags\x12\x1a\n" +
+	"\bposition\x18\x06 \x01(\x05R\bposition\x12\x14\n" +
+	"\x05final\x18\a \x01(\tR\x05final\x12\x1a\n" +
+	"\bstrategy\x18\b \x01(\tR\bstrategy\"\xb6\x01\n" +
+	"\x0fDNSRulesRequest\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\x1a\n" +
+	"\binstance\x18\x02 \x01(\tR\binstance\x12\x1c\n" +
+	"\toperation\x18\x03 \x01(\tR\toperation\x12\x14\n" +
+	"\x05rules\x18\x04 \x01(\tR\x05rules\x12\x1c\n" +
+	"\tpositions\x18\x05 \x03(\x05R\tpositions\x12\x1a\n" +
+	"\bposition\x18\x06 \x01(\x05R\bposition\"\xa2\x01\n" +
+	"\rFakeIPRequest\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\x1a\n" +
+	"\binstance\x18\x02 \x01(\tR\binstance\x12\x18\n" +
+	"\aenabled\x18\x03 \x01(\bR\aenabled\x12\x1f\n" +
+	"\vinet4_range\x18\x04 \x01(\tR\n" +
+	"inet4Range\x12\x1f\n" +
+	"\vinet6_range\x18\x05 \x01(\tR\n" +
+	"inet6Range\"\xc7\x01\n" +
+	"\x11DNSConfigResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12\x1d\n" +
+	"\n" +
+	"dns_config\x18\x03 \x01(\tR\tdnsConfig\x12\x18\n" +
+	"\aversion\x18\x04 \x01(\tR\aversion\x12\x1a\n" +
+	"\bversions\x18\x05 \x03(\tR\bversions\x12)\n" +
+	"\x06phases\x18\x06 \x03(\v2\x11.agent.ApplyPhaseR\x06phases\"\x9c\x02\n" +
+	"\rOutboundGroup\x12\x10\n" +
+	"\x03tag\x18\x01 \x01(\tR\x03tag\x12\x12\n" +
+	"\x04type\x18\x02 \x01(\tR\x04type\x12\x1c\n" +
+	"\toutbounds\x18\x03 \x03(\tR\toutbounds\x12\x18\n" +
+	"\adefault\x18\x04 \x01(\tR\adefault\x12\x10\n" +
+	"\x03url\x18\x05 \x01(\tR\x03url\x12\x1a\n" +
+	"\binterval\x18\x06 \x01(\tR\binterval\x12\x1c\n" +
+	"\ttolerance\x18\a \x01(\rR\ttolerance\x12!\n" +
+	"\fidle_timeout\x18\b \x01(\tR\vidleTimeout\x12>\n" +
+	"\x1binterrupt_exist_connections\x18\t \x01(\bR\x19interruptExistConnections\"\x97\x01\n" +
+	"\x14OutboundGroupRequest\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\x1a\n" +
+	"\binstance\x18\x02 \x01(\tR\binstance\x12\x1c\n" +
+	"\toperation\x18\x03 \x01(\tR\toperation\x12*\n" +
+	"\x05group\x18\x04 \x01(\v2\x14.agent.OutboundGroupR\x05group\"\x96\x01\n" +
+	"\x13GroupMembersRequest\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\x1a\n" +
+	"\binstance\x18\x02 \x01(\tR\binstance\x12\x10\n" +
+	"\x03tag\x18\x03 \x01(\tR\x03tag\x12\x1c\n" +
+	"\toperation\x18\x04 \x01(\tR\toperation\x12\x18\n" +
+	"\amembers\x18\x05 \x03(\tR\amembers\"\xa2\x01\n" +
+	"\x15OutboundGroupResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12*\n" +
+	"\x05group\x18\x03 \x01(\v2\x14.agent.OutboundGroupR\x05group\x12)\n" +
+	"\x06phases\x18\x04 \x03(\v2\x11.agent.ApplyPhaseR\x06phases\"\xb7\x01\n" +
+	"\x13OutboundGroupsQuery\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\x1a\n" +
+	"\binstance\x18\x02 \x01(\tR\binstance\x12\x10\n" +
+	"\x03tag\x18\x03 \x01(\tR\x03tag\x12\x1d\n" +
+	"\n" +
+	"test_delay\x18\x04 \x01(\bR\ttestDelay\x12\x19\n" +
+	"\btest_url\x18\x05 \x01(\tR\atestUrl\x12\x1d\n" +
+	"\n" +
+	"timeout_ms\x18\x06 \x01(\x05R\ttimeoutMs\"h\n" +
+	"\vMemberDelay\x12\x10\n" +
+	"\x03tag\x18\x01 \x01(\tR\x03tag\x12\x14\n" +
+	"\x05delay\x18\x02 \x01(\x05R\x05delay\x12\x1b\n" +
+	"\ttested_at\x18\x03 \x01(\x03R\btestedAt\x12\x14\n" +
+	"\x05error\x18\x04 \x01(\tR\x05error\"\x81\x01\n" +
+	"\x13OutboundGroupStatus\x12*\n" +
+	"\x05group\x18\x01 \x01(\v2\x14.agent.OutboundGroupR\x05group\x12\x10\n" +
+	"\x03now\x18\x02 \x01(\tR\x03now\x12,\n" +
+	"\amembers\x18\x03 \x03(\v2\x12.agent.MemberDelayR\amembers\"\xd2\x01\n" +
+	"\x16OutboundGroupsResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x122\n" +
+	"\x06groups\x18\x03 \x03(\v2\x1a.agent.OutboundGroupStatusR\x06groups\x12+\n" +
+	"\x11runtime_available\x18\x04 \x01(\bR\x10runtimeAvailable\x12#\n" +
+	"\rruntime_error\x18\x05 \x01(\tR\fruntimeError\"\xe4\x01\n" +
+	"\x12CertificateRequest\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\x1a\n" +
+	"\binstance\x18\x02 \x01(\tR\binstance\x12\x1f\n" +
+	"\vinbound_tag\x18\x03 \x01(\tR\n" +
+	"inboundTag\x12\x12\n" +
+	"\x04name\x18\x04 \x01(\tR\x04name\x12'\n" +
+	"\x0fcertificate_pem\x18\x05 \x01(\tR\x0ecertificatePem\x12\x17\n" +
+	"\akey_pem\x18\x06 \x01(\tR\x06keyPem\x12 \n" +
+	"\vfingerprint\x18\a \x01(\tR\vfingerprint\"\xba\x01\n" +
+	"\x13CertificateResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12)\n" +
+	"\x10certificate_path\x18\x03 \x01(\tR\x0fcertificatePath\x12\x19\n" +
+	"\bkey_path\x18\x04 \x01(\tR\akeyPath\x12)\n" +
+	"\x06phases\x18\x05 \x03(\v2\x11.agent.ApplyPhaseR\x06phases\"\xf8\x01\n" +
+	"\x0eRealityRequest\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\x1a\n" +
+	"\binstance\x18\x02 \x01(\tR\binstance\x12\x1f\n" +
+	"\vinbound_tag\x18\x03 \x01(\tR\n" +
+	"inboundTag\x12\x1f\n" +
+	"\vprivate_key\x18\x04 \x01(\tR\n" +
+	"privateKey\x12\x1b\n" +
+	"\tshort_ids\x18\x05 \x03(\tR\bshortIds\x12)\n" +
+	"\x10handshake_server\x18\x06 \x01(\tR\x0fhandshakeServer\x12%\n" +
+	"\x0ehandshake_port\x18\a \x01(\rR\rhandshakePort\"f\n" +
+	"\fRealityQuery\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\x12\x1a\n" +
+	"\binstance\x18\x02 \x01(\tR\binstance\x12\x1f\n" +
+	"\vinbound_tag\x18\x03 \x01(\tR\n" +
+	"inboundTag\"\xe7\x01\n" +
+	"\x0fRealityResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12\x18\n" +
+	"\aenabled\x18\x03 \x01(\bR\aenabled\x12\x1d\n" +
+	"\n" +
+	"public_key\x18\x04 \x01(\tR\tpublicKey\x12\x1b\n" +
+	"\tshort_ids\x18\x05 \x03(\tR\bshortIds\x12\x1f\n" +
+	"\vserver_name\x18\x06 \x01(\tR\n" +
+	"serverName\x12)\n" +
+	"\x06phases\x18\a \x03(\v2\x11.agent.ApplyPhaseR\x06phases\"*\n" +
+	"\rInboundsQuery\x12\x19\n" +
+	"\bagent_id\x18\x01 \x01(\tR\aagentId\"\x9b\x01\n" +
+	"\x11InboundDefinition\x12\x1a\n" +
+	"\binstance\x18\x01 \x01(\tR\binstance\x12\x10\n" +
+	"\x03tag\x18\x02 \x01(\tR\x03tag\x12\x12\n" +
+	"\x04type\x18\x03 \x01(\tR\x04type\x12\x16\n" +
+	"\x06config\x18\x04 \x01(\tR\x06config\x12,\n" +
+	"\x12reality_public_key\x18\x05 \x01(\tR\x10realityPublicKey\"|\n" +
+	"\x10InboundsResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x124\n" +
+	"\binbounds\x18\x03 \x03(\v2\x18.agent.InboundDefinitionR\binbounds2\xc1\x11\n" +
+	"\fAgentService\x12@\n" +
+	"\rRegisterAgent\x12\x16.agent.RegisterRequest\x1a\x17.agent.RegisterResponse\x12>\n" +
+	"\tHeartbeat\x12\x17.agent.HeartbeatRequest\x1a\x18.agent.HeartbeatResponse\x12;\n" +
+	"\fUpdateConfig\x12\x14.agent.ConfigRequest\x1a\x15.agent.ConfigResponse\x128\n" +
+	"\vUpdateRules\x12\x13.agent.RulesRequest\x1a\x14.agent.RulesResponse\x128\n" +
+	"\tGetStatus\x12\x14.agent.StatusRequest\x1a\x15.agent.StatusResponse\x12D\n" +
+	"\x0fUpdateBlacklist\x12\x17.agent.BlacklistRequest\x1a\x18.agent.BlacklistResponse\x12D\n" +
+	"\x0fUpdateWhitelist\x12\x17.agent.WhitelistRequest\x1a\x18.agent.WhitelistResponse\x12J\n" +
 	"\x0fGetFilterConfig\x12\x1a.agent.FilterConfigRequest\x1a\x1b.agent.FilterConfigResponse\x12A\n" +
 	"\x0eRollbackConfig\x12\x16.agent.RollbackRequest\x1a\x17.agent.RollbackResponse\x12V\n" +
 	"\x15UpdateMultiplexConfig\x12\x1d.agent.MultiplexConfigRequest\x1a\x1e.agent.MultiplexConfigResponse\x12S\n" +
 	"\x12GetMultiplexConfig\x12\x1d.agent.MultiplexStatusRequest\x1a\x1e.agent.MultiplexStatusResponse\x12C\n" +
-	"\x0eUninstallAgent\x12\x17.agent.UninstallRequest\x1a\x18.agent.UninstallResponseB.Z,github.com/xbox/sing-box-manager/proto/agentb\x06proto3"
+	"\x0eUninstallAgent\x12\x17.agent.UninstallRequest\x1a\x18.agent.UninstallResponse\x12Z\n" +
+	"\x15ListConfigGenerations\x12\x1f.agent.ConfigGenerationsRequest\x1a .agent.ConfigGenerationsResponse\x12L\n" +
+	"\x15DiffConfigGenerations\x12\x18.agent.ConfigDiffRequest\x1a\x19.agent.ConfigDiffResponse\x12F\n" +
+	"\x11StreamSingboxLogs\x12\x17.agent.LogStreamRequest\x1a\x16.agent.SingboxLogEntry0\x01\x12M\n" +
+	"\x12UpdateInboundUsers\x12\x1a.agent.InboundUsersRequest\x1a\x1b.agent.InboundUsersResponse\x12H\n" +
+	"\x0fGetInboundUsers\x12\x18.agent.InboundUsersQuery\x1a\x1b.agent.InboundUsersResponse\x12S\n" +
+	"\x10CloseConnections\x12\x1e.agent.CloseConnectionsRequest\x1a\x1f.agent.CloseConnectionsResponse\x12L\n" +
+	"\x12ReportTrafficUsage\x12\x19.agent.TrafficUsageReport\x1a\x1b.agent.TrafficUsageResponse\x12?\n" +
+	"\x0eUpgradeSingbox\x12\x15.agent.UpgradeRequest\x1a\x16.agent.UpgradeResponse\x12?\n" +
+	"\fGetDNSConfig\x12\x15.agent.DNSConfigQuery\x1a\x18.agent.DNSConfigResponse\x12F\n" +
+	"\x10UpdateDNSServers\x12\x18.agent.DNSServersRequest\x1a\x18.agent.DNSConfigResponse\x12B\n" +
+	"\x0eUpdateDNSRules\x12\x16.agent.DNSRulesRequest\x1a\x18.agent.DNSConfigResponse\x12>\n" +
+	"\fUpdateFakeIP\x12\x14.agent.FakeIPRequest\x1a\x18.agent.DNSConfigResponse\x12N\n" +
+	"\x11GetOutboundGroups\x12\x1a.agent.OutboundGroupsQuery\x1a\x1d.agent.OutboundGroupsResponse\x12P\n" +
+	"\x13UpdateOutboundGroup\x12\x1b.agent.OutboundGroupRequest\x1a\x1c.agent.OutboundGroupResponse\x12N\n" +
+	"\x12UpdateGroupMembers\x12\x1a.agent.GroupMembersRequest\x1a\x1c.agent.OutboundGroupResponse\x12J\n" +
+	"\x11UpdateCertificate\x12\x19.agent.CertificateRequest\x1a\x1a.agent.CertificateResponse\x12>\n" +
+	"\rUpdateReality\x12\x15.agent.RealityRequest\x1a\x16.agent.RealityResponse\x129\n" +
+	"\n" +
+	"GetReality\x12\x13.agent.RealityQuery\x1a\x16.agent.RealityResponse\x12<\n" +
+	"\vGetInbounds\x12\x14.agent.InboundsQuery\x1a\x17.agent.InboundsResponseB.Z,github.com/xbox/sing-box-manager/proto/agentb\x06proto3"
 
 var (
 	file_proto_agent_proto_rawDescOnce sync.Once
@@ -2245,85 +6466,196 @@ func file_proto_agent_proto_rawDescGZIP() []byte {
 	return file_proto_agent_proto_rawDescData
 }
 
-var file_proto_agent_proto_msgTypes = make([]protoimpl.MessageInfo, 34)
+var file_proto_agent_proto_msgTypes = make([]protoimpl.MessageInfo, 80)
 var file_proto_agent_proto_goTypes = []any{
-	(*RegisterRequest)(nil),         // 0: agent.RegisterRequest
-	(*RegisterResponse)(nil),        // 1: agent.RegisterResponse
-	(*HeartbeatRequest)(nil),        // 2: agent.HeartbeatRequest
-	(*HeartbeatResponse)(nil),       // 3: agent.HeartbeatResponse
-	(*ConfigRequest)(nil),           // 4: agent.ConfigRequest
-	(*ConfigResponse)(nil),          // 5: agent.ConfigResponse
-	(*RulesRequest)(nil),            // 6: agent.RulesRequest
-	(*RulesResponse)(nil),           // 7: agent.RulesResponse
-	(*StatusRequest)(nil),           // 8: agent.StatusRequest
-	(*StatusResponse)(nil),          // 9: agent.StatusResponse
-	(*Rule)(nil),                    // 10: agent.Rule
-	(*BlacklistRequest)(nil),        // 11: agent.BlacklistRequest
-	(*BlacklistResponse)(nil),       // 12: agent.BlacklistResponse
-	(*WhitelistRequest)(nil),        // 13: agent.WhitelistRequest
-	(*WhitelistResponse)(nil),       // 14: agent.WhitelistResponse
-	(*FilterConfigRequest)(nil),     // 15: agent.FilterConfigRequest
-	(*FilterConfigResponse)(nil),    // 16: agent.FilterConfigResponse
-	(*ProtocolFilter)(nil),          // 17: agent.ProtocolFilter
-	(*RollbackRequest)(nil),         // 18: agent.RollbackRequest
-	(*RollbackResponse)(nil),        // 19: agent.RollbackResponse
-	(*MultiplexConfigRequest)(nil),  // 20: agent.MultiplexConfigRequest
-	(*MultiplexConfigResponse)(nil), // 21: agent.MultiplexConfigResponse
-	(*MultiplexStatusRequest)(nil),  // 22: agent.MultiplexStatusRequest
-	(*MultiplexStatusResponse)(nil), // 23: agent.MultiplexStatusResponse
-	(*MultiplexConfig)(nil),         // 24: agent.MultiplexConfig
-	(*ProtocolMultiplex)(nil),       // 25: agent.ProtocolMultiplex
-	(*IPRangeInfo)(nil),             // 26: agent.IPRangeInfo
-	(*UninstallRequest)(nil),        // 27: agent.UninstallRequest
-	(*UninstallResponse)(nil),       // 28: agent.UninstallResponse
-	nil,                             // 29: agent.RegisterRequest.MetadataEntry
-	nil,                             // 30: agent.HeartbeatRequest.MetricsEntry
-	nil,                             // 31: agent.StatusResponse.SystemInfoEntry
-	nil,                             // 32: agent.Rule.MetadataEntry
-	nil,                             // 33: agent.MultiplexConfig.BrutalEntry
+	(*RegisterRequest)(nil),           // 0: agent.RegisterRequest
+	(*RegisterResponse)(nil),          // 1: agent.RegisterResponse
+	(*HeartbeatRequest)(nil),          // 2: agent.HeartbeatRequest
+	(*HeartbeatResponse)(nil),         // 3: agent.HeartbeatResponse
+	(*ConfigRequest)(nil),             // 4: agent.ConfigRequest
+	(*ConfigResponse)(nil),            // 5: agent.ConfigResponse
+	(*PortConflict)(nil),              // 6: agent.PortConflict
+	(*ApplyPhase)(nil),                // 7: agent.ApplyPhase
+	(*RulesRequest)(nil),              // 8: agent.RulesRequest
+	(*RulesResponse)(nil),             // 9: agent.RulesResponse
+	(*StatusRequest)(nil),             // 10: agent.StatusRequest
+	(*StatusResponse)(nil),            // 11: agent.StatusResponse
+	(*Rule)(nil),                      // 12: agent.Rule
+	(*BlacklistRequest)(nil),          // 13: agent.BlacklistRequest
+	(*BlacklistResponse)(nil),         // 14: agent.BlacklistResponse
+	(*WhitelistRequest)(nil),          // 15: agent.WhitelistRequest
+	(*WhitelistResponse)(nil),         // 16: agent.WhitelistResponse
+	(*FilterConfigRequest)(nil),       // 17: agent.FilterConfigRequest
+	(*FilterConfigResponse)(nil),      // 18: agent.FilterConfigResponse
+	(*ProtocolFilter)(nil),            // 19: agent.ProtocolFilter
+	(*RollbackRequest)(nil),           // 20: agent.RollbackRequest
+	(*RollbackResponse)(nil),          // 21: agent.RollbackResponse
+	(*MultiplexConfigRequest)(nil),    // 22: agent.MultiplexConfigRequest
+	(*MultiplexConfigResponse)(nil),   // 23: agent.MultiplexConfigResponse
+	(*MultiplexStatusRequest)(nil),    // 24: agent.MultiplexStatusRequest
+	(*MultiplexStatusResponse)(nil),   // 25: agent.MultiplexStatusResponse
+	(*MultiplexConfig)(nil),           // 26: agent.MultiplexConfig
+	(*ProtocolMultiplex)(nil),         // 27: agent.ProtocolMultiplex
+	(*IPRangeInfo)(nil),               // 28: agent.IPRangeInfo
+	(*UninstallRequest)(nil),          // 29: agent.UninstallRequest
+	(*UninstallResponse)(nil),         // 30: agent.UninstallResponse
+	(*ConfigGenerationsRequest)(nil),  // 31: agent.ConfigGenerationsRequest
+	(*ConfigGeneration)(nil),          // 32: agent.ConfigGeneration
+	(*ConfigGenerationsResponse)(nil), // 33: agent.ConfigGenerationsResponse
+	(*ConfigDiffRequest)(nil),         // 34: agent.ConfigDiffRequest
+	(*ConfigDiffResponse)(nil),        // 35: agent.ConfigDiffResponse
+	(*LogStreamRequest)(nil),          // 36: agent.LogStreamRequest
+	(*SingboxLogEntry)(nil),           // 37: agent.SingboxLogEntry
+	(*InboundUser)(nil),               // 38: agent.InboundUser
+	(*InboundUsersRequest)(nil),       // 39: agent.InboundUsersRequest
+	(*InboundUsersQuery)(nil),         // 40: agent.InboundUsersQuery
+	(*InboundUsersResponse)(nil),      // 41: agent.InboundUsersResponse
+	(*InstanceStatus)(nil),            // 42: agent.InstanceStatus
+	(*ResourceUsage)(nil),             // 43: agent.ResourceUsage
+	(*ConnectionStats)(nil),           // 44: agent.ConnectionStats
+	(*TagTraffic)(nil),                // 45: agent.TagTraffic
+	(*OutboundProbeResult)(nil),       // 46: agent.OutboundProbeResult
+	(*CloseConnectionsRequest)(nil),   // 47: agent.CloseConnectionsRequest
+	(*CloseConnectionsResponse)(nil),  // 48: agent.CloseConnectionsResponse
+	(*TrafficUsageReport)(nil),        // 49: agent.TrafficUsageReport
+	(*TrafficUsage)(nil),              // 50: agent.TrafficUsage
+	(*TrafficUsageResponse)(nil),      // 51: agent.TrafficUsageResponse
+	(*UpgradeRequest)(nil),            // 52: agent.UpgradeRequest
+	(*UpgradeResponse)(nil),           // 53: agent.UpgradeResponse
+	(*DNSConfigQuery)(nil),            // 54: agent.DNSConfigQuery
+	(*DNSServersRequest)(nil),         // 55: agent.DNSServersRequest
+	(*DNSRulesRequest)(nil),           // 56: agent.DNSRulesRequest
+	(*FakeIPRequest)(nil),             // 57: agent.FakeIPRequest
+	(*DNSConfigResponse)(nil),         // 58: agent.DNSConfigResponse
+	(*OutboundGroup)(nil),             // 59: agent.OutboundGroup
+	(*OutboundGroupRequest)(nil),      // 60: agent.OutboundGroupRequest
+	(*GroupMembersRequest)(nil),       // 61: agent.GroupMembersRequest
+	(*OutboundGroupResponse)(nil),     // 62: agent.OutboundGroupResponse
+	(*OutboundGroupsQuery)(nil),       // 63: agent.OutboundGroupsQuery
+	(*MemberDelay)(nil),               // 64: agent.MemberDelay
+	(*OutboundGroupStatus)(nil),       // 65: agent.OutboundGroupStatus
+	(*OutboundGroupsResponse)(nil),    // 66: agent.OutboundGroupsResponse
+	(*CertificateRequest)(nil),        // 67: agent.CertificateRequest
+	(*CertificateResponse)(nil),       // 68: agent.CertificateResponse
+	(*RealityRequest)(nil),            // 69: agent.RealityRequest
+	(*RealityQuery)(nil),              // 70: agent.RealityQuery
+	(*RealityResponse)(nil),           // 71: agent.RealityResponse
+	(*InboundsQuery)(nil),             // 72: agent.InboundsQuery
+	(*InboundDefinition)(nil),         // 73: agent.InboundDefinition
+	(*InboundsResponse)(nil),          // 74: agent.InboundsResponse
+	nil,                               // 75: agent.RegisterRequest.MetadataEntry
+	nil,                               // 76: agent.HeartbeatRequest.MetricsEntry
+	nil,                               // 77: agent.StatusResponse.SystemInfoEntry
+	nil,                               // 78: agent.Rule.MetadataEntry
+	nil,                               // 79: agent.MultiplexConfig.BrutalEntry
 }
 var file_proto_agent_proto_depIdxs = []int32{
-	29, // 0: agent.RegisterRequest.metadata:type_name -> agent.RegisterRequest.MetadataEntry
-	26, // 1: agent.RegisterRequest.ip_range_info:type_name -> agent.IPRangeInfo
-	30, // 2: agent.HeartbeatRequest.metrics:type_name -> agent.HeartbeatRequest.MetricsEntry
-	26, // 3: agent.HeartbeatRequest.ip_range_info:type_name -> agent.IPRangeInfo
-	10, // 4: agent.RulesRequest.rules:type_name -> agent.Rule
-	31, // 5: agent.StatusResponse.system_info:type_name -> agent.StatusResponse.SystemInfoEntry
-	32, // 6: agent.Rule.metadata:type_name -> agent.Rule.MetadataEntry
-	17, // 7: agent.FilterConfigResponse.filters:type_name -> agent.ProtocolFilter
-	24, // 8: agent.MultiplexConfigRequest.multiplex_config:type_name -> agent.MultiplexConfig
-	25, // 9: agent.MultiplexStatusResponse.multiplex_configs:type_name -> agent.ProtocolMultiplex
-	33, // 10: agent.MultiplexConfig.brutal:type_name -> agent.MultiplexConfig.BrutalEntry
-	24, // 11: agent.ProtocolMultiplex.multiplex_config:type_name -> agent.MultiplexConfig
-	0,  // 12: agent.AgentService.RegisterAgent:input_type -> agent.RegisterRequest
-	2,  // 13: agent.AgentService.Heartbeat:input_type -> agent.HeartbeatRequest
-	4,  // 14: agent.AgentService.UpdateConfig:input_type -> agent.ConfigRequest
-	6,  // 15: agent.AgentService.UpdateRules:input_type -> agent.RulesRequest
-	8,  // 16: agent.AgentService.GetStatus:input_type -> agent.StatusRequest
-	11, // 17: agent.AgentService.UpdateBlacklist:input_type -> agent.BlacklistRequest
-	13, // 18: agent.AgentService.UpdateWhitelist:input_type -> agent.WhitelistRequest
-	15, // 19: agent.AgentService.GetFilterConfig:input_type -> agent.FilterConfigRequest
-	18, // 20: agent.AgentService.RollbackConfig:input_type -> agent.RollbackRequest
-	20, // 21: agent.AgentService.UpdateMultiplexConfig:input_type -> agent.MultiplexConfigRequest
-	22, // 22: agent.AgentService.GetMultiplexConfig:input_type -> agent.MultiplexStatusRequest
-	27, // 23: agent.AgentService.UninstallAgent:input_type -> agent.UninstallRequest
-	1,  // 24: agent.AgentService.RegisterAgent:output_type -> agent.RegisterResponse
-	3,  // 25: agent.AgentService.Heartbeat:output_type -> agent.HeartbeatResponse
-	5,  // 26: agent.AgentService.UpdateConfig:output_type -> agent.ConfigResponse
-	7,  // 27: agent.AgentService.UpdateRules:output_type -> agent.RulesResponse
-	9,  // 28: agent.AgentService.GetStatus:output_type -> agent.StatusResponse
-	12, // 29: agent.AgentService.UpdateBlacklist:output_type -> agent.BlacklistResponse
-	14, // 30: agent.AgentService.UpdateWhitelist:output_type -> agent.WhitelistResponse
-	16, // 31: agent.AgentService.GetFilterConfig:output_type -> agent.FilterConfigResponse
-	19, // 32: agent.AgentService.RollbackConfig:output_type -> agent.RollbackResponse
-	21, // 33: agent.AgentService.UpdateMultiplexConfig:output_type -> agent.MultiplexConfigResponse
-	23, // 34: agent.AgentService.GetMultiplexConfig:output_type -> agent.MultiplexStatusResponse
-	28, // 35: agent.AgentService.UninstallAgent:output_type -> agent.UninstallResponse
-	24, // [24:36] is the sub-list for method output_type
-	12, // [12:24] is the sub-list for method input_type
-	12, // [12:12] is the sub-list for extension type_name
-	12, // [12:12] is the sub-list for extension extendee
-	0,  // [0:12] is the sub-list for field type_name
+	75, // 0: agent.RegisterRequest.metadata:type_name -> agent.RegisterRequest.MetadataEntry
+	28, // 1: agent.RegisterRequest.ip_range_info:type_name -> agent.IPRangeInfo
+	76, // 2: agent.HeartbeatRequest.metrics:type_name -> agent.HeartbeatRequest.MetricsEntry
+	28, // 3: agent.HeartbeatRequest.ip_range_info:type_name -> agent.IPRangeInfo
+	44, // 4: agent.HeartbeatRequest.connection_stats:type_name -> agent.ConnectionStats
+	42, // 5: agent.HeartbeatRequest.instances:type_name -> agent.InstanceStatus
+	46, // 6: agent.HeartbeatRequest.outbound_probes:type_name -> agent.OutboundProbeResult
+	7,  // 7: agent.ConfigResponse.phases:type_name -> agent.ApplyPhase
+	6,  // 8: agent.ConfigResponse.port_conflicts:type_name -> agent.PortConflict
+	12, // 9: agent.RulesRequest.rules:type_name -> agent.Rule
+	77, // 10: agent.StatusResponse.system_info:type_name -> agent.StatusResponse.SystemInfoEntry
+	42, // 11: agent.StatusResponse.instances:type_name -> agent.InstanceStatus
+	78, // 12: agent.Rule.metadata:type_name -> agent.Rule.MetadataEntry
+	19, // 13: agent.FilterConfigResponse.filters:type_name -> agent.ProtocolFilter
+	7,  // 14: agent.RollbackResponse.phases:type_name -> agent.ApplyPhase
+	26, // 15: agent.MultiplexConfigRequest.multiplex_config:type_name -> agent.MultiplexConfig
+	27, // 16: agent.MultiplexStatusResponse.multiplex_configs:type_name -> agent.ProtocolMultiplex
+	79, // 17: agent.MultiplexConfig.brutal:type_name -> agent.MultiplexConfig.BrutalEntry
+	26, // 18: agent.ProtocolMultiplex.multiplex_config:type_name -> agent.MultiplexConfig
+	32, // 19: agent.ConfigGenerationsResponse.generations:type_name -> agent.ConfigGeneration
+	38, // 20: agent.InboundUsersRequest.users:type_name -> agent.InboundUser
+	38, // 21: agent.InboundUsersResponse.users:type_name -> agent.InboundUser
+	7,  // 22: agent.InboundUsersResponse.phases:type_name -> agent.ApplyPhase
+	44, // 23: agent.InstanceStatus.connection_stats:type_name -> agent.ConnectionStats
+	43, // 24: agent.InstanceStatus.resource_usage:type_name -> agent.ResourceUsage
+	45, // 25: agent.ConnectionStats.inbounds:type_name -> agent.TagTraffic
+	45, // 26: agent.ConnectionStats.outbounds:type_name -> agent.TagTraffic
+	50, // 27: agent.TrafficUsageReport.usages:type_name -> agent.TrafficUsage
+	7,  // 28: agent.UpgradeResponse.phases:type_name -> agent.ApplyPhase
+	7,  // 29: agent.DNSConfigResponse.phases:type_name -> agent.ApplyPhase
+	59, // 30: agent.OutboundGroupRequest.group:type_name -> agent.OutboundGroup
+	59, // 31: agent.OutboundGroupResponse.group:type_name -> agent.OutboundGroup
+	7,  // 32: agent.OutboundGroupResponse.phases:type_name -> agent.ApplyPhase
+	59, // 33: agent.OutboundGroupStatus.group:type_name -> agent.OutboundGroup
+	64, // 34: agent.OutboundGroupStatus.members:type_name -> agent.MemberDelay
+	65, // 35: agent.OutboundGroupsResponse.groups:type_name -> agent.OutboundGroupStatus
+	7,  // 36: agent.CertificateResponse.phases:type_name -> agent.ApplyPhase
+	7,  // 37: agent.RealityResponse.phases:type_name -> agent.ApplyPhase
+	73, // 38: agent.InboundsResponse.inbounds:type_name -> agent.InboundDefinition
+	0,  // 39: agent.AgentService.RegisterAgent:input_type -> agent.RegisterRequest
+	2,  // 40: agent.AgentService.Heartbeat:input_type -> agent.HeartbeatRequest
+	4,  // 41: agent.AgentService.UpdateConfig:input_type -> agent.ConfigRequest
+	8,  // 42: agent.AgentService.UpdateRules:input_type -> agent.RulesRequest
+	10, // 43: agent.AgentService.GetStatus:input_type -> agent.StatusRequest
+	13, // 44: agent.AgentService.UpdateBlacklist:input_type -> agent.BlacklistRequest
+	15, // 45: agent.AgentService.UpdateWhitelist:input_type -> agent.WhitelistRequest
+	17, // 46: agent.AgentService.GetFilterConfig:input_type -> agent.FilterConfigRequest
+	20, // 47: agent.AgentService.RollbackConfig:input_type -> agent.RollbackRequest
+	22, // 48: agent.AgentService.UpdateMultiplexConfig:input_type -> agent.MultiplexConfigRequest
+	24, // 49: agent.AgentService.GetMultiplexConfig:input_type -> agent.MultiplexStatusRequest
+	29, // 50: agent.AgentService.UninstallAgent:input_type -> agent.UninstallRequest
+	31, // 51: agent.AgentService.ListConfigGenerations:input_type -> agent.ConfigGenerationsRequest
+	34, // 52: agent.AgentService.DiffConfigGenerations:input_type -> agent.ConfigDiffRequest
+	36, // 53: agent.AgentService.StreamSingboxLogs:input_type -> agent.LogStreamRequest
+	39, // 54: agent.AgentService.UpdateInboundUsers:input_type -> agent.InboundUsersRequest
+	40, // 55: agent.AgentService.GetInboundUsers:input_type -> agent.InboundUsersQuery
+	47, // 56: agent.AgentService.CloseConnections:input_type -> agent.CloseConnectionsRequest
+	49, // 57: agent.AgentService.ReportTrafficUsage:input_type -> agent.TrafficUsageReport
+	52, // 58: agent.AgentService.UpgradeSingbox:input_type -> agent.UpgradeRequest
+	54, // 59: agent.AgentService.GetDNSConfig:input_type -> agent.DNSConfigQuery
+	55, // 60: agent.AgentService.UpdateDNSServers:input_type -> agent.DNSServersRequest
+	56, // 61: agent.AgentService.UpdateDNSRules:input_type -> agent.DNSRulesRequest
+	57, // 62: agent.AgentService.UpdateFakeIP:input_type -> agent.FakeIPRequest
+	63, // 63: agent.AgentService.GetOutboundGroups:input_type -> agent.OutboundGroupsQuery
+	60, // 64: agent.AgentService.UpdateOutboundGroup:input_type -> agent.OutboundGroupRequest
+	61, // 65: agent.AgentService.UpdateGroupMembers:input_type -> agent.GroupMembersRequest
+	67, // 66: agent.AgentService.UpdateCertificate:input_type -> agent.CertificateRequest
+	69, // 67: agent.AgentService.UpdateReality:input_type -> agent.RealityRequest
+	70, // 68: agent.AgentService.GetReality:input_type -> agent.RealityQuery
+	72, // 69: agent.AgentService.GetInbounds:input_type -> agent.InboundsQuery
+	1,  // 70: agent.AgentService.RegisterAgent:output_type -> agent.RegisterResponse
+	3,  // 71: agent.AgentService.Heartbeat:output_type -> agent.HeartbeatResponse
+	5,  // 72: agent.AgentService.UpdateConfig:output_type -> agent.ConfigResponse
+	9,  // 73: agent.AgentService.UpdateRules:output_type -> agent.RulesResponse
+	11, // 74: agent.AgentService.GetStatus:output_type -> agent.StatusResponse
+	14, // 75: agent.AgentService.UpdateBlacklist:output_type -> agent.BlacklistResponse
+	16, // 76: agent.AgentService.UpdateWhitelist:output_type -> agent.WhitelistResponse
+	18, // 77: agent.AgentService.GetFilterConfig:output_type -> agent.FilterConfigResponse
+	21, // 78: agent.AgentService.RollbackConfig:output_type -> agent.RollbackResponse
+	23, // 79: agent.AgentService.UpdateMultiplexConfig:output_type -> agent.MultiplexConfigResponse
+	25, // 80: agent.AgentService.GetMultiplexConfig:output_type -> agent.MultiplexStatusResponse
+	30, // 81: agent.AgentService.UninstallAgent:output_type -> agent.UninstallResponse
+	33, // 82: agent.AgentService.ListConfigGenerations:output_type -> agent.ConfigGenerationsResponse
+	35, // 83: agent.AgentService.DiffConfigGenerations:output_type -> agent.ConfigDiffResponse
+	37, // 84: agent.AgentService.StreamSingboxLogs:output_type -> agent.SingboxLogEntry
+	41, // 85: agent.AgentService.UpdateInboundUsers:output_type -> agent.InboundUsersResponse
+	41, // 86: agent.AgentService.GetInboundUsers:output_type -> agent.InboundUsersResponse
+	48, // 87: agent.AgentService.CloseConnections:output_type -> agent.CloseConnectionsResponse
+	51, // 88: agent.AgentService.ReportTrafficUsage:output_type -> agent.TrafficUsageResponse
+	53, // 89: agent.AgentService.UpgradeSingbox:output_type -> agent.UpgradeResponse
+	58, // 90: agent.AgentService.GetDNSConfig:output_type -> agent.DNSConfigResponse
+	58, // 91: agent.AgentService.UpdateDNSServers:output_type -> agent.DNSConfigResponse
+	58, // 92: agent.AgentService.UpdateDNSRules:output_type -> agent.DNSConfigResponse
+	58, // 93: agent.AgentService.UpdateFakeIP:output_type -> agent.DNSConfigResponse
+	66, // 94: agent.AgentService.GetOutboundGroups:output_type -> agent.OutboundGroupsResponse
+	62, // 95: agent.AgentService.UpdateOutboundGroup:output_type -> agent.OutboundGroupResponse
+	62, // 96: agent.AgentService.UpdateGroupMembers:output_type -> agent.OutboundGroupResponse
+	68, // 97: agent.AgentService.UpdateCertificate:output_type -> agent.CertificateResponse
+	71, // 98: agent.AgentService.UpdateReality:output_type -> agent.RealityResponse
+	71, // 99: agent.AgentService.GetReality:output_type -> agent.RealityResponse
+	74, // 100: agent.AgentService.GetInbounds:output_type -> agent.InboundsResponse
+	70, // [70:101] is the sub-list for method output_type
+	39, // [39:70] is the sub-list for method input_type
+	39, // [39:39] is the sub-list for extension type_name
+	39, // [39:39] is the sub-list for extension extendee
+	0,  // [0:39] is the sub-list for field type_name
 }
 
 func init() { file_proto_agent_proto_init() }
@@ -2337,7 +6669,7 @@ func file_proto_agent_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_agent_proto_rawDesc), len(file_proto_agent_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   34,
+			NumMessages:   80,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -31,6 +31,25 @@ const (
 	AgentService_UpdateMultiplexConfig_FullMethodName = "/agent.AgentService/UpdateMultiplexConfig"
 	AgentService_GetMultiplexConfig_FullMethodName    = "/agent.AgentService/GetMultiplexConfig"
 	AgentService_UninstallAgent_FullMethodName        = "/agent.AgentService/UninstallAgent"
+	AgentService_ListConfigGenerations_FullMethodName = "/agent.AgentService/ListConfigGenerations"
+	AgentService_DiffConfigGenerations_FullMethodName = "/agent.AgentService/DiffConfigGenerations"
+	AgentService_StreamSingboxLogs_FullMethodName     = "/agent.AgentService/StreamSingboxLogs"
+	AgentService_UpdateInboundUsers_FullMethodName    = "/agent.AgentService/UpdateInboundUsers"
+	AgentService_GetInboundUsers_FullMethodName       = "/agent.AgentService/GetInboundUsers"
+	AgentService_CloseConnections_FullMethodName      = "/agent.AgentService/CloseConnections"
+	AgentService_ReportTrafficUsage_FullMethodName    = "/agent.AgentService/ReportTrafficUsage"
+	AgentService_UpgradeSingbox_FullMethodName        = "/agent.AgentService/UpgradeSingbox"
+	AgentService_GetDNSConfig_FullMethodName          = "/agent.AgentService/GetDNSConfig"
+	AgentService_UpdateDNSServers_FullMethodName      = "/agent.AgentService/UpdateDNSServers"
+	AgentService_UpdateDNSRules_FullMethodName        = "/agent.AgentService/UpdateDNSRules"
+	AgentService_UpdateFakeIP_FullMethodName          = "/agent.AgentService/UpdateFakeIP"
+	AgentService_GetOutboundGroups_FullMethodName     = "/agent.AgentService/GetOutboundGroups"
+	AgentService_UpdateOutboundGroup_FullMethodName   = "/agent.AgentService/UpdateOutboundGroup"
+	AgentService_UpdateGroupMembers_FullMethodName    = "/agent.AgentService/UpdateGroupMembers"
+	AgentService_UpdateCertificate_FullMethodName     = "/agent.AgentService/UpdateCertificate"
+	AgentService_UpdateReality_FullMethodName         = "/agent.AgentService/UpdateReality"
+	AgentService_GetReality_FullMethodName            = "/agent.AgentService/GetReality"
+	AgentService_GetInbounds_FullMethodName           = "/agent.AgentService/GetInbounds"
 )
 
 // AgentServiceClient is the client API for AgentService service.
@@ -63,6 +82,44 @@ type AgentServiceClient interface {
 	GetMultiplexConfig(ctx context.Context, in *MultiplexStatusRequest, opts ...grpc.CallOption) (*MultiplexStatusResponse, error)
 	// 卸载Agent
 	UninstallAgent(ctx context.Context, in *UninstallRequest, opts ...grpc.CallOption) (*UninstallResponse, error)
+	// 列出sing-box配置历史代
+	ListConfigGenerations(ctx context.Context, in *ConfigGenerationsRequest, opts ...grpc.CallOption) (*ConfigGenerationsResponse, error)
+	// 比较两代sing-box配置
+	DiffConfigGenerations(ctx context.Context, in *ConfigDiffRequest, opts ...grpc.CallOption) (*ConfigDiffResponse, error)
+	// 实时查看sing-box日志
+	StreamSingboxLogs(ctx context.Context, in *LogStreamRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[SingboxLogEntry], error)
+	// 增删或替换入站用户
+	UpdateInboundUsers(ctx context.Context, in *InboundUsersRequest, opts ...grpc.CallOption) (*InboundUsersResponse, error)
+	// 获取入站用户列表
+	GetInboundUsers(ctx context.Context, in *InboundUsersQuery, opts ...grpc.CallOption) (*InboundUsersResponse, error)
+	// 按条件关闭sing-box连接
+	CloseConnections(ctx context.Context, in *CloseConnectionsRequest, opts ...grpc.CallOption) (*CloseConnectionsResponse, error)
+	// 上报用户/入站/出站流量增量（Agent -> Controller）
+	ReportTrafficUsage(ctx context.Context, in *TrafficUsageReport, opts ...grpc.CallOption) (*TrafficUsageResponse, error)
+	// 切换sing-box版本（升级或降级），失败时自动恢复原二进制
+	UpgradeSingbox(ctx context.Context, in *UpgradeRequest, opts ...grpc.CallOption) (*UpgradeResponse, error)
+	// 获取DNS配置
+	GetDNSConfig(ctx context.Context, in *DNSConfigQuery, opts ...grpc.CallOption) (*DNSConfigResponse, error)
+	// 增删改或重排DNS服务器，设置默认服务器与解析策略
+	UpdateDNSServers(ctx context.Context, in *DNSServersRequest, opts ...grpc.CallOption) (*DNSConfigResponse, error)
+	// 增删、移动或替换DNS规则
+	UpdateDNSRules(ctx context.Context, in *DNSRulesRequest, opts ...grpc.CallOption) (*DNSConfigResponse, error)
+	// 启用或停用FakeIP并设置地址池
+	UpdateFakeIP(ctx context.Context, in *FakeIPRequest, opts ...grpc.CallOption) (*DNSConfigResponse, error)
+	// 获取selector/urltest出站组及当前选择与成员延迟
+	GetOutboundGroups(ctx context.Context, in *OutboundGroupsQuery, opts ...grpc.CallOption) (*OutboundGroupsResponse, error)
+	// 创建、修改或删除出站组
+	UpdateOutboundGroup(ctx context.Context, in *OutboundGroupRequest, opts ...grpc.CallOption) (*OutboundGroupResponse, error)
+	// 增删或替换出站组成员
+	UpdateGroupMembers(ctx context.Context, in *GroupMembersRequest, opts ...grpc.CallOption) (*OutboundGroupResponse, error)
+	// 写入证书文件并设置到入站的TLS配置后热重载
+	UpdateCertificate(ctx context.Context, in *CertificateRequest, opts ...grpc.CallOption) (*CertificateResponse, error)
+	// 设置入站的Reality私钥和short_id后热重载，配置的其余部分保持不变
+	UpdateReality(ctx context.Context, in *RealityRequest, opts ...grpc.CallOption) (*RealityResponse, error)
+	// 获取入站当前的Reality公钥和short_id，不返回私钥
+	GetReality(ctx context.Context, in *RealityQuery, opts ...grpc.CallOption) (*RealityResponse, error)
+	// 获取全部实例的入站定义（含用户），Reality私钥替换为公钥后返回
+	GetInbounds(ctx context.Context, in *InboundsQuery, opts ...grpc.CallOption) (*InboundsResponse, error)
 }
 
 type agentServiceClient struct {
@@ -193,6 +250,205 @@ func (c *agentServiceClient) UninstallAgent(ctx context.Context, in *UninstallRe
 	return out, nil
 }
 
+func (c *agentServiceClient) ListConfigGenerations(ctx context.Context, in *ConfigGenerationsRequest, opts ...grpc.CallOption) (*ConfigGenerationsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ConfigGenerationsResponse)
+	err := c.cc.Invoke(ctx, AgentService_ListConfigGenerations_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *agentServiceClient) DiffConfigGenerations(ctx context.Context, in *ConfigDiffRequest, opts ...grpc.CallOption) (*ConfigDiffResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ConfigDiffResponse)
+	err := c.cc.Invoke(ctx, AgentService_DiffConfigGenerations_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *agentServiceClient) StreamSingboxLogs(ctx context.Context, in *LogStreamRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[SingboxLogEntry], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &AgentService_ServiceDesc.Streams[0], AgentService_StreamSingboxLogs_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[LogStreamRequest, SingboxLogEntry]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type AgentService_StreamSingboxLogsClient = grpc.ServerStreamingClient[SingboxLogEntry]
+
+func (c *agentServiceClient) UpdateInboundUsers(ctx context.Context, in *InboundUsersRequest, opts ...grpc.CallOption) (*InboundUsersResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(InboundUsersResponse)
+	err := c.cc.Invoke(ctx, AgentService_UpdateInboundUsers_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *agentServiceClient) GetInboundUsers(ctx context.Context, in *InboundUsersQuery, opts ...grpc.CallOption) (*InboundUsersResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(InboundUsersResponse)
+	err := c.cc.Invoke(ctx, AgentService_GetInboundUsers_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *agentServiceClient) CloseConnections(ctx context.Context, in *CloseConnectionsRequest, opts ...grpc.CallOption) (*CloseConnectionsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CloseConnectionsResponse)
+	err := c.cc.Invoke(ctx, AgentService_CloseConnections_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *agentServiceClient) ReportTrafficUsage(ctx context.Context, in *TrafficUsageReport, opts ...grpc.CallOption) (*TrafficUsageResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TrafficUsageResponse)
+	err := c.cc.Invoke(ctx, AgentService_ReportTrafficUsage_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *agentServiceClient) UpgradeSingbox(ctx context.Context, in *UpgradeRequest, opts ...grpc.CallOption) (*UpgradeResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UpgradeResponse)
+	err := c.cc.Invoke(ctx, AgentService_UpgradeSingbox_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *agentServiceClient) GetDNSConfig(ctx context.Context, in *DNSConfigQuery, opts ...grpc.CallOption) (*DNSConfigResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DNSConfigResponse)
+	err := c.cc.Invoke(ctx, AgentService_GetDNSConfig_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *agentServiceClient) UpdateDNSServers(ctx context.Context, in *DNSServersRequest, opts ...grpc.CallOption) (*DNSConfigResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DNSConfigResponse)
+	err := c.cc.Invoke(ctx, AgentService_UpdateDNSServers_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *agentServiceClient) UpdateDNSRules(ctx context.Context, in *DNSRulesRequest, opts ...grpc.CallOption) (*DNSConfigResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DNSConfigResponse)
+	err := c.cc.Invoke(ctx, AgentService_UpdateDNSRules_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *agentServiceClient) UpdateFakeIP(ctx context.Context, in *FakeIPRequest, opts ...grpc.CallOption) (*DNSConfigResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DNSConfigResponse)
+	err := c.cc.Invoke(ctx, AgentService_UpdateFakeIP_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *agentServiceClient) GetOutboundGroups(ctx context.Context, in *OutboundGroupsQuery, opts ...grpc.CallOption) (*OutboundGroupsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(OutboundGroupsResponse)
+	err := c.cc.Invoke(ctx, AgentService_GetOutboundGroups_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *agentServiceClient) UpdateOutboundGroup(ctx context.Context, in *OutboundGroupRequest, opts ...grpc.CallOption) (*OutboundGroupResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(OutboundGroupResponse)
+	err := c.cc.Invoke(ctx, AgentService_UpdateOutboundGroup_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *agentServiceClient) UpdateGroupMembers(ctx context.Context, in *GroupMembersRequest, opts ...grpc.CallOption) (*OutboundGroupResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(OutboundGroupResponse)
+	err := c.cc.Invoke(ctx, AgentService_UpdateGroupMembers_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *agentServiceClient) UpdateCertificate(ctx context.Context, in *CertificateRequest, opts ...grpc.CallOption) (*CertificateResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CertificateResponse)
+	err := c.cc.Invoke(ctx, AgentService_UpdateCertificate_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *agentServiceClient) UpdateReality(ctx context.Context, in *RealityRequest, opts ...grpc.CallOption) (*RealityResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RealityResponse)
+	err := c.cc.Invoke(ctx, AgentService_UpdateReality_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *agentServiceClient) GetReality(ctx context.Context, in *RealityQuery, opts ...grpc.CallOption) (*RealityResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RealityResponse)
+	err := c.cc.Invoke(ctx, AgentService_GetReality_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *agentServiceClient) GetInbounds(ctx context.Context, in *InboundsQuery, opts ...grpc.CallOption) (*InboundsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(InboundsResponse)
+	err := c.cc.Invoke(ctx, AgentService_GetInbounds_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // AgentServiceServer is the server API for AgentService service.
 // All implementations must embed UnimplementedAgentServiceServer
 // for forward compatibility.
@@ -223,6 +479,44 @@ type AgentServiceServer interface {
 	GetMultiplexConfig(context.Context, *MultiplexStatusRequest) (*MultiplexStatusResponse, error)
 	// 卸载Agent
 	UninstallAgent(context.Context, *UninstallRequest) (*UninstallResponse, error)
+	// 列出sing-box配置历史代
+	ListConfigGenerations(context.Context, *ConfigGenerationsRequest) (*ConfigGenerationsResponse, error)
+	// 比较两代sing-box配置
+	DiffConfigGenerations(context.Context, *ConfigDiffRequest) (*ConfigDiffResponse, error)
+	// 实时查看sing-box日志
+	StreamSingboxLogs(*LogStreamRequest, grpc.ServerStreamingServer[SingboxLogEntry]) error
+	// 增删或替换入站用户
+	UpdateInboundUsers(context.Context, *InboundUsersRequest) (*InboundUsersResponse, error)
+	// 获取入站用户列表
+	GetInboundUsers(context.Context, *InboundUsersQuery) (*InboundUsersResponse, error)
+	// 按条件关闭sing-box连接
+	CloseConnections(context.Context, *CloseConnectionsRequest) (*CloseConnectionsResponse, error)
+	// 上报用户/入站/出站流量增量（Agent -> Controller）
+	ReportTrafficUsage(context.Context, *TrafficUsageReport) (*TrafficUsageResponse, error)
+	// 切换sing-box版本（升级或降级），失败时自动恢复原二进制
+	UpgradeSingbox(context.Context, *UpgradeRequest) (*UpgradeResponse, error)
+	// 获取DNS配置
+	GetDNSConfig(context.Context, *DNSConfigQuery) (*DNSConfigResponse, error)
+	// 增删改或重排DNS服务器，设置默认服务器与解析策略
+	UpdateDNSServers(context.Context, *DNSServersRequest) (*DNSConfigResponse, error)
+	// 增删、移动或替换DNS规则
+	UpdateDNSRules(context.Context, *DNSRulesRequest) (*DNSConfigResponse, error)
+	// 启用或停用FakeIP并设置地址池
+	UpdateFakeIP(context.Context, *FakeIPRequest) (*DNSConfigResponse, error)
+	// 获取selector/urltest出站组及当前选择与成员延迟
+	GetOutboundGroups(context.Context, *OutboundGroupsQuery) (*OutboundGroupsResponse, error)
+	// 创建、修改或删除出站组
+	UpdateOutboundGroup(context.Context, *OutboundGroupRequest) (*OutboundGroupResponse, error)
+	// 增删或替换出站组成员
+	UpdateGroupMembers(context.Context, *GroupMembersRequest) (*OutboundGroupResponse, error)
+	// 写入证书文件并设置到入站的TLS配置后热重载
+	UpdateCertificate(context.Context, *CertificateRequest) (*CertificateResponse, error)
+	// 设置入站的Reality私钥和short_id后热重载，配置的其余部分保持不变
+	UpdateReality(context.Context, *RealityRequest) (*RealityResponse, error)
+	// 获取入站当前的Reality公钥和short_id，不返回私钥
+	GetReality(context.Context, *RealityQuery) (*RealityResponse, error)
+	// 获取全部实例的入站定义（含用户），Reality私钥替换为公钥后返回
+	GetInbounds(context.Context, *InboundsQuery) (*InboundsResponse, error)
 	mustEmbedUnimplementedAgentServiceServer()
 }
 
@@ -269,6 +563,63 @@ func (UnimplementedAgentServiceServer) GetMultiplexConfig(context.Context, *Mult
 func (UnimplementedAgentServiceServer) UninstallAgent(context.Context, *UninstallRequest) (*UninstallResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method UninstallAgent not implemented")
 }
+func (UnimplementedAgentServiceServer) ListConfigGenerations(context.Context, *ConfigGenerationsRequest) (*ConfigGenerationsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListConfigGenerations not implemented")
+}
+func (UnimplementedAgentServiceServer) DiffConfigGenerations(context.Context, *ConfigDiffRequest) (*ConfigDiffResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DiffConfigGenerations not implemented")
+}
+func (UnimplementedAgentServiceServer) StreamSingboxLogs(*LogStreamRequest, grpc.ServerStreamingServer[SingboxLogEntry]) error {
+	return status.Errorf(codes.Unimplemented, "method StreamSingboxLogs not implemented")
+}
+func (UnimplementedAgentServiceServer) UpdateInboundUsers(context.Context, *InboundUsersRequest) (*InboundUsersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateInboundUsers not implemented")
+}
+func (UnimplementedAgentServiceServer) GetInboundUsers(context.Context, *InboundUsersQuery) (*InboundUsersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetInboundUsers not implemented")
+}
+func (UnimplementedAgentServiceServer) CloseConnections(context.Context, *CloseConnectionsRequest) (*CloseConnectionsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CloseConnections not implemented")
+}
+func (UnimplementedAgentServiceServer) ReportTrafficUsage(context.Context, *TrafficUsageReport) (*TrafficUsageResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReportTrafficUsage not implemented")
+}
+func (UnimplementedAgentServiceServer) UpgradeSingbox(context.Context, *UpgradeRequest) (*UpgradeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpgradeSingbox not implemented")
+}
+func (UnimplementedAgentServiceServer) GetDNSConfig(context.Context, *DNSConfigQuery) (*DNSConfigResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetDNSConfig not implemented")
+}
+func (UnimplementedAgentServiceServer) UpdateDNSServers(context.Context, *DNSServersRequest) (*DNSConfigResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateDNSServers not implemented")
+}
+func (UnimplementedAgentServiceServer) UpdateDNSRules(context.Context, *DNSRulesRequest) (*DNSConfigResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateDNSRules not implemented")
+}
+func (UnimplementedAgentServiceServer) UpdateFakeIP(context.Context, *FakeIPRequest) (*DNSConfigResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateFakeIP not implemented")
+}
+func (UnimplementedAgentServiceServer) GetOutboundGroups(context.Context, *OutboundGroupsQuery) (*OutboundGroupsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetOutboundGroups not implemented")
+}
+func (UnimplementedAgentServiceServer) UpdateOutboundGroup(context.Context, *OutboundGroupRequest) (*OutboundGroupResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateOutboundGroup not implemented")
+}
+func (UnimplementedAgentServiceServer) UpdateGroupMembers(context.Context, *GroupMembersRequest) (*OutboundGroupResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateGroupMembers not implemented")
+}
+func (UnimplementedAgentServiceServer) UpdateCertificate(context.Context, *CertificateRequest) (*CertificateResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateCertificate not implemented")
+}
+func (UnimplementedAgentServiceServer) UpdateReality(context.Context, *RealityRequest) (*RealityResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateReality not implemented")
+}
+func (UnimplementedAgentServiceServer) GetReality(context.Context, *RealityQuery) (*RealityResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetReality not implemented")
+}
+func (UnimplementedAgentServiceServer) GetInbounds(context.Context, *InboundsQuery) (*InboundsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetInbounds not implemented")
+}
 func (UnimplementedAgentServiceServer) mustEmbedUnimplementedAgentServiceServer() {}
 func (UnimplementedAgentServiceServer) testEmbeddedByValue()                      {}
 
@@ -506,6 +857,341 @@ func _AgentService_UninstallAgent_Handler(srv interface{}, ctx context.Context,
 	return interceptor(ctx, in, info, handler)
 }
 
+func _AgentService_ListConfigGenerations_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ConfigGenerationsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentServiceServer).ListConfigGenerations(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AgentService_ListConfigGenerations_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentServiceServer).ListConfigGenerations(ctx, req.(*ConfigGenerationsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AgentService_DiffConfigGenerations_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ConfigDiffRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentServiceServer).DiffConfigGenerations(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AgentService_DiffConfigGenerations_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentServiceServer).DiffConfigGenerations(ctx, req.(*ConfigDiffRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AgentService_StreamSingboxLogs_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(LogStreamRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(AgentServiceServer).StreamSingboxLogs(m, &grpc.GenericServerStream[LogStreamRequest, SingboxLogEntry]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type AgentService_StreamSingboxLogsServer = grpc.ServerStreamingServer[SingboxLogEntry]
+
+func _AgentService_UpdateInboundUsers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(InboundUsersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentServiceServer).UpdateInboundUsers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AgentService_UpdateInboundUsers_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentServiceServer).UpdateInboundUsers(ctx, req.(*InboundUsersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AgentService_GetInboundUsers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(InboundUsersQuery)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentServiceServer).GetInboundUsers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AgentService_GetInboundUsers_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentServiceServer).GetInboundUsers(ctx, req.(*InboundUsersQuery))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AgentService_CloseConnections_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CloseConnectionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentServiceServer).CloseConnections(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AgentService_CloseConnections_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentServiceServer).CloseConnections(ctx, req.(*CloseConnectionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AgentService_ReportTrafficUsage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TrafficUsageReport)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentServiceServer).ReportTrafficUsage(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AgentService_ReportTrafficUsage_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentServiceServer).ReportTrafficUsage(ctx, req.(*TrafficUsageReport))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AgentService_UpgradeSingbox_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpgradeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentServiceServer).UpgradeSingbox(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AgentService_UpgradeSingbox_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentServiceServer).UpgradeSingbox(ctx, req.(*UpgradeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AgentService_GetDNSConfig_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DNSConfigQuery)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentServiceServer).GetDNSConfig(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AgentService_GetDNSConfig_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentServiceServer).GetDNSConfig(ctx, req.(*DNSConfigQuery))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AgentService_UpdateDNSServers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DNSServersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentServiceServer).UpdateDNSServers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AgentService_UpdateDNSServers_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentServiceServer).UpdateDNSServers(ctx, req.(*DNSServersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AgentService_UpdateDNSRules_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DNSRulesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentServiceServer).UpdateDNSRules(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AgentService_UpdateDNSRules_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentServiceServer).UpdateDNSRules(ctx, req.(*DNSRulesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AgentService_UpdateFakeIP_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FakeIPRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentServiceServer).UpdateFakeIP(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AgentService_UpdateFakeIP_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentServiceServer).UpdateFakeIP(ctx, req.(*FakeIPRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AgentService_GetOutboundGroups_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(OutboundGroupsQuery)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentServiceServer).GetOutboundGroups(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AgentService_GetOutboundGroups_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentServiceServer).GetOutboundGroups(ctx, req.(*OutboundGroupsQuery))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AgentService_UpdateOutboundGroup_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(OutboundGroupRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentServiceServer).UpdateOutboundGroup(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AgentService_UpdateOutboundGroup_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentServiceServer).UpdateOutboundGroup(ctx, req.(*OutboundGroupRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AgentService_UpdateGroupMembers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GroupMembersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentServiceServer).UpdateGroupMembers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AgentService_UpdateGroupMembers_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentServiceServer).UpdateGroupMembers(ctx, req.(*GroupMembersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AgentService_UpdateCertificate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CertificateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentServiceServer).UpdateCertificate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AgentService_UpdateCertificate_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentServiceServer).UpdateCertificate(ctx, req.(*CertificateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AgentService_UpdateReality_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RealityRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentServiceServer).UpdateReality(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AgentService_UpdateReality_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentServiceServer).UpdateReality(ctx, req.(*RealityRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AgentService_GetReality_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RealityQuery)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentServiceServer).GetReality(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AgentService_GetReality_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentServiceServer).GetReality(ctx, req.(*RealityQuery))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AgentService_GetInbounds_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(InboundsQuery)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentServiceServer).GetInbounds(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AgentService_GetInbounds_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentServiceServer).GetInbounds(ctx, req.(*InboundsQuery))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // AgentService_ServiceDesc is the grpc.ServiceDesc for AgentService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -561,7 +1247,85 @@ var AgentService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "UninstallAgent",
 			Handler:    _AgentService_UninstallAgent_Handler,
 		},
+		{
+			MethodName: "ListConfigGenerations",
+			Handler:    _AgentService_ListConfigGenerations_Handler,
+		},
+		{
+			MethodName: "DiffConfigGenerations",
+			Handler:    _AgentService_DiffConfigGenerations_Handler,
+		},
+		{
+			MethodName: "UpdateInboundUsers",
+			Handler:    _AgentService_UpdateInboundUsers_Handler,
+		},
+		{
+			MethodName: "GetInboundUsers",
+			Handler:    _AgentService_GetInboundUsers_Handler,
+		},
+		{
+			MethodName: "CloseConnections",
+			Handler:    _AgentService_CloseConnections_Handler,
+		},
+		{
+			MethodName: "ReportTrafficUsage",
+			Handler:    _AgentService_ReportTrafficUsage_Handler,
+		},
+		{
+			MethodName: "UpgradeSingbox",
+			Handler:    _AgentService_UpgradeSingbox_Handler,
+		},
+		{
+			MethodName: "GetDNSConfig",
+			Handler:    _AgentService_GetDNSConfig_Handler,
+		},
+		{
+			MethodName: "UpdateDNSServers",
+			Handler:    _AgentService_UpdateDNSServers_Handler,
+		},
+		{
+			MethodName: "UpdateDNSRules",
+			Handler:    _AgentService_UpdateDNSRules_Handler,
+		},
+		{
+			MethodName: "UpdateFakeIP",
+			Handler:    _AgentService_UpdateFakeIP_Handler,
+		},
+		{
+			MethodName: "GetOutboundGroups",
+			Handler:    _AgentService_GetOutboundGroups_Handler,
+		},
+		{
+			MethodName: "UpdateOutboundGroup",
+			Handler:    _AgentService_UpdateOutboundGroup_Handler,
+		},
+		{
+			MethodName: "UpdateGroupMembers",
+			Handler:    _AgentService_UpdateGroupMembers_Handler,
+		},
+		{
+			MethodName: "UpdateCertificate",
+			Handler:    _AgentService_UpdateCertificate_Handler,
+		},
+		{
+			MethodName: "UpdateReality",
+			Handler:    _AgentService_UpdateReality_Handler,
+		},
+		{
+			MethodName: "GetReality",
+			Handler:    _AgentService_GetReality_Handler,
+		},
+		{
+			MethodName: "GetInbounds",
+			Handler:    _AgentService_GetInbounds_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamSingboxLogs",
+			Handler:       _AgentService_StreamSingboxLogs_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "proto/agent.proto",
 }
@@ -1348,6 +1348,7 @@ type AgentMonitoringData struct {
 	LastUpdate         *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=last_update,json=lastUpdate,proto3" json:"last_update,omitempty"`
 	ActiveConnections  int32                  `protobuf:"varint,8,opt,name=active_connections,json=activeConnections,proto3" json:"active_connections,omitempty"`
 	NetworkLatencyMs   float64                `protobuf:"fixed64,9,opt,name=network_latency_ms,json=networkLatencyMs,proto3" json:"network_latency_ms,omitempty"`
+	OutboundProbes     []*OutboundProbeSeries `protobuf:"bytes,10,rep,name=outbound_probes,json=outboundProbes,proto3" json:"outbound_probes,omitempty"` // 各出站的探测延迟与成功率
 	unknownFields      protoimpl.UnknownFields
 	sizeCache          protoimpl.SizeCache
 }
@@ -1445,6 +1446,130 @@ func (x *AgentMonitoringData) GetNetworkLatencyMs() float64 {
 	return 0
 }
 
+func (x *AgentMonitoringData) GetOutboundProbes() []*OutboundProbeSeries {
+	if x != nil {
+		return x.OutboundProbes
+	}
+	return nil
+}
+
+// 一个出站对一个探测目标的探测时间序列，每个数据点为一次心跳上报的汇总
+type OutboundProbeSeries struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Instance      string                 `protobuf:"bytes,1,opt,name=instance,proto3" json:"instance,omitempty"`                       // sing-box实例名称
+	Outbound      string                 `protobuf:"bytes,2,opt,name=outbound,proto3" json:"outbound,omitempty"`                       // 出站tag
+	TargetType    string                 `protobuf:"bytes,3,opt,name=target_type,json=targetType,proto3" json:"target_type,omitempty"` // http, tcp, dns
+	Target        string                 `protobuf:"bytes,4,opt,name=target,proto3" json:"target,omitempty"`
+	LatencyMs     []*MonitoringDataPoint `protobuf:"bytes,5,rep,name=latency_ms,json=latencyMs,proto3" json:"latency_ms,omitempty"`       // 成功探测的平均延迟，全部失败的时段没有数据点
+	SuccessRate   []*MonitoringDataPoint `protobuf:"bytes,6,rep,name=success_rate,json=successRate,proto3" json:"success_rate,omitempty"` // 成功率（0-1）
+	Probes        int32                  `protobuf:"varint,7,opt,name=probes,proto3" json:"probes,omitempty"`                             // 时间范围内的探测次数
+	Successes     int32                  `protobuf:"varint,8,opt,name=successes,proto3" json:"successes,omitempty"`
+	AvgLatencyMs  float64                `protobuf:"fixed64,9,opt,name=avg_latency_ms,json=avgLatencyMs,proto3" json:"avg_latency_ms,omitempty"`
+	LastError     string                 `protobuf:"bytes,10,opt,name=last_error,json=lastError,proto3" json:"last_error,omitempty"` // 时间范围内最近一次失败的原因
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OutboundProbeSeries) Reset() {
+	*x = OutboundProbeSeries{}
+	mi := &file_proto_backend_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OutboundProbeSeries) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OutboundProbeSeries) ProtoMessage() {}
+
+func (x *OutboundProbeSeries) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_backend_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OutboundProbeSeries.ProtoReflect.Descriptor instead.
+func (*OutboundProbeSeries) Descriptor() ([]byte, []int) {
+	return file_proto_backend_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *OutboundProbeSeries) GetInstance() string {
+	if x != nil {
+		return x.Instance
+	}
+	return ""
+}
+
+func (x *OutboundProbeSeries) GetOutbound() string {
+	if x != nil {
+		return x.Outbound
+	}
+	return ""
+}
+
+func (x *OutboundProbeSeries) GetTargetType() string {
+	if x != nil {
+		return x.TargetType
+	}
+	return ""
+}
+
+func (x *OutboundProbeSeries) GetTarget() string {
+	if x != nil {
+		return x.Target
+	}
+	return ""
+}
+
+func (x *OutboundProbeSeries) GetLatencyMs() []*MonitoringDataPoint {
+	if x != nil {
+		return x.LatencyMs
+	}
+	return nil
+}
+
+func (x *OutboundProbeSeries) GetSuccessRate() []*MonitoringDataPoint {
+	if x != nil {
+		return x.SuccessRate
+	}
+	return nil
+}
+
+func (x *OutboundProbeSeries) GetProbes() int32 {
+	if x != nil {
+		return x.Probes
+	}
+	return 0
+}
+
+func (x *OutboundProbeSeries) GetSuccesses() int32 {
+	if x != nil {
+		return x.Successes
+	}
+	return 0
+}
+
+func (x *OutboundProbeSeries) GetAvgLatencyMs() float64 {
+	if x != nil {
+		return x.AvgLatencyMs
+	}
+	return 0
+}
+
+func (x *OutboundProbeSeries) GetLastError() string {
+	if x != nil {
+		return x.LastError
+	}
+	return ""
+}
+
 // 获取Agent监控响应
 type GetAgentMonitoringResponse struct {
 	state          protoimpl.MessageState `protogen:"open.v1"`
@@ -1457,7 +1582,7 @@ type GetAgentMonitoringResponse struct {
 
 func (x *GetAgentMonitoringResponse) Reset() {
 	*x = GetAgentMonitoringResponse{}
-	mi := &file_proto_backend_proto_msgTypes[19]
+	mi := &file_proto_backend_proto_msgTypes[20]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1469,7 +1594,7 @@ func (x *GetAgentMonitoringResponse) String() string {
 func (*GetAgentMonitoringResponse) ProtoMessage() {}
 
 func (x *GetAgentMonitoringResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_backend_proto_msgTypes[19]
+	mi := &file_proto_backend_proto_msgTypes[20]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1482,7 +1607,7 @@ func (x *GetAgentMonitoringResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetAgentMonitoringResponse.ProtoReflect.Descriptor instead.
 func (*GetAgentMonitoringResponse) Descriptor() ([]byte, []int) {
-	return file_proto_backend_proto_rawDescGZIP(), []int{19}
+	return file_proto_backend_proto_rawDescGZIP(), []int{20}
 }
 
 func (x *GetAgentMonitoringResponse) GetSuccess() bool {
@@ -1519,7 +1644,7 @@ type GetIPRangesRequest struct {
 
 func (x *GetIPRangesRequest) Reset() {
 	*x = GetIPRangesRequest{}
-	mi := &file_proto_backend_proto_msgTypes[20]
+	mi := &file_proto_backend_proto_msgTypes[21]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1531,7 +1656,7 @@ func (x *GetIPRangesRequest) String() string {
 func (*GetIPRangesRequest) ProtoMessage() {}
 
 func (x *GetIPRangesRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_backend_proto_msgTypes[20]
+	mi := &file_proto_backend_proto_msgTypes[21]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1544,7 +1669,7 @@ func (x *GetIPRangesRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetIPRangesRequest.ProtoReflect.Descriptor instead.
 func (*GetIPRangesRequest) Descriptor() ([]byte, []int) {
-	return file_proto_backend_proto_rawDescGZIP(), []int{20}
+	return file_proto_backend_proto_rawDescGZIP(), []int{21}
 }
 
 func (x *GetIPRangesRequest) GetCountryFilter() string {
@@ -1593,7 +1718,7 @@ type IPRangeInfo struct {
 
 func (x *IPRangeInfo) Reset() {
 	*x = IPRangeInfo{}
-	mi := &file_proto_backend_proto_msgTypes[21]
+	mi := &file_proto_backend_proto_msgTypes[22]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1605,7 +1730,7 @@ func (x *IPRangeInfo) String() string {
 func (*IPRangeInfo) ProtoMessage() {}
 
 func (x *IPRangeInfo) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_backend_proto_msgTypes[21]
+	mi := &file_proto_backend_proto_msgTypes[22]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1618,7 +1743,7 @@ func (x *IPRangeInfo) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use IPRangeInfo.ProtoReflect.Descriptor instead.
 func (*IPRangeInfo) Descriptor() ([]byte, []int) {
-	return file_proto_backend_proto_rawDescGZIP(), []int{21}
+	return file_proto_backend_proto_rawDescGZIP(), []int{22}
 }
 
 func (x *IPRangeInfo) GetIpRange() string {
@@ -1697,7 +1822,7 @@ type GetIPRangesResponse struct {
 
 func (x *GetIPRangesResponse) Reset() {
 	*x = GetIPRangesResponse{}
-	mi := &file_proto_backend_proto_msgTypes[22]
+	mi := &file_proto_backend_proto_msgTypes[23]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1709,7 +1834,7 @@ func (x *GetIPRangesResponse) String() string {
 func (*GetIPRangesResponse) ProtoMessage() {}
 
 func (x *GetIPRangesResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_backend_proto_msgTypes[22]
+	mi := &file_proto_backend_proto_msgTypes[23]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1722,7 +1847,7 @@ func (x *GetIPRangesResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetIPRangesResponse.ProtoReflect.Descriptor instead.
 func (*GetIPRangesResponse) Descriptor() ([]byte, []int) {
-	return file_proto_backend_proto_rawDescGZIP(), []int{22}
+	return file_proto_backend_proto_rawDescGZIP(), []int{23}
 }
 
 func (x *GetIPRangesResponse) GetSuccess() bool {
@@ -1765,7 +1890,7 @@ type TestAgentRequest struct {
 
 func (x *TestAgentRequest) Reset() {
 	*x = TestAgentRequest{}
-	mi := &file_proto_backend_proto_msgTypes[23]
+	mi := &file_proto_backend_proto_msgTypes[24]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1777,7 +1902,7 @@ func (x *TestAgentRequest) String() string {
 func (*TestAgentRequest) ProtoMessage() {}
 
 func (x *TestAgentRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_backend_proto_msgTypes[23]
+	mi := &file_proto_backend_proto_msgTypes[24]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1790,7 +1915,7 @@ func (x *TestAgentRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use TestAgentRequest.ProtoReflect.Descriptor instead.
 func (*TestAgentRequest) Descriptor() ([]byte, []int) {
-	return file_proto_backend_proto_rawDescGZIP(), []int{23}
+	return file_proto_backend_proto_rawDescGZIP(), []int{24}
 }
 
 func (x *TestAgentRequest) GetAgentId() string {
@@ -1829,7 +1954,7 @@ type TestResult struct {
 
 func (x *TestResult) Reset() {
 	*x = TestResult{}
-	mi := &file_proto_backend_proto_msgTypes[24]
+	mi := &file_proto_backend_proto_msgTypes[25]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1841,7 +1966,7 @@ func (x *TestResult) String() string {
 func (*TestResult) ProtoMessage() {}
 
 func (x *TestResult) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_backend_proto_msgTypes[24]
+	mi := &file_proto_backend_proto_msgTypes[25]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1854,7 +1979,7 @@ func (x *TestResult) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use TestResult.ProtoReflect.Descriptor instead.
 func (*TestResult) Descriptor() ([]byte, []int) {
-	return file_proto_backend_proto_rawDescGZIP(), []int{24}
+	return file_proto_backend_proto_rawDescGZIP(), []int{25}
 }
 
 func (x *TestResult) GetTestType() string {
@@ -1912,7 +2037,7 @@ type TestAgentResponse struct {
 
 func (x *TestAgentResponse) Reset() {
 	*x = TestAgentResponse{}
-	mi := &file_proto_backend_proto_msgTypes[25]
+	mi := &file_proto_backend_proto_msgTypes[26]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1924,7 +2049,7 @@ func (x *TestAgentResponse) String() string {
 func (*TestAgentResponse) ProtoMessage() {}
 
 func (x *TestAgentResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_backend_proto_msgTypes[25]
+	mi := &file_proto_backend_proto_msgTypes[26]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1937,7 +2062,7 @@ func (x *TestAgentResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use TestAgentResponse.ProtoReflect.Descriptor instead.
 func (*TestAgentResponse) Descriptor() ([]byte, []int) {
-	return file_proto_backend_proto_rawDescGZIP(), []int{25}
+	return file_proto_backend_proto_rawDescGZIP(), []int{26}
 }
 
 func (x *TestAgentResponse) GetSuccess() bool {
@@ -1978,7 +2103,7 @@ type GetProtocolInfoRequest struct {
 
 func (x *GetProtocolInfoRequest) Reset() {
 	*x = GetProtocolInfoRequest{}
-	mi := &file_proto_backend_proto_msgTypes[26]
+	mi := &file_proto_backend_proto_msgTypes[27]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1990,7 +2115,7 @@ func (x *GetProtocolInfoRequest) String() string {
 func (*GetProtocolInfoRequest) ProtoMessage() {}
 
 func (x *GetProtocolInfoRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_backend_proto_msgTypes[26]
+	mi := &file_proto_backend_proto_msgTypes[27]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2003,7 +2128,7 @@ func (x *GetProtocolInfoRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetProtocolInfoRequest.ProtoReflect.Descriptor instead.
 func (*GetProtocolInfoRequest) Descriptor() ([]byte, []int) {
-	return file_proto_backend_proto_rawDescGZIP(), []int{26}
+	return file_proto_backend_proto_rawDescGZIP(), []int{27}
 }
 
 func (x *GetProtocolInfoRequest) GetAgentId() string {
@@ -2027,7 +2152,7 @@ type ProtocolSupportInfo struct {
 
 func (x *ProtocolSupportInfo) Reset() {
 	*x = ProtocolSupportInfo{}
-	mi := &file_proto_backend_proto_msgTypes[27]
+	mi := &file_proto_backend_proto_msgTypes[28]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2039,7 +2164,7 @@ func (x *ProtocolSupportInfo) String() string {
 func (*ProtocolSupportInfo) ProtoMessage() {}
 
 func (x *ProtocolSupportInfo) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_backend_proto_msgTypes[27]
+	mi := &file_proto_backend_proto_msgTypes[28]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2052,7 +2177,7 @@ func (x *ProtocolSupportInfo) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ProtocolSupportInfo.ProtoReflect.Descriptor instead.
 func (*ProtocolSupportInfo) Descriptor() ([]byte, []int) {
-	return file_proto_backend_proto_rawDescGZIP(), []int{27}
+	return file_proto_backend_proto_rawDescGZIP(), []int{28}
 }
 
 func (x *ProtocolSupportInfo) GetProtocolName() string {
@@ -2103,7 +2228,7 @@ type GetProtocolInfoResponse struct {
 
 func (x *GetProtocolInfoResponse) Reset() {
 	*x = GetProtocolInfoResponse{}
-	mi := &file_proto_backend_proto_msgTypes[28]
+	mi := &file_proto_backend_proto_msgTypes[29]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -2115,7 +2240,7 @@ func (x *GetProtocolInfoResponse) String() string {
 func (*GetProtocolInfoResponse) ProtoMessage() {}
 
 func (x *GetProtocolInfoResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_backend_proto_msgTypes[28]
+	mi := &file_proto_backend_proto_msgTypes[29]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -2128,7 +2253,7 @@ func (x *GetProtocolInfoResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetProtocolInfoResponse.ProtoReflect.Descriptor instead.
 func (*GetProtocolInfoResponse) Descriptor() ([]byte, []int) {
-	return file_proto_backend_proto_rawDescGZIP(), []int{28}
+	return file_proto_backend_proto_rawDescGZIP(), []int{29}
 }
 
 func (x *GetProtocolInfoResponse) GetSuccess() bool {
@@ -2281,7 +2406,7 @@ const file_proto_backend_proto_rawDesc = "" +
 	"\x10duration_minutes\x18\x02 \x01(\x05R\x0fdurationMinutes\"e\n" +
 	"\x13MonitoringDataPoint\x128\n" +
 	"\ttimestamp\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\ttimestamp\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\x01R\x05value\"\xf9\x03\n" +
+	"\x05value\x18\x02 \x01(\x01R\x05value\"\xc0\x04\n" +
 	"\x13AgentMonitoringData\x12\x19\n" +
 	"\bagent_id\x18\x01 \x01(\tR\aagentId\x129\n" +
 	"\tcpu_usage\x18\x02 \x03(\v2\x1c.backend.MonitoringDataPointR\bcpuUsage\x12?\n" +
@@ -2293,7 +2418,24 @@ const file_proto_backend_proto_rawDesc = "" +
 	"\vlast_update\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\n" +
 	"lastUpdate\x12-\n" +
 	"\x12active_connections\x18\b \x01(\x05R\x11activeConnections\x12,\n" +
-	"\x12network_latency_ms\x18\t \x01(\x01R\x10networkLatencyMs\"\x97\x01\n" +
+	"\x12network_latency_ms\x18\t \x01(\x01R\x10networkLatencyMs\x12E\n" +
+	"\x0foutbound_probes\x18\n" +
+	" \x03(\v2\x1c.backend.OutboundProbeSeriesR\x0eoutboundProbes\"\xff\x02\n" +
+	"\x13OutboundProbeSeries\x12\x1a\n" +
+	"\binstance\x18\x01 \x01(\tR\binstance\x12\x1a\n" +
+	"\boutbound\x18\x02 \x01(\tR\boutbound\x12\x1f\n" +
+	"\vtarget_type\x18\x03 \x01(\tR\n" +
+	"targetType\x12\x16\n" +
+	"\x06target\x18\x04 \x01(\tR\x06target\x12;\n" +
+	"\n" +
+	"latency_ms\x18\x05 \x03(\v2\x1c.backend.MonitoringDataPointR\tlatencyMs\x12?\n" +
+	"\fsuccess_rate\x18\x06 \x03(\v2\x1c.backend.MonitoringDataPointR\vsuccessRate\x12\x16\n" +
+	"\x06probes\x18\a \x01(\x05R\x06probes\x12\x1c\n" +
+	"\tsuccesses\x18\b \x01(\x05R\tsuccesses\x12$\n" +
+	"\x0eavg_latency_ms\x18\t \x01(\x01R\favgLatencyMs\x12\x1d\n" +
+	"\n" +
+	"last_error\x18\n" +
+	" \x01(\tR\tlastError\"\x97\x01\n" +
 	"\x1aGetAgentMonitoringResponse\x12\x18\n" +
 	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
 	"\amessage\x18\x02 \x01(\tR\amessage\x12E\n" +
@@ -2383,7 +2525,7 @@ func file_proto_backend_proto_rawDescGZIP() []byte {
 	return file_proto_backend_proto_rawDescData
 }
 
-var file_proto_backend_proto_msgTypes = make([]protoimpl.MessageInfo, 35)
+var file_proto_backend_proto_msgTypes = make([]protoimpl.MessageInfo, 36)
 var file_proto_backend_proto_goTypes = []any{
 	(*HealthCheckRequest)(nil),         // 0: backend.HealthCheckRequest
 	(*HealthCheckResponse)(nil),        // 1: backend.HealthCheckResponse
@@ -2404,79 +2546,83 @@ var file_proto_backend_proto_goTypes = []any{
 	(*GetAgentMonitoringRequest)(nil),  // 16: backend.GetAgentMonitoringRequest
 	(*MonitoringDataPoint)(nil),        // 17: backend.MonitoringDataPoint
 	(*AgentMonitoringData)(nil),        // 18: backend.AgentMonitoringData
-	(*GetAgentMonitoringResponse)(nil), // 19: backend.GetAgentMonitoringResponse
-	(*GetIPRangesRequest)(nil),         // 20: backend.GetIPRangesRequest
-	(*IPRangeInfo)(nil),                // 21: backend.IPRangeInfo
-	(*GetIPRangesResponse)(nil),        // 22: backend.GetIPRangesResponse
-	(*TestAgentRequest)(nil),           // 23: backend.TestAgentRequest
-	(*TestResult)(nil),                 // 24: backend.TestResult
-	(*TestAgentResponse)(nil),          // 25: backend.TestAgentResponse
-	(*GetProtocolInfoRequest)(nil),     // 26: backend.GetProtocolInfoRequest
-	(*ProtocolSupportInfo)(nil),        // 27: backend.ProtocolSupportInfo
-	(*GetProtocolInfoResponse)(nil),    // 28: backend.GetProtocolInfoResponse
-	nil,                                // 29: backend.AgentInfo.MetadataEntry
-	nil,                                // 30: backend.AgentStats.AgentsByCountryEntry
-	nil,                                // 31: backend.AgentStats.AgentsByRegionEntry
-	nil,                                // 32: backend.DeployAgentRequest.DeploymentOptionsEntry
-	nil,                                // 33: backend.TestResult.DetailsEntry
-	nil,                                // 34: backend.ProtocolSupportInfo.CapabilitiesEntry
-	(*timestamppb.Timestamp)(nil),      // 35: google.protobuf.Timestamp
+	(*OutboundProbeSeries)(nil),        // 19: backend.OutboundProbeSeries
+	(*GetAgentMonitoringResponse)(nil), // 20: backend.GetAgentMonitoringResponse
+	(*GetIPRangesRequest)(nil),         // 21: backend.GetIPRangesRequest
+	(*IPRangeInfo)(nil),                // 22: backend.IPRangeInfo
+	(*GetIPRangesResponse)(nil),        // 23: backend.GetIPRangesResponse
+	(*TestAgentRequest)(nil),           // 24: backend.TestAgentRequest
+	(*TestResult)(nil),                 // 25: backend.TestResult
+	(*TestAgentResponse)(nil),          // 26: backend.TestAgentResponse
+	(*GetProtocolInfoRequest)(nil),     // 27: backend.GetProtocolInfoRequest
+	(*ProtocolSupportInfo)(nil),        // 28: backend.ProtocolSupportInfo
+	(*GetProtocolInfoResponse)(nil),    // 29: backend.GetProtocolInfoResponse
+	nil,                                // 30: backend.AgentInfo.MetadataEntry
+	nil,                                // 31: backend.AgentStats.AgentsByCountryEntry
+	nil,                                // 32: backend.AgentStats.AgentsByRegionEntry
+	nil,                                // 33: backend.DeployAgentRequest.DeploymentOptionsEntry
+	nil,                                // 34: backend.TestResult.DetailsEntry
+	nil,                                // 35: backend.ProtocolSupportInfo.CapabilitiesEntry
+	(*timestamppb.Timestamp)(nil),      // 36: google.protobuf.Timestamp
 }
 var file_proto_backend_proto_depIdxs = []int32{
-	35, // 0: backend.HealthCheckResponse.timestamp:type_name -> google.protobuf.Timestamp
-	35, // 1: backend.AgentInfo.last_heartbeat:type_name -> google.protobuf.Timestamp
-	35, // 2: backend.AgentInfo.registered_at:type_name -> google.protobuf.Timestamp
-	29, // 3: backend.AgentInfo.metadata:type_name -> backend.AgentInfo.MetadataEntry
-	21, // 4: backend.AgentInfo.ip_range_info:type_name -> backend.IPRangeInfo
+	36, // 0: backend.HealthCheckResponse.timestamp:type_name -> google.protobuf.Timestamp
+	36, // 1: backend.AgentInfo.last_heartbeat:type_name -> google.protobuf.Timestamp
+	36, // 2: backend.AgentInfo.registered_at:type_name -> google.protobuf.Timestamp
+	30, // 3: backend.AgentInfo.metadata:type_name -> backend.AgentInfo.MetadataEntry
+	22, // 4: backend.AgentInfo.ip_range_info:type_name -> backend.IPRangeInfo
 	3,  // 5: backend.GetAgentsResponse.agents:type_name -> backend.AgentInfo
 	3,  // 6: backend.GetAgentResponse.agent:type_name -> backend.AgentInfo
-	30, // 7: backend.AgentStats.agents_by_country:type_name -> backend.AgentStats.AgentsByCountryEntry
-	31, // 8: backend.AgentStats.agents_by_region:type_name -> backend.AgentStats.AgentsByRegionEntry
+	31, // 7: backend.AgentStats.agents_by_country:type_name -> backend.AgentStats.AgentsByCountryEntry
+	32, // 8: backend.AgentStats.agents_by_region:type_name -> backend.AgentStats.AgentsByRegionEntry
 	8,  // 9: backend.GetAgentStatsResponse.stats:type_name -> backend.AgentStats
-	32, // 10: backend.DeployAgentRequest.deployment_options:type_name -> backend.DeployAgentRequest.DeploymentOptionsEntry
-	35, // 11: backend.UpdateAgentConfigResponse.updated_at:type_name -> google.protobuf.Timestamp
-	35, // 12: backend.MonitoringDataPoint.timestamp:type_name -> google.protobuf.Timestamp
+	33, // 10: backend.DeployAgentRequest.deployment_options:type_name -> backend.DeployAgentRequest.DeploymentOptionsEntry
+	36, // 11: backend.UpdateAgentConfigResponse.updated_at:type_name -> google.protobuf.Timestamp
+	36, // 12: backend.MonitoringDataPoint.timestamp:type_name -> google.protobuf.Timestamp
 	17, // 13: backend.AgentMonitoringData.cpu_usage:type_name -> backend.MonitoringDataPoint
 	17, // 14: backend.AgentMonitoringData.memory_usage:type_name -> backend.MonitoringDataPoint
 	17, // 15: backend.AgentMonitoringData.disk_usage:type_name -> backend.MonitoringDataPoint
 	17, // 16: backend.AgentMonitoringData.network_connections:type_name -> backend.MonitoringDataPoint
-	35, // 17: backend.AgentMonitoringData.last_update:type_name -> google.protobuf.Timestamp
-	18, // 18: backend.GetAgentMonitoringResponse.monitoring_data:type_name -> backend.AgentMonitoringData
-	3,  // 19: backend.IPRangeInfo.agents:type_name -> backend.AgentInfo
-	35, // 20: backend.IPRangeInfo.detected_at:type_name -> google.protobuf.Timestamp
-	21, // 21: backend.GetIPRangesResponse.ip_ranges:type_name -> backend.IPRangeInfo
-	35, // 22: backend.TestResult.tested_at:type_name -> google.protobuf.Timestamp
-	33, // 23: backend.TestResult.details:type_name -> backend.TestResult.DetailsEntry
-	24, // 24: backend.TestAgentResponse.test_results:type_name -> backend.TestResult
-	34, // 25: backend.ProtocolSupportInfo.capabilities:type_name -> backend.ProtocolSupportInfo.CapabilitiesEntry
-	27, // 26: backend.GetProtocolInfoResponse.protocols:type_name -> backend.ProtocolSupportInfo
-	0,  // 27: backend.BackendService.HealthCheck:input_type -> backend.HealthCheckRequest
-	2,  // 28: backend.BackendService.GetAgents:input_type -> backend.GetAgentsRequest
-	5,  // 29: backend.BackendService.GetAgent:input_type -> backend.GetAgentRequest
-	7,  // 30: backend.BackendService.GetAgentStats:input_type -> backend.GetAgentStatsRequest
-	10, // 31: backend.BackendService.DeployAgent:input_type -> backend.DeployAgentRequest
-	12, // 32: backend.BackendService.UninstallAgent:input_type -> backend.UninstallAgentRequest
-	14, // 33: backend.BackendService.UpdateAgentConfig:input_type -> backend.UpdateAgentConfigRequest
-	16, // 34: backend.BackendService.GetAgentMonitoring:input_type -> backend.GetAgentMonitoringRequest
-	20, // 35: backend.BackendService.GetIPRanges:input_type -> backend.GetIPRangesRequest
-	23, // 36: backend.BackendService.TestAgent:input_type -> backend.TestAgentRequest
-	26, // 37: backend.BackendService.GetProtocolInfo:input_type -> backend.GetProtocolInfoRequest
-	1,  // 38: backend.BackendService.HealthCheck:output_type -> backend.HealthCheckResponse
-	4,  // 39: backend.BackendService.GetAgents:output_type -> backend.GetAgentsResponse
-	6,  // 40: backend.BackendService.GetAgent:output_type -> backend.GetAgentResponse
-	9,  // 41: backend.BackendService.GetAgentStats:output_type -> backend.GetAgentStatsResponse
-	11, // 42: backend.BackendService.DeployAgent:output_type -> backend.DeployAgentResponse
-	13, // 43: backend.BackendService.UninstallAgent:output_type -> backend.UninstallAgentResponse
-	15, // 44: backend.BackendService.UpdateAgentConfig:output_type -> backend.UpdateAgentConfigResponse
-	19, // 45: backend.BackendService.GetAgentMonitoring:output_type -> backend.GetAgentMonitoringResponse
-	22, // 46: backend.BackendService.GetIPRanges:output_type -> backend.GetIPRangesResponse
-	25, // 47: backend.BackendService.TestAgent:output_type -> backend.TestAgentResponse
-	28, // 48: backend.BackendService.GetProtocolInfo:output_type -> backend.GetProtocolInfoResponse
-	38, // [38:49] is the sub-list for method output_type
-	27, // [27:38] is the sub-list for method input_type
-	27, // [27:27] is the sub-list for extension type_name
-	27, // [27:27] is the sub-list for extension extendee
-	0,  // [0:27] is the sub-list for field type_name
+	36, // 17: backend.AgentMonitoringData.last_update:type_name -> google.protobuf.Timestamp
+	19, // 18: backend.AgentMonitoringData.outbound_probes:type_name -> backend.OutboundProbeSeries
+	17, // 19: backend.OutboundProbeSeries.latency_ms:type_name -> backend.MonitoringDataPoint
+	17, // 20: backend.OutboundProbeSeries.success_rate:type_name -> backend.MonitoringDataPoint
+	18, // 21: backend.GetAgentMonitoringResponse.monitoring_data:type_name -> backend.AgentMonitoringData
+	3,  // 22: backend.IPRangeInfo.agents:type_name -> backend.AgentInfo
+	36, // 23: backend.IPRangeInfo.detected_at:type_name -> google.protobuf.Timestamp
+	22, // 24: backend.GetIPRangesResponse.ip_ranges:type_name -> backend.IPRangeInfo
+	36, // 25: backend.TestResult.tested_at:type_name -> google.protobuf.Timestamp
+	34, // 26: backend.TestResult.details:type_name -> backend.TestResult.DetailsEntry
+	25, // 27: backend.TestAgentResponse.test_results:type_name -> backend.TestResult
+	35, // 28: backend.ProtocolSupportInfo.capabilities:type_name -> backend.ProtocolSupportInfo.CapabilitiesEntry
+	28, // 29: backend.GetProtocolInfoResponse.protocols:type_name -> backend.ProtocolSupportInfo
+	0,  // 30: backend.BackendService.HealthCheck:input_type -> backend.HealthCheckRequest
+	2,  // 31: backend.BackendService.GetAgents:input_type -> backend.GetAgentsRequest
+	5,  // 32: backend.BackendService.GetAgent:input_type -> backend.GetAgentRequest
+	7,  // 33: backend.BackendService.GetAgentStats:input_type -> backend.GetAgentStatsRequest
+	10, // 34: backend.BackendService.DeployAgent:input_type -> backend.DeployAgentRequest
+	12, // 35: backend.BackendService.UninstallAgent:input_type -> backend.UninstallAgentRequest
+	14, // 36: backend.BackendService.UpdateAgentConfig:input_type -> backend.UpdateAgentConfigRequest
+	16, // 37: backend.BackendService.GetAgentMonitoring:input_type -> backend.GetAgentMonitoringRequest
+	21, // 38: backend.BackendService.GetIPRanges:input_type -> backend.GetIPRangesRequest
+	24, // 39: backend.BackendService.TestAgent:input_type -> backend.TestAgentRequest
+	27, // 40: backend.BackendService.GetProtocolInfo:input_type -> backend.GetProtocolInfoRequest
+	1,  // 41: backend.BackendService.HealthCheck:output_type -> backend.HealthCheckResponse
+	4,  // 42: backend.BackendService.GetAgents:output_type -> backend.GetAgentsResponse
+	6,  // 43: backend.BackendService.GetAgent:output_type -> backend.GetAgentResponse
+	9,  // 44: backend.BackendService.GetAgentStats:output_type -> backend.GetAgentStatsResponse
+	11, // 45: backend.BackendService.DeployAgent:output_type -> backend.DeployAgentResponse
+	13, // 46: backend.BackendService.UninstallAgent:output_type -> backend.UninstallAgentResponse
+	15, // 47: backend.BackendService.UpdateAgentConfig:output_type -> backend.UpdateAgentConfigResponse
+	20, // 48: backend.BackendService.GetAgentMonitoring:output_type -> backend.GetAgentMonitoringResponse
+	23, // 49: backend.BackendService.GetIPRanges:output_type -> backend.GetIPRangesResponse
+	26, // 50: backend.BackendService.TestAgent:output_type -> backend.TestAgentResponse
+	29, // 51: backend.BackendService.GetProtocolInfo:output_type -> backend.GetProtocolInfoResponse
+	41, // [41:52] is the sub-list for method output_type
+	30, // [30:41] is the sub-list for method input_type
+	30, // [30:30] is the sub-list for extension type_name
+	30, // [30:30] is the sub-list for extension extendee
+	0,  // [0:30] is the sub-list for field type_name
 }
 
 func init() { file_proto_backend_proto_init() }
@@ -2490,7 +2636,7 @@ func file_proto_backend_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_backend_proto_rawDesc), len(file_proto_backend_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   35,
+			NumMessages:   36,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
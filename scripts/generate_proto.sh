@@ -21,7 +21,7 @@ if ! command -v protoc-gen-go-grpc &> /dev/null; then
 fi
 
 # 创建输出目录
-mkdir -p proto/agent proto/backend proto/envoy
+mkdir -p proto/agent proto/backend proto/envoy proto/v2raystats
 
 # 生成Go代码
 echo "生成gRPC Go代码..."
@@ -37,4 +37,8 @@ protoc --go_out=. --go_opt=paths=source_relative \
        --go-grpc_out=. --go-grpc_opt=paths=source_relative \
        proto/envoy.proto
 
+protoc --go_out=. --go_opt=paths=source_relative \
+       --go-grpc_out=. --go-grpc_opt=paths=source_relative \
+       proto/v2raystats.proto
+
 echo "gRPC代码生成完成！"
\ No newline at end of file
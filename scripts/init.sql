@@ -24,14 +24,18 @@ CREATE TABLE IF NOT EXISTS agents (
 CREATE TABLE IF NOT EXISTS configs (
     id INT AUTO_INCREMENT PRIMARY KEY COMMENT '配置ID',
     agent_id VARCHAR(64) NOT NULL COMMENT '代理节点ID',
+    instance VARCHAR(64) NOT NULL DEFAULT 'default' COMMENT 'sing-box实例名称',
     config_content TEXT NOT NULL COMMENT '配置内容',
     config_version VARCHAR(32) NOT NULL COMMENT '配置版本',
     status ENUM('pending', 'applied', 'failed') DEFAULT 'pending' COMMENT '应用状态',
     apply_time TIMESTAMP NULL COMMENT '应用时间',
     error_message TEXT COMMENT '错误信息',
+    template_id INT NULL COMMENT '渲染使用的配置模板ID',
+    template_revision INT DEFAULT 0 COMMENT '渲染使用的模板修订版本',
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
     FOREIGN KEY (agent_id) REFERENCES agents(id) ON DELETE CASCADE,
     INDEX idx_agent_version (agent_id, config_version),
+    INDEX idx_template_id (template_id),
     INDEX idx_status (status),
     INDEX idx_created_at (created_at)
 ) ENGINE=InnoDB COMMENT='配置表';
@@ -95,6 +99,119 @@ CREATE TABLE IF NOT EXISTS operation_logs (
     INDEX idx_result (result)
 ) ENGINE=InnoDB COMMENT='操作日志表';
 
+-- 流量用量明细表
+CREATE TABLE IF NOT EXISTS traffic_usage (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY COMMENT '记录ID',
+    agent_id VARCHAR(64) NOT NULL COMMENT '代理节点ID',
+    instance VARCHAR(64) NOT NULL DEFAULT 'default' COMMENT 'sing-box实例名称',
+    scope VARCHAR(16) NOT NULL COMMENT '统计对象类型: user, inbound, outbound',
+    name VARCHAR(128) NOT NULL COMMENT '用户名或tag',
+    uplink BIGINT NOT NULL DEFAULT 0 COMMENT '上行字节',
+    downlink BIGINT NOT NULL DEFAULT 0 COMMENT '下行字节',
+    period_start TIMESTAMP NULL COMMENT '统计周期开始',
+    period_end TIMESTAMP NULL COMMENT '统计周期结束',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+    INDEX idx_usage_agent_scope (agent_id, scope, name),
+    INDEX idx_period_end (period_end)
+) ENGINE=InnoDB COMMENT='流量用量明细表';
+
+-- 流量用量小时汇总表
+CREATE TABLE IF NOT EXISTS traffic_usage_hourly (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY COMMENT '记录ID',
+    agent_id VARCHAR(64) NOT NULL COMMENT '代理节点ID',
+    instance VARCHAR(64) NOT NULL DEFAULT 'default' COMMENT 'sing-box实例名称',
+    scope VARCHAR(16) NOT NULL COMMENT '统计对象类型: user, inbound, outbound',
+    name VARCHAR(128) NOT NULL COMMENT '用户名或tag',
+    hour TIMESTAMP NOT NULL COMMENT '整点时间',
+    uplink BIGINT NOT NULL DEFAULT 0 COMMENT '上行字节',
+    downlink BIGINT NOT NULL DEFAULT 0 COMMENT '下行字节',
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+    UNIQUE KEY uk_usage_hour (agent_id, instance, scope, name, hour),
+    INDEX idx_hour (hour)
+) ENGINE=InnoDB COMMENT='流量用量小时汇总表';
+
+-- sing-box版本批量切换任务表
+CREATE TABLE IF NOT EXISTS singbox_rollouts (
+    id INT AUTO_INCREMENT PRIMARY KEY COMMENT '任务ID',
+    version VARCHAR(32) NOT NULL COMMENT '目标版本',
+    sha256 VARCHAR(64) COMMENT '制品sha256',
+    instance VARCHAR(64) COMMENT 'sing-box实例名称',
+    max_parallel INT NOT NULL DEFAULT 1 COMMENT '同时切换的Agent数',
+    max_failures INT NOT NULL DEFAULT 0 COMMENT '允许的失败数',
+    status ENUM('running', 'completed', 'failed') DEFAULT 'running' COMMENT '任务状态',
+    total INT NOT NULL DEFAULT 0 COMMENT 'Agent总数',
+    succeeded INT NOT NULL DEFAULT 0 COMMENT '成功数',
+    failed INT NOT NULL DEFAULT 0 COMMENT '失败数',
+    skipped INT NOT NULL DEFAULT 0 COMMENT '跳过数',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+    finished_at TIMESTAMP NULL COMMENT '完成时间',
+    INDEX idx_status (status),
+    INDEX idx_created_at (created_at)
+) ENGINE=InnoDB COMMENT='sing-box版本切换任务表';
+
+-- sing-box版本切换进度表
+CREATE TABLE IF NOT EXISTS singbox_rollout_targets (
+    id INT AUTO_INCREMENT PRIMARY KEY COMMENT '记录ID',
+    rollout_id INT NOT NULL COMMENT '任务ID',
+    agent_id VARCHAR(64) NOT NULL COMMENT '代理节点ID',
+    status ENUM('pending', 'running', 'succeeded', 'rolled_back', 'failed', 'skipped') DEFAULT 'pending' COMMENT '切换状态',
+    from_version VARCHAR(32) COMMENT '切换前版本',
+    current_version VARCHAR(32) COMMENT '当前版本',
+    message TEXT COMMENT '结果信息',
+    started_at TIMESTAMP NULL COMMENT '开始时间',
+    finished_at TIMESTAMP NULL COMMENT '完成时间',
+    FOREIGN KEY (rollout_id) REFERENCES singbox_rollouts(id) ON DELETE CASCADE,
+    INDEX idx_rollout_id (rollout_id),
+    INDEX idx_agent_id (agent_id)
+) ENGINE=InnoDB COMMENT='sing-box版本切换进度表';
+
+-- 配置模板表
+CREATE TABLE IF NOT EXISTS config_templates (
+    id INT AUTO_INCREMENT PRIMARY KEY COMMENT '模板ID',
+    name VARCHAR(128) NOT NULL UNIQUE COMMENT '模板名称',
+    description VARCHAR(512) COMMENT '模板描述',
+    content TEXT NOT NULL COMMENT '模板内容(text/template)',
+    revision INT NOT NULL DEFAULT 1 COMMENT '当前修订版本',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间'
+) ENGINE=InnoDB COMMENT='sing-box配置模板表';
+
+-- 配置模板修订表
+CREATE TABLE IF NOT EXISTS config_template_revisions (
+    id INT AUTO_INCREMENT PRIMARY KEY COMMENT '记录ID',
+    template_id INT NOT NULL COMMENT '模板ID',
+    revision INT NOT NULL COMMENT '修订版本',
+    content TEXT NOT NULL COMMENT '模板内容',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+    FOREIGN KEY (template_id) REFERENCES config_templates(id) ON DELETE CASCADE,
+    UNIQUE KEY uk_template_revision (template_id, revision)
+) ENGINE=InnoDB COMMENT='配置模板修订表';
+
+-- Agent模板变量表
+CREATE TABLE IF NOT EXISTS agent_template_vars (
+    agent_id VARCHAR(64) PRIMARY KEY COMMENT '代理节点ID',
+    variables JSON COMMENT '模板变量',
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间'
+) ENGINE=InnoDB COMMENT='Agent模板变量表';
+
+-- 客户端订阅表
+CREATE TABLE IF NOT EXISTS subscriptions (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    name VARCHAR(128) COMMENT '订阅名称',
+    user_name VARCHAR(128) NOT NULL COMMENT '入站用户标识',
+    token VARCHAR(64) NOT NULL COMMENT '订阅令牌',
+    enabled BOOLEAN NOT NULL DEFAULT TRUE COMMENT '是否启用',
+    regions JSON COMMENT '允许的国家或地区',
+    agent_ids JSON COMMENT '允许的Agent',
+    inbound_tags JSON COMMENT '允许的入站tag',
+    expires_at TIMESTAMP NULL COMMENT '过期时间',
+    last_access_at TIMESTAMP NULL COMMENT '最后访问时间',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+    UNIQUE KEY uk_token (token),
+    INDEX idx_user_name (user_name)
+) ENGINE=InnoDB COMMENT='客户端订阅表';
+
 -- 插入默认系统配置
 INSERT INTO system_configs (config_key, config_value, description) VALUES
 ('heartbeat_interval', '30', '心跳间隔时间(秒)'),